@@ -0,0 +1,48 @@
+// -------------------------------------------------------------------------------------------
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License. See License.txt in the project root for license information.
+// --------------------------------------------------------------------------------------------
+
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/spf13/pflag"
+	"k8s.io/klog/v2"
+
+	"github.com/Azure/application-gateway-kubernetes-ingress/pkg/azure/fakearm"
+)
+
+const fakeARMCommand = "fake-arm"
+
+// runFakeARM serves a stand-in for the Azure Resource Manager APIs AGIC uses, so that AGIC can run with ARM_ENDPOINT pointing
+// at it, e.g. in a kind cluster, without an Azure subscription.
+func runFakeARM(args []string) error {
+	fakeARMFlags := pflag.NewFlagSet("appgw-ingress fake-arm", pflag.ExitOnError)
+	listen := fakeARMFlags.String("listen", ":8080", "Address to serve the fake ARM on.")
+	resourcesFile := fakeARMFlags.String("resources", "", "Path to a JSON array of the Application Gateways, Public IP Addresses, Subnets and Route Tables to start with.")
+	operationDuration := fakeARMFlags.Duration("operation-duration", 5*time.Second, "How long the deployment of an Application Gateway or Subnet takes.")
+	if err := fakeARMFlags.Parse(args); err != nil {
+		return err
+	}
+
+	server := fakearm.NewServer()
+	server.OperationDuration = *operationDuration
+	if *resourcesFile != "" {
+		resources, err := os.Open(*resourcesFile)
+		if err != nil {
+			return err
+		}
+		defer resources.Close()
+		if err := server.Load(resources); err != nil {
+			return fmt.Errorf("loading %s: %w", *resourcesFile, err)
+		}
+	}
+
+	klog.Infof("Serving the fake ARM on %s", *listen)
+	return http.ListenAndServe(*listen, server)
+}
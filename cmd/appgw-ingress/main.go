@@ -6,12 +6,14 @@
 package main
 
 import (
+	"context"
 	"flag"
 	"fmt"
 	"os"
 	"os/signal"
 	"strconv"
 	"strings"
+	"sync"
 	"syscall"
 	"time"
 
@@ -23,6 +25,7 @@ import (
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/klog/v2"
 	ctrl_client "sigs.k8s.io/controller-runtime/pkg/client"
+	gatewayapi "sigs.k8s.io/gateway-api/pkg/client/clientset/versioned"
 
 	"github.com/Azure/application-gateway-kubernetes-ingress/pkg/appgw"
 	"github.com/Azure/application-gateway-kubernetes-ingress/pkg/azure"
@@ -68,10 +71,29 @@ func main() {
 	// Log output is buffered... Calling Flush before exiting guarantees all log output is written.
 	klog.InitFlags(nil)
 	defer klog.Flush()
+
+	if len(os.Args) > 1 && os.Args[1] == renderCommand {
+		if err := runRender(os.Args[2:], os.Stdout); err != nil {
+			klog.Fatal("Error rendering the App Gateway config: ", err)
+		}
+		return
+	}
+
+	if len(os.Args) > 1 && os.Args[1] == fakeARMCommand {
+		if err := runFakeARM(os.Args[2:]); err != nil {
+			klog.Fatal("Error serving the fake ARM: ", err)
+		}
+		return
+	}
+
 	if err := flags.Parse(os.Args); err != nil {
 		klog.Fatal("Error parsing command line arguments:", err)
 	}
 
+	// ctx is done once AGIC is asked to shut down; It cancels the ARM operations of the startup.
+	ctx, stopNotify := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stopNotify()
+
 	env := environment.GetEnv()
 	verbosity = to.IntPtr(getVerbosity(*verbosity, env.VerbosityLevel))
 	if *versionInfo {
@@ -119,13 +141,24 @@ func main() {
 	k8scontext.IsInMultiClusterMode = env.MultiClusterMode
 	crdClient := versioned.NewForConfigOrDie(apiConfig)
 	istioCrdClient := istio.NewForConfigOrDie(apiConfig)
+	gatewayAPIClient := gatewayapi.NewForConfigOrDie(apiConfig)
 	multiClusterCrdClient := multicluster.NewForConfigOrDie(apiConfig)
 	recorder := getEventRecorder(kubeClient, env.IngressClassControllerName)
 	namespaces := getNamespacesToWatch(env.WatchNamespace)
-	metricStore := metricstore.NewMetricStore(env)
-	metricStore.Start()
-	k8sContext := k8scontext.NewContext(kubeClient, crdClient, multiClusterCrdClient, istioCrdClient, namespaces, *resyncPeriod, metricStore, env)
-	agicPod := k8sContext.GetAGICPod(env)
+	agicPod := k8scontext.GetAGICPod(kubeClient, env)
+
+	// The IngressClasses may set their Application Gateway in their AzureApplicationGatewayClassParameters.
+	if k8scontext.IsNetworkingV1PackageSupported && !env.MultiClusterMode {
+		if env, err = env.ResolveGateways(func(ingressClass string) (string, error) {
+			return k8scontext.GetIngressClassGateway(kubeClient, crdClient, ingressClass)
+		}); err != nil {
+			errorLine := fmt.Sprint("Error while resolving the Application Gateways of the IngressClasses: ", err)
+			if agicPod != nil {
+				recorder.Event(agicPod, v1.EventTypeWarning, events.ReasonValidatonError, errorLine)
+			}
+			klog.Fatal(errorLine)
+		}
+	}
 
 	if err := environment.ValidateEnv(env); err != nil {
 		errorLine := fmt.Sprint("Error while initializing values from environment. Please check helm configuration for missing values: ", err)
@@ -135,59 +168,33 @@ func main() {
 		klog.Fatal(errorLine)
 	}
 
+	// Each Application Gateway of APPGW_GATEWAYS gets its own controller, with its own informers, ARM client, config cache and metrics.
+	gatewayEnvs := []environment.EnvVariables{env}
+	gateways, _ := env.GetGateways()
+	if len(gateways) > 0 {
+		gatewayEnvs = nil
+		for _, gateway := range gateways {
+			gatewayEnvs = append(gatewayEnvs, env.ForGateway(gateway))
+		}
+	}
+	metricStores := metricstore.NewMetricStores(gatewayEnvs)
+
 	uniqueUserAgentSuffix := utils.RandStringRunes(10)
 	if agicPod != nil {
 		uniqueUserAgentSuffix = agicPod.Name
 	}
 	klog.Infof("Using User Agent Suffix='%s' when communicating with ARM", uniqueUserAgentSuffix)
 
-	azClient := azure.NewAzClient(azure.SubscriptionID(env.SubscriptionID), azure.ResourceGroup(env.ResourceGroupName), azure.ResourceName(env.AppGwName), uniqueUserAgentSuffix, env.ClientID)
-	appGwIdentifier := appgw.Identifier{
-		SubscriptionID: env.SubscriptionID,
-		ResourceGroup:  env.ResourceGroupName,
-		AppGwName:      env.AppGwName,
-	}
-
-	klog.V(3).Infof("Application Gateway Details: Subscription=\"%s\" Resource Group=\"%s\" Name=\"%s\"", env.SubscriptionID, env.ResourceGroupName, env.AppGwName)
-
-	var authorizer autorest.Authorizer
-	if authorizer, err = azure.GetAuthorizerWithRetry(env.AuthLocation, env.UseManagedIdentityForPod, cpConfig, maxRetryCount, retryPause); err != nil {
+	// A stand-in for ARM, e.g. the fake-arm subcommand, has no Azure AD to get a token from.
+	var authorizer autorest.Authorizer = autorest.NullAuthorizer{}
+	if env.ARMEndpoint != "" {
+		klog.Warningf("Using the Azure Resource Manager at %s without authentication", env.ARMEndpoint)
+	} else if authorizer, err = azure.GetAuthorizerWithRetry(env.AuthLocation, env.UseManagedIdentityForPod, cpConfig, maxRetryCount, retryPause); err != nil {
 		errorLine := fmt.Sprint("Failed obtaining authentication token for Azure Resource Manager: ", err)
 		if agicPod != nil {
 			recorder.Event(agicPod, v1.EventTypeWarning, events.ReasonARMAuthFailure, errorLine)
 		}
 		klog.Fatal(errorLine)
-	} else {
-		azClient.SetAuthorizer(authorizer)
-	}
-
-	// Check if Application Gateway exists/have get access
-	// If AGIC's service principal or managed identity doesn't have read access to the Application Gateway's resource group,
-	// then AGIC can't read it's role assignments to look for the needed permission.
-	// Instead we perform a simple GET request to check both that the Application Gateway exists as well as implicitly make sure that AGIC has read access to it.
-	err = azClient.WaitForGetAccessOnGateway(maxRetryCount)
-	if err != nil {
-		if controllererrors.IsErrorCode(err, controllererrors.ErrorApplicationGatewayNotFound) && env.EnableDeployAppGateway {
-			if env.AppGwSubnetID != "" {
-				err = azClient.DeployGatewayWithSubnet(env.AppGwSubnetID, env.AppGwSkuName)
-			} else if cpConfig != nil {
-				err = azClient.DeployGatewayWithVnet(azure.ResourceGroup(cpConfig.VNetResourceGroup), azure.ResourceName(cpConfig.VNetName), azure.ResourceName(env.AppGwSubnetName), env.AppGwSubnetPrefix, env.AppGwSkuName)
-			}
-
-			if err != nil {
-				errorLine := fmt.Sprint("Failed in deploying Application Gateway", err)
-				if agicPod != nil {
-					recorder.Event(agicPod, v1.EventTypeWarning, events.ReasonFailedDeployingAppGw, errorLine)
-				}
-				klog.Fatal(errorLine)
-			}
-		} else {
-			errorLine := fmt.Sprint("Failed getting Application Gateway: ", err)
-			if agicPod != nil {
-				recorder.Event(agicPod, v1.EventTypeWarning, events.ReasonARMAuthFailure, errorLine)
-			}
-			klog.Fatal(errorLine)
-		}
 	}
 
 	// namespace validations
@@ -200,44 +207,123 @@ func main() {
 		klog.Info("Ingress Controller will observe the following namespaces:", strings.Join(namespaces, ","))
 	}
 
-	// fatal config validations
-	appGw, _ := azClient.GetGateway()
-	if _, exists := allowedSkus[appGw.Sku.Tier]; !exists {
-		errorLine := fmt.Sprintf("App Gateway SKU Tier %s is not supported by AGIC version %s; (v0.10.0 supports App Gwy v1)", appGw.Sku.Tier, appgw.GetVersion())
-		if agicPod != nil {
-			recorder.Event(agicPod, v1.EventTypeWarning, events.UnsupportedAppGatewaySKUTier, errorLine)
+	var controllers []*controller.AppGwIngressController
+	// The readiness gate of a pod waits for every Application Gateway whose backend pools include it.
+	backendPools := controller.NewBackendPools()
+	for i, gatewayEnv := range gatewayEnvs {
+		metricStore := metricStores[i]
+		metricStore.Start()
+		k8sContext := k8scontext.NewContext(kubeClient, crdClient, multiClusterCrdClient, istioCrdClient, gatewayAPIClient, namespaces, *resyncPeriod, metricStore, gatewayEnv)
+
+		var azClient azure.AzClient
+		if env.ARMEndpoint != "" {
+			azClient = azure.NewAzClientWithBaseURI(env.ARMEndpoint, azure.SubscriptionID(gatewayEnv.SubscriptionID), azure.ResourceGroup(gatewayEnv.ResourceGroupName), azure.ResourceName(gatewayEnv.AppGwName), uniqueUserAgentSuffix, env.ClientID)
+		} else {
+			azClient = azure.NewAzClient(azure.SubscriptionID(gatewayEnv.SubscriptionID), azure.ResourceGroup(gatewayEnv.ResourceGroupName), azure.ResourceName(gatewayEnv.AppGwName), uniqueUserAgentSuffix, env.ClientID)
+		}
+		azClient.SetTimeouts(azure.NewTimeouts(env.ARMGetTimeout, env.ARMPutTimeout, env.ARMPollingTimeout))
+		azClient.SetRetryMetrics(metricStore)
+		azClient.SetAuthorizer(authorizer)
+		appGwIdentifier := appgw.Identifier{
+			SubscriptionID: gatewayEnv.SubscriptionID,
+			ResourceGroup:  gatewayEnv.ResourceGroupName,
+			AppGwName:      gatewayEnv.AppGwName,
 		}
 
-		// Slow down the cycling of the AGIC pod.
-		time.Sleep(5 * time.Second)
-		klog.Fatal(errorLine)
-	}
+		klog.V(3).Infof("Application Gateway Details: Subscription=\"%s\" Resource Group=\"%s\" Name=\"%s\"", gatewayEnv.SubscriptionID, gatewayEnv.ResourceGroupName, gatewayEnv.AppGwName)
+
+		// Check if Application Gateway exists/have get access
+		// If AGIC's service principal or managed identity doesn't have read access to the Application Gateway's resource group,
+		// then AGIC can't read it's role assignments to look for the needed permission.
+		// Instead we perform a simple GET request to check both that the Application Gateway exists as well as implicitly make sure that AGIC has read access to it.
+		err = azClient.WaitForGetAccessOnGateway(ctx, maxRetryCount)
+		if err != nil {
+			if controllererrors.IsErrorCode(err, controllererrors.ErrorApplicationGatewayNotFound) && env.EnableDeployAppGateway {
+				if env.AppGwSubnetID != "" {
+					err = azClient.DeployGatewayWithSubnet(ctx, env.AppGwSubnetID, env.AppGwSkuName)
+				} else if cpConfig != nil {
+					err = azClient.DeployGatewayWithVnet(ctx, azure.ResourceGroup(cpConfig.VNetResourceGroup), azure.ResourceName(cpConfig.VNetName), azure.ResourceName(env.AppGwSubnetName), env.AppGwSubnetPrefix, env.AppGwSkuName)
+				}
 
-	cniReconciler := cni.NewReconciler(azClient, ctrlClient, recorder, cpConfig, appGw, agicPod, env.AGICPodNamespace, env.AddonMode)
+				if err != nil {
+					errorLine := fmt.Sprint("Failed in deploying Application Gateway", err)
+					if agicPod != nil {
+						recorder.Event(agicPod, v1.EventTypeWarning, events.ReasonFailedDeployingAppGw, errorLine)
+					}
+					klog.Fatal(errorLine)
+				}
+			} else {
+				errorLine := fmt.Sprint("Failed getting Application Gateway: ", err)
+				if agicPod != nil {
+					recorder.Event(agicPod, v1.EventTypeWarning, events.ReasonARMAuthFailure, errorLine)
+				}
+				klog.Fatal(errorLine)
+			}
+		}
 
-	// create a new agic controller
-	appGwIngressController := controller.NewAppGwIngressController(azClient, appGwIdentifier, k8sContext, recorder, metricStore, cniReconciler, agicPod, env.HostedOnUnderlay)
+		// fatal config validations
+		appGw, _ := azClient.GetGateway(ctx)
+		if _, exists := allowedSkus[appGw.Sku.Tier]; !exists {
+			errorLine := fmt.Sprintf("App Gateway SKU Tier %s is not supported by AGIC version %s; (v0.10.0 supports App Gwy v1)", appGw.Sku.Tier, appgw.GetVersion())
+			if agicPod != nil {
+				recorder.Event(agicPod, v1.EventTypeWarning, events.UnsupportedAppGatewaySKUTier, errorLine)
+			}
+
+			// Slow down the cycling of the AGIC pod.
+			time.Sleep(5 * time.Second)
+			klog.Fatal(errorLine)
+		}
+
+		// create a new agic controller
+		appGwIngressController := controller.NewAppGwIngressController(azClient, appGwIdentifier, k8sContext, recorder, metricStore, nil, agicPod, env.HostedOnUnderlay)
+
+		// The CNI reconciler uses the ARM client of the controller, whose operations the liveness probe watches.
+		appGwIngressController.ReconcileCNI(cni.NewReconciler(appGwIngressController.AzClient(), ctrlClient, recorder, cpConfig, appGw, agicPod, env.AGICPodNamespace, env.AddonMode))
+		if len(gateways) > 0 {
+			appGwIngressController.ManageGateway(gateways[i])
+			appGwIngressController.ShareBackendPools(backendPools)
+			klog.Infof("Managing Application Gateway %s for the Ingresses of IngressClass %s", gatewayEnv.AppGwName, gateways[i].IngressClass)
+		}
+		controllers = append(controllers, appGwIngressController)
+	}
 
 	// initialize the http server and start it
+	adminPort := ""
+	if env.EnableConfigAdminAPI {
+		adminPort = env.ConfigAdminPort
+	}
 	httpServer := httpserver.NewHTTPServer(
-		appGwIngressController,
-		metricStore,
-		env.HTTPServicePort)
+		controllers,
+		metricStores[0],
+		env.HTTPServicePort,
+		adminPort)
 	httpServer.Start()
 
-	if err := appGwIngressController.Start(env); err != nil {
-		errorLine := fmt.Sprint("Could not start AGIC: ", err)
-		if agicPod != nil {
-			recorder.Event(agicPod, v1.EventTypeWarning, events.ReasonARMAuthFailure, errorLine)
+	for i, appGwIngressController := range controllers {
+		if err := appGwIngressController.Start(gatewayEnvs[i]); err != nil {
+			errorLine := fmt.Sprint("Could not start AGIC: ", err)
+			if agicPod != nil {
+				recorder.Event(agicPod, v1.EventTypeWarning, events.ReasonARMAuthFailure, errorLine)
+			}
+			klog.Fatal(errorLine)
 		}
-		klog.Fatal(errorLine)
 	}
 
-	sigChan := make(chan os.Signal, 1)
-	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
-	<-sigChan
-
-	appGwIngressController.Stop()
+	<-ctx.Done()
+	// A second signal terminates AGIC right away.
+	stopNotify()
+	klog.Info("Shutting down")
+
+	// Stop waits for a deployment in progress to complete, or abandons it after the shutdown grace period.
+	var stopped sync.WaitGroup
+	for _, appGwIngressController := range controllers {
+		stopped.Add(1)
+		go func(appGwIngressController *controller.AppGwIngressController) {
+			defer stopped.Done()
+			appGwIngressController.Stop()
+		}(appGwIngressController)
+	}
+	stopped.Wait()
 	httpServer.Stop()
 	klog.Info("Goodbye!")
 }
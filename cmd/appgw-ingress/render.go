@@ -0,0 +1,124 @@
+// -------------------------------------------------------------------------------------------
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License. See License.txt in the project root for license information.
+// --------------------------------------------------------------------------------------------
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	n "github.com/Azure/azure-sdk-for-go/services/network/mgmt/2021-03-01/network"
+	"github.com/spf13/pflag"
+	"k8s.io/apimachinery/pkg/util/yaml"
+
+	"github.com/Azure/application-gateway-kubernetes-ingress/pkg/environment"
+	"github.com/Azure/application-gateway-kubernetes-ingress/pkg/render"
+)
+
+const (
+	renderCommand = "render"
+
+	renderOutputJSON = "json"
+	renderOutputDiff = "diff"
+)
+
+// runRender generates the App Gateway config of Kubernetes manifests on top of an existing App Gateway config, without a
+// cluster and without ARM, and writes the generated config or its diff to out.
+func runRender(args []string, out io.Writer) error {
+	renderFlags := pflag.NewFlagSet("appgw-ingress render", pflag.ExitOnError)
+	gatewayFile := renderFlags.String("gateway", "", "Path to the JSON of the existing Application Gateway, e.g. the output of 'az network application-gateway show'. Required.")
+	manifests := renderFlags.StringSlice("manifests", nil, "Path to a YAML or JSON manifest, or to a directory of manifests, of the Kubernetes resources. Can be repeated. Required.")
+	envFile := renderFlags.String("env", "", "Path to a YAML map of the environment variables of AGIC, or to the ConfigMap the Helm chart generates. Optional.")
+	namespace := renderFlags.String("namespace", "default", "Namespace of the resources whose manifests do not set one.")
+	output := renderFlags.StringP("output", "o", renderOutputJSON, "Output format: 'json' prints the generated config, 'diff' the changes to the existing config.")
+	if err := renderFlags.Parse(args); err != nil {
+		return err
+	}
+
+	if *gatewayFile == "" || len(*manifests) == 0 {
+		return fmt.Errorf("render requires --gateway and --manifests")
+	}
+	if *output != renderOutputJSON && *output != renderOutputDiff {
+		return fmt.Errorf("unknown output format %q; Use %q or %q", *output, renderOutputJSON, renderOutputDiff)
+	}
+
+	existing, err := readGateway(*gatewayFile)
+	if err != nil {
+		return err
+	}
+
+	objects := &render.Objects{}
+	for _, path := range *manifests {
+		loaded, err := render.LoadManifests(path, *namespace)
+		if err != nil {
+			return err
+		}
+		objects.Kubernetes = append(objects.Kubernetes, loaded.Kubernetes...)
+		objects.AGIC = append(objects.AGIC, loaded.AGIC...)
+		objects.MultiCluster = append(objects.MultiCluster, loaded.MultiCluster...)
+		objects.Istio = append(objects.Istio, loaded.Istio...)
+		objects.GatewayAPI = append(objects.GatewayAPI, loaded.GatewayAPI...)
+	}
+
+	if *envFile != "" {
+		if err := setEnvFromFile(*envFile); err != nil {
+			return err
+		}
+	}
+	env := environment.GetEnv()
+	env.Consolidate(nil)
+
+	result, err := render.Render(existing, objects, env, getNamespacesToWatch(env.WatchNamespace))
+	if err != nil {
+		return err
+	}
+
+	if *output == renderOutputDiff {
+		_, err = fmt.Fprint(out, result.DiffText())
+		return err
+	}
+	jsonBlob, err := result.JSON()
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintln(out, string(jsonBlob))
+	return err
+}
+
+func readGateway(path string) (*n.ApplicationGateway, error) {
+	contents, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var appGw n.ApplicationGateway
+	if err := json.Unmarshal(contents, &appGw); err != nil {
+		return nil, fmt.Errorf("reading App Gateway config %s: %w", path, err)
+	}
+	return &appGw, nil
+}
+
+// setEnvFromFile sets the environment variables of a YAML map, or of the data of a ConfigMap, as the AGIC pod would get them.
+func setEnvFromFile(path string) error {
+	contents, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	var vars map[string]interface{}
+	if err := yaml.Unmarshal(contents, &vars); err != nil {
+		return fmt.Errorf("reading environment %s: %w", path, err)
+	}
+	if vars["kind"] == "ConfigMap" {
+		data, _ := vars["data"].(map[string]interface{})
+		vars = data
+	}
+	for name, value := range vars {
+		if err := os.Setenv(name, fmt.Sprint(value)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
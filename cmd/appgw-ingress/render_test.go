@@ -0,0 +1,55 @@
+// -------------------------------------------------------------------------------------------
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License. See License.txt in the project root for license information.
+// --------------------------------------------------------------------------------------------
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+
+	n "github.com/Azure/azure-sdk-for-go/services/network/mgmt/2021-03-01/network"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/Azure/application-gateway-kubernetes-ingress/pkg/environment"
+)
+
+var _ = Describe("Test the render command", func() {
+	AfterEach(func() {
+		Expect(os.Unsetenv(environment.EnableGatewayAPIVarName)).To(Succeed())
+	})
+
+	It("should render the HTTPRoutes of the manifests", func() {
+		var out bytes.Buffer
+		err := runRender([]string{
+			"--gateway", "testdata/render/appgw.json",
+			"--manifests", "testdata/render/httproute.yaml",
+			"--env", "testdata/render/env.yaml",
+		}, &out)
+		Expect(err).ToNot(HaveOccurred())
+
+		var appGw n.ApplicationGateway
+		Expect(json.Unmarshal(out.Bytes(), &appGw)).To(Succeed())
+
+		var hostNames []string
+		for _, listener := range *appGw.HTTPListeners {
+			if listener.HostNames != nil {
+				hostNames = append(hostNames, *listener.HostNames...)
+			}
+		}
+		Expect(hostNames).To(ContainElement("shop.contoso.com"))
+
+		var addresses []string
+		for _, pool := range *appGw.BackendAddressPools {
+			if pool.BackendAddresses != nil {
+				for _, address := range *pool.BackendAddresses {
+					addresses = append(addresses, *address.IPAddress)
+				}
+			}
+		}
+		Expect(addresses).To(ContainElement("10.0.0.5"))
+	})
+})
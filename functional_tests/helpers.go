@@ -31,6 +31,11 @@ func check(cbCtx *appgw.ConfigBuilderContext, expectedFilename string, stopChan
 			if (*appGW.SslCertificates)[idx].Data != nil {
 				*(*appGW.SslCertificates)[idx].Data = "xx"
 			}
+			// every secret is converted with its own random password
+			if (*appGW.SslCertificates)[idx].Password != nil {
+				gomega.Expect(*(*appGW.SslCertificates)[idx].Password).ToNot(gomega.BeEmpty())
+				*(*appGW.SslCertificates)[idx].Password = "xx"
+			}
 		}
 	}
 
@@ -101,4 +101,18 @@ If release name contains chart name it will be used as a full name.
 {{- printf "%s-azidbinding-%s" .Release.Name $name | trunc 63 | trimSuffix "-" -}}
 {{- end -}}
 {{- end -}}
-{{- end -}}
\ No newline at end of file
+{{- end -}}
+{{/*
+List the Application Gateways AGIC manages as comma separated <ingress class>=<application gateway resource ID> pairs.
+*/}}
+{{- define "application-gateway-kubernetes-ingress.gateways" -}}
+{{- $gateways := list -}}
+{{- range .Values.appgw.gateways -}}
+{{- if and .parameters (not .applicationGatewayID) -}}
+{{- $gateways = append $gateways (required "Each of appgw.gateways requires an ingressClass" .ingressClass) -}}
+{{- else -}}
+{{- $gateways = append $gateways (printf "%s=%s" (required "Each of appgw.gateways requires an ingressClass" .ingressClass) (required "Each of appgw.gateways requires an applicationGatewayID, or parameters setting it" .applicationGatewayID)) -}}
+{{- end -}}
+{{- end -}}
+{{- join "," $gateways -}}
+{{- end -}}
@@ -109,8 +109,41 @@ const (
 	// RewriteRuleSetCustomResourceKey indicates the name of the rule set CRD to use for header CRD and URL Config.
 	RewriteRuleSetCustomResourceKey = ApplicationGatewayPrefix + "/rewrite-rule-set-custom-resource"
 
+	// LoadDistributionPolicyKey indicates the name of the LoadDistributionPolicy splitting the traffic of the backends it targets.
+	LoadDistributionPolicyKey = ApplicationGatewayPrefix + "/load-distribution-policy"
+
+	// RouteMatchCustomResourceKey indicates the name of the AzureApplicationGatewayRouteMatch routing requests by header, query parameter or cookie.
+	RouteMatchCustomResourceKey = ApplicationGatewayPrefix + "/route-match-custom-resource"
+
+	// CanaryKey marks the Ingress as the canary of the Ingress with the same host and path.
+	// The canary receives the share of the traffic set by the other canary annotations.
+	CanaryKey = ApplicationGatewayPrefix + "/canary"
+
+	// CanaryWeightKey defines the share of the traffic sent to the canary, out of the canary weight total.
+	CanaryWeightKey = ApplicationGatewayPrefix + "/canary-weight"
+
+	// CanaryWeightTotalKey defines the total the canary weight is a share of. Default value is 100.
+	CanaryWeightTotalKey = ApplicationGatewayPrefix + "/canary-weight-total"
+
+	// CanaryByHeaderKey defines the request header routing requests to the canary when set to "always" and away from it when set to "never".
+	CanaryByHeaderKey = ApplicationGatewayPrefix + "/canary-by-header"
+
+	// CanaryByHeaderValueKey defines the value of the canary header routing requests to the canary, instead of "always".
+	CanaryByHeaderValueKey = ApplicationGatewayPrefix + "/canary-by-header-value"
+
+	// CanaryByCookieKey defines the cookie routing requests to the canary when set to "always" and away from it when set to "never".
+	CanaryByCookieKey = ApplicationGatewayPrefix + "/canary-by-cookie"
+
+	// NginxIngressPrefix defines the prefix of the ingress-nginx annotations AGIC understands, to ease migrating Ingresses.
+	// Annotations with the Application Gateway prefix take precedence.
+	NginxIngressPrefix = "nginx.ingress.kubernetes.io"
+
 	// RequestRoutingRulePriority indicates the priority of the Request Routing Rules.
 	RequestRoutingRulePriority = ApplicationGatewayPrefix + "/rule-priority"
+
+	// IngressStatusKey is the key of the annotation in which AGIC reports which rules of the Ingress it applied and which it pruned.
+	// AGIC sets this annotation; It is not meant to be set by users.
+	IngressStatusKey = ApplicationGatewayPrefix + "/status"
 )
 
 // ProtocolEnum is the type for protocol
@@ -306,6 +339,62 @@ func RewriteRuleSetCustomResource(ing *networking.Ingress) (string, error) {
 	return parseString(ing, RewriteRuleSetCustomResourceKey)
 }
 
+// LoadDistributionPolicy name
+func LoadDistributionPolicy(ing *networking.Ingress) (string, error) {
+	return parseString(ing, LoadDistributionPolicyKey)
+}
+
+// IsCanary tells whether the Ingress is the canary of another Ingress
+func IsCanary(ing *networking.Ingress) (bool, error) {
+	return parseBool(ing, nginxFallback(ing, CanaryKey))
+}
+
+// CanaryWeight returns the share of the traffic sent to the canary and the total it is a share of
+func CanaryWeight(ing *networking.Ingress) (int32, int32, error) {
+	weight, err := parseInt32(ing, nginxFallback(ing, CanaryWeightKey))
+	if err != nil {
+		return 0, 0, err
+	}
+
+	total, err := parseInt32(ing, nginxFallback(ing, CanaryWeightTotalKey))
+	if controllererrors.IsErrorCode(err, controllererrors.ErrorMissingAnnotation) {
+		total, err = 100, nil
+	}
+	if err != nil {
+		return 0, 0, err
+	}
+
+	if total <= 0 {
+		return 0, 0, controllererrors.NewErrorf(controllererrors.ErrorInvalidContent,
+			"Canary weight total must be a positive value, not %d", total)
+	}
+	if weight < 0 || weight > total {
+		return 0, 0, controllererrors.NewErrorf(controllererrors.ErrorInvalidContent,
+			"Canary weight must be a value from 0 to %d", total)
+	}
+	return weight, total, nil
+}
+
+// CanaryByHeader name
+func CanaryByHeader(ing *networking.Ingress) (string, error) {
+	return parseString(ing, nginxFallback(ing, CanaryByHeaderKey))
+}
+
+// CanaryByHeaderValue value
+func CanaryByHeaderValue(ing *networking.Ingress) (string, error) {
+	return parseString(ing, nginxFallback(ing, CanaryByHeaderValueKey))
+}
+
+// CanaryByCookie name
+func CanaryByCookie(ing *networking.Ingress) (string, error) {
+	return parseString(ing, nginxFallback(ing, CanaryByCookieKey))
+}
+
+// RouteMatchCustomResource name
+func RouteMatchCustomResource(ing *networking.Ingress) (string, error) {
+	return parseString(ing, RouteMatchCustomResourceKey)
+}
+
 // GetRequestRoutingRulePriority gets the request routing rule priority
 func GetRequestRoutingRulePriority(ing *networking.Ingress) (*int32, error) {
 	min := int32(1)
@@ -324,6 +413,18 @@ func GetRequestRoutingRulePriority(ing *networking.Ingress) (*int32, error) {
 	return nil, err
 }
 
+// nginxFallback returns the key of the ingress-nginx annotation matching the given key, when only the ingress-nginx annotation is set.
+func nginxFallback(ing *networking.Ingress, name string) string {
+	if _, ok := ing.Annotations[name]; ok {
+		return name
+	}
+	nginxName := NginxIngressPrefix + strings.TrimPrefix(name, ApplicationGatewayPrefix)
+	if _, ok := ing.Annotations[nginxName]; ok {
+		return nginxName
+	}
+	return name
+}
+
 func parseBool(ing *networking.Ingress, name string) (bool, error) {
 	if val, ok := ing.Annotations[name]; ok {
 		if boolVal, err := strconv.ParseBool(val); err == nil {
@@ -61,6 +61,13 @@ var _ = Describe("Test ingress annotation functions", func() {
 		"appgw.ingress.kubernetes.io/health-probe-unhealthy-threshold":    "3",
 		"appgw.ingress.kubernetes.io/rewrite-rule-set":                    "my-rewrite-rule-set",
 		"appgw.ingress.kubernetes.io/rewrite-rule-set-custom-resource":    "my-rewrite-rule-set-cr",
+		"appgw.ingress.kubernetes.io/load-distribution-policy":            "my-load-distribution-policy",
+		"appgw.ingress.kubernetes.io/route-match-custom-resource":         "my-route-match",
+		"appgw.ingress.kubernetes.io/canary":                              "true",
+		"appgw.ingress.kubernetes.io/canary-weight":                       "20",
+		"appgw.ingress.kubernetes.io/canary-by-header":                    "X-Canary",
+		"appgw.ingress.kubernetes.io/canary-by-header-value":              "blue",
+		"appgw.ingress.kubernetes.io/canary-by-cookie":                    "canary",
 		"kubernetes.io/ingress.class":                                     "azure/application-gateway",
 		"appgw.ingress.istio.io/v1alpha3":                                 "azure/application-gateway",
 		"falseKey":                                                        "false",
@@ -270,6 +277,99 @@ var _ = Describe("Test ingress annotation functions", func() {
 		})
 	})
 
+	Context("test load-distribution-policy", func() {
+		It("returns error when ingress has no annotations", func() {
+			ing := &networking.Ingress{}
+			actual, err := LoadDistributionPolicy(ing)
+			Expect(err).To(HaveOccurred())
+			Expect(actual).To(Equal(""))
+		})
+		It("returns the load distribution policy", func() {
+			actual, err := LoadDistributionPolicy(ing)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(actual).To(Equal("my-load-distribution-policy"))
+		})
+	})
+
+	Context("test canary", func() {
+		It("returns error when ingress has no annotations", func() {
+			ing := &networking.Ingress{}
+			isCanary, err := IsCanary(ing)
+			Expect(err).To(HaveOccurred())
+			Expect(isCanary).To(BeFalse())
+			_, _, err = CanaryWeight(ing)
+			Expect(controllererrors.IsErrorCode(err, controllererrors.ErrorMissingAnnotation)).To(BeTrue())
+		})
+		It("returns the canary annotations", func() {
+			isCanary, err := IsCanary(ing)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(isCanary).To(BeTrue())
+
+			weight, total, err := CanaryWeight(ing)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(weight).To(Equal(int32(20)))
+			Expect(total).To(Equal(int32(100)))
+
+			header, err := CanaryByHeader(ing)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(header).To(Equal("X-Canary"))
+
+			value, err := CanaryByHeaderValue(ing)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(value).To(Equal("blue"))
+
+			cookie, err := CanaryByCookie(ing)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(cookie).To(Equal("canary"))
+		})
+		It("falls back to the ingress-nginx annotations", func() {
+			ing := &networking.Ingress{
+				ObjectMeta: v1.ObjectMeta{
+					Annotations: map[string]string{
+						"nginx.ingress.kubernetes.io/canary":              "true",
+						"nginx.ingress.kubernetes.io/canary-weight":       "3",
+						"nginx.ingress.kubernetes.io/canary-weight-total": "10",
+						"appgw.ingress.kubernetes.io/canary-weight-total": "30",
+					},
+				},
+			}
+			isCanary, err := IsCanary(ing)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(isCanary).To(BeTrue())
+
+			weight, total, err := CanaryWeight(ing)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(weight).To(Equal(int32(3)))
+			Expect(total).To(Equal(int32(30)))
+		})
+		It("returns error when the weight exceeds the total", func() {
+			ing := &networking.Ingress{
+				ObjectMeta: v1.ObjectMeta{
+					Annotations: map[string]string{
+						"appgw.ingress.kubernetes.io/canary-weight":       "30",
+						"appgw.ingress.kubernetes.io/canary-weight-total": "20",
+					},
+				},
+			}
+			_, _, err := CanaryWeight(ing)
+			Expect(controllererrors.IsErrorCode(err, controllererrors.ErrorInvalidContent)).To(BeTrue())
+		})
+	})
+
+	Context("test route-match-custom-resource", func() {
+		It("returns error when ingress has no annotations", func() {
+			ing := &networking.Ingress{}
+			actual, err := RouteMatchCustomResource(ing)
+			Expect(err).To(HaveOccurred())
+			Expect(actual).To(Equal(""))
+		})
+		It("returns the route match custom resource", func() {
+			actual, err := RouteMatchCustomResource(ing)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(actual).To(Equal("my-route-match"))
+		})
+	})
+
 	Context("test ConnectionDrainingTimeout", func() {
 		It("returns error when ingress has no annotations", func() {
 			ing := &networking.Ingress{}
@@ -0,0 +1,70 @@
+// -------------------------------------------------------------------------------------------
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License. See License.txt in the project root for license information.
+// --------------------------------------------------------------------------------------------
+
+package v1beta1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// +genclient
+// +genclient:nonNamespaced
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// AzureApplicationGatewayClassParameters is the cluster-scoped resource an IngressClass references in its spec.parameters
+// to configure the Application Gateway of the class and the defaults of the Ingresses of the class.
+type AzureApplicationGatewayClassParameters struct {
+	metav1.TypeMeta `json:",inline"`
+
+	// +optional
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+	// +optional
+	Spec AzureApplicationGatewayClassParametersSpec `json:"spec"`
+}
+
+// Frontend is the frontend IP configuration of the Application Gateway an Ingress is exposed on
+type Frontend string
+
+const (
+	// PublicFrontend exposes the Ingresses on the public IP of the Application Gateway
+	PublicFrontend Frontend = "Public"
+
+	// PrivateFrontend exposes the Ingresses on the private IP of the Application Gateway
+	PrivateFrontend Frontend = "Private"
+)
+
+// AzureApplicationGatewayClassParametersSpec defines the Application Gateway of an IngressClass and the defaults of its Ingresses
+type AzureApplicationGatewayClassParametersSpec struct {
+	// ApplicationGatewayID is the resource ID of the Application Gateway the Ingresses of the class are applied to
+	// +optional
+	ApplicationGatewayID string `json:"applicationGatewayID,omitempty"`
+
+	// DefaultFrontend is the frontend of the Ingresses which do not set the use-private-ip annotation
+	// +optional
+	DefaultFrontend Frontend `json:"defaultFrontend,omitempty"`
+
+	// DefaultWAFPolicy is the resource ID of the WAF policy of the Ingresses which do not set the waf-policy-for-path annotation
+	// +optional
+	DefaultWAFPolicy string `json:"defaultWAFPolicy,omitempty"`
+
+	// DefaultSSLProfile is the name of the SSL profile of the Ingresses which do not set the appgw-ssl-profile annotation
+	// +optional
+	DefaultSSLProfile string `json:"defaultSSLProfile,omitempty"`
+
+	// AnnotationDefaults are the annotations of the Ingresses which do not set them
+	// +optional
+	AnnotationDefaults map[string]string `json:"annotationDefaults,omitempty"`
+}
+
+// +genclient:nonNamespaced
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// AzureApplicationGatewayClassParametersList is the list of IngressClass parameters
+type AzureApplicationGatewayClassParametersList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata"`
+
+	Items []AzureApplicationGatewayClassParameters `json:"items"`
+}
@@ -0,0 +1,109 @@
+//go:build !ignore_autogenerated
+// +build !ignore_autogenerated
+
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Code generated by deepcopy-gen. DO NOT EDIT.
+
+package v1beta1
+
+import (
+	runtime "k8s.io/apimachinery/pkg/runtime"
+)
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AzureApplicationGatewayClassParameters) DeepCopyInto(out *AzureApplicationGatewayClassParameters) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AzureApplicationGatewayClassParameters.
+func (in *AzureApplicationGatewayClassParameters) DeepCopy() *AzureApplicationGatewayClassParameters {
+	if in == nil {
+		return nil
+	}
+	out := new(AzureApplicationGatewayClassParameters)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *AzureApplicationGatewayClassParameters) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AzureApplicationGatewayClassParametersList) DeepCopyInto(out *AzureApplicationGatewayClassParametersList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]AzureApplicationGatewayClassParameters, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AzureApplicationGatewayClassParametersList.
+func (in *AzureApplicationGatewayClassParametersList) DeepCopy() *AzureApplicationGatewayClassParametersList {
+	if in == nil {
+		return nil
+	}
+	out := new(AzureApplicationGatewayClassParametersList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *AzureApplicationGatewayClassParametersList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AzureApplicationGatewayClassParametersSpec) DeepCopyInto(out *AzureApplicationGatewayClassParametersSpec) {
+	*out = *in
+	if in.AnnotationDefaults != nil {
+		in, out := &in.AnnotationDefaults, &out.AnnotationDefaults
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AzureApplicationGatewayClassParametersSpec.
+func (in *AzureApplicationGatewayClassParametersSpec) DeepCopy() *AzureApplicationGatewayClassParametersSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(AzureApplicationGatewayClassParametersSpec)
+	in.DeepCopyInto(out)
+	return out
+}
@@ -0,0 +1,76 @@
+// -------------------------------------------------------------------------------------------
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License. See License.txt in the project root for license information.
+// --------------------------------------------------------------------------------------------
+
+package v1beta1
+
+import (
+	v1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// MatchTypeExact matches values equal to the value of the match
+	MatchTypeExact = "Exact"
+
+	// MatchTypeRegularExpression matches values matching the regular expression of the match
+	MatchTypeRegularExpression = "RegularExpression"
+)
+
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// AzureApplicationGatewayRouteMatch is the resource AGIC is watching on for requests routed by header, query parameter or cookie
+type AzureApplicationGatewayRouteMatch struct {
+	metav1.TypeMeta `json:",inline"`
+
+	// +optional
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+	// +optional
+	Spec AzureApplicationGatewayRouteMatchSpec `json:"spec"`
+}
+
+// AzureApplicationGatewayRouteMatchSpec defines a list of route match rules
+type AzureApplicationGatewayRouteMatchSpec struct {
+	// Rules include a list of route match rules; The first rule matching a request routes it
+	Rules []RouteMatchRule `json:"rules,omitempty"`
+}
+
+// RouteMatchRule routes the requests matching all of its headers, query parameters and cookies to a backend service
+type RouteMatchRule struct {
+	// Name of the route match rule
+	Name string `json:"name,omitempty"`
+
+	// Headers the requests must match
+	Headers []ValueMatch `json:"headers,omitempty"`
+
+	// QueryParams the requests must match
+	QueryParams []ValueMatch `json:"queryParams,omitempty"`
+
+	// Cookies the requests must match
+	Cookies []ValueMatch `json:"cookies,omitempty"`
+
+	// Backend the matching requests are routed to
+	Backend v1.IngressBackend `json:"backend,omitempty"`
+}
+
+// ValueMatch defines the name of a header, query parameter or cookie and the value it must match
+type ValueMatch struct {
+	Name string `json:"name,omitempty"`
+
+	// Type is either Exact, the default, or RegularExpression
+	Type string `json:"type,omitempty"`
+
+	Value string `json:"value,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// AzureApplicationGatewayRouteMatchList is the list of route matches
+type AzureApplicationGatewayRouteMatchList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata"`
+
+	Items []AzureApplicationGatewayRouteMatch `json:"items"`
+}
@@ -22,6 +22,7 @@ import (
 	"k8s.io/client-go/kubernetes"
 	testclient "k8s.io/client-go/kubernetes/fake"
 	"k8s.io/client-go/tools/record"
+	gateway_fake "sigs.k8s.io/gateway-api/pkg/client/clientset/versioned/fake"
 
 	"github.com/Azure/application-gateway-kubernetes-ingress/pkg/annotations"
 	"github.com/Azure/application-gateway-kubernetes-ingress/pkg/azure/tags"
@@ -30,6 +31,7 @@ import (
 	istio_fake "github.com/Azure/application-gateway-kubernetes-ingress/pkg/crd_client/istio_crd_client/clientset/versioned/fake"
 	"github.com/Azure/application-gateway-kubernetes-ingress/pkg/environment"
 	"github.com/Azure/application-gateway-kubernetes-ingress/pkg/k8scontext"
+	"github.com/Azure/application-gateway-kubernetes-ingress/pkg/k8scontext/convert"
 	"github.com/Azure/application-gateway-kubernetes-ingress/pkg/metricstore"
 	"github.com/Azure/application-gateway-kubernetes-ingress/pkg/tests"
 	"github.com/Azure/application-gateway-kubernetes-ingress/pkg/tests/mocks"
@@ -427,7 +429,7 @@ var _ = Describe("Tests `appgw.ConfigBuilder`", func() {
 		Ω(err).ToNot(HaveOccurred(), "Unable to create service resource due to: %v", err)
 
 		// Create the endpoints associated with this service.
-		_, err = k8sClient.CoreV1().Endpoints(ingressNS).Create(context.TODO(), endpoints, metav1.CreateOptions{})
+		err = createEndpointsFixture(k8sClient, endpoints)
 		Ω(err).ToNot(HaveOccurred(), "Unable to create endpoints resource due to: %v", err)
 
 		// Create the pods associated with this service.
@@ -445,7 +447,7 @@ var _ = Describe("Tests `appgw.ConfigBuilder`", func() {
 
 		// Create a `k8scontext` to start listiening to ingress resources.
 		k8scontext.IsNetworkingV1PackageSupported = true
-		ctxt = k8scontext.NewContext(k8sClient, crdClient, multiClusterCrdClient, istioCrdClient, []string{ingressNS}, 1000*time.Second, metricstore.NewFakeMetricStore(), environment.GetFakeEnv())
+		ctxt = k8scontext.NewContext(k8sClient, crdClient, multiClusterCrdClient, istioCrdClient, gateway_fake.NewSimpleClientset(), []string{ingressNS}, 1000*time.Second, metricstore.NewFakeMetricStore(), environment.GetFakeEnv())
 		Expect(ctxt).ShouldNot(BeNil(), "Unable to create `k8scontext`")
 
 		// Initialize the `ConfigBuilder`
@@ -510,9 +512,11 @@ var _ = Describe("Tests `appgw.ConfigBuilder`", func() {
 			err := k8sClient.CoreV1().Services(ingressNS).Delete(ctx, serviceName, options)
 			Ω(err).ToNot(HaveOccurred(), "Unable to delete service resource due to: %v", err)
 
-			// Delete the Endpoint
-			err = k8sClient.CoreV1().Endpoints(ingressNS).Delete(ctx, serviceName, options)
-			Ω(err).ToNot(HaveOccurred(), "Unable to delete endpoint resource due to: %v", err)
+			// Delete the EndpointSlices
+			for _, endpointSlice := range convert.ToEndpointSlices(endpoints) {
+				err = k8sClient.DiscoveryV1().EndpointSlices(ingressNS).Delete(ctx, endpointSlice.Name, options)
+				Ω(err).ToNot(HaveOccurred(), "Unable to delete endpoint slice resource due to: %v", err)
+			}
 
 			// Start the informers. This will sync the cache with the latest ingress.
 			err = ctxt.Run(stopChannel, true, environment.GetFakeEnv())
@@ -8,13 +8,18 @@ package appgw
 import (
 	"fmt"
 	"sort"
+	"time"
 
+	"github.com/Azure/application-gateway-kubernetes-ingress/pkg/annotations"
 	"github.com/Azure/application-gateway-kubernetes-ingress/pkg/brownfield"
 	"github.com/Azure/application-gateway-kubernetes-ingress/pkg/events"
 	"github.com/Azure/application-gateway-kubernetes-ingress/pkg/sorter"
 	n "github.com/Azure/azure-sdk-for-go/services/network/mgmt/2021-03-01/network"
 	"github.com/Azure/go-autorest/autorest/to"
 	v1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	networking "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
 	"k8s.io/klog/v2"
 )
 
@@ -42,12 +47,21 @@ func (c appGwConfigBuilder) getPools(cbCtx *ConfigBuilderContext) []n.Applicatio
 		klog.Error("Error fetching Backends and Settings: ", err)
 	}
 	for backendID, serviceBackendPair := range serviceBackendPairMap {
-		if pool := c.getBackendAddressPool(backendID, serviceBackendPair, managedPoolsByName); pool != nil {
+		if pool := c.getBackendAddressPool(cbCtx, backendID, serviceBackendPair, managedPoolsByName); pool != nil {
 			managedPoolsByName[*pool.Name] = pool
 			klog.V(3).Infof("Created backend pool %s for service %s", *pool.Name, backendID.serviceKey())
 		}
 	}
 
+	for _, ldp := range c.getIngressLoadDistributionPolicies(cbCtx) {
+		for _, pool := range ldp.pools {
+			if _, exists := managedPoolsByName[*pool.Name]; !exists {
+				managedPoolsByName[*pool.Name] = pool
+				klog.V(3).Infof("Created backend pool %s for load distribution policy %s", *pool.Name, *ldp.policy.Name)
+			}
+		}
+	}
+
 	if cbCtx.EnvVariables.EnableIstioIntegration {
 		_, _, istioServiceBackendPairMap, _ := c.getIstioDestinationsAndSettingsMap(cbCtx)
 		for destinationID, serviceBackendPair := range istioServiceBackendPairMap {
@@ -58,6 +72,16 @@ func (c appGwConfigBuilder) getPools(cbCtx *ConfigBuilderContext) []n.Applicatio
 		}
 	}
 
+	if cbCtx.EnvVariables.EnableGatewayAPI {
+		for poolName, pool := range c.getGatewayAPIConfig(cbCtx).pools {
+			if _, exists := managedPoolsByName[poolName]; !exists {
+				pool := pool
+				managedPoolsByName[poolName] = &pool
+				klog.V(3).Infof("Created backend pool %s for Gateway API routes", poolName)
+			}
+		}
+	}
+
 	var agicCreatedPools []n.ApplicationGatewayBackendAddressPool
 	for _, managedPool := range managedPoolsByName {
 		agicCreatedPools = append(agicCreatedPools, *managedPool)
@@ -89,85 +113,114 @@ func (c *appGwConfigBuilder) newBackendPoolMap(cbCtx *ConfigBuilderContext) map[
 	_, _, serviceBackendPairMap, _ := c.getBackendsAndSettingsMap(cbCtx)
 	for backendID, serviceBackendPair := range serviceBackendPairMap {
 		backendPoolMap[backendID] = &defaultPool
-		if pool := c.getBackendAddressPool(backendID, serviceBackendPair, addressPools); pool != nil {
+		if pool := c.getBackendAddressPool(cbCtx, backendID, serviceBackendPair, addressPools); pool != nil {
 			backendPoolMap[backendID] = pool
 		}
 	}
 	return backendPoolMap
 }
 
-func (c *appGwConfigBuilder) getBackendAddressPool(backendID backendIdentifier, serviceBackendPair serviceBackendPortPair, addressPools map[string]*n.ApplicationGatewayBackendAddressPool) *n.ApplicationGatewayBackendAddressPool {
-	endpoints, err := c.k8sContext.GetEndpointsByService(backendID.serviceKey())
+func (c *appGwConfigBuilder) getBackendAddressPool(cbCtx *ConfigBuilderContext, backendID backendIdentifier, serviceBackendPair serviceBackendPortPair, addressPools map[string]*n.ApplicationGatewayBackendAddressPool) *n.ApplicationGatewayBackendAddressPool {
+	endpointSlices, err := c.k8sContext.GetEndpointSlicesByService(backendID.serviceKey())
 	if err != nil {
 		klog.Error(err.Error())
 		c.recorder.Event(backendID.Ingress, v1.EventTypeWarning, events.ReasonEndpointsEmpty, err.Error())
 		return nil
 	}
 
-	for _, subset := range endpoints.Subsets {
-		if _, portExists := getUniqueTCPPorts(subset)[serviceBackendPair.BackendPort]; portExists {
-			poolName := generateAddressPoolName(backendID.serviceFullName(), serviceBackendPortToStr(backendID.Backend.Service.Port), serviceBackendPair.BackendPort)
-			// The same service might be referenced in multiple ingress resources, this might result in multiple `serviceBackendPairMap` having the same service key but different
-			// ingress resource. Thus, while generating the backend address pool, we should make sure that we are generating unique backend address pools.
-			if pool, ok := addressPools[poolName]; ok {
-				return pool
-			}
-			return c.newPool(poolName, subset)
-		}
+	endpointSlices = endpointSlicesWithPort(endpointSlices, serviceBackendPair.BackendPort)
+	if len(endpointSlices) == 0 {
 		logLine := fmt.Sprintf("Backend target port %d does not have matching endpoint port", serviceBackendPair.BackendPort)
 		klog.Error(logLine)
 		c.recorder.Event(backendID.Ingress, v1.EventTypeWarning, events.ReasonBackendPortTargetMatch, logLine)
+		return nil
 	}
-	return nil
+
+	poolName := generateAddressPoolName(backendID.serviceFullName(), serviceBackendPortToStr(backendID.Backend.Service.Port), serviceBackendPair.BackendPort)
+	// The same service might be referenced in multiple ingress resources, this might result in multiple `serviceBackendPairMap` having the same service key but different
+	// ingress resource. Thus, while generating the backend address pool, we should make sure that we are generating unique backend address pools.
+	if pool, ok := addressPools[poolName]; ok {
+		return pool
+	}
+	return c.newPool(poolName, endpointSlices, c.getEndpointFilter(cbCtx, backendID))
+}
+
+// endpointSlicesWithPort returns the slices whose endpoints expose the TCP port.
+// The endpoints of a service may expose a named target port on different numbers, e.g. during a rollout, in which case they are in different slices.
+func endpointSlicesWithPort(endpointSlices []*discoveryv1.EndpointSlice, port Port) []*discoveryv1.EndpointSlice {
+	var withPort []*discoveryv1.EndpointSlice
+	for _, endpointSlice := range endpointSlices {
+		if _, portExists := getUniqueTCPPorts(endpointSlice)[port]; portExists {
+			withPort = append(withPort, endpointSlice)
+		}
+	}
+	return withPort
 }
 
-func getUniqueTCPPorts(subset v1.EndpointSubset) map[Port]interface{} {
+func getUniqueTCPPorts(endpointSlice *discoveryv1.EndpointSlice) map[Port]interface{} {
 	ports := make(map[Port]interface{})
-	for _, endpointsPort := range subset.Ports {
-		if endpointsPort.Protocol == v1.ProtocolTCP {
-			ports[Port(endpointsPort.Port)] = nil
+	for _, endpointPort := range endpointSlice.Ports {
+		// The protocol of an EndpointPort defaults to TCP.
+		if endpointPort.Port != nil && (endpointPort.Protocol == nil || *endpointPort.Protocol == v1.ProtocolTCP) {
+			ports[Port(*endpointPort.Port)] = nil
 		}
 	}
 	return ports
 }
 
-func (c *appGwConfigBuilder) newPool(poolName string, subset v1.EndpointSubset) *n.ApplicationGatewayBackendAddressPool {
+// resolveEndpointSlicesPortName looks up the port numbers the endpoints expose under the port name.
+func resolveEndpointSlicesPortName(endpointSlices []*discoveryv1.EndpointSlice, portName string) map[Port]interface{} {
+	resolvedPorts := make(map[Port]interface{})
+	for _, endpointSlice := range endpointSlices {
+		for _, endpointPort := range endpointSlice.Ports {
+			if endpointPort.Name != nil && *endpointPort.Name == portName && endpointPort.Port != nil {
+				resolvedPorts[Port(*endpointPort.Port)] = nil
+			}
+		}
+	}
+	return resolvedPorts
+}
+
+// endpointFilter tells whether App Gateway should send requests to an endpoint.
+type endpointFilter func(endpoint discoveryv1.Endpoint) bool
+
+func (c *appGwConfigBuilder) newPool(poolName string, endpointSlices []*discoveryv1.EndpointSlice, inPool endpointFilter) *n.ApplicationGatewayBackendAddressPool {
 	return &n.ApplicationGatewayBackendAddressPool{
 		Etag: to.StringPtr("*"),
 		Name: &poolName,
 		ID:   to.StringPtr(c.appGwIdentifier.AddressPoolID(poolName)),
 		ApplicationGatewayBackendAddressPoolPropertiesFormat: &n.ApplicationGatewayBackendAddressPoolPropertiesFormat{
-			BackendAddresses: getAddressesForSubset(subset),
+			BackendAddresses: getAddressesForEndpointSlices(endpointSlices, inPool),
 		},
 	}
 }
 
-func getAddressesForSubset(subset v1.EndpointSubset) *[]n.ApplicationGatewayBackendAddress {
+func getAddressesForEndpointSlices(endpointSlices []*discoveryv1.EndpointSlice, inPool endpointFilter) *[]n.ApplicationGatewayBackendAddress {
 	// We make separate maps for IP and FQDN to ensure uniqueness within the 2 groups
 	// We cannot use ApplicationGatewayBackendAddress as it contains pointer to strings and the same IP string
 	// at a different address would be 2 unique keys.
 	addrSet := make(map[n.ApplicationGatewayBackendAddress]interface{})
 	ips := make(map[string]interface{})
 	fqdns := make(map[string]interface{})
-	for _, address := range subset.Addresses {
-		// prefer IP address
-		if len(address.IP) != 0 {
-			// address specified by ip
-			ips[address.IP] = nil
-		} else if len(address.Hostname) != 0 {
-			// address specified by hostname
-			fqdns[address.Hostname] = nil
+	for _, endpointSlice := range endpointSlices {
+		if endpointSlice.AddressType == discoveryv1.AddressTypeIPv6 {
+			// App Gateway only reaches backends over IPv4; Dual-stack services also have an IPv4 slice.
+			klog.V(5).Infof("Skipping IPv6 endpoint slice %s/%s", endpointSlice.Namespace, endpointSlice.Name)
+			continue
 		}
-	}
-
-	for _, address := range subset.NotReadyAddresses {
-		// prefer IP address
-		if len(address.IP) != 0 {
-			// address specified by ip
-			ips[address.IP] = nil
-		} else if len(address.Hostname) != 0 {
-			// address specified by hostname
-			fqdns[address.Hostname] = nil
+		for _, endpoint := range endpointSlice.Endpoints {
+			if !inPool(endpoint) {
+				continue
+			}
+			for _, address := range endpoint.Addresses {
+				if endpointSlice.AddressType == discoveryv1.AddressTypeFQDN {
+					// address specified by hostname
+					fqdns[address] = nil
+				} else {
+					// address specified by ip
+					ips[address] = nil
+				}
+			}
 		}
 	}
 
@@ -180,6 +233,71 @@ func getAddressesForSubset(subset v1.EndpointSubset) *[]n.ApplicationGatewayBack
 	return getBackendAddressMapKeys(&addrSet)
 }
 
+// isEndpointInPool tells whether App Gateway should send requests to the endpoint.
+// Like the not ready addresses of Endpoints, endpoints which are not ready yet are added and left to the health probes of App Gateway.
+// Terminating endpoints are removed, unless the service publishes not ready addresses, which keeps them ready.
+// A nil condition is unknown and, as the API documents, interpreted as ready and not terminating.
+func isEndpointInPool(endpoint discoveryv1.Endpoint) bool {
+	ready := endpoint.Conditions.Ready == nil || *endpoint.Conditions.Ready
+	terminating := endpoint.Conditions.Terminating != nil && *endpoint.Conditions.Terminating
+	return ready || !terminating
+}
+
+// getEndpointFilter returns which endpoints of the backend App Gateway should send requests to.
+// When the Ingress enables connection draining, the endpoints of terminating pods which still serve are kept for the drain timeout,
+// so that requests in flight complete; cbCtx.DrainingUntil records when the first of them must be removed.
+func (c *appGwConfigBuilder) getEndpointFilter(cbCtx *ConfigBuilderContext, backendID backendIdentifier) endpointFilter {
+	drainTimeout := getConnectionDrainingTimeout(backendID.Ingress)
+	if drainTimeout == 0 {
+		return isEndpointInPool
+	}
+
+	now := c.clock.Now()
+	return func(endpoint discoveryv1.Endpoint) bool {
+		if isEndpointInPool(endpoint) {
+			return true
+		}
+
+		serving := endpoint.Conditions.Serving == nil || *endpoint.Conditions.Serving
+		if !serving || endpoint.TargetRef == nil || endpoint.TargetRef.Kind != "Pod" {
+			return false
+		}
+
+		pod := c.k8sContext.GetPod(fmt.Sprintf("%s/%s", endpoint.TargetRef.Namespace, endpoint.TargetRef.Name))
+		if pod == nil || pod.DeletionTimestamp == nil {
+			return false
+		}
+
+		// The deletion timestamp is when the grace period of the pod ends.
+		terminatingSince := pod.DeletionTimestamp.Time
+		if pod.DeletionGracePeriodSeconds != nil {
+			terminatingSince = terminatingSince.Add(-time.Duration(*pod.DeletionGracePeriodSeconds) * time.Second)
+		}
+		drainingUntil := terminatingSince.Add(drainTimeout)
+		if !now.Before(drainingUntil) {
+			return false
+		}
+
+		klog.V(5).Infof("Keeping terminating pod %s/%s in the backend pool for connection draining until %s", pod.Namespace, pod.Name, drainingUntil)
+		if cbCtx.DrainingUntil.IsZero() || drainingUntil.Before(cbCtx.DrainingUntil) {
+			cbCtx.DrainingUntil = drainingUntil
+		}
+		return true
+	}
+}
+
+// getConnectionDrainingTimeout returns the drain timeout of the backends of the Ingress; It is zero when connection draining is disabled.
+func getConnectionDrainingTimeout(ingress *networking.Ingress) time.Duration {
+	if isConnDrain, err := annotations.IsConnectionDraining(ingress); err != nil || !isConnDrain {
+		return 0
+	}
+	drainTimeout, err := annotations.ConnectionDrainingTimeout(ingress)
+	if err != nil {
+		drainTimeout = DefaultConnDrainTimeoutInSec
+	}
+	return time.Duration(drainTimeout) * time.Second
+}
+
 func getBackendAddressMapKeys(m *map[n.ApplicationGatewayBackendAddress]interface{}) *[]n.ApplicationGatewayBackendAddress {
 	var addresses []n.ApplicationGatewayBackendAddress
 	for addr := range *m {
@@ -188,3 +306,45 @@ func getBackendAddressMapKeys(m *map[n.ApplicationGatewayBackendAddress]interfac
 	sort.Sort(sorter.ByIPFQDN(addresses))
 	return &addresses
 }
+
+// PodBackendPoolNames returns the names of the backend pools which hold the endpoints of the pod behind the ports of the service.
+// A backend references a port of the service by its number or its name, or by its target port.
+func PodBackendPoolNames(service *v1.Service, pod *v1.Pod) []string {
+	serviceID := serviceIdentifier{Namespace: service.Namespace, Name: service.Name}
+	var poolNames []string
+	for _, servicePort := range service.Spec.Ports {
+		backendPort, resolved := resolvePodPort(servicePort, pod)
+		if !resolved {
+			continue
+		}
+		portRefs := []string{fmt.Sprint(servicePort.Port)}
+		if servicePort.Name != "" {
+			portRefs = append(portRefs, servicePort.Name)
+		}
+		if targetPort := servicePort.TargetPort.String(); targetPort != "" && targetPort != "0" {
+			portRefs = append(portRefs, targetPort)
+		}
+		for _, portRef := range portRefs {
+			poolNames = append(poolNames, generateAddressPoolName(serviceID.serviceFullName(), portRef, backendPort))
+		}
+	}
+	return poolNames
+}
+
+// resolvePodPort returns the port of the pod the service port targets; A named target port must be a container port of the pod.
+func resolvePodPort(servicePort v1.ServicePort, pod *v1.Pod) (Port, bool) {
+	if servicePort.TargetPort.Type == intstr.String && servicePort.TargetPort.StrVal != "" {
+		for _, container := range pod.Spec.Containers {
+			for _, containerPort := range container.Ports {
+				if containerPort.Name == servicePort.TargetPort.StrVal {
+					return Port(containerPort.ContainerPort), true
+				}
+			}
+		}
+		return 0, false
+	}
+	if servicePort.TargetPort.IntVal != 0 {
+		return Port(servicePort.TargetPort.IntVal), true
+	}
+	return Port(servicePort.Port), true
+}
@@ -6,35 +6,68 @@
 package appgw
 
 import (
+	"time"
+
 	n "github.com/Azure/azure-sdk-for-go/services/network/mgmt/2021-03-01/network"
 	"github.com/Azure/go-autorest/autorest/to"
-	"github.com/knative/pkg/apis/istio/v1alpha3"
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 	v1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
 	networking "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
 
+	"github.com/Azure/application-gateway-kubernetes-ingress/pkg/annotations"
 	"github.com/Azure/application-gateway-kubernetes-ingress/pkg/environment"
 	"github.com/Azure/application-gateway-kubernetes-ingress/pkg/utils"
 
 	"github.com/Azure/application-gateway-kubernetes-ingress/pkg/tests"
+	"github.com/Azure/application-gateway-kubernetes-ingress/pkg/tests/mocks"
 )
 
 // appgw_suite_test.go launches these Ginkgo tests
 
 var _ = Describe("Test the creation of Backend Pools from Ingress definition", func() {
-	subset := v1.EndpointSubset{
-		Addresses: []v1.EndpointAddress{
-			{Hostname: "abc"},
-			{IP: "1.1.1.1"},
-			{Hostname: "abc"},
-			{IP: "1.1.1.1"},
-			{Hostname: "xyz"},
-			{IP: "2.2.2.2"},
+	endpoint := func(ready bool, terminating bool, addresses ...string) discoveryv1.Endpoint {
+		return discoveryv1.Endpoint{
+			Addresses: addresses,
+			Conditions: discoveryv1.EndpointConditions{
+				Ready:       to.BoolPtr(ready),
+				Serving:     to.BoolPtr(ready || terminating),
+				Terminating: to.BoolPtr(terminating),
+			},
+		}
+	}
+
+	endpointSlices := []*discoveryv1.EndpointSlice{
+		{
+			AddressType: discoveryv1.AddressTypeIPv4,
+			Endpoints: []discoveryv1.Endpoint{
+				endpoint(true, false, "1.1.1.1"),
+				endpoint(true, false, "1.1.1.1"),
+				endpoint(true, false, "2.2.2.2"),
+				endpoint(false, false, "3.3.3.3"),
+				// Terminating endpoints are removed unless they are kept ready by publishNotReadyAddresses.
+				endpoint(false, true, "4.4.4.4"),
+				endpoint(true, true, "5.5.5.5"),
+			},
+		},
+		{
+			AddressType: discoveryv1.AddressTypeFQDN,
+			Endpoints: []discoveryv1.Endpoint{
+				endpoint(true, false, "abc"),
+				endpoint(true, false, "abc"),
+				endpoint(true, false, "xyz"),
+				endpoint(false, false, "pqr"),
+			},
 		},
-		NotReadyAddresses: []v1.EndpointAddress{
-			{Hostname: "pqr"},
-			{IP: "3.3.3.3"},
+		{
+			// The IPv6 slice of a dual-stack service.
+			AddressType: discoveryv1.AddressTypeIPv6,
+			Endpoints: []discoveryv1.Endpoint{
+				endpoint(true, false, "fd00::1"),
+			},
 		},
 	}
 
@@ -87,16 +120,16 @@ var _ = Describe("Test the creation of Backend Pools from Ingress definition", f
 			DefaultHTTPSettingsID: to.StringPtr("yy"),
 		}
 		_ = cb.BackendAddressPools(cbCtx)
-		actualPool := cb.newPool("pool-name", subset)
+		actualPool := cb.newPool("pool-name", endpointSlices, isEndpointInPool)
 		It("should contain unique addresses only", func() {
-			Expect(len(*actualPool.BackendAddresses)).To(Equal(6))
+			Expect(len(*actualPool.BackendAddresses)).To(Equal(7))
 		})
 	})
 
 	Context("ensure correct creation of ApplicationGatewayBackendAddress", func() {
-		actual := getAddressesForSubset(subset)
+		actual := getAddressesForEndpointSlices(endpointSlices, isEndpointInPool)
 		It("should contain correct number of ApplicationGatewayBackendAddress", func() {
-			Expect(len(*actual)).To(Equal(6))
+			Expect(len(*actual)).To(Equal(7))
 		})
 		It("should contain correct set of ordered ApplicationGatewayBackendAddress", func() {
 			// The order here is deliberate -- ensure this is properly sorted
@@ -104,6 +137,7 @@ var _ = Describe("Test the creation of Backend Pools from Ingress definition", f
 				{IPAddress: to.StringPtr("1.1.1.1")},
 				{IPAddress: to.StringPtr("2.2.2.2")},
 				{IPAddress: to.StringPtr("3.3.3.3")},
+				{IPAddress: to.StringPtr("5.5.5.5")},
 				{Fqdn: to.StringPtr("abc")},
 				{Fqdn: to.StringPtr("pqr")},
 				{Fqdn: to.StringPtr("xyz")},
@@ -127,7 +161,7 @@ var _ = Describe("Test the creation of Backend Pools from Ingress definition", f
 		_ = cb.BackendAddressPools(cbCtx)
 
 		endpoints := tests.NewEndpointsFixture()
-		_ = cb.k8sContext.Caches.Endpoints.Add(endpoints)
+		_ = addEndpointsFixture(cb.k8sContext, endpoints)
 
 		// TODO(draychev): Move to test fixtures
 		backendID := backendIdentifier{
@@ -150,7 +184,7 @@ var _ = Describe("Test the creation of Backend Pools from Ingress definition", f
 		}
 
 		// -- Action --
-		actual := cb.getBackendAddressPool(backendID, serviceBackendPair, addressPools)
+		actual := cb.getBackendAddressPool(cbCtx, backendID, serviceBackendPair, addressPools)
 
 		It("should have constructed correct ApplicationGatewayBackendAddressPool", func() {
 			// The order here is deliberate -- ensure this is properly sorted
@@ -174,11 +208,149 @@ var _ = Describe("Test the creation of Backend Pools from Ingress definition", f
 		})
 	})
 
+	Context("ensure the pool of a service spans its endpoint slices exposing the backend port", func() {
+		cb := newConfigBuilderFixture(nil)
+		newSlice := func(name string, port int32, ip string) *discoveryv1.EndpointSlice {
+			endpointSlice := &discoveryv1.EndpointSlice{
+				AddressType: discoveryv1.AddressTypeIPv4,
+				Endpoints:   []discoveryv1.Endpoint{endpoint(true, false, ip)},
+				Ports:       []discoveryv1.EndpointPort{{Port: to.Int32Ptr(port)}},
+			}
+			endpointSlice.Namespace = tests.Namespace
+			endpointSlice.Name = name
+			endpointSlice.Labels = map[string]string{discoveryv1.LabelServiceName: tests.ServiceName}
+			return endpointSlice
+		}
+		_ = cb.k8sContext.Caches.EndpointSlices.Add(newSlice("a", tests.ContainerPort, "10.0.0.1"))
+		_ = cb.k8sContext.Caches.EndpointSlices.Add(newSlice("b", tests.ContainerPort, "10.0.0.2"))
+		_ = cb.k8sContext.Caches.EndpointSlices.Add(newSlice("c", 8080, "10.0.0.3"))
+
+		backendID := backendIdentifier{
+			serviceIdentifier: serviceIdentifier{
+				Namespace: tests.Namespace,
+				Name:      tests.ServiceName,
+			},
+			Backend: tests.NewIngressBackendFixture(tests.ServiceName, int32(4321)),
+			Ingress: tests.NewIngressFixture(),
+		}
+
+		It("should only contain the endpoints exposing the backend port", func() {
+			pool := cb.getBackendAddressPool(&ConfigBuilderContext{}, backendID, serviceBackendPortPair{ServicePort: Port(4321), BackendPort: Port(tests.ContainerPort)}, map[string]*n.ApplicationGatewayBackendAddressPool{})
+			Expect(*pool.BackendAddresses).To(Equal([]n.ApplicationGatewayBackendAddress{
+				{IPAddress: to.StringPtr("10.0.0.1")},
+				{IPAddress: to.StringPtr("10.0.0.2")},
+			}))
+		})
+
+		It("should not create a pool when no endpoint exposes the backend port", func() {
+			pool := cb.getBackendAddressPool(&ConfigBuilderContext{}, backendID, serviceBackendPortPair{ServicePort: Port(4321), BackendPort: Port(9090)}, map[string]*n.ApplicationGatewayBackendAddressPool{})
+			Expect(pool).To(BeNil())
+		})
+	})
+
+	Context("ensure terminating endpoints are kept for connection draining", func() {
+		var cb appGwConfigBuilder
+		var cbCtx *ConfigBuilderContext
+		var backendID backendIdentifier
+		now := mocks.Clock{}.Now()
+
+		newPod := func(name string, terminatingSince time.Duration) {
+			pod := tests.NewPodFixture(name, tests.Namespace, "container", tests.ContainerPort)
+			deletionTimestamp := metav1.NewTime(now.Add(30*time.Second - terminatingSince))
+			pod.DeletionTimestamp = &deletionTimestamp
+			pod.DeletionGracePeriodSeconds = to.Int64Ptr(30)
+			_ = cb.k8sContext.Caches.Pods.Add(pod)
+		}
+
+		podEndpoint := func(podName string, ready bool, serving bool, terminating bool, ip string) discoveryv1.Endpoint {
+			e := endpoint(ready, terminating, ip)
+			e.Conditions.Serving = to.BoolPtr(serving)
+			e.TargetRef = &v1.ObjectReference{Kind: "Pod", Namespace: tests.Namespace, Name: podName}
+			return e
+		}
+
+		BeforeEach(func() {
+			cb = newConfigBuilderFixture(nil)
+			cb.clock = mocks.Clock{}
+			cbCtx = &ConfigBuilderContext{}
+
+			newPod("draining", 10*time.Second)
+			newPod("drained", 40*time.Second)
+			newPod("stopped", 10*time.Second)
+			endpointSlice := &discoveryv1.EndpointSlice{
+				AddressType: discoveryv1.AddressTypeIPv4,
+				Endpoints: []discoveryv1.Endpoint{
+					podEndpoint("ready", true, true, false, "10.0.0.1"),
+					podEndpoint("draining", false, true, true, "10.0.0.2"),
+					podEndpoint("drained", false, true, true, "10.0.0.3"),
+					podEndpoint("stopped", false, false, true, "10.0.0.4"),
+				},
+				Ports: []discoveryv1.EndpointPort{{Port: to.Int32Ptr(tests.ContainerPort)}},
+			}
+			endpointSlice.Namespace = tests.Namespace
+			endpointSlice.Name = tests.ServiceName
+			endpointSlice.Labels = map[string]string{discoveryv1.LabelServiceName: tests.ServiceName}
+			_ = cb.k8sContext.Caches.EndpointSlices.Add(endpointSlice)
+
+			backendID = backendIdentifier{
+				serviceIdentifier: serviceIdentifier{
+					Namespace: tests.Namespace,
+					Name:      tests.ServiceName,
+				},
+				Backend: tests.NewIngressBackendFixture(tests.ServiceName, int32(4321)),
+				Ingress: tests.NewIngressFixture(),
+			}
+		})
+
+		It("should keep serving endpoints of pods terminating for less than the drain timeout", func() {
+			backendID.Ingress.Annotations[annotations.ConnectionDrainingKey] = "true"
+			backendID.Ingress.Annotations[annotations.ConnectionDrainingTimeoutKey] = "30"
+
+			pool := cb.getBackendAddressPool(cbCtx, backendID, serviceBackendPortPair{ServicePort: Port(4321), BackendPort: Port(tests.ContainerPort)}, map[string]*n.ApplicationGatewayBackendAddressPool{})
+			Expect(*pool.BackendAddresses).To(Equal([]n.ApplicationGatewayBackendAddress{
+				{IPAddress: to.StringPtr("10.0.0.1")},
+				{IPAddress: to.StringPtr("10.0.0.2")},
+			}))
+			Expect(cbCtx.DrainingUntil).To(Equal(now.Add(20 * time.Second)))
+		})
+
+		It("should remove terminating endpoints when connection draining is disabled", func() {
+			pool := cb.getBackendAddressPool(cbCtx, backendID, serviceBackendPortPair{ServicePort: Port(4321), BackendPort: Port(tests.ContainerPort)}, map[string]*n.ApplicationGatewayBackendAddressPool{})
+			Expect(*pool.BackendAddresses).To(Equal([]n.ApplicationGatewayBackendAddress{
+				{IPAddress: to.StringPtr("10.0.0.1")},
+			}))
+			Expect(cbCtx.DrainingUntil.IsZero()).To(BeTrue())
+		})
+	})
+
+	Context("ensure the pool names of a pod follow the ports of its service", func() {
+		service := &v1.Service{
+			ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "test-ns"},
+			Spec: v1.ServiceSpec{
+				Ports: []v1.ServicePort{
+					{Name: "http", Port: 80, TargetPort: intstr.FromString("web")},
+					{Port: 443, TargetPort: intstr.FromString("missing")},
+				},
+			},
+		}
+		pod := &v1.Pod{
+			Spec: v1.PodSpec{
+				Containers: []v1.Container{{Ports: []v1.ContainerPort{{Name: "web", ContainerPort: 8080}}}},
+			},
+		}
+
+		It("should name the pools after the service port number, name and target port", func() {
+			Expect(PodBackendPoolNames(service, pod)).To(Equal([]string{
+				"pool-test-ns-web-80-bp-8080",
+				"pool-test-ns-web-http-bp-8080",
+				"pool-test-ns-web-web-bp-8080",
+			}))
+		})
+	})
+
 	Context("Test Istio components", func() {
 		cb := newConfigBuilderFixture(nil)
 		istioDest := istioDestinationIdentifier{}
-		istioGateways := []*v1alpha3.Gateway{}
-		istioVirtualServices := []*v1alpha3.VirtualService{}
 
 		destinationID := istioDestinationIdentifier{}
 		serviceBackendPair := serviceBackendPortPair{}
@@ -191,7 +363,7 @@ var _ = Describe("Test the creation of Backend Pools from Ingress definition", f
 		})
 
 		It("Should get listener config from istio", func() {
-			actual := cb.getListenerConfigsFromIstio(istioGateways, istioVirtualServices, environment.GetFakeEnv())
+			actual := cb.getListenerConfigsFromIstio(&ConfigBuilderContext{EnvVariables: environment.GetFakeEnv()})
 			expected := map[listenerIdentifier]listenerAzConfig{
 				{FrontendPort: 80, FrontendType: FrontendTypePublic}: {
 					Protocol:                     "Http",
@@ -51,6 +51,15 @@ func (c *appGwConfigBuilder) BackendHTTPSettingsCollection(cbCtx *ConfigBuilderC
 		agicHTTPSettings = append(agicHTTPSettings, istioHTTPSettings...)
 	}
 
+	agicHTTPSettings = append(agicHTTPSettings, c.getCanaryConfig(cbCtx).settings...)
+	agicHTTPSettings = append(agicHTTPSettings, c.getRouteMatchConfig(cbCtx).settings...)
+
+	if cbCtx.EnvVariables.EnableGatewayAPI {
+		for _, settings := range c.getGatewayAPIConfig(cbCtx).settings {
+			agicHTTPSettings = append(agicHTTPSettings, settings)
+		}
+	}
+
 	if agicHTTPSettings != nil {
 		sort.Sort(sorter.BySettingsName(agicHTTPSettings))
 	}
@@ -40,7 +40,7 @@ var _ = Describe("Test the creation of Backend http settings from Ingress defini
 	// Ingress "--name--" contains two rules with service port as 80 and 443
 	ingress := tests.NewIngressFixture()
 	_ = configBuilder.k8sContext.Caches.Pods.Add(&pod)
-	_ = configBuilder.k8sContext.Caches.Endpoints.Add(endpoint)
+	_ = addEndpointsFixture(configBuilder.k8sContext, endpoint)
 	_ = configBuilder.k8sContext.Caches.Service.Add(service)
 	_ = configBuilder.k8sContext.Caches.Ingress.Add(ingress)
 
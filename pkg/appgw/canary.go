@@ -0,0 +1,268 @@
+// -------------------------------------------------------------------------------------------
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License. See License.txt in the project root for license information.
+// --------------------------------------------------------------------------------------------
+
+package appgw
+
+import (
+	"fmt"
+	"regexp"
+
+	n "github.com/Azure/azure-sdk-for-go/services/network/mgmt/2021-03-01/network"
+	"github.com/Azure/go-autorest/autorest/to"
+	v1 "k8s.io/api/core/v1"
+	networking "k8s.io/api/networking/v1"
+	"k8s.io/klog/v2"
+
+	"github.com/Azure/application-gateway-kubernetes-ingress/pkg/annotations"
+	"github.com/Azure/application-gateway-kubernetes-ingress/pkg/controllererrors"
+	"github.com/Azure/application-gateway-kubernetes-ingress/pkg/events"
+)
+
+const (
+	// canaryPathMarker prefixes the path of the requests rerouted to the canary backend.
+	canaryPathMarker = "/agic-canary"
+
+	// primaryPathMarker prefixes the path of the requests rerouted to the primary backend, bypassing the canary weight.
+	primaryPathMarker = "/agic-primary"
+
+	canaryAlways = "always"
+	canaryNever  = "never"
+)
+
+// canaryConfig is the App Gateway config merging canary Ingresses into the Ingresses they shadow.
+type canaryConfig struct {
+	// routes are keyed by the backend of the primary Ingress the canary shadows.
+	routes                   map[backendIdentifier]*canaryRoute
+	settings                 []n.ApplicationGatewayBackendHTTPSettings
+	rewriteRuleSets          []n.ApplicationGatewayRewriteRuleSet
+	loadDistributionPolicies []n.ApplicationGatewayLoadDistributionPolicy
+}
+
+// canaryRoute tells how the path of the primary Ingress sends traffic to the canary.
+type canaryRoute struct {
+	// loadDistributionPolicy splits the traffic of the path between the primary and the canary backend.
+	loadDistributionPolicy *n.SubResource
+
+	// backendAddressPool replaces the pool of the primary backend when the canary receives all the traffic.
+	backendAddressPool *n.SubResource
+
+	// rewriteRuleSet reroutes the requests matching the canary header or cookie to the marker path rules.
+	rewriteRuleSet *n.SubResource
+	markers        []rerouteMarker
+}
+
+// isCanaryIngress tells whether the Ingress is the canary of another Ingress.
+func isCanaryIngress(ingress *networking.Ingress) bool {
+	isCanary, _ := annotations.IsCanary(ingress)
+	return isCanary
+}
+
+// getCanaryConfig pairs each path of the canary Ingresses with the path of the Ingress with the same host and path,
+// and translates the canary annotations into a load distribution policy, for weights, and a rewrite rule set rerouting requests, for headers and cookies.
+func (c *appGwConfigBuilder) getCanaryConfig(cbCtx *ConfigBuilderContext) *canaryConfig {
+	if c.mem.canary != nil {
+		return c.mem.canary
+	}
+
+	config := &canaryConfig{routes: make(map[backendIdentifier]*canaryRoute)}
+	for _, ingress := range cbCtx.IngressList {
+		if !isCanaryIngress(ingress) {
+			continue
+		}
+
+		for ruleIdx := range ingress.Spec.Rules {
+			rule := &ingress.Spec.Rules[ruleIdx]
+			if rule.HTTP == nil {
+				continue
+			}
+
+			for pathIdx := range rule.HTTP.Paths {
+				path := &rule.HTTP.Paths[pathIdx]
+				primaryID, exists := findCanaryPrimary(cbCtx, rule, path)
+				if !exists {
+					c.canaryEvent(ingress, "Canary Ingress %s/%s is not applied to host %q and path %q: no Ingress with the same host and path to shadow",
+						ingress.Namespace, ingress.Name, rule.Host, path.Path)
+					continue
+				}
+				if _, exists := config.routes[primaryID]; exists {
+					c.canaryEvent(ingress, "Canary Ingress %s/%s is not applied to host %q and path %q: Ingress %s/%s already has a canary for this path",
+						ingress.Namespace, ingress.Name, rule.Host, path.Path, primaryID.Ingress.Namespace, primaryID.Ingress.Name)
+					continue
+				}
+
+				canaryID := generateBackendID(ingress, rule, path, &path.Backend)
+				if route := c.newCanaryRoute(cbCtx, config, primaryID, canaryID, ruleIdx, pathIdx); route != nil {
+					config.routes[primaryID] = route
+					klog.V(3).Infof("Merged canary Ingress %s/%s into Ingress %s/%s for host %q and path %q",
+						ingress.Namespace, ingress.Name, primaryID.Ingress.Namespace, primaryID.Ingress.Name, rule.Host, path.Path)
+				}
+			}
+		}
+	}
+
+	c.mem.canary = config
+	return config
+}
+
+// findCanaryPrimary returns the backend of the Ingress, which is not a canary, with the host and path of the canary.
+func findCanaryPrimary(cbCtx *ConfigBuilderContext, canaryRule *networking.IngressRule, canaryPath *networking.HTTPIngressPath) (backendIdentifier, bool) {
+	for _, ingress := range cbCtx.IngressList {
+		if isCanaryIngress(ingress) {
+			continue
+		}
+		for ruleIdx := range ingress.Spec.Rules {
+			rule := &ingress.Spec.Rules[ruleIdx]
+			if rule.Host != canaryRule.Host || rule.HTTP == nil {
+				continue
+			}
+			for pathIdx := range rule.HTTP.Paths {
+				path := &rule.HTTP.Paths[pathIdx]
+				if appGwPathRulePath(path) == appGwPathRulePath(canaryPath) {
+					return generateBackendID(ingress, rule, path, &path.Backend), true
+				}
+			}
+		}
+	}
+	return backendIdentifier{}, false
+}
+
+func (c *appGwConfigBuilder) newCanaryRoute(cbCtx *ConfigBuilderContext, config *canaryConfig, primaryID, canaryID backendIdentifier, ruleIdx, pathIdx int) *canaryRoute {
+	canary := canaryID.Ingress
+	backendPools := c.newBackendPoolMap(cbCtx)
+	_, backendHTTPSettingsMap, serviceBackendPairMap, _ := c.getBackendsAndSettingsMap(cbCtx)
+	primaryPool, canaryPool := backendPools[primaryID], backendPools[canaryID]
+	primarySettings, canarySettings := backendHTTPSettingsMap[primaryID], backendHTTPSettingsMap[canaryID]
+	if primaryPool == nil || canaryPool == nil || primarySettings == nil || canarySettings == nil {
+		klog.Errorf("Canary Ingress %s/%s is not applied to service %s: backend pools or http settings are missing", canary.Namespace, canary.Name, canaryID.serviceKey())
+		return nil
+	}
+
+	route := &canaryRoute{}
+	weight, total, err := annotations.CanaryWeight(canary)
+	if err != nil && !controllererrors.IsErrorCode(err, controllererrors.ErrorMissingAnnotation) {
+		c.recorder.Event(canary, v1.EventTypeWarning, events.ReasonInvalidAnnotation, err.Error())
+	} else if weight > 0 {
+		// App Gateway forwards the traffic of both pools with the HTTP settings of the primary backend.
+		primaryPort, canaryPort := serviceBackendPairMap[primaryID].BackendPort, serviceBackendPairMap[canaryID].BackendPort
+		if primaryPort != canaryPort {
+			c.canaryEvent(canary, "Canary weight of Ingress %s/%s is not applied: Application Gateway can only split traffic between backends listening on the same port, but service %s listens on port %d and service %s on port %d",
+				canary.Namespace, canary.Name, primaryID.serviceKey(), primaryPort, canaryID.serviceKey(), canaryPort)
+		} else if weight == total {
+			route.backendAddressPool = resourceRef(*canaryPool.ID)
+		} else {
+			policyName := generateCanaryName(prefixLoadDistributionPolicy, canary.Namespace, canary.Name, ruleIdx, pathIdx)
+			pools := []weightedPool{
+				{pool: primaryPool, weight: total - weight},
+				{pool: canaryPool, weight: weight},
+			}
+			if policy := c.newLoadDistributionPolicy(policyName, pools); policy != nil {
+				config.loadDistributionPolicies = append(config.loadDistributionPolicies, *policy)
+				route.loadDistributionPolicy = resourceRef(*policy.ID)
+			} else {
+				klog.V(3).Infof("Canary weight of Ingress %s/%s is not applied: service %s or %s has no endpoints", canary.Namespace, canary.Name, primaryID.serviceKey(), canaryID.serviceKey())
+			}
+		}
+	}
+	weighted := route.loadDistributionPolicy != nil || route.backendAddressPool != nil
+
+	rewriteRules := newCanaryRewriteRules(canary, weighted)
+	if len(rewriteRules) == 0 {
+		return route
+	}
+
+	if ruleSet, _ := annotations.RewriteRuleSet(primaryID.Ingress); ruleSet != "" {
+		c.canaryEvent(canary, "Canary header and cookie of Ingress %s/%s are not applied: Ingress %s/%s already uses rewrite rule set %s",
+			canary.Namespace, canary.Name, primaryID.Ingress.Namespace, primaryID.Ingress.Name, ruleSet)
+		return route
+	}
+	if ruleSet, _ := annotations.RewriteRuleSetCustomResource(primaryID.Ingress); ruleSet != "" {
+		c.canaryEvent(canary, "Canary header and cookie of Ingress %s/%s are not applied: Ingress %s/%s already uses rewrite rule set custom resource %s",
+			canary.Namespace, canary.Name, primaryID.Ingress.Namespace, primaryID.Ingress.Name, ruleSet)
+		return route
+	}
+
+	ruleSetName := generateCanaryName(prefixRewriteRuleSet, canary.Namespace, canary.Name, ruleIdx, pathIdx)
+	ruleSet := n.ApplicationGatewayRewriteRuleSet{
+		Name: to.StringPtr(ruleSetName),
+		ID:   to.StringPtr(c.appGwIdentifier.rewriteRuleSetID(ruleSetName)),
+		ApplicationGatewayRewriteRuleSetPropertiesFormat: &n.ApplicationGatewayRewriteRuleSetPropertiesFormat{
+			RewriteRules: &rewriteRules,
+		},
+	}
+	config.rewriteRuleSets = append(config.rewriteRuleSets, ruleSet)
+	route.rewriteRuleSet = resourceRef(*ruleSet.ID)
+
+	settingsName := generateCanaryName(prefixHTTPSettings, canary.Namespace, canary.Name, ruleIdx, pathIdx)
+	marker, settings := c.newRerouteMarker("canary", canaryPathMarker, primaryID.Path, canaryPool, canarySettings, settingsName)
+	config.settings = append(config.settings, settings)
+	route.markers = append(route.markers, marker)
+
+	if weighted {
+		marker, settings := c.newRerouteMarker("primary", primaryPathMarker, primaryID.Path, primaryPool, primarySettings, formatPropName(settingsName+"-primary"))
+		config.settings = append(config.settings, settings)
+		route.markers = append(route.markers, marker)
+	}
+
+	return route
+}
+
+// newCanaryRewriteRules translates the header and cookie of the canary into rewrite rules rerouting the requests to the marker path rules.
+// As with ingress-nginx, the header takes precedence over the cookie; The requests reroute to the primary marker only when the canary weight applies.
+func newCanaryRewriteRules(canary *networking.Ingress, weighted bool) []n.ApplicationGatewayRewriteRule {
+	var rewriteRules []n.ApplicationGatewayRewriteRule
+	newRule := func(name string, conditions []n.ApplicationGatewayRewriteRuleCondition, marker string) {
+		rewriteRules = append(rewriteRules, newRerouteRule(name, int32(100*(len(rewriteRules)+1)), conditions, marker))
+	}
+	newCondition := newRerouteCondition
+
+	// headerConditions match the requests the header decides about.
+	var headerConditions []n.ApplicationGatewayRewriteRuleCondition
+	if header, _ := annotations.CanaryByHeader(canary); header != "" {
+		variable := headerVariable(header)
+		if value, _ := annotations.CanaryByHeaderValue(canary); value != "" {
+			headerConditions = append(headerConditions, newCondition(variable, "^"+regexp.QuoteMeta(value)+"$", false))
+			newRule("canary-by-header", headerConditions, canaryPathMarker)
+		} else {
+			newRule("canary-by-header", []n.ApplicationGatewayRewriteRuleCondition{newCondition(variable, "^"+canaryAlways+"$", false)}, canaryPathMarker)
+			if weighted {
+				newRule("primary-by-header", []n.ApplicationGatewayRewriteRuleCondition{newCondition(variable, "^"+canaryNever+"$", false)}, primaryPathMarker)
+			}
+			headerConditions = append(headerConditions, newCondition(variable, "^("+canaryAlways+"|"+canaryNever+")$", false))
+		}
+	}
+
+	if cookie, _ := annotations.CanaryByCookie(canary); cookie != "" {
+		cookieCondition := func(value string) []n.ApplicationGatewayRewriteRuleCondition {
+			conditions := []n.ApplicationGatewayRewriteRuleCondition{
+				newCondition(cookieVariable, cookiePattern(cookie, value), false),
+			}
+			for _, condition := range headerConditions {
+				conditions = append(conditions, newCondition(*condition.Variable, *condition.Pattern, true))
+			}
+			return conditions
+		}
+		newRule("canary-by-cookie", cookieCondition(canaryAlways), canaryPathMarker)
+		if weighted {
+			newRule("primary-by-cookie", cookieCondition(canaryNever), primaryPathMarker)
+		}
+	}
+
+	return rewriteRules
+}
+
+// getCanaryPathRules returns the marker path rules of the canary of the backend.
+func (c *appGwConfigBuilder) getCanaryPathRules(cbCtx *ConfigBuilderContext, listenerID listenerIdentifier, pathRuleName string, backendID backendIdentifier) []n.ApplicationGatewayPathRule {
+	route, exists := c.getCanaryConfig(cbCtx).routes[backendID]
+	if !exists {
+		return nil
+	}
+	return c.newReroutePathRules(listenerID, pathRuleName, route.markers)
+}
+
+func (c *appGwConfigBuilder) canaryEvent(canary *networking.Ingress, format string, args ...interface{}) {
+	message := fmt.Sprintf(format, args...)
+	klog.Warning(message)
+	c.recorder.Event(canary, v1.EventTypeWarning, events.ReasonInvalidCanary, message)
+}
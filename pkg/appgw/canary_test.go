@@ -0,0 +1,340 @@
+// -------------------------------------------------------------------------------------------
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License. See License.txt in the project root for license information.
+// --------------------------------------------------------------------------------------------
+
+package appgw
+
+import (
+	"context"
+	"time"
+
+	n "github.com/Azure/azure-sdk-for-go/services/network/mgmt/2021-03-01/network"
+	"github.com/Azure/go-autorest/autorest/to"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	v1 "k8s.io/api/core/v1"
+	networking "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	testclient "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/record"
+	gateway_fake "sigs.k8s.io/gateway-api/pkg/client/clientset/versioned/fake"
+
+	"github.com/Azure/application-gateway-kubernetes-ingress/pkg/annotations"
+	"github.com/Azure/application-gateway-kubernetes-ingress/pkg/crd_client/agic_crd_client/clientset/versioned/fake"
+	multiCluster_fake "github.com/Azure/application-gateway-kubernetes-ingress/pkg/crd_client/azure_multicluster_crd_client/clientset/versioned/fake"
+	istio_fake "github.com/Azure/application-gateway-kubernetes-ingress/pkg/crd_client/istio_crd_client/clientset/versioned/fake"
+	"github.com/Azure/application-gateway-kubernetes-ingress/pkg/environment"
+	"github.com/Azure/application-gateway-kubernetes-ingress/pkg/events"
+	"github.com/Azure/application-gateway-kubernetes-ingress/pkg/k8scontext"
+	"github.com/Azure/application-gateway-kubernetes-ingress/pkg/metricstore"
+	"github.com/Azure/application-gateway-kubernetes-ingress/pkg/tests"
+	"github.com/Azure/application-gateway-kubernetes-ingress/pkg/tests/mocks"
+)
+
+var _ = Describe("Test canary Ingress translation", func() {
+	namespace := "web"
+
+	newService := func(name string, targetPort int32, ips ...string) (*v1.Service, *v1.Endpoints) {
+		service := &v1.Service{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+			Spec: v1.ServiceSpec{
+				Ports: []v1.ServicePort{{
+					Protocol:   v1.ProtocolTCP,
+					Port:       80,
+					TargetPort: intstr.FromInt(int(targetPort)),
+				}},
+			},
+		}
+		var addresses []v1.EndpointAddress
+		for _, ip := range ips {
+			addresses = append(addresses, v1.EndpointAddress{IP: ip})
+		}
+		endpoints := &v1.Endpoints{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+			Subsets: []v1.EndpointSubset{{
+				Addresses: addresses,
+				Ports:     []v1.EndpointPort{{Protocol: v1.ProtocolTCP, Port: targetPort}},
+			}},
+		}
+		return service, endpoints
+	}
+
+	newPath := func(path string, service string) networking.HTTPIngressPath {
+		pathType := networking.PathTypePrefix
+		return networking.HTTPIngressPath{
+			Path:     path,
+			PathType: &pathType,
+			Backend: networking.IngressBackend{
+				Service: &networking.IngressServiceBackend{
+					Name: service,
+					Port: networking.ServiceBackendPort{Number: 80},
+				},
+			},
+		}
+	}
+
+	newIngress := func(name string, ingressAnnotations map[string]string, paths ...networking.HTTPIngressPath) *networking.Ingress {
+		return &networking.Ingress{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        name,
+				Namespace:   namespace,
+				Annotations: ingressAnnotations,
+			},
+			Spec: networking.IngressSpec{
+				Rules: []networking.IngressRule{{
+					Host: "web.contoso.com",
+					IngressRuleValue: networking.IngressRuleValue{
+						HTTP: &networking.HTTPIngressRuleValue{Paths: paths},
+					},
+				}},
+			},
+		}
+	}
+
+	var cb *appGwConfigBuilder
+	var cbCtx *ConfigBuilderContext
+	var recorder *record.FakeRecorder
+	var primary *networking.Ingress
+
+	BeforeEach(func() {
+		k8sClient := testclient.NewSimpleClientset()
+		for _, s := range []struct {
+			name       string
+			targetPort int32
+			ips        []string
+		}{
+			{"web-v1", 8080, []string{"10.0.0.1", "10.0.0.2"}},
+			{"web-v2", 8080, []string{"10.0.0.3"}},
+			{"web-v3", 9090, []string{"10.0.0.4"}},
+		} {
+			service, endpoints := newService(s.name, s.targetPort, s.ips...)
+			_, _ = k8sClient.CoreV1().Services(namespace).Create(context.TODO(), service, metav1.CreateOptions{})
+			_ = createEndpointsFixture(k8sClient, endpoints)
+		}
+
+		k8scontext.IsNetworkingV1PackageSupported = true
+		ctxt := k8scontext.NewContext(k8sClient, fake.NewSimpleClientset(), multiCluster_fake.NewSimpleClientset(), istio_fake.NewSimpleClientset(), gateway_fake.NewSimpleClientset(), []string{namespace}, 1000*time.Second, metricstore.NewFakeMetricStore(), environment.GetFakeEnv())
+		Expect(ctxt.Run(make(chan struct{}), true, environment.GetFakeEnv())).To(Succeed())
+
+		appGw := &n.ApplicationGateway{ApplicationGatewayPropertiesFormat: NewAppGwyConfigFixture()}
+		recorder = record.NewFakeRecorder(100)
+		cb = NewConfigBuilder(ctxt, &Identifier{
+			SubscriptionID: tests.Subscription,
+			ResourceGroup:  tests.ResourceGroup,
+			AppGwName:      tests.AppGwName,
+		}, appGw, recorder, mocks.Clock{}).(*appGwConfigBuilder)
+
+		primary = newIngress("web", nil, newPath("/", "web-v1"), newPath("/api", "web-v1"))
+		cbCtx = &ConfigBuilderContext{
+			EnvVariables:          environment.GetFakeEnv(),
+			IngressList:           []*networking.Ingress{primary},
+			DefaultAddressPoolID:  to.StringPtr(cb.appGwIdentifier.AddressPoolID(DefaultBackendAddressPoolName)),
+			DefaultHTTPSettingsID: to.StringPtr(cb.appGwIdentifier.HTTPSettingsID(DefaultBackendHTTPSettingsName)),
+		}
+	})
+
+	addCanary := func(canaryAnnotations map[string]string, paths ...networking.HTTPIngressPath) *networking.Ingress {
+		canaryAnnotations[annotations.CanaryKey] = "true"
+		canary := newIngress("web-canary", canaryAnnotations, paths...)
+		cbCtx.IngressList = append(cbCtx.IngressList, canary)
+		return canary
+	}
+
+	findPathMap := func(appGw *n.ApplicationGateway) *n.ApplicationGatewayURLPathMap {
+		Expect(*appGw.URLPathMaps).To(HaveLen(1))
+		return &(*appGw.URLPathMaps)[0]
+	}
+
+	findPathRule := func(pathMap *n.ApplicationGatewayURLPathMap, name string) n.ApplicationGatewayPathRule {
+		for _, rule := range *pathMap.PathRules {
+			if *rule.Name == name {
+				return rule
+			}
+		}
+		Fail("path rule not found: " + name)
+		return n.ApplicationGatewayPathRule{}
+	}
+
+	findSettings := func(appGw *n.ApplicationGateway, id string) n.ApplicationGatewayBackendHTTPSettings {
+		for _, settings := range *appGw.BackendHTTPSettingsCollection {
+			if *settings.ID == id {
+				return settings
+			}
+		}
+		Fail("http settings not found: " + id)
+		return n.ApplicationGatewayBackendHTTPSettings{}
+	}
+
+	ruleNames := func(ruleSet n.ApplicationGatewayRewriteRuleSet) []string {
+		var names []string
+		for _, rule := range *ruleSet.RewriteRules {
+			names = append(names, *rule.Name)
+		}
+		return names
+	}
+
+	warnings := func() []string {
+		var received []string
+		for len(recorder.Events) > 0 {
+			received = append(received, <-recorder.Events)
+		}
+		return received
+	}
+
+	pathRuleName := generatePathRuleName(namespace, "web", 0, 1)
+
+	Context("ensure a canary Ingress is merged into the Ingress it shadows", func() {
+		It("splits the traffic of the path according to the canary weight", func() {
+			addCanary(map[string]string{annotations.CanaryWeightKey: "20"}, newPath("/api", "web-v2"))
+
+			appGw, err := cb.Build(cbCtx)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(*appGw.HTTPListeners).To(HaveLen(1))
+			Expect(*appGw.LoadDistributionPolicies).To(HaveLen(1))
+			policy := (*appGw.LoadDistributionPolicies)[0]
+			Expect(*policy.Name).To(Equal(generateCanaryName(prefixLoadDistributionPolicy, namespace, "web-canary", 0, 0)))
+			targets := *policy.LoadDistributionTargets
+			Expect(targets).To(HaveLen(2))
+			// web-v1 weighs 80 over 2 endpoints, web-v2 weighs 20 over 1 endpoint.
+			Expect(*targets[0].WeightPerServer).To(Equal(int32(100)))
+			Expect(*targets[0].BackendAddressPool.ID).To(HaveSuffix("/backendAddressPools/" + generateAddressPoolName(namespace+"-web-v1", "80", 8080)))
+			Expect(*targets[1].WeightPerServer).To(Equal(int32(50)))
+			Expect(*targets[1].BackendAddressPool.ID).To(HaveSuffix("/backendAddressPools/" + generateAddressPoolName(namespace+"-web-v2", "80", 8080)))
+
+			pathMap := findPathMap(appGw)
+			Expect(*pathMap.PathRules).To(HaveLen(1))
+			Expect(*findPathRule(pathMap, pathRuleName).LoadDistributionPolicy.ID).To(Equal(*policy.ID))
+			Expect(pathMap.DefaultLoadDistributionPolicy).To(BeNil())
+			Expect(*appGw.RewriteRuleSets).To(BeEmpty())
+		})
+
+		It("sends all the traffic to the canary when its weight is the total", func() {
+			addCanary(map[string]string{annotations.CanaryWeightKey: "5", annotations.CanaryWeightTotalKey: "5"}, newPath("/api", "web-v2"))
+
+			appGw, err := cb.Build(cbCtx)
+			Expect(err).ToNot(HaveOccurred())
+
+			pathRule := findPathRule(findPathMap(appGw), pathRuleName)
+			Expect(*pathRule.BackendAddressPool.ID).To(HaveSuffix("/backendAddressPools/" + generateAddressPoolName(namespace+"-web-v2", "80", 8080)))
+			Expect(pathRule.LoadDistributionPolicy).To(BeNil())
+		})
+
+		It("reroutes the requests with the canary header or cookie", func() {
+			addCanary(map[string]string{
+				annotations.CanaryWeightKey:   "20",
+				annotations.CanaryByHeaderKey: "X-Canary",
+				annotations.CanaryByCookieKey: "canary",
+			}, newPath("/api", "web-v2"))
+
+			appGw, err := cb.Build(cbCtx)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(*appGw.RewriteRuleSets).To(HaveLen(1))
+			ruleSet := (*appGw.RewriteRuleSets)[0]
+			Expect(*ruleSet.Name).To(Equal(generateCanaryName(prefixRewriteRuleSet, namespace, "web-canary", 0, 0)))
+			Expect(ruleNames(ruleSet)).To(Equal([]string{"canary-by-header", "primary-by-header", "canary-by-cookie", "primary-by-cookie"}))
+
+			byHeader := (*ruleSet.RewriteRules)[0]
+			Expect(*(*byHeader.Conditions)[0].Variable).To(Equal("http_req_X-Canary"))
+			Expect(*(*byHeader.Conditions)[0].Pattern).To(Equal("^always$"))
+			Expect(*byHeader.ActionSet.URLConfiguration.ModifiedPath).To(Equal(canaryPathMarker + "{var_uri_path}"))
+			Expect(*byHeader.ActionSet.URLConfiguration.Reroute).To(BeTrue())
+
+			// The header takes precedence over the cookie.
+			byCookie := (*ruleSet.RewriteRules)[2]
+			Expect(*byCookie.Conditions).To(HaveLen(2))
+			Expect(*(*byCookie.Conditions)[0].Variable).To(Equal("http_req_Cookie"))
+			Expect(*(*byCookie.Conditions)[1].Pattern).To(Equal("^(always|never)$"))
+			Expect(*(*byCookie.Conditions)[1].Negate).To(BeTrue())
+
+			pathMap := findPathMap(appGw)
+			Expect(*pathMap.PathRules).To(HaveLen(3))
+			Expect(*findPathRule(pathMap, pathRuleName).RewriteRuleSet.ID).To(Equal(*ruleSet.ID))
+
+			canaryRule := findPathRule(pathMap, pathRuleName+"-canary")
+			Expect(*canaryRule.Paths).To(Equal([]string{canaryPathMarker + "/api*"}))
+			Expect(*canaryRule.BackendAddressPool.ID).To(HaveSuffix("/backendAddressPools/" + generateAddressPoolName(namespace+"-web-v2", "80", 8080)))
+			Expect(*findSettings(appGw, *canaryRule.BackendHTTPSettings.ID).Path).To(Equal("/api"))
+
+			primaryRule := findPathRule(pathMap, pathRuleName+"-primary")
+			Expect(*primaryRule.Paths).To(Equal([]string{primaryPathMarker + "/api*"}))
+			Expect(*primaryRule.BackendAddressPool.ID).To(HaveSuffix("/backendAddressPools/" + generateAddressPoolName(namespace+"-web-v1", "80", 8080)))
+			Expect(primaryRule.LoadDistributionPolicy).To(BeNil())
+		})
+
+		It("matches the canary header value and reroutes the default backend", func() {
+			addCanary(map[string]string{
+				annotations.CanaryByHeaderKey:      "X-Canary",
+				annotations.CanaryByHeaderValueKey: "blue.green",
+				annotations.CanaryByCookieKey:      "canary",
+			}, newPath("/", "web-v2"))
+
+			appGw, err := cb.Build(cbCtx)
+			Expect(err).ToNot(HaveOccurred())
+
+			ruleSet := (*appGw.RewriteRuleSets)[0]
+			Expect(ruleNames(ruleSet)).To(Equal([]string{"canary-by-header", "canary-by-cookie"}))
+			Expect(*(*(*ruleSet.RewriteRules)[0].Conditions)[0].Pattern).To(Equal(`^blue\.green$`))
+
+			pathMap := findPathMap(appGw)
+			Expect(*pathMap.DefaultRewriteRuleSet.ID).To(Equal(*ruleSet.ID))
+			canaryRule := findPathRule(pathMap, generatePathRuleName(namespace, "web", 0, 0)+"-canary")
+			Expect(*canaryRule.Paths).To(Equal([]string{canaryPathMarker + "/*"}))
+			Expect(*findSettings(appGw, *canaryRule.BackendHTTPSettings.ID).Path).To(Equal("/"))
+		})
+
+		It("removes the rewrite rule sets of stale canaries only", func() {
+			addCanary(map[string]string{annotations.CanaryByHeaderKey: "X-Canary"}, newPath("/api", "web-v2"))
+			cb.appGw.RewriteRuleSets = &[]n.ApplicationGatewayRewriteRuleSet{
+				{Name: to.StringPtr("manual")},
+				{Name: to.StringPtr(generateCanaryName(prefixRewriteRuleSet, namespace, "stale", 0, 0))},
+			}
+
+			appGw, err := cb.Build(cbCtx)
+			Expect(err).ToNot(HaveOccurred())
+
+			var names []string
+			for _, ruleSet := range *appGw.RewriteRuleSets {
+				names = append(names, *ruleSet.Name)
+			}
+			Expect(names).To(ConsistOf("manual", generateCanaryName(prefixRewriteRuleSet, namespace, "web-canary", 0, 0)))
+		})
+	})
+
+	Context("ensure canaries App Gateway cannot express are reported", func() {
+		It("warns when no Ingress has the host and path of the canary", func() {
+			addCanary(map[string]string{annotations.CanaryWeightKey: "20"}, newPath("/missing", "web-v2"))
+
+			appGw, err := cb.Build(cbCtx)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(appGw.LoadDistributionPolicies).To(BeNil())
+			Expect(warnings()).To(ContainElement(And(ContainSubstring(events.ReasonInvalidCanary), ContainSubstring("no Ingress with the same host and path"))))
+		})
+
+		It("does not split traffic between backends listening on different ports", func() {
+			addCanary(map[string]string{annotations.CanaryWeightKey: "20"}, newPath("/api", "web-v3"))
+
+			appGw, err := cb.Build(cbCtx)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(appGw.LoadDistributionPolicies).To(BeNil())
+			Expect(warnings()).To(ContainElement(ContainSubstring("same port")))
+		})
+
+		It("does not replace the rewrite rule set of the primary Ingress", func() {
+			primary.Annotations = map[string]string{annotations.RewriteRuleSetKey: "headers"}
+			addCanary(map[string]string{annotations.CanaryByHeaderKey: "X-Canary"}, newPath("/api", "web-v2"))
+
+			appGw, err := cb.Build(cbCtx)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(*findPathRule(findPathMap(appGw), pathRuleName).RewriteRuleSet.ID).To(HaveSuffix("/rewriteRuleSets/headers"))
+			Expect(*findPathMap(appGw).PathRules).To(HaveLen(1))
+			Expect(warnings()).To(ContainElement(And(ContainSubstring(events.ReasonInvalidCanary), ContainSubstring("rewrite rule set headers"))))
+		})
+	})
+})
@@ -33,6 +33,18 @@ func (c *appGwConfigBuilder) getSslCertificates(cbCtx *ConfigBuilderContext) *[]
 		}
 	}
 
+	if cbCtx.EnvVariables.EnableIstioIntegration {
+		for secretID, cert := range c.getIstioConfig(cbCtx).certificates {
+			secretIDCertificateMap[secretID] = cert
+		}
+	}
+
+	if cbCtx.EnvVariables.EnableGatewayAPI {
+		for secretID, cert := range c.getGatewayAPIConfig(cbCtx).certificates {
+			secretIDCertificateMap[secretID] = cert
+		}
+	}
+
 	sslCertificates := []n.ApplicationGatewaySslCertificate{}
 	for secretID, cert := range secretIDCertificateMap {
 		sslCertificates = append(sslCertificates, c.newCert(secretID, cert))
@@ -139,7 +151,7 @@ func (c *appGwConfigBuilder) newCert(secretID secretIdentifier, cert *string) n.
 		ID:   to.StringPtr(c.appGwIdentifier.sslCertificateID(sslCertName)),
 		ApplicationGatewaySslCertificatePropertiesFormat: &n.ApplicationGatewaySslCertificatePropertiesFormat{
 			Data:     cert,
-			Password: to.StringPtr("msazure"),
+			Password: to.StringPtr(c.k8sContext.CertificateSecretStore.GetPfxPassword(secretID.secretKey())),
 		},
 	}
 }
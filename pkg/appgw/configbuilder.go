@@ -17,6 +17,7 @@ import (
 	"k8s.io/klog/v2"
 
 	"github.com/Azure/application-gateway-kubernetes-ingress/pkg/annotations"
+	agroutematchv1beta1 "github.com/Azure/application-gateway-kubernetes-ingress/pkg/apis/azureapplicationgatewayroutematch/v1beta1"
 	"github.com/Azure/application-gateway-kubernetes-ingress/pkg/azure"
 	"github.com/Azure/application-gateway-kubernetes-ingress/pkg/azure/tags"
 	"github.com/Azure/application-gateway-kubernetes-ingress/pkg/controllererrors"
@@ -54,6 +55,12 @@ type memoization struct {
 	certs                        *[]n.ApplicationGatewaySslCertificate
 	redirectConfigs              *[]n.ApplicationGatewayRedirectConfiguration
 	ports                        *[]n.ApplicationGatewayFrontendPort
+	gatewayAPI                   *gatewayAPIConfig
+	istio                        *istioConfig
+	loadDistributionPolicies     *map[string]*ingressLoadDistributionPolicy
+	canary                       *canaryConfig
+	routeMatches                 *map[string]*agroutematchv1beta1.AzureApplicationGatewayRouteMatch
+	routeMatch                   *routeMatchConfig
 }
 
 type appGwConfigBuilder struct {
@@ -162,6 +169,7 @@ type valFunc func(eventRecorder record.EventRecorder, config *n.ApplicationGatew
 
 // PreBuildValidate runs all the validators that suggest misconfiguration in Kubernetes resources.
 func (c *appGwConfigBuilder) PreBuildValidate(cbCtx *ConfigBuilderContext) error {
+	trackMissingServices(cbCtx)
 
 	validationFunctions := []valFunc{
 		validateServiceDefinition,
@@ -189,25 +197,18 @@ func (c *appGwConfigBuilder) runValidationFunctions(cbCtx *ConfigBuilderContext,
 	return nil
 }
 
-// resolvePortName function goes through the endpoints of a given service and
+// resolvePortName function goes through the endpoint slices of a given service and
 // look for possible port number corresponding to a port name
 func (c *appGwConfigBuilder) resolvePortName(portName string, backendID *backendIdentifier) map[int32]interface{} {
 	resolvedPorts := make(map[int32]interface{})
-	endpoints, err := c.k8sContext.GetEndpointsByService(backendID.serviceKey())
+	endpointSlices, err := c.k8sContext.GetEndpointSlicesByService(backendID.serviceKey())
 	if err != nil {
-		klog.Error("Could not fetch endpoint by service key from cache", err)
+		klog.Error("Could not fetch endpoint slices by service key from cache", err)
 		return resolvedPorts
 	}
 
-	if endpoints == nil {
-		return resolvedPorts
-	}
-	for _, subset := range endpoints.Subsets {
-		for _, epPort := range subset.Ports {
-			if epPort.Name == portName {
-				resolvedPorts[epPort.Port] = nil
-			}
-		}
+	for port := range resolveEndpointSlicesPortName(endpointSlices, portName) {
+		resolvedPorts[int32(port)] = nil
 	}
 	return resolvedPorts
 }
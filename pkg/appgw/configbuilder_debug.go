@@ -9,18 +9,20 @@ import (
 	"fmt"
 	"strings"
 
-	v1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
 )
 
-func printEndpoints(endpoints v1.Endpoints) {
-	fmt.Printf("Endpoint [%s]\n", endpoints.Name)
-	for _, subset := range endpoints.Subsets {
-		ports := subset.Ports
+func printEndpointSlices(endpointSlices []*discoveryv1.EndpointSlice) {
+	for _, endpointSlice := range endpointSlices {
+		fmt.Printf("EndpointSlice [%s]\n", endpointSlice.Name)
+		ports := endpointSlice.Ports
 		tmp := make([]string, 0, len(ports))
 		for _, port := range ports {
-			tmp = append(tmp, port.Name)
+			if port.Name != nil {
+				tmp = append(tmp, *port.Name)
+			}
 		}
 		portsString := strings.Join(tmp, ",")
-		fmt.Printf(" - subset ports=[%s]\n", portsString)
+		fmt.Printf(" - ports=[%s]\n", portsString)
 	}
 }
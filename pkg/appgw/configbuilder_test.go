@@ -22,6 +22,7 @@ import (
 	"k8s.io/apimachinery/pkg/util/intstr"
 	testclient "k8s.io/client-go/kubernetes/fake"
 	"k8s.io/client-go/tools/record"
+	gateway_fake "sigs.k8s.io/gateway-api/pkg/client/clientset/versioned/fake"
 
 	"github.com/Azure/application-gateway-kubernetes-ingress/pkg/annotations"
 	"github.com/Azure/application-gateway-kubernetes-ingress/pkg/crd_client/agic_crd_client/clientset/versioned/fake"
@@ -197,14 +198,14 @@ var _ = Describe("Tests `appgw.ConfigBuilder`", func() {
 		_, _ = k8sClient.CoreV1().Nodes().Create(ctx, node, options)
 		_, _ = k8sClient.NetworkingV1().Ingresses(ingressNS).Create(ctx, ingress, options)
 		_, _ = k8sClient.CoreV1().Services(ingressNS).Create(ctx, service, options)
-		_, _ = k8sClient.CoreV1().Endpoints(ingressNS).Create(ctx, endpoints, options)
+		_ = createEndpointsFixture(k8sClient, endpoints)
 		_, _ = k8sClient.CoreV1().Pods(ingressNS).Create(ctx, pod, options)
 
 		crdClient := fake.NewSimpleClientset()
 		istioCrdClient := istio_fake.NewSimpleClientset()
 		multiClusterCrdClient := multiCluster_fake.NewSimpleClientset()
 		k8scontext.IsNetworkingV1PackageSupported = true
-		ctxt = k8scontext.NewContext(k8sClient, crdClient, multiClusterCrdClient, istioCrdClient, []string{ingressNS}, 1000*time.Second, metricstore.NewFakeMetricStore(), environment.GetFakeEnv())
+		ctxt = k8scontext.NewContext(k8sClient, crdClient, multiClusterCrdClient, istioCrdClient, gateway_fake.NewSimpleClientset(), []string{ingressNS}, 1000*time.Second, metricstore.NewFakeMetricStore(), environment.GetFakeEnv())
 
 		appGwy := &n.ApplicationGateway{
 			ApplicationGatewayPropertiesFormat: NewAppGwyConfigFixture(),
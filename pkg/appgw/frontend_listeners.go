@@ -113,6 +113,10 @@ func (c *appGwConfigBuilder) getListenerConfigs(cbCtx *ConfigBuilderContext) map
 	// TODO(draychev): Emit an error event if 2 namespaces define different TLS for the same domain!
 	allListeners := make(map[listenerIdentifier]listenerAzConfig)
 	for _, ingress := range cbCtx.IngressList {
+		// Canaries share the listeners of the Ingresses they shadow.
+		if isCanaryIngress(ingress) {
+			continue
+		}
 		klog.V(3).Infof("Processing Rules for Ingress: %s/%s", ingress.Namespace, ingress.Name)
 		azListenerConfigs := c.getListenersFromIngress(ingress, cbCtx.EnvVariables)
 		for listenerID, azConfig := range azListenerConfigs {
@@ -120,6 +124,15 @@ func (c *appGwConfigBuilder) getListenerConfigs(cbCtx *ConfigBuilderContext) map
 		}
 	}
 
+	if cbCtx.EnvVariables.EnableGatewayAPI {
+		// Listeners of Ingresses take precedence; Conflicting Gateway listeners are not created.
+		for listenerID, azConfig := range c.getGatewayAPIConfig(cbCtx).listenerConfigs {
+			if _, exists := allListeners[listenerID]; !exists {
+				allListeners[listenerID] = azConfig
+			}
+		}
+	}
+
 	// App Gateway must have at least one listener - the default one!
 	if len(allListeners) == 0 {
 		listenerConfig := listenerAzConfig{
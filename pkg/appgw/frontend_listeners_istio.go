@@ -15,13 +15,18 @@ func (c *appGwConfigBuilder) getIstioListenersPorts(cbCtx *ConfigBuilderContext)
 	var listeners []n.ApplicationGatewayHTTPListener
 
 	if cbCtx.EnvVariables.EnableIstioIntegration {
-		for listenerID, config := range c.getListenerConfigsFromIstio(cbCtx.IstioGateways, cbCtx.IstioVirtualServices, cbCtx.EnvVariables) {
+		for listenerID, config := range c.getListenerConfigsFromIstio(cbCtx) {
 			listener, port, err := c.newListener(cbCtx, listenerID, config.Protocol, portsByNumber)
 			if err != nil {
 				klog.Errorf("Failed creating listener %+v: %s", listenerID, err)
 				continue
 			}
 
+			if config.Protocol == n.ApplicationGatewayProtocolHTTPS {
+				sslCertificateID := c.appGwIdentifier.sslCertificateID(config.Secret.secretFullName())
+				listener.SslCertificate = resourceRef(sslCertificateID)
+			}
+
 			listeners = append(listeners, *listener)
 			if _, exists := portsByNumber[Port(*port.Port)]; !exists {
 				portsByNumber[Port(*port.Port)] = *port
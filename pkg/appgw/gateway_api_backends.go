@@ -0,0 +1,221 @@
+// -------------------------------------------------------------------------------------------
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License. See License.txt in the project root for license information.
+// --------------------------------------------------------------------------------------------
+
+package appgw
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	n "github.com/Azure/azure-sdk-for-go/services/network/mgmt/2021-03-01/network"
+	"github.com/Azure/go-autorest/autorest/to"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/klog/v2"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+
+	"github.com/Azure/application-gateway-kubernetes-ingress/pkg/gatewayapi"
+)
+
+// gatewayAPIBackend is where App Gateway sends the requests matching a rule of an HTTPRoute: either a pool or a redirect.
+// When the rule splits traffic, the load distribution policy spreads requests over the pools of its targets.
+type gatewayAPIBackend struct {
+	pool                   *n.SubResource
+	settings               *n.SubResource
+	loadDistributionPolicy *n.SubResource
+	redirect               *n.SubResource
+}
+
+// gatewayAPIServiceBackend is a backendRef of an HTTPRoute resolved to a Service port and the port of its endpoints.
+type gatewayAPIServiceBackend struct {
+	serviceIdentifier
+	ServicePort Port
+	BackendPort Port
+	Weight      int32
+}
+
+// getGatewayAPIBackend resolves the backendRefs of the rule into a pool and HTTP settings.
+// A rule without any usable backendRef is forwarded to the default backend, which has no servers.
+func (c *appGwConfigBuilder) getGatewayAPIBackend(cbCtx *ConfigBuilderContext, config *gatewayAPIConfig, route *gatewayv1.HTTPRoute, ruleIdx int, rule gatewayv1.HTTPRouteRule) gatewayAPIBackend {
+	defaultBackend := gatewayAPIBackend{
+		pool:     resourceRef(c.appGwIdentifier.AddressPoolID(DefaultBackendAddressPoolName)),
+		settings: resourceRef(c.appGwIdentifier.HTTPSettingsID(DefaultBackendHTTPSettingsName)),
+	}
+
+	var backends []gatewayAPIServiceBackend
+	for _, backendRef := range rule.BackendRefs {
+		weight := int32(1)
+		if backendRef.Weight != nil {
+			weight = *backendRef.Weight
+		}
+		if weight == 0 {
+			continue
+		}
+		backend, reason, message := c.resolveGatewayAPIBackendRef(route, backendRef.BackendObjectReference)
+		if reason != "" {
+			cbCtx.GatewayAPIStatus.RouteRefsNotResolved(route, reason, message)
+			continue
+		}
+		backend.Weight = weight
+		backends = append(backends, backend)
+	}
+	if len(backends) == 0 {
+		return defaultBackend
+	}
+
+	// The heaviest backend provides the HTTP settings and the pool App Gateway falls back to.
+	sort.SliceStable(backends, func(i, j int) bool {
+		return backends[i].Weight > backends[j].Weight
+	})
+	heaviest := backends[0]
+	settings := c.newGatewayAPIHTTPSettings(heaviest, route, rule)
+	config.settings[*settings.Name] = settings
+
+	backend := gatewayAPIBackend{
+		pool:     defaultBackend.pool,
+		settings: resourceRef(*settings.ID),
+	}
+	if pool := c.newGatewayAPIPool(heaviest); pool != nil {
+		config.pools[*pool.Name] = *pool
+		backend.pool = resourceRef(*pool.ID)
+	}
+	if len(backends) == 1 {
+		return backend
+	}
+
+	for _, other := range backends[1:] {
+		if other.BackendPort != heaviest.BackendPort {
+			cbCtx.GatewayAPIStatus.DropRouteRules(route, fmt.Sprintf(
+				"Application Gateway can only split traffic between backends listening on the same port; Rule %d forwards all traffic to Service %s", ruleIdx, heaviest.serviceKey()))
+			return backend
+		}
+	}
+
+	if policy := c.newGatewayAPILoadDistributionPolicy(config, route, ruleIdx, backends); policy != nil {
+		config.loadDistributionPolicies[*policy.Name] = *policy
+		backend.loadDistributionPolicy = resourceRef(*policy.ID)
+	}
+	return backend
+}
+
+// resolveGatewayAPIBackendRef resolves the backendRef to a port of a Service in the namespace of the route.
+// When it cannot be resolved, the reason and message tell why.
+func (c *appGwConfigBuilder) resolveGatewayAPIBackendRef(route *gatewayv1.HTTPRoute, ref gatewayv1.BackendObjectReference) (gatewayAPIServiceBackend, gatewayv1.RouteConditionReason, string) {
+	backend := gatewayAPIServiceBackend{
+		serviceIdentifier: serviceIdentifier{
+			Namespace: route.Namespace,
+			Name:      string(ref.Name),
+		},
+	}
+	if (ref.Group != nil && *ref.Group != "") || (ref.Kind != nil && *ref.Kind != gatewayapi.ServiceKind) {
+		return backend, gatewayv1.RouteReasonInvalidKind, fmt.Sprintf("backendRef %s is not a core Service", ref.Name)
+	}
+	if ref.Namespace != nil && string(*ref.Namespace) != route.Namespace {
+		return backend, gatewayv1.RouteReasonRefNotPermitted, fmt.Sprintf("Service %s/%s is not in the namespace of the HTTPRoute", *ref.Namespace, ref.Name)
+	}
+	if ref.Port == nil {
+		return backend, gatewayv1.RouteReasonUnsupportedValue, fmt.Sprintf("backendRef %s needs a port", ref.Name)
+	}
+
+	service := c.k8sContext.GetService(backend.serviceKey())
+	if service == nil {
+		return backend, gatewayv1.RouteReasonBackendNotFound, fmt.Sprintf("Service %s does not exist", backend.serviceKey())
+	}
+	backend.ServicePort = Port(*ref.Port)
+	backendPort, resolved := c.resolveGatewayAPIBackendPort(backend, service)
+	if !resolved {
+		return backend, gatewayv1.RouteReasonBackendNotFound, fmt.Sprintf("Service %s has no TCP port %d", backend.serviceKey(), backend.ServicePort)
+	}
+	backend.BackendPort = backendPort
+	return backend, "", ""
+}
+
+// resolveGatewayAPIBackendPort looks up the port of the endpoints behind the Service port; Named target ports are resolved through the endpoints.
+func (c *appGwConfigBuilder) resolveGatewayAPIBackendPort(backend gatewayAPIServiceBackend, service *v1.Service) (Port, bool) {
+	for _, servicePort := range service.Spec.Ports {
+		if servicePort.Protocol != v1.ProtocolTCP || Port(servicePort.Port) != backend.ServicePort {
+			continue
+		}
+		switch {
+		case servicePort.TargetPort.Type == intstr.Int && servicePort.TargetPort.IntVal == 0:
+			return Port(servicePort.Port), true
+		case servicePort.TargetPort.Type == intstr.Int:
+			return Port(servicePort.TargetPort.IntVal), true
+		}
+
+		endpointSlices, err := c.k8sContext.GetEndpointSlicesByService(backend.serviceKey())
+		if err != nil {
+			return 0, false
+		}
+		// When the endpoints expose the named port on several numbers, e.g. during a rollout, the lowest one is used.
+		resolved := Port(0)
+		for port := range resolveEndpointSlicesPortName(endpointSlices, servicePort.TargetPort.StrVal) {
+			if resolved == 0 || port < resolved {
+				resolved = port
+			}
+		}
+		return resolved, resolved != 0
+	}
+	return 0, false
+}
+
+// newGatewayAPIHTTPSettings creates the HTTP settings forwarding the requests of the route to the backend.
+func (c *appGwConfigBuilder) newGatewayAPIHTTPSettings(backend gatewayAPIServiceBackend, route *gatewayv1.HTTPRoute, rule gatewayv1.HTTPRouteRule) n.ApplicationGatewayBackendHTTPSettings {
+	requestTimeout := int32(30)
+	settingsSuffix := route.Name
+	if rule.Timeouts != nil && rule.Timeouts.Request != nil {
+		if timeout, err := time.ParseDuration(string(*rule.Timeouts.Request)); err == nil && timeout > 0 {
+			requestTimeout = int32(math.Max(1, math.Ceil(timeout.Seconds())))
+			settingsSuffix = fmt.Sprintf("%s-%ds", route.Name, requestTimeout)
+		}
+	}
+
+	httpSettingsName := generateHTTPSettingsName(backend.serviceFullName(), fmt.Sprint(backend.ServicePort), backend.BackendPort, settingsSuffix)
+	return n.ApplicationGatewayBackendHTTPSettings{
+		Etag: to.StringPtr("*"),
+		Name: &httpSettingsName,
+		ID:   to.StringPtr(c.appGwIdentifier.HTTPSettingsID(httpSettingsName)),
+		ApplicationGatewayBackendHTTPSettingsPropertiesFormat: &n.ApplicationGatewayBackendHTTPSettingsPropertiesFormat{
+			Protocol:                       n.ApplicationGatewayProtocolHTTP,
+			Port:                           to.Int32Ptr(int32(backend.BackendPort)),
+			PickHostNameFromBackendAddress: to.BoolPtr(false),
+			CookieBasedAffinity:            n.ApplicationGatewayCookieBasedAffinityDisabled,
+			RequestTimeout:                 to.Int32Ptr(requestTimeout),
+		},
+	}
+}
+
+// newGatewayAPIPool creates the pool of the endpoints of the backend; It returns nil when the Service has no endpoints on the backend port.
+func (c *appGwConfigBuilder) newGatewayAPIPool(backend gatewayAPIServiceBackend) *n.ApplicationGatewayBackendAddressPool {
+	endpointSlices, err := c.k8sContext.GetEndpointSlicesByService(backend.serviceKey())
+	if err != nil {
+		klog.Errorf("Failed fetching endpoints for service: %s", backend.serviceKey())
+		return nil
+	}
+
+	endpointSlices = endpointSlicesWithPort(endpointSlices, backend.BackendPort)
+	if len(endpointSlices) == 0 {
+		klog.Errorf("Backend target port %d of service %s does not have matching endpoint port", backend.BackendPort, backend.serviceKey())
+		return nil
+	}
+	poolName := generateAddressPoolName(backend.serviceFullName(), fmt.Sprint(backend.ServicePort), backend.BackendPort)
+	return c.newPool(poolName, endpointSlices, isEndpointInPool)
+}
+
+// newGatewayAPILoadDistributionPolicy creates the policy splitting the traffic of a rule between its backends according to their weights.
+func (c *appGwConfigBuilder) newGatewayAPILoadDistributionPolicy(config *gatewayAPIConfig, route *gatewayv1.HTTPRoute, ruleIdx int, backends []gatewayAPIServiceBackend) *n.ApplicationGatewayLoadDistributionPolicy {
+	var pools []weightedPool
+	for _, backend := range backends {
+		pool := c.newGatewayAPIPool(backend)
+		if pool == nil || pool.BackendAddresses == nil || len(*pool.BackendAddresses) == 0 {
+			continue
+		}
+		config.pools[*pool.Name] = *pool
+		pools = append(pools, weightedPool{pool: pool, weight: backend.Weight})
+	}
+	return c.newLoadDistributionPolicy(generateLoadDistributionPolicyName(route.Namespace, route.Name, ruleIdx), pools)
+}
@@ -0,0 +1,145 @@
+// -------------------------------------------------------------------------------------------
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License. See License.txt in the project root for license information.
+// --------------------------------------------------------------------------------------------
+
+package appgw
+
+import (
+	"encoding/base64"
+	"fmt"
+
+	n "github.com/Azure/azure-sdk-for-go/services/network/mgmt/2021-03-01/network"
+	"github.com/Azure/go-autorest/autorest/to"
+	"k8s.io/klog/v2"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+
+	"github.com/Azure/application-gateway-kubernetes-ingress/pkg/gatewayapi"
+)
+
+// gatewayAPIConfig is the App Gateway config translated from the Gateways and HTTPRoutes AGIC implements.
+type gatewayAPIConfig struct {
+	listenerConfigs          map[listenerIdentifier]listenerAzConfig
+	certificates             map[secretIdentifier]*string
+	pathMaps                 map[listenerIdentifier]*n.ApplicationGatewayURLPathMap
+	settings                 map[string]n.ApplicationGatewayBackendHTTPSettings
+	pools                    map[string]n.ApplicationGatewayBackendAddressPool
+	loadDistributionPolicies map[string]n.ApplicationGatewayLoadDistributionPolicy
+	redirects                map[string]n.ApplicationGatewayRedirectConfiguration
+
+	// listenerIDs caches the App Gateway listener of a hostname served by a Gateway listener; A nil value means it cannot be created.
+	listenerIDs map[gatewayAPIListenerKey]*listenerIdentifier
+
+	// catchAll holds the listeners whose default backend was already set by a route.
+	catchAll map[listenerIdentifier]interface{}
+
+	// paths holds the paths already routed on each listener; The oldest route wins.
+	paths map[listenerIdentifier]map[string]interface{}
+}
+
+type gatewayAPIListenerKey struct {
+	Namespace string
+	Gateway   string
+	Listener  gatewayv1.SectionName
+	Hostname  string
+}
+
+// getGatewayAPIConfig translates the Gateways and HTTPRoutes into listeners, path maps and backends.
+// The result is memoized, so that the outcome recorded on cbCtx.GatewayAPIStatus is computed once per event loop.
+func (c *appGwConfigBuilder) getGatewayAPIConfig(cbCtx *ConfigBuilderContext) *gatewayAPIConfig {
+	if c.mem.gatewayAPI != nil {
+		return c.mem.gatewayAPI
+	}
+
+	config := &gatewayAPIConfig{
+		listenerConfigs:          make(map[listenerIdentifier]listenerAzConfig),
+		certificates:             make(map[secretIdentifier]*string),
+		pathMaps:                 make(map[listenerIdentifier]*n.ApplicationGatewayURLPathMap),
+		settings:                 make(map[string]n.ApplicationGatewayBackendHTTPSettings),
+		pools:                    make(map[string]n.ApplicationGatewayBackendAddressPool),
+		loadDistributionPolicies: make(map[string]n.ApplicationGatewayLoadDistributionPolicy),
+		redirects:                make(map[string]n.ApplicationGatewayRedirectConfiguration),
+		listenerIDs:              make(map[gatewayAPIListenerKey]*listenerIdentifier),
+		catchAll:                 make(map[listenerIdentifier]interface{}),
+		paths:                    make(map[listenerIdentifier]map[string]interface{}),
+	}
+	c.mem.gatewayAPI = config
+
+	if !cbCtx.EnvVariables.EnableGatewayAPI {
+		return config
+	}
+
+	_, attachments := gatewayapi.Attach(cbCtx.GatewayAPIGateways, cbCtx.HTTPRoutes, cbCtx.GatewayAPIStatus)
+	ingressListeners := make(map[listenerIdentifier]listenerAzConfig)
+	for _, ingress := range cbCtx.IngressList {
+		for listenerID, azConfig := range c.getListenersFromIngress(ingress, cbCtx.EnvVariables) {
+			ingressListeners[listenerID] = azConfig
+		}
+	}
+
+	for _, attachment := range attachments {
+		c.addGatewayAPIRoute(cbCtx, config, attachment, ingressListeners)
+	}
+
+	return config
+}
+
+// getGatewayAPIListener returns the App Gateway listener serving the hostname on the Gateway listener, creating its config on first use.
+// It returns nil when App Gateway cannot serve the hostname there; The reason is recorded on the Gateway listener.
+func (c *appGwConfigBuilder) getGatewayAPIListener(cbCtx *ConfigBuilderContext, config *gatewayAPIConfig, listener gatewayapi.Listener, hostname string, ingressListeners map[listenerIdentifier]listenerAzConfig) *listenerIdentifier {
+	key := gatewayAPIListenerKey{
+		Namespace: listener.Gateway.Namespace,
+		Gateway:   listener.Gateway.Name,
+		Listener:  listener.Name,
+		Hostname:  hostname,
+	}
+	if listenerID, exists := config.listenerIDs[key]; exists {
+		return listenerID
+	}
+	config.listenerIDs[key] = nil
+
+	listenerID := listenerIdentifier{
+		FrontendPort: Port(listener.Port),
+		FrontendType: defaultFrontendType(c.appGw, cbCtx.EnvVariables),
+	}
+	if hostname != "" {
+		listenerID.setHostNames([]string{hostname})
+	}
+
+	azConfig := listenerAzConfig{Protocol: n.ApplicationGatewayProtocolHTTP}
+	if listener.Protocol == gatewayv1.HTTPSProtocolType {
+		azConfig.Protocol = n.ApplicationGatewayProtocolHTTPS
+		azConfig.Secret = secretIdentifier{
+			Namespace: listener.Gateway.Namespace,
+			Name:      listener.SecretName,
+		}
+		cert := c.k8sContext.CertificateSecretStore.GetPfxCertificate(azConfig.Secret.secretKey())
+		if cert == nil {
+			cbCtx.GatewayAPIStatus.RejectListener(listener.Gateway, listener.Name, gatewayv1.ListenerConditionResolvedRefs, gatewayv1.ListenerReasonInvalidCertificateRef,
+				fmt.Sprintf("Secret %s does not exist or does not hold a valid TLS certificate", azConfig.Secret.secretKey()))
+			return nil
+		}
+		config.certificates[azConfig.Secret] = to.StringPtr(base64.StdEncoding.EncodeToString(cert))
+	}
+
+	for ingressListenerID, ingressConfig := range ingressListeners {
+		if ingressListenerID.FrontendPort != listenerID.FrontendPort {
+			continue
+		}
+		if ingressConfig.Protocol != azConfig.Protocol {
+			cbCtx.GatewayAPIStatus.ConflictListener(listener.Gateway, listener.Name, gatewayv1.ListenerReasonProtocolConflict,
+				fmt.Sprintf("An Ingress already uses port %d with protocol %s", listenerID.FrontendPort, ingressConfig.Protocol))
+			return nil
+		}
+		if ingressListenerID == listenerID {
+			cbCtx.GatewayAPIStatus.ConflictListener(listener.Gateway, listener.Name, gatewayv1.ListenerReasonHostnameConflict,
+				fmt.Sprintf("An Ingress already uses port %d for hostname %q", listenerID.FrontendPort, hostname))
+			return nil
+		}
+	}
+
+	klog.V(3).Infof("Created listener for hostname %q of listener %s of Gateway %s/%s", hostname, listener.Name, listener.Gateway.Namespace, listener.Gateway.Name)
+	config.listenerConfigs[listenerID] = azConfig
+	config.listenerIDs[key] = &listenerID
+	return &listenerID
+}
@@ -0,0 +1,268 @@
+// -------------------------------------------------------------------------------------------
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License. See License.txt in the project root for license information.
+// --------------------------------------------------------------------------------------------
+
+package appgw
+
+import (
+	"fmt"
+	"strings"
+
+	n "github.com/Azure/azure-sdk-for-go/services/network/mgmt/2021-03-01/network"
+	"github.com/Azure/go-autorest/autorest/to"
+	"k8s.io/klog/v2"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+
+	"github.com/Azure/application-gateway-kubernetes-ingress/pkg/gatewayapi"
+)
+
+// addGatewayAPIRoute adds the rules of the route to the path maps of the App Gateway listeners serving its hostnames on the Gateway listener.
+// Rules App Gateway cannot express are dropped and recorded on the status of the route.
+func (c *appGwConfigBuilder) addGatewayAPIRoute(cbCtx *ConfigBuilderContext, config *gatewayAPIConfig, attachment gatewayapi.Attachment, ingressListeners map[listenerIdentifier]listenerAzConfig) {
+	route := attachment.Route
+	for ruleIdx, rule := range route.Spec.Rules {
+		redirect, message := gatewayAPIRedirectFilter(rule)
+		if message != "" {
+			cbCtx.GatewayAPIStatus.DropRouteRules(route, fmt.Sprintf("Rule %d was dropped: %s", ruleIdx, message))
+			continue
+		}
+
+		var backend gatewayAPIBackend
+		if redirect == nil {
+			backend = c.getGatewayAPIBackend(cbCtx, config, route, ruleIdx, rule)
+		}
+
+		matches := rule.Matches
+		if len(matches) == 0 {
+			// A rule without matches matches all requests.
+			matches = []gatewayv1.HTTPRouteMatch{{}}
+		}
+		for matchIdx, match := range matches {
+			paths, host, message := gatewayAPIMatchPaths(match)
+			if message != "" {
+				cbCtx.GatewayAPIStatus.DropRouteRules(route, fmt.Sprintf("Match %d of rule %d was dropped: %s", matchIdx, ruleIdx, message))
+				continue
+			}
+
+			for _, hostname := range gatewayAPIMatchHostnames(attachment.Hostnames, host) {
+				listenerID := c.getGatewayAPIListener(cbCtx, config, attachment.Listener, hostname, ingressListeners)
+				if listenerID == nil {
+					continue
+				}
+
+				target := backend
+				if redirect != nil {
+					redirectConfig, message := c.newGatewayAPIRedirect(attachment.Listener, route, ruleIdx, redirect, hostname)
+					if message != "" {
+						cbCtx.GatewayAPIStatus.DropRouteRules(route, fmt.Sprintf("Rule %d was dropped: %s", ruleIdx, message))
+						continue
+					}
+					config.redirects[*redirectConfig.Name] = *redirectConfig
+					target = gatewayAPIBackend{redirect: resourceRef(*redirectConfig.ID)}
+				}
+
+				c.addGatewayAPIPathRule(config, *listenerID, generatePathRuleName(route.Namespace, route.Name, ruleIdx, matchIdx), paths, target)
+			}
+		}
+	}
+}
+
+// addGatewayAPIPathRule routes the paths on the listener to the target; No paths stands for all paths, i.e. the default of the path map.
+// Paths already routed by an older route are skipped.
+func (c *appGwConfigBuilder) addGatewayAPIPathRule(config *gatewayAPIConfig, listenerID listenerIdentifier, pathRuleName string, paths []string, target gatewayAPIBackend) {
+	pathMapName := generateURLPathMapName(listenerID)
+	pathMap, exists := config.pathMaps[listenerID]
+	if !exists {
+		pathMap = &n.ApplicationGatewayURLPathMap{
+			Etag: to.StringPtr("*"),
+			Name: to.StringPtr(pathMapName),
+			ID:   to.StringPtr(c.appGwIdentifier.urlPathMapID(pathMapName)),
+			ApplicationGatewayURLPathMapPropertiesFormat: &n.ApplicationGatewayURLPathMapPropertiesFormat{
+				DefaultBackendAddressPool:  resourceRef(c.appGwIdentifier.AddressPoolID(DefaultBackendAddressPoolName)),
+				DefaultBackendHTTPSettings: resourceRef(c.appGwIdentifier.HTTPSettingsID(DefaultBackendHTTPSettingsName)),
+				PathRules:                  &[]n.ApplicationGatewayPathRule{},
+			},
+		}
+		config.pathMaps[listenerID] = pathMap
+		config.paths[listenerID] = make(map[string]interface{})
+	}
+
+	if len(paths) == 0 {
+		if _, exists := config.catchAll[listenerID]; exists {
+			klog.V(3).Infof("Path map %s already has a default backend; Skipping path rule %s", pathMapName, pathRuleName)
+			return
+		}
+		config.catchAll[listenerID] = nil
+		pathMap.DefaultBackendAddressPool = target.pool
+		pathMap.DefaultBackendHTTPSettings = target.settings
+		pathMap.DefaultLoadDistributionPolicy = target.loadDistributionPolicy
+		pathMap.DefaultRedirectConfiguration = target.redirect
+		return
+	}
+
+	var newPaths []string
+	for _, path := range paths {
+		if _, exists := config.paths[listenerID][path]; exists {
+			klog.V(3).Infof("Path %s is already routed by path map %s; Skipping it in path rule %s", path, pathMapName, pathRuleName)
+			continue
+		}
+		config.paths[listenerID][path] = nil
+		newPaths = append(newPaths, path)
+	}
+	if len(newPaths) == 0 {
+		return
+	}
+
+	*pathMap.PathRules = append(*pathMap.PathRules, n.ApplicationGatewayPathRule{
+		Etag: to.StringPtr("*"),
+		Name: to.StringPtr(pathRuleName),
+		ID:   to.StringPtr(c.appGwIdentifier.pathRuleID(pathMapName, pathRuleName)),
+		ApplicationGatewayPathRulePropertiesFormat: &n.ApplicationGatewayPathRulePropertiesFormat{
+			Paths:                  &newPaths,
+			BackendAddressPool:     target.pool,
+			BackendHTTPSettings:    target.settings,
+			LoadDistributionPolicy: target.loadDistributionPolicy,
+			RedirectConfiguration:  target.redirect,
+		},
+	})
+}
+
+// gatewayAPIMatchPaths translates the match into the paths of a path rule; No paths stands for all paths.
+// App Gateway routes on paths and hostnames, so the only header a match may test is an exact Host.
+// When the match cannot be expressed, the message tells why.
+func gatewayAPIMatchPaths(match gatewayv1.HTTPRouteMatch) ([]string, string, string) {
+	if match.Method != nil {
+		return nil, "", "Application Gateway cannot route on the request method"
+	}
+	if len(match.QueryParams) > 0 {
+		return nil, "", "Application Gateway cannot route on query parameters"
+	}
+
+	host := ""
+	for _, header := range match.Headers {
+		isExact := header.Type == nil || *header.Type == gatewayv1.HeaderMatchExact
+		if !strings.EqualFold(string(header.Name), "Host") || !isExact {
+			return nil, "", fmt.Sprintf("Application Gateway cannot route on header %s", header.Name)
+		}
+		host = strings.ToLower(header.Value)
+	}
+
+	if match.Path == nil || match.Path.Value == nil {
+		return nil, host, ""
+	}
+	pathType := gatewayv1.PathMatchPathPrefix
+	if match.Path.Type != nil {
+		pathType = *match.Path.Type
+	}
+	path := *match.Path.Value
+	switch pathType {
+	case gatewayv1.PathMatchPathPrefix:
+		path = strings.TrimSuffix(path, "/")
+		if path == "" {
+			return nil, host, ""
+		}
+		return []string{path, path + "/*"}, host, ""
+	case gatewayv1.PathMatchExact:
+		if path == "/" {
+			// App Gateway requires paths to have a non-empty value after the leading '/'.
+			return nil, "", "Application Gateway cannot match the exact path /"
+		}
+		return []string{path}, host, ""
+	default:
+		return nil, "", fmt.Sprintf("Application Gateway does not support path match type %s", pathType)
+	}
+}
+
+// gatewayAPIMatchHostnames returns the hostnames a match applies to; A Host header restricts the hostnames the route serves to that host.
+func gatewayAPIMatchHostnames(hostnames []string, host string) []string {
+	if host == "" {
+		return hostnames
+	}
+	for _, hostname := range hostnames {
+		if gatewayapi.HostnameMatches(hostname, host) {
+			return []string{host}
+		}
+	}
+	return nil
+}
+
+// gatewayAPIRedirectFilter returns the RequestRedirect filter of the rule, if any.
+// When the rule has a filter App Gateway cannot apply, the message tells why.
+func gatewayAPIRedirectFilter(rule gatewayv1.HTTPRouteRule) (*gatewayv1.HTTPRequestRedirectFilter, string) {
+	var redirect *gatewayv1.HTTPRequestRedirectFilter
+	for _, filter := range rule.Filters {
+		if filter.Type != gatewayv1.HTTPRouteFilterRequestRedirect || filter.RequestRedirect == nil {
+			return nil, fmt.Sprintf("AGIC does not support filter %s", filter.Type)
+		}
+		redirect = filter.RequestRedirect
+	}
+	return redirect, ""
+}
+
+// newGatewayAPIRedirect creates the redirect configuration of a RequestRedirect filter for requests to the hostname.
+// App Gateway redirects to a fixed URL, so the filter must not depend on parts of the request other than its path and query.
+// When the filter cannot be expressed, the message tells why.
+func (c *appGwConfigBuilder) newGatewayAPIRedirect(listener gatewayapi.Listener, route *gatewayv1.HTTPRoute, ruleIdx int, filter *gatewayv1.HTTPRequestRedirectFilter, hostname string) (*n.ApplicationGatewayRedirectConfiguration, string) {
+	statusCode := 302
+	if filter.StatusCode != nil {
+		statusCode = *filter.StatusCode
+	}
+	redirectTypes := map[int]n.ApplicationGatewayRedirectType{
+		301: n.ApplicationGatewayRedirectTypePermanent,
+		302: n.ApplicationGatewayRedirectTypeFound,
+		303: n.ApplicationGatewayRedirectTypeSeeOther,
+		307: n.ApplicationGatewayRedirectTypeTemporary,
+	}
+	redirectType, supported := redirectTypes[statusCode]
+	if !supported {
+		return nil, fmt.Sprintf("Application Gateway does not support redirect status code %d", statusCode)
+	}
+
+	scheme := strings.ToLower(string(listener.Protocol))
+	port := int32(listener.Port)
+	if filter.Scheme != nil {
+		scheme = *filter.Scheme
+		port = 0
+	}
+	if filter.Port != nil {
+		port = int32(*filter.Port)
+	}
+
+	host := hostname
+	nameHostname := hostname
+	if filter.Hostname != nil {
+		host = string(*filter.Hostname)
+		nameHostname = ""
+	}
+	if host == "" || strings.Contains(host, "*") {
+		return nil, "Application Gateway can only redirect to a fixed hostname; Set the hostname of the RequestRedirect filter"
+	}
+
+	targetURL := fmt.Sprintf("%s://%s", scheme, host)
+	if port != 0 && !(scheme == "http" && port == 80) && !(scheme == "https" && port == 443) {
+		targetURL = fmt.Sprintf("%s:%d", targetURL, port)
+	}
+
+	includePath := true
+	if filter.Path != nil {
+		if filter.Path.Type != gatewayv1.FullPathHTTPPathModifier || filter.Path.ReplaceFullPath == nil {
+			return nil, "Application Gateway can only redirect to a full path"
+		}
+		targetURL += *filter.Path.ReplaceFullPath
+		includePath = false
+	}
+
+	redirectName := generateRouteRedirectName(route.Namespace, route.Name, ruleIdx, nameHostname)
+	return &n.ApplicationGatewayRedirectConfiguration{
+		Etag: to.StringPtr("*"),
+		Name: to.StringPtr(redirectName),
+		ID:   to.StringPtr(c.appGwIdentifier.redirectConfigurationID(redirectName)),
+		ApplicationGatewayRedirectConfigurationPropertiesFormat: &n.ApplicationGatewayRedirectConfigurationPropertiesFormat{
+			RedirectType:       redirectType,
+			TargetURL:          to.StringPtr(targetURL),
+			IncludePath:        to.BoolPtr(includePath),
+			IncludeQueryString: to.BoolPtr(true),
+		},
+	}, ""
+}
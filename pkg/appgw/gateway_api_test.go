@@ -0,0 +1,250 @@
+// -------------------------------------------------------------------------------------------
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License. See License.txt in the project root for license information.
+// --------------------------------------------------------------------------------------------
+
+package appgw
+
+import (
+	"context"
+	"time"
+
+	n "github.com/Azure/azure-sdk-for-go/services/network/mgmt/2021-03-01/network"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	testclient "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/record"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+	gateway_fake "sigs.k8s.io/gateway-api/pkg/client/clientset/versioned/fake"
+
+	"github.com/Azure/application-gateway-kubernetes-ingress/pkg/crd_client/agic_crd_client/clientset/versioned/fake"
+	multiCluster_fake "github.com/Azure/application-gateway-kubernetes-ingress/pkg/crd_client/azure_multicluster_crd_client/clientset/versioned/fake"
+	istio_fake "github.com/Azure/application-gateway-kubernetes-ingress/pkg/crd_client/istio_crd_client/clientset/versioned/fake"
+	"github.com/Azure/application-gateway-kubernetes-ingress/pkg/environment"
+	"github.com/Azure/application-gateway-kubernetes-ingress/pkg/gatewayapi"
+	"github.com/Azure/application-gateway-kubernetes-ingress/pkg/k8scontext"
+	"github.com/Azure/application-gateway-kubernetes-ingress/pkg/metricstore"
+	"github.com/Azure/application-gateway-kubernetes-ingress/pkg/tests"
+	"github.com/Azure/application-gateway-kubernetes-ingress/pkg/tests/mocks"
+)
+
+var _ = Describe("Test Gateway API translation", func() {
+	namespace := "shop"
+
+	newService := func(name string, ips ...string) (*v1.Service, *v1.Endpoints) {
+		service := &v1.Service{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+			Spec: v1.ServiceSpec{
+				Ports: []v1.ServicePort{{
+					Protocol:   v1.ProtocolTCP,
+					Port:       80,
+					TargetPort: intstr.FromInt(8080),
+				}},
+			},
+		}
+		var addresses []v1.EndpointAddress
+		for _, ip := range ips {
+			addresses = append(addresses, v1.EndpointAddress{IP: ip})
+		}
+		endpoints := &v1.Endpoints{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+			Subsets: []v1.EndpointSubset{{
+				Addresses: addresses,
+				Ports:     []v1.EndpointPort{{Protocol: v1.ProtocolTCP, Port: 8080}},
+			}},
+		}
+		return service, endpoints
+	}
+
+	backendRef := func(name string, weight int32) gatewayv1.HTTPBackendRef {
+		port := gatewayv1.PortNumber(80)
+		return gatewayv1.HTTPBackendRef{
+			BackendRef: gatewayv1.BackendRef{
+				BackendObjectReference: gatewayv1.BackendObjectReference{Name: gatewayv1.ObjectName(name), Port: &port},
+				Weight:                 &weight,
+			},
+		}
+	}
+
+	pathMatch := func(matchType gatewayv1.PathMatchType, path string) []gatewayv1.HTTPRouteMatch {
+		return []gatewayv1.HTTPRouteMatch{{Path: &gatewayv1.HTTPPathMatch{Type: &matchType, Value: &path}}}
+	}
+
+	gateway := &gatewayv1.Gateway{
+		ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: namespace},
+		Spec: gatewayv1.GatewaySpec{
+			GatewayClassName: "azure-application-gateway",
+			Listeners: []gatewayv1.Listener{{
+				Name:     "http",
+				Protocol: gatewayv1.HTTPProtocolType,
+				Port:     80,
+			}},
+		},
+	}
+
+	var cb *appGwConfigBuilder
+	var cbCtx *ConfigBuilderContext
+	var route *gatewayv1.HTTPRoute
+
+	BeforeEach(func() {
+		k8sClient := testclient.NewSimpleClientset()
+		for _, s := range []struct {
+			name string
+			ips  []string
+		}{
+			{"cart-v1", []string{"10.0.0.1", "10.0.0.2"}},
+			{"cart-v2", []string{"10.0.0.3"}},
+			{"catalog", []string{"10.0.0.4"}},
+		} {
+			service, endpoints := newService(s.name, s.ips...)
+			_, _ = k8sClient.CoreV1().Services(namespace).Create(context.TODO(), service, metav1.CreateOptions{})
+			_ = createEndpointsFixture(k8sClient, endpoints)
+		}
+
+		k8scontext.IsNetworkingV1PackageSupported = true
+		ctxt := k8scontext.NewContext(k8sClient, fake.NewSimpleClientset(), multiCluster_fake.NewSimpleClientset(), istio_fake.NewSimpleClientset(), gateway_fake.NewSimpleClientset(), []string{namespace}, 1000*time.Second, metricstore.NewFakeMetricStore(), environment.GetFakeEnv())
+		Expect(ctxt.Run(make(chan struct{}), true, environment.GetFakeEnv())).To(Succeed())
+
+		appGw := &n.ApplicationGateway{ApplicationGatewayPropertiesFormat: NewAppGwyConfigFixture()}
+		cb = NewConfigBuilder(ctxt, &Identifier{
+			SubscriptionID: tests.Subscription,
+			ResourceGroup:  tests.ResourceGroup,
+			AppGwName:      tests.AppGwName,
+		}, appGw, record.NewFakeRecorder(100), mocks.Clock{}).(*appGwConfigBuilder)
+
+		redirectCode := 301
+		redirectHost := gatewayv1.PreciseHostname("new.contoso.com")
+		method := gatewayv1.HTTPMethodPost
+		route = &gatewayv1.HTTPRoute{
+			ObjectMeta: metav1.ObjectMeta{Name: "shop", Namespace: namespace},
+			Spec: gatewayv1.HTTPRouteSpec{
+				CommonRouteSpec: gatewayv1.CommonRouteSpec{
+					ParentRefs: []gatewayv1.ParentReference{{Name: "web"}},
+				},
+				Hostnames: []gatewayv1.Hostname{"shop.contoso.com"},
+				Rules: []gatewayv1.HTTPRouteRule{
+					{
+						Matches:     pathMatch(gatewayv1.PathMatchPathPrefix, "/cart"),
+						BackendRefs: []gatewayv1.HTTPBackendRef{backendRef("cart-v1", 90), backendRef("cart-v2", 10)},
+					},
+					{
+						BackendRefs: []gatewayv1.HTTPBackendRef{backendRef("catalog", 1)},
+					},
+					{
+						Matches: pathMatch(gatewayv1.PathMatchExact, "/old"),
+						Filters: []gatewayv1.HTTPRouteFilter{{
+							Type: gatewayv1.HTTPRouteFilterRequestRedirect,
+							RequestRedirect: &gatewayv1.HTTPRequestRedirectFilter{
+								Hostname:   &redirectHost,
+								StatusCode: &redirectCode,
+							},
+						}},
+					},
+					{
+						Matches:     []gatewayv1.HTTPRouteMatch{{Method: &method}},
+						BackendRefs: []gatewayv1.HTTPBackendRef{backendRef("catalog", 1)},
+					},
+				},
+			},
+		}
+
+		envVariables := environment.GetFakeEnv()
+		envVariables.EnableGatewayAPI = true
+		cbCtx = &ConfigBuilderContext{
+			EnvVariables:       envVariables,
+			GatewayAPIGateways: []*gatewayv1.Gateway{gateway},
+			HTTPRoutes:         []*gatewayv1.HTTPRoute{route},
+			GatewayAPIStatus:   gatewayapi.NewTracker(envVariables.GatewayClassControllerName),
+		}
+	})
+
+	findPathMap := func(appGw *n.ApplicationGateway) *n.ApplicationGatewayURLPathMap {
+		for _, listener := range *appGw.HTTPListeners {
+			if listener.HostNames == nil || len(*listener.HostNames) != 1 || (*listener.HostNames)[0] != "shop.contoso.com" {
+				continue
+			}
+			for _, rule := range *appGw.RequestRoutingRules {
+				if *rule.HTTPListener.ID == *listener.ID && rule.URLPathMap != nil {
+					for idx := range *appGw.URLPathMaps {
+						if *(*appGw.URLPathMaps)[idx].ID == *rule.URLPathMap.ID {
+							return &(*appGw.URLPathMaps)[idx]
+						}
+					}
+				}
+			}
+		}
+		return nil
+	}
+
+	Context("ensure HTTPRoutes are translated into path maps", func() {
+		It("creates a listener, path rules, a load distribution policy and a redirect", func() {
+			appGw, err := cb.Build(cbCtx)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(cb.PostBuildValidate(cbCtx)).To(Succeed())
+
+			pathMap := findPathMap(appGw)
+			Expect(pathMap).ToNot(BeNil())
+			Expect(*pathMap.DefaultBackendAddressPool.ID).To(HaveSuffix("/backendAddressPools/" + generateAddressPoolName(namespace+"-catalog", "80", 8080)))
+
+			policyName := generateLoadDistributionPolicyName(namespace, "shop", 0)
+			Expect(*appGw.LoadDistributionPolicies).To(HaveLen(1))
+			policy := (*appGw.LoadDistributionPolicies)[0]
+			Expect(*policy.Name).To(Equal(policyName))
+			targets := *policy.LoadDistributionTargets
+			Expect(targets).To(HaveLen(2))
+			// cart-v1 weighs 90 over 2 endpoints, cart-v2 weighs 10 over 1 endpoint.
+			Expect(*targets[0].WeightPerServer).To(Equal(int32(100)))
+			Expect(*targets[1].WeightPerServer).To(Equal(int32(22)))
+
+			redirectName := generateRouteRedirectName(namespace, "shop", 2, "")
+			var redirect *n.ApplicationGatewayRedirectConfiguration
+			for idx := range *appGw.RedirectConfigurations {
+				if *(*appGw.RedirectConfigurations)[idx].Name == redirectName {
+					redirect = &(*appGw.RedirectConfigurations)[idx]
+				}
+			}
+			Expect(redirect).ToNot(BeNil())
+			Expect(redirect.RedirectType).To(Equal(n.ApplicationGatewayRedirectTypePermanent))
+			Expect(*redirect.TargetURL).To(Equal("http://new.contoso.com"))
+
+			rules := make(map[string]n.ApplicationGatewayPathRule)
+			for _, rule := range *pathMap.PathRules {
+				rules[*rule.Name] = rule
+			}
+			Expect(rules).To(HaveLen(2))
+			cartRule := rules[generatePathRuleName(namespace, "shop", 0, 0)]
+			Expect(*cartRule.Paths).To(Equal([]string{"/cart", "/cart/*"}))
+			Expect(*cartRule.LoadDistributionPolicy.ID).To(HaveSuffix("/loadDistributionPolicies/" + policyName))
+			Expect(cartRule.BackendAddressPool).ToNot(BeNil())
+			redirectRule := rules[generatePathRuleName(namespace, "shop", 2, 0)]
+			Expect(*redirectRule.Paths).To(Equal([]string{"/old"}))
+			Expect(*redirectRule.RedirectConfiguration.ID).To(Equal(*redirect.ID))
+		})
+
+		It("reports the dropped rule on the route", func() {
+			_, err := cb.Build(cbCtx)
+			Expect(err).ToNot(HaveOccurred())
+
+			status := cbCtx.GatewayAPIStatus.RouteStatus(route)
+			Expect(status.Parents).To(HaveLen(1))
+			conditions := status.Parents[0].Conditions
+			Expect(meta.IsStatusConditionTrue(conditions, string(gatewayv1.RouteConditionAccepted))).To(BeTrue())
+			Expect(meta.IsStatusConditionTrue(conditions, string(gatewayv1.RouteConditionPartiallyInvalid))).To(BeTrue())
+		})
+
+		It("reports missing backends on the route", func() {
+			route.Spec.Rules[1].BackendRefs = []gatewayv1.HTTPBackendRef{backendRef("missing", 1)}
+			appGw, err := cb.Build(cbCtx)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(*findPathMap(appGw).DefaultBackendAddressPool.ID).To(HaveSuffix("/backendAddressPools/" + DefaultBackendAddressPoolName))
+
+			resolvedRefs := meta.FindStatusCondition(cbCtx.GatewayAPIStatus.RouteStatus(route).Parents[0].Conditions, string(gatewayv1.RouteConditionResolvedRefs))
+			Expect(resolvedRefs.Status).To(Equal(metav1.ConditionFalse))
+			Expect(resolvedRefs.Reason).To(Equal(string(gatewayv1.RouteReasonBackendNotFound)))
+		})
+	})
+})
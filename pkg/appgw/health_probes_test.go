@@ -31,7 +31,7 @@ var _ = Describe("configure App Gateway health probes", func() {
 		cb := newConfigBuilderFixture(nil)
 
 		endpoints := tests.NewEndpointsFixture()
-		_ = cb.k8sContext.Caches.Endpoints.Add(endpoints)
+		_ = addEndpointsFixture(cb.k8sContext, endpoints)
 
 		service := tests.NewServiceFixture(*tests.NewServicePortsFixture()...)
 		_ = cb.k8sContext.Caches.Service.Add(service)
@@ -114,7 +114,7 @@ var _ = Describe("configure App Gateway health probes", func() {
 		cb := newConfigBuilderFixture(nil)
 
 		endpoints := tests.NewEndpointsFixture()
-		_ = cb.k8sContext.Caches.Endpoints.Add(endpoints)
+		_ = addEndpointsFixture(cb.k8sContext, endpoints)
 
 		service := tests.NewServiceFixture(*tests.NewServicePortsFixture()...)
 		_ = cb.k8sContext.Caches.Service.Add(service)
@@ -97,6 +97,14 @@ func (agw Identifier) requestRoutingRuleID(settingsName string) string {
 	return agw.gatewayResourceID("requestRoutingRules", settingsName)
 }
 
+func (agw Identifier) loadDistributionPolicyID(policyName string) string {
+	return agw.gatewayResourceID("loadDistributionPolicies", policyName)
+}
+
+func (agw Identifier) loadDistributionTargetID(policyName string, targetName string) string {
+	return agw.gatewayResourceID("loadDistributionPolicies", policyName+"/loadDistributionTargets/"+targetName)
+}
+
 func (agw Identifier) rewriteRuleSetID(rewriteName string) string {
 	return agw.gatewayResourceID("rewriteRuleSets", rewriteName)
 }
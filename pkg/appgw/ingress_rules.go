@@ -162,6 +162,10 @@ func (c *appGwConfigBuilder) newBackendIdsFiltered(cbCtx *ConfigBuilderContext)
 				backendIDs[backendID] = nil
 			}
 		}
+		for _, backendID := range c.getRouteMatchBackendIDs(ingress) {
+			klog.V(3).Info("Found route match backend:", backendID.serviceKey())
+			backendIDs[backendID] = nil
+		}
 	}
 
 	finalBackendIDs := make(map[backendIdentifier]interface{})
@@ -36,6 +36,10 @@ const (
 	prefixRedirect       = "sslr"
 	prefixPathRule       = "pr"
 	prefixSslCertificate = "cert"
+
+	prefixLoadDistributionPolicy = "ldp"
+	prefixRouteRedirect          = "rdr"
+	prefixRewriteRuleSet         = "rrs"
 )
 
 const (
@@ -167,6 +171,37 @@ func generatePathRuleName(namespace, ingress string, ruleIdx, pathIdx int) strin
 	return formatPropName(fmt.Sprintf("%s%s-%s-%s-rule-%d-path-%d", agPrefix, prefixPathRule, namespace, ingress, ruleIdx, pathIdx))
 }
 
+func generateLoadDistributionPolicyName(namespace, route string, ruleIdx int) string {
+	return formatPropName(fmt.Sprintf("%s%s-%s-%s-rule-%d", agPrefix, prefixLoadDistributionPolicy, namespace, route, ruleIdx))
+}
+
+func generateIngressLoadDistributionPolicyName(namespace, name string) string {
+	return formatPropName(fmt.Sprintf("%s%s-crd-%s-%s", agPrefix, prefixLoadDistributionPolicy, namespace, name))
+}
+
+func generateIstioLoadDistributionPolicyName(namespace, virtualService string, ruleIdx int) string {
+	return formatPropName(fmt.Sprintf("%s%s-istio-%s-%s-rule-%d", agPrefix, prefixLoadDistributionPolicy, namespace, virtualService, ruleIdx))
+}
+
+func generateCanaryName(prefix, namespace, ingress string, ruleIdx, pathIdx int) string {
+	return formatPropName(fmt.Sprintf("%s%s-canary-%s-%s-rule-%d-path-%d", agPrefix, prefix, namespace, ingress, ruleIdx, pathIdx))
+}
+
+func generateRouteMatchRewriteRuleSetName(namespace, ingress string) string {
+	return formatPropName(fmt.Sprintf("%s%s-route-%s-%s", agPrefix, prefixRewriteRuleSet, namespace, ingress))
+}
+
+func generateRouteMatchHTTPSettingsName(namespace, ingress string, ruleIdx, pathIdx, matchIdx int) string {
+	return formatPropName(fmt.Sprintf("%s%s-route-%s-%s-rule-%d-path-%d-match-%d", agPrefix, prefixHTTPSettings, namespace, ingress, ruleIdx, pathIdx, matchIdx))
+}
+
+func generateRouteRedirectName(namespace, route string, ruleIdx int, hostname string) string {
+	if hostname == "" {
+		return formatPropName(fmt.Sprintf("%s%s-%s-%s-rule-%d", agPrefix, prefixRouteRedirect, namespace, route, ruleIdx))
+	}
+	return formatPropName(fmt.Sprintf("%s%s-%s-%s-rule-%d-%s", agPrefix, prefixRouteRedirect, namespace, route, ruleIdx, hostname))
+}
+
 // DefaultBackendHTTPSettingsName is the name to be assigned to App Gateway's default HTTP settings resource.
 var DefaultBackendHTTPSettingsName = fmt.Sprintf("%sdefaulthttpsetting", agPrefix)
 
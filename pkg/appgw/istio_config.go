@@ -6,29 +6,54 @@
 package appgw
 
 import (
+	n "github.com/Azure/azure-sdk-for-go/services/network/mgmt/2021-03-01/network"
 	"github.com/knative/pkg/apis/istio/v1alpha3"
 	"k8s.io/klog/v2"
 )
 
-func (c *appGwConfigBuilder) resolveIstioPortName(portName string, destinationID *istioDestinationIdentifier) map[Port]interface{} {
-	resolvedPorts := make(map[Port]interface{})
-	endpoints, err := c.k8sContext.GetEndpointsByService(destinationID.serviceKey())
-	if err != nil {
-		klog.Error("Could not fetch endpoint by service key from cache", err)
-		return resolvedPorts
+// getIstioConfig translates the Istio Gateways and VirtualServices into listeners, path maps and load distribution policies.
+// The result is memoized, so that the events about unsupported config are recorded once per event loop.
+func (c *appGwConfigBuilder) getIstioConfig(cbCtx *ConfigBuilderContext) *istioConfig {
+	if c.mem.istio != nil {
+		return c.mem.istio
+	}
+
+	config := &istioConfig{
+		listenerConfigs:          make(map[listenerIdentifier]listenerAzConfig),
+		certificates:             make(map[secretIdentifier]*string),
+		pathMaps:                 make(map[listenerIdentifier]*n.ApplicationGatewayURLPathMap),
+		loadDistributionPolicies: make(map[string]n.ApplicationGatewayLoadDistributionPolicy),
+		servers:                  make(map[istioServerKey]*listenerAzConfig),
+		catchAll:                 make(map[listenerIdentifier]interface{}),
+		paths:                    make(map[listenerIdentifier]map[string]interface{}),
 	}
+	c.mem.istio = config
 
-	if endpoints == nil {
-		return resolvedPorts
+	_, settingsByDestination, _, err := c.getIstioDestinationsAndSettingsMap(cbCtx)
+	if err != nil {
+		klog.Error(err.Error())
 	}
-	for _, subset := range endpoints.Subsets {
-		for _, epPort := range subset.Ports {
-			if epPort.Name == portName {
-				resolvedPorts[Port(epPort.Port)] = nil
-			}
+	poolsByDestination := c.newIstioBackendPoolMap(cbCtx)
+
+	for _, virtualService := range cbCtx.IstioVirtualServices {
+		listenerIDs := c.getIstioListeners(cbCtx, config, virtualService)
+		if len(listenerIDs) == 0 {
+			klog.V(3).Infof("[istio] VirtualService %s/%s is not served by any Gateway server", virtualService.Namespace, virtualService.Name)
+			continue
 		}
+		c.addIstioVirtualService(config, virtualService, listenerIDs, settingsByDestination, poolsByDestination)
+	}
+
+	return config
+}
+
+func (c *appGwConfigBuilder) resolveIstioPortName(portName string, destinationID *istioDestinationIdentifier) map[Port]interface{} {
+	endpointSlices, err := c.k8sContext.GetEndpointSlicesByService(destinationID.serviceKey())
+	if err != nil {
+		klog.Error("Could not fetch endpoint slices by service key from cache", err)
+		return make(map[Port]interface{})
 	}
-	return resolvedPorts
+	return resolveEndpointSlicesPortName(endpointSlices, portName)
 }
 
 func generateIstioMatchID(virtualService *v1alpha3.VirtualService, rule *v1alpha3.HTTPRoute, match *v1alpha3.HTTPMatchRequest, destinations []*v1alpha3.Destination) istioMatchIdentifier {
@@ -0,0 +1,151 @@
+// -------------------------------------------------------------------------------------------
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License. See License.txt in the project root for license information.
+// --------------------------------------------------------------------------------------------
+
+package appgw
+
+import (
+	"fmt"
+	"strings"
+
+	n "github.com/Azure/azure-sdk-for-go/services/network/mgmt/2021-03-01/network"
+	"github.com/Azure/go-autorest/autorest/to"
+	"github.com/knative/pkg/apis/istio/v1alpha3"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/klog/v2"
+
+	"github.com/Azure/application-gateway-kubernetes-ingress/pkg/events"
+	"github.com/Azure/application-gateway-kubernetes-ingress/pkg/gatewayapi"
+)
+
+// istioClusterDomain is the domain Istio expands short host names with.
+const istioClusterDomain = "svc.cluster.local"
+
+// applyIstioDestinationRule applies the TLS settings of the DestinationRule of the destination to its HTTP settings:
+//   - TLS in SIMPLE mode makes App Gateway connect with HTTPS, sending the SNI of the policy as host name;
+//   - caCertificates names the trusted root certificates, installed on App Gateway, which sign the certificate of the destination.
+//
+// App Gateway cannot present client certificates, neither its own nor those of Istio workloads, so MUTUAL and ISTIO_MUTUAL are not applied.
+func (c *appGwConfigBuilder) applyIstioDestinationRule(httpSettings *n.ApplicationGatewayBackendHTTPSettings, destinationID istioDestinationIdentifier, cbCtx *ConfigBuilderContext) {
+	rule, tls := istioDestinationTrafficPolicy(destinationID, cbCtx.IstioDestinationRules)
+	if rule == nil || tls == nil {
+		return
+	}
+
+	switch tls.Mode {
+	case v1alpha3.TLSmodeSimple:
+	case v1alpha3.TLSmodeIstioMutual, v1alpha3.TLSmodeMutual:
+		c.istioDestinationRuleEvent(rule, fmt.Sprintf("Application Gateway cannot present client certificates; TLS mode %s is not applied to destination %s", tls.Mode, destinationID.DestinationHost))
+		return
+	default:
+		return
+	}
+
+	httpSettings.Protocol = n.ApplicationGatewayProtocolHTTPS
+	if tls.Sni != "" {
+		httpSettings.HostName = to.StringPtr(tls.Sni)
+		httpSettings.PickHostNameFromBackendAddress = to.BoolPtr(false)
+	}
+
+	installed := make(map[string]interface{})
+	if c.appGw.TrustedRootCertificates != nil {
+		for _, cert := range *c.appGw.TrustedRootCertificates {
+			if cert.Name != nil {
+				installed[*cert.Name] = nil
+			}
+		}
+	}
+
+	var certs []n.SubResource
+	for _, certName := range strings.Split(tls.CaCertificates, ",") {
+		if certName = strings.TrimSpace(certName); certName == "" {
+			continue
+		}
+		if _, exists := installed[certName]; !exists {
+			c.istioDestinationRuleEvent(rule, fmt.Sprintf("Trusted root certificate %s of caCertificates is not installed on Application Gateway", certName))
+			continue
+		}
+		certs = append(certs, *resourceRef(c.appGwIdentifier.trustedRootCertificateID(certName)))
+	}
+	if len(certs) > 0 {
+		httpSettings.TrustedRootCertificates = &certs
+		// To use an HTTP setting with a trusted root certificate, we must either override with a specific domain name or choose "Pick host name from backend target".
+		httpSettings.PickHostNameFromBackendAddress = to.BoolPtr(httpSettings.HostName == nil)
+	}
+}
+
+// istioDestinationTrafficPolicy returns the DestinationRule of the host of the destination, with its TLS settings.
+// Settings of the port and of the subset of the destination take precedence over those of the whole host, as in Istio.
+func istioDestinationTrafficPolicy(destinationID istioDestinationIdentifier, destinationRules []*v1alpha3.DestinationRule) (*v1alpha3.DestinationRule, *v1alpha3.TLSSettings) {
+	rule := findIstioDestinationRule(destinationID, destinationRules)
+	if rule == nil {
+		return nil, nil
+	}
+
+	var tls *v1alpha3.TLSSettings
+	apply := func(policy *v1alpha3.TrafficPolicy) {
+		if policy == nil {
+			return
+		}
+		if policy.TLS != nil {
+			tls = policy.TLS
+		}
+		for _, portPolicy := range policy.PortLevelSettings {
+			if portPolicy.Port.Number == 0 || portPolicy.Port.Number != destinationID.DestinationPort {
+				continue
+			}
+			if portPolicy.TLS != nil {
+				tls = portPolicy.TLS
+			}
+		}
+	}
+
+	apply(rule.Spec.TrafficPolicy)
+	for _, subset := range rule.Spec.Subsets {
+		if destinationID.DestinationSubset != "" && subset.Name == destinationID.DestinationSubset {
+			apply(subset.TrafficPolicy)
+		}
+	}
+	return rule, tls
+}
+
+// findIstioDestinationRule returns the DestinationRule whose host matches the destination; An exact host wins over a wildcard one.
+// Short host names are relative to the namespace of their resource.
+func findIstioDestinationRule(destinationID istioDestinationIdentifier, destinationRules []*v1alpha3.DestinationRule) *v1alpha3.DestinationRule {
+	destinationHost := istioFullHostName(destinationID.DestinationHost, destinationID.istioVirtualServiceIdentifier.Namespace)
+	var found *v1alpha3.DestinationRule
+	foundHost := ""
+	for _, rule := range destinationRules {
+		if rule.Spec.Host == "" {
+			continue
+		}
+		ruleHost := istioFullHostName(rule.Spec.Host, rule.Namespace)
+		pattern := ruleHost
+		if pattern == "*" {
+			pattern = ""
+		}
+		if !gatewayapi.HostnameMatches(pattern, destinationHost) {
+			continue
+		}
+		// The longest host is the most specific; Ties are broken by namespace and name, to be stable across event loops.
+		if found == nil || len(ruleHost) > len(foundHost) ||
+			(len(ruleHost) == len(foundHost) && rule.Namespace+"/"+rule.Name < found.Namespace+"/"+found.Name) {
+			found, foundHost = rule, ruleHost
+		}
+	}
+	return found
+}
+
+// istioFullHostName expands a short host name, i.e. the name of a Service, into its fully qualified name in the namespace.
+func istioFullHostName(host, namespace string) string {
+	if host == "" || strings.Contains(host, ".") || strings.Contains(host, "*") {
+		return host
+	}
+	return host + "." + namespace + "." + istioClusterDomain
+}
+
+func (c *appGwConfigBuilder) istioDestinationRuleEvent(rule *v1alpha3.DestinationRule, message string) {
+	klog.Warningf("[istio] DestinationRule %s/%s: %s", rule.Namespace, rule.Name, message)
+	c.recorder.Event(rule, v1.EventTypeWarning, events.ReasonUnsupportedIstioConfig, message)
+}
@@ -0,0 +1,158 @@
+// -------------------------------------------------------------------------------------------
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License. See License.txt in the project root for license information.
+// --------------------------------------------------------------------------------------------
+
+package appgw
+
+import (
+	n "github.com/Azure/azure-sdk-for-go/services/network/mgmt/2021-03-01/network"
+	"github.com/Azure/go-autorest/autorest/to"
+	"github.com/knative/pkg/apis/istio/v1alpha3"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+
+	"github.com/Azure/application-gateway-kubernetes-ingress/pkg/environment"
+	"github.com/Azure/application-gateway-kubernetes-ingress/pkg/events"
+)
+
+var _ = Describe("Test Istio DestinationRule translation", func() {
+	var cb appGwConfigBuilder
+	var recorder *record.FakeRecorder
+	destinationID := generateIstioDestinationID(
+		&v1alpha3.VirtualService{ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "web"}},
+		&v1alpha3.Destination{Host: "web-v1", Subset: "v1", Port: v1alpha3.PortSelector{Number: 443}},
+	)
+
+	newDestinationRule := func(name, host string, policy *v1alpha3.TrafficPolicy) *v1alpha3.DestinationRule {
+		return &v1alpha3.DestinationRule{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "web"},
+			Spec: v1alpha3.DestinationRuleSpec{
+				Host:          host,
+				TrafficPolicy: policy,
+			},
+		}
+	}
+
+	BeforeEach(func() {
+		recorder = record.NewFakeRecorder(100)
+		cb = newConfigBuilderFixture(nil)
+		cb.recorder = recorder
+		cb.appGw.TrustedRootCertificates = &[]n.ApplicationGatewayTrustedRootCertificate{
+			{Name: to.StringPtr("contoso-root")},
+			{Name: to.StringPtr("contoso-intermediate")},
+		}
+	})
+
+	drainEvents := func() []string {
+		var recorded []string
+		for len(recorder.Events) > 0 {
+			recorded = append(recorded, <-recorder.Events)
+		}
+		return recorded
+	}
+
+	settingsFor := func(rules ...*v1alpha3.DestinationRule) n.ApplicationGatewayBackendHTTPSettings {
+		cbCtx := &ConfigBuilderContext{
+			EnvVariables:          environment.GetFakeEnv(),
+			IstioDestinationRules: rules,
+		}
+		return cb.generateIstioHTTPSettings(destinationID, Port(8443), cbCtx)
+	}
+
+	It("keeps HTTP without a DestinationRule for the destination", func() {
+		settings := settingsFor(newDestinationRule("other", "web-v2", &v1alpha3.TrafficPolicy{
+			TLS: &v1alpha3.TLSSettings{Mode: v1alpha3.TLSmodeSimple},
+		}))
+		Expect(settings.Protocol).To(Equal(n.ApplicationGatewayProtocolHTTP))
+		Expect(settings.HostName).To(BeNil())
+		Expect(settings.TrustedRootCertificates).To(BeNil())
+	})
+
+	It("connects with HTTPS to the SNI, trusting the CA certificates", func() {
+		settings := settingsFor(newDestinationRule("web-v1", "web-v1.web.svc.cluster.local", &v1alpha3.TrafficPolicy{
+			TLS: &v1alpha3.TLSSettings{
+				Mode:           v1alpha3.TLSmodeSimple,
+				Sni:            "web-v1.contoso.com",
+				CaCertificates: "contoso-root, contoso-intermediate",
+			},
+			ConnectionPool: &v1alpha3.ConnectionPoolSettings{
+				TCP: &v1alpha3.TCPSettings{ConnectTimeout: "2500ms"},
+			},
+		}))
+		Expect(settings.Protocol).To(Equal(n.ApplicationGatewayProtocolHTTPS))
+		Expect(settings.Port).To(Equal(to.Int32Ptr(8443)))
+		Expect(settings.HostName).To(Equal(to.StringPtr("web-v1.contoso.com")))
+		Expect(settings.PickHostNameFromBackendAddress).To(Equal(to.BoolPtr(false)))
+		Expect(*settings.TrustedRootCertificates).To(Equal([]n.SubResource{
+			*resourceRef(cb.appGwIdentifier.trustedRootCertificateID("contoso-root")),
+			*resourceRef(cb.appGwIdentifier.trustedRootCertificateID("contoso-intermediate")),
+		}))
+		Expect(settings.RequestTimeout).To(BeNil())
+		Expect(drainEvents()).To(BeEmpty())
+	})
+
+	It("skips the CA certificates which are not installed on App Gateway with an event", func() {
+		settings := settingsFor(newDestinationRule("web-v1", "web-v1", &v1alpha3.TrafficPolicy{
+			TLS: &v1alpha3.TLSSettings{Mode: v1alpha3.TLSmodeSimple, CaCertificates: "contoso-root,/etc/certs/root-cert.pem"},
+		}))
+		Expect(settings.Protocol).To(Equal(n.ApplicationGatewayProtocolHTTPS))
+		Expect(*settings.TrustedRootCertificates).To(Equal([]n.SubResource{
+			*resourceRef(cb.appGwIdentifier.trustedRootCertificateID("contoso-root")),
+		}))
+
+		recorded := drainEvents()
+		Expect(recorded).To(HaveLen(1))
+		Expect(recorded[0]).To(ContainSubstring(events.ReasonUnsupportedIstioConfig))
+		Expect(recorded[0]).To(ContainSubstring("/etc/certs/root-cert.pem"))
+	})
+
+	It("keeps HTTP for ISTIO_MUTUAL with an event", func() {
+		settings := settingsFor(newDestinationRule("web-v1", "web-v1", &v1alpha3.TrafficPolicy{
+			TLS: &v1alpha3.TLSSettings{Mode: v1alpha3.TLSmodeIstioMutual},
+		}))
+		Expect(settings.Protocol).To(Equal(n.ApplicationGatewayProtocolHTTP))
+		Expect(settings.TrustedRootCertificates).To(BeNil())
+
+		recorded := drainEvents()
+		Expect(recorded).To(HaveLen(1))
+		Expect(recorded[0]).To(ContainSubstring(events.ReasonUnsupportedIstioConfig))
+		Expect(recorded[0]).To(ContainSubstring("ISTIO_MUTUAL"))
+	})
+
+	It("applies the settings of the port and subset over those of the host", func() {
+		rule := newDestinationRule("web-v1", "web-v1", &v1alpha3.TrafficPolicy{
+			TLS: &v1alpha3.TLSSettings{Mode: v1alpha3.TLSmodeDisable},
+			PortLevelSettings: []v1alpha3.PortTrafficPolicy{{
+				Port: v1alpha3.PortSelector{Number: 443},
+				TLS:  &v1alpha3.TLSSettings{Mode: v1alpha3.TLSmodeIstioMutual},
+			}},
+		})
+		rule.Spec.Subsets = []v1alpha3.Subset{{
+			Name: "v1",
+			TrafficPolicy: &v1alpha3.TrafficPolicy{
+				TLS: &v1alpha3.TLSSettings{Mode: v1alpha3.TLSmodeSimple, CaCertificates: "contoso-root"},
+			},
+		}}
+
+		settings := settingsFor(rule)
+		Expect(settings.Protocol).To(Equal(n.ApplicationGatewayProtocolHTTPS))
+		Expect(settings.HostName).To(BeNil())
+		Expect(settings.PickHostNameFromBackendAddress).To(Equal(to.BoolPtr(true)))
+		Expect(*settings.TrustedRootCertificates).To(HaveLen(1))
+	})
+
+	It("prefers the DestinationRule of the exact host over a wildcard one", func() {
+		settings := settingsFor(
+			newDestinationRule("all", "*.web.svc.cluster.local", &v1alpha3.TrafficPolicy{
+				TLS: &v1alpha3.TLSSettings{Mode: v1alpha3.TLSmodeSimple},
+			}),
+			newDestinationRule("web-v1", "web-v1", &v1alpha3.TrafficPolicy{
+				TLS: &v1alpha3.TLSSettings{Mode: v1alpha3.TLSmodeDisable},
+			}),
+		)
+		Expect(settings.Protocol).To(Equal(n.ApplicationGatewayProtocolHTTP))
+	})
+})
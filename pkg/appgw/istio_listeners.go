@@ -6,48 +6,193 @@
 package appgw
 
 import (
-	"github.com/Azure/application-gateway-kubernetes-ingress/pkg/environment"
+	"encoding/base64"
+	"fmt"
+	"strings"
+
 	n "github.com/Azure/azure-sdk-for-go/services/network/mgmt/2021-03-01/network"
+	"github.com/Azure/go-autorest/autorest/to"
 	"github.com/knative/pkg/apis/istio/v1alpha3"
+	v1 "k8s.io/api/core/v1"
 	"k8s.io/klog/v2"
+
+	"github.com/Azure/application-gateway-kubernetes-ingress/pkg/events"
+	"github.com/Azure/application-gateway-kubernetes-ingress/pkg/gatewayapi"
 )
 
-func (c *appGwConfigBuilder) getListenerConfigsFromIstio(istioGateways []*v1alpha3.Gateway, istioVirtualServices []*v1alpha3.VirtualService, env environment.EnvVariables) map[listenerIdentifier]listenerAzConfig {
-	knownHosts := make(map[string]interface{})
-	for _, virtualService := range istioVirtualServices {
-		for _, host := range virtualService.Spec.Hosts {
-			knownHosts[host] = nil
+func (c *appGwConfigBuilder) getListenerConfigsFromIstio(cbCtx *ConfigBuilderContext) map[listenerIdentifier]listenerAzConfig {
+	allListeners := make(map[listenerIdentifier]listenerAzConfig)
+	for listenerID, config := range c.getIstioConfig(cbCtx).listenerConfigs {
+		allListeners[listenerID] = config
+	}
+
+	// App Gateway must have at least one listener - the default one!
+	if len(allListeners) == 0 {
+		// TODO(aksgupta): refactor to get environment variable
+		allListeners[defaultFrontendListenerIdentifier(c.appGw, cbCtx.EnvVariables)] = listenerAzConfig{
+			// Default protocol
+			Protocol: n.ApplicationGatewayProtocolHTTP,
 		}
 	}
 
-	allListeners := make(map[listenerIdentifier]listenerAzConfig)
-	for _, igwy := range istioGateways {
-		for _, server := range igwy.Spec.Servers {
-			if server.Port.Protocol != v1alpha3.ProtocolHTTP {
-				klog.Infof("[istio] AGIC does not support Gateway with Server.Port.Protocol=%+v", server.Port.Protocol)
+	return allListeners
+}
+
+// getIstioListeners returns the App Gateway listeners serving the hosts of the VirtualService on the servers of the Gateways it is bound to.
+// A listener holds up to MaxAllowedHostNames hosts, so a server serving more hosts of the VirtualService gets several listeners.
+func (c *appGwConfigBuilder) getIstioListeners(cbCtx *ConfigBuilderContext, config *istioConfig, virtualService *v1alpha3.VirtualService) []listenerIdentifier {
+	var listenerIDs []listenerIdentifier
+	for _, gateway := range cbCtx.IstioGateways {
+		if !isIstioGatewayBound(virtualService, gateway) {
+			continue
+		}
+		for serverIdx, server := range gateway.Spec.Servers {
+			hosts, wildcard := istioServerHosts(gateway, server, virtualService)
+			if len(hosts) == 0 && !wildcard {
 				continue
 			}
-			for _, host := range server.Hosts {
-				if _, exist := knownHosts[host]; !exist {
-					continue
+			azConfig := c.getIstioServerConfig(config, gateway, serverIdx, server)
+			if azConfig == nil {
+				continue
+			}
+
+			var hostGroups [][]string
+			for start := 0; start < len(hosts); start += MaxAllowedHostNames {
+				end := start + MaxAllowedHostNames
+				if end > len(hosts) {
+					end = len(hosts)
 				}
+				hostGroups = append(hostGroups, hosts[start:end])
+			}
+			if wildcard {
+				// A VirtualService for all hosts is served by a listener without host names.
+				hostGroups = append(hostGroups, nil)
+			}
+
+			for _, hostGroup := range hostGroups {
 				listenerID := listenerIdentifier{
 					FrontendPort: Port(server.Port.Number),
-					HostNames:    [5]string{host},
+					FrontendType: defaultFrontendType(c.appGw, cbCtx.EnvVariables),
 				}
-				allListeners[listenerID] = listenerAzConfig{Protocol: n.ApplicationGatewayProtocolHTTP}
+				listenerID.setHostNames(hostGroup)
+				config.listenerConfigs[listenerID] = *azConfig
+				c.getIstioPathMap(config, listenerID)
+				listenerIDs = append(listenerIDs, listenerID)
 			}
 		}
 	}
+	return listenerIDs
+}
 
-	// App Gateway must have at least one listener - the default one!
-	if len(allListeners) == 0 {
-		// TODO(aksgupta): refactor to get environment variable
-		allListeners[defaultFrontendListenerIdentifier(c.appGw, env)] = listenerAzConfig{
-			// Default protocol
-			Protocol: n.ApplicationGatewayProtocolHTTP,
+// getIstioServerConfig returns the listener config of the Gateway server, resolving the certificate of HTTPS servers on first use.
+// It returns nil when App Gateway cannot serve the server; The reason is recorded on the Gateway.
+func (c *appGwConfigBuilder) getIstioServerConfig(config *istioConfig, gateway *v1alpha3.Gateway, serverIdx int, server v1alpha3.Server) *listenerAzConfig {
+	key := istioServerKey{
+		Namespace: gateway.Namespace,
+		Gateway:   gateway.Name,
+		Server:    serverIdx,
+	}
+	if azConfig, exists := config.servers[key]; exists {
+		return azConfig
+	}
+	config.servers[key] = nil
+
+	switch server.Port.Protocol {
+	case v1alpha3.ProtocolHTTP:
+		if server.TLS != nil && server.TLS.HTTPSRedirect {
+			c.istioGatewayEvent(gateway, fmt.Sprintf("AGIC does not support httpsRedirect; Server %d on port %d serves HTTP", serverIdx, server.Port.Number))
+		}
+		config.servers[key] = &listenerAzConfig{Protocol: n.ApplicationGatewayProtocolHTTP}
+	case v1alpha3.ProtocolHTTPS:
+		if server.TLS == nil || (server.TLS.Mode != "" && server.TLS.Mode != v1alpha3.TLSModeSimple) {
+			mode := ""
+			if server.TLS != nil {
+				mode = string(server.TLS.Mode)
+			}
+			c.istioGatewayEvent(gateway, fmt.Sprintf("Server %d on port %d was dropped: Application Gateway only terminates TLS in SIMPLE mode, not %q", serverIdx, server.Port.Number, mode))
+			return nil
+		}
+		if server.TLS.CredentialName == "" {
+			c.istioGatewayEvent(gateway, fmt.Sprintf("Server %d on port %d was dropped: AGIC needs the certificate in a Secret referenced by credentialName", serverIdx, server.Port.Number))
+			return nil
+		}
+		secret := secretIdentifier{
+			Namespace: gateway.Namespace,
+			Name:      server.TLS.CredentialName,
+		}
+		cert := c.k8sContext.CertificateSecretStore.GetPfxCertificate(secret.secretKey())
+		if cert == nil {
+			message := fmt.Sprintf("Unable to find the secret associated to secretId: [%s]", secret.secretKey())
+			klog.Warning(message)
+			c.recorder.Event(gateway, v1.EventTypeWarning, events.ReasonSecretNotFound, message)
+			return nil
+		}
+		config.certificates[secret] = to.StringPtr(base64.StdEncoding.EncodeToString(cert))
+		config.servers[key] = &listenerAzConfig{
+			Protocol: n.ApplicationGatewayProtocolHTTPS,
+			Secret:   secret,
 		}
+	default:
+		klog.Infof("[istio] AGIC does not support Gateway with Server.Port.Protocol=%+v", server.Port.Protocol)
 	}
 
-	return allListeners
+	return config.servers[key]
+}
+
+// isIstioGatewayBound tells whether the VirtualService applies to the Gateway.
+// Gateways are referenced by name, in the namespace of the VirtualService, or as <namespace>/<name>; A VirtualService referencing no Gateway applies to all of them.
+func isIstioGatewayBound(virtualService *v1alpha3.VirtualService, gateway *v1alpha3.Gateway) bool {
+	if len(virtualService.Spec.Gateways) == 0 {
+		return true
+	}
+	for _, ref := range virtualService.Spec.Gateways {
+		if ref == gateway.Name || ref == gateway.Namespace+"/"+gateway.Name {
+			return true
+		}
+	}
+	return false
+}
+
+// istioServerHosts returns the hosts of the VirtualService the server serves; wildcard tells whether the VirtualService is for all hosts.
+func istioServerHosts(gateway *v1alpha3.Gateway, server v1alpha3.Server, virtualService *v1alpha3.VirtualService) ([]string, bool) {
+	var hosts []string
+	wildcard := false
+	seen := make(map[string]interface{})
+	for _, host := range virtualService.Spec.Hosts {
+		if _, exists := seen[host]; exists {
+			continue
+		}
+		for _, serverHost := range server.Hosts {
+			// Server hosts may be restricted to the VirtualServices of a namespace, e.g. prod/*.example.com; "." is the namespace of the Gateway.
+			if idx := strings.Index(serverHost, "/"); idx >= 0 {
+				namespace := serverHost[:idx]
+				if namespace == "." {
+					namespace = gateway.Namespace
+				}
+				if namespace != "*" && namespace != virtualService.Namespace {
+					continue
+				}
+				serverHost = serverHost[idx+1:]
+			}
+			if serverHost == "*" {
+				serverHost = ""
+			}
+			if !gatewayapi.HostnameMatches(serverHost, host) {
+				continue
+			}
+			seen[host] = nil
+			if host == "*" {
+				wildcard = true
+			} else {
+				hosts = append(hosts, host)
+			}
+			break
+		}
+	}
+	return hosts, wildcard
+}
+
+func (c *appGwConfigBuilder) istioGatewayEvent(gateway *v1alpha3.Gateway, message string) {
+	klog.Warningf("[istio] Gateway %s/%s: %s", gateway.Namespace, gateway.Name, message)
+	c.recorder.Event(gateway, v1.EventTypeWarning, events.ReasonUnsupportedIstioConfig, message)
 }
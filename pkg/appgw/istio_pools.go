@@ -14,7 +14,7 @@ import (
 )
 
 func (c *appGwConfigBuilder) getIstioBackendAddressPool(destinationID istioDestinationIdentifier, serviceBackendPair serviceBackendPortPair, addressPools map[string]*n.ApplicationGatewayBackendAddressPool) *n.ApplicationGatewayBackendAddressPool {
-	endpoints, err := c.k8sContext.GetEndpointsByService(destinationID.serviceKey())
+	endpointSlices, err := c.k8sContext.GetEndpointSlicesByService(destinationID.serviceKey())
 	if err != nil {
 		logLine := fmt.Sprintf("Failed fetching endpoints for service: %s", destinationID.serviceKey())
 		klog.Error(logLine)
@@ -22,27 +22,27 @@ func (c *appGwConfigBuilder) getIstioBackendAddressPool(destinationID istioDesti
 		return nil
 	}
 
-	for _, subset := range endpoints.Subsets {
-		if _, portExists := getUniqueTCPPorts(subset)[serviceBackendPair.BackendPort]; portExists {
-			backendServicePort := ""
-			if destinationID.DestinationPort != 0 {
-				backendServicePort = fmt.Sprint(destinationID.DestinationPort)
-			} else {
-				// TODO(delqn): lookup port by name
-			}
-			poolName := generateAddressPoolName(destinationID.serviceFullName(), backendServicePort, serviceBackendPair.BackendPort)
-			if pool, ok := addressPools[poolName]; ok {
-				return pool
-			}
-			pool := c.newPool(poolName, subset)
-			pool.ID = to.StringPtr(c.appGwIdentifier.AddressPoolID(poolName))
-			return pool
-		}
+	endpointSlices = endpointSlicesWithPort(endpointSlices, serviceBackendPair.BackendPort)
+	if len(endpointSlices) == 0 {
 		logLine := fmt.Sprintf("Backend target port %d does not have matching endpoint port", serviceBackendPair.BackendPort)
 		klog.Error(logLine)
 		//TODO(rhea): add recorder event for error
+		return nil
+	}
+
+	backendServicePort := ""
+	if destinationID.DestinationPort != 0 {
+		backendServicePort = fmt.Sprint(destinationID.DestinationPort)
+	} else {
+		// TODO(delqn): lookup port by name
+	}
+	poolName := generateAddressPoolName(destinationID.serviceFullName(), backendServicePort, serviceBackendPair.BackendPort)
+	if pool, ok := addressPools[poolName]; ok {
+		return pool
 	}
-	return nil
+	pool := c.newPool(poolName, endpointSlices, isEndpointInPool)
+	pool.ID = to.StringPtr(c.appGwIdentifier.AddressPoolID(poolName))
+	return pool
 }
 
 func (c *appGwConfigBuilder) newIstioBackendPoolMap(cbCtx *ConfigBuilderContext) map[istioDestinationIdentifier]*n.ApplicationGatewayBackendAddressPool {
@@ -6,90 +6,22 @@
 package appgw
 
 import (
-	"strconv"
+	"fmt"
+	"sort"
 
 	n "github.com/Azure/azure-sdk-for-go/services/network/mgmt/2021-03-01/network"
 	"github.com/Azure/go-autorest/autorest/to"
+	"github.com/knative/pkg/apis/istio/v1alpha3"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/klog/v2"
+
+	"github.com/Azure/application-gateway-kubernetes-ingress/pkg/events"
 )
 
 func (c *appGwConfigBuilder) getIstioPathMaps(cbCtx *ConfigBuilderContext) map[listenerIdentifier]*n.ApplicationGatewayURLPathMap {
-	defaultAddressPoolID := to.StringPtr(c.appGwIdentifier.AddressPoolID(DefaultBackendAddressPoolName))
-	defaultHTTPSettingsID := to.StringPtr(c.appGwIdentifier.HTTPSettingsID(DefaultBackendHTTPSettingsName))
-
-	// TODO(delqn)
-	istioHTTPSettings, _, _, _ := c.getIstioDestinationsAndSettingsMap(cbCtx)
-
-	backendByDestination := c.newIstioBackendPoolMap(cbCtx)
-
 	urlPathMaps := make(map[listenerIdentifier]*n.ApplicationGatewayURLPathMap)
-	for virtSvcIdx, virtSvc := range cbCtx.IstioVirtualServices {
-		for _, http := range virtSvc.Spec.HTTP {
-			// TODO(delqn): consider weights
-			host := http.Route[0].Destination.Host
-			var port uint32
-			if http.Route[0].Destination.Port.Number != 0 {
-				port = http.Route[0].Destination.Port.Number
-			} else {
-				port64, _ := strconv.ParseUint(http.Route[0].Destination.Port.Name, 10, 32)
-				port = uint32(port64)
-			}
-			for matchIdx, match := range http.Match {
-				dst := istioDestinationIdentifier{
-					serviceIdentifier: serviceIdentifier{
-						Namespace: virtSvc.Namespace,
-						Name:      virtSvc.Name,
-					},
-					istioVirtualServiceIdentifier: istioVirtualServiceIdentifier{
-						Namespace: virtSvc.Namespace,
-						Name:      virtSvc.Name,
-					},
-					// TODO(delqn)
-					DestinationHost: host,
-					DestinationPort: port,
-				}
-
-				// TODO(delqn)
-				listenerID := listenerIdentifier{
-					FrontendPort: 80,
-					HostNames:    [5]string{virtSvc.Spec.Hosts[0]}, // TODO(delqn),
-				}
-				pool, found := backendByDestination[dst]
-
-				if !found {
-					continue
-				}
-				pathMapName := generateURLPathMapName(listenerID)
-				pathMap := n.ApplicationGatewayURLPathMap{
-					Etag: to.StringPtr("*"),
-					Name: to.StringPtr(pathMapName),
-					ID:   to.StringPtr(c.appGwIdentifier.urlPathMapID(pathMapName)),
-					ApplicationGatewayURLPathMapPropertiesFormat: &n.ApplicationGatewayURLPathMapPropertiesFormat{
-						DefaultBackendAddressPool:  &n.SubResource{ID: defaultAddressPoolID},
-						DefaultBackendHTTPSettings: &n.SubResource{ID: defaultHTTPSettingsID},
-						PathRules:                  &[]n.ApplicationGatewayPathRule{},
-					},
-				}
-
-				pathRuleName := generatePathRuleName(virtSvc.Namespace, virtSvc.Name, virtSvcIdx, matchIdx)
-				pathRule := n.ApplicationGatewayPathRule{
-					Etag: to.StringPtr("*"),
-					Name: to.StringPtr(pathRuleName),
-					ID:   to.StringPtr(c.appGwIdentifier.pathRuleID(pathMapName, pathRuleName)),
-					ApplicationGatewayPathRulePropertiesFormat: &n.ApplicationGatewayPathRulePropertiesFormat{
-						Paths: &[]string{
-							match.URI.Prefix,
-						},
-						BackendAddressPool: &n.SubResource{ID: pool.ID},
-						// TODO(delqn)
-						BackendHTTPSettings: &n.SubResource{ID: istioHTTPSettings[0].ID},
-					},
-				}
-				pathMap.PathRules = &[]n.ApplicationGatewayPathRule{
-					pathRule,
-				}
-				urlPathMaps[listenerID] = &pathMap
-			}
-		}
+	for listenerID, pathMap := range c.getIstioConfig(cbCtx).pathMaps {
+		urlPathMaps[listenerID] = pathMap
 	}
 
 	// if no url pathmaps were created, then add a default path map since this will be translated to
@@ -114,3 +46,209 @@ func (c *appGwConfigBuilder) getIstioPathMaps(cbCtx *ConfigBuilderContext) map[l
 
 	return urlPathMaps
 }
+
+// addIstioVirtualService adds the HTTP routes of the VirtualService to the path maps of the listeners serving its hosts.
+// Routes and matches App Gateway cannot express are dropped and recorded as events on the VirtualService.
+func (c *appGwConfigBuilder) addIstioVirtualService(config *istioConfig, virtualService *v1alpha3.VirtualService, listenerIDs []listenerIdentifier, settingsByDestination map[istioDestinationIdentifier]*n.ApplicationGatewayBackendHTTPSettings, poolsByDestination map[istioDestinationIdentifier]*n.ApplicationGatewayBackendAddressPool) {
+	for ruleIdx, rule := range virtualService.Spec.HTTP {
+		if rule.Redirect != nil {
+			c.istioVirtualServiceEvent(virtualService, fmt.Sprintf("HTTP route %d was dropped: AGIC does not support redirects", ruleIdx))
+			continue
+		}
+		backend := c.getIstioBackend(config, virtualService, ruleIdx, rule, settingsByDestination, poolsByDestination)
+
+		matches := rule.Match
+		if len(matches) == 0 {
+			// A route without matches matches all requests.
+			matches = []v1alpha3.HTTPMatchRequest{{}}
+		}
+		for matchIdx, match := range matches {
+			paths, message := istioMatchPaths(match)
+			if message != "" {
+				c.istioVirtualServiceEvent(virtualService, fmt.Sprintf("Match %d of HTTP route %d was dropped: %s", matchIdx, ruleIdx, message))
+				continue
+			}
+			for _, listenerID := range listenerIDs {
+				c.addIstioPathRule(config, listenerID, generatePathRuleName(virtualService.Namespace, virtualService.Name, ruleIdx, matchIdx), paths, backend)
+			}
+		}
+	}
+}
+
+// getIstioBackend resolves the weighted destinations of the HTTP route into a pool and HTTP settings.
+// A route without any resolved destination is forwarded to the default backend, which has no servers.
+func (c *appGwConfigBuilder) getIstioBackend(config *istioConfig, virtualService *v1alpha3.VirtualService, ruleIdx int, rule v1alpha3.HTTPRoute, settingsByDestination map[istioDestinationIdentifier]*n.ApplicationGatewayBackendHTTPSettings, poolsByDestination map[istioDestinationIdentifier]*n.ApplicationGatewayBackendAddressPool) istioBackend {
+	defaultBackend := istioBackend{
+		pool:     resourceRef(c.appGwIdentifier.AddressPoolID(DefaultBackendAddressPoolName)),
+		settings: resourceRef(c.appGwIdentifier.HTTPSettingsID(DefaultBackendHTTPSettingsName)),
+	}
+
+	type weightedDestination struct {
+		host     string
+		pool     *n.ApplicationGatewayBackendAddressPool
+		settings *n.ApplicationGatewayBackendHTTPSettings
+		weight   int32
+	}
+	var destinations []weightedDestination
+	for _, route := range rule.Route {
+		weight := int32(route.Weight)
+		if len(rule.Route) == 1 {
+			// A single destination receives all the traffic, whatever its weight.
+			weight = 100
+		}
+		if weight <= 0 {
+			continue
+		}
+		destinationID := generateIstioDestinationID(virtualService, &route.Destination)
+		settings, settingsExist := settingsByDestination[destinationID]
+		pool, poolExists := poolsByDestination[destinationID]
+		if !settingsExist || !poolExists {
+			klog.Warningf("[istio] Destination %s of HTTP route %d of VirtualService %s/%s could not be resolved", route.Destination.Host, ruleIdx, virtualService.Namespace, virtualService.Name)
+			continue
+		}
+		destinations = append(destinations, weightedDestination{host: route.Destination.Host, pool: pool, settings: settings, weight: weight})
+	}
+	if len(destinations) == 0 {
+		return defaultBackend
+	}
+
+	// The heaviest destination provides the HTTP settings and the pool App Gateway falls back to.
+	sort.SliceStable(destinations, func(i, j int) bool {
+		return destinations[i].weight > destinations[j].weight
+	})
+	heaviest := destinations[0]
+	backend := istioBackend{
+		pool:     resourceRef(*heaviest.pool.ID),
+		settings: resourceRef(*heaviest.settings.ID),
+	}
+	if len(destinations) == 1 {
+		return backend
+	}
+
+	var pools []weightedPool
+	for _, destination := range destinations {
+		if *destination.settings.Port != *heaviest.settings.Port {
+			c.istioVirtualServiceEvent(virtualService, fmt.Sprintf(
+				"Application Gateway can only split traffic between destinations listening on the same port; HTTP route %d forwards all traffic to %s", ruleIdx, heaviest.host))
+			return backend
+		}
+		pools = append(pools, weightedPool{pool: destination.pool, weight: destination.weight})
+	}
+
+	if policy := c.newLoadDistributionPolicy(generateIstioLoadDistributionPolicyName(virtualService.Namespace, virtualService.Name, ruleIdx), pools); policy != nil {
+		config.loadDistributionPolicies[*policy.Name] = *policy
+		backend.loadDistributionPolicy = resourceRef(*policy.ID)
+	}
+	return backend
+}
+
+// getIstioPathMap returns the path map of the listener, creating it with the default backend on first use.
+func (c *appGwConfigBuilder) getIstioPathMap(config *istioConfig, listenerID listenerIdentifier) *n.ApplicationGatewayURLPathMap {
+	if pathMap, exists := config.pathMaps[listenerID]; exists {
+		return pathMap
+	}
+	pathMapName := generateURLPathMapName(listenerID)
+	pathMap := &n.ApplicationGatewayURLPathMap{
+		Etag: to.StringPtr("*"),
+		Name: to.StringPtr(pathMapName),
+		ID:   to.StringPtr(c.appGwIdentifier.urlPathMapID(pathMapName)),
+		ApplicationGatewayURLPathMapPropertiesFormat: &n.ApplicationGatewayURLPathMapPropertiesFormat{
+			DefaultBackendAddressPool:  resourceRef(c.appGwIdentifier.AddressPoolID(DefaultBackendAddressPoolName)),
+			DefaultBackendHTTPSettings: resourceRef(c.appGwIdentifier.HTTPSettingsID(DefaultBackendHTTPSettingsName)),
+			PathRules:                  &[]n.ApplicationGatewayPathRule{},
+		},
+	}
+	config.pathMaps[listenerID] = pathMap
+	config.paths[listenerID] = make(map[string]interface{})
+	return pathMap
+}
+
+// addIstioPathRule routes the paths on the listener to the backend; No paths stands for all paths, i.e. the default of the path map.
+// Paths already routed by an earlier route are skipped, since Istio applies the first matching route.
+func (c *appGwConfigBuilder) addIstioPathRule(config *istioConfig, listenerID listenerIdentifier, pathRuleName string, paths []string, backend istioBackend) {
+	pathMap := c.getIstioPathMap(config, listenerID)
+
+	if len(paths) == 0 {
+		if _, exists := config.catchAll[listenerID]; exists {
+			klog.V(3).Infof("Path map %s already has a default backend; Skipping path rule %s", *pathMap.Name, pathRuleName)
+			return
+		}
+		config.catchAll[listenerID] = nil
+		pathMap.DefaultBackendAddressPool = backend.pool
+		pathMap.DefaultBackendHTTPSettings = backend.settings
+		pathMap.DefaultLoadDistributionPolicy = backend.loadDistributionPolicy
+		return
+	}
+
+	var newPaths []string
+	for _, path := range paths {
+		if _, exists := config.paths[listenerID][path]; exists {
+			klog.V(3).Infof("Path %s is already routed by path map %s; Skipping it in path rule %s", path, *pathMap.Name, pathRuleName)
+			continue
+		}
+		config.paths[listenerID][path] = nil
+		newPaths = append(newPaths, path)
+	}
+	if len(newPaths) == 0 {
+		return
+	}
+
+	*pathMap.PathRules = append(*pathMap.PathRules, n.ApplicationGatewayPathRule{
+		Etag: to.StringPtr("*"),
+		Name: to.StringPtr(pathRuleName),
+		ID:   to.StringPtr(c.appGwIdentifier.pathRuleID(*pathMap.Name, pathRuleName)),
+		ApplicationGatewayPathRulePropertiesFormat: &n.ApplicationGatewayPathRulePropertiesFormat{
+			Paths:                  &newPaths,
+			BackendAddressPool:     backend.pool,
+			BackendHTTPSettings:    backend.settings,
+			LoadDistributionPolicy: backend.loadDistributionPolicy,
+		},
+	})
+}
+
+// istioMatchPaths translates the match into the paths of a path rule; No paths stands for all paths.
+// App Gateway routes on the path only, so the match may test nothing but the URI, with an exact or prefix match.
+// When the match cannot be expressed, the message tells why.
+func istioMatchPaths(match v1alpha3.HTTPMatchRequest) ([]string, string) {
+	switch {
+	case match.Method != nil:
+		return nil, "Application Gateway cannot route on the request method"
+	case match.Scheme != nil:
+		return nil, "Application Gateway cannot route on the request scheme"
+	case match.Authority != nil:
+		return nil, "Application Gateway cannot route on the authority"
+	case len(match.Headers) > 0:
+		return nil, "Application Gateway cannot route on headers"
+	case match.Port != 0:
+		return nil, "Application Gateway cannot route on the port of the match"
+	case len(match.SourceLabels) > 0:
+		return nil, "Application Gateway cannot route on source labels"
+	}
+
+	uri := match.URI
+	switch {
+	case uri == nil:
+		return nil, ""
+	case uri.Exact != "":
+		if uri.Exact == "/" {
+			// App Gateway requires paths to have a non-empty value after the leading '/'.
+			return nil, "Application Gateway cannot match the exact URI /"
+		}
+		return []string{uri.Exact}, ""
+	case uri.Prefix != "":
+		if uri.Prefix == "/" {
+			return nil, ""
+		}
+		return []string{uri.Prefix + "*"}, ""
+	case uri.Suffix != "":
+		return nil, "Application Gateway cannot match URI suffixes"
+	case uri.Regex != "":
+		return nil, "Application Gateway cannot match URI regular expressions"
+	}
+	return nil, ""
+}
+
+func (c *appGwConfigBuilder) istioVirtualServiceEvent(virtualService *v1alpha3.VirtualService, message string) {
+	klog.Warningf("[istio] VirtualService %s/%s: %s", virtualService.Namespace, virtualService.Name, message)
+	c.recorder.Event(virtualService, v1.EventTypeWarning, events.ReasonUnsupportedIstioConfig, message)
+}
@@ -0,0 +1,274 @@
+// -------------------------------------------------------------------------------------------
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License. See License.txt in the project root for license information.
+// --------------------------------------------------------------------------------------------
+
+package appgw
+
+import (
+	"context"
+	"time"
+
+	n "github.com/Azure/azure-sdk-for-go/services/network/mgmt/2021-03-01/network"
+	"github.com/Azure/go-autorest/autorest/to"
+	"github.com/knative/pkg/apis/istio/common/v1alpha1"
+	"github.com/knative/pkg/apis/istio/v1alpha3"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	testclient "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/record"
+	gateway_fake "sigs.k8s.io/gateway-api/pkg/client/clientset/versioned/fake"
+
+	"github.com/Azure/application-gateway-kubernetes-ingress/pkg/crd_client/agic_crd_client/clientset/versioned/fake"
+	multiCluster_fake "github.com/Azure/application-gateway-kubernetes-ingress/pkg/crd_client/azure_multicluster_crd_client/clientset/versioned/fake"
+	istio_fake "github.com/Azure/application-gateway-kubernetes-ingress/pkg/crd_client/istio_crd_client/clientset/versioned/fake"
+	"github.com/Azure/application-gateway-kubernetes-ingress/pkg/environment"
+	"github.com/Azure/application-gateway-kubernetes-ingress/pkg/events"
+	"github.com/Azure/application-gateway-kubernetes-ingress/pkg/k8scontext"
+	"github.com/Azure/application-gateway-kubernetes-ingress/pkg/metricstore"
+	"github.com/Azure/application-gateway-kubernetes-ingress/pkg/tests"
+	"github.com/Azure/application-gateway-kubernetes-ingress/pkg/tests/mocks"
+	"github.com/Azure/application-gateway-kubernetes-ingress/pkg/utils"
+)
+
+var _ = Describe("Test Istio VirtualService translation", func() {
+	namespace := "web"
+
+	var ctxt *k8scontext.Context
+	var cb *appGwConfigBuilder
+	var cbCtx *ConfigBuilderContext
+	var recorder *record.FakeRecorder
+	var gateway *v1alpha3.Gateway
+	var virtualService *v1alpha3.VirtualService
+
+	BeforeEach(func() {
+		k8sClient := testclient.NewSimpleClientset()
+		for _, s := range []struct {
+			name       string
+			targetPort int32
+			ip         string
+		}{
+			{"web-v1", 8080, "10.0.0.1"},
+			{"web-v2", 8080, "10.0.0.2"},
+			{"web-api", 9090, "10.0.0.3"},
+		} {
+			service := &v1.Service{
+				ObjectMeta: metav1.ObjectMeta{Name: s.name, Namespace: namespace},
+				Spec: v1.ServiceSpec{
+					Ports: []v1.ServicePort{{
+						Protocol:   v1.ProtocolTCP,
+						Port:       80,
+						TargetPort: intstr.FromInt(int(s.targetPort)),
+					}},
+				},
+			}
+			endpoints := &v1.Endpoints{
+				ObjectMeta: metav1.ObjectMeta{Name: s.name, Namespace: namespace},
+				Subsets: []v1.EndpointSubset{{
+					Addresses: []v1.EndpointAddress{{IP: s.ip}},
+					Ports:     []v1.EndpointPort{{Protocol: v1.ProtocolTCP, Port: s.targetPort}},
+				}},
+			}
+			_, _ = k8sClient.CoreV1().Services(namespace).Create(context.TODO(), service, metav1.CreateOptions{})
+			_ = createEndpointsFixture(k8sClient, endpoints)
+		}
+
+		k8scontext.IsNetworkingV1PackageSupported = true
+		ctxt = k8scontext.NewContext(k8sClient, fake.NewSimpleClientset(), multiCluster_fake.NewSimpleClientset(), istio_fake.NewSimpleClientset(), gateway_fake.NewSimpleClientset(), []string{namespace}, 1000*time.Second, metricstore.NewFakeMetricStore(), environment.GetFakeEnv())
+		Expect(ctxt.Run(make(chan struct{}), true, environment.GetFakeEnv())).To(Succeed())
+
+		appGw := &n.ApplicationGateway{ApplicationGatewayPropertiesFormat: NewAppGwyConfigFixture()}
+		recorder = record.NewFakeRecorder(100)
+		cb = NewConfigBuilder(ctxt, &Identifier{
+			SubscriptionID: tests.Subscription,
+			ResourceGroup:  tests.ResourceGroup,
+			AppGwName:      tests.AppGwName,
+		}, appGw, recorder, mocks.Clock{}).(*appGwConfigBuilder)
+
+		gateway = &v1alpha3.Gateway{
+			ObjectMeta: metav1.ObjectMeta{Name: "web-gateway", Namespace: namespace},
+			Spec: v1alpha3.GatewaySpec{
+				Servers: []v1alpha3.Server{{
+					Port:  v1alpha3.Port{Number: 8000, Protocol: v1alpha3.ProtocolHTTP, Name: "http"},
+					Hosts: []string{"*.contoso.com"},
+				}},
+			},
+		}
+		virtualService = &v1alpha3.VirtualService{
+			ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: namespace},
+			Spec: v1alpha3.VirtualServiceSpec{
+				Hosts:    []string{"www.contoso.com", "web.contoso.com", "www.fabrikam.com"},
+				Gateways: []string{"web-gateway"},
+				HTTP: []v1alpha3.HTTPRoute{
+					{
+						Match: []v1alpha3.HTTPMatchRequest{{URI: &v1alpha1.StringMatch{Exact: "/login"}}},
+						Route: []v1alpha3.HTTPRouteDestination{{Destination: v1alpha3.Destination{Host: "web-api", Port: v1alpha3.PortSelector{Number: 80}}}},
+					},
+					{
+						Match: []v1alpha3.HTTPMatchRequest{{URI: &v1alpha1.StringMatch{Prefix: "/api"}}},
+						Route: []v1alpha3.HTTPRouteDestination{{Destination: v1alpha3.Destination{Host: "web-api", Port: v1alpha3.PortSelector{Number: 80}}}},
+					},
+					{
+						Route: []v1alpha3.HTTPRouteDestination{
+							{Destination: v1alpha3.Destination{Host: "web-v1", Port: v1alpha3.PortSelector{Number: 80}}, Weight: 90},
+							{Destination: v1alpha3.Destination{Host: "web-v2", Port: v1alpha3.PortSelector{Number: 80}}, Weight: 10},
+						},
+					},
+				},
+			},
+		}
+
+		env := environment.GetFakeEnv()
+		env.EnableIstioIntegration = true
+		cbCtx = &ConfigBuilderContext{
+			EnvVariables:          env,
+			DefaultAddressPoolID:  to.StringPtr(cb.appGwIdentifier.AddressPoolID(DefaultBackendAddressPoolName)),
+			DefaultHTTPSettingsID: to.StringPtr(cb.appGwIdentifier.HTTPSettingsID(DefaultBackendHTTPSettingsName)),
+		}
+	})
+
+	pathsOf := func(pathMap *n.ApplicationGatewayURLPathMap) map[string]string {
+		poolByPath := make(map[string]string)
+		for _, pathRule := range *pathMap.PathRules {
+			for _, path := range *pathRule.Paths {
+				poolByPath[path] = *pathRule.BackendAddressPool.ID
+			}
+		}
+		return poolByPath
+	}
+
+	drainEvents := func() []string {
+		var recorded []string
+		for len(recorder.Events) > 0 {
+			recorded = append(recorded, <-recorder.Events)
+		}
+		return recorded
+	}
+
+	It("routes all the routes of the VirtualService on the hosts the Gateway server serves", func() {
+		cbCtx.IstioGateways = []*v1alpha3.Gateway{gateway}
+		cbCtx.IstioVirtualServices = []*v1alpha3.VirtualService{virtualService}
+
+		listenerID := listenerIdentifier{FrontendPort: Port(8000), FrontendType: FrontendTypePublic}
+		listenerID.setHostNames([]string{"www.contoso.com", "web.contoso.com"})
+		Expect(cb.getListenerConfigsFromIstio(cbCtx)).To(Equal(map[listenerIdentifier]listenerAzConfig{
+			listenerID: {Protocol: n.ApplicationGatewayProtocolHTTP},
+		}))
+
+		pathMaps := cb.getIstioPathMaps(cbCtx)
+		Expect(pathMaps).To(HaveLen(1))
+		Expect(pathMaps).To(HaveKey(listenerID))
+		pathMap := pathMaps[listenerID]
+
+		apiPoolID := cb.appGwIdentifier.AddressPoolID(generateAddressPoolName("web-web-api", "80", 9090))
+		Expect(pathsOf(pathMap)).To(Equal(map[string]string{
+			"/login": apiPoolID,
+			"/api*":  apiPoolID,
+		}))
+
+		// The weighted destinations of the catch-all route are split by a load distribution policy.
+		policyName := generateIstioLoadDistributionPolicyName(namespace, "web", 2)
+		Expect(*pathMap.DefaultBackendAddressPool.ID).To(Equal(cb.appGwIdentifier.AddressPoolID(generateAddressPoolName("web-web-v1", "80", 8080))))
+		Expect(*pathMap.DefaultLoadDistributionPolicy.ID).To(Equal(cb.appGwIdentifier.loadDistributionPolicyID(policyName)))
+
+		policies := cb.getLoadDistributionPolicies(cbCtx, nil, []n.ApplicationGatewayURLPathMap{*pathMap})
+		Expect(policies).To(HaveLen(1))
+		targets := *policies[0].LoadDistributionTargets
+		Expect(targets).To(HaveLen(2))
+		Expect(*targets[0].WeightPerServer).To(Equal(int32(100)))
+		Expect(*targets[1].WeightPerServer).To(Equal(int32(11)))
+		Expect(drainEvents()).To(BeEmpty())
+	})
+
+	It("drops matches App Gateway cannot express with an event", func() {
+		virtualService.Spec.HTTP[0].Match = []v1alpha3.HTTPMatchRequest{
+			{URI: &v1alpha1.StringMatch{Regex: "/log(in|out)"}},
+			{Headers: map[string]v1alpha1.StringMatch{"x-beta": {Exact: "true"}}},
+		}
+		cbCtx.IstioGateways = []*v1alpha3.Gateway{gateway}
+		cbCtx.IstioVirtualServices = []*v1alpha3.VirtualService{virtualService}
+
+		listenerID := listenerIdentifier{FrontendPort: Port(8000), FrontendType: FrontendTypePublic}
+		listenerID.setHostNames([]string{"www.contoso.com", "web.contoso.com"})
+		pathMaps := cb.getIstioPathMaps(cbCtx)
+		Expect(pathMaps).To(HaveKey(listenerID))
+		Expect(pathsOf(pathMaps[listenerID])).To(HaveLen(1))
+		Expect(pathsOf(pathMaps[listenerID])).To(HaveKey("/api*"))
+
+		recorded := drainEvents()
+		Expect(recorded).To(HaveLen(2))
+		Expect(recorded[0]).To(ContainSubstring(events.ReasonUnsupportedIstioConfig))
+		Expect(recorded[0]).To(ContainSubstring("regular expressions"))
+		Expect(recorded[1]).To(ContainSubstring("headers"))
+	})
+
+	It("does not split traffic between destinations listening on different ports", func() {
+		virtualService.Spec.HTTP[2].Route[1].Destination.Host = "web-api"
+		cbCtx.IstioGateways = []*v1alpha3.Gateway{gateway}
+		cbCtx.IstioVirtualServices = []*v1alpha3.VirtualService{virtualService}
+
+		for _, pathMap := range cb.getIstioPathMaps(cbCtx) {
+			Expect(pathMap.DefaultLoadDistributionPolicy).To(BeNil())
+			Expect(*pathMap.DefaultBackendAddressPool.ID).To(Equal(cb.appGwIdentifier.AddressPoolID(generateAddressPoolName("web-web-v1", "80", 8080))))
+		}
+
+		recorded := drainEvents()
+		Expect(recorded).To(HaveLen(1))
+		Expect(recorded[0]).To(ContainSubstring("same port"))
+	})
+
+	It("serves HTTPS servers with the certificate of their credentialName", func() {
+		secret := tests.NewSecretTestFixture()
+		secret.Namespace = namespace
+		secret.Name = "web-tls"
+		secKey := utils.GetResourceKey(secret.Namespace, secret.Name)
+		Expect(ctxt.CertificateSecretStore.ConvertSecret(secKey, secret)).To(Succeed())
+
+		gateway.Spec.Servers = append(gateway.Spec.Servers,
+			v1alpha3.Server{
+				Port:  v1alpha3.Port{Number: 443, Protocol: v1alpha3.ProtocolHTTPS, Name: "https"},
+				Hosts: []string{"www.contoso.com"},
+				TLS:   &v1alpha3.TLSOptions{Mode: v1alpha3.TLSModeSimple, CredentialName: "web-tls"},
+			},
+			v1alpha3.Server{
+				Port:  v1alpha3.Port{Number: 8443, Protocol: v1alpha3.ProtocolHTTPS, Name: "https-missing"},
+				Hosts: []string{"web.contoso.com"},
+				TLS:   &v1alpha3.TLSOptions{Mode: v1alpha3.TLSModeSimple, CredentialName: "missing"},
+			},
+		)
+		cbCtx.IstioGateways = []*v1alpha3.Gateway{gateway}
+		cbCtx.IstioVirtualServices = []*v1alpha3.VirtualService{virtualService}
+
+		httpsListenerID := listenerIdentifier{FrontendPort: Port(443), FrontendType: FrontendTypePublic}
+		httpsListenerID.setHostNames([]string{"www.contoso.com"})
+		listenerConfigs := cb.getListenerConfigsFromIstio(cbCtx)
+		Expect(listenerConfigs).To(HaveLen(2))
+		Expect(listenerConfigs).To(HaveKeyWithValue(httpsListenerID, listenerAzConfig{
+			Protocol: n.ApplicationGatewayProtocolHTTPS,
+			Secret:   secretIdentifier{Namespace: namespace, Name: "web-tls"},
+		}))
+		Expect(cb.getIstioPathMaps(cbCtx)).To(HaveKey(httpsListenerID))
+
+		cbCtx.ExistingPortsByNumber = make(map[Port]n.ApplicationGatewayFrontendPort)
+		listeners, _ := cb.getIstioListenersPorts(cbCtx)
+		var httpsListener *n.ApplicationGatewayHTTPListener
+		for idx := range listeners {
+			if listeners[idx].Protocol == n.ApplicationGatewayProtocolHTTPS {
+				httpsListener = &listeners[idx]
+			}
+		}
+		Expect(httpsListener).ToNot(BeNil())
+		Expect(*httpsListener.SslCertificate.ID).To(Equal(cb.appGwIdentifier.sslCertificateID(secretIdentifier{Namespace: namespace, Name: "web-tls"}.secretFullName())))
+
+		certificates := *cb.getSslCertificates(cbCtx)
+		Expect(certificates).To(HaveLen(1))
+		Expect(*certificates[0].Name).To(Equal(secretIdentifier{Namespace: namespace, Name: "web-tls"}.secretFullName()))
+
+		recorded := drainEvents()
+		Expect(recorded).To(HaveLen(1))
+		Expect(recorded[0]).To(ContainSubstring(events.ReasonSecretNotFound))
+	})
+})
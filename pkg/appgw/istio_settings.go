@@ -25,11 +25,9 @@ func istioMatchDestinationIds(cbCtx *ConfigBuilderContext) ([]istioMatchIdentifi
 		for _, rule := range virtualService.Spec.HTTP {
 			destinations := make([]*v1alpha3.Destination, 0)
 			for _, routeDestination := range rule.Route {
+				// Weights are applied by the load distribution policy of the route, see getIstioBackend.
 				if routeDestination.Weight != 0 {
 					destinations = append(destinations, &routeDestination.Destination)
-					/* TODO(rhea): Weights are being ignored for now, since this is not
-					yet supported on App Gateway. Include gates from routeDestination when
-					this is supported */
 				}
 				destinationID := generateIstioDestinationID(virtualService, &routeDestination.Destination)
 				destinationIDs[destinationID] = nil
@@ -201,7 +199,7 @@ func (c *appGwConfigBuilder) generateIstioHTTPSettings(destinationID istioDestin
 		},
 	}
 
-	//TODO(rhea): check relevant annotations and modify http settings accordingly
+	c.applyIstioDestinationRule(&httpSettings, destinationID, cbCtx)
 
 	return httpSettings
 }
@@ -5,7 +5,10 @@
 
 package appgw
 
-import "github.com/knative/pkg/apis/istio/v1alpha3"
+import (
+	n "github.com/Azure/azure-sdk-for-go/services/network/mgmt/2021-03-01/network"
+	"github.com/knative/pkg/apis/istio/v1alpha3"
+)
 
 type istioMatchIdentifier struct {
 	Namespace      string
@@ -29,3 +32,34 @@ type istioDestinationIdentifier struct {
 	DestinationSubset string
 	DestinationPort   uint32
 }
+
+// istioConfig is the App Gateway config translated from the Istio Gateways and VirtualServices.
+type istioConfig struct {
+	listenerConfigs          map[listenerIdentifier]listenerAzConfig
+	certificates             map[secretIdentifier]*string
+	pathMaps                 map[listenerIdentifier]*n.ApplicationGatewayURLPathMap
+	loadDistributionPolicies map[string]n.ApplicationGatewayLoadDistributionPolicy
+
+	// servers caches the listener config of a Gateway server; A nil value means App Gateway cannot serve it.
+	servers map[istioServerKey]*listenerAzConfig
+
+	// catchAll holds the listeners whose default backend was already set by a route.
+	catchAll map[listenerIdentifier]interface{}
+
+	// paths holds the paths already routed on each listener; The first route wins, as in Istio.
+	paths map[listenerIdentifier]map[string]interface{}
+}
+
+type istioServerKey struct {
+	Namespace string
+	Gateway   string
+	Server    int
+}
+
+// istioBackend is where App Gateway sends the requests matching an HTTP route of a VirtualService.
+// When the route splits traffic, the load distribution policy spreads requests over the pools of its destinations.
+type istioBackend struct {
+	pool                   *n.SubResource
+	settings               *n.SubResource
+	loadDistributionPolicy *n.SubResource
+}
@@ -0,0 +1,280 @@
+// -------------------------------------------------------------------------------------------
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License. See License.txt in the project root for license information.
+// --------------------------------------------------------------------------------------------
+
+package appgw
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	n "github.com/Azure/azure-sdk-for-go/services/network/mgmt/2021-03-01/network"
+	"github.com/Azure/go-autorest/autorest/to"
+	v1 "k8s.io/api/core/v1"
+	networking "k8s.io/api/networking/v1"
+	"k8s.io/klog/v2"
+
+	"github.com/Azure/application-gateway-kubernetes-ingress/pkg/annotations"
+	"github.com/Azure/application-gateway-kubernetes-ingress/pkg/events"
+)
+
+const (
+	// roleActive is the role of the targets of a LoadDistributionPolicy App Gateway sends traffic to.
+	roleActive = "active"
+)
+
+// weightedPool is a backend pool and the share of the traffic a load distribution policy sends to it.
+type weightedPool struct {
+	pool   *n.ApplicationGatewayBackendAddressPool
+	weight int32
+}
+
+// ingressLoadDistributionPolicy is the App Gateway config of a LoadDistributionPolicy referenced by Ingresses.
+type ingressLoadDistributionPolicy struct {
+	// policy is nil when fewer than two targets have endpoints.
+	policy *n.ApplicationGatewayLoadDistributionPolicy
+	pools  []*n.ApplicationGatewayBackendAddressPool
+
+	// targets are the services the policy sends traffic to and the port of their endpoints.
+	targets []loadDistributionTarget
+
+	// warnings tell which targets were left out.
+	warnings []string
+}
+
+type loadDistributionTarget struct {
+	service     string
+	backendPort Port
+}
+
+// newLoadDistributionPolicy creates the policy splitting traffic between the pools according to their weights.
+// App Gateway weighs each server of a pool, so the weight of a pool is spread over its servers; Pools without servers are left out.
+// It returns nil when fewer than two pools have servers.
+func (c *appGwConfigBuilder) newLoadDistributionPolicy(policyName string, pools []weightedPool) *n.ApplicationGatewayLoadDistributionPolicy {
+	type target struct {
+		pool            *n.ApplicationGatewayBackendAddressPool
+		weightPerServer float64
+	}
+	var targets []target
+	maxWeightPerServer := 0.0
+	for _, weighted := range pools {
+		if weighted.pool.BackendAddresses == nil || len(*weighted.pool.BackendAddresses) == 0 || weighted.weight <= 0 {
+			continue
+		}
+		weightPerServer := float64(weighted.weight) / float64(len(*weighted.pool.BackendAddresses))
+		maxWeightPerServer = math.Max(maxWeightPerServer, weightPerServer)
+		targets = append(targets, target{pool: weighted.pool, weightPerServer: weightPerServer})
+	}
+	if len(targets) < 2 {
+		return nil
+	}
+
+	var loadDistributionTargets []n.ApplicationGatewayLoadDistributionTarget
+	for idx, t := range targets {
+		targetName := fmt.Sprintf("target-%d", idx)
+		loadDistributionTargets = append(loadDistributionTargets, n.ApplicationGatewayLoadDistributionTarget{
+			Name: to.StringPtr(targetName),
+			ID:   to.StringPtr(c.appGwIdentifier.loadDistributionTargetID(policyName, targetName)),
+			ApplicationGatewayLoadDistributionTargetPropertiesFormat: &n.ApplicationGatewayLoadDistributionTargetPropertiesFormat{
+				// App Gateway accepts weights between 1 and 100.
+				WeightPerServer:    to.Int32Ptr(int32(math.Max(1, math.Round(100*t.weightPerServer/maxWeightPerServer)))),
+				BackendAddressPool: resourceRef(*t.pool.ID),
+			},
+		})
+	}
+
+	return &n.ApplicationGatewayLoadDistributionPolicy{
+		Etag: to.StringPtr("*"),
+		Name: to.StringPtr(policyName),
+		ID:   to.StringPtr(c.appGwIdentifier.loadDistributionPolicyID(policyName)),
+		ApplicationGatewayLoadDistributionPolicyPropertiesFormat: &n.ApplicationGatewayLoadDistributionPolicyPropertiesFormat{
+			LoadDistributionAlgorithm: n.ApplicationGatewayLoadDistributionAlgorithmRoundRobin,
+			LoadDistributionTargets:   &loadDistributionTargets,
+		},
+	}
+}
+
+// getIngressLoadDistributionPolicy translates the LoadDistributionPolicy in the namespace of the Ingress into a policy with one pool per target service.
+// It returns nil when the LoadDistributionPolicy does not exist.
+func (c *appGwConfigBuilder) getIngressLoadDistributionPolicy(cbCtx *ConfigBuilderContext, ingress *networking.Ingress, policyName string) *ingressLoadDistributionPolicy {
+	key := ingress.Namespace + "/" + policyName
+	if c.mem.loadDistributionPolicies == nil {
+		c.mem.loadDistributionPolicies = &map[string]*ingressLoadDistributionPolicy{}
+	}
+	if ldp, exists := (*c.mem.loadDistributionPolicies)[key]; exists {
+		return ldp
+	}
+	(*c.mem.loadDistributionPolicies)[key] = nil
+
+	resource, err := c.k8sContext.GetLoadDistributionPolicy(ingress.Namespace, policyName)
+	if err != nil {
+		return nil
+	}
+
+	ldp := &ingressLoadDistributionPolicy{}
+	var pools []weightedPool
+	for idx, target := range resource.Spec.Targets {
+		if target.Backend.Service == nil {
+			ldp.warnings = append(ldp.warnings, fmt.Sprintf("target %d of load distribution policy %s has no service", idx, key))
+			continue
+		}
+		if target.Role != "" && target.Role != roleActive {
+			ldp.warnings = append(ldp.warnings, fmt.Sprintf("Application Gateway has no %s backends; Service %s of load distribution policy %s receives no traffic", target.Role, target.Backend.Service.Name, key))
+			continue
+		}
+		if target.Weight <= 0 {
+			continue
+		}
+
+		backendID := generateBackendID(ingress, nil, nil, &networking.IngressBackend{Service: target.Backend.Service})
+		backendPort, err := c.resolveBackendPort(backendID)
+		if err != nil {
+			ldp.warnings = append(ldp.warnings, err.Error())
+			continue
+		}
+		ldp.targets = append(ldp.targets, loadDistributionTarget{service: target.Backend.Service.Name, backendPort: backendPort})
+
+		pair := serviceBackendPortPair{ServicePort: backendPort, BackendPort: backendPort}
+		if pool := c.getBackendAddressPool(cbCtx, backendID, pair, map[string]*n.ApplicationGatewayBackendAddressPool{}); pool != nil {
+			ldp.pools = append(ldp.pools, pool)
+			pools = append(pools, weightedPool{pool: pool, weight: int32(target.Weight)})
+		}
+	}
+
+	ldp.policy = c.newLoadDistributionPolicy(generateIngressLoadDistributionPolicyName(ingress.Namespace, policyName), pools)
+	(*c.mem.loadDistributionPolicies)[key] = ldp
+	return ldp
+}
+
+// getIngressLoadDistributionPolicies returns the policies of the LoadDistributionPolicies the Ingresses reference.
+func (c *appGwConfigBuilder) getIngressLoadDistributionPolicies(cbCtx *ConfigBuilderContext) []*ingressLoadDistributionPolicy {
+	var policies []*ingressLoadDistributionPolicy
+	for _, ingress := range cbCtx.IngressList {
+		policyName, err := annotations.LoadDistributionPolicy(ingress)
+		if err != nil || policyName == "" {
+			continue
+		}
+		if ldp := c.getIngressLoadDistributionPolicy(cbCtx, ingress, policyName); ldp != nil && ldp.policy != nil {
+			policies = append(policies, ldp)
+		}
+	}
+	return policies
+}
+
+// getLoadDistributionPolicyRef returns the load distribution policy splitting the traffic of the backend,
+// when the Ingress references a LoadDistributionPolicy targeting the service of the backend.
+// App Gateway forwards the traffic of all the targets with the HTTP settings of the rule, so they must listen on the port of the backend.
+func (c *appGwConfigBuilder) getLoadDistributionPolicyRef(cbCtx *ConfigBuilderContext, backendID backendIdentifier, backendPort Port) *n.SubResource {
+	policyName, err := annotations.LoadDistributionPolicy(backendID.Ingress)
+	if err != nil || policyName == "" || backendID.Backend == nil || backendID.Backend.Service == nil {
+		return nil
+	}
+
+	ldp := c.getIngressLoadDistributionPolicy(cbCtx, backendID.Ingress, policyName)
+	if ldp == nil {
+		message := fmt.Sprintf("Load distribution policy %s/%s referenced by annotation %s does not exist", backendID.Ingress.Namespace, policyName, annotations.LoadDistributionPolicyKey)
+		klog.Warning(message)
+		c.recorder.Event(backendID.Ingress, v1.EventTypeWarning, events.ReasonInvalidLoadDistributionPolicy, message)
+		return nil
+	}
+
+	for _, warning := range ldp.warnings {
+		c.recorder.Event(backendID.Ingress, v1.EventTypeWarning, events.ReasonInvalidLoadDistributionPolicy, warning)
+	}
+
+	isTarget := false
+	for _, target := range ldp.targets {
+		isTarget = isTarget || target.service == backendID.Backend.Service.Name
+	}
+	if !isTarget || ldp.policy == nil {
+		return nil
+	}
+
+	for _, target := range ldp.targets {
+		if target.backendPort != backendPort {
+			message := fmt.Sprintf("Load distribution policy %s/%s is not applied to service %s: Application Gateway can only split traffic between backends listening on the same port, but service %s listens on port %d and service %s on port %d",
+				backendID.Ingress.Namespace, policyName, backendID.serviceKey(), backendID.Backend.Service.Name, backendPort, target.service, target.backendPort)
+			klog.Warning(message)
+			c.recorder.Event(backendID.Ingress, v1.EventTypeWarning, events.ReasonInvalidLoadDistributionPolicy, message)
+			return nil
+		}
+	}
+
+	klog.V(3).Infof("Attached load distribution policy %s to backend %s of Ingress %s/%s", *ldp.policy.Name, backendID.serviceKey(), backendID.Ingress.Namespace, backendID.Ingress.Name)
+	return resourceRef(*ldp.policy.ID)
+}
+
+// getLoadDistributionPolicies lists the policies AGIC creates which the routing rules use, sorted by name.
+func (c *appGwConfigBuilder) getLoadDistributionPolicies(cbCtx *ConfigBuilderContext, routingRules []n.ApplicationGatewayRequestRoutingRule, pathMaps []n.ApplicationGatewayURLPathMap) []n.ApplicationGatewayLoadDistributionPolicy {
+	policiesByID := make(map[string]n.ApplicationGatewayLoadDistributionPolicy)
+	for _, ldp := range c.getIngressLoadDistributionPolicies(cbCtx) {
+		policiesByID[*ldp.policy.ID] = *ldp.policy
+	}
+	for _, policy := range c.getCanaryConfig(cbCtx).loadDistributionPolicies {
+		policiesByID[*policy.ID] = policy
+	}
+	if cbCtx.EnvVariables.EnableIstioIntegration {
+		for _, policy := range c.getIstioConfig(cbCtx).loadDistributionPolicies {
+			policiesByID[*policy.ID] = policy
+		}
+	}
+	if cbCtx.EnvVariables.EnableGatewayAPI {
+		for _, policy := range c.getGatewayAPIConfig(cbCtx).loadDistributionPolicies {
+			policiesByID[*policy.ID] = policy
+		}
+	}
+
+	referenced := make(map[string]interface{})
+	addReference := func(ref *n.SubResource) {
+		if ref != nil && ref.ID != nil {
+			referenced[*ref.ID] = nil
+		}
+	}
+	for _, rule := range routingRules {
+		if rule.ApplicationGatewayRequestRoutingRulePropertiesFormat != nil {
+			addReference(rule.LoadDistributionPolicy)
+		}
+	}
+	for _, pathMap := range pathMaps {
+		if pathMap.ApplicationGatewayURLPathMapPropertiesFormat == nil {
+			continue
+		}
+		addReference(pathMap.DefaultLoadDistributionPolicy)
+		if pathMap.PathRules == nil {
+			continue
+		}
+		for _, pathRule := range *pathMap.PathRules {
+			if pathRule.ApplicationGatewayPathRulePropertiesFormat != nil {
+				addReference(pathRule.LoadDistributionPolicy)
+			}
+		}
+	}
+
+	policies := []n.ApplicationGatewayLoadDistributionPolicy{}
+	for policyID, policy := range policiesByID {
+		if _, exists := referenced[policyID]; exists {
+			policies = append(policies, policy)
+		}
+	}
+	sort.Slice(policies, func(i, j int) bool {
+		return *policies[i].Name < *policies[j].Name
+	})
+	return policies
+}
+
+// removeAGICGeneratedLoadDistributionPolicies removes the load distribution policies that were generated by AGIC
+func removeAGICGeneratedLoadDistributionPolicies(currentPolicies *[]n.ApplicationGatewayLoadDistributionPolicy) []n.ApplicationGatewayLoadDistributionPolicy {
+	policies := []n.ApplicationGatewayLoadDistributionPolicy{}
+	if currentPolicies == nil {
+		return policies
+	}
+	for _, policy := range *currentPolicies {
+		if policy.Name != nil && !strings.HasPrefix(*policy.Name, agPrefix+prefixLoadDistributionPolicy+"-") {
+			policies = append(policies, policy)
+		}
+	}
+	return policies
+}
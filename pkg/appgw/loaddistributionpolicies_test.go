@@ -0,0 +1,267 @@
+// -------------------------------------------------------------------------------------------
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License. See License.txt in the project root for license information.
+// --------------------------------------------------------------------------------------------
+
+package appgw
+
+import (
+	"context"
+	"time"
+
+	n "github.com/Azure/azure-sdk-for-go/services/network/mgmt/2021-03-01/network"
+	"github.com/Azure/go-autorest/autorest/to"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	v1 "k8s.io/api/core/v1"
+	networking "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	testclient "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/record"
+	gateway_fake "sigs.k8s.io/gateway-api/pkg/client/clientset/versioned/fake"
+
+	"github.com/Azure/application-gateway-kubernetes-ingress/pkg/annotations"
+	ldpv1beta1 "github.com/Azure/application-gateway-kubernetes-ingress/pkg/apis/loaddistributionpolicy/v1beta1"
+	"github.com/Azure/application-gateway-kubernetes-ingress/pkg/crd_client/agic_crd_client/clientset/versioned/fake"
+	multiCluster_fake "github.com/Azure/application-gateway-kubernetes-ingress/pkg/crd_client/azure_multicluster_crd_client/clientset/versioned/fake"
+	istio_fake "github.com/Azure/application-gateway-kubernetes-ingress/pkg/crd_client/istio_crd_client/clientset/versioned/fake"
+	"github.com/Azure/application-gateway-kubernetes-ingress/pkg/environment"
+	"github.com/Azure/application-gateway-kubernetes-ingress/pkg/events"
+	"github.com/Azure/application-gateway-kubernetes-ingress/pkg/k8scontext"
+	"github.com/Azure/application-gateway-kubernetes-ingress/pkg/metricstore"
+	"github.com/Azure/application-gateway-kubernetes-ingress/pkg/tests"
+	"github.com/Azure/application-gateway-kubernetes-ingress/pkg/tests/mocks"
+)
+
+var _ = Describe("Test LoadDistributionPolicy translation", func() {
+	namespace := "shop"
+	policyName := "cart-split"
+
+	newService := func(name string, targetPort int32, ips ...string) (*v1.Service, *v1.Endpoints) {
+		service := &v1.Service{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+			Spec: v1.ServiceSpec{
+				Ports: []v1.ServicePort{{
+					Protocol:   v1.ProtocolTCP,
+					Port:       80,
+					TargetPort: intstr.FromInt(int(targetPort)),
+				}},
+			},
+		}
+		var addresses []v1.EndpointAddress
+		for _, ip := range ips {
+			addresses = append(addresses, v1.EndpointAddress{IP: ip})
+		}
+		endpoints := &v1.Endpoints{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+			Subsets: []v1.EndpointSubset{{
+				Addresses: addresses,
+				Ports:     []v1.EndpointPort{{Protocol: v1.ProtocolTCP, Port: targetPort}},
+			}},
+		}
+		return service, endpoints
+	}
+
+	newTarget := func(service string, role string, weight int) ldpv1beta1.Target {
+		return ldpv1beta1.Target{
+			Role:   role,
+			Weight: weight,
+			Backend: ldpv1beta1.Backend{
+				Service: &networking.IngressServiceBackend{
+					Name: service,
+					Port: networking.ServiceBackendPort{Number: 80},
+				},
+			},
+		}
+	}
+
+	newPath := func(path string, service string) networking.HTTPIngressPath {
+		pathType := networking.PathTypePrefix
+		return networking.HTTPIngressPath{
+			Path:     path,
+			PathType: &pathType,
+			Backend: networking.IngressBackend{
+				Service: &networking.IngressServiceBackend{
+					Name: service,
+					Port: networking.ServiceBackendPort{Number: 80},
+				},
+			},
+		}
+	}
+
+	var cb *appGwConfigBuilder
+	var cbCtx *ConfigBuilderContext
+	var ctxt *k8scontext.Context
+	var recorder *record.FakeRecorder
+	var ingress *networking.Ingress
+
+	BeforeEach(func() {
+		k8sClient := testclient.NewSimpleClientset()
+		for _, s := range []struct {
+			name       string
+			targetPort int32
+			ips        []string
+		}{
+			{"cart-v1", 8080, []string{"10.0.0.1", "10.0.0.2"}},
+			{"cart-v2", 8080, []string{"10.0.0.3"}},
+			{"cart-v3", 9090, []string{"10.0.0.4"}},
+			{"catalog", 8080, []string{"10.0.0.5"}},
+		} {
+			service, endpoints := newService(s.name, s.targetPort, s.ips...)
+			_, _ = k8sClient.CoreV1().Services(namespace).Create(context.TODO(), service, metav1.CreateOptions{})
+			_ = createEndpointsFixture(k8sClient, endpoints)
+		}
+
+		k8scontext.IsNetworkingV1PackageSupported = true
+		ctxt = k8scontext.NewContext(k8sClient, fake.NewSimpleClientset(), multiCluster_fake.NewSimpleClientset(), istio_fake.NewSimpleClientset(), gateway_fake.NewSimpleClientset(), []string{namespace}, 1000*time.Second, metricstore.NewFakeMetricStore(), environment.GetFakeEnv())
+		Expect(ctxt.Run(make(chan struct{}), true, environment.GetFakeEnv())).To(Succeed())
+
+		appGw := &n.ApplicationGateway{ApplicationGatewayPropertiesFormat: NewAppGwyConfigFixture()}
+		recorder = record.NewFakeRecorder(100)
+		cb = NewConfigBuilder(ctxt, &Identifier{
+			SubscriptionID: tests.Subscription,
+			ResourceGroup:  tests.ResourceGroup,
+			AppGwName:      tests.AppGwName,
+		}, appGw, recorder, mocks.Clock{}).(*appGwConfigBuilder)
+
+		ingress = &networking.Ingress{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        "shop",
+				Namespace:   namespace,
+				Annotations: map[string]string{annotations.LoadDistributionPolicyKey: policyName},
+			},
+			Spec: networking.IngressSpec{
+				Rules: []networking.IngressRule{{
+					Host: "shop.contoso.com",
+					IngressRuleValue: networking.IngressRuleValue{
+						HTTP: &networking.HTTPIngressRuleValue{
+							Paths: []networking.HTTPIngressPath{
+								newPath("/", "cart-v1"),
+								newPath("/cart", "cart-v1"),
+								newPath("/catalog", "catalog"),
+							},
+						},
+					},
+				}},
+			},
+		}
+
+		cbCtx = &ConfigBuilderContext{
+			EnvVariables:          environment.GetFakeEnv(),
+			IngressList:           []*networking.Ingress{ingress},
+			DefaultAddressPoolID:  to.StringPtr(cb.appGwIdentifier.AddressPoolID(DefaultBackendAddressPoolName)),
+			DefaultHTTPSettingsID: to.StringPtr(cb.appGwIdentifier.HTTPSettingsID(DefaultBackendHTTPSettingsName)),
+		}
+	})
+
+	addPolicy := func(targets ...ldpv1beta1.Target) {
+		policy := &ldpv1beta1.LoadDistributionPolicy{
+			ObjectMeta: metav1.ObjectMeta{Name: policyName, Namespace: namespace},
+			Spec:       ldpv1beta1.LoadDistributionPolicySpec{Targets: targets},
+		}
+		Expect(ctxt.Caches.LoadDistributionPolicy.Add(policy)).To(Succeed())
+	}
+
+	findPathMap := func(appGw *n.ApplicationGateway) *n.ApplicationGatewayURLPathMap {
+		Expect(*appGw.URLPathMaps).To(HaveLen(1))
+		return &(*appGw.URLPathMaps)[0]
+	}
+
+	findPathRule := func(pathMap *n.ApplicationGatewayURLPathMap, pathIdx int) n.ApplicationGatewayPathRule {
+		for _, rule := range *pathMap.PathRules {
+			if *rule.Name == generatePathRuleName(namespace, ingress.Name, 0, pathIdx) {
+				return rule
+			}
+		}
+		Fail("path rule not found")
+		return n.ApplicationGatewayPathRule{}
+	}
+
+	warnings := func() []string {
+		var received []string
+		for len(recorder.Events) > 0 {
+			received = append(received, <-recorder.Events)
+		}
+		return received
+	}
+
+	Context("ensure an Ingress splits the traffic of the services a LoadDistributionPolicy targets", func() {
+		It("creates the policy with a pool per target", func() {
+			addPolicy(newTarget("cart-v1", "active", 90), newTarget("cart-v2", "active", 10))
+
+			appGw, err := cb.Build(cbCtx)
+			Expect(err).ToNot(HaveOccurred())
+
+			ldpName := generateIngressLoadDistributionPolicyName(namespace, policyName)
+			Expect(*appGw.LoadDistributionPolicies).To(HaveLen(1))
+			policy := (*appGw.LoadDistributionPolicies)[0]
+			Expect(*policy.Name).To(Equal(ldpName))
+			targets := *policy.LoadDistributionTargets
+			Expect(targets).To(HaveLen(2))
+			// cart-v1 weighs 90 over 2 endpoints, cart-v2 weighs 10 over 1 endpoint.
+			Expect(*targets[0].WeightPerServer).To(Equal(int32(100)))
+			Expect(*targets[0].BackendAddressPool.ID).To(HaveSuffix("/backendAddressPools/" + generateAddressPoolName(namespace+"-cart-v1", "80", 8080)))
+			Expect(*targets[1].WeightPerServer).To(Equal(int32(22)))
+			Expect(*targets[1].BackendAddressPool.ID).To(HaveSuffix("/backendAddressPools/" + generateAddressPoolName(namespace+"-cart-v2", "80", 8080)))
+
+			poolNames := make(map[string]interface{})
+			for _, pool := range *appGw.BackendAddressPools {
+				poolNames[*pool.Name] = nil
+			}
+			Expect(poolNames).To(HaveKey(generateAddressPoolName(namespace+"-cart-v2", "80", 8080)))
+
+			pathMap := findPathMap(appGw)
+			Expect(*pathMap.DefaultLoadDistributionPolicy.ID).To(Equal(*policy.ID))
+			Expect(*findPathRule(pathMap, 1).LoadDistributionPolicy.ID).To(Equal(*policy.ID))
+			Expect(findPathRule(pathMap, 2).LoadDistributionPolicy).To(BeNil())
+		})
+
+		It("leaves out passive targets", func() {
+			addPolicy(newTarget("cart-v1", "active", 8), newTarget("cart-v2", "passive", 2))
+
+			appGw, err := cb.Build(cbCtx)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(appGw.LoadDistributionPolicies).To(BeNil())
+			Expect(findPathRule(findPathMap(appGw), 1).LoadDistributionPolicy).To(BeNil())
+			Expect(warnings()).To(ContainElement(And(ContainSubstring(events.ReasonInvalidLoadDistributionPolicy), ContainSubstring("cart-v2"))))
+		})
+
+		It("does not split traffic between backends listening on different ports", func() {
+			addPolicy(newTarget("cart-v1", "active", 1), newTarget("cart-v3", "active", 1))
+
+			appGw, err := cb.Build(cbCtx)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(appGw.LoadDistributionPolicies).To(BeNil())
+			Expect(findPathRule(findPathMap(appGw), 1).LoadDistributionPolicy).To(BeNil())
+			Expect(warnings()).To(ContainElement(ContainSubstring("same port")))
+		})
+
+		It("warns when the LoadDistributionPolicy does not exist", func() {
+			appGw, err := cb.Build(cbCtx)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(appGw.LoadDistributionPolicies).To(BeNil())
+			Expect(warnings()).To(ContainElement(ContainSubstring("does not exist")))
+		})
+
+		It("keeps the policies AGIC did not create", func() {
+			addPolicy(newTarget("cart-v1", "active", 90), newTarget("cart-v2", "active", 10))
+			cb.appGw.LoadDistributionPolicies = &[]n.ApplicationGatewayLoadDistributionPolicy{
+				{Name: to.StringPtr("manual")},
+				{Name: to.StringPtr(generateIngressLoadDistributionPolicyName(namespace, "stale"))},
+			}
+
+			appGw, err := cb.Build(cbCtx)
+			Expect(err).ToNot(HaveOccurred())
+
+			var names []string
+			for _, policy := range *appGw.LoadDistributionPolicies {
+				names = append(names, *policy.Name)
+			}
+			Expect(names).To(ConsistOf("manual", generateIngressLoadDistributionPolicyName(namespace, policyName)))
+		})
+	})
+})
@@ -21,6 +21,7 @@ import (
 	"k8s.io/apimachinery/pkg/util/intstr"
 	testclient "k8s.io/client-go/kubernetes/fake"
 	"k8s.io/client-go/tools/record"
+	gateway_fake "sigs.k8s.io/gateway-api/pkg/client/clientset/versioned/fake"
 
 	"github.com/Azure/application-gateway-kubernetes-ingress/pkg/annotations"
 	"github.com/Azure/application-gateway-kubernetes-ingress/pkg/crd_client/agic_crd_client/clientset/versioned/fake"
@@ -230,7 +231,7 @@ var _ = Describe("Public and Private IP tests", func() {
 	istioCrdClient := istio_fake.NewSimpleClientset()
 	multiClusterCrdClient := multiCluster_fake.NewSimpleClientset()
 	k8scontext.IsNetworkingV1PackageSupported = true
-	ctxt := k8scontext.NewContext(k8sClient, crdClient, multiClusterCrdClient, istioCrdClient, []string{ingressNS}, 1000*time.Second, metricstore.NewFakeMetricStore(), environment.GetFakeEnv())
+	ctxt := k8scontext.NewContext(k8sClient, crdClient, multiClusterCrdClient, istioCrdClient, gateway_fake.NewSimpleClientset(), []string{ingressNS}, 1000*time.Second, metricstore.NewFakeMetricStore(), environment.GetFakeEnv())
 
 	secret := tests.NewSecretTestFixture()
 
@@ -266,7 +267,7 @@ var _ = Describe("Public and Private IP tests", func() {
 	_, err = k8sClient.CoreV1().Services(ingressNS).Create(context.TODO(), service, metav1.CreateOptions{})
 	It("should have not failed", func() { Expect(err).ToNot(HaveOccurred()) })
 
-	_, err = k8sClient.CoreV1().Endpoints(ingressNS).Create(context.TODO(), endpoints, metav1.CreateOptions{})
+	err = createEndpointsFixture(k8sClient, endpoints)
 	It("should have not failed", func() { Expect(err).ToNot(HaveOccurred()) })
 
 	_, err = k8sClient.CoreV1().Pods(ingressNS).Create(context.TODO(), pod1, metav1.CreateOptions{})
@@ -45,6 +45,12 @@ func (c *appGwConfigBuilder) getRedirectConfigurations(cbCtx *ConfigBuilderConte
 		}
 	}
 
+	if cbCtx.EnvVariables.EnableGatewayAPI {
+		for _, redirectConfig := range c.getGatewayAPIConfig(cbCtx).redirects {
+			redirectConfigs = append(redirectConfigs, redirectConfig)
+		}
+	}
+
 	if cbCtx.EnvVariables.EnableBrownfieldDeployment {
 		er := brownfield.NewExistingResources(c.appGw, cbCtx.ProhibitedTargets, nil)
 
@@ -69,6 +69,13 @@ func (c *appGwConfigBuilder) RequestRoutingRules(cbCtx *ConfigBuilderContext) er
 
 	c.appGw.RequestRoutingRules = &requestRoutingRules
 
+	agicLoadDistributionPolicies := c.getLoadDistributionPolicies(cbCtx, requestRoutingRules, pathMaps)
+	if c.appGw.LoadDistributionPolicies != nil || len(agicLoadDistributionPolicies) > 0 {
+		loadDistributionPolicies := removeAGICGeneratedLoadDistributionPolicies(c.appGw.LoadDistributionPolicies)
+		loadDistributionPolicies = append(loadDistributionPolicies, agicLoadDistributionPolicies...)
+		c.appGw.LoadDistributionPolicies = &loadDistributionPolicies
+	}
+
 	return nil
 }
 
@@ -106,6 +113,7 @@ func (c *appGwConfigBuilder) getRules(cbCtx *ConfigBuilderContext) ([]n.Applicat
 			if rule.RedirectConfiguration == nil {
 				rule.BackendAddressPool = urlPathMap.DefaultBackendAddressPool
 				rule.BackendHTTPSettings = urlPathMap.DefaultBackendHTTPSettings
+				rule.LoadDistributionPolicy = urlPathMap.DefaultLoadDistributionPolicy
 			} else {
 				rule.BackendAddressPool = nil
 				rule.BackendHTTPSettings = nil
@@ -171,6 +179,11 @@ func (c *appGwConfigBuilder) getPathMaps(cbCtx *ConfigBuilderContext) map[listen
 	for ingressIdx := range cbCtx.IngressList {
 		ingress := cbCtx.IngressList[ingressIdx]
 
+		// Canaries are merged into the path maps of the Ingresses they shadow.
+		if isCanaryIngress(ingress) {
+			continue
+		}
+
 		if len(ingress.Spec.Rules) == 0 {
 			c.noRulesIngress(cbCtx, ingress, &urlPathMaps)
 		}
@@ -204,6 +217,14 @@ func (c *appGwConfigBuilder) getPathMaps(cbCtx *ConfigBuilderContext) map[listen
 		}
 	}
 
+	if cbCtx.EnvVariables.EnableGatewayAPI {
+		for listenerID, pathMap := range c.getGatewayAPIConfig(cbCtx).pathMaps {
+			if _, exists := urlPathMaps[listenerID]; !exists {
+				urlPathMaps[listenerID] = pathMap
+			}
+		}
+	}
+
 	// if no url pathmaps were created, then add a default path map since this will be translated to
 	// a basic request routing rule which is needed on Application Gateway to avoid validation error.
 	if len(urlPathMaps) == 0 {
@@ -245,7 +266,7 @@ func (c *appGwConfigBuilder) getPathMap(cbCtx *ConfigBuilderContext, listenerID
 	}
 
 	// get defaults provided by the rules if any
-	defaultAddressPoolID, defaultHTTPSettingsID, defaultRedirectConfigurationID, defaultRewriteRuleSetID := c.getDefaultFromRule(cbCtx, listenerID, listenerAzConfig, ingress, rule)
+	defaultAddressPoolID, defaultHTTPSettingsID, defaultRedirectConfigurationID, defaultRewriteRuleSetID, defaultLoadDistributionPolicy := c.getDefaultFromRule(cbCtx, listenerID, listenerAzConfig, ingress, rule)
 	if defaultRedirectConfigurationID != nil {
 		pathMap.DefaultRedirectConfiguration = resourceRef(*defaultRedirectConfigurationID)
 		pathMap.DefaultBackendAddressPool = nil
@@ -253,6 +274,7 @@ func (c *appGwConfigBuilder) getPathMap(cbCtx *ConfigBuilderContext, listenerID
 	} else if defaultAddressPoolID != nil && defaultHTTPSettingsID != nil {
 		pathMap.DefaultBackendAddressPool = resourceRef(*defaultAddressPoolID)
 		pathMap.DefaultBackendHTTPSettings = resourceRef(*defaultHTTPSettingsID)
+		pathMap.DefaultLoadDistributionPolicy = defaultLoadDistributionPolicy
 	}
 	if defaultRewriteRuleSetID != nil {
 		pathMap.DefaultRewriteRuleSet = resourceRef(*defaultRewriteRuleSetID)
@@ -263,7 +285,7 @@ func (c *appGwConfigBuilder) getPathMap(cbCtx *ConfigBuilderContext, listenerID
 	return &pathMap
 }
 
-func (c *appGwConfigBuilder) getDefaultFromRule(cbCtx *ConfigBuilderContext, listenerID listenerIdentifier, listenerAzConfig listenerAzConfig, ingress *networking.Ingress, rule *networking.IngressRule) (*string, *string, *string, *string) {
+func (c *appGwConfigBuilder) getDefaultFromRule(cbCtx *ConfigBuilderContext, listenerID listenerIdentifier, listenerAzConfig listenerAzConfig, ingress *networking.Ingress, rule *networking.IngressRule) (*string, *string, *string, *string, *n.SubResource) {
 	if sslRedirect, _ := annotations.IsSslRedirect(ingress); sslRedirect && listenerAzConfig.Protocol == n.ApplicationGatewayProtocolHTTP {
 		targetListener := listenerID
 		targetListener.FrontendPort = 443
@@ -274,7 +296,7 @@ func (c *appGwConfigBuilder) getDefaultFromRule(cbCtx *ConfigBuilderContext, lis
 
 		if _, exists := redirectsSet[*redirectRef.ID]; exists {
 			klog.V(3).Infof("Attached default redirection %s to rule %+v", *redirectRef.ID, *rule)
-			return nil, nil, redirectRef.ID, nil
+			return nil, nil, redirectRef.ID, nil, nil
 		}
 		klog.Errorf("Will not attach default redirect to rule; SSL Redirect does not exist: %s", *redirectRef.ID)
 	}
@@ -292,7 +314,7 @@ func (c *appGwConfigBuilder) getDefaultFromRule(cbCtx *ConfigBuilderContext, lis
 	}
 
 	backendPools := c.newBackendPoolMap(cbCtx)
-	_, backendHTTPSettingsMap, _, _ := c.getBackendsAndSettingsMap(cbCtx)
+	_, backendHTTPSettingsMap, serviceBackendPairMap, _ := c.getBackendsAndSettingsMap(cbCtx)
 	var defaultRewriteRuleSet *string
 	if defBackend != nil {
 		// has default backend
@@ -316,25 +338,44 @@ func (c *appGwConfigBuilder) getDefaultFromRule(cbCtx *ConfigBuilderContext, lis
 		if defaultAddressPool != nil && defaultHTTPSettings != nil {
 			poolID := to.StringPtr(c.appGwIdentifier.AddressPoolID(*defaultAddressPool.Name))
 			settID := to.StringPtr(c.appGwIdentifier.HTTPSettingsID(*defaultHTTPSettings.Name))
-			return poolID, settID, nil, defaultRewriteRuleSet
+			loadDistributionPolicy := c.getLoadDistributionPolicyRef(cbCtx, defaultBackendID, serviceBackendPairMap[defaultBackendID].BackendPort)
+			if route, exists := c.getCanaryConfig(cbCtx).routes[defaultBackendID]; exists {
+				if route.loadDistributionPolicy != nil {
+					loadDistributionPolicy = route.loadDistributionPolicy
+				}
+				if route.backendAddressPool != nil {
+					poolID, loadDistributionPolicy = route.backendAddressPool.ID, nil
+				}
+				if route.rewriteRuleSet != nil {
+					defaultRewriteRuleSet = route.rewriteRuleSet.ID
+				}
+			}
+			if route, exists := c.getRouteMatchConfig(cbCtx).routes[defaultBackendID]; exists {
+				defaultRewriteRuleSet = route.rewriteRuleSet.ID
+			}
+			return poolID, settID, nil, defaultRewriteRuleSet, loadDistributionPolicy
 		}
 	}
 
-	return cbCtx.DefaultAddressPoolID, cbCtx.DefaultHTTPSettingsID, nil, defaultRewriteRuleSet
+	return cbCtx.DefaultAddressPoolID, cbCtx.DefaultHTTPSettingsID, nil, defaultRewriteRuleSet, nil
 }
 
 func (c *appGwConfigBuilder) getPathRules(cbCtx *ConfigBuilderContext, listenerID listenerIdentifier, listenerAzConfig listenerAzConfig, ingress *networking.Ingress, rule *networking.IngressRule, ruleIdx int) *[]n.ApplicationGatewayPathRule {
 	backendPools := c.newBackendPoolMap(cbCtx)
-	_, backendHTTPSettingsMap, _, _ := c.getBackendsAndSettingsMap(cbCtx)
+	_, backendHTTPSettingsMap, serviceBackendPairMap, _ := c.getBackendsAndSettingsMap(cbCtx)
 	pathRules := make([]n.ApplicationGatewayPathRule, 0)
 	for pathIdx := range rule.HTTP.Paths {
 		path := &rule.HTTP.Paths[pathIdx]
+		pathRuleName := generatePathRuleName(ingress.Namespace, ingress.Name, ruleIdx, pathIdx)
 		if isPathCatchAll(path.Path, path.PathType) {
+			// The default backend has no path rule, but the requests rerouted to its canary, or by its route match, need one.
+			backendID := generateBackendID(ingress, rule, path, &path.Backend)
+			pathRules = append(pathRules, c.getCanaryPathRules(cbCtx, listenerID, pathRuleName, backendID)...)
+			pathRules = append(pathRules, c.getRouteMatchPathRules(cbCtx, listenerID, pathRuleName, backendID)...)
 			continue
 		}
 
 		pathMapName := generateURLPathMapName(listenerID)
-		pathRuleName := generatePathRuleName(ingress.Namespace, ingress.Name, ruleIdx, pathIdx)
 		pathRule := n.ApplicationGatewayPathRule{
 			Etag: to.StringPtr("*"),
 			Name: to.StringPtr(pathRuleName),
@@ -409,9 +450,26 @@ func (c *appGwConfigBuilder) getPathRules(cbCtx *ConfigBuilderContext, listenerI
 
 		pathRule.BackendAddressPool = &n.SubResource{ID: backendPool.ID}
 		pathRule.BackendHTTPSettings = &n.SubResource{ID: backendHTTPSettings.ID}
+		pathRule.LoadDistributionPolicy = c.getLoadDistributionPolicyRef(cbCtx, backendID, serviceBackendPairMap[backendID].BackendPort)
+		if route, exists := c.getCanaryConfig(cbCtx).routes[backendID]; exists {
+			if route.loadDistributionPolicy != nil {
+				pathRule.LoadDistributionPolicy = route.loadDistributionPolicy
+			}
+			if route.backendAddressPool != nil {
+				pathRule.BackendAddressPool, pathRule.LoadDistributionPolicy = route.backendAddressPool, nil
+			}
+			if route.rewriteRuleSet != nil {
+				pathRule.RewriteRuleSet = route.rewriteRuleSet
+			}
+		}
+		if route, exists := c.getRouteMatchConfig(cbCtx).routes[backendID]; exists {
+			pathRule.RewriteRuleSet = route.rewriteRuleSet
+		}
 		klog.V(3).Infof("Attached pool %s and http setting %s to path rule: %s", *backendPool.Name, *backendHTTPSettings.Name, *pathRule.Name)
 
 		pathRules = append(pathRules, pathRule)
+		pathRules = append(pathRules, c.getCanaryPathRules(cbCtx, listenerID, pathRuleName, backendID)...)
+		pathRules = append(pathRules, c.getRouteMatchPathRules(cbCtx, listenerID, pathRuleName, backendID)...)
 	}
 
 	return &pathRules
@@ -420,6 +478,7 @@ func (c *appGwConfigBuilder) getPathRules(cbCtx *ConfigBuilderContext, listenerI
 func (c *appGwConfigBuilder) mergePathMap(existingPathMap *n.ApplicationGatewayURLPathMap, pathMapToMerge *n.ApplicationGatewayURLPathMap, cbCtx *ConfigBuilderContext) *n.ApplicationGatewayURLPathMap {
 	if pathMapToMerge.DefaultBackendAddressPool != nil && *pathMapToMerge.DefaultBackendAddressPool.ID != *cbCtx.DefaultAddressPoolID {
 		existingPathMap.DefaultBackendAddressPool = pathMapToMerge.DefaultBackendAddressPool
+		existingPathMap.DefaultLoadDistributionPolicy = pathMapToMerge.DefaultLoadDistributionPolicy
 	}
 	if pathMapToMerge.DefaultBackendHTTPSettings != nil && *pathMapToMerge.DefaultBackendHTTPSettings.ID != *cbCtx.DefaultHTTPSettingsID {
 		existingPathMap.DefaultBackendHTTPSettings = pathMapToMerge.DefaultBackendHTTPSettings
@@ -428,6 +487,7 @@ func (c *appGwConfigBuilder) mergePathMap(existingPathMap *n.ApplicationGatewayU
 		existingPathMap.DefaultRedirectConfiguration = pathMapToMerge.DefaultRedirectConfiguration
 		existingPathMap.DefaultBackendAddressPool = nil
 		existingPathMap.DefaultBackendHTTPSettings = nil
+		existingPathMap.DefaultLoadDistributionPolicy = nil
 	}
 	if pathMapToMerge.DefaultRewriteRuleSet != nil {
 		existingPathMap.DefaultRewriteRuleSet = pathMapToMerge.DefaultRewriteRuleSet
@@ -471,6 +531,9 @@ func (c *appGwConfigBuilder) getListenerPriorities(cbCtx *ConfigBuilderContext)
 	priorityExists := make(map[int32]bool)
 	allPriorities := make(map[listenerIdentifier]*int32)
 	for _, ingress := range cbCtx.IngressList {
+		if isCanaryIngress(ingress) {
+			continue
+		}
 		klog.V(3).Infof("Getting Request Routing Rules Priority for Ingress: %s/%s", ingress.Namespace, ingress.Name)
 		azListenerConfigs := c.getListenersFromIngress(ingress, cbCtx.EnvVariables)
 		for listenerID := range azListenerConfigs {
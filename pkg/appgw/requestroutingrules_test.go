@@ -49,7 +49,7 @@ var _ = Describe("Test routing rules generations", func() {
 		service := tests.NewServiceFixture(*tests.NewServicePortsFixture()...)
 		ingressPathBased1 := tests.NewIngressFixture()
 		ingressPathBased1.Annotations[annotations.SslRedirectKey] = "false"
-		_ = configBuilder.k8sContext.Caches.Endpoints.Add(endpoint)
+		_ = addEndpointsFixture(configBuilder.k8sContext, endpoint)
 		_ = configBuilder.k8sContext.Caches.Service.Add(service)
 		_ = configBuilder.k8sContext.Caches.Ingress.Add(ingressPathBased1)
 
@@ -65,7 +65,7 @@ var _ = Describe("Test routing rules generations", func() {
 		ingressPathBased2.Spec.Rules = []networking.IngressRule{
 			testRule,
 		}
-		_ = configBuilder.k8sContext.Caches.Endpoints.Add(testEndpoint)
+		_ = addEndpointsFixture(configBuilder.k8sContext, testEndpoint)
 		_ = configBuilder.k8sContext.Caches.Service.Add(testService)
 		_ = configBuilder.k8sContext.Caches.Ingress.Add(ingressPathBased2)
 
@@ -144,7 +144,7 @@ var _ = Describe("Test routing rules generations", func() {
 			ruleBasic,
 		}
 
-		_ = configBuilder.k8sContext.Caches.Endpoints.Add(endpoint)
+		_ = addEndpointsFixture(configBuilder.k8sContext, endpoint)
 		_ = configBuilder.k8sContext.Caches.Service.Add(service)
 		_ = configBuilder.k8sContext.Caches.Ingress.Add(ingressPathBased)
 		_ = configBuilder.k8sContext.Caches.Ingress.Add(ingressBasic)
@@ -211,7 +211,7 @@ var _ = Describe("Test routing rules generations", func() {
 		service := tests.NewServiceFixture(*tests.NewServicePortsFixture()...)
 		ingress := tests.NewIngressFixture()
 
-		_ = configBuilder.k8sContext.Caches.Endpoints.Add(endpoint)
+		_ = addEndpointsFixture(configBuilder.k8sContext, endpoint)
 		_ = configBuilder.k8sContext.Caches.Service.Add(service)
 		_ = configBuilder.k8sContext.Caches.Ingress.Add(ingress)
 
@@ -261,7 +261,7 @@ var _ = Describe("Test routing rules generations", func() {
 		service := tests.NewServiceFixture(*tests.NewServicePortsFixture()...)
 		ingress := tests.NewIngressFixture()
 
-		_ = configBuilder.k8sContext.Caches.Endpoints.Add(endpoint)
+		_ = addEndpointsFixture(configBuilder.k8sContext, endpoint)
 		_ = configBuilder.k8sContext.Caches.Service.Add(service)
 		_ = configBuilder.k8sContext.Caches.Ingress.Add(ingress)
 
@@ -341,7 +341,7 @@ var _ = Describe("Test routing rules generations", func() {
 		})
 
 		_ = configBuilder.k8sContext.Caches.Secret.Add(secret)
-		_ = configBuilder.k8sContext.Caches.Endpoints.Add(endpoint)
+		_ = addEndpointsFixture(configBuilder.k8sContext, endpoint)
 		_ = configBuilder.k8sContext.Caches.Service.Add(service)
 		_ = configBuilder.k8sContext.Caches.Ingress.Add(ingress)
 
@@ -378,7 +378,7 @@ var _ = Describe("Test routing rules generations", func() {
 		ingress.Annotations[annotations.FirewallPolicy] = "/sub/waf"
 
 		_ = configBuilder.k8sContext.Caches.Secret.Add(secret)
-		_ = configBuilder.k8sContext.Caches.Endpoints.Add(endpoint)
+		_ = addEndpointsFixture(configBuilder.k8sContext, endpoint)
 		_ = configBuilder.k8sContext.Caches.Service.Add(service)
 		_ = configBuilder.k8sContext.Caches.Ingress.Add(ingress)
 
@@ -418,7 +418,7 @@ var _ = Describe("Test routing rules generations", func() {
 		})
 
 		_ = configBuilder.k8sContext.Caches.Secret.Add(secret)
-		_ = configBuilder.k8sContext.Caches.Endpoints.Add(endpoint)
+		_ = addEndpointsFixture(configBuilder.k8sContext, endpoint)
 		_ = configBuilder.k8sContext.Caches.Service.Add(service)
 		_ = configBuilder.k8sContext.Caches.Ingress.Add(ingress)
 
@@ -463,7 +463,7 @@ var _ = Describe("Test routing rules generations", func() {
 		service := tests.NewServiceFixture(*tests.NewServicePortsFixture()...)
 		ingress := fixtures.GetIngress()
 
-		_ = configBuilder.k8sContext.Caches.Endpoints.Add(endpoint)
+		_ = addEndpointsFixture(configBuilder.k8sContext, endpoint)
 		_ = configBuilder.k8sContext.Caches.Service.Add(service)
 		_ = configBuilder.k8sContext.Caches.Ingress.Add(ingress)
 
@@ -562,8 +562,8 @@ var _ = Describe("Test routing rules generations", func() {
 		duplicatePathRule := tests.NewIngressRuleFixture(tests.Host, "/api1", *backendBasic)
 		ingressPathBased.Spec.Rules = append([]networking.IngressRule{duplicatePathRule}, ingressPathBased.Spec.Rules...)
 
-		_ = configBuilder.k8sContext.Caches.Endpoints.Add(endpoint1)
-		_ = configBuilder.k8sContext.Caches.Endpoints.Add(endpoint2)
+		_ = addEndpointsFixture(configBuilder.k8sContext, endpoint1)
+		_ = addEndpointsFixture(configBuilder.k8sContext, endpoint2)
 		_ = configBuilder.k8sContext.Caches.Service.Add(service1)
 		_ = configBuilder.k8sContext.Caches.Service.Add(service2)
 		_ = configBuilder.k8sContext.Caches.Ingress.Add(ingressPathBased)
@@ -640,7 +640,7 @@ var _ = Describe("Test routing rules generations", func() {
 		duplicatePathRule := tests.NewIngressRuleWithPathsFixture(tests.Host, []string{"/api3", "/api3"}, *backendBasic)
 		ingressPathBased.Spec.Rules = append([]networking.IngressRule{duplicatePathRule}, ingressPathBased.Spec.Rules...)
 
-		_ = configBuilder.k8sContext.Caches.Endpoints.Add(endpoint1)
+		_ = addEndpointsFixture(configBuilder.k8sContext, endpoint1)
 		_ = configBuilder.k8sContext.Caches.Service.Add(service1)
 		_ = configBuilder.k8sContext.Caches.Ingress.Add(ingressPathBased)
 
@@ -715,7 +715,7 @@ var _ = Describe("Test routing rules generations", func() {
 		service := tests.NewServiceFixture(*tests.NewServicePortsFixture()...)
 		ingressPathBased1 := tests.NewIngressFixture()
 		ingressPathBased1.Annotations[annotations.SslRedirectKey] = "false"
-		_ = configBuilder.k8sContext.Caches.Endpoints.Add(endpoint)
+		_ = addEndpointsFixture(configBuilder.k8sContext, endpoint)
 		_ = configBuilder.k8sContext.Caches.Service.Add(service)
 		_ = configBuilder.k8sContext.Caches.Ingress.Add(ingressPathBased1)
 
@@ -731,7 +731,7 @@ var _ = Describe("Test routing rules generations", func() {
 		ingressPathBased2.Spec.Rules = []networking.IngressRule{
 			testRule,
 		}
-		_ = configBuilder.k8sContext.Caches.Endpoints.Add(testEndpoint)
+		_ = addEndpointsFixture(configBuilder.k8sContext, testEndpoint)
 		_ = configBuilder.k8sContext.Caches.Service.Add(testService)
 		_ = configBuilder.k8sContext.Caches.Ingress.Add(ingressPathBased2)
 
@@ -1096,7 +1096,7 @@ var _ = Describe("Test routing rules generations", func() {
 		service := tests.NewServiceFixture(*tests.NewServicePortsFixture()...)
 		ingress := tests.NewIngressTestWithVariousPathTypeFixture(tests.Namespace, "ingress-with-path-type")
 
-		_ = configBuilder.k8sContext.Caches.Endpoints.Add(endpoint)
+		_ = addEndpointsFixture(configBuilder.k8sContext, endpoint)
 		_ = configBuilder.k8sContext.Caches.Service.Add(service)
 		_ = configBuilder.k8sContext.Caches.Ingress.Add(&ingress)
 
@@ -1169,7 +1169,7 @@ var _ = Describe("Test routing rules generations", func() {
 			endpoint := tests.NewEndpointsFixture()
 			service := tests.NewServiceFixture(*tests.NewServicePortsFixture()...)
 
-			Expect(configBuilder.k8sContext.Caches.Endpoints.Add(endpoint)).To(Succeed())
+			Expect(addEndpointsFixture(configBuilder.k8sContext, endpoint)).To(Succeed())
 			Expect(configBuilder.k8sContext.Caches.Service.Add(service)).To(Succeed())
 
 			// The upper bound for rule priority defined as 20000 in NRP.
@@ -0,0 +1,119 @@
+// -------------------------------------------------------------------------------------------
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License. See License.txt in the project root for license information.
+// --------------------------------------------------------------------------------------------
+
+package appgw
+
+import (
+	"regexp"
+	"strings"
+
+	n "github.com/Azure/azure-sdk-for-go/services/network/mgmt/2021-03-01/network"
+	"github.com/Azure/go-autorest/autorest/to"
+	networking "k8s.io/api/networking/v1"
+)
+
+// cookieVariable is the server variable holding the Cookie header of the request.
+const cookieVariable = "http_req_Cookie"
+
+// App Gateway only routes on the path of the request. To route on headers, query parameters or cookies,
+// a rewrite rule set matches the requests and reroutes them to a marker path rule by prefixing their path with the marker.
+
+// rerouteMarker is a path rule only reached by the requests a rewrite rule set reroutes.
+type rerouteMarker struct {
+	role     string
+	path     string
+	pool     *n.SubResource
+	settings *n.SubResource
+}
+
+// appGwPathRulePath returns the path of the path rule App Gateway matches the path of the Ingress with.
+func appGwPathRulePath(path *networking.HTTPIngressPath) string {
+	if isPathCatchAll(path.Path, path.PathType) {
+		return "/*"
+	}
+	return preparePathFromPathType(path.Path, path.PathType)
+}
+
+// newRerouteMarker creates the marker path rule of the path of the Ingress, and the copy of the HTTP settings of the backend it uses.
+// The marker path rule strips the marker, and the path App Gateway would otherwise strip, off the path of the rerouted requests.
+func (c *appGwConfigBuilder) newRerouteMarker(role, marker string, path *networking.HTTPIngressPath, pool *n.ApplicationGatewayBackendAddressPool, settings *n.ApplicationGatewayBackendHTTPSettings, settingsName string) (rerouteMarker, n.ApplicationGatewayBackendHTTPSettings) {
+	pathRulePath := appGwPathRulePath(path)
+	markerSettings := c.newRerouteHTTPSettings(settings, settingsName, strings.TrimSuffix(pathRulePath, "*"))
+	return rerouteMarker{
+		role:     role,
+		path:     marker + pathRulePath,
+		pool:     resourceRef(*pool.ID),
+		settings: resourceRef(*markerSettings.ID),
+	}, markerSettings
+}
+
+// newRerouteHTTPSettings copies the HTTP settings of a backend for a marker path rule.
+// The marker path rule overrides the path it matches with the backend path prefix of the Ingress, if any, or with the path of the original path rule.
+func (c *appGwConfigBuilder) newRerouteHTTPSettings(settings *n.ApplicationGatewayBackendHTTPSettings, settingsName string, backendPath string) n.ApplicationGatewayBackendHTTPSettings {
+	properties := *settings.ApplicationGatewayBackendHTTPSettingsPropertiesFormat
+	if properties.Path == nil {
+		properties.Path = to.StringPtr(backendPath)
+	}
+	return n.ApplicationGatewayBackendHTTPSettings{
+		Etag: to.StringPtr("*"),
+		Name: to.StringPtr(settingsName),
+		ID:   to.StringPtr(c.appGwIdentifier.HTTPSettingsID(settingsName)),
+		ApplicationGatewayBackendHTTPSettingsPropertiesFormat: &properties,
+	}
+}
+
+// newReroutePathRules returns the marker path rules, named after the path rule of the path of the Ingress.
+func (c *appGwConfigBuilder) newReroutePathRules(listenerID listenerIdentifier, pathRuleName string, markers []rerouteMarker) []n.ApplicationGatewayPathRule {
+	pathMapName := generateURLPathMapName(listenerID)
+	var pathRules []n.ApplicationGatewayPathRule
+	for _, marker := range markers {
+		name := formatPropName(pathRuleName + "-" + marker.role)
+		pathRules = append(pathRules, n.ApplicationGatewayPathRule{
+			Etag: to.StringPtr("*"),
+			Name: to.StringPtr(name),
+			ID:   to.StringPtr(c.appGwIdentifier.pathRuleID(pathMapName, name)),
+			ApplicationGatewayPathRulePropertiesFormat: &n.ApplicationGatewayPathRulePropertiesFormat{
+				Paths:               &[]string{marker.path},
+				BackendAddressPool:  marker.pool,
+				BackendHTTPSettings: marker.settings,
+			},
+		})
+	}
+	return pathRules
+}
+
+// newRerouteRule creates the rewrite rule rerouting the requests matching all the conditions to the marker path rule.
+func newRerouteRule(name string, sequence int32, conditions []n.ApplicationGatewayRewriteRuleCondition, marker string) n.ApplicationGatewayRewriteRule {
+	return n.ApplicationGatewayRewriteRule{
+		Name:         to.StringPtr(name),
+		RuleSequence: to.Int32Ptr(sequence),
+		Conditions:   &conditions,
+		ActionSet: &n.ApplicationGatewayRewriteRuleActionSet{
+			URLConfiguration: &n.ApplicationGatewayURLConfiguration{
+				ModifiedPath: to.StringPtr(marker + "{var_uri_path}"),
+				Reroute:      to.BoolPtr(true),
+			},
+		},
+	}
+}
+
+func newRerouteCondition(variable, pattern string, negate bool) n.ApplicationGatewayRewriteRuleCondition {
+	return n.ApplicationGatewayRewriteRuleCondition{
+		Variable:   to.StringPtr(variable),
+		Pattern:    to.StringPtr(pattern),
+		IgnoreCase: to.BoolPtr(false),
+		Negate:     to.BoolPtr(negate),
+	}
+}
+
+// headerVariable returns the server variable holding the request header.
+func headerVariable(header string) string {
+	return "http_req_" + header
+}
+
+// cookiePattern matches the Cookie header of the requests with the cookie, whose value matches the value pattern.
+func cookiePattern(cookie, valuePattern string) string {
+	return `(^|;\s*)` + regexp.QuoteMeta(cookie) + "=" + valuePattern + "(;|$)"
+}
@@ -25,6 +25,8 @@ func (c *appGwConfigBuilder) RewriteRuleSets(cbCtx *ConfigBuilderContext) error
 
 	rewriteRuleSets := removeAGICGeneratedRewriteRuleSets(c.appGw.RewriteRuleSets)
 	rewriteRuleSets = append(rewriteRuleSets, c.getAGICRewriteRuleSets(cbCtx)...)
+	rewriteRuleSets = append(rewriteRuleSets, c.getCanaryConfig(cbCtx).rewriteRuleSets...)
+	rewriteRuleSets = append(rewriteRuleSets, c.getRouteMatchConfig(cbCtx).rewriteRuleSets...)
 
 	c.appGw.RewriteRuleSets = &rewriteRuleSets
 	return nil
@@ -40,7 +42,7 @@ func removeAGICGeneratedRewriteRuleSets(currentRewriteRuleSets *[]n.ApplicationG
 	var appGwRewriteRuleSets []n.ApplicationGatewayRewriteRuleSet
 
 	for _, rrs := range *currentRewriteRuleSets {
-		if rewriteRuleSetName := *(rrs.Name); !(strings.HasPrefix(rewriteRuleSetName, "crd-")) {
+		if rewriteRuleSetName := *(rrs.Name); !(strings.HasPrefix(rewriteRuleSetName, "crd-")) && !(strings.HasPrefix(rewriteRuleSetName, agPrefix+prefixRewriteRuleSet+"-")) {
 			appGwRewriteRuleSets = append(appGwRewriteRuleSets, rrs)
 		}
 	}
@@ -0,0 +1,308 @@
+// -------------------------------------------------------------------------------------------
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License. See License.txt in the project root for license information.
+// --------------------------------------------------------------------------------------------
+
+package appgw
+
+import (
+	"crypto/md5"
+	"fmt"
+	"regexp"
+
+	n "github.com/Azure/azure-sdk-for-go/services/network/mgmt/2021-03-01/network"
+	"github.com/Azure/go-autorest/autorest/to"
+	v1 "k8s.io/api/core/v1"
+	networking "k8s.io/api/networking/v1"
+	"k8s.io/klog/v2"
+
+	"github.com/Azure/application-gateway-kubernetes-ingress/pkg/annotations"
+	agroutematchv1beta1 "github.com/Azure/application-gateway-kubernetes-ingress/pkg/apis/azureapplicationgatewayroutematch/v1beta1"
+	"github.com/Azure/application-gateway-kubernetes-ingress/pkg/events"
+)
+
+const (
+	// routeMatchPathMarker prefixes the path of the requests a route match rule reroutes to its backend.
+	routeMatchPathMarker = "/agic-route"
+
+	// queryStringVariable is the server variable holding the query string of the request.
+	queryStringVariable = "query_string"
+)
+
+// routeMatchConfig is the App Gateway config rerouting the requests the AzureApplicationGatewayRouteMatch custom resources of the Ingresses match.
+type routeMatchConfig struct {
+	// routes are keyed by the backend of the path of the Ingress referencing the route match.
+	routes          map[backendIdentifier]*routeMatchRoute
+	settings        []n.ApplicationGatewayBackendHTTPSettings
+	rewriteRuleSets []n.ApplicationGatewayRewriteRuleSet
+}
+
+// routeMatchRoute tells how the path of the Ingress reroutes the requests the route match rules match.
+type routeMatchRoute struct {
+	rewriteRuleSet *n.SubResource
+	markers        []rerouteMarker
+}
+
+// routeMatchTarget is a route match rule and the backend it routes the requests it matches to.
+type routeMatchTarget struct {
+	index      int
+	name       string
+	marker     string
+	conditions []n.ApplicationGatewayRewriteRuleCondition
+	backendID  backendIdentifier
+}
+
+// getRouteMatch returns the AzureApplicationGatewayRouteMatch the Ingress references, if any.
+func (c *appGwConfigBuilder) getRouteMatch(ingress *networking.Ingress) *agroutematchv1beta1.AzureApplicationGatewayRouteMatch {
+	routeMatchName, err := annotations.RouteMatchCustomResource(ingress)
+	if err != nil || routeMatchName == "" {
+		return nil
+	}
+
+	key := ingress.Namespace + "/" + ingress.Name
+	if c.mem.routeMatches == nil {
+		c.mem.routeMatches = &map[string]*agroutematchv1beta1.AzureApplicationGatewayRouteMatch{}
+	}
+	if routeMatch, exists := (*c.mem.routeMatches)[key]; exists {
+		return routeMatch
+	}
+	(*c.mem.routeMatches)[key] = nil
+
+	if isCanaryIngress(ingress) {
+		c.routeMatchEvent(ingress, "Route match %s of Ingress %s/%s is not applied: canary Ingresses only merge their backend into the Ingress they shadow",
+			routeMatchName, ingress.Namespace, ingress.Name)
+		return nil
+	}
+
+	routeMatch, err := c.k8sContext.GetRouteMatch(ingress.Namespace, routeMatchName)
+	if err != nil {
+		c.routeMatchEvent(ingress, "Route match %s of Ingress %s/%s is not applied: %s", routeMatchName, ingress.Namespace, ingress.Name, err.Error())
+		return nil
+	}
+
+	(*c.mem.routeMatches)[key] = routeMatch
+	return routeMatch
+}
+
+// getRouteMatchBackendIDs returns the backends the route match of the Ingress routes requests to.
+func (c *appGwConfigBuilder) getRouteMatchBackendIDs(ingress *networking.Ingress) []backendIdentifier {
+	routeMatch := c.getRouteMatch(ingress)
+	if routeMatch == nil {
+		return nil
+	}
+
+	var backendIDs []backendIdentifier
+	for idx := range routeMatch.Spec.Rules {
+		backend := &routeMatch.Spec.Rules[idx].Backend
+		if backend.Service != nil {
+			backendIDs = append(backendIDs, generateBackendID(ingress, nil, nil, backend))
+		}
+	}
+	return backendIDs
+}
+
+// getRouteMatchConfig translates the route match of each Ingress into a rewrite rule set, attached to every path of the Ingress,
+// rerouting the requests each route match rule matches to the marker path rules of its backend.
+func (c *appGwConfigBuilder) getRouteMatchConfig(cbCtx *ConfigBuilderContext) *routeMatchConfig {
+	if c.mem.routeMatch != nil {
+		return c.mem.routeMatch
+	}
+
+	config := &routeMatchConfig{routes: make(map[backendIdentifier]*routeMatchRoute)}
+	for _, ingress := range cbCtx.IngressList {
+		routeMatch := c.getRouteMatch(ingress)
+		if routeMatch == nil {
+			continue
+		}
+
+		if ruleSet, _ := annotations.RewriteRuleSet(ingress); ruleSet != "" {
+			c.routeMatchEvent(ingress, "Route match %s of Ingress %s/%s is not applied: the Ingress already uses rewrite rule set %s",
+				routeMatch.Name, ingress.Namespace, ingress.Name, ruleSet)
+			continue
+		}
+		if ruleSet, _ := annotations.RewriteRuleSetCustomResource(ingress); ruleSet != "" {
+			c.routeMatchEvent(ingress, "Route match %s of Ingress %s/%s is not applied: the Ingress already uses rewrite rule set custom resource %s",
+				routeMatch.Name, ingress.Namespace, ingress.Name, ruleSet)
+			continue
+		}
+
+		targets := c.getRouteMatchTargets(cbCtx, ingress, routeMatch)
+		if len(targets) == 0 {
+			continue
+		}
+
+		// App Gateway runs the rewrite rules in ascending sequence, so the first route match rule runs last and wins.
+		var rewriteRules []n.ApplicationGatewayRewriteRule
+		for _, target := range targets {
+			sequence := int32(len(routeMatch.Spec.Rules) - target.index)
+			rewriteRules = append(rewriteRules, newRerouteRule(target.name, sequence, target.conditions, target.marker))
+		}
+		ruleSetName := generateRouteMatchRewriteRuleSetName(ingress.Namespace, ingress.Name)
+		ruleSet := n.ApplicationGatewayRewriteRuleSet{
+			Name: to.StringPtr(ruleSetName),
+			ID:   to.StringPtr(c.appGwIdentifier.rewriteRuleSetID(ruleSetName)),
+			ApplicationGatewayRewriteRuleSetPropertiesFormat: &n.ApplicationGatewayRewriteRuleSetPropertiesFormat{
+				RewriteRules: &rewriteRules,
+			},
+		}
+
+		applied := false
+		for ruleIdx := range ingress.Spec.Rules {
+			rule := &ingress.Spec.Rules[ruleIdx]
+			if rule.HTTP == nil {
+				continue
+			}
+
+			for pathIdx := range rule.HTTP.Paths {
+				path := &rule.HTTP.Paths[pathIdx]
+				backendID := generateBackendID(ingress, rule, path, &path.Backend)
+				if route, exists := c.getCanaryConfig(cbCtx).routes[backendID]; exists && route.rewriteRuleSet != nil {
+					c.routeMatchEvent(ingress, "Route match %s of Ingress %s/%s is not applied to host %q and path %q: the canary of the path already routes requests by header or cookie",
+						routeMatch.Name, ingress.Namespace, ingress.Name, rule.Host, path.Path)
+					continue
+				}
+
+				config.routes[backendID] = c.newRouteMatchRoute(cbCtx, config, backendID, targets, resourceRef(*ruleSet.ID), ruleIdx, pathIdx)
+				applied = true
+			}
+		}
+
+		if applied {
+			config.rewriteRuleSets = append(config.rewriteRuleSets, ruleSet)
+			klog.V(3).Infof("Applied route match %s/%s to Ingress %s/%s", routeMatch.Namespace, routeMatch.Name, ingress.Namespace, ingress.Name)
+		}
+	}
+
+	c.mem.routeMatch = config
+	return config
+}
+
+// getRouteMatchTargets returns the route match rules, which match at least one header, query parameter or cookie, and whose backend exists.
+func (c *appGwConfigBuilder) getRouteMatchTargets(cbCtx *ConfigBuilderContext, ingress *networking.Ingress, routeMatch *agroutematchv1beta1.AzureApplicationGatewayRouteMatch) []routeMatchTarget {
+	backendPools := c.newBackendPoolMap(cbCtx)
+	_, backendHTTPSettingsMap, _, _ := c.getBackendsAndSettingsMap(cbCtx)
+
+	var targets []routeMatchTarget
+	for idx := range routeMatch.Spec.Rules {
+		rule := &routeMatch.Spec.Rules[idx]
+		name := rule.Name
+		if name == "" {
+			name = fmt.Sprintf("rule-%d", idx)
+		}
+
+		if rule.Backend.Service == nil {
+			c.routeMatchEvent(ingress, "Rule %s of route match %s/%s is not applied: it has no backend service", name, routeMatch.Namespace, routeMatch.Name)
+			continue
+		}
+
+		conditions, invalid := newRouteMatchConditions(rule)
+		if invalid != "" {
+			c.routeMatchEvent(ingress, "Rule %s of route match %s/%s is not applied: %s", name, routeMatch.Namespace, routeMatch.Name, invalid)
+			continue
+		}
+
+		backendID := generateBackendID(ingress, nil, nil, &rule.Backend)
+		if backendPools[backendID] == nil || backendHTTPSettingsMap[backendID] == nil {
+			klog.Errorf("Rule %s of route match %s/%s is not applied to service %s: backend pools or http settings are missing", name, routeMatch.Namespace, routeMatch.Name, backendID.serviceKey())
+			continue
+		}
+
+		targets = append(targets, routeMatchTarget{
+			index:      idx,
+			name:       name,
+			marker:     routeMatchMarker(ingress, idx),
+			conditions: conditions,
+			backendID:  backendID,
+		})
+	}
+	return targets
+}
+
+// routeMatchMarker returns the marker path of the route match rule of the Ingress. Clients can request marker paths directly,
+// so the marker hashes the namespace and name of the Ingress instead of revealing them.
+func routeMatchMarker(ingress *networking.Ingress, ruleIdx int) string {
+	return fmt.Sprintf("%s-%x-%d", routeMatchPathMarker, md5.Sum([]byte(ingress.Namespace+"/"+ingress.Name)), ruleIdx)
+}
+
+// newRouteMatchConditions translates the headers, query parameters and cookies of the route match rule into rewrite rule conditions.
+// It returns why the rule is invalid, if it is.
+func newRouteMatchConditions(rule *agroutematchv1beta1.RouteMatchRule) ([]n.ApplicationGatewayRewriteRuleCondition, string) {
+	var conditions []n.ApplicationGatewayRewriteRuleCondition
+	for _, header := range rule.Headers {
+		pattern, invalid := valueMatchPattern("header", header)
+		if invalid != "" {
+			return nil, invalid
+		}
+		conditions = append(conditions, newRerouteCondition(headerVariable(header.Name), "^"+pattern+"$", false))
+	}
+	for _, queryParam := range rule.QueryParams {
+		pattern, invalid := valueMatchPattern("query parameter", queryParam)
+		if invalid != "" {
+			return nil, invalid
+		}
+		conditions = append(conditions, newRerouteCondition(queryStringVariable, "(^|&)"+regexp.QuoteMeta(queryParam.Name)+"="+pattern+"(&|$)", false))
+	}
+	for _, cookie := range rule.Cookies {
+		pattern, invalid := valueMatchPattern("cookie", cookie)
+		if invalid != "" {
+			return nil, invalid
+		}
+		conditions = append(conditions, newRerouteCondition(cookieVariable, cookiePattern(cookie.Name, pattern), false))
+	}
+
+	if len(conditions) == 0 {
+		return nil, "it matches no header, query parameter or cookie"
+	}
+	return conditions, ""
+}
+
+// valueMatchPattern returns the pattern matching the whole value of the header, query parameter or cookie.
+func valueMatchPattern(kind string, match agroutematchv1beta1.ValueMatch) (string, string) {
+	if match.Name == "" {
+		return "", fmt.Sprintf("a %s has no name", kind)
+	}
+
+	switch match.Type {
+	case "", agroutematchv1beta1.MatchTypeExact:
+		return regexp.QuoteMeta(match.Value), ""
+	case agroutematchv1beta1.MatchTypeRegularExpression:
+		if _, err := regexp.Compile(match.Value); err != nil {
+			return "", fmt.Sprintf("the value of %s %s is not a valid regular expression: %s", kind, match.Name, err.Error())
+		}
+		return "(" + match.Value + ")", ""
+	default:
+		return "", fmt.Sprintf("%s %s has unknown match type %q", kind, match.Name, match.Type)
+	}
+}
+
+// newRouteMatchRoute creates the marker path rules, and their HTTP settings, the requests rerouted from the path of the Ingress reach.
+func (c *appGwConfigBuilder) newRouteMatchRoute(cbCtx *ConfigBuilderContext, config *routeMatchConfig, backendID backendIdentifier, targets []routeMatchTarget, rewriteRuleSet *n.SubResource, ruleIdx, pathIdx int) *routeMatchRoute {
+	backendPools := c.newBackendPoolMap(cbCtx)
+	_, backendHTTPSettingsMap, _, _ := c.getBackendsAndSettingsMap(cbCtx)
+	ingress := backendID.Ingress
+
+	route := &routeMatchRoute{rewriteRuleSet: rewriteRuleSet}
+	for _, target := range targets {
+		settingsName := generateRouteMatchHTTPSettingsName(ingress.Namespace, ingress.Name, ruleIdx, pathIdx, target.index)
+		role := fmt.Sprintf("route-%d", target.index)
+		marker, settings := c.newRerouteMarker(role, target.marker, backendID.Path, backendPools[target.backendID], backendHTTPSettingsMap[target.backendID], settingsName)
+		config.settings = append(config.settings, settings)
+		route.markers = append(route.markers, marker)
+	}
+	return route
+}
+
+// getRouteMatchPathRules returns the marker path rules of the route match of the backend.
+func (c *appGwConfigBuilder) getRouteMatchPathRules(cbCtx *ConfigBuilderContext, listenerID listenerIdentifier, pathRuleName string, backendID backendIdentifier) []n.ApplicationGatewayPathRule {
+	route, exists := c.getRouteMatchConfig(cbCtx).routes[backendID]
+	if !exists {
+		return nil
+	}
+	return c.newReroutePathRules(listenerID, pathRuleName, route.markers)
+}
+
+func (c *appGwConfigBuilder) routeMatchEvent(ingress *networking.Ingress, format string, args ...interface{}) {
+	message := fmt.Sprintf(format, args...)
+	klog.Warning(message)
+	c.recorder.Event(ingress, v1.EventTypeWarning, events.ReasonInvalidRouteMatch, message)
+}
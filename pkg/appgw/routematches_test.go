@@ -0,0 +1,281 @@
+// -------------------------------------------------------------------------------------------
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License. See License.txt in the project root for license information.
+// --------------------------------------------------------------------------------------------
+
+package appgw
+
+import (
+	"context"
+	"time"
+
+	n "github.com/Azure/azure-sdk-for-go/services/network/mgmt/2021-03-01/network"
+	"github.com/Azure/go-autorest/autorest/to"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	v1 "k8s.io/api/core/v1"
+	networking "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	testclient "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/record"
+	gateway_fake "sigs.k8s.io/gateway-api/pkg/client/clientset/versioned/fake"
+
+	"github.com/Azure/application-gateway-kubernetes-ingress/pkg/annotations"
+	agroutematchv1beta1 "github.com/Azure/application-gateway-kubernetes-ingress/pkg/apis/azureapplicationgatewayroutematch/v1beta1"
+	"github.com/Azure/application-gateway-kubernetes-ingress/pkg/crd_client/agic_crd_client/clientset/versioned/fake"
+	multiCluster_fake "github.com/Azure/application-gateway-kubernetes-ingress/pkg/crd_client/azure_multicluster_crd_client/clientset/versioned/fake"
+	istio_fake "github.com/Azure/application-gateway-kubernetes-ingress/pkg/crd_client/istio_crd_client/clientset/versioned/fake"
+	"github.com/Azure/application-gateway-kubernetes-ingress/pkg/environment"
+	"github.com/Azure/application-gateway-kubernetes-ingress/pkg/events"
+	"github.com/Azure/application-gateway-kubernetes-ingress/pkg/k8scontext"
+	"github.com/Azure/application-gateway-kubernetes-ingress/pkg/metricstore"
+	"github.com/Azure/application-gateway-kubernetes-ingress/pkg/tests"
+	"github.com/Azure/application-gateway-kubernetes-ingress/pkg/tests/mocks"
+)
+
+var _ = Describe("Test route match translation", func() {
+	namespace := "web"
+
+	newServiceBackend := func(name string) *networking.IngressServiceBackend {
+		return &networking.IngressServiceBackend{
+			Name: name,
+			Port: networking.ServiceBackendPort{Number: 80},
+		}
+	}
+
+	newPath := func(path string, service string) networking.HTTPIngressPath {
+		pathType := networking.PathTypePrefix
+		return networking.HTTPIngressPath{
+			Path:     path,
+			PathType: &pathType,
+			Backend:  networking.IngressBackend{Service: newServiceBackend(service)},
+		}
+	}
+
+	var ctxt *k8scontext.Context
+	var cb *appGwConfigBuilder
+	var cbCtx *ConfigBuilderContext
+	var recorder *record.FakeRecorder
+	var ingress *networking.Ingress
+
+	BeforeEach(func() {
+		k8sClient := testclient.NewSimpleClientset()
+		for _, s := range []struct {
+			name       string
+			targetPort int32
+			ip         string
+		}{
+			{"web-v1", 8080, "10.0.0.1"},
+			{"web-beta", 8080, "10.0.0.2"},
+			{"web-mobile", 9090, "10.0.0.3"},
+		} {
+			service := &v1.Service{
+				ObjectMeta: metav1.ObjectMeta{Name: s.name, Namespace: namespace},
+				Spec: v1.ServiceSpec{
+					Ports: []v1.ServicePort{{
+						Protocol:   v1.ProtocolTCP,
+						Port:       80,
+						TargetPort: intstr.FromInt(int(s.targetPort)),
+					}},
+				},
+			}
+			endpoints := &v1.Endpoints{
+				ObjectMeta: metav1.ObjectMeta{Name: s.name, Namespace: namespace},
+				Subsets: []v1.EndpointSubset{{
+					Addresses: []v1.EndpointAddress{{IP: s.ip}},
+					Ports:     []v1.EndpointPort{{Protocol: v1.ProtocolTCP, Port: s.targetPort}},
+				}},
+			}
+			_, _ = k8sClient.CoreV1().Services(namespace).Create(context.TODO(), service, metav1.CreateOptions{})
+			_ = createEndpointsFixture(k8sClient, endpoints)
+		}
+
+		k8scontext.IsNetworkingV1PackageSupported = true
+		ctxt = k8scontext.NewContext(k8sClient, fake.NewSimpleClientset(), multiCluster_fake.NewSimpleClientset(), istio_fake.NewSimpleClientset(), gateway_fake.NewSimpleClientset(), []string{namespace}, 1000*time.Second, metricstore.NewFakeMetricStore(), environment.GetFakeEnv())
+		Expect(ctxt.Run(make(chan struct{}), true, environment.GetFakeEnv())).To(Succeed())
+
+		appGw := &n.ApplicationGateway{ApplicationGatewayPropertiesFormat: NewAppGwyConfigFixture()}
+		recorder = record.NewFakeRecorder(100)
+		cb = NewConfigBuilder(ctxt, &Identifier{
+			SubscriptionID: tests.Subscription,
+			ResourceGroup:  tests.ResourceGroup,
+			AppGwName:      tests.AppGwName,
+		}, appGw, recorder, mocks.Clock{}).(*appGwConfigBuilder)
+
+		ingress = &networking.Ingress{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        "web",
+				Namespace:   namespace,
+				Annotations: map[string]string{annotations.RouteMatchCustomResourceKey: "web-routes"},
+			},
+			Spec: networking.IngressSpec{
+				Rules: []networking.IngressRule{{
+					Host: "web.contoso.com",
+					IngressRuleValue: networking.IngressRuleValue{
+						HTTP: &networking.HTTPIngressRuleValue{
+							Paths: []networking.HTTPIngressPath{newPath("/", "web-v1"), newPath("/api", "web-v1")},
+						},
+					},
+				}},
+			},
+		}
+		cbCtx = &ConfigBuilderContext{
+			EnvVariables:          environment.GetFakeEnv(),
+			IngressList:           []*networking.Ingress{ingress},
+			DefaultAddressPoolID:  to.StringPtr(cb.appGwIdentifier.AddressPoolID(DefaultBackendAddressPoolName)),
+			DefaultHTTPSettingsID: to.StringPtr(cb.appGwIdentifier.HTTPSettingsID(DefaultBackendHTTPSettingsName)),
+		}
+	})
+
+	addRouteMatch := func(rules ...agroutematchv1beta1.RouteMatchRule) {
+		routeMatch := &agroutematchv1beta1.AzureApplicationGatewayRouteMatch{
+			ObjectMeta: metav1.ObjectMeta{Name: "web-routes", Namespace: namespace},
+			Spec:       agroutematchv1beta1.AzureApplicationGatewayRouteMatchSpec{Rules: rules},
+		}
+		Expect(ctxt.Caches.AzureApplicationGatewayRouteMatch.Add(routeMatch)).To(Succeed())
+	}
+
+	betaRule := agroutematchv1beta1.RouteMatchRule{
+		Name:    "beta",
+		Headers: []agroutematchv1beta1.ValueMatch{{Name: "X-Beta", Value: "true"}},
+		Backend: networking.IngressBackend{Service: newServiceBackend("web-beta")},
+	}
+
+	mobileRule := agroutematchv1beta1.RouteMatchRule{
+		Name:        "mobile",
+		QueryParams: []agroutematchv1beta1.ValueMatch{{Name: "client", Type: agroutematchv1beta1.MatchTypeRegularExpression, Value: "android|ios"}},
+		Cookies:     []agroutematchv1beta1.ValueMatch{{Name: "region", Value: "eu.west"}},
+		Backend:     networking.IngressBackend{Service: newServiceBackend("web-mobile")},
+	}
+
+	findPathRule := func(pathMap *n.ApplicationGatewayURLPathMap, name string) n.ApplicationGatewayPathRule {
+		for _, rule := range *pathMap.PathRules {
+			if *rule.Name == name {
+				return rule
+			}
+		}
+		Fail("path rule not found: " + name)
+		return n.ApplicationGatewayPathRule{}
+	}
+
+	findSettings := func(appGw *n.ApplicationGateway, id string) n.ApplicationGatewayBackendHTTPSettings {
+		for _, settings := range *appGw.BackendHTTPSettingsCollection {
+			if *settings.ID == id {
+				return settings
+			}
+		}
+		Fail("http settings not found: " + id)
+		return n.ApplicationGatewayBackendHTTPSettings{}
+	}
+
+	warnings := func() []string {
+		var received []string
+		for len(recorder.Events) > 0 {
+			received = append(received, <-recorder.Events)
+		}
+		return received
+	}
+
+	Context("ensure the route match of an Ingress reroutes the requests it matches", func() {
+		It("generates the rewrite rule set and the marker path rules of each route match rule", func() {
+			addRouteMatch(betaRule, mobileRule)
+
+			appGw, err := cb.Build(cbCtx)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(warnings()).To(BeEmpty())
+
+			Expect(*appGw.RewriteRuleSets).To(HaveLen(1))
+			ruleSet := (*appGw.RewriteRuleSets)[0]
+			Expect(*ruleSet.Name).To(Equal(generateRouteMatchRewriteRuleSetName(namespace, "web")))
+			rewriteRules := *ruleSet.RewriteRules
+			Expect(rewriteRules).To(HaveLen(2))
+
+			// The first route match rule runs last, so it wins when both match.
+			beta, mobile := rewriteRules[0], rewriteRules[1]
+			Expect(*beta.RuleSequence).To(BeNumerically(">", *mobile.RuleSequence))
+			Expect(*(*beta.Conditions)[0].Variable).To(Equal("http_req_X-Beta"))
+			Expect(*(*beta.Conditions)[0].Pattern).To(Equal("^true$"))
+			Expect(*beta.ActionSet.URLConfiguration.ModifiedPath).To(Equal("/agic-route-4780dff3b0485e15eb43c4b36b1c3e81-0{var_uri_path}"))
+			Expect(*beta.ActionSet.URLConfiguration.Reroute).To(BeTrue())
+
+			Expect(*mobile.Conditions).To(HaveLen(2))
+			Expect(*(*mobile.Conditions)[0].Variable).To(Equal("query_string"))
+			Expect(*(*mobile.Conditions)[0].Pattern).To(Equal("(^|&)client=(android|ios)(&|$)"))
+			Expect(*(*mobile.Conditions)[1].Variable).To(Equal("http_req_Cookie"))
+			Expect(*(*mobile.Conditions)[1].Pattern).To(Equal(`(^|;\s*)region=eu\.west(;|$)`))
+
+			Expect(*appGw.URLPathMaps).To(HaveLen(1))
+			pathMap := &(*appGw.URLPathMaps)[0]
+			Expect(*pathMap.DefaultRewriteRuleSet.ID).To(Equal(*ruleSet.ID))
+			Expect(*pathMap.PathRules).To(HaveLen(5))
+
+			apiRuleName := generatePathRuleName(namespace, "web", 0, 1)
+			Expect(*findPathRule(pathMap, apiRuleName).RewriteRuleSet.ID).To(Equal(*ruleSet.ID))
+
+			apiBeta := findPathRule(pathMap, apiRuleName+"-route-0")
+			Expect(*apiBeta.Paths).To(Equal([]string{"/agic-route-4780dff3b0485e15eb43c4b36b1c3e81-0/api*"}))
+			Expect(apiBeta.RewriteRuleSet).To(BeNil())
+			Expect(*apiBeta.BackendAddressPool.ID).To(HaveSuffix("/backendAddressPools/" + generateAddressPoolName(namespace+"-web-beta", "80", 8080)))
+			Expect(*findSettings(appGw, *apiBeta.BackendHTTPSettings.ID).Path).To(Equal("/api"))
+
+			// The catch-all path has no path rule of its own, but its marker path rules are needed.
+			defaultMobile := findPathRule(pathMap, generatePathRuleName(namespace, "web", 0, 0)+"-route-1")
+			Expect(*defaultMobile.Paths).To(Equal([]string{"/agic-route-4780dff3b0485e15eb43c4b36b1c3e81-1/*"}))
+			Expect(*defaultMobile.BackendAddressPool.ID).To(HaveSuffix("/backendAddressPools/" + generateAddressPoolName(namespace+"-web-mobile", "80", 9090)))
+			mobileSettings := findSettings(appGw, *defaultMobile.BackendHTTPSettings.ID)
+			Expect(*mobileSettings.Name).To(Equal(generateRouteMatchHTTPSettingsName(namespace, "web", 0, 0, 1)))
+			Expect(*mobileSettings.Path).To(Equal("/"))
+			Expect(*mobileSettings.Port).To(Equal(int32(9090)))
+		})
+
+		It("skips the invalid route match rules", func() {
+			invalidType := betaRule
+			invalidType.Name = "invalid-type"
+			invalidType.Headers = []agroutematchv1beta1.ValueMatch{{Name: "X-Beta", Type: "Prefix", Value: "t"}}
+			noConditions := agroutematchv1beta1.RouteMatchRule{Name: "no-conditions", Backend: betaRule.Backend}
+			addRouteMatch(invalidType, noConditions, mobileRule)
+
+			appGw, err := cb.Build(cbCtx)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(*appGw.RewriteRuleSets).To(HaveLen(1))
+			rewriteRules := *(*appGw.RewriteRuleSets)[0].RewriteRules
+			Expect(rewriteRules).To(HaveLen(1))
+			Expect(*rewriteRules[0].Name).To(Equal("mobile"))
+			Expect(*rewriteRules[0].ActionSet.URLConfiguration.ModifiedPath).To(Equal("/agic-route-4780dff3b0485e15eb43c4b36b1c3e81-2{var_uri_path}"))
+
+			received := warnings()
+			Expect(received).To(HaveLen(2))
+			Expect(received[0]).To(ContainSubstring(events.ReasonInvalidRouteMatch))
+			Expect(received[0]).To(ContainSubstring(`unknown match type "Prefix"`))
+			Expect(received[1]).To(ContainSubstring("matches no header, query parameter or cookie"))
+		})
+
+		It("does not apply a missing route match", func() {
+			appGw, err := cb.Build(cbCtx)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(*appGw.RewriteRuleSets).To(BeEmpty())
+			received := warnings()
+			Expect(received).To(HaveLen(1))
+			Expect(received[0]).To(ContainSubstring(events.ReasonInvalidRouteMatch))
+			Expect(received[0]).To(ContainSubstring("not found"))
+		})
+
+		It("does not apply the route match of an Ingress using a rewrite rule set", func() {
+			ingress.Annotations[annotations.RewriteRuleSetKey] = "my-rewrite-rule-set"
+			addRouteMatch(betaRule)
+
+			appGw, err := cb.Build(cbCtx)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(*appGw.RewriteRuleSets).To(BeEmpty())
+			pathRule := findPathRule(&(*appGw.URLPathMaps)[0], generatePathRuleName(namespace, "web", 0, 1))
+			Expect(*pathRule.RewriteRuleSet.ID).To(HaveSuffix("/rewriteRuleSets/my-rewrite-rule-set"))
+			received := warnings()
+			Expect(received).To(HaveLen(1))
+			Expect(received[0]).To(ContainSubstring("already uses rewrite rule set my-rewrite-rule-set"))
+		})
+	})
+})
@@ -6,15 +6,20 @@
 package appgw
 
 import (
+	"context"
 	"fmt"
 	"reflect"
 
 	n "github.com/Azure/azure-sdk-for-go/services/network/mgmt/2021-03-01/network"
 	"github.com/Azure/go-autorest/autorest/to"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/tools/cache"
 	"k8s.io/client-go/tools/record"
 
 	"github.com/Azure/application-gateway-kubernetes-ingress/pkg/k8scontext"
+	"github.com/Azure/application-gateway-kubernetes-ingress/pkg/k8scontext/convert"
 	"github.com/Azure/application-gateway-kubernetes-ingress/pkg/metricstore"
 	"github.com/Azure/application-gateway-kubernetes-ingress/pkg/tests"
 )
@@ -91,6 +96,26 @@ func keyFunc(obj interface{}) (string, error) {
 	return fmt.Sprintf("%s/%s", namespace, name), nil
 }
 
+// addEndpointsFixture adds the EndpointSlices mirrored from the endpoints to the cache of the context.
+func addEndpointsFixture(k8sContext *k8scontext.Context, endpoints *v1.Endpoints) error {
+	for _, endpointSlice := range convert.ToEndpointSlices(endpoints) {
+		if err := k8sContext.Caches.EndpointSlices.Add(endpointSlice); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// createEndpointsFixture creates the EndpointSlices mirrored from the endpoints through the client.
+func createEndpointsFixture(k8sClient kubernetes.Interface, endpoints *v1.Endpoints) error {
+	for _, endpointSlice := range convert.ToEndpointSlices(endpoints) {
+		if _, err := k8sClient.DiscoveryV1().EndpointSlices(endpointSlice.Namespace).Create(context.TODO(), endpointSlice, metav1.CreateOptions{}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func newConfigBuilderFixture(certs *map[string]interface{}) appGwConfigBuilder {
 	appGwConfig := NewAppGwyConfigFixture()
 	cb := appGwConfigBuilder{
@@ -102,12 +127,14 @@ func newConfigBuilderFixture(certs *map[string]interface{}) appGwConfigBuilder {
 		appGw: n.ApplicationGateway{ApplicationGatewayPropertiesFormat: appGwConfig},
 		k8sContext: &k8scontext.Context{
 			Caches: &k8scontext.CacheCollection{
-				AzureApplicationGatewayRewrite: cache.NewStore(keyFunc),
-				Endpoints:                      cache.NewStore(keyFunc),
-				Secret:                         cache.NewStore(keyFunc),
-				Service:                        cache.NewStore(keyFunc),
-				Pods:                           cache.NewStore(keyFunc),
-				Ingress:                        cache.NewStore(keyFunc),
+				AzureApplicationGatewayRewrite:    cache.NewStore(keyFunc),
+				EndpointSlices:                    cache.NewIndexer(keyFunc, k8scontext.EndpointSliceIndexers),
+				Secret:                            cache.NewStore(keyFunc),
+				Service:                           cache.NewStore(keyFunc),
+				Pods:                              cache.NewStore(keyFunc),
+				Ingress:                           cache.NewStore(keyFunc),
+				LoadDistributionPolicy:            cache.NewStore(keyFunc),
+				AzureApplicationGatewayRouteMatch: cache.NewStore(keyFunc),
 			},
 			CertificateSecretStore: newSecretStoreFixture(certs),
 			MetricStore:            metricstore.NewFakeMetricStore(),
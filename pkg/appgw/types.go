@@ -6,12 +6,17 @@
 package appgw
 
 import (
+	"time"
+
 	n "github.com/Azure/azure-sdk-for-go/services/network/mgmt/2021-03-01/network"
 	"github.com/knative/pkg/apis/istio/v1alpha3"
 	v1 "k8s.io/api/core/v1"
 	networking "k8s.io/api/networking/v1"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
 
 	"github.com/Azure/application-gateway-kubernetes-ingress/pkg/environment"
+	"github.com/Azure/application-gateway-kubernetes-ingress/pkg/gatewayapi"
+	"github.com/Azure/application-gateway-kubernetes-ingress/pkg/ingressstatus"
 
 	ptv1 "github.com/Azure/application-gateway-kubernetes-ingress/pkg/apis/azureingressprohibitedtarget/v1"
 )
@@ -19,17 +24,30 @@ import (
 // ConfigBuilderContext holds the structs we have fetches from Kubernetes + environment, based on which
 // we will construct App Gateway config.
 type ConfigBuilderContext struct {
-	IngressList          []*networking.Ingress
-	ServiceList          []*v1.Service
-	ProhibitedTargets    []*ptv1.AzureIngressProhibitedTarget
-	EnvVariables         environment.EnvVariables
-	IstioGateways        []*v1alpha3.Gateway
-	IstioVirtualServices []*v1alpha3.VirtualService
+	IngressList           []*networking.Ingress
+	ServiceList           []*v1.Service
+	ProhibitedTargets     []*ptv1.AzureIngressProhibitedTarget
+	EnvVariables          environment.EnvVariables
+	IstioGateways         []*v1alpha3.Gateway
+	IstioVirtualServices  []*v1alpha3.VirtualService
+	IstioDestinationRules []*v1alpha3.DestinationRule
+	GatewayAPIGateways    []*gatewayv1.Gateway
+	HTTPRoutes            []*gatewayv1.HTTPRoute
 
 	DefaultAddressPoolID  *string
 	DefaultHTTPSettingsID *string
 
 	ExistingPortsByNumber map[Port]n.ApplicationGatewayFrontendPort
+
+	// IngressStatus records why rules of the Ingresses were pruned.
+	IngressStatus *ingressstatus.Tracker
+
+	// GatewayAPIStatus records how the Gateways and HTTPRoutes were translated.
+	GatewayAPIStatus *gatewayapi.Tracker
+
+	// DrainingUntil is when the first of the terminating endpoints kept in backend pools for connection draining must be removed.
+	// It is zero when no endpoint is draining.
+	DrainingUntil time.Time
 }
 
 // InIngressList returns true if an ingress is in the ingress list
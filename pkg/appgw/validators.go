@@ -16,6 +16,7 @@ import (
 	"github.com/Azure/application-gateway-kubernetes-ingress/pkg/controllererrors"
 	"github.com/Azure/application-gateway-kubernetes-ingress/pkg/environment"
 	"github.com/Azure/application-gateway-kubernetes-ingress/pkg/events"
+	"github.com/Azure/application-gateway-kubernetes-ingress/pkg/ingressstatus"
 )
 
 func validateServiceDefinition(eventRecorder record.EventRecorder, config *n.ApplicationGatewayPropertiesFormat, envVariables environment.EnvVariables, ingressList []*networking.Ingress, serviceList []*v1.Service) error {
@@ -43,8 +44,7 @@ func validateServiceDefinition(eventRecorder record.EventRecorder, config *n.App
 	serviceSet := newServiceSet(&serviceList)
 	for be := range backendIDs {
 		if _, exists := serviceSet[be.serviceKey()]; !exists {
-			logLine := fmt.Sprintf("Ingress %s/%s references non existent Service %s. Please correct the Service section of your Kubernetes YAML", be.Ingress.Namespace, be.Ingress.Name, be.serviceKey())
-			eventRecorder.Event(be.Ingress, v1.EventTypeWarning, events.ReasonIngressServiceTargetMatch, logLine)
+			eventRecorder.Event(be.Ingress, v1.EventTypeWarning, events.ReasonIngressServiceTargetMatch, missingServiceMessage(be))
 			// NOTE: We could and should return a new error here.
 			// However this could be enabled at a later point in time once we know with certainty that there are no valid
 			// scenarios where one could have Ingress pointing to a missing Service targets.
@@ -53,6 +53,55 @@ func validateServiceDefinition(eventRecorder record.EventRecorder, config *n.App
 	return nil
 }
 
+// trackMissingServices records the rules referencing non existent Services in the Ingress status.
+// These rules are applied, but App Gateway has no backend to route their traffic to.
+func trackMissingServices(cbCtx *ConfigBuilderContext) {
+	for _, be := range backendsWithMissingService(cbCtx.IngressList, cbCtx.ServiceList) {
+		host, path := "", ""
+		if be.Rule != nil {
+			host = be.Rule.Host
+		}
+		if be.Path != nil {
+			path = be.Path.Path
+		}
+		cbCtx.IngressStatus.Warn(be.Ingress, ingressstatus.NewRule(host, path, be.Backend), events.ReasonServiceNotFound, missingServiceMessage(be))
+	}
+}
+
+// backendsWithMissingService lists every rule of the Ingresses which references a non existent Service, so each one gets
+// its own entry in the Ingress status.
+func backendsWithMissingService(ingressList []*networking.Ingress, serviceList []*v1.Service) []backendIdentifier {
+	var backendIDs []backendIdentifier
+	for _, ingress := range ingressList {
+		if ingress.Spec.DefaultBackend != nil {
+			backendIDs = append(backendIDs, generateBackendID(ingress, nil, nil, ingress.Spec.DefaultBackend))
+		}
+		for ruleIdx := range ingress.Spec.Rules {
+			rule := &ingress.Spec.Rules[ruleIdx]
+			if rule.HTTP == nil {
+				continue
+			}
+			for pathIdx := range rule.HTTP.Paths {
+				path := &rule.HTTP.Paths[pathIdx]
+				backendIDs = append(backendIDs, generateBackendID(ingress, rule, path, &path.Backend))
+			}
+		}
+	}
+
+	var missing []backendIdentifier
+	serviceSet := newServiceSet(&serviceList)
+	for _, be := range backendIDs {
+		if _, exists := serviceSet[be.serviceKey()]; !exists {
+			missing = append(missing, be)
+		}
+	}
+	return missing
+}
+
+func missingServiceMessage(be backendIdentifier) string {
+	return fmt.Sprintf("Ingress %s/%s references non existent Service %s. Please correct the Service section of your Kubernetes YAML", be.Ingress.Namespace, be.Ingress.Name, be.serviceKey())
+}
+
 func validateURLPathMaps(eventRecorder record.EventRecorder, config *n.ApplicationGatewayPropertiesFormat, envVariables environment.EnvVariables, ingressList []*networking.Ingress, serviceList []*v1.Service) error {
 	if config.URLPathMaps == nil {
 		return nil
@@ -16,6 +16,9 @@ import (
 
 	"github.com/Azure/application-gateway-kubernetes-ingress/pkg/controllererrors"
 	"github.com/Azure/application-gateway-kubernetes-ingress/pkg/environment"
+	"github.com/Azure/application-gateway-kubernetes-ingress/pkg/events"
+	"github.com/Azure/application-gateway-kubernetes-ingress/pkg/ingressstatus"
+	"github.com/Azure/application-gateway-kubernetes-ingress/pkg/tests/fixtures"
 )
 
 // appgw_suite_test.go launches these Ginkgo tests
@@ -115,4 +118,43 @@ var _ = Describe("Test ConfigBuilder validator functions", func() {
 			Expect(err).To(BeNil())
 		})
 	})
+
+	Context("test validateServiceDefinition", func() {
+		var ingress *networking.Ingress
+		var eventRecorder *record.FakeRecorder
+
+		BeforeEach(func() {
+			ingress = fixtures.GetIngress()
+			ingress.Spec.Rules[0].HTTP.Paths = append(ingress.Spec.Rules[0].HTTP.Paths, ingress.Spec.Rules[0].HTTP.Paths[0])
+			ingress.Spec.Rules[0].HTTP.Paths[1].Path = "/api"
+			eventRecorder = record.NewFakeRecorder(100)
+		})
+
+		It("should not emit events for the paths of Ingresses without a default backend", func() {
+			err := validateServiceDefinition(eventRecorder, nil, environment.GetFakeEnv(), []*networking.Ingress{ingress}, []*v1.Service{})
+			Expect(err).To(BeNil())
+			Expect(eventRecorder.Events).To(BeEmpty())
+		})
+
+		It("should emit one event per missing backend of Ingresses with a default backend", func() {
+			ingress.Spec.DefaultBackend = &ingress.Spec.Rules[0].HTTP.Paths[0].Backend
+			err := validateServiceDefinition(eventRecorder, nil, environment.GetFakeEnv(), []*networking.Ingress{ingress}, []*v1.Service{})
+			Expect(err).To(BeNil())
+			Expect(eventRecorder.Events).To(HaveLen(3))
+		})
+
+		It("should record every path referencing a missing Service in the Ingress status", func() {
+			cbCtx := &ConfigBuilderContext{
+				IngressList:   []*networking.Ingress{ingress},
+				ServiceList:   []*v1.Service{},
+				IngressStatus: ingressstatus.NewTracker(),
+			}
+			trackMissingServices(cbCtx)
+			status := cbCtx.IngressStatus.Status(ingress)
+			Expect(status.AppliedRules).To(HaveLen(2))
+			for _, rule := range status.AppliedRules {
+				Expect(rule.Reason).To(Equal(events.ReasonServiceNotFound))
+			}
+		})
+	})
 })
@@ -9,6 +9,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"net/http"
 	"time"
 
 	r "github.com/Azure/azure-sdk-for-go/profiles/latest/resources/mgmt/resources"
@@ -28,16 +29,20 @@ type AzClient interface {
 	SetAuthorizer(authorizer autorest.Authorizer)
 	SetSender(sender autorest.Sender)
 	SetDuration(retryDuration time.Duration)
-
-	ApplyRouteTable(string, string) error
-	WaitForGetAccessOnGateway(maxRetryCount int) error
-	GetGateway() (n.ApplicationGateway, error)
-	UpdateGateway(*n.ApplicationGateway) error
-	DeployGatewayWithVnet(ResourceGroup, ResourceName, ResourceName, string, string) error
-	DeployGatewayWithSubnet(string, string) error
-	GetSubnet(string) (n.Subnet, error)
-
-	GetPublicIP(string) (n.PublicIPAddress, error)
+	SetTimeouts(timeouts Timeouts)
+	SetRetryPolicy(policy RetryPolicy)
+	SetRetryMetrics(metrics RetryMetrics)
+
+	// All operations stop waiting on ARM once ctx is done. A long running operation which ARM already accepted carries on in Azure.
+	ApplyRouteTable(ctx context.Context, subnetID string, routeTableID string) error
+	WaitForGetAccessOnGateway(ctx context.Context, maxRetryCount int) error
+	GetGateway(ctx context.Context) (n.ApplicationGateway, error)
+	UpdateGateway(ctx context.Context, appGwObj *n.ApplicationGateway) error
+	DeployGatewayWithVnet(ctx context.Context, resourceGroupName ResourceGroup, vnetName ResourceName, subnetName ResourceName, subnetPrefix, skuName string) error
+	DeployGatewayWithSubnet(ctx context.Context, subnetID, skuName string) error
+	GetSubnet(ctx context.Context, subnetID string) (n.Subnet, error)
+
+	GetPublicIP(ctx context.Context, resourceID string) (n.PublicIPAddress, error)
 }
 
 type azClient struct {
@@ -55,7 +60,9 @@ type azClient struct {
 	appGwName         ResourceName
 	memoizedIPs       map[string]n.PublicIPAddress
 
-	ctx context.Context
+	timeouts     Timeouts
+	retryPolicy  RetryPolicy
+	retryMetrics RetryMetrics
 }
 
 // NewAzClient returns an Azure Client
@@ -64,16 +71,21 @@ func NewAzClient(subscriptionID SubscriptionID, resourceGroupName ResourceGroup,
 	if err != nil {
 		return nil
 	}
+	return NewAzClientWithBaseURI(settings.Environment.ResourceManagerEndpoint, subscriptionID, resourceGroupName, appGwName, uniqueUserAgentSuffix, clientID)
+}
 
+// NewAzClientWithBaseURI returns an Azure Client which talks to the Azure Resource Manager at baseURI, e.g. the fake ARM
+// server of package fakearm.
+func NewAzClientWithBaseURI(baseURI string, subscriptionID SubscriptionID, resourceGroupName ResourceGroup, appGwName ResourceName, uniqueUserAgentSuffix, clientID string) AzClient {
 	userAgent := fmt.Sprintf("ingress-appgw/%s/%s", version.Version, uniqueUserAgentSuffix)
 	az := &azClient{
-		appGatewaysClient:     n.NewApplicationGatewaysClientWithBaseURI(settings.Environment.ResourceManagerEndpoint, string(subscriptionID)),
-		publicIPsClient:       n.NewPublicIPAddressesClientWithBaseURI(settings.Environment.ResourceManagerEndpoint, string(subscriptionID)),
-		virtualNetworksClient: n.NewVirtualNetworksClientWithBaseURI(settings.Environment.ResourceManagerEndpoint, string(subscriptionID)),
-		subnetsClient:         n.NewSubnetsClientWithBaseURI(settings.Environment.ResourceManagerEndpoint, string(subscriptionID)),
-		routeTablesClient:     n.NewRouteTablesClientWithBaseURI(settings.Environment.ResourceManagerEndpoint, string(subscriptionID)),
-		groupsClient:          r.NewGroupsClientWithBaseURI(settings.Environment.ResourceManagerEndpoint, string(subscriptionID)),
-		deploymentsClient:     r.NewDeploymentsClientWithBaseURI(settings.Environment.ResourceManagerEndpoint, string(subscriptionID)),
+		appGatewaysClient:     n.NewApplicationGatewaysClientWithBaseURI(baseURI, string(subscriptionID)),
+		publicIPsClient:       n.NewPublicIPAddressesClientWithBaseURI(baseURI, string(subscriptionID)),
+		virtualNetworksClient: n.NewVirtualNetworksClientWithBaseURI(baseURI, string(subscriptionID)),
+		subnetsClient:         n.NewSubnetsClientWithBaseURI(baseURI, string(subscriptionID)),
+		routeTablesClient:     n.NewRouteTablesClientWithBaseURI(baseURI, string(subscriptionID)),
+		groupsClient:          r.NewGroupsClientWithBaseURI(baseURI, string(subscriptionID)),
+		deploymentsClient:     r.NewDeploymentsClientWithBaseURI(baseURI, string(subscriptionID)),
 		clientID:              clientID,
 
 		subscriptionID:    subscriptionID,
@@ -81,9 +93,19 @@ func NewAzClient(subscriptionID SubscriptionID, resourceGroupName ResourceGroup,
 		appGwName:         appGwName,
 		memoizedIPs:       make(map[string]n.PublicIPAddress),
 
-		ctx: context.Background(),
+		timeouts:     DefaultTimeouts(),
+		retryPolicy:  DefaultRetryPolicy(),
+		retryMetrics: noopRetryMetrics{},
 	}
 
+	az.appGatewaysClient.SendDecorators = []autorest.SendDecorator{az.sendWithRetries()}
+	az.publicIPsClient.SendDecorators = []autorest.SendDecorator{az.sendWithRetries()}
+	az.virtualNetworksClient.SendDecorators = []autorest.SendDecorator{az.sendWithRetries()}
+	az.subnetsClient.SendDecorators = []autorest.SendDecorator{az.sendWithRetries()}
+	az.routeTablesClient.SendDecorators = []autorest.SendDecorator{az.sendWithRetries()}
+	az.groupsClient.SendDecorators = []autorest.SendDecorator{az.sendWithRetries()}
+	az.deploymentsClient.SendDecorators = []autorest.SendDecorator{az.sendWithRetries()}
+
 	if err := az.appGatewaysClient.AddToUserAgent(userAgent); err != nil {
 		klog.Error("Error adding User Agent to App Gateway client: ", userAgent)
 	}
@@ -106,10 +128,6 @@ func NewAzClient(subscriptionID SubscriptionID, resourceGroupName ResourceGroup,
 		klog.Error("Error adding User Agent to Deployments client: ", userAgent)
 	}
 
-	// increase the polling duration to 60 minutes
-	az.appGatewaysClient.PollingDuration = 60 * time.Minute
-	az.deploymentsClient.PollingDuration = 60 * time.Minute
-
 	return az
 }
 
@@ -129,13 +147,28 @@ func (az *azClient) SetSender(sender autorest.Sender) {
 
 func (az *azClient) SetDuration(retryDuration time.Duration) {
 	az.appGatewaysClient.Client.RetryDuration = retryDuration
+	az.retryPolicy.BaseDelay = retryDuration
+}
+
+func (az *azClient) SetTimeouts(timeouts Timeouts) {
+	az.timeouts = timeouts
+}
+
+func (az *azClient) SetRetryPolicy(policy RetryPolicy) {
+	az.retryPolicy = policy
 }
 
-func (az *azClient) WaitForGetAccessOnGateway(maxRetryCount int) (err error) {
+func (az *azClient) SetRetryMetrics(metrics RetryMetrics) {
+	az.retryMetrics = metrics
+}
+
+func (az *azClient) WaitForGetAccessOnGateway(ctx context.Context, maxRetryCount int) (err error) {
 	klog.V(3).Info("Getting Application Gateway configuration.")
-	err = utils.Retry(maxRetryCount, retryPause,
+	err = utils.RetryWithContext(ctx, maxRetryCount, retryPause,
 		func() (utils.Retriable, error) {
-			response, err := az.appGatewaysClient.Get(az.ctx, string(az.resourceGroupName), string(az.appGwName))
+			getCtx, cancel := az.getContext(ctx)
+			defer cancel()
+			response, err := az.appGatewaysClient.Get(getCtx, string(az.resourceGroupName), string(az.appGwName))
 			if err == nil {
 				return utils.Retriable(true), nil
 			}
@@ -200,41 +233,79 @@ func (az *azClient) WaitForGetAccessOnGateway(maxRetryCount int) (err error) {
 	return
 }
 
-func (az *azClient) GetGateway() (gateway n.ApplicationGateway, err error) {
-	err = utils.Retry(retryCount, retryPause,
-		func() (utils.Retriable, error) {
-			gateway, err = az.appGatewaysClient.Get(az.ctx, string(az.resourceGroupName), string(az.appGwName))
-			if err != nil {
-				klog.Errorf("Error while getting application gateway '%s': %s", string(az.appGwName), err)
-			}
-			return utils.Retriable(true), err
-		})
+// GetGateway reads App Gateway once; Throttled and transiently failed reads are retried by the RetryPolicy.
+func (az *azClient) GetGateway(ctx context.Context) (gateway n.ApplicationGateway, err error) {
+	getCtx, cancel := az.getContext(ctx)
+	defer cancel()
+	gateway, err = az.appGatewaysClient.Get(getCtx, string(az.resourceGroupName), string(az.appGwName))
+	if err != nil {
+		klog.Errorf("Error while getting application gateway '%s': %s", string(az.appGwName), err)
+	}
 	return
 }
 
-func (az *azClient) UpdateGateway(appGwObj *n.ApplicationGateway) (err error) {
-	appGwFuture, err := az.appGatewaysClient.CreateOrUpdate(az.ctx, string(az.resourceGroupName), string(az.appGwName), *appGwObj)
+func (az *azClient) UpdateGateway(ctx context.Context, appGwObj *n.ApplicationGateway) (err error) {
+	putCtx, cancelPut := az.putContext(ctx)
+	defer cancelPut()
+	appGwFuture, err := az.createOrUpdateGateway(putCtx, appGwObj)
 	if err != nil {
 		return
 	}
 
+	operationID := GetOperationIDFromPollingURL(appGwFuture.PollingURL())
 	if appGwFuture.PollingURL() != "" {
-		klog.V(3).Infof("OperationID='%s'", GetOperationIDFromPollingURL(appGwFuture.PollingURL()))
+		klog.V(3).Infof("OperationID='%s'", operationID)
 	}
 
 	// Wait until deployment finshes and save the error message
-	err = appGwFuture.WaitForCompletionRef(az.ctx, az.appGatewaysClient.BaseClient.Client)
+	pollingCtx, cancelPolling := az.pollingContext(ctx)
+	defer cancelPolling()
+	err = appGwFuture.WaitForCompletionRef(pollingCtx, az.appGatewaysClient.BaseClient.Client)
+	if err != nil && pollingCtx.Err() != nil {
+		klog.Warningf("Stopped waiting for the deployment of Application Gateway (OperationID='%s') to complete; It carries on in Azure: %s", operationID, pollingCtx.Err())
+	}
 	return
 }
 
-func (az *azClient) GetPublicIP(resourceID string) (n.PublicIPAddress, error) {
+// createOrUpdateGateway starts the PUT of App Gateway. It carries the ETag of appGwObj in the If-Match header, so that ARM rejects
+// the PUT when App Gateway was modified since appGwObj was read, instead of overwriting the modification.
+func (az *azClient) createOrUpdateGateway(ctx context.Context, appGwObj *n.ApplicationGateway) (appGwFuture n.ApplicationGatewaysCreateOrUpdateFuture, err error) {
+	req, err := az.appGatewaysClient.CreateOrUpdatePreparer(ctx, string(az.resourceGroupName), string(az.appGwName), *appGwObj)
+	if err != nil {
+		return appGwFuture, autorest.NewErrorWithError(err, "network.ApplicationGatewaysClient", "CreateOrUpdate", nil, "Failure preparing request")
+	}
+
+	etag := to.String(appGwObj.Etag)
+	if etag != "" && etag != "*" {
+		if req, err = autorest.Prepare(req, autorest.WithHeader("If-Match", etag)); err != nil {
+			return appGwFuture, autorest.NewErrorWithError(err, "network.ApplicationGatewaysClient", "CreateOrUpdate", nil, "Failure preparing request")
+		}
+	}
+
+	appGwFuture, err = az.appGatewaysClient.CreateOrUpdateSender(req)
+	if err != nil {
+		if response := appGwFuture.Response(); response != nil && response.StatusCode == http.StatusPreconditionFailed {
+			return appGwFuture, controllererrors.NewErrorWithInnerErrorf(
+				controllererrors.ErrorApplicationGatewayConcurrentUpdate,
+				err,
+				"Application Gateway '%s' was modified since its ETag '%s' was read.", string(az.appGwName), etag,
+			)
+		}
+		return appGwFuture, autorest.NewErrorWithError(err, "network.ApplicationGatewaysClient", "CreateOrUpdate", appGwFuture.Response(), "Failure sending request")
+	}
+	return appGwFuture, nil
+}
+
+func (az *azClient) GetPublicIP(ctx context.Context, resourceID string) (n.PublicIPAddress, error) {
 	if ip, ok := az.memoizedIPs[resourceID]; ok {
 		return ip, nil
 	}
 
 	_, resourceGroupName, publicIPName := ParseResourceID(resourceID)
 
-	ip, err := az.publicIPsClient.Get(az.ctx, string(resourceGroupName), string(publicIPName), "")
+	getCtx, cancel := az.getContext(ctx)
+	defer cancel()
+	ip, err := az.publicIPsClient.Get(getCtx, string(resourceGroupName), string(publicIPName), "")
 	if err != nil {
 		return n.PublicIPAddress{}, err
 	}
@@ -242,10 +313,13 @@ func (az *azClient) GetPublicIP(resourceID string) (n.PublicIPAddress, error) {
 	return ip, nil
 }
 
-func (az *azClient) ApplyRouteTable(subnetID string, routeTableID string) error {
+func (az *azClient) ApplyRouteTable(ctx context.Context, subnetID string, routeTableID string) error {
+	getCtx, cancelGet := az.getContext(ctx)
+	defer cancelGet()
+
 	// Check if the route table exists
 	_, routeTableResourceGroup, routeTableName := ParseResourceID(routeTableID)
-	routeTable, err := az.routeTablesClient.Get(az.ctx, string(routeTableResourceGroup), string(routeTableName), "")
+	routeTable, err := az.routeTablesClient.Get(getCtx, string(routeTableResourceGroup), string(routeTableName), "")
 
 	// if route table is not found, then simply add a log and return no error. routeTable will always be initialized.
 	if routeTable.Response.StatusCode == 404 {
@@ -259,7 +333,7 @@ func (az *azClient) ApplyRouteTable(subnetID string, routeTableID string) error
 
 	// Get subnet and check if it is already associated to a route table
 	_, subnetResourceGroup, subnetVnetName, subnetName := ParseSubResourceID(subnetID)
-	subnet, err := az.subnetsClient.Get(az.ctx, string(subnetResourceGroup), string(subnetVnetName), string(subnetName), "")
+	subnet, err := az.subnetsClient.Get(getCtx, string(subnetResourceGroup), string(subnetVnetName), string(subnetName), "")
 	if err != nil {
 		return err
 	}
@@ -282,13 +356,17 @@ func (az *azClient) ApplyRouteTable(subnetID string, routeTableID string) error
 	klog.Infof("Associating Application Gateway subnet '%s' with route table '%s' used by k8s cluster.", subnetID, routeTableID)
 	subnet.RouteTable = &routeTable
 
-	subnetFuture, err := az.subnetsClient.CreateOrUpdate(az.ctx, string(subnetResourceGroup), string(subnetVnetName), string(subnetName), subnet)
+	putCtx, cancelPut := az.putContext(ctx)
+	defer cancelPut()
+	subnetFuture, err := az.subnetsClient.CreateOrUpdate(putCtx, string(subnetResourceGroup), string(subnetVnetName), string(subnetName), subnet)
 	if err != nil {
 		return err
 	}
 
 	// Wait until deployment finshes and save the error message
-	err = subnetFuture.WaitForCompletionRef(az.ctx, az.subnetsClient.BaseClient.Client)
+	pollingCtx, cancelPolling := az.pollingContext(ctx)
+	defer cancelPolling()
+	err = subnetFuture.WaitForCompletionRef(pollingCtx, az.subnetsClient.BaseClient.Client)
 	if err != nil {
 		return err
 	}
@@ -296,11 +374,13 @@ func (az *azClient) ApplyRouteTable(subnetID string, routeTableID string) error
 	return nil
 }
 
-func (az *azClient) GetSubnet(subnetID string) (subnet n.Subnet, err error) {
-	_ = utils.Retry(retryCount, retryPause,
+func (az *azClient) GetSubnet(ctx context.Context, subnetID string) (subnet n.Subnet, err error) {
+	_ = utils.RetryWithContext(ctx, retryCount, retryPause,
 		func() (utils.Retriable, error) {
+			getCtx, cancel := az.getContext(ctx)
+			defer cancel()
 			_, subnetResourceGroup, subnetVnetName, subnetName := ParseSubResourceID(subnetID)
-			subnet, err = az.subnetsClient.Get(az.ctx, string(subnetResourceGroup), string(subnetVnetName), string(subnetName), "")
+			subnet, err = az.subnetsClient.Get(getCtx, string(subnetResourceGroup), string(subnetVnetName), string(subnetName), "")
 			return utils.Retriable(true), err
 		})
 
@@ -308,8 +388,8 @@ func (az *azClient) GetSubnet(subnetID string) (subnet n.Subnet, err error) {
 }
 
 // DeployGatewayWithVnet creates Application Gateway within the specifid VNet. Implements AzClient interface.
-func (az *azClient) DeployGatewayWithVnet(resourceGroupName ResourceGroup, vnetName ResourceName, subnetName ResourceName, subnetPrefix, skuName string) (err error) {
-	vnet, err := az.getVnet(resourceGroupName, vnetName)
+func (az *azClient) DeployGatewayWithVnet(ctx context.Context, resourceGroupName ResourceGroup, vnetName ResourceName, subnetName ResourceName, subnetPrefix, skuName string) (err error) {
+	vnet, err := az.getVnet(ctx, resourceGroupName, vnetName)
 	if err != nil {
 		return
 	}
@@ -323,28 +403,28 @@ func (az *azClient) DeployGatewayWithVnet(resourceGroupName ResourceGroup, vnetN
 		}
 
 		klog.Infof("Unable to find a subnet. Creating a subnet '%s' with prefix '%s' in Vnet '%s'.", subnetName, subnetPrefix, vnetName)
-		subnet, err = az.createSubnet(vnet, subnetName, subnetPrefix)
+		subnet, err = az.createSubnet(ctx, vnet, subnetName, subnetPrefix)
 		if err != nil {
 			return
 		}
 	} else if subnet.SubnetPropertiesFormat != nil && (subnet.SubnetPropertiesFormat.Delegations == nil || (subnet.SubnetPropertiesFormat.Delegations != nil && len(*subnet.SubnetPropertiesFormat.Delegations) == 0)) {
 		klog.Infof("Subnet '%s' is an existing subnet and subnet delegation to Application Gateway is not found, creating a delegation.", subnetName)
-		subnet, err = az.createSubnet(vnet, subnetName, subnetPrefix)
+		subnet, err = az.createSubnet(ctx, vnet, subnetName, subnetPrefix)
 		if err != nil {
 			klog.Errorf("Backfill delegation to Application Gateway on existing subnet has failed. Please check the subnet '%s' in vnet '%s'.", subnetName, vnetName)
 		}
 	}
 
-	err = az.DeployGatewayWithSubnet(*subnet.ID, skuName)
+	err = az.DeployGatewayWithSubnet(ctx, *subnet.ID, skuName)
 	return
 }
 
 // DeployGatewayWithSubnet creates Application Gateway within the specifid subnet. Implements AzClient interface.
-func (az *azClient) DeployGatewayWithSubnet(subnetID, skuName string) (err error) {
+func (az *azClient) DeployGatewayWithSubnet(ctx context.Context, subnetID, skuName string) (err error) {
 	klog.Infof("Deploying Gateway")
 
 	// Check if group exists
-	group, err := az.getGroup()
+	group, err := az.getGroup(ctx)
 	if err != nil {
 		return
 	}
@@ -352,7 +432,7 @@ func (az *azClient) DeployGatewayWithSubnet(subnetID, skuName string) (err error
 
 	deploymentName := string(az.appGwName)
 	klog.Infof("Starting ARM template deployment: %s", deploymentName)
-	result, err := az.createDeployment(subnetID, skuName)
+	result, err := az.createDeployment(ctx, subnetID, skuName)
 	if err != nil {
 		return
 	}
@@ -366,10 +446,12 @@ func (az *azClient) DeployGatewayWithSubnet(subnetID, skuName string) (err error
 }
 
 // Create a resource group for the deployment.
-func (az *azClient) getGroup() (group r.Group, err error) {
-	utils.Retry(retryCount, retryPause,
+func (az *azClient) getGroup(ctx context.Context) (group r.Group, err error) {
+	utils.RetryWithContext(ctx, retryCount, retryPause,
 		func() (utils.Retriable, error) {
-			group, err = az.groupsClient.Get(az.ctx, string(az.resourceGroupName))
+			getCtx, cancel := az.getContext(ctx)
+			defer cancel()
+			group, err = az.groupsClient.Get(getCtx, string(az.resourceGroupName))
 			if err != nil {
 				klog.Errorf("Error while getting resource group '%s': %s", az.resourceGroupName, err)
 			}
@@ -379,10 +461,12 @@ func (az *azClient) getGroup() (group r.Group, err error) {
 	return
 }
 
-func (az *azClient) getVnet(resourceGroupName ResourceGroup, vnetName ResourceName) (vnet n.VirtualNetwork, err error) {
-	utils.Retry(extendedRetryCount, retryPause,
+func (az *azClient) getVnet(ctx context.Context, resourceGroupName ResourceGroup, vnetName ResourceName) (vnet n.VirtualNetwork, err error) {
+	utils.RetryWithContext(ctx, extendedRetryCount, retryPause,
 		func() (utils.Retriable, error) {
-			vnet, err = az.virtualNetworksClient.Get(az.ctx, string(resourceGroupName), string(vnetName), "")
+			getCtx, cancel := az.getContext(ctx)
+			defer cancel()
+			vnet, err = az.virtualNetworksClient.Get(getCtx, string(resourceGroupName), string(vnetName), "")
 			if err != nil {
 				klog.Errorf("Error while getting virtual network '%s': %s", vnetName, err)
 			}
@@ -405,7 +489,7 @@ func (az *azClient) findSubnet(vnet n.VirtualNetwork, subnetName ResourceName, s
 	return
 }
 
-func (az *azClient) createSubnet(vnet n.VirtualNetwork, subnetName ResourceName, subnetPrefix string) (subnet n.Subnet, err error) {
+func (az *azClient) createSubnet(ctx context.Context, vnet n.VirtualNetwork, subnetName ResourceName, subnetPrefix string) (subnet n.Subnet, err error) {
 	_, resourceGroup, vnetName := ParseResourceID(*vnet.ID)
 	subnet = n.Subnet{
 		SubnetPropertiesFormat: &n.SubnetPropertiesFormat{
@@ -420,22 +504,28 @@ func (az *azClient) createSubnet(vnet n.VirtualNetwork, subnetName ResourceName,
 			},
 		},
 	}
-	subnetFuture, err := az.subnetsClient.CreateOrUpdate(az.ctx, string(resourceGroup), string(vnetName), string(subnetName), subnet)
+	putCtx, cancelPut := az.putContext(ctx)
+	defer cancelPut()
+	subnetFuture, err := az.subnetsClient.CreateOrUpdate(putCtx, string(resourceGroup), string(vnetName), string(subnetName), subnet)
 	if err != nil {
 		return
 	}
 
 	// Wait until deployment finshes and save the error message
-	err = subnetFuture.WaitForCompletionRef(az.ctx, az.subnetsClient.BaseClient.Client)
+	pollingCtx, cancelPolling := az.pollingContext(ctx)
+	defer cancelPolling()
+	err = subnetFuture.WaitForCompletionRef(pollingCtx, az.subnetsClient.BaseClient.Client)
 	if err != nil {
 		return
 	}
 
-	return az.subnetsClient.Get(az.ctx, string(resourceGroup), string(vnetName), string(subnetName), "")
+	getCtx, cancelGet := az.getContext(ctx)
+	defer cancelGet()
+	return az.subnetsClient.Get(getCtx, string(resourceGroup), string(vnetName), string(subnetName), "")
 }
 
 // Create the deployment
-func (az *azClient) createDeployment(subnetID, skuName string) (deployment r.DeploymentExtended, err error) {
+func (az *azClient) createDeployment(ctx context.Context, subnetID, skuName string) (deployment r.DeploymentExtended, err error) {
 	template := getTemplate()
 	if err != nil {
 		return
@@ -452,8 +542,10 @@ func (az *azClient) createDeployment(subnetID, skuName string) (deployment r.Dep
 		},
 	}
 
+	putCtx, cancelPut := az.putContext(ctx)
+	defer cancelPut()
 	deploymentFuture, err := az.deploymentsClient.CreateOrUpdate(
-		az.ctx,
+		putCtx,
 		string(az.resourceGroupName),
 		string(az.appGwName),
 		r.Deployment{
@@ -467,7 +559,9 @@ func (az *azClient) createDeployment(subnetID, skuName string) (deployment r.Dep
 	if err != nil {
 		return
 	}
-	err = deploymentFuture.WaitForCompletionRef(az.ctx, az.deploymentsClient.BaseClient.Client)
+	pollingCtx, cancelPolling := az.pollingContext(ctx)
+	defer cancelPolling()
+	err = deploymentFuture.WaitForCompletionRef(pollingCtx, az.deploymentsClient.BaseClient.Client)
 	if err != nil {
 		return
 	}
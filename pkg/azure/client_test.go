@@ -2,6 +2,7 @@ package azure
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"io"
@@ -9,9 +10,12 @@ import (
 	"time"
 
 	n "github.com/Azure/azure-sdk-for-go/services/network/mgmt/2021-03-01/network"
+	"github.com/Azure/go-autorest/autorest/to"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 	"k8s.io/klog/v2"
+
+	"github.com/Azure/application-gateway-kubernetes-ingress/pkg/controllererrors"
 )
 
 type FakeSender struct {
@@ -36,6 +40,26 @@ func (fs *FakeSender) Do(request *http.Request) (response *http.Response, err er
 	return response, err
 }
 
+// ConditionalSender fails PUT requests whose If-Match header does not match its ETag.
+type ConditionalSender struct {
+	etag    string
+	ifMatch []string
+}
+
+func (cs *ConditionalSender) Do(request *http.Request) (*http.Response, error) {
+	cs.ifMatch = append(cs.ifMatch, request.Header.Get("If-Match"))
+	statusCode := http.StatusPreconditionFailed
+	if ifMatch := request.Header.Get("If-Match"); ifMatch == "" || ifMatch == cs.etag {
+		statusCode = http.StatusOK
+	}
+	return &http.Response{
+		StatusCode: statusCode,
+		Request:    request,
+		Header:     http.Header{},
+		Body:       io.NopCloser(bytes.NewReader([]byte(`{"properties":{"provisioningState":"Succeeded"}}`))),
+	}, nil
+}
+
 var _ = DescribeTable("Az Application Gateway failures using authorizer", func(statusCodeArg int, errorExpected bool) {
 	var azClient = NewAzClient("", "", "", "", "")
 	var fakeSender = &FakeSender{
@@ -48,7 +72,7 @@ var _ = DescribeTable("Az Application Gateway failures using authorizer", func(s
 	}
 	azClient.SetDuration(retryDuration)
 	azClient.SetSender(fakeSender)
-	err = azClient.WaitForGetAccessOnGateway(3)
+	err = azClient.WaitForGetAccessOnGateway(context.Background(), 3)
 	if errorExpected {
 		Expect(err).To(HaveOccurred())
 	} else {
@@ -61,3 +85,191 @@ var _ = DescribeTable("Az Application Gateway failures using authorizer", func(s
 	Entry("403 Error", 403, true),
 	Entry("404 Error", 404, true),
 )
+
+var _ = Describe("Az Application Gateway client with a context", func() {
+	It("stops retrying once the context is done", func() {
+		azClient := NewAzClient("", "", "", "", "")
+		azClient.SetSender(&FakeSender{statusCode: 500})
+		azClient.SetDuration(time.Millisecond)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		started := time.Now()
+		Expect(azClient.WaitForGetAccessOnGateway(ctx, 3)).To(HaveOccurred())
+		Expect(time.Since(started)).To(BeNumerically("<", retryPause))
+	})
+
+	It("keeps the default timeouts which are not configured", func() {
+		timeouts := NewTimeouts("30s", "", "invalid")
+		Expect(timeouts.Get).To(Equal(30 * time.Second))
+		Expect(timeouts.Put).To(Equal(DefaultTimeouts().Put))
+		Expect(timeouts.Polling).To(Equal(DefaultTimeouts().Polling))
+	})
+})
+
+var _ = Describe("Az Application Gateway updates with an ETag", func() {
+	var azClient AzClient
+	var sender *ConditionalSender
+
+	BeforeEach(func() {
+		azClient = NewAzClient("", "", "", "", "")
+		sender = &ConditionalSender{etag: "current"}
+		azClient.SetSender(sender)
+	})
+
+	It("carries the ETag in the If-Match header", func() {
+		Expect(azClient.UpdateGateway(context.Background(), &n.ApplicationGateway{Etag: to.StringPtr("current")})).To(Succeed())
+		Expect(sender.ifMatch[0]).To(Equal("current"))
+	})
+
+	It("reports a concurrent update when App Gateway was modified since it was read", func() {
+		err := azClient.UpdateGateway(context.Background(), &n.ApplicationGateway{Etag: to.StringPtr("outdated")})
+		Expect(controllererrors.IsErrorCode(err, controllererrors.ErrorApplicationGatewayConcurrentUpdate)).To(BeTrue())
+	})
+
+	It("updates unconditionally without an ETag", func() {
+		Expect(azClient.UpdateGateway(context.Background(), &n.ApplicationGateway{Etag: to.StringPtr("*")})).To(Succeed())
+		Expect(sender.ifMatch[0]).To(BeEmpty())
+	})
+})
+
+// ScriptedSender answers the requests with its responses in order, repeating the last one.
+type ScriptedSender struct {
+	responses []*http.Response
+	bodies    []string
+}
+
+func (ss *ScriptedSender) Do(request *http.Request) (*http.Response, error) {
+	body := ""
+	if request.Body != nil {
+		b, _ := io.ReadAll(request.Body)
+		body = string(b)
+	}
+	ss.bodies = append(ss.bodies, body)
+
+	response := ss.responses[len(ss.responses)-1]
+	if len(ss.bodies) <= len(ss.responses) {
+		response = ss.responses[len(ss.bodies)-1]
+	}
+	if response == nil {
+		return nil, errors.New("connection reset by peer")
+	}
+	response.Request = request
+	return response, nil
+}
+
+func armResponse(statusCode int, header http.Header, body string) *http.Response {
+	if header == nil {
+		header = http.Header{}
+	}
+	return &http.Response{
+		StatusCode: statusCode,
+		Header:     header,
+		Body:       io.NopCloser(bytes.NewReader([]byte(body))),
+	}
+}
+
+type fakeRetryMetrics struct {
+	retries   map[string]int
+	throttled int
+	quota     map[string]int
+}
+
+func (m *fakeRetryMetrics) IncArmAPIRetryCounter(reason string) {
+	m.retries[reason]++
+}
+
+func (m *fakeRetryMetrics) IncArmAPIThrottledCounter() {
+	m.throttled++
+}
+
+func (m *fakeRetryMetrics) SetArmAPIRemainingQuota(kind string, remaining int) {
+	m.quota[kind] = remaining
+}
+
+var _ = Describe("Az Application Gateway client retrying ARM requests", func() {
+	const succeeded = `{"properties":{"provisioningState":"Succeeded"}}`
+
+	var azClient AzClient
+	var metrics *fakeRetryMetrics
+
+	BeforeEach(func() {
+		azClient = NewAzClient("", "", "", "", "")
+		azClient.SetRetryPolicy(RetryPolicy{MaxRetries: 3, BaseDelay: time.Millisecond, MaxDelay: 10 * time.Millisecond})
+		metrics = &fakeRetryMetrics{retries: map[string]int{}, quota: map[string]int{}}
+		azClient.SetRetryMetrics(metrics)
+	})
+
+	It("retries a throttled request after the delay of Retry-After", func() {
+		sender := &ScriptedSender{responses: []*http.Response{
+			armResponse(http.StatusTooManyRequests, http.Header{"Retry-After": []string{"1"}}, ""),
+			armResponse(http.StatusOK, nil, succeeded),
+		}}
+		azClient.SetSender(sender)
+
+		started := time.Now()
+		_, err := azClient.GetGateway(context.Background())
+		Expect(err).ToNot(HaveOccurred())
+		Expect(time.Since(started)).To(BeNumerically(">=", time.Second))
+		Expect(sender.bodies).To(HaveLen(2))
+		Expect(metrics.throttled).To(Equal(1))
+		Expect(metrics.retries).To(Equal(map[string]int{RetryReasonThrottled: 1}))
+	})
+
+	It("retries an update conflicting with an operation in progress with the same body", func() {
+		sender := &ScriptedSender{responses: []*http.Response{
+			armResponse(http.StatusConflict, nil, `{"error":{"code":"AnotherOperationInProgress","message":"Another operation is in progress"}}`),
+			armResponse(http.StatusServiceUnavailable, nil, ""),
+			nil,
+			armResponse(http.StatusOK, nil, succeeded),
+		}}
+		azClient.SetSender(sender)
+
+		Expect(azClient.UpdateGateway(context.Background(), &n.ApplicationGateway{Etag: to.StringPtr("current")})).To(Succeed())
+		Expect(sender.bodies).To(HaveLen(4))
+		Expect(sender.bodies[0]).ToNot(BeEmpty())
+		Expect(sender.bodies).To(HaveEach(sender.bodies[0]))
+		Expect(metrics.retries).To(Equal(map[string]int{
+			RetryReasonOperationInProgress: 1,
+			RetryReasonServerError:         1,
+			RetryReasonTransportError:      1,
+		}))
+	})
+
+	It("does not retry other conflicts", func() {
+		sender := &ScriptedSender{responses: []*http.Response{
+			armResponse(http.StatusConflict, nil, `{"error":{"code":"InUseSubnetCannotBeDeleted","message":"Subnet is in use"}}`),
+		}}
+		azClient.SetSender(sender)
+
+		err := azClient.UpdateGateway(context.Background(), &n.ApplicationGateway{})
+		Expect(err).To(HaveOccurred())
+		Expect(sender.bodies).To(HaveLen(1))
+	})
+
+	It("gives up after the retries of the policy", func() {
+		sender := &ScriptedSender{responses: []*http.Response{armResponse(http.StatusInternalServerError, nil, "")}}
+		azClient.SetSender(sender)
+
+		_, err := azClient.GetGateway(context.Background())
+		Expect(err).To(HaveOccurred())
+		Expect(sender.bodies).To(HaveLen(4))
+	})
+
+	It("exports the remaining quota reported by ARM", func() {
+		azClient.SetSender(&ScriptedSender{responses: []*http.Response{
+			armResponse(http.StatusOK, http.Header{"X-Ms-Ratelimit-Remaining-Subscription-Reads": []string{"11999"}}, succeeded),
+		}})
+
+		_, err := azClient.GetGateway(context.Background())
+		Expect(err).ToNot(HaveOccurred())
+		Expect(metrics.quota).To(Equal(map[string]int{QuotaKindReads: 11999}))
+	})
+
+	It("backs off exponentially up to the maximum delay", func() {
+		policy := RetryPolicy{BaseDelay: time.Second, MaxDelay: 8 * time.Second}
+		Expect(policy.backoff(0)).To(BeNumerically("~", 750*time.Millisecond, 250*time.Millisecond))
+		Expect(policy.backoff(2)).To(BeNumerically("~", 3*time.Second, time.Second))
+		Expect(policy.backoff(10)).To(BeNumerically("~", 6*time.Second, 2*time.Second))
+	})
+})
@@ -6,6 +6,7 @@
 package azure
 
 import (
+	"context"
 	"time"
 
 	"github.com/Azure/go-autorest/autorest"
@@ -31,7 +32,7 @@ type ApplyRouteTableFunc func(string, string) error
 // GetSubnetFunc is a function type
 type GetSubnetFunc func(string) (n.Subnet, error)
 
-// FakeAzClient is a fake struct for AzClient
+// FakeAzClient is a fake struct for AzClient; Its methods return the error of the context once it is done, without running the functions.
 type FakeAzClient struct {
 	GetGatewayFunc
 	UpdateGatewayFunc
@@ -58,8 +59,23 @@ func (az *FakeAzClient) SetSender(sender autorest.Sender) {
 func (az *FakeAzClient) SetDuration(retryDuration time.Duration) {
 }
 
+// SetTimeouts is an empty function
+func (az *FakeAzClient) SetTimeouts(timeouts Timeouts) {
+}
+
+// SetRetryPolicy is an empty function
+func (az *FakeAzClient) SetRetryPolicy(policy RetryPolicy) {
+}
+
+// SetRetryMetrics is an empty function
+func (az *FakeAzClient) SetRetryMetrics(metrics RetryMetrics) {
+}
+
 // GetGateway runs GetGatewayFunc and return a gateway
-func (az *FakeAzClient) GetGateway() (n.ApplicationGateway, error) {
+func (az *FakeAzClient) GetGateway(ctx context.Context) (n.ApplicationGateway, error) {
+	if err := ctx.Err(); err != nil {
+		return n.ApplicationGateway{}, err
+	}
 	if az.GetGatewayFunc != nil {
 		return az.GetGatewayFunc()
 	}
@@ -67,9 +83,12 @@ func (az *FakeAzClient) GetGateway() (n.ApplicationGateway, error) {
 }
 
 // WaitForGetAccessOnGateway runs GetGatewayFunc until it returns a gateway
-func (az *FakeAzClient) WaitForGetAccessOnGateway(maxRetryCount int) error {
+func (az *FakeAzClient) WaitForGetAccessOnGateway(ctx context.Context, maxRetryCount int) error {
 	if az.GetGatewayFunc != nil {
 		for {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
 			_, err := az.GetGatewayFunc()
 			if err == nil {
 				return nil
@@ -81,7 +100,10 @@ func (az *FakeAzClient) WaitForGetAccessOnGateway(maxRetryCount int) error {
 }
 
 // UpdateGateway runs UpdateGatewayFunc and return a gateway
-func (az *FakeAzClient) UpdateGateway(appGwObj *n.ApplicationGateway) (err error) {
+func (az *FakeAzClient) UpdateGateway(ctx context.Context, appGwObj *n.ApplicationGateway) (err error) {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
 	if az.UpdateGatewayFunc != nil {
 		return az.UpdateGatewayFunc(appGwObj)
 	}
@@ -89,7 +111,10 @@ func (az *FakeAzClient) UpdateGateway(appGwObj *n.ApplicationGateway) (err error
 }
 
 // DeployGatewayWithSubnet runs DeployGatewayFunc
-func (az *FakeAzClient) DeployGatewayWithSubnet(subnetID, skuName string) (err error) {
+func (az *FakeAzClient) DeployGatewayWithSubnet(ctx context.Context, subnetID, skuName string) (err error) {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
 	if az.DeployGatewayFunc != nil {
 		return az.DeployGatewayFunc(subnetID)
 	}
@@ -97,7 +122,10 @@ func (az *FakeAzClient) DeployGatewayWithSubnet(subnetID, skuName string) (err e
 }
 
 // DeployGatewayWithVnet runs DeployGatewayFunc
-func (az *FakeAzClient) DeployGatewayWithVnet(resourceGroupName ResourceGroup, vnetName ResourceName, subnetName ResourceName, subnetPrefix, skuName string) (err error) {
+func (az *FakeAzClient) DeployGatewayWithVnet(ctx context.Context, resourceGroupName ResourceGroup, vnetName ResourceName, subnetName ResourceName, subnetPrefix, skuName string) (err error) {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
 	if az.DeployGatewayFunc != nil {
 		return az.DeployGatewayFunc(subnetPrefix)
 	}
@@ -105,7 +133,10 @@ func (az *FakeAzClient) DeployGatewayWithVnet(resourceGroupName ResourceGroup, v
 }
 
 // GetPublicIP runs GetPublicIPFunc
-func (az *FakeAzClient) GetPublicIP(resourceID string) (n.PublicIPAddress, error) {
+func (az *FakeAzClient) GetPublicIP(ctx context.Context, resourceID string) (n.PublicIPAddress, error) {
+	if err := ctx.Err(); err != nil {
+		return n.PublicIPAddress{}, err
+	}
 	if az.GetPublicIPFunc != nil {
 		return az.GetPublicIPFunc(resourceID)
 	}
@@ -113,14 +144,20 @@ func (az *FakeAzClient) GetPublicIP(resourceID string) (n.PublicIPAddress, error
 }
 
 // ApplyRouteTable runs ApplyRouteTableFunc
-func (az *FakeAzClient) ApplyRouteTable(subnetID string, routeTableID string) error {
+func (az *FakeAzClient) ApplyRouteTable(ctx context.Context, subnetID string, routeTableID string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
 	if az.ApplyRouteTableFunc != nil {
 		return az.ApplyRouteTableFunc(subnetID, routeTableID)
 	}
 	return nil
 }
 
-func (az *FakeAzClient) GetSubnet(subnetID string) (n.Subnet, error) {
+func (az *FakeAzClient) GetSubnet(ctx context.Context, subnetID string) (n.Subnet, error) {
+	if err := ctx.Err(); err != nil {
+		return n.Subnet{}, err
+	}
 	if az.GetSubnetFunc != nil {
 		return az.GetSubnetFunc(subnetID)
 	}
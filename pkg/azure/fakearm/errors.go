@@ -0,0 +1,46 @@
+// -------------------------------------------------------------------------------------------
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License. See License.txt in the project root for license information.
+// --------------------------------------------------------------------------------------------
+
+package fakearm
+
+import (
+	"fmt"
+	"net/http"
+)
+
+// armError is an error response of ARM: Its status code and the code and message of its body.
+type armError struct {
+	statusCode int
+	code       string
+	message    string
+}
+
+func errNotFound(id string) *armError {
+	return &armError{http.StatusNotFound, "ResourceNotFound", fmt.Sprintf("The Resource '%s' was not found.", id)}
+}
+
+func errMethodNotAllowed(req *http.Request) *armError {
+	return &armError{http.StatusMethodNotAllowed, "MethodNotAllowed", fmt.Sprintf("The fake ARM server does not serve %s requests on '%s'.", req.Method, req.URL.Path)}
+}
+
+func errInvalidReference(referenced, referrer string) *armError {
+	return &armError{http.StatusBadRequest, "InvalidResourceReference", fmt.Sprintf(
+		"Resource %s referenced by resource %s was not found. Please make sure that the referenced resource exists, and that both resources are in the same region.",
+		referenced, referrer)}
+}
+
+func errLimitExceeded(count int, what string, limit int) *armError {
+	return &armError{http.StatusBadRequest, "ApplicationGatewayLimitExceeded", fmt.Sprintf(
+		"The Application Gateway has %d %s, which exceeds the limit of %d.", count, what, limit)}
+}
+
+func writeError(w http.ResponseWriter, err *armError) {
+	writeJSON(w, err.statusCode, map[string]interface{}{
+		"error": map[string]string{
+			"code":    err.code,
+			"message": err.message,
+		},
+	})
+}
@@ -0,0 +1,390 @@
+// -------------------------------------------------------------------------------------------
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License. See License.txt in the project root for license information.
+// --------------------------------------------------------------------------------------------
+
+// Package fakearm is a stand-in for the parts of Azure Resource Manager AGIC talks to: Application Gateways, Public IP
+// Addresses, Subnets and Route Tables. It keeps the resources in memory, validates the Application Gateways deployed to it
+// like ARM does, and completes the PUT requests with long running operations, so that AGIC can run without Azure.
+package fakearm
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/uuid"
+	"k8s.io/klog/v2"
+)
+
+const (
+	provisioningStateSucceeded = "Succeeded"
+	provisioningStateUpdating  = "Updating"
+
+	operationStatusInProgress = "InProgress"
+	operationStatusSucceeded  = "Succeeded"
+
+	// defaultOperationalState is the operational state of an Application Gateway loaded without one; AGIC only updates
+	// Application Gateways which are running.
+	defaultOperationalState = "Running"
+)
+
+// resourceType is a type of resource the server serves. Resources of types which cannot be put are loaded with the server.
+type resourceType struct {
+	name     string
+	canPut   bool
+	validate func(s *Server, id string, body []byte) *armError
+	prepare  func(id string, resource, existing map[string]interface{})
+}
+
+// resourceTypes are keyed by the lower case path of the type below the Microsoft.Network provider.
+var resourceTypes = map[string]resourceType{
+	"applicationgateways": {
+		name:     "Microsoft.Network/applicationGateways",
+		canPut:   true,
+		validate: validateApplicationGateway,
+		prepare:  prepareApplicationGateway,
+	},
+	"publicipaddresses": {
+		name: "Microsoft.Network/publicIPAddresses",
+	},
+	"routetables": {
+		name: "Microsoft.Network/routeTables",
+	},
+	"virtualnetworks/subnets": {
+		name:     "Microsoft.Network/virtualNetworks/subnets",
+		canPut:   true,
+		validate: validateSubnet,
+	},
+}
+
+// operation is a long running operation started by a PUT request.
+type operation struct {
+	resourceID string
+	done       time.Time
+}
+
+// Server is the fake ARM server. It implements http.Handler.
+type Server struct {
+	// Limits bound the sub-resources of the Application Gateways.
+	Limits Limits
+
+	// OperationDuration is how long the long running operations of PUT requests take to complete.
+	OperationDuration time.Duration
+
+	lock       sync.Mutex
+	resources  map[string]map[string]interface{}
+	operations map[string]*operation
+	now        func() time.Time
+}
+
+// NewServer returns a Server without resources, enforcing the limits of the v2 SKU.
+func NewServer() *Server {
+	return &Server{
+		Limits:     DefaultLimits(),
+		resources:  make(map[string]map[string]interface{}),
+		operations: make(map[string]*operation),
+		now:        time.Now,
+	}
+}
+
+// Load adds the resources of a JSON array of ARM resources, e.g. the output of 'az resource show' for each of them.
+func (s *Server) Load(reader io.Reader) error {
+	var resources []json.RawMessage
+	if err := json.NewDecoder(reader).Decode(&resources); err != nil {
+		return fmt.Errorf("reading the resources: %w", err)
+	}
+	for _, resource := range resources {
+		if err := s.Add(resource); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Add adds a resource, given as its ARM JSON or as a struct of the SDK, e.g. n.ApplicationGateway. The resource must have an ID.
+func (s *Server) Add(resource interface{}) error {
+	resourceJSON, ok := resource.(json.RawMessage)
+	if !ok {
+		var err error
+		if resourceJSON, err = json.Marshal(resource); err != nil {
+			return err
+		}
+	}
+	var doc map[string]interface{}
+	if err := json.Unmarshal(resourceJSON, &doc); err != nil {
+		return err
+	}
+
+	id, _ := doc["id"].(string)
+	typeKey, ok := parseResourceID(id)
+	if !ok {
+		return fmt.Errorf("resource ID %q is not the ID of an Application Gateway, Public IP Address, Subnet or Route Table", id)
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.store(id, typeKey, doc, nil)
+	properties(doc)["provisioningState"] = provisioningStateSucceeded
+	return nil
+}
+
+// Get unmarshals the resource with the ID into into, and tells whether the resource exists.
+func (s *Server) Get(id string, into interface{}) (bool, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.completeOperations()
+
+	doc, exists := s.resources[strings.ToLower(id)]
+	if !exists {
+		return false, nil
+	}
+	resourceJSON, err := json.Marshal(doc)
+	if err != nil {
+		return true, err
+	}
+	return true, json.Unmarshal(resourceJSON, into)
+}
+
+// ServeHTTP serves the GET and PUT requests of the resources, and the GET requests polling their long running operations.
+func (s *Server) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	klog.V(5).Infof("[fake-arm] %s %s", req.Method, req.URL.Path)
+	if req.URL.Query().Get("api-version") == "" {
+		writeError(w, &armError{http.StatusBadRequest, "MissingApiVersionParameter", "The api-version query parameter (?api-version=) is required for all requests."})
+		return
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.completeOperations()
+
+	path := strings.TrimSuffix(req.URL.Path, "/")
+	if operationID, ok := parseOperationID(path); ok {
+		if req.Method != http.MethodGet {
+			writeError(w, errMethodNotAllowed(req))
+			return
+		}
+		s.getOperation(w, operationID)
+		return
+	}
+
+	typeKey, ok := parseResourceID(path)
+	if !ok {
+		writeError(w, &armError{http.StatusBadRequest, "InvalidResourceType", fmt.Sprintf("The resource type of '%s' is not served by the fake ARM server.", path)})
+		return
+	}
+	switch {
+	case req.Method == http.MethodGet:
+		s.getResource(w, path)
+	case req.Method == http.MethodPut && resourceTypes[typeKey].canPut:
+		s.putResource(w, req, path, typeKey)
+	default:
+		writeError(w, errMethodNotAllowed(req))
+	}
+}
+
+func (s *Server) getResource(w http.ResponseWriter, id string) {
+	doc, exists := s.resources[strings.ToLower(id)]
+	if !exists {
+		writeError(w, errNotFound(id))
+		return
+	}
+	writeJSON(w, http.StatusOK, doc)
+}
+
+func (s *Server) putResource(w http.ResponseWriter, req *http.Request, id, typeKey string) {
+	body, err := io.ReadAll(req.Body)
+	if err != nil {
+		writeError(w, &armError{http.StatusBadRequest, "InvalidRequestContent", err.Error()})
+		return
+	}
+	var doc map[string]interface{}
+	if err := json.Unmarshal(body, &doc); err != nil {
+		writeError(w, &armError{http.StatusBadRequest, "InvalidRequestContent", fmt.Sprintf("The request content was invalid and could not be deserialized: %s", err)})
+		return
+	}
+
+	existing, exists := s.resources[strings.ToLower(id)]
+	if ifMatch := req.Header.Get("If-Match"); ifMatch != "" && ifMatch != "*" && (!exists || existing["etag"] != ifMatch) {
+		writeError(w, &armError{http.StatusPreconditionFailed, "PreconditionFailed", fmt.Sprintf("The condition '%s' in the If-Match header was not met; The resource '%s' was modified since.", ifMatch, id)})
+		return
+	}
+	if _, inProgress := s.operationOf(id); inProgress {
+		writeError(w, &armError{http.StatusConflict, "AnotherOperationInProgress", fmt.Sprintf("Another operation on the resource '%s' is in progress.", id)})
+		return
+	}
+	if validate := resourceTypes[typeKey].validate; validate != nil {
+		if armErr := validate(s, id, body); armErr != nil {
+			writeError(w, armErr)
+			return
+		}
+	}
+
+	if exists {
+		id, _ = existing["id"].(string)
+	}
+	s.store(id, typeKey, doc, existing)
+	properties(doc)["provisioningState"] = provisioningStateUpdating
+
+	operationID := string(uuid.NewUUID())
+	s.operations[operationID] = &operation{
+		resourceID: strings.ToLower(id),
+		done:       s.now().Add(s.OperationDuration),
+	}
+
+	scheme := "http"
+	if req.TLS != nil {
+		scheme = "https"
+	}
+	subscription := strings.Split(strings.TrimPrefix(id, "/"), "/")[1]
+	location, _ := doc["location"].(string)
+	if location == "" {
+		location = "local"
+	}
+	w.Header().Set("Azure-AsyncOperation", fmt.Sprintf("%s://%s/subscriptions/%s/providers/Microsoft.Network/locations/%s/operations/%s?api-version=%s",
+		scheme, req.Host, subscription, location, operationID, req.URL.Query().Get("api-version")))
+	w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(s.OperationDuration.Seconds()))))
+
+	statusCode := http.StatusCreated
+	if exists {
+		statusCode = http.StatusOK
+	}
+	writeJSON(w, statusCode, doc)
+}
+
+func (s *Server) getOperation(w http.ResponseWriter, operationID string) {
+	op, exists := s.operations[operationID]
+	if !exists {
+		writeError(w, errNotFound(operationID))
+		return
+	}
+	status := operationStatusSucceeded
+	if s.now().Before(op.done) {
+		status = operationStatusInProgress
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"name":   operationID,
+		"status": status,
+	})
+}
+
+// operationOf returns the operation in progress on the resource with the ID.
+func (s *Server) operationOf(id string) (*operation, bool) {
+	for _, op := range s.operations {
+		if op.resourceID == strings.ToLower(id) && s.now().Before(op.done) {
+			return op, true
+		}
+	}
+	return nil, false
+}
+
+// completeOperations sets the resources whose operations completed to succeeded.
+func (s *Server) completeOperations() {
+	for _, op := range s.operations {
+		if s.now().Before(op.done) {
+			continue
+		}
+		if doc, exists := s.resources[op.resourceID]; exists && properties(doc)["provisioningState"] == provisioningStateUpdating {
+			properties(doc)["provisioningState"] = provisioningStateSucceeded
+		}
+	}
+}
+
+// store sets the fields ARM manages on the resource, and stores it in place of the existing one.
+func (s *Server) store(id, typeKey string, doc, existing map[string]interface{}) {
+	segments := strings.Split(id, "/")
+	doc["id"] = id
+	doc["name"] = segments[len(segments)-1]
+	doc["type"] = resourceTypes[typeKey].name
+	doc["etag"] = fmt.Sprintf(`W/"%s"`, uuid.NewUUID())
+	if _, ok := doc["location"]; !ok && existing != nil && existing["location"] != nil {
+		doc["location"] = existing["location"]
+	}
+	if prepare := resourceTypes[typeKey].prepare; prepare != nil {
+		prepare(id, doc, existing)
+	}
+	s.resources[strings.ToLower(id)] = doc
+}
+
+// prepareApplicationGateway sets the fields ARM manages on an Application Gateway: Its operational state, which a PUT does not
+// change, and the IDs of its sub-resources, which derive from their names.
+func prepareApplicationGateway(id string, doc, existing map[string]interface{}) {
+	props := properties(doc)
+	if existing != nil && properties(existing)["operationalState"] != nil {
+		props["operationalState"] = properties(existing)["operationalState"]
+	} else if props["operationalState"] == nil {
+		props["operationalState"] = defaultOperationalState
+	}
+
+	for collection, items := range props {
+		items, ok := items.([]interface{})
+		if !ok {
+			continue
+		}
+		for _, item := range items {
+			subResource, ok := item.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			if name, ok := subResource["name"].(string); ok {
+				subResource["id"] = fmt.Sprintf("%s/%s/%s", id, collection, name)
+				subResource["etag"] = doc["etag"]
+			}
+		}
+	}
+}
+
+// properties returns the properties of the resource, adding them when it has none.
+func properties(doc map[string]interface{}) map[string]interface{} {
+	props, ok := doc["properties"].(map[string]interface{})
+	if !ok {
+		props = make(map[string]interface{})
+		doc["properties"] = props
+	}
+	return props
+}
+
+// parseResourceID returns the key of the type of the resource with the ID, e.g.
+// /subscriptions/<sub>/resourceGroups/<rg>/providers/Microsoft.Network/applicationGateways/<name>.
+func parseResourceID(id string) (string, bool) {
+	segments := strings.Split(strings.ToLower(strings.TrimPrefix(id, "/")), "/")
+	if len(segments) < 8 || segments[0] != "subscriptions" || segments[2] != "resourcegroups" || segments[4] != "providers" || segments[5] != "microsoft.network" {
+		return "", false
+	}
+
+	var typeKey string
+	switch len(segments) {
+	case 8:
+		typeKey = segments[6]
+	case 10:
+		typeKey = segments[6] + "/" + segments[8]
+	}
+	if _, ok := resourceTypes[typeKey]; !ok {
+		return "", false
+	}
+	return typeKey, true
+}
+
+// parseOperationID returns the ID of the operation at path, e.g.
+// /subscriptions/<sub>/providers/Microsoft.Network/locations/<location>/operations/<id>.
+func parseOperationID(path string) (string, bool) {
+	segments := strings.Split(strings.TrimPrefix(path, "/"), "/")
+	if len(segments) != 8 || !strings.EqualFold(segments[2], "providers") || !strings.EqualFold(segments[4], "locations") || !strings.EqualFold(segments[6], "operations") {
+		return "", false
+	}
+	return segments[7], true
+}
+
+func writeJSON(w http.ResponseWriter, statusCode int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	if err := json.NewEncoder(w).Encode(body); err != nil {
+		klog.Error("[fake-arm] Error writing the response: ", err)
+	}
+}
@@ -0,0 +1,273 @@
+// -------------------------------------------------------------------------------------------
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License. See License.txt in the project root for license information.
+// --------------------------------------------------------------------------------------------
+
+package fakearm
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"time"
+
+	n "github.com/Azure/azure-sdk-for-go/services/network/mgmt/2021-03-01/network"
+	"github.com/Azure/go-autorest/autorest"
+	"github.com/Azure/go-autorest/autorest/to"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/Azure/application-gateway-kubernetes-ingress/pkg/azure"
+	"github.com/Azure/application-gateway-kubernetes-ingress/pkg/controllererrors"
+)
+
+const (
+	resourceGroupID = "/subscriptions/sub/resourceGroups/rg/providers/Microsoft.Network"
+	appGwID         = resourceGroupID + "/applicationGateways/appgw"
+	publicIPID      = resourceGroupID + "/publicIPAddresses/pip"
+	subnetID        = resourceGroupID + "/virtualNetworks/vnet/subnets/appgw-subnet"
+	routeTableID    = resourceGroupID + "/routeTables/aks-routes"
+)
+
+var _ = Describe("Fake ARM server", func() {
+	var server *Server
+	var httpServer *httptest.Server
+	var azClient azure.AzClient
+
+	newAppGw := func() *n.ApplicationGateway {
+		return &n.ApplicationGateway{
+			ID:       to.StringPtr(appGwID),
+			Location: to.StringPtr("westeurope"),
+			ApplicationGatewayPropertiesFormat: &n.ApplicationGatewayPropertiesFormat{
+				GatewayIPConfigurations: &[]n.ApplicationGatewayIPConfiguration{{
+					Name: to.StringPtr("ipconfig"),
+					ApplicationGatewayIPConfigurationPropertiesFormat: &n.ApplicationGatewayIPConfigurationPropertiesFormat{
+						Subnet: &n.SubResource{ID: to.StringPtr(subnetID)},
+					},
+				}},
+				FrontendIPConfigurations: &[]n.ApplicationGatewayFrontendIPConfiguration{{
+					Name: to.StringPtr("public"),
+					ApplicationGatewayFrontendIPConfigurationPropertiesFormat: &n.ApplicationGatewayFrontendIPConfigurationPropertiesFormat{
+						PublicIPAddress: &n.SubResource{ID: to.StringPtr(publicIPID)},
+					},
+				}},
+				FrontendPorts: &[]n.ApplicationGatewayFrontendPort{{
+					Name: to.StringPtr("port-80"),
+					ApplicationGatewayFrontendPortPropertiesFormat: &n.ApplicationGatewayFrontendPortPropertiesFormat{
+						Port: to.Int32Ptr(80),
+					},
+				}},
+				BackendAddressPools: &[]n.ApplicationGatewayBackendAddressPool{{
+					Name: to.StringPtr("pool"),
+					ApplicationGatewayBackendAddressPoolPropertiesFormat: &n.ApplicationGatewayBackendAddressPoolPropertiesFormat{
+						BackendAddresses: &[]n.ApplicationGatewayBackendAddress{{IPAddress: to.StringPtr("10.0.0.4")}},
+					},
+				}},
+				BackendHTTPSettingsCollection: &[]n.ApplicationGatewayBackendHTTPSettings{{
+					Name: to.StringPtr("settings"),
+					ApplicationGatewayBackendHTTPSettingsPropertiesFormat: &n.ApplicationGatewayBackendHTTPSettingsPropertiesFormat{
+						Port:     to.Int32Ptr(8080),
+						Protocol: n.ApplicationGatewayProtocolHTTP,
+					},
+				}},
+				HTTPListeners: &[]n.ApplicationGatewayHTTPListener{{
+					Name: to.StringPtr("listener"),
+					ApplicationGatewayHTTPListenerPropertiesFormat: &n.ApplicationGatewayHTTPListenerPropertiesFormat{
+						FrontendIPConfiguration: &n.SubResource{ID: to.StringPtr(appGwID + "/frontendIPConfigurations/public")},
+						FrontendPort:            &n.SubResource{ID: to.StringPtr(appGwID + "/frontendPorts/port-80")},
+						Protocol:                n.ApplicationGatewayProtocolHTTP,
+					},
+				}},
+				RequestRoutingRules: &[]n.ApplicationGatewayRequestRoutingRule{{
+					Name: to.StringPtr("rule"),
+					ApplicationGatewayRequestRoutingRulePropertiesFormat: &n.ApplicationGatewayRequestRoutingRulePropertiesFormat{
+						RuleType:            n.ApplicationGatewayRequestRoutingRuleTypeBasic,
+						HTTPListener:        &n.SubResource{ID: to.StringPtr(appGwID + "/httpListeners/listener")},
+						BackendAddressPool:  &n.SubResource{ID: to.StringPtr(appGwID + "/backendAddressPools/pool")},
+						BackendHTTPSettings: &n.SubResource{ID: to.StringPtr(appGwID + "/backendHttpSettingsCollection/settings")},
+					},
+				}},
+			},
+		}
+	}
+
+	BeforeEach(func() {
+		server = NewServer()
+		Expect(server.Add(n.PublicIPAddress{
+			ID:                              to.StringPtr(publicIPID),
+			PublicIPAddressPropertiesFormat: &n.PublicIPAddressPropertiesFormat{IPAddress: to.StringPtr("20.0.0.1")},
+		})).To(Succeed())
+		Expect(server.Add(n.Subnet{
+			ID:                     to.StringPtr(subnetID),
+			SubnetPropertiesFormat: &n.SubnetPropertiesFormat{AddressPrefix: to.StringPtr("10.1.0.0/24")},
+		})).To(Succeed())
+		Expect(server.Add(n.RouteTable{ID: to.StringPtr(routeTableID), RouteTablePropertiesFormat: &n.RouteTablePropertiesFormat{}})).To(Succeed())
+		Expect(server.Add(newAppGw())).To(Succeed())
+		httpServer = httptest.NewServer(server)
+
+		azClient = azure.NewAzClientWithBaseURI(httpServer.URL, "sub", "rg", "appgw", "test", "")
+		azClient.SetAuthorizer(autorest.NullAuthorizer{})
+		azClient.SetRetryPolicy(azure.RetryPolicy{MaxRetries: 20, BaseDelay: 50 * time.Millisecond, MaxDelay: 100 * time.Millisecond})
+	})
+
+	AfterEach(func() {
+		httpServer.Close()
+	})
+
+	Context("serving Application Gateways", func() {
+		It("serves the Application Gateway with the fields ARM manages", func() {
+			Expect(azClient.WaitForGetAccessOnGateway(context.Background(), 1)).To(Succeed())
+			appGw, err := azClient.GetGateway(context.Background())
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(*appGw.Name).To(Equal("appgw"))
+			Expect(*appGw.Etag).To(HavePrefix(`W/"`))
+			Expect(appGw.OperationalState).To(Equal(n.ApplicationGatewayOperationalStateRunning))
+			Expect(appGw.ProvisioningState).To(Equal(n.ProvisioningStateSucceeded))
+			Expect(*(*appGw.BackendAddressPools)[0].ID).To(Equal(appGwID + "/backendAddressPools/pool"))
+		})
+
+		It("deploys an Application Gateway with a long running operation", func() {
+			server.OperationDuration = time.Second
+			appGw, err := azClient.GetGateway(context.Background())
+			Expect(err).ToNot(HaveOccurred())
+
+			(*appGw.BackendAddressPools)[0].BackendAddresses = &[]n.ApplicationGatewayBackendAddress{{IPAddress: to.StringPtr("10.0.0.5")}}
+			started := time.Now()
+			Expect(azClient.UpdateGateway(context.Background(), &appGw)).To(Succeed())
+			Expect(time.Since(started)).To(BeNumerically(">=", time.Second))
+
+			deployed, err := azClient.GetGateway(context.Background())
+			Expect(err).ToNot(HaveOccurred())
+			Expect(*(*(*deployed.BackendAddressPools)[0].BackendAddresses)[0].IPAddress).To(Equal("10.0.0.5"))
+			Expect(deployed.ProvisioningState).To(Equal(n.ProvisioningStateSucceeded))
+			Expect(*deployed.Etag).ToNot(Equal(*appGw.Etag))
+		})
+
+		It("rejects a deployment with an outdated ETag", func() {
+			appGw, err := azClient.GetGateway(context.Background())
+			Expect(err).ToNot(HaveOccurred())
+			Expect(azClient.UpdateGateway(context.Background(), &appGw)).To(Succeed())
+
+			err = azClient.UpdateGateway(context.Background(), &appGw)
+			Expect(controllererrors.IsErrorCode(err, controllererrors.ErrorApplicationGatewayConcurrentUpdate)).To(BeTrue())
+		})
+
+		It("retries a deployment while another one is in progress", func() {
+			server.OperationDuration = 300 * time.Millisecond
+			appGw, err := azClient.GetGateway(context.Background())
+			Expect(err).ToNot(HaveOccurred())
+			appGw.Etag = nil
+
+			done := make(chan error)
+			go func() { done <- azClient.UpdateGateway(context.Background(), &appGw) }()
+			Eventually(func() bool {
+				_, inProgress := server.operationOf(appGwID)
+				return inProgress
+			}).Should(BeTrue())
+
+			Expect(azClient.UpdateGateway(context.Background(), &appGw)).To(Succeed())
+			Expect(<-done).To(Succeed())
+		})
+
+		It("rejects references to sub-resources which do not exist", func() {
+			appGw := newAppGw()
+			(*appGw.RequestRoutingRules)[0].BackendAddressPool.ID = to.StringPtr(appGwID + "/backendAddressPools/missing")
+
+			err := azClient.UpdateGateway(context.Background(), appGw)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("InvalidResourceReference"))
+
+			var stored n.ApplicationGateway
+			Expect(server.Get(appGwID, &stored)).To(BeTrue())
+			Expect(*(*stored.RequestRoutingRules)[0].BackendAddressPool.ID).To(Equal(appGwID + "/backendAddressPools/pool"))
+		})
+
+		It("rejects references to public IP addresses which do not exist", func() {
+			appGw := newAppGw()
+			(*appGw.FrontendIPConfigurations)[0].PublicIPAddress.ID = to.StringPtr(resourceGroupID + "/publicIPAddresses/missing")
+
+			err := azClient.UpdateGateway(context.Background(), appGw)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("InvalidResourceReference"))
+		})
+
+		It("rejects sub-resources with the same name", func() {
+			appGw := newAppGw()
+			*appGw.FrontendPorts = append(*appGw.FrontendPorts, (*appGw.FrontendPorts)[0])
+
+			err := azClient.UpdateGateway(context.Background(), appGw)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("DuplicateResourceName"))
+		})
+
+		It("rejects an Application Gateway exceeding the limits", func() {
+			server.Limits.BackendAddressPools = 1
+			appGw := newAppGw()
+			*appGw.BackendAddressPools = append(*appGw.BackendAddressPools, n.ApplicationGatewayBackendAddressPool{
+				Name: to.StringPtr("another-pool"),
+			})
+
+			err := azClient.UpdateGateway(context.Background(), appGw)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("ApplicationGatewayLimitExceeded"))
+		})
+	})
+
+	Context("serving the network of the Application Gateway", func() {
+		It("serves the public IP addresses", func() {
+			ip, err := azClient.GetPublicIP(context.Background(), publicIPID)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(*ip.IPAddress).To(Equal("20.0.0.1"))
+
+			_, err = azClient.GetPublicIP(context.Background(), resourceGroupID+"/publicIPAddresses/missing")
+			Expect(err).To(HaveOccurred())
+		})
+
+		It("associates the subnet with the route table", func() {
+			Expect(azClient.ApplyRouteTable(context.Background(), subnetID, routeTableID)).To(Succeed())
+
+			subnet, err := azClient.GetSubnet(context.Background(), subnetID)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(*subnet.RouteTable.ID).To(Equal(routeTableID))
+			Expect(*subnet.AddressPrefix).To(Equal("10.1.0.0/24"))
+		})
+
+		It("skips route tables which do not exist", func() {
+			Expect(azClient.ApplyRouteTable(context.Background(), subnetID, resourceGroupID+"/routeTables/missing")).To(Succeed())
+
+			subnet, err := azClient.GetSubnet(context.Background(), subnetID)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(subnet.RouteTable).To(BeNil())
+		})
+	})
+
+	Context("serving HTTP", func() {
+		It("requires the api-version", func() {
+			resp, err := http.Get(httpServer.URL + appGwID)
+			Expect(err).ToNot(HaveOccurred())
+			defer resp.Body.Close()
+			Expect(resp.StatusCode).To(Equal(http.StatusBadRequest))
+		})
+
+		It("does not serve the resource types AGIC does not use", func() {
+			resp, err := http.Get(fmt.Sprintf("%s%s/loadBalancers/lb?api-version=2021-03-01", httpServer.URL, resourceGroupID))
+			Expect(err).ToNot(HaveOccurred())
+			defer resp.Body.Close()
+			Expect(resp.StatusCode).To(Equal(http.StatusBadRequest))
+		})
+
+		It("loads the resources of a JSON array", func() {
+			resources := fmt.Sprintf(`[{"id": "%s/publicIPAddresses/other", "properties": {"ipAddress": "20.0.0.2"}}]`, resourceGroupID)
+			Expect(server.Load(strings.NewReader(resources))).To(Succeed())
+
+			ip, err := azClient.GetPublicIP(context.Background(), resourceGroupID+"/publicIPAddresses/other")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(*ip.IPAddress).To(Equal("20.0.0.2"))
+
+			Expect(server.Load(strings.NewReader(`[{"id": "/subscriptions/sub"}]`))).ToNot(Succeed())
+		})
+	})
+})
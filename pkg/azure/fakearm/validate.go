@@ -0,0 +1,329 @@
+// -------------------------------------------------------------------------------------------
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License. See License.txt in the project root for license information.
+// --------------------------------------------------------------------------------------------
+
+package fakearm
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	n "github.com/Azure/azure-sdk-for-go/services/network/mgmt/2021-03-01/network"
+)
+
+// Collections of the sub-resources of an Application Gateway, as named in its JSON.
+const (
+	authenticationCertificates = "authenticationCertificates"
+	backendAddressPools        = "backendAddressPools"
+	backendHTTPSettings        = "backendHttpSettingsCollection"
+	frontendIPConfigurations   = "frontendIPConfigurations"
+	frontendPorts              = "frontendPorts"
+	httpListeners              = "httpListeners"
+	probes                     = "probes"
+	redirectConfigurations     = "redirectConfigurations"
+	requestRoutingRules        = "requestRoutingRules"
+	rewriteRuleSets            = "rewriteRuleSets"
+	sslCertificates            = "sslCertificates"
+	trustedRootCertificates    = "trustedRootCertificates"
+	urlPathMaps                = "urlPathMaps"
+)
+
+// Limits are the most sub-resources an Application Gateway may have. Zero is no limit.
+type Limits struct {
+	FrontendPorts           int
+	BackendAddressPools     int
+	BackendAddresses        int
+	HTTPListeners           int
+	RequestRoutingRules     int
+	BackendHTTPSettings     int
+	Probes                  int
+	SslCertificates         int
+	TrustedRootCertificates int
+	URLPathMaps             int
+	PathRules               int
+	RedirectConfigurations  int
+	RewriteRuleSets         int
+}
+
+// DefaultLimits returns the limits of the v2 SKU; BackendAddresses is per pool and PathRules per URL path map.
+func DefaultLimits() Limits {
+	return Limits{
+		FrontendPorts:           100,
+		BackendAddressPools:     100,
+		BackendAddresses:        1200,
+		HTTPListeners:           200,
+		RequestRoutingRules:     400,
+		BackendHTTPSettings:     100,
+		Probes:                  100,
+		SslCertificates:         100,
+		TrustedRootCertificates: 100,
+		URLPathMaps:             400,
+		PathRules:               100,
+		RedirectConfigurations:  100,
+		RewriteRuleSets:         400,
+	}
+}
+
+// reference is a reference of a sub-resource, the referrer, to a resource.
+type reference struct {
+	collection string
+	target     *n.SubResource
+	referrer   string
+}
+
+// validateApplicationGateway validates an Application Gateway about to be put: Its sub-resources have unique names, its
+// references resolve and it is within the limits.
+func validateApplicationGateway(s *Server, id string, body []byte) *armError {
+	var appGw n.ApplicationGateway
+	if err := json.Unmarshal(body, &appGw); err != nil {
+		return &armError{http.StatusBadRequest, "InvalidRequestContent", fmt.Sprintf("The request content was invalid and could not be deserialized: %s", err)}
+	}
+	if appGw.ApplicationGatewayPropertiesFormat == nil {
+		return &armError{http.StatusBadRequest, "InvalidRequestFormat", "The properties of the Application Gateway are required."}
+	}
+
+	subResourceIDs, armErr := collectSubResourceIDs(id, body)
+	if armErr != nil {
+		return armErr
+	}
+	if armErr := s.Limits.check(subResourceIDs, appGw.ApplicationGatewayPropertiesFormat); armErr != nil {
+		return armErr
+	}
+
+	for _, ref := range references(id, appGw.ApplicationGatewayPropertiesFormat) {
+		if ref.target == nil || ref.target.ID == nil {
+			continue
+		}
+		if _, exists := subResourceIDs[ref.collection][strings.ToLower(*ref.target.ID)]; !exists {
+			return errInvalidReference(*ref.target.ID, ref.referrer)
+		}
+	}
+
+	// The subnets and public IP addresses are resources of their own.
+	for _, ref := range externalReferences(id, appGw.ApplicationGatewayPropertiesFormat) {
+		if ref.target == nil || ref.target.ID == nil {
+			continue
+		}
+		if _, exists := s.resources[strings.ToLower(*ref.target.ID)]; !exists {
+			return errInvalidReference(*ref.target.ID, ref.referrer)
+		}
+	}
+
+	return nil
+}
+
+// validateSubnet validates a subnet about to be put: Its route table exists.
+func validateSubnet(s *Server, id string, body []byte) *armError {
+	var subnet n.Subnet
+	if err := json.Unmarshal(body, &subnet); err != nil {
+		return &armError{http.StatusBadRequest, "InvalidRequestContent", fmt.Sprintf("The request content was invalid and could not be deserialized: %s", err)}
+	}
+	if subnet.SubnetPropertiesFormat == nil || subnet.RouteTable == nil || subnet.RouteTable.ID == nil {
+		return nil
+	}
+	if _, exists := s.resources[strings.ToLower(*subnet.RouteTable.ID)]; !exists {
+		return errInvalidReference(*subnet.RouteTable.ID, id)
+	}
+	return nil
+}
+
+// collectSubResourceIDs returns the lower case IDs of the sub-resources of the Application Gateway by collection. The IDs
+// derive from the names, which must be unique within a collection.
+func collectSubResourceIDs(id string, body []byte) (map[string]map[string]interface{}, *armError) {
+	var appGw struct {
+		Properties map[string]json.RawMessage `json:"properties"`
+	}
+	if err := json.Unmarshal(body, &appGw); err != nil {
+		return nil, &armError{http.StatusBadRequest, "InvalidRequestContent", err.Error()}
+	}
+
+	ids := make(map[string]map[string]interface{})
+	for collection, items := range appGw.Properties {
+		var subResources []struct {
+			Name *string `json:"name"`
+		}
+		if json.Unmarshal(items, &subResources) != nil {
+			// Not a collection of sub-resources, e.g. the SKU.
+			continue
+		}
+		ids[collection] = make(map[string]interface{})
+		for _, subResource := range subResources {
+			if subResource.Name == nil || *subResource.Name == "" {
+				return nil, &armError{http.StatusBadRequest, "InvalidRequestFormat", fmt.Sprintf("A sub-resource in %s of %s has no name.", collection, id)}
+			}
+			subID := strings.ToLower(subResourceID(id, collection, subResource.Name))
+			if _, exists := ids[collection][subID]; exists {
+				return nil, &armError{http.StatusBadRequest, "DuplicateResourceName", fmt.Sprintf("%s of %s contains more than one sub-resource named %s.", collection, id, *subResource.Name)}
+			}
+			ids[collection][subID] = nil
+		}
+	}
+	return ids, nil
+}
+
+// references returns the references between the sub-resources of an Application Gateway.
+func references(id string, props *n.ApplicationGatewayPropertiesFormat) []reference {
+	var refs []reference
+	if props.HTTPListeners != nil {
+		for _, listener := range *props.HTTPListeners {
+			if listener.ApplicationGatewayHTTPListenerPropertiesFormat == nil {
+				continue
+			}
+			referrer := subResourceID(id, httpListeners, listener.Name)
+			refs = append(refs,
+				reference{frontendIPConfigurations, listener.FrontendIPConfiguration, referrer},
+				reference{frontendPorts, listener.FrontendPort, referrer},
+				reference{sslCertificates, listener.SslCertificate, referrer})
+		}
+	}
+	if props.RequestRoutingRules != nil {
+		for _, rule := range *props.RequestRoutingRules {
+			if rule.ApplicationGatewayRequestRoutingRulePropertiesFormat == nil {
+				continue
+			}
+			referrer := subResourceID(id, requestRoutingRules, rule.Name)
+			refs = append(refs,
+				reference{httpListeners, rule.HTTPListener, referrer},
+				reference{backendAddressPools, rule.BackendAddressPool, referrer},
+				reference{backendHTTPSettings, rule.BackendHTTPSettings, referrer},
+				reference{urlPathMaps, rule.URLPathMap, referrer},
+				reference{rewriteRuleSets, rule.RewriteRuleSet, referrer},
+				reference{redirectConfigurations, rule.RedirectConfiguration, referrer})
+		}
+	}
+	if props.URLPathMaps != nil {
+		for _, pathMap := range *props.URLPathMaps {
+			if pathMap.ApplicationGatewayURLPathMapPropertiesFormat == nil {
+				continue
+			}
+			referrer := subResourceID(id, urlPathMaps, pathMap.Name)
+			refs = append(refs,
+				reference{backendAddressPools, pathMap.DefaultBackendAddressPool, referrer},
+				reference{backendHTTPSettings, pathMap.DefaultBackendHTTPSettings, referrer},
+				reference{rewriteRuleSets, pathMap.DefaultRewriteRuleSet, referrer},
+				reference{redirectConfigurations, pathMap.DefaultRedirectConfiguration, referrer})
+			if pathMap.PathRules == nil {
+				continue
+			}
+			for _, pathRule := range *pathMap.PathRules {
+				if pathRule.ApplicationGatewayPathRulePropertiesFormat == nil {
+					continue
+				}
+				pathRuleReferrer := fmt.Sprintf("%s/pathRules/%s", referrer, nameOf(pathRule.Name))
+				refs = append(refs,
+					reference{backendAddressPools, pathRule.BackendAddressPool, pathRuleReferrer},
+					reference{backendHTTPSettings, pathRule.BackendHTTPSettings, pathRuleReferrer},
+					reference{rewriteRuleSets, pathRule.RewriteRuleSet, pathRuleReferrer},
+					reference{redirectConfigurations, pathRule.RedirectConfiguration, pathRuleReferrer})
+			}
+		}
+	}
+	if props.BackendHTTPSettingsCollection != nil {
+		for _, settings := range *props.BackendHTTPSettingsCollection {
+			if settings.ApplicationGatewayBackendHTTPSettingsPropertiesFormat == nil {
+				continue
+			}
+			referrer := subResourceID(id, backendHTTPSettings, settings.Name)
+			refs = append(refs, reference{probes, settings.Probe, referrer})
+			if settings.TrustedRootCertificates != nil {
+				for idx := range *settings.TrustedRootCertificates {
+					refs = append(refs, reference{trustedRootCertificates, &(*settings.TrustedRootCertificates)[idx], referrer})
+				}
+			}
+			if settings.AuthenticationCertificates != nil {
+				for idx := range *settings.AuthenticationCertificates {
+					refs = append(refs, reference{authenticationCertificates, &(*settings.AuthenticationCertificates)[idx], referrer})
+				}
+			}
+		}
+	}
+	if props.RedirectConfigurations != nil {
+		for _, redirect := range *props.RedirectConfigurations {
+			if redirect.ApplicationGatewayRedirectConfigurationPropertiesFormat == nil {
+				continue
+			}
+			refs = append(refs, reference{httpListeners, redirect.TargetListener, subResourceID(id, redirectConfigurations, redirect.Name)})
+		}
+	}
+	return refs
+}
+
+// externalReferences returns the references of the IP configurations of an Application Gateway to subnets and public IP
+// addresses, which are resources of their own.
+func externalReferences(id string, props *n.ApplicationGatewayPropertiesFormat) []reference {
+	var refs []reference
+	if props.GatewayIPConfigurations != nil {
+		for _, config := range *props.GatewayIPConfigurations {
+			if config.ApplicationGatewayIPConfigurationPropertiesFormat != nil {
+				refs = append(refs, reference{"", config.Subnet, subResourceID(id, "gatewayIPConfigurations", config.Name)})
+			}
+		}
+	}
+	if props.FrontendIPConfigurations != nil {
+		for _, config := range *props.FrontendIPConfigurations {
+			if config.ApplicationGatewayFrontendIPConfigurationPropertiesFormat != nil {
+				referrer := subResourceID(id, frontendIPConfigurations, config.Name)
+				refs = append(refs, reference{"", config.Subnet, referrer}, reference{"", config.PublicIPAddress, referrer})
+			}
+		}
+	}
+	return refs
+}
+
+// check returns an error when the Application Gateway, whose sub-resources have the IDs, exceeds a limit.
+func (l Limits) check(subResourceIDs map[string]map[string]interface{}, props *n.ApplicationGatewayPropertiesFormat) *armError {
+	limits := map[string]int{
+		frontendPorts:           l.FrontendPorts,
+		backendAddressPools:     l.BackendAddressPools,
+		httpListeners:           l.HTTPListeners,
+		requestRoutingRules:     l.RequestRoutingRules,
+		backendHTTPSettings:     l.BackendHTTPSettings,
+		probes:                  l.Probes,
+		sslCertificates:         l.SslCertificates,
+		trustedRootCertificates: l.TrustedRootCertificates,
+		urlPathMaps:             l.URLPathMaps,
+		redirectConfigurations:  l.RedirectConfigurations,
+		rewriteRuleSets:         l.RewriteRuleSets,
+	}
+	for collection, limit := range limits {
+		if count := len(subResourceIDs[collection]); limit > 0 && count > limit {
+			return errLimitExceeded(count, collection, limit)
+		}
+	}
+
+	if props.BackendAddressPools != nil {
+		for _, pool := range *props.BackendAddressPools {
+			if pool.ApplicationGatewayBackendAddressPoolPropertiesFormat == nil || pool.BackendAddresses == nil {
+				continue
+			}
+			if count := len(*pool.BackendAddresses); l.BackendAddresses > 0 && count > l.BackendAddresses {
+				return errLimitExceeded(count, "backend addresses in "+nameOf(pool.Name), l.BackendAddresses)
+			}
+		}
+	}
+	if props.URLPathMaps != nil {
+		for _, pathMap := range *props.URLPathMaps {
+			if pathMap.ApplicationGatewayURLPathMapPropertiesFormat == nil || pathMap.PathRules == nil {
+				continue
+			}
+			if count := len(*pathMap.PathRules); l.PathRules > 0 && count > l.PathRules {
+				return errLimitExceeded(count, "path rules in "+nameOf(pathMap.Name), l.PathRules)
+			}
+		}
+	}
+	return nil
+}
+
+func subResourceID(id, collection string, name *string) string {
+	return fmt.Sprintf("%s/%s/%s", id, collection, nameOf(name))
+}
+
+func nameOf(name *string) string {
+	if name == nil {
+		return ""
+	}
+	return *name
+}
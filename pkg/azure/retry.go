@@ -0,0 +1,233 @@
+// -------------------------------------------------------------------------------------------
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License. See License.txt in the project root for license information.
+// --------------------------------------------------------------------------------------------
+
+package azure
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/Azure/go-autorest/autorest"
+	"k8s.io/klog/v2"
+)
+
+const (
+	// RetryReasonThrottled is the reason of retrying a request ARM throttled with 429 Too Many Requests.
+	RetryReasonThrottled = "throttled"
+
+	// RetryReasonOperationInProgress is the reason of retrying a request ARM rejected as another operation on the resource is in progress.
+	RetryReasonOperationInProgress = "operation_in_progress"
+
+	// RetryReasonServerError is the reason of retrying a request which failed with a transient 5xx status code.
+	RetryReasonServerError = "server_error"
+
+	// RetryReasonTransportError is the reason of retrying a request which did not get a response.
+	RetryReasonTransportError = "transport_error"
+
+	// QuotaKindReads is the kind of the remaining quota of ARM reads of the subscription.
+	QuotaKindReads = "subscription_reads"
+
+	// QuotaKindWrites is the kind of the remaining quota of ARM writes of the subscription.
+	QuotaKindWrites = "subscription_writes"
+)
+
+// operationInProgressCodes are the error codes with which ARM rejects a request with 409 Conflict while another operation on the
+// resource is in progress. The request succeeds once that operation completed.
+var operationInProgressCodes = map[string]interface{}{
+	"AnotherOperationInProgress":            nil,
+	"ApplicationGatewayOperationInProgress": nil,
+}
+
+// retriableServerErrors are the 5xx status codes of transient ARM failures.
+var retriableServerErrors = map[int]interface{}{
+	http.StatusInternalServerError: nil,
+	http.StatusBadGateway:          nil,
+	http.StatusServiceUnavailable:  nil,
+	http.StatusGatewayTimeout:      nil,
+}
+
+// quotaHeaders map the headers carrying the remaining ARM quota to the kind of quota.
+var quotaHeaders = map[string]string{
+	"x-ms-ratelimit-remaining-subscription-reads":  QuotaKindReads,
+	"x-ms-ratelimit-remaining-subscription-writes": QuotaKindWrites,
+}
+
+// RetryPolicy is how AzClient retries ARM requests which were throttled, conflicted with an operation in progress or failed transiently.
+// The retries of a request are bounded by its timeout, see Timeouts.
+type RetryPolicy struct {
+	// MaxRetries is how many times a request is retried.
+	MaxRetries int
+
+	// BaseDelay is the delay before the first retry; It doubles with every retry.
+	BaseDelay time.Duration
+
+	// MaxDelay bounds the delay between two attempts, unless ARM asks for a longer one with Retry-After.
+	MaxDelay time.Duration
+}
+
+// DefaultRetryPolicy returns the RetryPolicy of AzClient.
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxRetries: 5,
+		BaseDelay:  time.Second,
+		MaxDelay:   30 * time.Second,
+	}
+}
+
+// RetryMetrics receives the retries of ARM requests and the remaining ARM quota. It is implemented by metricstore.MetricStore.
+type RetryMetrics interface {
+	IncArmAPIRetryCounter(reason string)
+	IncArmAPIThrottledCounter()
+	SetArmAPIRemainingQuota(kind string, remaining int)
+}
+
+type noopRetryMetrics struct{}
+
+func (noopRetryMetrics) IncArmAPIRetryCounter(string) {}
+
+func (noopRetryMetrics) IncArmAPIThrottledCounter() {}
+
+func (noopRetryMetrics) SetArmAPIRemainingQuota(string, int) {}
+
+// backoff returns the jittered delay before the retry following attempt, counting from 0.
+func (p RetryPolicy) backoff(attempt int) time.Duration {
+	delay := p.MaxDelay
+	if attempt < 32 && p.BaseDelay<<uint(attempt) < p.MaxDelay {
+		delay = p.BaseDelay << uint(attempt)
+	}
+	if delay <= 0 {
+		return 0
+	}
+	// Half of the delay is random, so that the retries of several requests throttled together are spread out.
+	return delay/2 + time.Duration(rand.Int63n(int64(delay/2)+1))
+}
+
+// sendWithRetries is the SendDecorator of the ARM clients of AzClient. It replaces the retries of the SDK, which retries
+// every 5xx, ignores conflicting operations in progress, and retries PUT requests only to register the resource provider.
+func (az *azClient) sendWithRetries() autorest.SendDecorator {
+	return func(s autorest.Sender) autorest.Sender {
+		return autorest.SenderFunc(func(req *http.Request) (resp *http.Response, err error) {
+			rr := autorest.NewRetriableRequest(req)
+			for attempt := 0; ; attempt++ {
+				if err = rr.Prepare(); err != nil {
+					return resp, err
+				}
+				resp, err = s.Do(rr.Request())
+				az.recordRemainingQuota(resp)
+
+				reason := retryReason(resp, err)
+				if reason == RetryReasonThrottled {
+					az.retryMetrics.IncArmAPIThrottledCounter()
+				}
+				if reason == "" || attempt >= az.retryPolicy.MaxRetries || req.Context().Err() != nil {
+					return resp, err
+				}
+
+				delay := az.retryPolicy.backoff(attempt)
+				if retryAfter, ok := parseRetryAfter(resp); ok {
+					delay = retryAfter
+				}
+				klog.V(3).Infof("Retrying %s %s in %v (%s, attempt %d of %d)", req.Method, req.URL.Path, delay, reason, attempt+1, az.retryPolicy.MaxRetries)
+				az.retryMetrics.IncArmAPIRetryCounter(reason)
+
+				if resp != nil && resp.Body != nil {
+					_, _ = io.Copy(io.Discard, resp.Body)
+					resp.Body.Close()
+				}
+				timer := time.NewTimer(delay)
+				select {
+				case <-req.Context().Done():
+					timer.Stop()
+					return resp, req.Context().Err()
+				case <-timer.C:
+				}
+			}
+		})
+	}
+}
+
+// retryReason tells why the request which got resp and err is to be retried; It is empty when the request is not to be retried.
+func retryReason(resp *http.Response, err error) string {
+	if resp == nil {
+		if err != nil {
+			return RetryReasonTransportError
+		}
+		return ""
+	}
+	switch {
+	case resp.StatusCode == http.StatusTooManyRequests:
+		return RetryReasonThrottled
+	case resp.StatusCode == http.StatusConflict:
+		if _, ok := operationInProgressCodes[errorCode(resp)]; ok {
+			return RetryReasonOperationInProgress
+		}
+	default:
+		if _, ok := retriableServerErrors[resp.StatusCode]; ok {
+			return RetryReasonServerError
+		}
+	}
+	return ""
+}
+
+// errorCode returns the code of the ARM error in the body of resp. The body is kept for the responder of the request.
+func errorCode(resp *http.Response) string {
+	if resp.Body == nil {
+		return ""
+	}
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	if err != nil {
+		return ""
+	}
+
+	var armError struct {
+		Error struct {
+			Code string `json:"code"`
+		} `json:"error"`
+	}
+	if json.Unmarshal(body, &armError) != nil {
+		return ""
+	}
+	return armError.Error.Code
+}
+
+// parseRetryAfter returns the delay ARM asked for with the Retry-After header of resp, in seconds or as an HTTP date.
+func parseRetryAfter(resp *http.Response) (time.Duration, bool) {
+	if resp == nil {
+		return 0, false
+	}
+	retryAfter := resp.Header.Get("Retry-After")
+	if retryAfter == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(retryAfter); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if date, err := http.ParseTime(retryAfter); err == nil {
+		if delay := time.Until(date); delay > 0 {
+			return delay, true
+		}
+		return 0, true
+	}
+	return 0, false
+}
+
+// recordRemainingQuota exports the remaining ARM quota of the subscription ARM reports in the headers of resp.
+func (az *azClient) recordRemainingQuota(resp *http.Response) {
+	if resp == nil {
+		return
+	}
+	for header, kind := range quotaHeaders {
+		if remaining, err := strconv.Atoi(resp.Header.Get(header)); err == nil {
+			az.retryMetrics.SetArmAPIRemainingQuota(kind, remaining)
+		}
+	}
+}
@@ -0,0 +1,59 @@
+// -------------------------------------------------------------------------------------------
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License. See License.txt in the project root for license information.
+// --------------------------------------------------------------------------------------------
+
+package azure
+
+import (
+	"context"
+	"time"
+)
+
+// Timeouts bounds how long AzClient waits on ARM. They apply on top of the context passed to every AzClient method.
+type Timeouts struct {
+	// Get bounds a single GET request.
+	Get time.Duration
+
+	// Put bounds the request starting a long running operation, e.g. the PUT of App Gateway.
+	Put time.Duration
+
+	// Polling bounds how long AzClient waits for a long running operation to complete.
+	Polling time.Duration
+}
+
+// DefaultTimeouts returns the Timeouts used for the values which are not configured.
+func DefaultTimeouts() Timeouts {
+	return Timeouts{
+		Get:     time.Minute,
+		Put:     2 * time.Minute,
+		Polling: 60 * time.Minute,
+	}
+}
+
+// NewTimeouts parses the GET, PUT and polling timeouts; Empty or invalid values keep their default.
+func NewTimeouts(get, put, polling string) Timeouts {
+	timeouts := DefaultTimeouts()
+	parseTimeout(get, &timeouts.Get)
+	parseTimeout(put, &timeouts.Put)
+	parseTimeout(polling, &timeouts.Polling)
+	return timeouts
+}
+
+func parseTimeout(value string, timeout *time.Duration) {
+	if parsed, err := time.ParseDuration(value); err == nil && parsed > 0 {
+		*timeout = parsed
+	}
+}
+
+func (az *azClient) getContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	return context.WithTimeout(ctx, az.timeouts.Get)
+}
+
+func (az *azClient) putContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	return context.WithTimeout(ctx, az.timeouts.Put)
+}
+
+func (az *azClient) pollingContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	return context.WithTimeout(ctx, az.timeouts.Polling)
+}
@@ -48,7 +48,7 @@ func NewReconciler(armClient azure.AzClient,
 func (r *Reconciler) Reconcile(ctx context.Context) error {
 	subnetID := *(*r.appGw.GatewayIPConfigurations)[0].Subnet.ID
 
-	if err := r.reconcileKubenetCniIfNeeded(r.cpConfig, subnetID); err != nil {
+	if err := r.reconcileKubenetCniIfNeeded(ctx, r.cpConfig, subnetID); err != nil {
 		return errors.Wrap(err, "failed to reconcile kubenet CNI")
 	}
 
@@ -1,11 +1,13 @@
 package cni
 
 import (
+	"context"
+
 	"github.com/Azure/application-gateway-kubernetes-ingress/pkg/azure"
 	"github.com/pkg/errors"
 )
 
-func (r *Reconciler) reconcileKubenetCniIfNeeded(cpConfig *azure.CloudProviderConfig, subnetID string) error {
+func (r *Reconciler) reconcileKubenetCniIfNeeded(ctx context.Context, cpConfig *azure.CloudProviderConfig, subnetID string) error {
 	if r.reconciledKubenetCNI {
 		return nil
 	}
@@ -15,7 +17,7 @@ func (r *Reconciler) reconcileKubenetCniIfNeeded(cpConfig *azure.CloudProviderCo
 	}
 
 	routeTableID := azure.RouteTableID(azure.SubscriptionID(cpConfig.SubscriptionID), azure.ResourceGroup(cpConfig.RouteTableResourceGroup), azure.ResourceName(cpConfig.RouteTableName))
-	if err := r.armClient.ApplyRouteTable(subnetID, routeTableID); err != nil {
+	if err := r.armClient.ApplyRouteTable(ctx, subnetID, routeTableID); err != nil {
 		return errors.Wrapf(err, "Unable to associate Application Gateway subnet '%s' with route table '%s' due to error (this is relevant for AKS clusters using 'Kubenet' network plugin)",
 			subnetID,
 			routeTableID)
@@ -50,7 +50,7 @@ func (r *Reconciler) reconcileOverlayCniIfNeeded(ctx context.Context, subnetID s
 	}
 
 	klog.Infof("Cluster is using overlay CNI, using subnetID %q for application gateway", subnetID)
-	subnet, err := r.armClient.GetSubnet(subnetID)
+	subnet, err := r.armClient.GetSubnet(ctx, subnetID)
 	if err != nil {
 		return errors.Wrap(err, "failed to get subnet")
 	}
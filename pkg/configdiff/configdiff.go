@@ -0,0 +1,380 @@
+// -------------------------------------------------------------------------------------------
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License. See License.txt in the project root for license information.
+// --------------------------------------------------------------------------------------------
+
+package configdiff
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	n "github.com/Azure/azure-sdk-for-go/services/network/mgmt/2021-03-01/network"
+)
+
+// ResourceKind is the name of an App Gateway sub-resource collection as it appears in the ARM JSON.
+type ResourceKind string
+
+const (
+	// FrontendPorts is the frontend ports collection.
+	FrontendPorts ResourceKind = "frontendPorts"
+
+	// HTTPListeners is the HTTP listeners collection.
+	HTTPListeners ResourceKind = "httpListeners"
+
+	// RequestRoutingRules is the request routing rules collection.
+	RequestRoutingRules ResourceKind = "requestRoutingRules"
+
+	// URLPathMaps is the URL path maps collection.
+	URLPathMaps ResourceKind = "urlPathMaps"
+
+	// BackendAddressPools is the backend address pools collection.
+	BackendAddressPools ResourceKind = "backendAddressPools"
+
+	// BackendHTTPSettings is the backend HTTP settings collection.
+	BackendHTTPSettings ResourceKind = "backendHttpSettingsCollection"
+
+	// Probes is the health probes collection.
+	Probes ResourceKind = "probes"
+
+	// SslCertificates is the listener certificates collection.
+	SslCertificates ResourceKind = "sslCertificates"
+
+	// TrustedRootCertificates is the backend trusted root certificates collection.
+	TrustedRootCertificates ResourceKind = "trustedRootCertificates"
+
+	// RedirectConfigurations is the redirect configurations collection.
+	RedirectConfigurations ResourceKind = "redirectConfigurations"
+
+	// RewriteRuleSets is the rewrite rule sets collection.
+	RewriteRuleSets ResourceKind = "rewriteRuleSets"
+)
+
+// Kinds lists the sub-resource collections compared, in the order in which they are reported.
+var Kinds = []ResourceKind{
+	FrontendPorts,
+	HTTPListeners,
+	RequestRoutingRules,
+	URLPathMaps,
+	BackendAddressPools,
+	BackendHTTPSettings,
+	Probes,
+	SslCertificates,
+	TrustedRootCertificates,
+	RedirectConfigurations,
+	RewriteRuleSets,
+}
+
+// Action is what happens to a sub-resource when the generated config is applied.
+type Action string
+
+const (
+	// Added sub-resources exist only in the generated config.
+	Added Action = "Added"
+
+	// Removed sub-resources exist only in the existing config.
+	Removed Action = "Removed"
+
+	// Changed sub-resources exist in both configs with different properties.
+	Changed Action = "Changed"
+)
+
+// Read-only keys ARM fills in, which never appear in a generated config.
+var readOnlyKeys = []string{
+	"etag",
+	"provisioningState",
+	"type",
+}
+
+// Properties ARM manages on its own, which AGIC does not generate.
+var ignoredProperties = map[ResourceKind][]string{
+	// Back references to the rules using the sub-resource.
+	RedirectConfigurations: {"requestRoutingRules", "urlPathMaps", "pathRules"},
+	BackendAddressPools:    {"backendIPConfigurations"},
+
+	// ARM never returns certificate material, so certificates are compared by name and Key Vault secret only.
+	SslCertificates: {"data", "password", "publicCertData"},
+}
+
+// Snapshot is a normalized copy of the sub-resources of an App Gateway, keyed by kind and name.
+// Take a snapshot of the existing config before running the ConfigBuilder, as it mutates the gateway in place.
+type Snapshot map[ResourceKind]map[string]interface{}
+
+// Change is a single sub-resource which differs between two configs.
+type Change struct {
+	Kind   ResourceKind `json:"kind"`
+	Name   string       `json:"name"`
+	Action Action       `json:"action"`
+
+	// Fields lists the properties which differ; Only set for changed sub-resources.
+	Fields []FieldChange `json:"fields,omitempty"`
+}
+
+// FieldChange is a single property of a changed sub-resource, addressed by its path within the sub-resource properties.
+// Old is nil when the property was added and New is nil when it was removed.
+type FieldChange struct {
+	Path string      `json:"path"`
+	Old  interface{} `json:"old,omitempty"`
+	New  interface{} `json:"new,omitempty"`
+}
+
+// Counts summarizes the changes to a single kind of sub-resource.
+type Counts struct {
+	Added     int `json:"added"`
+	Removed   int `json:"removed"`
+	Changed   int `json:"changed"`
+	Unchanged int `json:"unchanged"`
+}
+
+// Diff is the structured difference between an existing and a generated App Gateway config.
+type Diff struct {
+	Changes []Change                `json:"changes"`
+	Summary map[ResourceKind]Counts `json:"summary"`
+}
+
+// NewSnapshot normalizes the sub-resources of the given App Gateway for comparison.
+func NewSnapshot(appGw *n.ApplicationGateway) (Snapshot, error) {
+	snapshot := make(Snapshot)
+	if appGw == nil {
+		return snapshot, nil
+	}
+
+	jsonConfig, err := appGw.MarshalJSON()
+	if err != nil {
+		return nil, err
+	}
+
+	var config map[string]interface{}
+	if err := json.Unmarshal(jsonConfig, &config); err != nil {
+		return nil, err
+	}
+
+	properties, _ := config["properties"].(map[string]interface{})
+	for _, kind := range Kinds {
+		resources := make(map[string]interface{})
+		items, _ := properties[string(kind)].([]interface{})
+		for _, item := range items {
+			resource, ok := item.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			name, _ := resource["name"].(string)
+
+			// The ID is derived from the name, which is the key already.
+			delete(resource, "id")
+			if resourceProperties, ok := resource["properties"].(map[string]interface{}); ok {
+				for _, key := range ignoredProperties[kind] {
+					delete(resourceProperties, key)
+				}
+			}
+			resources[name] = normalize(resource)
+		}
+		snapshot[kind] = resources
+	}
+
+	return snapshot, nil
+}
+
+// Compare matches the sub-resources of both snapshots by name and reports the ones which differ.
+func Compare(existing, generated Snapshot) *Diff {
+	diff := &Diff{
+		Changes: []Change{},
+		Summary: make(map[ResourceKind]Counts),
+	}
+
+	for _, kind := range Kinds {
+		var counts Counts
+		for _, name := range sortedNames(existing[kind], generated[kind]) {
+			before, inExisting := existing[kind][name]
+			after, inGenerated := generated[kind][name]
+
+			change := Change{
+				Kind: kind,
+				Name: name,
+			}
+			switch {
+			case !inExisting:
+				change.Action = Added
+				counts.Added++
+			case !inGenerated:
+				change.Action = Removed
+				counts.Removed++
+			case !reflect.DeepEqual(before, after):
+				change.Action = Changed
+				change.Fields = compareFields("", propertiesOf(before), propertiesOf(after), nil)
+				counts.Changed++
+			default:
+				counts.Unchanged++
+				continue
+			}
+
+			diff.Changes = append(diff.Changes, change)
+		}
+		diff.Summary[kind] = counts
+	}
+
+	return diff
+}
+
+// HasChanges tells whether applying the generated config would change anything.
+func (d *Diff) HasChanges() bool {
+	return len(d.Changes) > 0
+}
+
+// Totals sums up the counts of all kinds of sub-resources.
+func (d *Diff) Totals() Counts {
+	var totals Counts
+	for _, counts := range d.Summary {
+		totals.Added += counts.Added
+		totals.Removed += counts.Removed
+		totals.Changed += counts.Changed
+		totals.Unchanged += counts.Unchanged
+	}
+	return totals
+}
+
+// String summarizes the diff in a single line, listing only the kinds of sub-resources with changes.
+func (d *Diff) String() string {
+	totals := d.Totals()
+	summary := fmt.Sprintf("%d added, %d changed, %d removed", totals.Added, totals.Changed, totals.Removed)
+
+	var kinds []string
+	for _, kind := range Kinds {
+		counts := d.Summary[kind]
+		if counts.Added+counts.Changed+counts.Removed == 0 {
+			continue
+		}
+		kinds = append(kinds, fmt.Sprintf("%s: +%d ~%d -%d", kind, counts.Added, counts.Changed, counts.Removed))
+	}
+	if len(kinds) == 0 {
+		return summary
+	}
+	return fmt.Sprintf("%s (%s)", summary, strings.Join(kinds, ", "))
+}
+
+// String formats the field change as "path: old -> new".
+func (f FieldChange) String() string {
+	return fmt.Sprintf("%s: %s -> %s", f.Path, toJSON(f.Old), toJSON(f.New))
+}
+
+// compareFields walks both values in parallel and appends the leaves which differ to fields.
+func compareFields(path string, before, after interface{}, fields []FieldChange) []FieldChange {
+	if reflect.DeepEqual(before, after) {
+		return fields
+	}
+
+	beforeMap, beforeIsMap := before.(map[string]interface{})
+	afterMap, afterIsMap := after.(map[string]interface{})
+	if beforeIsMap && afterIsMap {
+		for _, key := range sortedNames(beforeMap, afterMap) {
+			fields = compareFields(joinPath(path, key), beforeMap[key], afterMap[key], fields)
+		}
+		return fields
+	}
+
+	// Lists are compared item by item as long as only the items changed; Otherwise the whole list is reported.
+	beforeSlice, beforeIsSlice := before.([]interface{})
+	afterSlice, afterIsSlice := after.([]interface{})
+	if beforeIsSlice && afterIsSlice && len(beforeSlice) == len(afterSlice) {
+		for idx := range beforeSlice {
+			fields = compareFields(fmt.Sprintf("%s[%d]", path, idx), beforeSlice[idx], afterSlice[idx], fields)
+		}
+		return fields
+	}
+
+	return append(fields, FieldChange{
+		Path: path,
+		Old:  before,
+		New:  after,
+	})
+}
+
+func propertiesOf(resource interface{}) interface{} {
+	if resourceMap, ok := resource.(map[string]interface{}); ok {
+		return resourceMap["properties"]
+	}
+	return nil
+}
+
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+func toJSON(value interface{}) string {
+	if value == nil {
+		return "<none>"
+	}
+	valueJSON, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprintf("%v", value)
+	}
+	return string(valueJSON)
+}
+
+// normalize drops read-only keys and empty values, which ARM and the ConfigBuilder represent differently.
+func normalize(value interface{}) interface{} {
+	switch typed := value.(type) {
+	case map[string]interface{}:
+		normalized := make(map[string]interface{})
+		for key, v := range typed {
+			if isReadOnly(key) {
+				continue
+			}
+			if v = normalize(v); !isEmpty(v) {
+				normalized[key] = v
+			}
+		}
+		return normalized
+	case []interface{}:
+		normalized := make([]interface{}, 0, len(typed))
+		for _, v := range typed {
+			normalized = append(normalized, normalize(v))
+		}
+		return normalized
+	default:
+		return value
+	}
+}
+
+func isReadOnly(key string) bool {
+	for _, readOnlyKey := range readOnlyKeys {
+		if strings.EqualFold(key, readOnlyKey) {
+			return true
+		}
+	}
+	return false
+}
+
+func isEmpty(value interface{}) bool {
+	switch typed := value.(type) {
+	case nil:
+		return true
+	case map[string]interface{}:
+		return len(typed) == 0
+	case []interface{}:
+		return len(typed) == 0
+	default:
+		return false
+	}
+}
+
+func sortedNames(maps ...map[string]interface{}) []string {
+	seen := make(map[string]interface{})
+	var names []string
+	for _, byName := range maps {
+		for name := range byName {
+			if _, exists := seen[name]; !exists {
+				seen[name] = nil
+				names = append(names, name)
+			}
+		}
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,117 @@
+// -------------------------------------------------------------------------------------------
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License. See License.txt in the project root for license information.
+// --------------------------------------------------------------------------------------------
+
+package configdiff
+
+import (
+	n "github.com/Azure/azure-sdk-for-go/services/network/mgmt/2021-03-01/network"
+	"github.com/Azure/go-autorest/autorest/to"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("test configdiff", func() {
+	newProbe := func(name, path string) n.ApplicationGatewayProbe {
+		return n.ApplicationGatewayProbe{
+			Name: to.StringPtr(name),
+			ID:   to.StringPtr("/subscriptions/xxx/probes/" + name),
+			ApplicationGatewayProbePropertiesFormat: &n.ApplicationGatewayProbePropertiesFormat{
+				Protocol: n.ApplicationGatewayProtocolHTTP,
+				Path:     to.StringPtr(path),
+			},
+		}
+	}
+
+	newAppGw := func(probes ...n.ApplicationGatewayProbe) *n.ApplicationGateway {
+		return &n.ApplicationGateway{
+			ApplicationGatewayPropertiesFormat: &n.ApplicationGatewayPropertiesFormat{
+				Probes: &probes,
+			},
+		}
+	}
+
+	Context("ensure Compare works as expected", func() {
+		It("should report added, removed and changed sub-resources", func() {
+			existing, err := NewSnapshot(newAppGw(newProbe("unchanged", "/"), newProbe("changed", "/"), newProbe("removed", "/")))
+			Expect(err).ToNot(HaveOccurred())
+			generated, err := NewSnapshot(newAppGw(newProbe("unchanged", "/"), newProbe("changed", "/healthz"), newProbe("added", "/")))
+			Expect(err).ToNot(HaveOccurred())
+
+			diff := Compare(existing, generated)
+			Expect(diff.HasChanges()).To(BeTrue())
+			Expect(diff.Changes).To(Equal([]Change{
+				{Kind: Probes, Name: "added", Action: Added},
+				{Kind: Probes, Name: "changed", Action: Changed, Fields: []FieldChange{{Path: "path", Old: "/", New: "/healthz"}}},
+				{Kind: Probes, Name: "removed", Action: Removed},
+			}))
+			Expect(diff.Summary[Probes]).To(Equal(Counts{Added: 1, Removed: 1, Changed: 1, Unchanged: 1}))
+			Expect(diff.Summary[HTTPListeners]).To(Equal(Counts{}))
+			Expect(diff.String()).To(Equal("1 added, 1 changed, 1 removed (probes: +1 ~1 -1)"))
+		})
+
+		It("should report the changed fields", func() {
+			before := newProbe("probe", "/")
+			after := newProbe("probe", "/healthz")
+			after.Host = to.StringPtr("www.contoso.com")
+
+			existing, err := NewSnapshot(newAppGw(before))
+			Expect(err).ToNot(HaveOccurred())
+			generated, err := NewSnapshot(newAppGw(after))
+			Expect(err).ToNot(HaveOccurred())
+
+			diff := Compare(existing, generated)
+			Expect(diff.Changes).To(HaveLen(1))
+			Expect(diff.Changes[0].Fields).To(Equal([]FieldChange{
+				{Path: "host", New: "www.contoso.com"},
+				{Path: "path", Old: "/", New: "/healthz"},
+			}))
+			Expect(diff.Changes[0].Fields[0].String()).To(Equal(`host: <none> -> "www.contoso.com"`))
+			Expect(diff.Changes[0].Fields[1].String()).To(Equal(`path: "/" -> "/healthz"`))
+		})
+
+		It("should ignore read-only properties filled in by ARM", func() {
+			fromARM := newProbe("probe", "/")
+			fromARM.Etag = to.StringPtr("W/\"d3aa9ec8\"")
+			fromARM.Type = to.StringPtr("Microsoft.Network/applicationGateways/probes")
+			fromARM.ProvisioningState = n.ProvisioningStateSucceeded
+			fromARM.Match = &n.ApplicationGatewayProbeHealthResponseMatch{StatusCodes: &[]string{}}
+
+			existing, err := NewSnapshot(newAppGw(fromARM))
+			Expect(err).ToNot(HaveOccurred())
+			generated, err := NewSnapshot(newAppGw(newProbe("probe", "/")))
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(Compare(existing, generated).HasChanges()).To(BeFalse())
+		})
+
+		It("should compare certificates without their data", func() {
+			existing, err := NewSnapshot(&n.ApplicationGateway{
+				ApplicationGatewayPropertiesFormat: &n.ApplicationGatewayPropertiesFormat{
+					SslCertificates: &[]n.ApplicationGatewaySslCertificate{{
+						Name: to.StringPtr("cert"),
+						ApplicationGatewaySslCertificatePropertiesFormat: &n.ApplicationGatewaySslCertificatePropertiesFormat{
+							PublicCertData: to.StringPtr("public"),
+						},
+					}},
+				},
+			})
+			Expect(err).ToNot(HaveOccurred())
+			generated, err := NewSnapshot(&n.ApplicationGateway{
+				ApplicationGatewayPropertiesFormat: &n.ApplicationGatewayPropertiesFormat{
+					SslCertificates: &[]n.ApplicationGatewaySslCertificate{{
+						Name: to.StringPtr("cert"),
+						ApplicationGatewaySslCertificatePropertiesFormat: &n.ApplicationGatewaySslCertificatePropertiesFormat{
+							Data:     to.StringPtr("secret"),
+							Password: to.StringPtr("msazure"),
+						},
+					}},
+				},
+			})
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(Compare(existing, generated).HasChanges()).To(BeFalse())
+		})
+	})
+})
@@ -0,0 +1,301 @@
+// -------------------------------------------------------------------------------------------
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License. See License.txt in the project root for license information.
+// --------------------------------------------------------------------------------------------
+
+package confighistory
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	corev1 "k8s.io/client-go/kubernetes/typed/core/v1"
+)
+
+const (
+	// revisionsKey holds the metadata of the stored revisions, oldest first.
+	revisionsKey = "revisions"
+
+	// pausedKey holds the Pause while reconciliation is paused.
+	pausedKey = "paused"
+
+	// revisionKeyPrefix prefixes the binary data keys holding the gzipped configs.
+	revisionKeyPrefix = "revision-"
+)
+
+// Revision is a configuration AGIC successfully applied to App Gateway.
+type Revision struct {
+	Number    int       `json:"revision"`
+	AppliedAt time.Time `json:"appliedAt"`
+
+	// Summary tells what the revision changed, e.g. the summary of the config diff.
+	Summary string `json:"summary,omitempty"`
+
+	// Config is the sanitized App Gateway config; It is only set when a single revision is requested.
+	Config json.RawMessage `json:"config,omitempty"`
+}
+
+// Pause records that an operator rolled App Gateway back and AGIC must not reconcile it until resumed.
+type Pause struct {
+	Revision int       `json:"revision"`
+	Reason   string    `json:"reason,omitempty"`
+	PausedAt time.Time `json:"pausedAt"`
+}
+
+// Store keeps the most recent revisions and the pause state in a ConfigMap, so that they survive restarts and leader changes.
+type Store struct {
+	configMaps corev1.ConfigMapInterface
+	name       string
+	size       int
+
+	mutex sync.Mutex
+}
+
+// NewStore creates a Store keeping up to size revisions in the named ConfigMap.
+func NewStore(configMaps corev1.ConfigMapInterface, name string, size int) *Store {
+	return &Store{
+		configMaps: configMaps,
+		name:       name,
+		size:       size,
+	}
+}
+
+// Record stores the config as a new revision, evicting the oldest revisions beyond the size of the store.
+// Periodic reconciles re-apply the same config, so a config identical to the newest revision is not stored again;
+// In that case Record returns the newest revision and false.
+func (s *Store) Record(config []byte, summary string) (*Revision, bool, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	configMap, err := s.getOrCreate()
+	if err != nil {
+		return nil, false, err
+	}
+
+	revisions, err := readRevisions(configMap)
+	if err != nil {
+		return nil, false, err
+	}
+
+	if len(revisions) > 0 {
+		newest := revisions[len(revisions)-1]
+		if newestConfig, err := readConfig(configMap, newest.Number); err == nil && bytes.Equal(newestConfig, config) {
+			return &newest, false, nil
+		}
+	}
+
+	revision := Revision{
+		Number:    1,
+		AppliedAt: time.Now().UTC(),
+		Summary:   summary,
+	}
+	if len(revisions) > 0 {
+		revision.Number = revisions[len(revisions)-1].Number + 1
+	}
+
+	compressed, err := compress(config)
+	if err != nil {
+		return nil, false, err
+	}
+	if configMap.BinaryData == nil {
+		configMap.BinaryData = make(map[string][]byte)
+	}
+	configMap.BinaryData[revisionKey(revision.Number)] = compressed
+	revisions = append(revisions, revision)
+
+	for len(revisions) > s.size {
+		delete(configMap.BinaryData, revisionKey(revisions[0].Number))
+		revisions = revisions[1:]
+	}
+	if err := writeRevisions(configMap, revisions); err != nil {
+		return nil, false, err
+	}
+
+	if _, err := s.configMaps.Update(context.TODO(), configMap, metav1.UpdateOptions{}); err != nil {
+		return nil, false, err
+	}
+	return &revision, true, nil
+}
+
+// List returns the stored revisions without their configs, oldest first.
+func (s *Store) List() ([]Revision, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	configMap, err := s.get()
+	if err != nil || configMap == nil {
+		return []Revision{}, err
+	}
+	return readRevisions(configMap)
+}
+
+// Get returns the revision with the given number including its config; nil when it is not stored.
+func (s *Store) Get(number int) (*Revision, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	configMap, err := s.get()
+	if err != nil || configMap == nil {
+		return nil, err
+	}
+
+	revisions, err := readRevisions(configMap)
+	if err != nil {
+		return nil, err
+	}
+	for _, revision := range revisions {
+		if revision.Number != number {
+			continue
+		}
+		if revision.Config, err = readConfig(configMap, number); err != nil {
+			return nil, err
+		}
+		return &revision, nil
+	}
+	return nil, nil
+}
+
+// Pause persists that reconciliation is paused.
+func (s *Store) Pause(pause Pause) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	configMap, err := s.getOrCreate()
+	if err != nil {
+		return err
+	}
+
+	pauseJSON, err := json.Marshal(pause)
+	if err != nil {
+		return err
+	}
+	if configMap.Data == nil {
+		configMap.Data = make(map[string]string)
+	}
+	configMap.Data[pausedKey] = string(pauseJSON)
+
+	_, err = s.configMaps.Update(context.TODO(), configMap, metav1.UpdateOptions{})
+	return err
+}
+
+// Resume persists that reconciliation is no longer paused.
+func (s *Store) Resume() error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	configMap, err := s.get()
+	if err != nil || configMap == nil {
+		return err
+	}
+	if _, exists := configMap.Data[pausedKey]; !exists {
+		return nil
+	}
+	delete(configMap.Data, pausedKey)
+
+	_, err = s.configMaps.Update(context.TODO(), configMap, metav1.UpdateOptions{})
+	return err
+}
+
+// Paused returns the persisted Pause; nil when reconciliation is not paused.
+func (s *Store) Paused() (*Pause, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	configMap, err := s.get()
+	if err != nil || configMap == nil {
+		return nil, err
+	}
+
+	pauseJSON, exists := configMap.Data[pausedKey]
+	if !exists {
+		return nil, nil
+	}
+	var pause Pause
+	if err := json.Unmarshal([]byte(pauseJSON), &pause); err != nil {
+		return nil, err
+	}
+	return &pause, nil
+}
+
+// get returns the ConfigMap; nil when it does not exist yet.
+func (s *Store) get() (*v1.ConfigMap, error) {
+	configMap, err := s.configMaps.Get(context.TODO(), s.name, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return nil, nil
+	}
+	return configMap, err
+}
+
+func (s *Store) getOrCreate() (*v1.ConfigMap, error) {
+	configMap, err := s.get()
+	if err != nil || configMap != nil {
+		return configMap, err
+	}
+
+	return s.configMaps.Create(context.TODO(), &v1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: s.name,
+		},
+	}, metav1.CreateOptions{})
+}
+
+func readRevisions(configMap *v1.ConfigMap) ([]Revision, error) {
+	revisions := []Revision{}
+	revisionsJSON, exists := configMap.Data[revisionsKey]
+	if !exists {
+		return revisions, nil
+	}
+	err := json.Unmarshal([]byte(revisionsJSON), &revisions)
+	return revisions, err
+}
+
+func writeRevisions(configMap *v1.ConfigMap, revisions []Revision) error {
+	revisionsJSON, err := json.Marshal(revisions)
+	if err != nil {
+		return err
+	}
+	if configMap.Data == nil {
+		configMap.Data = make(map[string]string)
+	}
+	configMap.Data[revisionsKey] = string(revisionsJSON)
+	return nil
+}
+
+func readConfig(configMap *v1.ConfigMap, number int) ([]byte, error) {
+	compressed, exists := configMap.BinaryData[revisionKey(number)]
+	if !exists {
+		return nil, fmt.Errorf("config of revision %d is missing from ConfigMap %s/%s", number, configMap.Namespace, configMap.Name)
+	}
+	reader, err := gzip.NewReader(bytes.NewReader(compressed))
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+	return io.ReadAll(reader)
+}
+
+// compress gzips the config; App Gateway configs compress well, which keeps several revisions within the 1MiB limit of a ConfigMap.
+func compress(config []byte) ([]byte, error) {
+	var buffer bytes.Buffer
+	writer := gzip.NewWriter(&buffer)
+	if _, err := writer.Write(config); err != nil {
+		return nil, err
+	}
+	if err := writer.Close(); err != nil {
+		return nil, err
+	}
+	return buffer.Bytes(), nil
+}
+
+func revisionKey(number int) string {
+	return fmt.Sprintf("%s%d", revisionKeyPrefix, number)
+}
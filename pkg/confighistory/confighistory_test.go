@@ -0,0 +1,81 @@
+// -------------------------------------------------------------------------------------------
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License. See License.txt in the project root for license information.
+// --------------------------------------------------------------------------------------------
+
+package confighistory
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	testclient "k8s.io/client-go/kubernetes/fake"
+)
+
+var _ = Describe("test config history", func() {
+	var store *Store
+
+	BeforeEach(func() {
+		store = NewStore(testclient.NewSimpleClientset().CoreV1().ConfigMaps("agic"), "history", 2)
+	})
+
+	Context("ensure Record works as expected", func() {
+		It("should keep the most recent revisions", func() {
+			for idx, config := range []string{`{"name":"one"}`, `{"name":"two"}`, `{"name":"three"}`} {
+				revision, recorded, err := store.Record([]byte(config), "summary")
+				Expect(err).ToNot(HaveOccurred())
+				Expect(recorded).To(BeTrue())
+				Expect(revision.Number).To(Equal(idx + 1))
+			}
+
+			revisions, err := store.List()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(revisions).To(HaveLen(2))
+			Expect(revisions[0].Number).To(Equal(2))
+			Expect(revisions[1].Number).To(Equal(3))
+
+			revision, err := store.Get(1)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(revision).To(BeNil())
+
+			revision, err = store.Get(2)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(string(revision.Config)).To(Equal(`{"name":"two"}`))
+
+			configMap, err := store.configMaps.Get(context.TODO(), "history", metav1.GetOptions{})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(configMap.BinaryData).To(HaveLen(2))
+		})
+
+		It("should not record the newest config again", func() {
+			_, _, err := store.Record([]byte(`{"name":"one"}`), "")
+			Expect(err).ToNot(HaveOccurred())
+
+			revision, recorded, err := store.Record([]byte(`{"name":"one"}`), "")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(recorded).To(BeFalse())
+			Expect(revision.Number).To(Equal(1))
+		})
+	})
+
+	Context("ensure Pause works as expected", func() {
+		It("should persist the pause until resumed", func() {
+			pause, err := store.Paused()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(pause).To(BeNil())
+
+			Expect(store.Pause(Pause{Revision: 3, Reason: "bad deploy"})).To(Succeed())
+			pause, err = store.Paused()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(pause.Revision).To(Equal(3))
+			Expect(pause.Reason).To(Equal("bad deploy"))
+
+			Expect(store.Resume()).To(Succeed())
+			pause, err = store.Paused()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(pause).To(BeNil())
+		})
+	})
+})
@@ -0,0 +1,384 @@
+// -------------------------------------------------------------------------------------------
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License. See License.txt in the project root for license information.
+// --------------------------------------------------------------------------------------------
+
+package controller
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	n "github.com/Azure/azure-sdk-for-go/services/network/mgmt/2021-03-01/network"
+	"github.com/Azure/go-autorest/autorest"
+	autorestazure "github.com/Azure/go-autorest/autorest/azure"
+	v1 "k8s.io/api/core/v1"
+	networking "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/klog/v2"
+
+	"github.com/Azure/application-gateway-kubernetes-ingress/pkg/appgw"
+	"github.com/Azure/application-gateway-kubernetes-ingress/pkg/configdiff"
+	"github.com/Azure/application-gateway-kubernetes-ingress/pkg/controllererrors"
+	"github.com/Azure/application-gateway-kubernetes-ingress/pkg/events"
+	"github.com/Azure/application-gateway-kubernetes-ingress/pkg/utils"
+)
+
+// excludedIngress is an Ingress whose config failed to provision on App Gateway.
+// It stays excluded until it is changed, which bumps its generation.
+type excludedIngress struct {
+	uid        types.UID
+	generation int64
+	message    string
+}
+
+// deployAppGw updates App Gateway. With auto rollback enabled, a deployment which leaves App Gateway
+// in the Failed provisioning state is an error as well.
+func (c AppGwIngressController) deployAppGw(appGw *n.ApplicationGateway, autoRollback bool) error {
+	ctx := c.armContext()
+	if err := c.azClient.UpdateGateway(ctx, appGw); err != nil {
+		// The deployment ran to completion and failed; Polling errors and timeouts are wrapped, not returned as is.
+		if serviceErr, ok := err.(*autorestazure.ServiceError); ok && autoRollback && ctx.Err() == nil {
+			return controllererrors.NewErrorWithInnerErrorf(
+				controllererrors.ErrorAppGatewayProvisioningFailed,
+				serviceErr,
+				"the deployment of App Gateway %s failed", c.appGwIdentifier.AppGwName,
+			)
+		}
+		return err
+	}
+	if !autoRollback {
+		return nil
+	}
+
+	deployed, err := c.azClient.GetGateway(ctx)
+	c.MetricStore.IncArmAPICallCounter()
+	if err != nil {
+		// The deployment itself succeeded; Do not roll back because of a failed read.
+		klog.Warningf("Could not check the provisioning state of App Gateway %s after the deployment: %s", c.appGwIdentifier.AppGwName, err)
+		return nil
+	}
+	if deployed.ApplicationGatewayPropertiesFormat != nil && deployed.ProvisioningState == n.ProvisioningStateFailed {
+		return controllererrors.NewErrorf(
+			controllererrors.ErrorAppGatewayProvisioningFailed,
+			"App Gateway %s is in the %s provisioning state after the deployment", c.appGwIdentifier.AppGwName, deployed.ProvisioningState,
+		)
+	}
+	return nil
+}
+
+// isFailedDeployment tells whether App Gateway failed to provision the deployed config or ARM rejected it as invalid.
+// Only such a deployment is rolled back: Any other error, e.g. throttling, an operation in progress, a transport error,
+// a polling timeout or AGIC shutting down, says nothing about the config and is retried with the next event.
+func isFailedDeployment(err error) bool {
+	if controllererrors.IsErrorCode(err, controllererrors.ErrorAppGatewayProvisioningFailed) {
+		return true
+	}
+
+	var detailedErr autorest.DetailedError
+	if errors.As(err, &detailedErr) {
+		if statusCode, ok := detailedErr.StatusCode.(int); ok && statusCode == http.StatusBadRequest {
+			return true
+		}
+	}
+	var requestErr *autorestazure.RequestError
+	return errors.As(err, &requestErr) && requestErr.StatusCode == http.StatusBadRequest
+}
+
+// recoverFromFailedDeployment re-applies the config App Gateway had before the failed deployment, then finds the Ingresses
+// whose config fails to provision from the sub-resources named in the error, or with trial deployments when these are enabled.
+// These are excluded until they are changed and App Gateway is updated with the config of the remaining Ingresses.
+func (c AppGwIngressController) recoverFromFailedDeployment(deployErr error, previousAppGw *n.ApplicationGateway, cbCtx *appgw.ConfigBuilderContext, ingresses []*networking.Ingress) error {
+	appGwName := c.appGwIdentifier.AppGwName
+	klog.Errorf("Deployment of App Gateway %s failed; Rolling back to the previously applied config: %s", appGwName, deployErr)
+	c.MetricStore.IncArmAPIUpdateCallFailureCounter()
+
+	rolledBack, err := copyAppGw(previousAppGw)
+	if err == nil {
+		err = c.deployLatestAppGw(rolledBack)
+	}
+	if err != nil {
+		return controllererrors.NewErrorWithInnerErrorf(
+			controllererrors.ErrorRollingBackAppGatewayConfig,
+			err,
+			"unable to roll back App Gateway %s after the deployment failed with: %s", appGwName, deployErr,
+		)
+	}
+	c.MetricStore.IncArmAPIUpdateCallSuccessCounter()
+
+	msg := fmt.Sprintf("Rolled back App Gateway %s to the previously applied config after a failed deployment: %s", appGwName, deployErr)
+	klog.Warning(msg)
+	if c.agicPod != nil {
+		c.recorder.Event(c.agicPod, v1.EventTypeWarning, events.ReasonRolledBackAppGwConfig, msg)
+	}
+	c.recordRevision(rolledBack, "Automatic rollback after a failed deployment")
+	c.updateCache(rolledBack)
+
+	var deployed *n.ApplicationGateway
+	var deployedIngresses []*networking.Ingress
+	deploy := func(ingressList []*networking.Ingress) error {
+		candidate, err := c.buildAppGw(previousAppGw, cbCtx, ingressList)
+		if err != nil {
+			return err
+		}
+		klog.V(3).Infof("[auto-rollback] Deploying the config of %d Ingresses", len(ingressList))
+		if err := c.deployLatestAppGw(candidate); err != nil {
+			klog.V(3).Info("[auto-rollback] Deployment failed: ", err)
+			deployed, deployedIngresses = nil, nil
+			return err
+		}
+		deployed, deployedIngresses = candidate, ingressList
+		return nil
+	}
+
+	unchanged, changed := c.splitChangedIngresses(cbCtx.IngressList)
+	culprits := c.blameIngresses(previousAppGw, cbCtx, unchanged, changed, deployErr)
+	if len(culprits) == 0 {
+		maxTrialDeployments, _ := strconv.Atoi(cbCtx.EnvVariables.AutoRollbackMaxTrialDeployments)
+		culprits = findFailingIngresses(unchanged, changed, maxTrialDeployments, deploy)
+	}
+	if len(culprits) == 0 {
+		return controllererrors.NewErrorWithInnerErrorf(
+			controllererrors.ErrorDeployingAppGatewayConfig,
+			deployErr,
+			"rolled back App Gateway %s, but could not find an Ingress causing the failed deployment", appGwName,
+		)
+	}
+
+	for _, ingress := range culprits {
+		errorLine := fmt.Sprintf("ignoring Ingress %s/%s as its config failed to provision on Application Gateway %s: %s. The Ingress is applied again once it is changed.",
+			ingress.Namespace, ingress.Name, appGwName, deployErr)
+		klog.Error(errorLine)
+		c.excludedIngresses[utils.GetResourceKey(ingress.Namespace, ingress.Name)] = excludedIngress{
+			uid:        ingress.UID,
+			generation: ingress.Generation,
+			message:    errorLine,
+		}
+		c.recorder.Event(ingress, v1.EventTypeWarning, events.ReasonFailedProvisioning, errorLine)
+		cbCtx.IngressStatus.PruneIngress(ingress, events.ReasonFailedProvisioning, errorLine)
+		if c.agicPod != nil {
+			c.recorder.Event(c.agicPod, v1.EventTypeWarning, events.ReasonFailedProvisioning, errorLine)
+		}
+	}
+
+	// Trial deployments may have stopped on the config of a subset of the remaining Ingresses; Apply the config of all of them.
+	remaining := withoutIngresses(cbCtx.IngressList, culprits)
+	if deployed == nil || len(deployedIngresses) != len(remaining) {
+		if err := deploy(remaining); err != nil {
+			return controllererrors.NewErrorWithInnerErrorf(
+				controllererrors.ErrorDeployingAppGatewayConfig,
+				err,
+				"unable to deploy App Gateway %s without the Ingresses causing the failed deployment", appGwName,
+			)
+		}
+	}
+
+	klog.V(1).Infof("Applied generated Application Gateway configuration without %d Ingresses causing the failed deployment", len(culprits))
+	c.recordRevision(deployed, fmt.Sprintf("Excluded %d Ingresses after a failed deployment", len(culprits)))
+	c.recordAppliedIngresses(remaining)
+	c.updateIngressStatuses(ingresses, cbCtx.IngressStatus)
+	c.updateCache(deployed)
+	return nil
+}
+
+// buildAppGw generates the config of the given Ingresses on top of a copy of the existing App Gateway config.
+func (c AppGwIngressController) buildAppGw(existingAppGw *n.ApplicationGateway, cbCtx *appgw.ConfigBuilderContext, ingressList []*networking.Ingress) (*n.ApplicationGateway, error) {
+	appGw, err := copyAppGw(existingAppGw)
+	if err != nil {
+		return nil, err
+	}
+
+	candidateCtx := *cbCtx
+	candidateCtx.IngressList = ingressList
+	candidateCtx.IngressStatus = nil
+	candidateCtx.ExistingPortsByNumber = make(map[appgw.Port]n.ApplicationGatewayFrontendPort)
+	if appGw.FrontendPorts != nil {
+		for _, port := range *appGw.FrontendPorts {
+			candidateCtx.ExistingPortsByNumber[appgw.Port(*port.Port)] = port
+		}
+	}
+
+	configBuilder := appgw.NewConfigBuilder(c.k8sContext, &c.appGwIdentifier, appGw, c.recorder, realClock{})
+	return configBuilder.Build(&candidateCtx)
+}
+
+// splitChangedIngresses separates the Ingresses applied unchanged by the last successful deployment from the others.
+// Without any change all Ingresses are suspects.
+func (c AppGwIngressController) splitChangedIngresses(ingressList []*networking.Ingress) (unchanged []*networking.Ingress, changed []*networking.Ingress) {
+	for _, ingress := range ingressList {
+		generation, applied := c.appliedIngresses[utils.GetResourceKey(ingress.Namespace, ingress.Name)]
+		if applied && generation == ingress.Generation {
+			unchanged = append(unchanged, ingress)
+		} else {
+			changed = append(changed, ingress)
+		}
+	}
+	if len(changed) == 0 {
+		return nil, unchanged
+	}
+	return unchanged, changed
+}
+
+// recordAppliedIngresses remembers the generation of every Ingress in the applied config.
+func (c AppGwIngressController) recordAppliedIngresses(ingressList []*networking.Ingress) {
+	for key := range c.appliedIngresses {
+		delete(c.appliedIngresses, key)
+	}
+	for _, ingress := range ingressList {
+		c.appliedIngresses[utils.GetResourceKey(ingress.Namespace, ingress.Name)] = ingress.Generation
+	}
+}
+
+// blameIngresses finds the suspects whose config contains a sub-resource named in the error of the failed deployment.
+// It compares the config of each suspect on top of the config of the good Ingresses with the config of the good Ingresses alone,
+// so it deploys nothing. Without a single match the error is not attributed, unless there is a single suspect.
+func (c AppGwIngressController) blameIngresses(previousAppGw *n.ApplicationGateway, cbCtx *appgw.ConfigBuilderContext, good []*networking.Ingress, suspects []*networking.Ingress, deployErr error) []*networking.Ingress {
+	if len(suspects) == 1 {
+		return suspects
+	}
+
+	baseline, err := c.snapshotAppGw(previousAppGw, cbCtx, good)
+	if err != nil {
+		klog.Warning("[auto-rollback] Could not generate the config of the Ingresses which did not change: ", err)
+		return nil
+	}
+
+	errorText := deployErr.Error()
+	var culprits []*networking.Ingress
+	for _, suspect := range suspects {
+		candidate, err := c.snapshotAppGw(previousAppGw, cbCtx, concatIngresses(good, []*networking.Ingress{suspect}))
+		if err != nil {
+			klog.Warningf("[auto-rollback] Could not generate the config of Ingress %s/%s: %s", suspect.Namespace, suspect.Name, err)
+			continue
+		}
+		for _, change := range configdiff.Compare(baseline, candidate).Changes {
+			if change.Action != configdiff.Removed && mentionsName(errorText, change.Name) {
+				klog.V(3).Infof("[auto-rollback] The failed deployment names %s %s of Ingress %s/%s", change.Kind, change.Name, suspect.Namespace, suspect.Name)
+				culprits = append(culprits, suspect)
+				break
+			}
+		}
+	}
+	return culprits
+}
+
+// snapshotAppGw generates the config of the given Ingresses, without deploying it, and snapshots its sub-resources.
+func (c AppGwIngressController) snapshotAppGw(previousAppGw *n.ApplicationGateway, cbCtx *appgw.ConfigBuilderContext, ingressList []*networking.Ingress) (configdiff.Snapshot, error) {
+	appGw, err := c.buildAppGw(previousAppGw, cbCtx, ingressList)
+	if err != nil {
+		return nil, err
+	}
+	return configdiff.NewSnapshot(appGw)
+}
+
+// mentionsName tells whether the text contains the name of a sub-resource as a whole, e.g. within its resource ID.
+func mentionsName(text, name string) bool {
+	if name == "" {
+		return false
+	}
+	isNameChar := func(char byte) bool {
+		return char == '-' || char == '_' || char == '.' ||
+			(char >= '0' && char <= '9') || (char >= 'a' && char <= 'z') || (char >= 'A' && char <= 'Z')
+	}
+	for offset := 0; offset < len(text); {
+		idx := strings.Index(text[offset:], name)
+		if idx < 0 {
+			return false
+		}
+		start, end := offset+idx, offset+idx+len(name)
+		if (start == 0 || !isNameChar(text[start-1])) && (end == len(text) || !isNameChar(text[end])) {
+			return true
+		}
+		offset = start + 1
+	}
+	return false
+}
+
+// findFailingIngresses deploys the config of each suspect on top of the config of the good Ingresses, one suspect after the other,
+// for at most maxDeployments deployments. A suspect whose config fails to deploy is a culprit; One whose config deploys joins the good Ingresses.
+// Every trial deployment takes App Gateway away from the config of the suspects not tried yet, so it is only done when enabled.
+func findFailingIngresses(good []*networking.Ingress, suspects []*networking.Ingress, maxDeployments int, deploy func([]*networking.Ingress) error) []*networking.Ingress {
+	var culprits []*networking.Ingress
+	for idx, suspect := range suspects {
+		if idx >= maxDeployments {
+			klog.Warningf("[auto-rollback] Stopped after %d trial deployments; %d Ingresses were not tried", maxDeployments, len(suspects)-idx)
+			break
+		}
+		err := deploy(concatIngresses(good, []*networking.Ingress{suspect}))
+		switch {
+		case err == nil:
+			good = concatIngresses(good, []*networking.Ingress{suspect})
+		case isFailedDeployment(err):
+			culprits = append(culprits, suspect)
+		default:
+			klog.Warning("[auto-rollback] Stopped the trial deployments: ", err)
+			return culprits
+		}
+	}
+	return culprits
+}
+
+// pruneExcludedIngress filters ingresses whose config failed to provision on App Gateway until they are changed
+func pruneExcludedIngress(c *AppGwIngressController, appGw *n.ApplicationGateway, cbCtx *appgw.ConfigBuilderContext, ingressList []*networking.Ingress) []*networking.Ingress {
+	if len(c.excludedIngresses) == 0 {
+		return ingressList
+	}
+
+	var prunedIngresses []*networking.Ingress
+	for _, ingress := range ingressList {
+		ingressKey := utils.GetResourceKey(ingress.Namespace, ingress.Name)
+		excluded, exists := c.excludedIngresses[ingressKey]
+		if exists && (excluded.uid != ingress.UID || excluded.generation != ingress.Generation) {
+			klog.Infof("Ingress %s/%s changed since its config failed to provision; Applying it again", ingress.Namespace, ingress.Name)
+			delete(c.excludedIngresses, ingressKey)
+			exists = false
+		}
+		if !exists {
+			prunedIngresses = append(prunedIngresses, ingress)
+			continue
+		}
+		klog.V(3).Info(excluded.message)
+		cbCtx.IngressStatus.PruneIngress(ingress, events.ReasonFailedProvisioning, excluded.message)
+	}
+
+	return prunedIngresses
+}
+
+// copyAppGw deep copies the App Gateway config the way it is sent to ARM.
+func copyAppGw(appGw *n.ApplicationGateway) (*n.ApplicationGateway, error) {
+	appGwJSON, err := appGw.MarshalJSON()
+	if err != nil {
+		return nil, err
+	}
+	var appGwCopy n.ApplicationGateway
+	if err := json.Unmarshal(appGwJSON, &appGwCopy); err != nil {
+		return nil, err
+	}
+	return &appGwCopy, nil
+}
+
+func concatIngresses(lists ...[]*networking.Ingress) []*networking.Ingress {
+	var ingressList []*networking.Ingress
+	for _, list := range lists {
+		ingressList = append(ingressList, list...)
+	}
+	return ingressList
+}
+
+func withoutIngresses(ingressList []*networking.Ingress, exclude []*networking.Ingress) []*networking.Ingress {
+	excluded := make(map[string]interface{})
+	for _, ingress := range exclude {
+		excluded[utils.GetResourceKey(ingress.Namespace, ingress.Name)] = nil
+	}
+	var remaining []*networking.Ingress
+	for _, ingress := range ingressList {
+		if _, exists := excluded[utils.GetResourceKey(ingress.Namespace, ingress.Name)]; !exists {
+			remaining = append(remaining, ingress)
+		}
+	}
+	return remaining
+}
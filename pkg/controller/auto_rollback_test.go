@@ -0,0 +1,291 @@
+// -------------------------------------------------------------------------------------------
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License. See License.txt in the project root for license information.
+// --------------------------------------------------------------------------------------------
+
+package controller
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	n "github.com/Azure/azure-sdk-for-go/services/network/mgmt/2021-03-01/network"
+	"github.com/Azure/go-autorest/autorest"
+	autorestazure "github.com/Azure/go-autorest/autorest/azure"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	networking "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	testclient "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/record"
+	gateway_fake "sigs.k8s.io/gateway-api/pkg/client/clientset/versioned/fake"
+
+	"github.com/Azure/application-gateway-kubernetes-ingress/pkg/appgw"
+	"github.com/Azure/application-gateway-kubernetes-ingress/pkg/azure"
+	"github.com/Azure/application-gateway-kubernetes-ingress/pkg/controllererrors"
+	"github.com/Azure/application-gateway-kubernetes-ingress/pkg/crd_client/agic_crd_client/clientset/versioned/fake"
+	multiClusterFake "github.com/Azure/application-gateway-kubernetes-ingress/pkg/crd_client/azure_multicluster_crd_client/clientset/versioned/fake"
+	istioFake "github.com/Azure/application-gateway-kubernetes-ingress/pkg/crd_client/istio_crd_client/clientset/versioned/fake"
+	"github.com/Azure/application-gateway-kubernetes-ingress/pkg/environment"
+	"github.com/Azure/application-gateway-kubernetes-ingress/pkg/events"
+	"github.com/Azure/application-gateway-kubernetes-ingress/pkg/ingressstatus"
+	"github.com/Azure/application-gateway-kubernetes-ingress/pkg/k8scontext"
+	"github.com/Azure/application-gateway-kubernetes-ingress/pkg/metricstore"
+	"github.com/Azure/application-gateway-kubernetes-ingress/pkg/tests"
+	"github.com/Azure/application-gateway-kubernetes-ingress/pkg/tests/fixtures"
+)
+
+var _ = Describe("automatic rollback", func() {
+	newIngress := func(name string) *networking.Ingress {
+		return &networking.Ingress{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:       name,
+				Namespace:  tests.Namespace,
+				Generation: 1,
+			},
+			Spec: networking.IngressSpec{
+				Rules: []networking.IngressRule{
+					tests.NewIngressRuleFixture(name+".contoso.com", "/", *tests.NewIngressBackendFixture(tests.ServiceName, 80)),
+				},
+			},
+		}
+	}
+
+	names := func(ingressList []*networking.Ingress) []string {
+		var ingressNames []string
+		for _, ingress := range ingressList {
+			ingressNames = append(ingressNames, ingress.Name)
+		}
+		return ingressNames
+	}
+
+	Context("ensure findFailingIngresses deploys one suspect at a time", func() {
+		var deployments int
+		deployFailingWith := func(failing ...string) func([]*networking.Ingress) error {
+			return func(ingressList []*networking.Ingress) error {
+				deployments++
+				for _, ingress := range ingressList {
+					for _, name := range failing {
+						if ingress.Name == name {
+							return controllererrors.NewError(controllererrors.ErrorAppGatewayProvisioningFailed, "failed provisioning")
+						}
+					}
+				}
+				return nil
+			}
+		}
+
+		BeforeEach(func() {
+			deployments = 0
+		})
+
+		It("finds every failing Ingress with one deployment per suspect", func() {
+			suspects := []*networking.Ingress{newIngress("a"), newIngress("b"), newIngress("c"), newIngress("d")}
+			culprits := findFailingIngresses(nil, suspects, 10, deployFailingWith("b", "d"))
+			Expect(names(culprits)).To(Equal([]string{"b", "d"}))
+			Expect(deployments).To(Equal(4))
+		})
+
+		It("stops after the maximum number of deployments", func() {
+			suspects := []*networking.Ingress{newIngress("a"), newIngress("b"), newIngress("c"), newIngress("d")}
+			culprits := findFailingIngresses(nil, suspects, 2, deployFailingWith("b", "d"))
+			Expect(names(culprits)).To(Equal([]string{"b"}))
+			Expect(deployments).To(Equal(2))
+
+			deployments = 0
+			Expect(findFailingIngresses(nil, suspects, 0, deployFailingWith("b"))).To(BeEmpty())
+			Expect(deployments).To(Equal(0))
+		})
+
+		It("stops on an error which does not come from the config", func() {
+			suspects := []*networking.Ingress{newIngress("a"), newIngress("b")}
+			culprits := findFailingIngresses(nil, suspects, 10, func([]*networking.Ingress) error {
+				deployments++
+				return autorest.DetailedError{StatusCode: http.StatusTooManyRequests}
+			})
+			Expect(culprits).To(BeEmpty())
+			Expect(deployments).To(Equal(1))
+		})
+	})
+
+	Context("ensure mentionsName matches whole sub-resource names", func() {
+		It("matches names within resource IDs only as a whole", func() {
+			errorText := "Resource /subscriptions/sub/resourceGroups/rg/providers/Microsoft.Network/applicationGateways/gw/httpListeners/fl-80-bad referenced by ..."
+			Expect(mentionsName(errorText, "fl-80-bad")).To(BeTrue())
+			Expect(mentionsName(errorText, "fl-80")).To(BeFalse())
+			Expect(mentionsName(errorText, "80-bad")).To(BeFalse())
+			Expect(mentionsName(errorText, "")).To(BeFalse())
+		})
+	})
+
+	Context("ensure isFailedDeployment only blames the config", func() {
+		It("tells failed and rejected deployments from incomplete ones", func() {
+			Expect(isFailedDeployment(controllererrors.NewError(controllererrors.ErrorAppGatewayProvisioningFailed, "failed"))).To(BeTrue())
+			Expect(isFailedDeployment(autorest.DetailedError{StatusCode: http.StatusBadRequest})).To(BeTrue())
+			Expect(isFailedDeployment(autorest.NewErrorWithError(&autorestazure.RequestError{
+				DetailedError: autorest.DetailedError{StatusCode: http.StatusBadRequest},
+			}, "network.ApplicationGatewaysClient", "CreateOrUpdate", nil, "Failure sending request"))).To(BeTrue())
+
+			Expect(isFailedDeployment(autorest.DetailedError{StatusCode: http.StatusTooManyRequests})).To(BeFalse())
+			Expect(isFailedDeployment(autorest.DetailedError{StatusCode: http.StatusConflict})).To(BeFalse())
+			Expect(isFailedDeployment(controllererrors.NewError(controllererrors.ErrorApplicationGatewayConcurrentUpdate, "412"))).To(BeFalse())
+			Expect(isFailedDeployment(context.Canceled)).To(BeFalse())
+			Expect(isFailedDeployment(errors.New("connection reset by peer"))).To(BeFalse())
+		})
+	})
+
+	Context("ensure pruneExcludedIngress prunes ingress", func() {
+		It("prunes an excluded Ingress until it changes", func() {
+			controller := &AppGwIngressController{
+				recorder:          record.NewFakeRecorder(100),
+				excludedIngresses: map[string]excludedIngress{},
+			}
+			good := newIngress("good")
+			bad := newIngress("bad")
+			controller.excludedIngresses[tests.Namespace+"/bad"] = excludedIngress{
+				generation: 1,
+				message:    "failed provisioning",
+			}
+			cbCtx := &appgw.ConfigBuilderContext{
+				IngressList:   []*networking.Ingress{good, bad},
+				IngressStatus: ingressstatus.NewTracker(),
+			}
+
+			prunedIngresses := pruneExcludedIngress(controller, nil, cbCtx, cbCtx.IngressList)
+			Expect(names(prunedIngresses)).To(Equal([]string{"good"}))
+			Expect(cbCtx.IngressStatus.Status(bad).PrunedRules[0].Reason).To(Equal(events.ReasonFailedProvisioning))
+
+			bad.Generation = 2
+			prunedIngresses = pruneExcludedIngress(controller, nil, cbCtx, cbCtx.IngressList)
+			Expect(names(prunedIngresses)).To(Equal([]string{"good", "bad"}))
+			Expect(controller.excludedIngresses).To(BeEmpty())
+		})
+	})
+
+	Context("ensure a failed deployment is rolled back", func() {
+		var controller *AppGwIngressController
+		var azClient *azure.FakeAzClient
+		var deployed []string
+		var provisioningState n.ProvisioningState
+
+		BeforeEach(func() {
+			k8scontext.IsNetworkingV1PackageSupported = true
+			k8sContext := k8scontext.NewContext(testclient.NewSimpleClientset(), fake.NewSimpleClientset(), multiClusterFake.NewSimpleClientset(), istioFake.NewSimpleClientset(), gateway_fake.NewSimpleClientset(), []string{}, 1000*time.Second, metricstore.NewFakeMetricStore(), environment.GetFakeEnv())
+
+			deployed = nil
+			provisioningState = n.ProvisioningStateSucceeded
+			azClient = azure.NewFakeAzClient()
+			azClient.GetGatewayFunc = func() (n.ApplicationGateway, error) {
+				appGw := fixtures.GetAppGateway()
+				appGw.FrontendPorts = &[]n.ApplicationGatewayFrontendPort{}
+				appGw.ProvisioningState = provisioningState
+				return appGw, nil
+			}
+			azClient.UpdateGatewayFunc = func(appGw *n.ApplicationGateway) error {
+				appGwJSON, _ := appGw.MarshalJSON()
+				deployed = append(deployed, string(appGwJSON))
+				provisioningState = n.ProvisioningStateSucceeded
+				if strings.Contains(string(appGwJSON), "bad.contoso.com") {
+					provisioningState = n.ProvisioningStateFailed
+				}
+				return nil
+			}
+
+			controller = NewAppGwIngressController(azClient, appgw.Identifier{AppGwName: "--AppGwName--"}, k8sContext, record.NewFakeRecorder(100), metricstore.NewFakeMetricStore(), nil, nil, false)
+		})
+
+		It("excludes the Ingress whose sub-resource ARM rejected without any trial deployment", func() {
+			appGw, cbCtx, err := controller.GetAppGw()
+			Expect(err).ToNot(HaveOccurred())
+			cbCtx.EnvVariables.EnableAutoRollback = true
+			cbCtx.IngressList = []*networking.Ingress{newIngress("good"), newIngress("bad")}
+			azClient.UpdateGatewayFunc = func(appGw *n.ApplicationGateway) error {
+				appGwJSON, _ := appGw.MarshalJSON()
+				deployed = append(deployed, string(appGwJSON))
+				for _, listener := range *appGw.HTTPListeners {
+					if listener.HostNames != nil && len(*listener.HostNames) > 0 && (*listener.HostNames)[0] == "bad.contoso.com" {
+						return autorest.DetailedError{
+							StatusCode: http.StatusBadRequest,
+							Message:    "Invalid value of " + *listener.ID,
+						}
+					}
+				}
+				return nil
+			}
+
+			Expect(controller.MutateAppGateway(events.Event{Type: events.PeriodicReconcile}, appGw, cbCtx)).To(Succeed())
+
+			// The failed deployment, the rollback and the deployment without the bad Ingress.
+			Expect(deployed).To(HaveLen(3))
+			Expect(deployed[0]).To(ContainSubstring("bad.contoso.com"))
+			Expect(deployed[1]).ToNot(ContainSubstring("good.contoso.com"))
+			Expect(deployed[2]).To(ContainSubstring("good.contoso.com"))
+			Expect(deployed[2]).ToNot(ContainSubstring("bad.contoso.com"))
+			Expect(controller.excludedIngresses).To(HaveKey(tests.Namespace + "/bad"))
+			Expect(controller.appliedIngresses).To(Equal(map[string]int64{tests.Namespace + "/good": 1}))
+		})
+
+		It("only rolls back when the error does not name the failed sub-resource and trial deployments are disabled", func() {
+			appGw, cbCtx, err := controller.GetAppGw()
+			Expect(err).ToNot(HaveOccurred())
+			cbCtx.EnvVariables.EnableAutoRollback = true
+			cbCtx.IngressList = []*networking.Ingress{newIngress("good"), newIngress("bad")}
+
+			Expect(controller.MutateAppGateway(events.Event{Type: events.PeriodicReconcile}, appGw, cbCtx)).ToNot(Succeed())
+
+			// The failed deployment and the rollback.
+			Expect(deployed).To(HaveLen(2))
+			Expect(deployed[1]).ToNot(ContainSubstring("contoso.com"))
+			Expect(controller.excludedIngresses).To(BeEmpty())
+		})
+
+		It("excludes the Ingress which left App Gateway in the Failed provisioning state with trial deployments", func() {
+			appGw, cbCtx, err := controller.GetAppGw()
+			Expect(err).ToNot(HaveOccurred())
+			cbCtx.EnvVariables.EnableAutoRollback = true
+			cbCtx.EnvVariables.AutoRollbackMaxTrialDeployments = "5"
+			cbCtx.IngressList = []*networking.Ingress{newIngress("good"), newIngress("bad")}
+
+			Expect(controller.MutateAppGateway(events.Event{Type: events.PeriodicReconcile}, appGw, cbCtx)).To(Succeed())
+
+			// The failed deployment, the rollback, a trial deployment per suspect and the deployment without the bad Ingress.
+			Expect(deployed).To(HaveLen(5))
+			Expect(deployed[2]).To(ContainSubstring("good.contoso.com"))
+			Expect(deployed[3]).To(ContainSubstring("bad.contoso.com"))
+			Expect(deployed[4]).To(ContainSubstring("good.contoso.com"))
+			Expect(deployed[4]).ToNot(ContainSubstring("bad.contoso.com"))
+			Expect(controller.excludedIngresses).To(HaveKey(tests.Namespace + "/bad"))
+			Expect(controller.appliedIngresses).To(Equal(map[string]int64{tests.Namespace + "/good": 1}))
+		})
+
+		It("does not roll back a deployment which did not complete", func() {
+			appGw, cbCtx, err := controller.GetAppGw()
+			Expect(err).ToNot(HaveOccurred())
+			cbCtx.EnvVariables.EnableAutoRollback = true
+			cbCtx.IngressList = []*networking.Ingress{newIngress("good"), newIngress("bad")}
+			azClient.UpdateGatewayFunc = func(appGw *n.ApplicationGateway) error {
+				appGwJSON, _ := appGw.MarshalJSON()
+				deployed = append(deployed, string(appGwJSON))
+				return autorest.DetailedError{StatusCode: http.StatusTooManyRequests, Message: "throttled"}
+			}
+
+			Expect(controller.MutateAppGateway(events.Event{Type: events.PeriodicReconcile}, appGw, cbCtx)).ToNot(Succeed())
+			Expect(deployed).To(HaveLen(1))
+			Expect(controller.excludedIngresses).To(BeEmpty())
+		})
+
+		It("returns the error without auto rollback", func() {
+			appGw, cbCtx, err := controller.GetAppGw()
+			Expect(err).ToNot(HaveOccurred())
+			azClient.UpdateGatewayFunc = func(*n.ApplicationGateway) error {
+				return errors.New("failed deployment")
+			}
+
+			Expect(controller.MutateAppGateway(events.Event{Type: events.PeriodicReconcile}, appGw, cbCtx)).ToNot(Succeed())
+			Expect(controller.excludedIngresses).To(BeEmpty())
+		})
+	})
+})
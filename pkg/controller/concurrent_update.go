@@ -0,0 +1,48 @@
+// -------------------------------------------------------------------------------------------
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License. See License.txt in the project root for license information.
+// --------------------------------------------------------------------------------------------
+
+package controller
+
+import (
+	n "github.com/Azure/azure-sdk-for-go/services/network/mgmt/2021-03-01/network"
+	"k8s.io/klog/v2"
+
+	"github.com/Azure/application-gateway-kubernetes-ingress/pkg/controllererrors"
+)
+
+// maxConcurrentUpdateAttempts bounds how many times an event reads App Gateway and generates its config
+// when App Gateway keeps being modified before the generated config is deployed.
+const maxConcurrentUpdateAttempts = 3
+
+// isConcurrentUpdate tells whether ARM rejected a deployment as App Gateway was modified since AGIC read it.
+func isConcurrentUpdate(err error) bool {
+	return controllererrors.IsErrorCode(err, controllererrors.ErrorApplicationGatewayConcurrentUpdate)
+}
+
+// retryConcurrentUpdate tells whether to read App Gateway and generate its config again after the deployment failed with err.
+func (c AppGwIngressController) retryConcurrentUpdate(err error, attempt int) bool {
+	if !isConcurrentUpdate(err) {
+		return false
+	}
+	c.MetricStore.IncArmAPIConcurrencyConflictCounter()
+	if attempt >= maxConcurrentUpdateAttempts {
+		return false
+	}
+	klog.Warningf("App Gateway %s was modified since AGIC read it; Reading it and generating its config again (attempt %d of %d)",
+		c.appGwIdentifier.AppGwName, attempt+1, maxConcurrentUpdateAttempts)
+	return true
+}
+
+// deployLatestAppGw deploys appGw on top of App Gateway as it is now. Recovering from a failed deployment deploys several configs
+// in a row, each of which changes the ETag of App Gateway; AGIC only guards against modifications between its own read and deployment.
+func (c AppGwIngressController) deployLatestAppGw(appGw *n.ApplicationGateway) error {
+	latest, err := c.azClient.GetGateway(c.armContext())
+	c.MetricStore.IncArmAPICallCounter()
+	if err != nil {
+		return err
+	}
+	appGw.Etag = latest.Etag
+	return c.deployAppGw(appGw, true)
+}
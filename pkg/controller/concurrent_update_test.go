@@ -0,0 +1,91 @@
+// -------------------------------------------------------------------------------------------
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License. See License.txt in the project root for license information.
+// --------------------------------------------------------------------------------------------
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	n "github.com/Azure/azure-sdk-for-go/services/network/mgmt/2021-03-01/network"
+	"github.com/Azure/go-autorest/autorest/to"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	testclient "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/record"
+	gateway_fake "sigs.k8s.io/gateway-api/pkg/client/clientset/versioned/fake"
+
+	"github.com/Azure/application-gateway-kubernetes-ingress/pkg/appgw"
+	"github.com/Azure/application-gateway-kubernetes-ingress/pkg/azure"
+	"github.com/Azure/application-gateway-kubernetes-ingress/pkg/controllererrors"
+	"github.com/Azure/application-gateway-kubernetes-ingress/pkg/crd_client/agic_crd_client/clientset/versioned/fake"
+	multiClusterFake "github.com/Azure/application-gateway-kubernetes-ingress/pkg/crd_client/azure_multicluster_crd_client/clientset/versioned/fake"
+	istioFake "github.com/Azure/application-gateway-kubernetes-ingress/pkg/crd_client/istio_crd_client/clientset/versioned/fake"
+	"github.com/Azure/application-gateway-kubernetes-ingress/pkg/environment"
+	"github.com/Azure/application-gateway-kubernetes-ingress/pkg/events"
+	"github.com/Azure/application-gateway-kubernetes-ingress/pkg/k8scontext"
+	"github.com/Azure/application-gateway-kubernetes-ingress/pkg/metricstore"
+	"github.com/Azure/application-gateway-kubernetes-ingress/pkg/tests/fixtures"
+)
+
+type fakeCniReconciler struct{}
+
+func (fakeCniReconciler) Reconcile(context.Context) error {
+	return nil
+}
+
+var _ = Describe("concurrent updates of App Gateway", func() {
+	var controller *AppGwIngressController
+	var reads int
+	var deployedEtags []string
+	var conflicts int
+
+	BeforeEach(func() {
+		k8scontext.IsNetworkingV1PackageSupported = true
+		k8sContext := k8scontext.NewContext(testclient.NewSimpleClientset(), fake.NewSimpleClientset(), multiClusterFake.NewSimpleClientset(), istioFake.NewSimpleClientset(), gateway_fake.NewSimpleClientset(), []string{}, 1000*time.Second, metricstore.NewFakeMetricStore(), environment.GetFakeEnv())
+
+		reads, deployedEtags, conflicts = 0, nil, 0
+		azClient := azure.NewFakeAzClient()
+		azClient.GetGatewayFunc = func() (n.ApplicationGateway, error) {
+			reads++
+			appGw := fixtures.GetAppGateway()
+			appGw.FrontendPorts = &[]n.ApplicationGatewayFrontendPort{}
+			appGw.OperationalState = n.ApplicationGatewayOperationalStateRunning
+			appGw.Etag = to.StringPtr(fmt.Sprintf("etag-%d", reads))
+			return appGw, nil
+		}
+		azClient.GetPublicIPFunc = func(string) (n.PublicIPAddress, error) {
+			return n.PublicIPAddress{PublicIPAddressPropertiesFormat: &n.PublicIPAddressPropertiesFormat{IPAddress: to.StringPtr("1.2.3.4")}}, nil
+		}
+		azClient.UpdateGatewayFunc = func(appGw *n.ApplicationGateway) error {
+			deployedEtags = append(deployedEtags, to.String(appGw.Etag))
+			if len(deployedEtags) <= conflicts {
+				return controllererrors.NewError(controllererrors.ErrorApplicationGatewayConcurrentUpdate, "modified since it was read")
+			}
+			return nil
+		}
+
+		controller = NewAppGwIngressController(azClient, appgw.Identifier{AppGwName: "--AppGwName--"}, k8sContext, record.NewFakeRecorder(100), metricstore.NewFakeMetricStore(), fakeCniReconciler{}, nil, false)
+	})
+
+	It("deploys the config with the ETag of the App Gateway it was generated from", func() {
+		Expect(controller.ProcessEvent(events.Event{Type: events.PeriodicReconcile})).To(Succeed())
+		Expect(deployedEtags).To(Equal([]string{"etag-1"}))
+	})
+
+	It("reads App Gateway and generates its config again after a concurrent update", func() {
+		conflicts = 1
+		Expect(controller.ProcessEvent(events.Event{Type: events.PeriodicReconcile})).To(Succeed())
+		Expect(deployedEtags).To(Equal([]string{"etag-1", "etag-2"}))
+	})
+
+	It("gives up once App Gateway kept being modified", func() {
+		conflicts = maxConcurrentUpdateAttempts
+		err := controller.ProcessEvent(events.Event{Type: events.PeriodicReconcile})
+		Expect(controllererrors.IsErrorCode(err, controllererrors.ErrorApplicationGatewayConcurrentUpdate)).To(BeTrue())
+		Expect(deployedEtags).To(HaveLen(maxConcurrentUpdateAttempts))
+	})
+})
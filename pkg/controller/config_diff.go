@@ -0,0 +1,66 @@
+// -------------------------------------------------------------------------------------------
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License. See License.txt in the project root for license information.
+// --------------------------------------------------------------------------------------------
+
+package controller
+
+import (
+	"fmt"
+
+	n "github.com/Azure/azure-sdk-for-go/services/network/mgmt/2021-03-01/network"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/klog/v2"
+
+	"github.com/Azure/application-gateway-kubernetes-ingress/pkg/configdiff"
+	"github.com/Azure/application-gateway-kubernetes-ingress/pkg/events"
+)
+
+// diffAppGw compares the snapshot of the existing config with the generated config.
+func diffAppGw(existing configdiff.Snapshot, generatedAppGw *n.ApplicationGateway) (*configdiff.Diff, error) {
+	if existing == nil {
+		return nil, fmt.Errorf("no snapshot of the existing App Gateway config")
+	}
+	generated, err := configdiff.NewSnapshot(generatedAppGw)
+	if err != nil {
+		return nil, err
+	}
+	return configdiff.Compare(existing, generated), nil
+}
+
+// logConfigDiff logs a summary of the diff; Each changed sub-resource is logged at a higher verbosity.
+func logConfigDiff(msg string, appGwName string, diff *configdiff.Diff) {
+	totals := diff.Totals()
+	klog.V(1).InfoS(msg, "appGateway", appGwName, "added", totals.Added, "changed", totals.Changed, "removed", totals.Removed, "unchanged", totals.Unchanged)
+	for _, change := range diff.Changes {
+		if change.Action == configdiff.Changed {
+			klog.V(3).InfoS("App Gateway sub-resource", "kind", change.Kind, "name", change.Name, "action", change.Action, "fields", change.Fields)
+			continue
+		}
+		klog.V(3).InfoS("App Gateway sub-resource", "kind", change.Kind, "name", change.Name, "action", change.Action)
+	}
+}
+
+// reportAppliedConfigDiff records the changes made by an applied config in an event on the AGIC pod and in metrics.
+func (c AppGwIngressController) reportAppliedConfigDiff(diff *configdiff.Diff) {
+	if !diff.HasChanges() {
+		return
+	}
+
+	if c.agicPod != nil {
+		msg := fmt.Sprintf("Applied configuration to App Gateway %s: %s", c.appGwIdentifier.AppGwName, diff)
+		c.recorder.Event(c.agicPod, v1.EventTypeNormal, events.ReasonAppliedAppGwConfig, msg)
+	}
+
+	for kind, counts := range diff.Summary {
+		for action, count := range map[configdiff.Action]int{
+			configdiff.Added:   counts.Added,
+			configdiff.Changed: counts.Changed,
+			configdiff.Removed: counts.Removed,
+		} {
+			if count > 0 {
+				c.MetricStore.AddConfigChanges(string(kind), string(action), count)
+			}
+		}
+	}
+}
@@ -0,0 +1,269 @@
+// -------------------------------------------------------------------------------------------
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License. See License.txt in the project root for license information.
+// --------------------------------------------------------------------------------------------
+
+package controller
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	n "github.com/Azure/azure-sdk-for-go/services/network/mgmt/2021-03-01/network"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/klog/v2"
+
+	"github.com/Azure/application-gateway-kubernetes-ingress/pkg/confighistory"
+	"github.com/Azure/application-gateway-kubernetes-ingress/pkg/controllererrors"
+	"github.com/Azure/application-gateway-kubernetes-ingress/pkg/environment"
+	"github.com/Azure/application-gateway-kubernetes-ingress/pkg/events"
+)
+
+// startConfigHistory creates the config history store and restores the pause persisted by a previous leader.
+func (c *AppGwIngressController) startConfigHistory(envVariables environment.EnvVariables) {
+	size, _ := strconv.Atoi(envVariables.ConfigHistorySize)
+	c.history = confighistory.NewStore(c.k8sContext.ConfigMaps(envVariables.AGICPodNamespace), envVariables.ConfigHistoryConfigMapName, size)
+	klog.Infof("Config history enabled; Keeping the last %d applied configs in ConfigMap %s/%s", size, envVariables.AGICPodNamespace, envVariables.ConfigHistoryConfigMapName)
+	c.loadPause()
+}
+
+// loadPause reads the pause state from the config history, which another replica may have changed.
+func (c *AppGwIngressController) loadPause() {
+	if c.history == nil {
+		return
+	}
+
+	pause, err := c.history.Paused()
+	if err != nil {
+		klog.Error("Could not read the pause state from the config history: ", err)
+		return
+	}
+	c.paused.Store(pause)
+	if pause != nil {
+		klog.Warningf("Reconciliation is paused since App Gateway was rolled back to revision %d at %s", pause.Revision, pause.PausedAt)
+	}
+}
+
+// Paused returns the pause set by the last rollback; nil when AGIC reconciles App Gateway.
+func (c *AppGwIngressController) Paused() *confighistory.Pause {
+	return c.paused.Load()
+}
+
+// ConfigRevisions lists the applied configs kept in the config history, oldest first.
+func (c *AppGwIngressController) ConfigRevisions() ([]confighistory.Revision, error) {
+	if c.history == nil {
+		return nil, errConfigHistoryDisabled()
+	}
+	return c.history.List()
+}
+
+// ConfigRevision returns a revision of the config history including its sanitized config.
+func (c *AppGwIngressController) ConfigRevision(number int) (*confighistory.Revision, error) {
+	if c.history == nil {
+		return nil, errConfigHistoryDisabled()
+	}
+
+	revision, err := c.history.Get(number)
+	if err != nil {
+		return nil, err
+	}
+	if revision == nil {
+		return nil, controllererrors.NewErrorf(
+			controllererrors.ErrorConfigRevisionNotFound,
+			"revision %d is not in the config history", number,
+		)
+	}
+	return revision, nil
+}
+
+// Rollback re-applies a revision of the config history to App Gateway and pauses reconciliation until Resume is called,
+// so that AGIC does not overwrite the rolled back config with the one generated from the current state of the cluster.
+func (c *AppGwIngressController) Rollback(number int, reason string) (*confighistory.Revision, error) {
+	if c.envVariables().EnableDryRun {
+		return nil, controllererrors.NewError(
+			controllererrors.ErrorRollingBackAppGatewayConfig,
+			"rollback is not available in dry run mode, which never updates App Gateway",
+		)
+	}
+	if !c.IsLeader() {
+		return nil, errNotLeader()
+	}
+
+	revision, err := c.ConfigRevision(number)
+	if err != nil {
+		return nil, err
+	}
+
+	var restored n.ApplicationGateway
+	if err := json.Unmarshal(revision.Config, &restored); err != nil || restored.ApplicationGatewayPropertiesFormat == nil {
+		return nil, controllererrors.NewErrorWithInnerErrorf(
+			controllererrors.ErrorRollingBackAppGatewayConfig,
+			err,
+			"revision %d does not contain a valid App Gateway config", number,
+		)
+	}
+
+	// Wait for the event loop in progress; It must not apply a generated config after the rollback.
+	c.applyLock.Lock()
+	defer c.applyLock.Unlock()
+
+	existing, err := c.azClient.GetGateway(c.armContext())
+	if err != nil {
+		return nil, controllererrors.NewErrorWithInnerErrorf(
+			controllererrors.ErrorFetchingAppGatewayConfig,
+			err,
+			"unable to get specified AppGateway %s", c.appGwIdentifier.AppGwName,
+		)
+	}
+
+	// The config history does not contain the SSL certificates; Keep the ones installed on App Gateway.
+	if missing := missingSslCertificates(&restored, existing.SslCertificates); len(missing) > 0 {
+		return nil, controllererrors.NewErrorf(
+			controllererrors.ErrorRollingBackAppGatewayConfig,
+			"revision %d references SSL certificates which are no longer installed on App Gateway %s: %s",
+			number, c.appGwIdentifier.AppGwName, strings.Join(missing, ", "),
+		)
+	}
+	restored.SslCertificates = existing.SslCertificates
+	restored.Etag = existing.Etag
+
+	klog.Infof("Rolling back App Gateway %s to revision %d", c.appGwIdentifier.AppGwName, number)
+	if err := c.azClient.UpdateGateway(c.armContext(), &restored); err != nil {
+		c.MetricStore.IncArmAPIUpdateCallFailureCounter()
+		return nil, controllererrors.NewErrorWithInnerErrorf(
+			controllererrors.ErrorRollingBackAppGatewayConfig,
+			err,
+			"unable to roll back App Gateway %s to revision %d", c.appGwIdentifier.AppGwName, number,
+		)
+	}
+	c.MetricStore.IncArmAPIUpdateCallSuccessCounter()
+
+	pause := &confighistory.Pause{
+		Revision: number,
+		Reason:   reason,
+		PausedAt: time.Now().UTC(),
+	}
+	c.paused.Store(pause)
+	if err := c.history.Pause(*pause); err != nil {
+		// The pause still holds until AGIC restarts.
+		klog.Error("Could not persist the pause in the config history: ", err)
+	}
+
+	// Compare the next generated config with the rolled back one.
+	c.updateCache(&restored)
+	applied := c.recordRevision(&restored, fmt.Sprintf("Rollback to revision %d", number))
+
+	msg := fmt.Sprintf("Rolled back App Gateway %s to revision %d; Reconciliation is paused until resumed", c.appGwIdentifier.AppGwName, number)
+	if reason != "" {
+		msg = fmt.Sprintf("%s. Reason: %s", msg, reason)
+	}
+	klog.Warning(msg)
+	if c.agicPod != nil {
+		c.recorder.Event(c.agicPod, v1.EventTypeWarning, events.ReasonRolledBackAppGwConfig, msg)
+	}
+
+	if applied == nil {
+		applied = revision
+	}
+	return applied, nil
+}
+
+// missingSslCertificates returns the names of the SSL certificates the listeners of the config reference, which are not among the installed ones.
+func missingSslCertificates(config *n.ApplicationGateway, installed *[]n.ApplicationGatewaySslCertificate) []string {
+	installedNames := make(map[string]interface{})
+	if installed != nil {
+		for _, cert := range *installed {
+			if cert.Name != nil {
+				installedNames[*cert.Name] = nil
+			}
+		}
+	}
+
+	var missing []string
+	if config.HTTPListeners == nil {
+		return missing
+	}
+	for _, listener := range *config.HTTPListeners {
+		if listener.ApplicationGatewayHTTPListenerPropertiesFormat == nil || listener.SslCertificate == nil || listener.SslCertificate.ID == nil {
+			continue
+		}
+		certID := *listener.SslCertificate.ID
+		certName := certID[strings.LastIndex(certID, "/")+1:]
+		if _, exists := installedNames[certName]; !exists {
+			// Name each missing certificate once, however many listeners reference it.
+			installedNames[certName] = nil
+			missing = append(missing, certName)
+		}
+	}
+	return missing
+}
+
+// Resume lifts the pause set by a rollback and reconciles App Gateway with the current state of the cluster.
+func (c *AppGwIngressController) Resume() error {
+	if c.history == nil {
+		return errConfigHistoryDisabled()
+	}
+	if !c.IsLeader() {
+		return errNotLeader()
+	}
+
+	if err := c.history.Resume(); err != nil {
+		return err
+	}
+	if c.paused.Swap(nil) == nil {
+		return nil
+	}
+
+	msg := fmt.Sprintf("Resumed reconciliation of App Gateway %s", c.appGwIdentifier.AppGwName)
+	klog.Info(msg)
+	if c.agicPod != nil {
+		c.recorder.Event(c.agicPod, v1.EventTypeNormal, events.ReasonResumedReconciliation, msg)
+	}
+
+	// Events were discarded while paused; Reconcile the whole gateway right away.
+	c.k8sContext.Work <- events.Event{
+		Type: events.PeriodicReconcile,
+	}
+	return nil
+}
+
+// recordRevision adds the applied config to the config history; It returns the new revision, or nil when nothing was recorded.
+func (c AppGwIngressController) recordRevision(appGw *n.ApplicationGateway, summary string) *confighistory.Revision {
+	if c.history == nil {
+		return nil
+	}
+
+	config, err := sanitizedJSON(appGw)
+	if err != nil {
+		klog.Error("Could not sanitize the applied config for the config history: ", err)
+		return nil
+	}
+
+	revision, recorded, err := c.history.Record(config, summary)
+	if err != nil {
+		klog.Error("Could not record the applied config in the config history: ", err)
+		return nil
+	}
+	if !recorded {
+		return nil
+	}
+	klog.V(3).Infof("Recorded applied config as revision %d of the config history", revision.Number)
+	return revision
+}
+
+func errConfigHistoryDisabled() error {
+	return controllererrors.NewErrorf(
+		controllererrors.ErrorConfigHistoryDisabled,
+		"config history is disabled; Set %s to true to enable it", environment.EnableConfigHistoryVarName,
+	)
+}
+
+func errNotLeader() error {
+	return controllererrors.NewError(
+		controllererrors.ErrorNotLeader,
+		"this AGIC replica is not the leader; Send the request to the leader replica",
+	)
+}
@@ -0,0 +1,61 @@
+// -------------------------------------------------------------------------------------------
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License. See License.txt in the project root for license information.
+// --------------------------------------------------------------------------------------------
+
+package controller
+
+import (
+	"sync"
+	"time"
+
+	"k8s.io/klog/v2"
+
+	"github.com/Azure/application-gateway-kubernetes-ingress/pkg/events"
+)
+
+// drainingTimer fires the reconcile which removes terminating endpoints from backend pools once their connections drained.
+type drainingTimer struct {
+	lock  sync.Mutex
+	timer *time.Timer
+	at    time.Time
+}
+
+// schedule makes fire run at the given time, unless it is already going to run earlier; The earlier reconcile schedules the next one.
+func (t *drainingTimer) schedule(at time.Time, fire func()) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	if t.timer != nil && time.Now().Before(t.at) && !at.Before(t.at) {
+		return
+	}
+	t.stop()
+	t.at = at
+	t.timer = time.AfterFunc(time.Until(at), fire)
+}
+
+// stop cancels the pending reconcile; The lock must be held.
+func (t *drainingTimer) stop() {
+	if t.timer != nil {
+		t.timer.Stop()
+		t.timer = nil
+	}
+}
+
+// scheduleDrainingReconcile reconciles App Gateway once the first terminating endpoint kept in backend pools for connection draining must be removed.
+// drainingUntil is zero when no endpoint is draining.
+func (c AppGwIngressController) scheduleDrainingReconcile(drainingUntil time.Time) {
+	if drainingUntil.IsZero() {
+		return
+	}
+
+	// Give App Gateway the whole drain timeout; The reconcile at drainingUntil itself removes the endpoint.
+	at := drainingUntil.Add(time.Second)
+	klog.V(3).Infof("Scheduling a reconcile at %s to remove drained endpoints from backend pools", at)
+	c.drainingTimer.schedule(at, func() {
+		select {
+		case c.k8sContext.Work <- events.Event{Type: events.PeriodicReconcile}:
+		case <-c.stopChannel:
+		}
+	})
+}
@@ -0,0 +1,59 @@
+// -------------------------------------------------------------------------------------------
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License. See License.txt in the project root for license information.
+// --------------------------------------------------------------------------------------------
+
+package controller
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("test the connection draining timer", func() {
+	Context("ensure the reconcile runs when the first endpoint drained", func() {
+		var timer *drainingTimer
+		var fired chan string
+
+		BeforeEach(func() {
+			timer = &drainingTimer{}
+			fired = make(chan string, 10)
+		})
+
+		AfterEach(func() {
+			timer.lock.Lock()
+			defer timer.lock.Unlock()
+			timer.stop()
+		})
+
+		fire := func(name string) func() {
+			return func() { fired <- name }
+		}
+
+		It("keeps the earlier reconcile", func() {
+			timer.schedule(time.Now().Add(50*time.Millisecond), fire("earlier"))
+			timer.schedule(time.Now().Add(time.Hour), fire("later"))
+
+			Eventually(fired).Should(Receive(Equal("earlier")))
+			Consistently(fired, 200*time.Millisecond).ShouldNot(Receive())
+		})
+
+		It("replaces a later reconcile", func() {
+			timer.schedule(time.Now().Add(time.Hour), fire("later"))
+			timer.schedule(time.Now().Add(50*time.Millisecond), fire("earlier"))
+
+			Eventually(fired).Should(Receive(Equal("earlier")))
+			Consistently(fired, 200*time.Millisecond).ShouldNot(Receive())
+		})
+
+		It("schedules a new reconcile once the previous one fired", func() {
+			timer.schedule(time.Now().Add(10*time.Millisecond), fire("first"))
+			Eventually(fired).Should(Receive(Equal("first")))
+
+			timer.schedule(time.Now().Add(10*time.Millisecond), fire("second"))
+			Eventually(fired).Should(Receive(Equal("second")))
+		})
+	})
+})
@@ -8,6 +8,8 @@ package controller
 import (
 	"context"
 	"strconv"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/Azure/go-autorest/autorest/to"
@@ -17,8 +19,10 @@ import (
 
 	"github.com/Azure/application-gateway-kubernetes-ingress/pkg/appgw"
 	"github.com/Azure/application-gateway-kubernetes-ingress/pkg/azure"
+	"github.com/Azure/application-gateway-kubernetes-ingress/pkg/confighistory"
 	"github.com/Azure/application-gateway-kubernetes-ingress/pkg/environment"
 	"github.com/Azure/application-gateway-kubernetes-ingress/pkg/events"
+	"github.com/Azure/application-gateway-kubernetes-ingress/pkg/health"
 	"github.com/Azure/application-gateway-kubernetes-ingress/pkg/k8scontext"
 	"github.com/Azure/application-gateway-kubernetes-ingress/pkg/metricstore"
 	"github.com/Azure/application-gateway-kubernetes-ingress/pkg/worker"
@@ -28,8 +32,10 @@ import (
 type AppGwIngressController struct {
 	azClient        azure.AzClient
 	appGwIdentifier appgw.Identifier
-	ipAddressMap    map[string]k8scontext.IPAddress
-	cniReconciler   CniReconciler
+	// gateway is the Application Gateway of APPGW_GATEWAYS the controller manages; nil when AGIC manages a single one.
+	gateway       *environment.Gateway
+	ipAddressMap  map[string]k8scontext.IPAddress
+	cniReconciler CniReconciler
 
 	k8sContext       *k8scontext.Context
 	worker           *worker.Worker
@@ -42,6 +48,48 @@ type AppGwIngressController struct {
 	agicPod     *v1.Pod
 	MetricStore metricstore.MetricStore
 
+	// isLeader is true while this replica is allowed to mutate App Gateway.
+	// It is a pointer because most of the controller's methods have value receivers.
+	isLeader           *atomic.Bool
+	leaderElectionDone chan struct{}
+
+	// lastPlan holds the plan computed by the most recent dry run.
+	lastPlan *atomic.Pointer[Plan]
+
+	// history keeps the configs applied to App Gateway; nil unless the config history is enabled.
+	history *confighistory.Store
+
+	// paused is set while reconciliation is paused after a rollback.
+	paused *atomic.Pointer[confighistory.Pause]
+
+	// applyLock serializes the event loop and rollbacks, which both update App Gateway.
+	applyLock *sync.Mutex
+
+	// excludedIngresses holds the Ingresses whose config failed to provision, by key; Guarded by applyLock.
+	excludedIngresses map[string]excludedIngress
+
+	// appliedIngresses holds the generation of every Ingress in the last applied config, by key; Guarded by applyLock.
+	appliedIngresses map[string]int64
+
+	// watchdog tells the liveness probe whether the worker, the ARM operations and the informers make progress.
+	watchdog *health.Watchdog
+
+	// ctx is the parent of every ARM operation; Stop cancels it to abandon the operations still in flight after the shutdown grace period.
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	// leaderTerm holds the context of the current leader election term, which losing the Lease cancels; See armContext.
+	leaderTerm *atomic.Pointer[leaderTerm]
+
+	// backendPools holds the backend pools of the App Gateways the readiness gates of pods wait for.
+	backendPools *BackendPools
+
+	// drainingTimer reconciles once terminating endpoints kept in backend pools for connection draining must be removed.
+	drainingTimer *drainingTimer
+
+	// shutdownGracePeriod is how long Stop waits for the event in progress, e.g. a deployment, to complete.
+	shutdownGracePeriod time.Duration
+
 	stopChannel chan struct{}
 }
 
@@ -51,29 +99,74 @@ type CniReconciler interface {
 
 // NewAppGwIngressController constructs a controller object.
 func NewAppGwIngressController(azClient azure.AzClient, appGwIdentifier appgw.Identifier, k8sContext *k8scontext.Context, recorder record.EventRecorder, metricStore metricstore.MetricStore, cniReconciler CniReconciler, agicPod *v1.Pod, hostedOnUnderlay bool) *AppGwIngressController {
+	watchdog := health.NewWatchdog(health.DefaultWatchdogConfig())
+	ctx, cancel := context.WithCancel(context.Background())
 	controller := &AppGwIngressController{
-		azClient:         azClient,
-		appGwIdentifier:  appGwIdentifier,
-		k8sContext:       k8sContext,
-		recorder:         recorder,
-		cniReconciler:    cniReconciler,
-		configCache:      to.ByteSlicePtr([]byte{}),
-		ipAddressMap:     map[string]k8scontext.IPAddress{},
-		stopChannel:      make(chan struct{}),
-		agicPod:          agicPod,
-		MetricStore:      metricStore,
-		hostedOnUnderlay: hostedOnUnderlay,
+		azClient:          watchedAzClient{AzClient: azClient, watchdog: watchdog},
+		appGwIdentifier:   appGwIdentifier,
+		k8sContext:        k8sContext,
+		recorder:          recorder,
+		cniReconciler:     cniReconciler,
+		configCache:       to.ByteSlicePtr([]byte{}),
+		ipAddressMap:      map[string]k8scontext.IPAddress{},
+		stopChannel:       make(chan struct{}),
+		agicPod:           agicPod,
+		MetricStore:       metricStore,
+		hostedOnUnderlay:  hostedOnUnderlay,
+		isLeader:          &atomic.Bool{},
+		leaderTerm:        &atomic.Pointer[leaderTerm]{},
+		lastPlan:          &atomic.Pointer[Plan]{},
+		paused:            &atomic.Pointer[confighistory.Pause]{},
+		applyLock:         &sync.Mutex{},
+		excludedIngresses: map[string]excludedIngress{},
+		appliedIngresses:  map[string]int64{},
+		watchdog:          watchdog,
+		drainingTimer:     &drainingTimer{},
+		backendPools:      NewBackendPools(),
+		ctx:               ctx,
+		cancel:            cancel,
+
+		shutdownGracePeriod: defaultShutdownGracePeriod,
 	}
+	watchdog.WatchInformers(controller.informersSynced)
+	controller.backendPools.register(appGwIdentifier)
 
 	controller.worker = &worker.Worker{
 		EventProcessor: controller,
+		MetricStore:    metricStore,
+		Watchdog:       watchdog,
 	}
 	return controller
 }
 
+// ManageGateway binds the controller to one of the Application Gateways of APPGW_GATEWAYS; It must be called before Start.
+func (c *AppGwIngressController) ManageGateway(gateway environment.Gateway) {
+	c.gateway = &gateway
+}
+
+// IngressClass returns the IngressClass bound to the App Gateway of the controller; It is empty when AGIC manages a single App Gateway.
+func (c *AppGwIngressController) IngressClass() string {
+	if c.gateway == nil {
+		return ""
+	}
+	return c.gateway.IngressClass
+}
+
+// envVariables returns the environment variables narrowed down to the App Gateway of the controller.
+func (c AppGwIngressController) envVariables() environment.EnvVariables {
+	env := environment.GetEnv()
+	if c.gateway != nil {
+		env = env.ForGateway(*c.gateway)
+	}
+	return env
+}
+
 // Start function runs the k8scontext and continues to listen to the
 // event channel and enqueue events before stopChannel is closed
 func (c *AppGwIngressController) Start(envVariables environment.EnvVariables) error {
+	c.watchdog.SetConfig(health.NewWatchdogConfig(envVariables))
+	c.shutdownGracePeriod = newShutdownGracePeriod(envVariables)
+
 	// Starts k8scontext which contains all the informers
 	// This will start individual go routines for informers
 	if err := c.k8sContext.Run(c.stopChannel, false, envVariables); err != nil {
@@ -81,27 +174,50 @@ func (c *AppGwIngressController) Start(envVariables environment.EnvVariables) er
 		return err
 	}
 
+	if envVariables.EnableConfigHistory {
+		c.startConfigHistory(envVariables)
+	}
+
 	// initilize reconcilerTickerTask
 	if envVariables.ReconcilePeriodSeconds != "" {
 		go reconcilerTickerTask(c.k8sContext.Work, c.stopChannel, envVariables.ReconcilePeriodSeconds)
 	}
 
-	// Starts Worker processing events from k8sContext
+	// Starts Worker processing events from k8sContext.
+	// The worker runs on every replica so that the informers never block on a full work channel;
+	// ShouldProcess discards the events while this replica is not the leader.
+	c.worker.Config = worker.NewConfig(envVariables)
 	go c.worker.Run(c.k8sContext.Work, c.stopChannel)
 
+	if envVariables.EnableLeaderElection {
+		c.leaderElectionDone = make(chan struct{})
+		go c.runLeaderElection(envVariables)
+	} else {
+		c.setLeader(true)
+	}
+
 	return nil
 }
 
-// Stop function terminates the k8scontext and signal the stopchannel
+// Stop function terminates the k8scontext and signal the stopchannel.
+// It waits up to the shutdown grace period for the event in progress to complete, before abandoning its ARM operations.
 func (c *AppGwIngressController) Stop() {
-	c.MetricStore.Stop()
 	close(c.stopChannel)
+	c.finishInFlightWork(c.shutdownGracePeriod)
+	if c.leaderElectionDone != nil {
+		// Give the leader election a chance to release the Lease so that a standby replica can take over right away.
+		select {
+		case <-c.leaderElectionDone:
+		case <-time.After(leaseReleaseTimeout):
+			klog.Warning("Timed out waiting for leader election to release the Lease")
+		}
+	}
+	c.MetricStore.Stop()
 }
 
-// Liveness fulfills the health.HealthProbe interface; It is evaluated when K8s liveness-checks the AGIC pod.
-func (c *AppGwIngressController) Liveness() bool {
-	// TODO(draychev): implement
-	return true
+// IsLeader tells whether this replica is currently allowed to mutate App Gateway.
+func (c *AppGwIngressController) IsLeader() bool {
+	return c.isLeader.Load()
 }
 
 // Readiness fulfills the health.HealthProbe interface; It is evaluated when K8s readiness-checks the AGIC pod.
@@ -119,46 +235,83 @@ func (c *AppGwIngressController) Readiness() bool {
 func (c *AppGwIngressController) ProcessEvent(event events.Event) error {
 	processEventStart := time.Now()
 
-	err := c.cniReconciler.Reconcile(context.Background())
-	if err != nil {
-		// Not treated as fatal errors, but we log them and emit a warning event.
-		if c.agicPod != nil {
-			c.recorder.Event(c.agicPod, v1.EventTypeWarning, events.ReasonFailedCNIConfiguration, err.Error())
-		}
-		klog.Warning(err)
-	}
+	c.applyLock.Lock()
+	defer c.applyLock.Unlock()
 
-	appGw, cbCtx, err := c.GetAppGw()
-	if err != nil {
-		klog.Error("Error Retrieving AppGw for k8s event. ", err)
-		return err
+	// Stop lets the event in progress complete; Events still queued are left to the next AGIC pod.
+	select {
+	case <-c.stopChannel:
+		klog.V(3).Info("Skipping event; AGIC is shutting down")
+		return nil
+	default:
 	}
 
-	// Reset all ingress Ips and ignore mutating appgw if gateway is in stopped state
-	if !c.isApplicationGatewayMutable(appGw) {
-		klog.Info("Reset all ingress ip")
-		c.ResetAllIngress(appGw, cbCtx)
-		klog.Info("Ignore mutating App Gateway as it is not mutable")
+	// A rollback may have paused reconciliation after this event was queued.
+	if pause := c.Paused(); pause != nil {
+		klog.V(3).Infof("Skipping event; Reconciliation is paused since the rollback to revision %d", pause.Revision)
 		return nil
 	}
 
-	if err := c.MutateAllIngress(appGw, cbCtx); err != nil {
-		klog.Error("Error mutating AKS from k8s event. ", err)
+	// A dry run leaves the cluster untouched as well as App Gateway.
+	dryRun := c.envVariables().EnableDryRun
+
+	if !dryRun {
+		if err := c.cniReconciler.Reconcile(c.armContext()); err != nil {
+			// Not treated as fatal errors, but we log them and emit a warning event.
+			if c.agicPod != nil {
+				c.recorder.Event(c.agicPod, v1.EventTypeWarning, events.ReasonFailedCNIConfiguration, err.Error())
+			}
+			klog.Warning(err)
+		}
 	}
 
-	if err := c.MutateAppGateway(event, appGw, cbCtx); err != nil {
-		klogIt := klog.Errorf
-		if cbCtx.EnvVariables.EnablePanicOnPutError {
-			klogIt = klog.Fatalf
+	// App Gateway may be modified between reading it and deploying the config generated from it; Start over from a fresh read then.
+	for attempt := 1; ; attempt++ {
+		appGw, cbCtx, err := c.GetAppGw()
+		if err != nil {
+			klog.Error("Error Retrieving AppGw for k8s event. ", err)
+			return err
 		}
-		if c.agicPod != nil {
-			c.recorder.Event(c.agicPod, v1.EventTypeWarning, events.ReasonFailedApplyingAppGwConfig, err.Error())
+
+		// Reset all ingress Ips and ignore mutating appgw if gateway is in stopped state
+		if !c.isApplicationGatewayMutable(appGw) {
+			if dryRun {
+				klog.Info("[dry-run] App Gateway is not mutable; Skipping plan")
+				return nil
+			}
+			klog.Info("Reset all ingress ip")
+			c.ResetAllIngress(appGw, cbCtx)
+			klog.Info("Ignore mutating App Gateway as it is not mutable")
+			return nil
 		}
-		klogIt(err.Error())
-		c.MetricStore.IncArmAPIUpdateCallFailureCounter()
-		return err
+
+		if !dryRun {
+			if err := c.MutateAllIngress(appGw, cbCtx); err != nil {
+				klog.Error("Error mutating AKS from k8s event. ", err)
+			}
+		}
+
+		err = c.MutateAppGateway(event, appGw, cbCtx)
+		if c.retryConcurrentUpdate(err, attempt) {
+			continue
+		}
+		if err != nil {
+			klogIt := klog.Errorf
+			if cbCtx.EnvVariables.EnablePanicOnPutError {
+				klogIt = klog.Fatalf
+			}
+			if c.agicPod != nil {
+				c.recorder.Event(c.agicPod, v1.EventTypeWarning, events.ReasonFailedApplyingAppGwConfig, err.Error())
+			}
+			klogIt(err.Error())
+			c.MetricStore.IncArmAPIUpdateCallFailureCounter()
+			return err
+		}
+		if !dryRun {
+			c.MetricStore.IncArmAPIUpdateCallSuccessCounter()
+		}
+		break
 	}
-	c.MetricStore.IncArmAPIUpdateCallSuccessCounter()
 
 	duration := time.Since(processEventStart)
 	c.MetricStore.SetUpdateLatencySec(duration)
@@ -169,7 +322,7 @@ func (c *AppGwIngressController) ProcessEvent(event events.Event) error {
 	return nil
 }
 
-func reconcilerTickerTask(work chan events.Event, stopChannel chan struct{}, reconcilePeriodSecondsStr string) {
+func reconcilerTickerTask(work chan events.Event, stopChannel <-chan struct{}, reconcilePeriodSecondsStr string) {
 	klog.V(3).Info("Reconciler Ticker task started with period: ", reconcilePeriodSecondsStr)
 
 	reconcilePeriodSeconds, _ := strconv.Atoi(reconcilePeriodSecondsStr)
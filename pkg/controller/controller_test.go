@@ -6,15 +6,27 @@
 package controller
 
 import (
+	"encoding/json"
+	"strings"
 	"time"
 
+	n "github.com/Azure/azure-sdk-for-go/services/network/mgmt/2021-03-01/network"
+	"github.com/Azure/go-autorest/autorest/to"
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
+	v1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	testclient "k8s.io/client-go/kubernetes/fake"
 	"k8s.io/client-go/tools/record"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+	gateway_fake "sigs.k8s.io/gateway-api/pkg/client/clientset/versioned/fake"
 
 	"github.com/Azure/application-gateway-kubernetes-ingress/pkg/appgw"
 	"github.com/Azure/application-gateway-kubernetes-ingress/pkg/azure"
+	"github.com/Azure/application-gateway-kubernetes-ingress/pkg/configdiff"
+	"github.com/Azure/application-gateway-kubernetes-ingress/pkg/confighistory"
+	"github.com/Azure/application-gateway-kubernetes-ingress/pkg/controllererrors"
 	"github.com/Azure/application-gateway-kubernetes-ingress/pkg/crd_client/agic_crd_client/clientset/versioned/fake"
 	multiClusterFake "github.com/Azure/application-gateway-kubernetes-ingress/pkg/crd_client/azure_multicluster_crd_client/clientset/versioned/fake"
 	istioFake "github.com/Azure/application-gateway-kubernetes-ingress/pkg/crd_client/istio_crd_client/clientset/versioned/fake"
@@ -22,6 +34,7 @@ import (
 	"github.com/Azure/application-gateway-kubernetes-ingress/pkg/events"
 	"github.com/Azure/application-gateway-kubernetes-ingress/pkg/k8scontext"
 	"github.com/Azure/application-gateway-kubernetes-ingress/pkg/metricstore"
+	"github.com/Azure/application-gateway-kubernetes-ingress/pkg/tests/fixtures"
 	"github.com/Azure/application-gateway-kubernetes-ingress/pkg/worker"
 )
 
@@ -51,7 +64,7 @@ var _ = Describe("test NewAppGwIngressController", func() {
 			multiClusterCrdClient := multiClusterFake.NewSimpleClientset()
 			// Create a `k8scontext` to start listening to ingress resources.
 			k8scontext.IsNetworkingV1PackageSupported = true
-			k8sContext := k8scontext.NewContext(k8sClient, crdClient, multiClusterCrdClient, istioCrdClient, []string{}, 1000*time.Second, metricstore.NewFakeMetricStore(), environment.GetFakeEnv())
+			k8sContext := k8scontext.NewContext(k8sClient, crdClient, multiClusterCrdClient, istioCrdClient, gateway_fake.NewSimpleClientset(), []string{}, 1000*time.Second, metricstore.NewFakeMetricStore(), environment.GetFakeEnv())
 
 			azClient := azure.NewFakeAzClient()
 			appGwIdentifier := appgw.Identifier{}
@@ -79,6 +92,8 @@ var _ = Describe("test NewAppGwIngressController", func() {
 
 			env := environment.GetFakeEnv()
 			env.ReconcilePeriodSeconds = "1"
+			// Ticks every second would keep pushing back the default debounce of one second.
+			env.EventDebounceDuration = "10ms"
 			err := controller.Start(env)
 			Expect(err).To(BeNil())
 
@@ -95,4 +110,281 @@ var _ = Describe("test NewAppGwIngressController", func() {
 			Expect(processCalled).To(Equal(true), "Reconciler didn't tick in the expected time.")
 		})
 	})
+
+	Context("Verify that leader election works", func() {
+		var k8sClient *testclient.Clientset
+
+		newController := func() *AppGwIngressController {
+			k8sContext := k8scontext.NewContext(k8sClient, fake.NewSimpleClientset(), multiClusterFake.NewSimpleClientset(), istioFake.NewSimpleClientset(), gateway_fake.NewSimpleClientset(), []string{}, 1000*time.Second, metricstore.NewFakeMetricStore(), environment.GetFakeEnv())
+			controller := NewAppGwIngressController(azure.NewFakeAzClient(), appgw.Identifier{}, k8sContext, record.NewFakeRecorder(100), metricstore.NewFakeMetricStore(), nil, nil, false)
+			controller.worker = &worker.Worker{
+				EventProcessor: worker.NewFakeProcessor(func(event events.Event) error { return nil }),
+			}
+			return controller
+		}
+
+		startController := func(controller *AppGwIngressController, podName string) {
+			env := environment.GetFakeEnv()
+			env.EnableLeaderElection = true
+			env.LeaderElectionLeaseName = environment.DefaultLeaderElectionLeaseName
+			env.AGICPodName = podName
+			env.AGICPodNamespace = "agic"
+			Expect(controller.Start(env)).To(BeNil())
+		}
+
+		BeforeEach(func() {
+			k8scontext.IsNetworkingV1PackageSupported = true
+			k8sClient = testclient.NewSimpleClientset()
+		})
+
+		It("should not process events until the controller is elected as the leader", func() {
+			controller := newController()
+			Expect(controller.IsLeader()).To(BeFalse())
+			shouldProcess, reason := controller.ShouldProcess(events.Event{Type: events.Create})
+			Expect(shouldProcess).To(BeFalse())
+			Expect(reason).To(BeNil())
+
+			startController(controller, "agic-1")
+			defer controller.Stop()
+			Eventually(controller.IsLeader, 5*time.Second, 100*time.Millisecond).Should(BeTrue())
+		})
+
+		It("should let a standby replica take over once the leader stops", func() {
+			leader := newController()
+			startController(leader, "agic-1")
+			Eventually(leader.IsLeader, 5*time.Second, 100*time.Millisecond).Should(BeTrue())
+
+			standby := newController()
+			startController(standby, "agic-2")
+			defer standby.Stop()
+			Consistently(standby.IsLeader, 3*time.Second, 100*time.Millisecond).Should(BeFalse())
+
+			leader.Stop()
+			Expect(leader.IsLeader()).To(BeFalse())
+			Eventually(standby.IsLeader, 10*time.Second, 100*time.Millisecond).Should(BeTrue())
+		})
+	})
+
+	Context("Verify that dry run works", func() {
+		var controller *AppGwIngressController
+		var updateGatewayCalled bool
+
+		BeforeEach(func() {
+			k8scontext.IsNetworkingV1PackageSupported = true
+			k8sContext := k8scontext.NewContext(testclient.NewSimpleClientset(), fake.NewSimpleClientset(), multiClusterFake.NewSimpleClientset(), istioFake.NewSimpleClientset(), gateway_fake.NewSimpleClientset(), []string{}, 1000*time.Second, metricstore.NewFakeMetricStore(), environment.GetFakeEnv())
+
+			updateGatewayCalled = false
+			azClient := azure.NewFakeAzClient()
+			azClient.GetGatewayFunc = func() (n.ApplicationGateway, error) {
+				appGw := fixtures.GetAppGateway()
+				appGw.FrontendPorts = &[]n.ApplicationGatewayFrontendPort{}
+				return appGw, nil
+			}
+			azClient.UpdateGatewayFunc = func(*n.ApplicationGateway) error {
+				updateGatewayCalled = true
+				return nil
+			}
+
+			appGwIdentifier := appgw.Identifier{AppGwName: "--AppGwName--"}
+			controller = NewAppGwIngressController(azClient, appGwIdentifier, k8sContext, record.NewFakeRecorder(100), metricstore.NewFakeMetricStore(), nil, nil, false)
+		})
+
+		It("should report the changes when applying the generated config", func() {
+			recorder := record.NewFakeRecorder(100)
+			controller.recorder = recorder
+			controller.agicPod = &v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "agic", Namespace: "agic"}}
+
+			appGw, cbCtx, err := controller.GetAppGw()
+			Expect(err).ToNot(HaveOccurred())
+
+			err = controller.MutateAppGateway(events.Event{Type: events.PeriodicReconcile}, appGw, cbCtx)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(updateGatewayCalled).To(BeTrue())
+			Expect(controller.LastPlan()).To(BeNil())
+
+			var applied []string
+			for len(recorder.Events) > 0 {
+				if event := <-recorder.Events; strings.Contains(event, events.ReasonAppliedAppGwConfig) {
+					applied = append(applied, event)
+				}
+			}
+			Expect(applied).To(HaveLen(1))
+			Expect(applied[0]).To(ContainSubstring("httpListeners: +"))
+		})
+
+		It("should record a plan instead of updating App Gateway", func() {
+			Expect(controller.LastPlan()).To(BeNil())
+
+			appGw, cbCtx, err := controller.GetAppGw()
+			Expect(err).ToNot(HaveOccurred())
+			cbCtx.EnvVariables.EnableDryRun = true
+
+			err = controller.MutateAppGateway(events.Event{Type: events.PeriodicReconcile}, appGw, cbCtx)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(updateGatewayCalled).To(BeFalse())
+
+			plan := controller.LastPlan()
+			Expect(plan).ToNot(BeNil())
+			Expect(plan.AppGwName).To(Equal("--AppGwName--"))
+			Expect(plan.Diff.Changes).To(ContainElement(configdiff.Change{
+				Kind:   configdiff.HTTPListeners,
+				Name:   *fixtures.GetListenerBasic().Name,
+				Action: configdiff.Removed,
+			}))
+		})
+	})
+
+	Context("Verify that rollback works", func() {
+		var controller *AppGwIngressController
+		var updatedAppGws []*n.ApplicationGateway
+
+		BeforeEach(func() {
+			k8scontext.IsNetworkingV1PackageSupported = true
+			k8sContext := k8scontext.NewContext(testclient.NewSimpleClientset(), fake.NewSimpleClientset(), multiClusterFake.NewSimpleClientset(), istioFake.NewSimpleClientset(), gateway_fake.NewSimpleClientset(), []string{}, 1000*time.Second, metricstore.NewFakeMetricStore(), environment.GetFakeEnv())
+
+			updatedAppGws = nil
+			azClient := azure.NewFakeAzClient()
+			azClient.GetGatewayFunc = func() (n.ApplicationGateway, error) {
+				appGw := fixtures.GetAppGateway()
+				appGw.FrontendPorts = &[]n.ApplicationGatewayFrontendPort{}
+				return appGw, nil
+			}
+			azClient.UpdateGatewayFunc = func(appGw *n.ApplicationGateway) error {
+				updatedAppGws = append(updatedAppGws, appGw)
+				return nil
+			}
+
+			controller = NewAppGwIngressController(azClient, appgw.Identifier{AppGwName: "--AppGwName--"}, k8sContext, record.NewFakeRecorder(100), metricstore.NewFakeMetricStore(), nil, nil, false)
+			controller.history = confighistory.NewStore(k8sContext.ConfigMaps("agic"), environment.DefaultConfigHistoryConfigMapName, 5)
+			controller.setLeader(true)
+		})
+
+		It("should re-apply a revision and pause reconciliation until resumed", func() {
+			appGw, cbCtx, err := controller.GetAppGw()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(controller.MutateAppGateway(events.Event{Type: events.PeriodicReconcile}, appGw, cbCtx)).To(Succeed())
+
+			revisions, err := controller.ConfigRevisions()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(revisions).To(HaveLen(1))
+			Expect(revisions[0].Summary).To(ContainSubstring("httpListeners: +"))
+
+			_, _, err = controller.history.Record([]byte(`{"properties":{}}`), "applied by a later event")
+			Expect(err).ToNot(HaveOccurred())
+
+			_, err = controller.Rollback(42, "")
+			Expect(controllererrors.IsErrorCode(err, controllererrors.ErrorConfigRevisionNotFound)).To(BeTrue())
+
+			revision, err := controller.Rollback(1, "bad deploy")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(revision.Number).To(Equal(3))
+			Expect(revision.Summary).To(Equal("Rollback to revision 1"))
+			Expect(updatedAppGws).To(HaveLen(2))
+			applied, err := configdiff.NewSnapshot(updatedAppGws[0])
+			Expect(err).ToNot(HaveOccurred())
+			rolledBack, err := configdiff.NewSnapshot(updatedAppGws[1])
+			Expect(err).ToNot(HaveOccurred())
+			Expect(configdiff.Compare(applied, rolledBack).HasChanges()).To(BeFalse())
+			Expect(updatedAppGws[1].SslCertificates).ToNot(BeNil())
+
+			Expect(controller.Paused().Revision).To(Equal(1))
+			shouldProcess, _ := controller.ShouldProcess(events.Event{Type: events.Create})
+			Expect(shouldProcess).To(BeFalse())
+			Expect(controller.ProcessEvent(events.Event{Type: events.Create})).To(Succeed())
+			Expect(updatedAppGws).To(HaveLen(2))
+
+			Expect(controller.Resume()).To(Succeed())
+			Expect(controller.Paused()).To(BeNil())
+			Expect(<-controller.k8sContext.Work).To(Equal(events.Event{Type: events.PeriodicReconcile}))
+		})
+
+		It("should refuse to roll back to a revision whose certificates were removed", func() {
+			retired := fixtures.GetAppGateway()
+			listener := fixtures.GetDefaultListener()
+			listener.SslCertificate = &n.SubResource{ID: to.StringPtr("/subscriptions/sub/resourceGroups/rg/providers/Microsoft.Network/applicationGateways/appgw/sslCertificates/retired-cert")}
+			retired.HTTPListeners = &[]n.ApplicationGatewayHTTPListener{*listener}
+			config, err := json.Marshal(retired)
+			Expect(err).ToNot(HaveOccurred())
+			_, _, err = controller.history.Record(config, "applied before the certificate was removed")
+			Expect(err).ToNot(HaveOccurred())
+
+			_, err = controller.Rollback(1, "")
+			Expect(controllererrors.IsErrorCode(err, controllererrors.ErrorRollingBackAppGatewayConfig)).To(BeTrue())
+			Expect(err.Error()).To(ContainSubstring("retired-cert"))
+			Expect(updatedAppGws).To(BeEmpty())
+			Expect(controller.Paused()).To(BeNil())
+		})
+
+		It("should refuse to roll back on a standby replica", func() {
+			controller.setLeader(false)
+			_, err := controller.Rollback(1, "")
+			Expect(controllererrors.IsErrorCode(err, controllererrors.ErrorNotLeader)).To(BeTrue())
+		})
+	})
+
+	Context("Verify that ShouldProcess keeps the events of Services referenced by HTTPRoutes", func() {
+		var controller *AppGwIngressController
+		var k8sContext *k8scontext.Context
+
+		newContext := func(enableGatewayAPI bool) {
+			env := environment.GetFakeEnv()
+			env.EnableGatewayAPI = enableGatewayAPI
+			k8scontext.IsNetworkingV1PackageSupported = true
+			k8sContext = k8scontext.NewContext(testclient.NewSimpleClientset(), fake.NewSimpleClientset(), multiClusterFake.NewSimpleClientset(), istioFake.NewSimpleClientset(), gateway_fake.NewSimpleClientset(), []string{}, 1000*time.Second, metricstore.NewFakeMetricStore(), env)
+			controller = NewAppGwIngressController(azure.NewFakeAzClient(), appgw.Identifier{}, k8sContext, record.NewFakeRecorder(100), metricstore.NewFakeMetricStore(), nil, nil, false)
+			controller.setLeader(true)
+
+			service := &v1.Service{
+				ObjectMeta: metav1.ObjectMeta{Name: "cart", Namespace: "shop"},
+				Spec: v1.ServiceSpec{
+					Selector: map[string]string{"app": "cart"},
+					Ports:    []v1.ServicePort{{Port: 80, Protocol: v1.ProtocolTCP}},
+				},
+			}
+			Expect(k8sContext.Caches.Service.Add(service)).To(Succeed())
+
+			// The route lives in another namespace than the Service.
+			namespace := gatewayv1.Namespace("shop")
+			route := &gatewayv1.HTTPRoute{
+				ObjectMeta: metav1.ObjectMeta{Name: "storefront", Namespace: "web"},
+				Spec: gatewayv1.HTTPRouteSpec{
+					Rules: []gatewayv1.HTTPRouteRule{{
+						BackendRefs: []gatewayv1.HTTPBackendRef{{
+							BackendRef: gatewayv1.BackendRef{
+								BackendObjectReference: gatewayv1.BackendObjectReference{Name: "cart", Namespace: &namespace},
+							},
+						}},
+					}},
+				},
+			}
+			Expect(k8sContext.Caches.HTTPRoute.Add(route)).To(Succeed())
+		}
+
+		endpointSlice := &discoveryv1.EndpointSlice{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "cart-abcde",
+				Namespace: "shop",
+				Labels:    map[string]string{discoveryv1.LabelServiceName: "cart"},
+			},
+		}
+		pod := &v1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "cart-0", Namespace: "shop", Labels: map[string]string{"app": "cart"}},
+		}
+
+		It("should process EndpointSlice and Pod events of a Service referenced only by an HTTPRoute", func() {
+			newContext(true)
+			shouldProcess, _ := controller.ShouldProcess(events.Event{Type: events.Update, Value: endpointSlice})
+			Expect(shouldProcess).To(BeTrue())
+			shouldProcess, _ = controller.ShouldProcess(events.Event{Type: events.Update, Value: pod})
+			Expect(shouldProcess).To(BeTrue())
+		})
+
+		It("should skip them when the Gateway API is not enabled", func() {
+			newContext(false)
+			shouldProcess, _ := controller.ShouldProcess(events.Event{Type: events.Update, Value: endpointSlice})
+			Expect(shouldProcess).To(BeFalse())
+			shouldProcess, _ = controller.ShouldProcess(events.Event{Type: events.Update, Value: pod})
+			Expect(shouldProcess).To(BeFalse())
+		})
+	})
 })
@@ -0,0 +1,76 @@
+// -------------------------------------------------------------------------------------------
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License. See License.txt in the project root for license information.
+// --------------------------------------------------------------------------------------------
+
+package controller
+
+import (
+	"reflect"
+
+	n "github.com/Azure/azure-sdk-for-go/services/network/mgmt/2021-03-01/network"
+	"k8s.io/klog/v2"
+
+	"github.com/Azure/application-gateway-kubernetes-ingress/pkg/appgw"
+)
+
+// updateGatewayAPIStatuses reports the conditions of the GatewayClasses, Gateways and HTTPRoutes AGIC translated into the config of App Gateway.
+// It must only be called once App Gateway runs the config generated from cbCtx.
+// Resources are only updated when their status changed, so that periodic reconciles do not cause writes.
+func (c AppGwIngressController) updateGatewayAPIStatuses(appGw *n.ApplicationGateway, cbCtx *appgw.ConfigBuilderContext) {
+	if !cbCtx.EnvVariables.EnableGatewayAPI {
+		return
+	}
+
+	for _, gatewayClass := range c.k8sContext.ListGatewayClasses() {
+		status := cbCtx.GatewayAPIStatus.GatewayClassStatus(gatewayClass)
+		if reflect.DeepEqual(status, gatewayClass.Status) {
+			continue
+		}
+		updated := gatewayClass.DeepCopy()
+		updated.Status = status
+		if err := c.k8sContext.UpdateGatewayClassStatus(updated); err != nil {
+			klog.Warning(err)
+		}
+	}
+
+	address := ""
+	if len(cbCtx.GatewayAPIGateways) > 0 {
+		address = c.gatewayAPIAddress(appGw, cbCtx)
+	}
+	for _, gateway := range cbCtx.GatewayAPIGateways {
+		status := cbCtx.GatewayAPIStatus.GatewayStatus(gateway, address)
+		if reflect.DeepEqual(status, gateway.Status) {
+			continue
+		}
+		updated := gateway.DeepCopy()
+		updated.Status = status
+		if err := c.k8sContext.UpdateGatewayStatus(updated); err != nil {
+			klog.Warning(err)
+		}
+	}
+
+	for _, route := range cbCtx.HTTPRoutes {
+		status := cbCtx.GatewayAPIStatus.RouteStatus(route)
+		if reflect.DeepEqual(status, route.Status) || (len(status.Parents) == 0 && len(route.Status.Parents) == 0) {
+			continue
+		}
+		updated := route.DeepCopy()
+		updated.Status = status
+		if err := c.k8sContext.UpdateHTTPRouteStatus(updated); err != nil {
+			klog.Warning(err)
+		}
+	}
+}
+
+// gatewayAPIAddress returns the IP address of the frontend the listeners of Gateways are created on.
+func (c AppGwIngressController) gatewayAPIAddress(appGw *n.ApplicationGateway, cbCtx *appgw.ConfigBuilderContext) string {
+	ipConf := appgw.LookupIPConfigurationByType(appGw.FrontendIPConfigurations, appgw.FrontendTypePublic)
+	if cbCtx.EnvVariables.UsePrivateIP || ipConf == nil {
+		ipConf = appgw.LookupIPConfigurationByType(appGw.FrontendIPConfigurations, appgw.FrontendTypePrivate)
+	}
+	if ipConf == nil {
+		return ""
+	}
+	return string(getIPsFromAppGateway(c.armContext(), appGw, c.azClient)[ipResource(*ipConf.ID)])
+}
@@ -89,22 +89,13 @@ func resetBackReference(appGw *n.ApplicationGateway) *n.ApplicationGateway {
 }
 
 func dumpSanitizedJSON(appGw *n.ApplicationGateway, logToFile bool, overwritePrefix *string) ([]byte, error) {
-	jsonConfig, err := appGw.MarshalJSON()
-	if err != nil {
-		return nil, err
-	}
-
 	prefix := "-- App Gwy config --"
 	if overwritePrefix != nil {
 		prefix = *overwritePrefix
 	}
 
-	// Remove sensitive data from the JSON config to be logged
-	keysToDelete := []string{
-		"sslCertificates",
-	}
-	var sanitized []byte
-	if sanitized, err = deleteKeyFromJSON(jsonConfig, keysToDelete...); err != nil {
+	sanitized, err := sanitizedJSON(appGw)
+	if err != nil {
 		return nil, err
 	}
 
@@ -120,6 +111,20 @@ func dumpSanitizedJSON(appGw *n.ApplicationGateway, logToFile bool, overwritePre
 	return prettyJSON, err
 }
 
+// sanitizedJSON marshals the App Gateway config without sensitive data, such as SSL certificates.
+func sanitizedJSON(appGw *n.ApplicationGateway) ([]byte, error) {
+	jsonConfig, err := appGw.MarshalJSON()
+	if err != nil {
+		return nil, err
+	}
+
+	// Remove sensitive data from the JSON config
+	keysToDelete := []string{
+		"sslCertificates",
+	}
+	return deleteKeyFromJSON(jsonConfig, keysToDelete...)
+}
+
 func (c *AppGwIngressController) isApplicationGatewayMutable(appGw *n.ApplicationGateway) bool {
 	return appGw.OperationalState == "Running" || appGw.OperationalState == "Starting"
 }
@@ -0,0 +1,57 @@
+// -------------------------------------------------------------------------------------------
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License. See License.txt in the project root for license information.
+// --------------------------------------------------------------------------------------------
+
+package controller
+
+import (
+	"fmt"
+
+	v1 "k8s.io/api/core/v1"
+	networking "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2"
+
+	"github.com/Azure/application-gateway-kubernetes-ingress/pkg/annotations"
+	"github.com/Azure/application-gateway-kubernetes-ingress/pkg/events"
+	"github.com/Azure/application-gateway-kubernetes-ingress/pkg/ingressstatus"
+)
+
+// updateIngressStatuses reports on each Ingress which of its rules are applied to App Gateway and which were pruned.
+// It must only be called once App Gateway runs the config generated from the given Ingresses.
+// Ingresses are only updated when their status changed, so that periodic reconciles do not cause writes.
+func (c AppGwIngressController) updateIngressStatuses(ingresses []*networking.Ingress, tracker *ingressstatus.Tracker) {
+	now := metav1.Now()
+	for _, ingress := range ingresses {
+		status := tracker.Status(ingress)
+
+		var previous ingressstatus.Status
+		if value, exists := ingress.Annotations[annotations.IngressStatusKey]; exists {
+			var err error
+			if previous, err = ingressstatus.Parse(value); err != nil {
+				klog.Warningf("Overwriting malformed annotation %s on Ingress %s/%s: %s", annotations.IngressStatusKey, ingress.Namespace, ingress.Name, err)
+			} else if status.IsUpToDate(previous) {
+				continue
+			}
+		}
+
+		status.LastAppliedTime = previous.LastAppliedTime
+		if len(status.AppliedRules) > 0 {
+			status.LastAppliedTime = &now
+		}
+
+		if err := c.k8sContext.UpdateIngressAnnotation(*ingress, annotations.IngressStatusKey, status.String()); err != nil {
+			// Usually the ClusterRole of AGIC lacks the patch permission on Ingresses.
+			klog.Warning(err)
+			c.recorder.Event(ingress, v1.EventTypeWarning, events.ReasonUnableToUpdateIngressStatus, err.Error())
+			continue
+		}
+
+		if len(status.AppliedRules) > 0 {
+			msg := fmt.Sprintf("Applied %d rules of Ingress %s/%s to Application Gateway %s; %d rules were pruned. See the %s annotation for details.",
+				len(status.AppliedRules), ingress.Namespace, ingress.Name, c.appGwIdentifier.AppGwName, len(status.PrunedRules), annotations.IngressStatusKey)
+			c.recorder.Event(ingress, v1.EventTypeNormal, events.ReasonAppliedIngress, msg)
+		}
+	}
+}
@@ -0,0 +1,78 @@
+// -------------------------------------------------------------------------------------------
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License. See License.txt in the project root for license information.
+// --------------------------------------------------------------------------------------------
+
+package controller
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	networking "k8s.io/api/networking/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	testclient "k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+	"k8s.io/client-go/tools/record"
+	gateway_fake "sigs.k8s.io/gateway-api/pkg/client/clientset/versioned/fake"
+
+	"github.com/Azure/application-gateway-kubernetes-ingress/pkg/annotations"
+	"github.com/Azure/application-gateway-kubernetes-ingress/pkg/appgw"
+	"github.com/Azure/application-gateway-kubernetes-ingress/pkg/azure"
+	"github.com/Azure/application-gateway-kubernetes-ingress/pkg/crd_client/agic_crd_client/clientset/versioned/fake"
+	multiClusterFake "github.com/Azure/application-gateway-kubernetes-ingress/pkg/crd_client/azure_multicluster_crd_client/clientset/versioned/fake"
+	istioFake "github.com/Azure/application-gateway-kubernetes-ingress/pkg/crd_client/istio_crd_client/clientset/versioned/fake"
+	"github.com/Azure/application-gateway-kubernetes-ingress/pkg/environment"
+	"github.com/Azure/application-gateway-kubernetes-ingress/pkg/events"
+	"github.com/Azure/application-gateway-kubernetes-ingress/pkg/ingressstatus"
+	"github.com/Azure/application-gateway-kubernetes-ingress/pkg/k8scontext"
+	"github.com/Azure/application-gateway-kubernetes-ingress/pkg/metricstore"
+	"github.com/Azure/application-gateway-kubernetes-ingress/pkg/tests/fixtures"
+)
+
+var _ = Describe("test ingress status annotation", func() {
+	var k8sClient *testclient.Clientset
+	var recorder *record.FakeRecorder
+	var controller *AppGwIngressController
+	var ingress *networking.Ingress
+
+	BeforeEach(func() {
+		k8sClient = testclient.NewSimpleClientset()
+		k8scontext.IsNetworkingV1PackageSupported = true
+		k8scontext.IsInMultiClusterMode = false
+		k8sContext := k8scontext.NewContext(k8sClient, fake.NewSimpleClientset(), multiClusterFake.NewSimpleClientset(), istioFake.NewSimpleClientset(), gateway_fake.NewSimpleClientset(), []string{}, 1000*time.Second, metricstore.NewFakeMetricStore(), environment.GetFakeEnv())
+		recorder = record.NewFakeRecorder(100)
+		controller = NewAppGwIngressController(azure.NewFakeAzClient(), appgw.Identifier{AppGwName: "--AppGwName--"}, k8sContext, recorder, metricstore.NewFakeMetricStore(), nil, nil, false)
+
+		ingress = fixtures.GetIngress()
+		_, err := k8sClient.NetworkingV1().Ingresses(ingress.Namespace).Create(context.TODO(), ingress, metav1.CreateOptions{})
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	It("writes the status annotation and reports the applied rules", func() {
+		controller.updateIngressStatuses([]*networking.Ingress{ingress}, ingressstatus.NewTracker())
+
+		updated, err := k8sClient.NetworkingV1().Ingresses(ingress.Namespace).Get(context.TODO(), ingress.Name, metav1.GetOptions{})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(updated.Annotations).To(HaveKey(annotations.IngressStatusKey))
+		Expect(recorder.Events).To(Receive(ContainSubstring(events.ReasonAppliedIngress)))
+	})
+
+	It("reports a Warning instead of the applied rules when the patch is Forbidden", func() {
+		k8sClient.PrependReactor("patch", "ingresses", func(action k8stesting.Action) (bool, runtime.Object, error) {
+			return true, nil, apierrors.NewForbidden(networking.Resource("ingresses"), ingress.Name, nil)
+		})
+
+		controller.updateIngressStatuses([]*networking.Ingress{ingress}, ingressstatus.NewTracker())
+
+		var event string
+		Expect(recorder.Events).To(Receive(&event))
+		Expect(event).To(ContainSubstring(events.ReasonUnableToUpdateIngressStatus))
+		Expect(event).To(ContainSubstring("forbidden"))
+		Expect(recorder.Events).ToNot(Receive())
+	})
+})
@@ -0,0 +1,150 @@
+// -------------------------------------------------------------------------------------------
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License. See License.txt in the project root for license information.
+// --------------------------------------------------------------------------------------------
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/tools/leaderelection"
+	"k8s.io/klog/v2"
+
+	"github.com/Azure/application-gateway-kubernetes-ingress/pkg/environment"
+	"github.com/Azure/application-gateway-kubernetes-ingress/pkg/events"
+)
+
+const (
+	// A standby replica takes over at most leaseDuration after the leader stopped renewing the Lease.
+	leaseDuration = 15 * time.Second
+	renewDeadline = 10 * time.Second
+	retryPeriod   = 2 * time.Second
+
+	leaseReleaseTimeout = 5 * time.Second
+)
+
+// leaderTerm is the time this replica holds the leader election Lease.
+type leaderTerm struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+// armContext returns the context to run ARM operations with. Stop cancels it, and so does losing the leader election Lease:
+// A standby replica may take over right away, so the ARM operations of the previous leader must not carry on.
+func (c *AppGwIngressController) armContext() context.Context {
+	if term := c.leaderTerm.Load(); term != nil {
+		return term.ctx
+	}
+	return c.ctx
+}
+
+// runLeaderElection campaigns for the leader election Lease until the controller is stopped.
+// Losing the Lease puts this replica back on standby, after which it campaigns again.
+func (c *AppGwIngressController) runLeaderElection(envVariables environment.EnvVariables) {
+	defer close(c.leaderElectionDone)
+
+	// The Lease is released only once Stop finished or abandoned the ARM operations in flight;
+	// Otherwise a standby replica could take over while a deployment is still in progress.
+	ctx, cancel := context.WithCancel(c.ctx)
+	defer cancel()
+
+	identity := getLeaderElectionIdentity(envVariables)
+	lock := c.k8sContext.NewLeaseLock(envVariables.AGICPodNamespace, envVariables.LeaderElectionLeaseName, identity)
+	klog.Infof("Leader election enabled; Campaigning for Lease %s/%s as %s", envVariables.AGICPodNamespace, envVariables.LeaderElectionLeaseName, identity)
+
+	for {
+		leaderelection.RunOrDie(ctx, leaderelection.LeaderElectionConfig{
+			Lock:            lock,
+			Name:            envVariables.LeaderElectionLeaseName,
+			LeaseDuration:   leaseDuration,
+			RenewDeadline:   renewDeadline,
+			RetryPeriod:     retryPeriod,
+			ReleaseOnCancel: true,
+			Callbacks: leaderelection.LeaderCallbacks{
+				OnStartedLeading: func(leaderCtx context.Context) {
+					c.onStartedLeading(leaderCtx, identity)
+				},
+				OnStoppedLeading: func() {
+					c.onStoppedLeading(identity, ctx.Err() != nil)
+				},
+				OnNewLeader: func(leader string) {
+					if leader != identity {
+						klog.Infof("AGIC replica %s is the leader; Staying on standby", leader)
+					}
+				},
+			},
+		})
+
+		if ctx.Err() != nil {
+			return
+		}
+	}
+}
+
+// onStartedLeading starts a leader term; leaderCtx is cancelled by client-go once the Lease is lost.
+func (c *AppGwIngressController) onStartedLeading(leaderCtx context.Context, identity string) {
+	termCtx, cancel := context.WithCancel(leaderCtx)
+	c.leaderTerm.Store(&leaderTerm{ctx: termCtx, cancel: cancel})
+	c.setLeader(true)
+
+	msg := fmt.Sprintf("AGIC replica %s acquired the leader election Lease and is now managing Application Gateway %s", identity, c.appGwIdentifier.AppGwName)
+	klog.Info(msg)
+	if c.agicPod != nil {
+		c.recorder.Event(c.agicPod, v1.EventTypeNormal, events.ReasonStartedLeading, msg)
+	}
+
+	// The previous leader may have rolled App Gateway back or resumed reconciliation.
+	c.loadPause()
+
+	// Changes observed while on standby were discarded; Reconcile the whole gateway right away.
+	c.k8sContext.Work <- events.Event{
+		Type: events.PeriodicReconcile,
+	}
+}
+
+func (c *AppGwIngressController) onStoppedLeading(identity string, shuttingDown bool) {
+	if !c.isLeader.Load() {
+		return
+	}
+	c.setLeader(false)
+
+	// Abandon the ARM operations in flight; The term stays stored, so that the event in progress does not start new ones.
+	if term := c.leaderTerm.Load(); term != nil {
+		term.cancel()
+	}
+
+	eventType := v1.EventTypeWarning
+	msg := fmt.Sprintf("AGIC replica %s lost the leader election Lease and stopped managing Application Gateway %s", identity, c.appGwIdentifier.AppGwName)
+	if shuttingDown {
+		eventType = v1.EventTypeNormal
+		msg = fmt.Sprintf("AGIC replica %s is shutting down and released the leader election Lease", identity)
+	}
+
+	klog.Info(msg)
+	if c.agicPod != nil {
+		c.recorder.Event(c.agicPod, eventType, events.ReasonStoppedLeading, msg)
+	}
+}
+
+func (c *AppGwIngressController) setLeader(isLeader bool) {
+	c.isLeader.Store(isLeader)
+	c.MetricStore.SetIsLeader(isLeader)
+}
+
+func getLeaderElectionIdentity(envVariables environment.EnvVariables) string {
+	if envVariables.AGICPodName != "" {
+		return envVariables.AGICPodName
+	}
+
+	hostname, err := os.Hostname()
+	if err != nil {
+		klog.Error("Could not obtain host name to use as leader election identity", err)
+		return "unknown-hostname"
+	}
+	return hostname
+}
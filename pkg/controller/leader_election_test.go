@@ -0,0 +1,66 @@
+// -------------------------------------------------------------------------------------------
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License. See License.txt in the project root for license information.
+// --------------------------------------------------------------------------------------------
+
+package controller
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"k8s.io/client-go/tools/record"
+
+	"github.com/Azure/application-gateway-kubernetes-ingress/pkg/appgw"
+	"github.com/Azure/application-gateway-kubernetes-ingress/pkg/azure"
+	"github.com/Azure/application-gateway-kubernetes-ingress/pkg/events"
+	"github.com/Azure/application-gateway-kubernetes-ingress/pkg/k8scontext"
+	"github.com/Azure/application-gateway-kubernetes-ingress/pkg/metricstore"
+)
+
+var _ = Describe("leader election", func() {
+	var controller *AppGwIngressController
+
+	BeforeEach(func() {
+		metricStore := metricstore.NewFakeMetricStore()
+		k8sContext := &k8scontext.Context{
+			MetricStore: metricStore,
+			Work:        make(chan events.Event, 10),
+		}
+		controller = NewAppGwIngressController(azure.NewFakeAzClient(), appgw.Identifier{}, k8sContext, record.NewFakeRecorder(10), metricStore, nil, nil, false)
+	})
+
+	It("cancels the ARM operations in flight when the Lease is lost", func() {
+		controller.onStartedLeading(context.Background(), "agic-1")
+		Expect(controller.IsLeader()).To(BeTrue())
+		armCtx := controller.armContext()
+		Expect(armCtx.Err()).ToNot(HaveOccurred())
+
+		controller.onStoppedLeading("agic-1", false)
+		Expect(controller.IsLeader()).To(BeFalse())
+		Expect(armCtx.Err()).To(HaveOccurred())
+
+		// The event in progress cannot start new ARM operations either.
+		_, err := controller.azClient.GetGateway(controller.armContext())
+		Expect(err).To(HaveOccurred())
+
+		// The replica keeps campaigning, and runs the ARM operations of its next term.
+		Expect(controller.ctx.Err()).ToNot(HaveOccurred())
+		controller.onStartedLeading(context.Background(), "agic-1")
+		Expect(controller.armContext().Err()).ToNot(HaveOccurred())
+	})
+
+	It("cancels the ARM operations when client-go ends the term", func() {
+		leaderCtx, cancel := context.WithCancel(context.Background())
+		controller.onStartedLeading(leaderCtx, "agic-1")
+		cancel()
+		Expect(controller.armContext().Err()).To(HaveOccurred())
+	})
+
+	It("runs the ARM operations with the context of the controller without leader election", func() {
+		Expect(controller.armContext()).To(Equal(controller.ctx))
+		controller.cancel()
+		Expect(controller.armContext().Err()).To(HaveOccurred())
+	})
+})
@@ -6,6 +6,7 @@
 package controller
 
 import (
+	"context"
 	"fmt"
 
 	n "github.com/Azure/azure-sdk-for-go/services/network/mgmt/2021-03-01/network"
@@ -25,7 +26,7 @@ type ipAddress string
 
 // MutateAllIngress applies changes to ingress status object in kubernetes
 func (c AppGwIngressController) MutateAllIngress(appGw *n.ApplicationGateway, cbCtx *appgw.ConfigBuilderContext) error {
-	ips := getIPsFromAppGateway(appGw, c.azClient)
+	ips := getIPsFromAppGateway(c.armContext(), appGw, c.azClient)
 
 	// update all relevant ingresses with IP address obtained from existing App Gateway configuration
 	cbCtx.IngressList = c.PruneIngress(appGw, cbCtx)
@@ -77,7 +78,7 @@ func (c AppGwIngressController) updateIngressStatus(appGw *n.ApplicationGateway,
 	}
 }
 
-func getIPsFromAppGateway(appGw *n.ApplicationGateway, azClient azure.AzClient) map[ipResource]ipAddress {
+func getIPsFromAppGateway(ctx context.Context, appGw *n.ApplicationGateway, azClient azure.AzClient) map[ipResource]ipAddress {
 	ips := make(map[ipResource]ipAddress)
 	for _, ipConf := range *appGw.FrontendIPConfigurations {
 		ipID := ipResource(*ipConf.ID)
@@ -87,7 +88,7 @@ func getIPsFromAppGateway(appGw *n.ApplicationGateway, azClient azure.AzClient)
 
 		if ipConf.PrivateIPAddress != nil {
 			ips[ipID] = ipAddress(*ipConf.PrivateIPAddress)
-		} else if ipAddress := getPublicIPAddress(*ipConf.PublicIPAddress.ID, azClient); ipAddress != nil {
+		} else if ipAddress := getPublicIPAddress(ctx, *ipConf.PublicIPAddress.ID, azClient); ipAddress != nil {
 			ips[ipID] = *ipAddress
 		}
 	}
@@ -96,9 +97,9 @@ func getIPsFromAppGateway(appGw *n.ApplicationGateway, azClient azure.AzClient)
 }
 
 // getPublicIPAddress gets the ipAddress address associated to public ipAddress on Azure
-func getPublicIPAddress(publicIPID string, azClient azure.AzClient) *ipAddress {
+func getPublicIPAddress(ctx context.Context, publicIPID string, azClient azure.AzClient) *ipAddress {
 	// get public ipAddress
-	publicIP, err := azClient.GetPublicIP(publicIPID)
+	publicIP, err := azClient.GetPublicIP(ctx, publicIPID)
 	if err != nil {
 		klog.Errorf("[mutate_aks] Unable to get Public IP Address %s. Error %s", publicIPID, err)
 		return nil
@@ -14,13 +14,17 @@ import (
 	n "github.com/Azure/azure-sdk-for-go/services/network/mgmt/2021-03-01/network"
 	"github.com/Azure/go-autorest/autorest/to"
 	v1 "k8s.io/api/core/v1"
+	networking "k8s.io/api/networking/v1"
 	"k8s.io/klog/v2"
 
 	"github.com/Azure/application-gateway-kubernetes-ingress/pkg/appgw"
 	"github.com/Azure/application-gateway-kubernetes-ingress/pkg/brownfield"
+	"github.com/Azure/application-gateway-kubernetes-ingress/pkg/configdiff"
 	"github.com/Azure/application-gateway-kubernetes-ingress/pkg/controllererrors"
 	"github.com/Azure/application-gateway-kubernetes-ingress/pkg/environment"
 	"github.com/Azure/application-gateway-kubernetes-ingress/pkg/events"
+	"github.com/Azure/application-gateway-kubernetes-ingress/pkg/gatewayapi"
+	"github.com/Azure/application-gateway-kubernetes-ingress/pkg/ingressstatus"
 )
 
 type realClock struct{}
@@ -30,7 +34,7 @@ func (realClock) Now() time.Time { return time.Now() }
 // GetAppGw gets App Gateway config.
 func (c AppGwIngressController) GetAppGw() (*n.ApplicationGateway, *appgw.ConfigBuilderContext, error) {
 	// Get current application gateway config
-	appGw, err := c.azClient.GetGateway()
+	appGw, err := c.azClient.GetGateway(c.armContext())
 	c.MetricStore.IncArmAPICallCounter()
 	if err != nil {
 		e := controllererrors.NewErrorWithInnerErrorf(
@@ -45,30 +49,152 @@ func (c AppGwIngressController) GetAppGw() (*n.ApplicationGateway, *appgw.Config
 		return nil, nil, e
 	}
 
+	return &appGw, c.NewConfigBuilderContext(&appGw, c.envVariables()), nil
+}
+
+// NewConfigBuilderContext returns the context in which the config of the Kubernetes resources is generated on top of appGw.
+func (c AppGwIngressController) NewConfigBuilderContext(appGw *n.ApplicationGateway, envVariables environment.EnvVariables) *appgw.ConfigBuilderContext {
 	cbCtx := &appgw.ConfigBuilderContext{
 		ServiceList:  c.k8sContext.ListServices(),
 		IngressList:  c.k8sContext.ListHTTPIngresses(),
-		EnvVariables: environment.GetEnv(),
+		EnvVariables: envVariables,
 
 		DefaultAddressPoolID:  to.StringPtr(c.appGwIdentifier.AddressPoolID(appgw.DefaultBackendAddressPoolName)),
 		DefaultHTTPSettingsID: to.StringPtr(c.appGwIdentifier.HTTPSettingsID(appgw.DefaultBackendHTTPSettingsName)),
 
 		ExistingPortsByNumber: make(map[appgw.Port]n.ApplicationGatewayFrontendPort),
+
+		IngressStatus:    ingressstatus.NewTracker(),
+		GatewayAPIStatus: gatewayapi.NewTracker(envVariables.GatewayClassControllerName),
 	}
 
 	for _, port := range *appGw.FrontendPorts {
 		cbCtx.ExistingPortsByNumber[appgw.Port(*port.Port)] = port
 	}
 
-	return &appGw, cbCtx, nil
+	return cbCtx
 }
 
 // MutateAppGateway applies App Gateway config.
 func (c AppGwIngressController) MutateAppGateway(event events.Event, appGw *n.ApplicationGateway, cbCtx *appgw.ConfigBuilderContext) error {
-	var err error
 	existingConfigJSON, _ := dumpSanitizedJSON(appGw, false, to.StringPtr("-- Existing App Gwy Config --"))
-	klog.V(3).Info("Existing App Gateway config: ", string(existingConfigJSON))
+	klog.V(5).Info("Existing App Gateway config: ", string(existingConfigJSON))
+
+	// Pruning replaces Ingresses in the list; Keep the original ones to report their status.
+	ingresses := append([]*networking.Ingress{}, cbCtx.IngressList...)
+
+	// The ConfigBuilder mutates appGw in place; Keep a copy of the existing sub-resources to diff against.
+	existingSnapshot, err := configdiff.NewSnapshot(appGw)
+	if err != nil {
+		klog.Error("Could not snapshot the existing App Gateway config: ", err)
+	}
+
+	// Keep a copy of the existing config to roll back to should the deployment fail.
+	var previousAppGw *n.ApplicationGateway
+	autoRollback := cbCtx.EnvVariables.EnableAutoRollback && !cbCtx.EnvVariables.EnableDryRun
+	if autoRollback {
+		if previousAppGw, err = copyAppGw(appGw); err != nil {
+			klog.Error("Could not copy the existing App Gateway config; Automatic rollback is not available for this deployment: ", err)
+		}
+	}
+
+	generatedAppGw, err := c.GenerateAppGw(appGw, cbCtx)
+	if err != nil {
+		return err
+	}
+
+	// Dry Run Phase //
+	// ------------- //
+	if cbCtx.EnvVariables.EnableDryRun {
+		diff, err := diffAppGw(existingSnapshot, generatedAppGw)
+		if err != nil {
+			klog.Error("Could not compute the dry run plan: ", err)
+			return err
+		}
+		c.recordPlan(diff)
+		return nil
+	}
+	// ------------- //
 
+	// Post Compare Phase //
+	// ------------------ //
+	// if this is not a reconciliation task
+	// then compare the generated state with cached state
+	if event.Type != events.PeriodicReconcile {
+		if c.configIsSame(appGw) {
+			klog.V(3).Info("cache: Config has NOT changed! No need to connect to ARM.")
+			c.updateIngressStatuses(ingresses, cbCtx.IngressStatus)
+			c.updateGatewayAPIStatuses(appGw, cbCtx)
+			c.updatePodReadinessGates(appGw)
+			c.scheduleDrainingReconcile(cbCtx.DrainingUntil)
+			return nil
+		}
+	}
+	// ------------------ //
+
+	// Deployment Phase //
+	// ---------------- //
+
+	configJSON, _ := dumpSanitizedJSON(appGw, cbCtx.EnvVariables.EnableSaveConfigToFile, nil)
+	klog.V(5).Infof("Generated config:\n%s", string(configJSON))
+
+	diff, diffErr := diffAppGw(existingSnapshot, generatedAppGw)
+	if diffErr != nil {
+		klog.Error("Could not diff the generated App Gateway config with the existing one: ", diffErr)
+	} else {
+		logConfigDiff("Applying generated Application Gateway configuration", c.appGwIdentifier.AppGwName, diff)
+	}
+
+	// Initiate deployment
+	klog.V(3).Info("BEGIN AppGateway deployment")
+	defer klog.V(3).Info("END AppGateway deployment")
+	err = c.deployAppGw(generatedAppGw, autoRollback)
+	if err != nil {
+		// A concurrent update did not deploy anything; ProcessEvent generates the config again on top of the modified App Gateway.
+		// Errors which do not come from the config, e.g. throttling or shutting down, are retried as they are without auto rollback.
+		if previousAppGw != nil && isFailedDeployment(err) {
+			return c.recoverFromFailedDeployment(err, previousAppGw, cbCtx, ingresses)
+		}
+		// Reset cache
+		c.configCache = nil
+		return err
+	}
+	klog.V(1).Infof("Applied generated Application Gateway configuration")
+	c.recordAppliedIngresses(cbCtx.IngressList)
+	summary := ""
+	if diff != nil {
+		c.reportAppliedConfigDiff(diff)
+		summary = diff.String()
+	}
+	c.recordRevision(generatedAppGw, summary)
+	c.updateIngressStatuses(ingresses, cbCtx.IngressStatus)
+	c.updateGatewayAPIStatuses(generatedAppGw, cbCtx)
+	c.updatePodReadinessGates(generatedAppGw)
+	c.scheduleDrainingReconcile(cbCtx.DrainingUntil)
+	// ----------------- //
+
+	// Cache Phase //
+	// ----------- //
+	if err != nil {
+		// Reset cache
+		c.configCache = nil
+		return controllererrors.NewErrorWithInnerErrorf(
+			controllererrors.ErrorDeployingAppGatewayConfig,
+			err,
+			"unable to get specified AppGateway %s", c.appGwIdentifier.AppGwName,
+		)
+	}
+
+	klog.V(3).Info("cache: Updated with latest applied config.")
+	c.updateCache(appGw)
+	// ----------- //
+
+	return nil
+}
+
+// GenerateAppGw generates the config of the Kubernetes resources in cbCtx on top of appGw, which it mutates in place.
+// It does not deploy the generated config.
+func (c AppGwIngressController) GenerateAppGw(appGw *n.ApplicationGateway, cbCtx *appgw.ConfigBuilderContext) (*n.ApplicationGateway, error) {
 	// Prepare k8s resources Phase //
 	// --------------------------- //
 	if cbCtx.EnvVariables.EnableBrownfieldDeployment {
@@ -93,12 +219,18 @@ func (c AppGwIngressController) MutateAppGateway(event events.Event, appGw *n.Ap
 		if len(istioGateways) > 0 && len(istioServices) > 0 {
 			cbCtx.IstioGateways = istioGateways
 			cbCtx.IstioVirtualServices = istioServices
+			cbCtx.IstioDestinationRules = c.k8sContext.ListIstioDestinationRules()
 		} else {
 			klog.Warning("Istio Integration is enabled, but AGIC needs Istio Gateways and Virtual Services; Disabling Istio integration.")
 			cbCtx.EnvVariables.EnableIstioIntegration = false
 		}
 	}
 
+	if cbCtx.EnvVariables.EnableGatewayAPI {
+		cbCtx.GatewayAPIGateways = c.k8sContext.ListGatewayAPIGateways()
+		cbCtx.HTTPRoutes = c.k8sContext.ListHTTPRoutes()
+	}
+
 	cbCtx.IngressList = c.PruneIngress(appGw, cbCtx)
 
 	if cbCtx.EnvVariables.EnableIstioIntegration {
@@ -115,7 +247,7 @@ func (c AppGwIngressController) MutateAppGateway(event events.Event, appGw *n.Ap
 	configBuilder := appgw.NewConfigBuilder(c.k8sContext, &c.appGwIdentifier, appGw, c.recorder, realClock{})
 
 	// Run validations on the Kubernetes resources which can suggest misconfiguration.
-	if err = configBuilder.PreBuildValidate(cbCtx); err != nil {
+	if err := configBuilder.PreBuildValidate(cbCtx); err != nil {
 		errorLine := fmt.Sprint("ConfigBuilder PostBuildValidate returned error:", err)
 		klog.Error(errorLine)
 		if c.agicPod != nil {
@@ -123,15 +255,15 @@ func (c AppGwIngressController) MutateAppGateway(event events.Event, appGw *n.Ap
 		}
 	}
 
-	var generatedAppGw *n.ApplicationGateway
 	// Replace the current appgw config with the generated one
-	if generatedAppGw, err = configBuilder.Build(cbCtx); err != nil {
+	generatedAppGw, err := configBuilder.Build(cbCtx)
+	if err != nil {
 		errorLine := fmt.Sprint("ConfigBuilder Build returned error:", err)
 		klog.Error(errorLine)
 		if c.agicPod != nil {
 			c.recorder.Event(c.agicPod, v1.EventTypeWarning, events.ReasonValidatonError, errorLine)
 		}
-		return err
+		return nil, err
 	}
 
 	// Run post validations to report errors in the config generation.
@@ -144,51 +276,5 @@ func (c AppGwIngressController) MutateAppGateway(event events.Event, appGw *n.Ap
 	}
 	// -------------------------- //
 
-	// Post Compare Phase //
-	// ------------------ //
-	// if this is not a reconciliation task
-	// then compare the generated state with cached state
-	if event.Type != events.PeriodicReconcile {
-		if c.configIsSame(appGw) {
-			klog.V(3).Info("cache: Config has NOT changed! No need to connect to ARM.")
-			return nil
-		}
-	}
-	// ------------------ //
-
-	// Deployment Phase //
-	// ---------------- //
-
-	configJSON, _ := dumpSanitizedJSON(appGw, cbCtx.EnvVariables.EnableSaveConfigToFile, nil)
-	klog.V(3).Infof("Generated config:\n%s", string(configJSON))
-
-	// Initiate deployment
-	klog.V(3).Info("BEGIN AppGateway deployment")
-	defer klog.V(3).Info("END AppGateway deployment")
-	err = c.azClient.UpdateGateway(generatedAppGw)
-	if err != nil {
-		// Reset cache
-		c.configCache = nil
-		return err
-	}
-	klog.V(1).Infof("Applied generated Application Gateway configuration")
-	// ----------------- //
-
-	// Cache Phase //
-	// ----------- //
-	if err != nil {
-		// Reset cache
-		c.configCache = nil
-		return controllererrors.NewErrorWithInnerErrorf(
-			controllererrors.ErrorDeployingAppGatewayConfig,
-			err,
-			"unable to get specified AppGateway %s", c.appGwIdentifier.AppGwName,
-		)
-	}
-
-	klog.V(3).Info("cache: Updated with latest applied config.")
-	c.updateCache(appGw)
-	// ----------- //
-
-	return nil
+	return generatedAppGw, nil
 }
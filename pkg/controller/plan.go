@@ -0,0 +1,33 @@
+// -------------------------------------------------------------------------------------------
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License. See License.txt in the project root for license information.
+// --------------------------------------------------------------------------------------------
+
+package controller
+
+import (
+	"time"
+
+	"github.com/Azure/application-gateway-kubernetes-ingress/pkg/configdiff"
+)
+
+// Plan is what AGIC would change on App Gateway, as computed by a dry run.
+type Plan struct {
+	AppGwName  string           `json:"appGatewayName"`
+	ComputedAt time.Time        `json:"computedAt"`
+	Diff       *configdiff.Diff `json:"diff"`
+}
+
+// LastPlan returns the plan computed by the most recent dry run; nil when no dry run has completed.
+func (c *AppGwIngressController) LastPlan() *Plan {
+	return c.lastPlan.Load()
+}
+
+func (c AppGwIngressController) recordPlan(diff *configdiff.Diff) {
+	c.lastPlan.Store(&Plan{
+		AppGwName:  c.appGwIdentifier.AppGwName,
+		ComputedAt: time.Now(),
+		Diff:       diff,
+	})
+	logConfigDiff("[dry-run] Computed plan; App Gateway was not updated", c.appGwIdentifier.AppGwName, diff)
+}
@@ -0,0 +1,201 @@
+// -------------------------------------------------------------------------------------------
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License. See License.txt in the project root for license information.
+// --------------------------------------------------------------------------------------------
+
+package controller
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	n "github.com/Azure/azure-sdk-for-go/services/network/mgmt/2021-03-01/network"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2"
+
+	"github.com/Azure/application-gateway-kubernetes-ingress/pkg/annotations"
+	"github.com/Azure/application-gateway-kubernetes-ingress/pkg/appgw"
+)
+
+const (
+	// BackendReadinessGate is the condition type of the pod readiness gate AGIC manages.
+	// Pods listing it in their readinessGates are not Ready until App Gateway runs a config with their IP in the backend pool of their Service.
+	BackendReadinessGate v1.PodConditionType = annotations.ApplicationGatewayPrefix + "/backend-ready"
+
+	// reasonInBackendPool is the reason of the condition set on pods whose IP is in the backend pools of their Services.
+	reasonInBackendPool = "InBackendPool"
+)
+
+// BackendPools holds the backend pools of the config each App Gateway AGIC manages runs, so that the readiness gate of a pod
+// waits for every App Gateway whose backends include the pod. The controllers of the App Gateways share it.
+type BackendPools struct {
+	lock sync.Mutex
+
+	// applied holds the IPs of each backend pool, by pool name, of the config each App Gateway runs.
+	// An App Gateway which has not run a config of AGIC yet maps to nil.
+	applied map[appgw.Identifier]map[string]map[string]interface{}
+}
+
+// NewBackendPools returns the BackendPools of App Gateways which have not run a config yet.
+func NewBackendPools() *BackendPools {
+	return &BackendPools{
+		applied: make(map[appgw.Identifier]map[string]map[string]interface{}),
+	}
+}
+
+// register adds an App Gateway the readiness gates wait for.
+func (p *BackendPools) register(appGwIdentifier appgw.Identifier) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	if _, exists := p.applied[appGwIdentifier]; !exists {
+		p.applied[appGwIdentifier] = nil
+	}
+}
+
+// update records the backend pools of the config App Gateway runs.
+func (p *BackendPools) update(appGwIdentifier appgw.Identifier, appGw *n.ApplicationGateway) {
+	pools := make(map[string]map[string]interface{})
+	if appGw.BackendAddressPools != nil {
+		for _, pool := range *appGw.BackendAddressPools {
+			if pool.Name == nil {
+				continue
+			}
+			ips := make(map[string]interface{})
+			if pool.ApplicationGatewayBackendAddressPoolPropertiesFormat != nil && pool.BackendAddresses != nil {
+				for _, address := range *pool.BackendAddresses {
+					if address.IPAddress != nil {
+						ips[*address.IPAddress] = nil
+					}
+				}
+			}
+			pools[*pool.Name] = ips
+		}
+	}
+
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	p.applied[appGwIdentifier] = pools
+}
+
+// podIPInPools returns the IP of the pod which is in the named pools of every App Gateway having any of these pools, with the names
+// of these App Gateways. The IP is empty when an App Gateway misses the IP, when no App Gateway has the pools, or while an App Gateway
+// has not run a config yet, as it may have the pools once it does.
+func (p *BackendPools) podIPInPools(podIPs []string, poolNames []string) (string, []string) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	var foundIP string
+	var appGwNames []string
+	for appGwIdentifier, pools := range p.applied {
+		if pools == nil {
+			return "", nil
+		}
+		var ips []map[string]interface{}
+		for _, poolName := range poolNames {
+			if poolIPs, exists := pools[poolName]; exists {
+				ips = append(ips, poolIPs)
+			}
+		}
+		if len(ips) == 0 {
+			// The App Gateway does not serve the pod.
+			continue
+		}
+		podIP := findPodIP(podIPs, ips)
+		if podIP == "" {
+			return "", nil
+		}
+		foundIP = podIP
+		appGwNames = append(appGwNames, appGwIdentifier.AppGwName)
+	}
+	sort.Strings(appGwNames)
+	return foundIP, appGwNames
+}
+
+// ShareBackendPools makes the readiness gates of the controller wait for the App Gateways of the other controllers sharing pools.
+// It must be called before Start.
+func (c *AppGwIngressController) ShareBackendPools(pools *BackendPools) {
+	pools.register(c.appGwIdentifier)
+	c.backendPools = pools
+}
+
+// updatePodReadinessGates sets the readiness gate condition of the pods whose IP is in the backend pools of their Services.
+// It must only be called once App Gateway runs appGw.
+// AGIC adds the endpoints of pods which are not ready yet to backend pools, so a pod waiting on its gate still gets its IP in a pool.
+// The condition is never reset; A pod leaving the backend pools is terminating, or is not ready for another reason.
+func (c AppGwIngressController) updatePodReadinessGates(appGw *n.ApplicationGateway) {
+	c.backendPools.update(c.appGwIdentifier, appGw)
+
+	pods := c.k8sContext.ListPodsWithReadinessGate(BackendReadinessGate)
+	if len(pods) == 0 {
+		return
+	}
+
+	for _, pod := range pods {
+		if isPodConditionTrue(pod, BackendReadinessGate) {
+			continue
+		}
+
+		var poolNames []string
+		for _, service := range c.k8sContext.ListServicesByPodSelector(pod) {
+			// A Service without a selector selects no pods; Its endpoints are managed by hand.
+			if service.Namespace != pod.Namespace || len(service.Spec.Selector) == 0 {
+				continue
+			}
+			poolNames = append(poolNames, appgw.PodBackendPoolNames(service, pod)...)
+		}
+
+		podIP, appGwNames := c.backendPools.podIPInPools(getPodIPs(pod), poolNames)
+		if podIP == "" {
+			continue
+		}
+
+		condition := v1.PodCondition{
+			Type:               BackendReadinessGate,
+			Status:             v1.ConditionTrue,
+			LastTransitionTime: metav1.Now(),
+			Reason:             reasonInBackendPool,
+			Message:            fmt.Sprintf("IP %s is in the backend pools of its Services on Application Gateway %s", podIP, strings.Join(appGwNames, ", ")),
+		}
+		if err := c.k8sContext.UpdatePodCondition(pod, condition); err != nil {
+			klog.Warning(err)
+			continue
+		}
+		klog.V(3).Infof("Set readiness gate %s on pod %s/%s", BackendReadinessGate, pod.Namespace, pod.Name)
+	}
+}
+
+// getPodIPs returns the IPs of the pod.
+func getPodIPs(pod *v1.Pod) []string {
+	podIPs := []string{pod.Status.PodIP}
+	for _, podIP := range pod.Status.PodIPs {
+		podIPs = append(podIPs, podIP.IP)
+	}
+	return podIPs
+}
+
+// findPodIP returns the IP of the pod which is in any of the IP sets; It is empty when none of the sets has an IP of the pod.
+func findPodIP(podIPs []string, ipSets []map[string]interface{}) string {
+	for _, podIP := range podIPs {
+		if podIP == "" {
+			continue
+		}
+		for _, ips := range ipSets {
+			if _, exists := ips[podIP]; exists {
+				return podIP
+			}
+		}
+	}
+	return ""
+}
+
+func isPodConditionTrue(pod *v1.Pod, conditionType v1.PodConditionType) bool {
+	for _, condition := range pod.Status.Conditions {
+		if condition.Type == conditionType {
+			return condition.Status == v1.ConditionTrue
+		}
+	}
+	return false
+}
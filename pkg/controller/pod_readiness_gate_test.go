@@ -0,0 +1,186 @@
+// -------------------------------------------------------------------------------------------
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License. See License.txt in the project root for license information.
+// --------------------------------------------------------------------------------------------
+
+package controller
+
+import (
+	"context"
+	"time"
+
+	n "github.com/Azure/azure-sdk-for-go/services/network/mgmt/2021-03-01/network"
+	"github.com/Azure/go-autorest/autorest/to"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/kubernetes"
+	testclient "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/record"
+	gateway_fake "sigs.k8s.io/gateway-api/pkg/client/clientset/versioned/fake"
+
+	"github.com/Azure/application-gateway-kubernetes-ingress/pkg/appgw"
+	"github.com/Azure/application-gateway-kubernetes-ingress/pkg/azure"
+	"github.com/Azure/application-gateway-kubernetes-ingress/pkg/crd_client/agic_crd_client/clientset/versioned/fake"
+	multiClusterFake "github.com/Azure/application-gateway-kubernetes-ingress/pkg/crd_client/azure_multicluster_crd_client/clientset/versioned/fake"
+	istioFake "github.com/Azure/application-gateway-kubernetes-ingress/pkg/crd_client/istio_crd_client/clientset/versioned/fake"
+	"github.com/Azure/application-gateway-kubernetes-ingress/pkg/environment"
+	"github.com/Azure/application-gateway-kubernetes-ingress/pkg/k8scontext"
+	"github.com/Azure/application-gateway-kubernetes-ingress/pkg/metricstore"
+)
+
+var _ = Describe("test pod readiness gates", func() {
+	var k8sClient kubernetes.Interface
+	var k8sContext *k8scontext.Context
+	var controller *AppGwIngressController
+
+	newPod := func(name string, ip string, gated bool) *v1.Pod {
+		pod := &v1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "test-ns", Labels: map[string]string{"app": "web"}},
+			Status:     v1.PodStatus{PodIP: ip, PodIPs: []v1.PodIP{{IP: ip}}},
+		}
+		if gated {
+			pod.Spec.ReadinessGates = []v1.PodReadinessGate{{ConditionType: BackendReadinessGate}}
+		}
+		_, err := k8sClient.CoreV1().Pods(pod.Namespace).Create(context.TODO(), pod, metav1.CreateOptions{})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(k8sContext.Caches.Pods.Add(pod)).To(Succeed())
+		return pod
+	}
+
+	getCondition := func(pod *v1.Pod) *v1.PodCondition {
+		updated, err := k8sClient.CoreV1().Pods(pod.Namespace).Get(context.TODO(), pod.Name, metav1.GetOptions{})
+		Expect(err).ToNot(HaveOccurred())
+		for idx := range updated.Status.Conditions {
+			if updated.Status.Conditions[idx].Type == BackendReadinessGate {
+				return &updated.Status.Conditions[idx]
+			}
+		}
+		return nil
+	}
+
+	service := &v1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "test-ns"},
+		Spec: v1.ServiceSpec{
+			Selector: map[string]string{"app": "web"},
+			Ports:    []v1.ServicePort{{Protocol: v1.ProtocolTCP, Port: 80, TargetPort: intstr.FromInt(8080)}},
+		},
+	}
+
+	newAppGw := func(pools map[string][]string) *n.ApplicationGateway {
+		var backendPools []n.ApplicationGatewayBackendAddressPool
+		for name, ips := range pools {
+			var addresses []n.ApplicationGatewayBackendAddress
+			for _, ip := range ips {
+				addresses = append(addresses, n.ApplicationGatewayBackendAddress{IPAddress: to.StringPtr(ip)})
+			}
+			backendPools = append(backendPools, n.ApplicationGatewayBackendAddressPool{
+				Name: to.StringPtr(name),
+				ApplicationGatewayBackendAddressPoolPropertiesFormat: &n.ApplicationGatewayBackendAddressPoolPropertiesFormat{
+					BackendAddresses: &addresses,
+				},
+			})
+		}
+		return &n.ApplicationGateway{
+			ApplicationGatewayPropertiesFormat: &n.ApplicationGatewayPropertiesFormat{
+				BackendAddressPools: &backendPools,
+			},
+		}
+	}
+
+	servicePool := "pool-test-ns-web-80-bp-8080"
+	appGw := newAppGw(map[string][]string{
+		servicePool:                     {"10.0.0.1", "10.0.0.3"},
+		"pool-test-ns-other-80-bp-8080": {"10.0.0.2"},
+	})
+
+	BeforeEach(func() {
+		k8sClient = testclient.NewSimpleClientset()
+		k8scontext.IsNetworkingV1PackageSupported = true
+		k8scontext.IsInMultiClusterMode = false
+		k8sContext = k8scontext.NewContext(k8sClient, fake.NewSimpleClientset(), multiClusterFake.NewSimpleClientset(), istioFake.NewSimpleClientset(), gateway_fake.NewSimpleClientset(), []string{}, 1000*time.Second, metricstore.NewFakeMetricStore(), environment.GetFakeEnv())
+		controller = NewAppGwIngressController(azure.NewFakeAzClient(), appgw.Identifier{AppGwName: "--AppGwName--"}, k8sContext, record.NewFakeRecorder(100), metricstore.NewFakeMetricStore(), nil, nil, false)
+		Expect(k8sContext.Caches.Service.Add(service)).To(Succeed())
+		Expect(appgw.PodBackendPoolNames(service, &v1.Pod{})).To(ContainElement(servicePool))
+	})
+
+	Context("ensure the readiness gate tracks the backend pools", func() {
+		It("sets the condition on gated pods whose IP is in the backend pool of their service", func() {
+			inPool := newPod("in-pool", "10.0.0.1", true)
+			inOtherPool := newPod("in-other-pool", "10.0.0.2", true)
+			notGated := newPod("not-gated", "10.0.0.3", false)
+
+			controller.updatePodReadinessGates(appGw)
+
+			condition := getCondition(inPool)
+			Expect(condition).ToNot(BeNil())
+			Expect(condition.Status).To(Equal(v1.ConditionTrue))
+			Expect(condition.Reason).To(Equal(reasonInBackendPool))
+			Expect(condition.Message).To(ContainSubstring("10.0.0.1"))
+
+			Expect(getCondition(inOtherPool)).To(BeNil())
+			Expect(getCondition(notGated)).To(BeNil())
+		})
+
+		It("leaves pods whose condition is already true untouched", func() {
+			pod := newPod("ready", "10.0.0.1", true)
+			transition := metav1.NewTime(time.Now().Add(-time.Hour).Truncate(time.Second))
+			pod.Status.Conditions = []v1.PodCondition{{Type: BackendReadinessGate, Status: v1.ConditionTrue, LastTransitionTime: transition}}
+			_, err := k8sClient.CoreV1().Pods(pod.Namespace).UpdateStatus(context.TODO(), pod, metav1.UpdateOptions{})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(k8sContext.Caches.Pods.Update(pod)).To(Succeed())
+
+			controller.updatePodReadinessGates(appGw)
+
+			Expect(getCondition(pod).LastTransitionTime.Equal(&transition)).To(BeTrue())
+		})
+
+		It("waits for the pods of services without a backend pool", func() {
+			pod := newPod("no-service-pool", "10.0.0.1", true)
+			Expect(k8sContext.Caches.Service.Delete(service)).To(Succeed())
+
+			controller.updatePodReadinessGates(appGw)
+
+			Expect(getCondition(pod)).To(BeNil())
+		})
+	})
+
+	Context("ensure the readiness gate waits for every App Gateway", func() {
+		var other *AppGwIngressController
+
+		BeforeEach(func() {
+			other = NewAppGwIngressController(azure.NewFakeAzClient(), appgw.Identifier{AppGwName: "--OtherAppGwName--"}, k8sContext, record.NewFakeRecorder(100), metricstore.NewFakeMetricStore(), nil, nil, false)
+			pools := NewBackendPools()
+			controller.ShareBackendPools(pools)
+			other.ShareBackendPools(pools)
+		})
+
+		It("sets the condition once every App Gateway with the pool of the service has the IP", func() {
+			pod := newPod("in-pool", "10.0.0.1", true)
+
+			controller.updatePodReadinessGates(appGw)
+			Expect(getCondition(pod)).To(BeNil())
+
+			other.updatePodReadinessGates(newAppGw(map[string][]string{servicePool: {"10.0.0.3"}}))
+			Expect(getCondition(pod)).To(BeNil())
+
+			other.updatePodReadinessGates(newAppGw(map[string][]string{servicePool: {"10.0.0.1"}}))
+			condition := getCondition(pod)
+			Expect(condition).ToNot(BeNil())
+			Expect(condition.Message).To(ContainSubstring("--AppGwName--, --OtherAppGwName--"))
+		})
+
+		It("does not wait for App Gateways without the pool of the service", func() {
+			pod := newPod("in-pool", "10.0.0.1", true)
+
+			other.updatePodReadinessGates(newAppGw(map[string][]string{"pool-test-ns-other-80-bp-8080": {"10.0.0.2"}}))
+			controller.updatePodReadinessGates(appGw)
+
+			condition := getCondition(pod)
+			Expect(condition).ToNot(BeNil())
+			Expect(condition.Message).ToNot(ContainSubstring("--OtherAppGwName--"))
+		})
+	})
+})
@@ -15,6 +15,7 @@ import (
 	"github.com/Azure/application-gateway-kubernetes-ingress/pkg/brownfield"
 	"github.com/Azure/application-gateway-kubernetes-ingress/pkg/controllererrors"
 	"github.com/Azure/application-gateway-kubernetes-ingress/pkg/events"
+	"github.com/Azure/application-gateway-kubernetes-ingress/pkg/ingressstatus"
 )
 
 type pruneFunc func(c *AppGwIngressController, appGw *n.ApplicationGateway, cbCtx *appgw.ConfigBuilderContext, ingressList []*networking.Ingress) []*networking.Ingress
@@ -25,6 +26,7 @@ var pruneFuncList []pruneFunc
 // PruneIngress filters ingress list based on filter functions and returns a filtered ingress list
 func (c *AppGwIngressController) PruneIngress(appGw *n.ApplicationGateway, cbCtx *appgw.ConfigBuilderContext) []*networking.Ingress {
 	once.Do(func() {
+		pruneFuncList = append(pruneFuncList, pruneExcludedIngress)
 		if cbCtx.EnvVariables.EnableBrownfieldDeployment {
 			pruneFuncList = append(pruneFuncList, pruneProhibitedIngress)
 		}
@@ -53,6 +55,13 @@ func pruneProhibitedIngress(c *AppGwIngressController, appGw *n.ApplicationGatew
 		ingressClone.Spec.Rules = brownfield.PruneIngressRules(ingress, cbCtx.ProhibitedTargets)
 		ingressList[idx] = ingressClone
 
+		if prunedRules := prunedIngressRules(ingress, ingressClone); len(prunedRules) > 0 {
+			errorLine := fmt.Sprintf("ignoring %d rules of Ingress %s/%s as they target paths protected by AzureIngressProhibitedTarget resources", len(prunedRules), ingress.Namespace, ingress.Name)
+			klog.V(3).Info(errorLine)
+			c.recorder.Event(ingress, v1.EventTypeWarning, events.ReasonProhibitedTarget, errorLine)
+			cbCtx.IngressStatus.PruneRules(ingress, prunedRules, events.ReasonProhibitedTarget, errorLine)
+		}
+
 		klog.V(3).Infof("Sanitized Ingress[%d] Rules: %+v", idx, ingressList[idx].Spec.Rules)
 	}
 
@@ -76,6 +85,7 @@ func pruneNoPrivateIP(c *AppGwIngressController, appGw *n.ApplicationGateway, cb
 				ingress.Namespace, ingress.Name, c.appGwIdentifier.AppGwName)
 			klog.Error(errorLine)
 			c.recorder.Event(ingress, v1.EventTypeWarning, events.ReasonNoPrivateIPError, errorLine)
+			cbCtx.IngressStatus.PruneIngress(ingress, events.ReasonNoPrivateIPError, errorLine)
 			if c.agicPod != nil {
 				c.recorder.Event(c.agicPod, v1.EventTypeWarning, events.ReasonNoPrivateIPError, errorLine)
 			}
@@ -105,6 +115,7 @@ func pruneNoPublicIP(c *AppGwIngressController, appGw *n.ApplicationGateway, cbC
 				ingress.Namespace, ingress.Name, c.appGwIdentifier.AppGwName)
 			klog.Error(errorLine)
 			c.recorder.Event(ingress, v1.EventTypeWarning, events.ReasonNoPublicIPError, errorLine)
+			cbCtx.IngressStatus.PruneIngress(ingress, events.ReasonNoPublicIPError, errorLine)
 			if c.agicPod != nil {
 				c.recorder.Event(c.agicPod, v1.EventTypeWarning, events.ReasonNoPublicIPError, errorLine)
 			}
@@ -137,6 +148,7 @@ func pruneNoSslCertificate(c *AppGwIngressController, appGw *n.ApplicationGatewa
 			errorLine := fmt.Sprintf("ignoring Ingress %s/%s as it requires Application Gateway %s to have pre-installed ssl certificate '%s'", ingress.Namespace, ingress.Name, c.appGwIdentifier.AppGwName, annotatedSslCertificate)
 			klog.Error(errorLine)
 			c.recorder.Event(ingress, v1.EventTypeWarning, events.ReasonNoPreInstalledSslCertificate, errorLine)
+			cbCtx.IngressStatus.PruneIngress(ingress, events.ReasonNoPreInstalledSslCertificate, errorLine)
 			if c.agicPod != nil {
 				c.recorder.Event(c.agicPod, v1.EventTypeWarning, events.ReasonNoPreInstalledSslCertificate, errorLine)
 			}
@@ -169,6 +181,7 @@ func pruneNoSslProfile(c *AppGwIngressController, appGw *n.ApplicationGateway, c
 			errorLine := fmt.Sprintf("ignoring Ingress %s/%s as it requires Application Gateway %s to have pre-installed ssl profile '%s'", ingress.Namespace, ingress.Name, c.appGwIdentifier.AppGwName, annotatedSslProfile)
 			klog.Error(errorLine)
 			c.recorder.Event(ingress, v1.EventTypeWarning, events.ReasonNoPreInstalledSslProfile, errorLine)
+			cbCtx.IngressStatus.PruneIngress(ingress, events.ReasonNoPreInstalledSslProfile, errorLine)
 			if c.agicPod != nil {
 				c.recorder.Event(c.agicPod, v1.EventTypeWarning, events.ReasonNoPreInstalledSslProfile, errorLine)
 			}
@@ -203,6 +216,7 @@ func pruneNoTrustedRootCertificate(c *AppGwIngressController, appGw *n.Applicati
 				errorLine := fmt.Sprintf("ignoring Ingress %s/%s as it requires Application Gateway %s to have pre-installed root certificate '%s'", ingress.Namespace, ingress.Name, c.appGwIdentifier.AppGwName, rootCert)
 				klog.Error(errorLine)
 				c.recorder.Event(ingress, v1.EventTypeWarning, events.ReasonNoPreInstalledRootCertificate, errorLine)
+				cbCtx.IngressStatus.PruneIngress(ingress, events.ReasonNoPreInstalledRootCertificate, errorLine)
 				if c.agicPod != nil {
 					c.recorder.Event(c.agicPod, v1.EventTypeWarning, events.ReasonNoPreInstalledRootCertificate, errorLine)
 				}
@@ -227,6 +241,7 @@ func pruneRedirectWithNoTLS(c *AppGwIngressController, appGw *n.ApplicationGatew
 			errorLine := fmt.Sprintf("ignoring Ingress %s/%s as it has an invalid spec. It is annotated with ssl-redirect: true but is missing a TLS secret or '%s' annotation. Please add a TLS secret/annotation or remove ssl-redirect annotation", ingress.Namespace, ingress.Name, annotations.AppGwSslCertificate)
 			klog.Error(errorLine)
 			c.recorder.Event(ingress, v1.EventTypeWarning, events.ReasonRedirectWithNoTLS, errorLine)
+			cbCtx.IngressStatus.PruneIngress(ingress, events.ReasonRedirectWithNoTLS, errorLine)
 			if c.agicPod != nil {
 				c.recorder.Event(c.agicPod, v1.EventTypeWarning, events.ReasonRedirectWithNoTLS, errorLine)
 			}
@@ -237,3 +252,22 @@ func pruneRedirectWithNoTLS(c *AppGwIngressController, appGw *n.ApplicationGatew
 
 	return prunedIngresses
 }
+
+// prunedIngressRules lists the rules of the original Ingress which are missing from the pruned Ingress.
+func prunedIngressRules(original *networking.Ingress, pruned *networking.Ingress) []ingressstatus.Rule {
+	remaining := ingressstatus.Rules(pruned)
+	var prunedRules []ingressstatus.Rule
+	for _, rule := range ingressstatus.Rules(original) {
+		found := false
+		for _, remainingRule := range remaining {
+			if rule == remainingRule {
+				found = true
+				break
+			}
+		}
+		if !found {
+			prunedRules = append(prunedRules, rule)
+		}
+	}
+	return prunedRules
+}
@@ -19,6 +19,8 @@ import (
 	"github.com/Azure/application-gateway-kubernetes-ingress/pkg/annotations"
 	"github.com/Azure/application-gateway-kubernetes-ingress/pkg/appgw"
 	"github.com/Azure/application-gateway-kubernetes-ingress/pkg/environment"
+	"github.com/Azure/application-gateway-kubernetes-ingress/pkg/events"
+	"github.com/Azure/application-gateway-kubernetes-ingress/pkg/ingressstatus"
 	"github.com/Azure/application-gateway-kubernetes-ingress/pkg/tests"
 	"github.com/Azure/application-gateway-kubernetes-ingress/pkg/tests/fixtures"
 )
@@ -65,6 +67,21 @@ var _ = Describe("prune function tests", func() {
 			Expect(prunedIngresses[0].Name).To(Equal("public"))
 		})
 
+		It("records why the rules of the ingress using private ipAddress were pruned", func() {
+			cbCtx.IngressStatus = ingressstatus.NewTracker()
+			defer func() { cbCtx.IngressStatus = nil }()
+			pruneNoPrivateIP(controller, &appGw, cbCtx, cbCtx.IngressList)
+
+			status := cbCtx.IngressStatus.Status(ingressPrivate)
+			Expect(status.AppliedRules).To(BeEmpty())
+			Expect(status.PrunedRules).To(HaveLen(len(ingressstatus.Rules(ingressPrivate))))
+			Expect(status.PrunedRules[0].Reason).To(Equal(events.ReasonNoPrivateIPError))
+
+			status = cbCtx.IngressStatus.Status(ingressPublic)
+			Expect(status.AppliedRules).To(Equal(ingressstatus.Rules(ingressPublic)))
+			Expect(status.PrunedRules).To(BeEmpty())
+		})
+
 		It("keeps the ingress using private ipAddress when private ipAddress is present", func() {
 			appGw.FrontendIPConfigurations = &[]n.ApplicationGatewayFrontendIPConfiguration{
 				fixtures.GetPublicIPConfiguration(),
@@ -10,6 +10,7 @@ import (
 
 	"github.com/Azure/go-autorest/autorest/to"
 	v1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
 	"k8s.io/klog/v2"
 
 	"github.com/Azure/application-gateway-kubernetes-ingress/pkg/events"
@@ -17,21 +18,31 @@ import (
 
 // ShouldProcess determines whether to process an event.
 func (c AppGwIngressController) ShouldProcess(event events.Event) (bool, *string) {
+	if !c.isLeader.Load() {
+		// Standby replicas only keep their caches warm. Returning a nil reason keeps the logs quiet.
+		return false, nil
+	}
+
+	if pause := c.Paused(); pause != nil {
+		reason := fmt.Sprintf("reconciliation is paused since App Gateway was rolled back to revision %d", pause.Revision)
+		return false, to.StringPtr(reason)
+	}
+
 	if pod, ok := event.Value.(*v1.Pod); ok {
 		// this pod is not used by any ingress, skip any event for this
 		reason := fmt.Sprintf("pod %s/%s is not used by any Ingress", pod.Namespace, pod.Name)
 		return c.k8sContext.IsPodReferencedByAnyIngress(pod), to.StringPtr(reason)
 	}
 
-	if endpoints, ok := event.Value.(*v1.Endpoints); ok {
-		if endpoints.Namespace == "default" && endpoints.Name == "aad-pod-identity-mic" {
+	if endpointSlice, ok := event.Value.(*discoveryv1.EndpointSlice); ok {
+		if endpointSlice.Namespace == "default" && endpointSlice.Labels[discoveryv1.LabelServiceName] == "aad-pod-identity-mic" {
 			// Ignore AAD Pod Identity
 			return false, nil
 		}
 
-		// this pod is not used by any ingress, skip any event for this
-		reason := fmt.Sprintf("endpoint %s/%s is not used by any Ingress", endpoints.Namespace, endpoints.Name)
-		return c.k8sContext.IsEndpointReferencedByAnyIngress(endpoints), to.StringPtr(reason)
+		// the service of this endpoint slice is not used by any ingress, skip any event for this
+		reason := fmt.Sprintf("endpoint slice %s/%s is not used by any Ingress", endpointSlice.Namespace, endpointSlice.Name)
+		return c.k8sContext.IsEndpointSliceReferencedByAnyIngress(endpointSlice), to.StringPtr(reason)
 	}
 
 	if event.Type == events.PeriodicReconcile {
@@ -0,0 +1,57 @@
+// -------------------------------------------------------------------------------------------
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License. See License.txt in the project root for license information.
+// --------------------------------------------------------------------------------------------
+
+package controller
+
+import (
+	"time"
+
+	"k8s.io/klog/v2"
+
+	"github.com/Azure/application-gateway-kubernetes-ingress/pkg/environment"
+)
+
+const (
+	// defaultShutdownGracePeriod stays below the default terminationGracePeriodSeconds of a pod, which is 30s.
+	defaultShutdownGracePeriod = 20 * time.Second
+
+	// abandonTimeout bounds how long Stop waits for the ARM operations to return once they were cancelled.
+	abandonTimeout = 5 * time.Second
+)
+
+func newShutdownGracePeriod(envVariables environment.EnvVariables) time.Duration {
+	if gracePeriod, err := time.ParseDuration(envVariables.ShutdownGracePeriod); err == nil && gracePeriod > 0 {
+		return gracePeriod
+	}
+	return defaultShutdownGracePeriod
+}
+
+// finishInFlightWork waits for the event in progress, e.g. a deployment of App Gateway, to complete.
+// Once the grace period is over, it abandons the ARM operations in flight by cancelling their context;
+// App Gateway carries on with a deployment ARM already accepted, and the next leader reconciles it.
+func (c *AppGwIngressController) finishInFlightWork(gracePeriod time.Duration) {
+	defer c.cancel()
+
+	idle := make(chan struct{})
+	go func() {
+		c.applyLock.Lock()
+		defer c.applyLock.Unlock()
+		close(idle)
+	}()
+
+	select {
+	case <-idle:
+		return
+	case <-time.After(gracePeriod):
+	}
+
+	klog.Warningf("Abandoning the ARM operations in flight; They did not complete within the shutdown grace period of %s", gracePeriod)
+	c.cancel()
+	select {
+	case <-idle:
+	case <-time.After(abandonTimeout):
+		klog.Warning("Timed out waiting for the abandoned ARM operations to return")
+	}
+}
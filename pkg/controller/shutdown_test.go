@@ -0,0 +1,62 @@
+// -------------------------------------------------------------------------------------------
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License. See License.txt in the project root for license information.
+// --------------------------------------------------------------------------------------------
+
+package controller
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"k8s.io/client-go/tools/record"
+
+	"github.com/Azure/application-gateway-kubernetes-ingress/pkg/appgw"
+	"github.com/Azure/application-gateway-kubernetes-ingress/pkg/azure"
+	"github.com/Azure/application-gateway-kubernetes-ingress/pkg/environment"
+	"github.com/Azure/application-gateway-kubernetes-ingress/pkg/k8scontext"
+	"github.com/Azure/application-gateway-kubernetes-ingress/pkg/metricstore"
+)
+
+var _ = Describe("graceful shutdown", func() {
+	var controller *AppGwIngressController
+
+	BeforeEach(func() {
+		metricStore := metricstore.NewFakeMetricStore()
+		controller = NewAppGwIngressController(azure.NewFakeAzClient(), appgw.Identifier{}, &k8scontext.Context{MetricStore: metricStore}, record.NewFakeRecorder(0), metricStore, nil, nil, false)
+	})
+
+	It("waits for the event in progress to complete", func() {
+		controller.applyLock.Lock()
+		go func() {
+			defer GinkgoRecover()
+			time.Sleep(50 * time.Millisecond)
+			Expect(controller.ctx.Err()).ToNot(HaveOccurred())
+			controller.applyLock.Unlock()
+		}()
+
+		controller.finishInFlightWork(time.Minute)
+		Expect(controller.ctx.Err()).To(HaveOccurred())
+	})
+
+	It("abandons the ARM operations in flight after the grace period", func() {
+		controller.applyLock.Lock()
+		go func() {
+			// The deployment in progress returns once its context is cancelled.
+			<-controller.ctx.Done()
+			controller.applyLock.Unlock()
+		}()
+
+		started := time.Now()
+		controller.finishInFlightWork(50 * time.Millisecond)
+		Expect(time.Since(started)).To(BeNumerically("<", abandonTimeout))
+		_, err := controller.azClient.GetGateway(controller.ctx)
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("reads the grace period from the environment", func() {
+		Expect(newShutdownGracePeriod(environment.EnvVariables{ShutdownGracePeriod: "25s"})).To(Equal(25 * time.Second))
+		Expect(newShutdownGracePeriod(environment.EnvVariables{})).To(Equal(defaultShutdownGracePeriod))
+	})
+})
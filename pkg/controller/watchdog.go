@@ -0,0 +1,117 @@
+// -------------------------------------------------------------------------------------------
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License. See License.txt in the project root for license information.
+// --------------------------------------------------------------------------------------------
+
+package controller
+
+import (
+	"context"
+
+	n "github.com/Azure/azure-sdk-for-go/services/network/mgmt/2021-03-01/network"
+	"k8s.io/klog/v2"
+
+	"github.com/Azure/application-gateway-kubernetes-ingress/pkg/azure"
+	"github.com/Azure/application-gateway-kubernetes-ingress/pkg/health"
+)
+
+// watchedAzClient reports the ARM operations in flight and their failures to the watchdog, so that a hung deployment fails the liveness probe.
+// It watches every ARM operation of AzClient, including those of the CNI reconciler.
+type watchedAzClient struct {
+	azure.AzClient
+	watchdog *health.Watchdog
+}
+
+// watch records the operation in flight until the returned function is called with its error.
+func (az watchedAzClient) watch(name string) func(err error) {
+	done := az.watchdog.BeginOperation(name)
+	return func(err error) {
+		done()
+		az.watchdog.RecordOperationResult(name, err)
+	}
+}
+
+func (az watchedAzClient) ApplyRouteTable(ctx context.Context, subnetID string, routeTableID string) (err error) {
+	done := az.watch("ApplyRouteTable")
+	defer func() { done(err) }()
+	return az.AzClient.ApplyRouteTable(ctx, subnetID, routeTableID)
+}
+
+func (az watchedAzClient) WaitForGetAccessOnGateway(ctx context.Context, maxRetryCount int) (err error) {
+	done := az.watch("WaitForGetAccessOnGateway")
+	defer func() { done(err) }()
+	return az.AzClient.WaitForGetAccessOnGateway(ctx, maxRetryCount)
+}
+
+func (az watchedAzClient) GetGateway(ctx context.Context) (appGw n.ApplicationGateway, err error) {
+	done := az.watch("GetGateway")
+	defer func() { done(err) }()
+	return az.AzClient.GetGateway(ctx)
+}
+
+func (az watchedAzClient) UpdateGateway(ctx context.Context, appGw *n.ApplicationGateway) (err error) {
+	done := az.watch("UpdateGateway")
+	defer func() { done(err) }()
+	return az.AzClient.UpdateGateway(ctx, appGw)
+}
+
+func (az watchedAzClient) DeployGatewayWithVnet(ctx context.Context, resourceGroupName azure.ResourceGroup, vnetName azure.ResourceName, subnetName azure.ResourceName, subnetPrefix, skuName string) (err error) {
+	done := az.watch("DeployGatewayWithVnet")
+	defer func() { done(err) }()
+	return az.AzClient.DeployGatewayWithVnet(ctx, resourceGroupName, vnetName, subnetName, subnetPrefix, skuName)
+}
+
+func (az watchedAzClient) DeployGatewayWithSubnet(ctx context.Context, subnetID, skuName string) (err error) {
+	done := az.watch("DeployGatewayWithSubnet")
+	defer func() { done(err) }()
+	return az.AzClient.DeployGatewayWithSubnet(ctx, subnetID, skuName)
+}
+
+func (az watchedAzClient) GetSubnet(ctx context.Context, subnetID string) (subnet n.Subnet, err error) {
+	done := az.watch("GetSubnet")
+	defer func() { done(err) }()
+	return az.AzClient.GetSubnet(ctx, subnetID)
+}
+
+func (az watchedAzClient) GetPublicIP(ctx context.Context, resourceID string) (publicIP n.PublicIPAddress, err error) {
+	done := az.watch("GetPublicIP")
+	defer func() { done(err) }()
+	return az.AzClient.GetPublicIP(ctx, resourceID)
+}
+
+// AzClient returns the ARM client of the controller, which reports its operations to the watchdog of the controller.
+// The CNI reconciler must use it, so that its operations reach the liveness probe too.
+func (c *AppGwIngressController) AzClient() azure.AzClient {
+	return c.azClient
+}
+
+// ReconcileCNI sets the reconciler of the CNI resources of the cluster; It must be called before Start.
+func (c *AppGwIngressController) ReconcileCNI(cniReconciler CniReconciler) {
+	c.cniReconciler = cniReconciler
+}
+
+// informersSynced tells whether the informers completed their initial sync without blocking.
+func (c *AppGwIngressController) informersSynced() bool {
+	select {
+	case <-c.k8sContext.CacheSynced:
+		return true
+	default:
+		return false
+	}
+}
+
+// HealthDetails reports the checks behind the liveness probe.
+func (c *AppGwIngressController) HealthDetails() health.Report {
+	return c.watchdog.Report()
+}
+
+// Liveness fulfills the health.HealthProbe interface; It is evaluated when K8s liveness-checks the AGIC pod.
+func (c *AppGwIngressController) Liveness() bool {
+	report := c.watchdog.Report()
+	for _, check := range report.Checks {
+		if !check.Healthy {
+			klog.Errorf("Liveness check %s failed: %s", check.Name, check.Message)
+		}
+	}
+	return report.Alive
+}
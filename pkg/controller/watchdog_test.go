@@ -0,0 +1,128 @@
+// -------------------------------------------------------------------------------------------
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License. See License.txt in the project root for license information.
+// --------------------------------------------------------------------------------------------
+
+package controller
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	n "github.com/Azure/azure-sdk-for-go/services/network/mgmt/2021-03-01/network"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	testclient "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/record"
+	gateway_fake "sigs.k8s.io/gateway-api/pkg/client/clientset/versioned/fake"
+
+	"github.com/Azure/application-gateway-kubernetes-ingress/pkg/appgw"
+	"github.com/Azure/application-gateway-kubernetes-ingress/pkg/azure"
+	"github.com/Azure/application-gateway-kubernetes-ingress/pkg/crd_client/agic_crd_client/clientset/versioned/fake"
+	multiClusterFake "github.com/Azure/application-gateway-kubernetes-ingress/pkg/crd_client/azure_multicluster_crd_client/clientset/versioned/fake"
+	istioFake "github.com/Azure/application-gateway-kubernetes-ingress/pkg/crd_client/istio_crd_client/clientset/versioned/fake"
+	"github.com/Azure/application-gateway-kubernetes-ingress/pkg/environment"
+	"github.com/Azure/application-gateway-kubernetes-ingress/pkg/health"
+	"github.com/Azure/application-gateway-kubernetes-ingress/pkg/k8scontext"
+	"github.com/Azure/application-gateway-kubernetes-ingress/pkg/metricstore"
+)
+
+var _ = Describe("test the ARM client watched by the watchdog", func() {
+	var watchdog *health.Watchdog
+	var fakeClient *azure.FakeAzClient
+	var client watchedAzClient
+
+	// failWith makes every ARM operation of the fake client check that the watchdog sees it in flight, then return err.
+	failWith := func(err error) {
+		inFlight := func() {
+			Expect(watchdog.Report().Checks[1].Message).To(ContainSubstring("1 ARM operations in flight"))
+		}
+		fakeClient.GetGatewayFunc = func() (n.ApplicationGateway, error) {
+			inFlight()
+			return n.ApplicationGateway{}, err
+		}
+		fakeClient.UpdateGatewayFunc = func(*n.ApplicationGateway) error {
+			inFlight()
+			return err
+		}
+		fakeClient.DeployGatewayFunc = func(string) error {
+			inFlight()
+			return err
+		}
+		fakeClient.GetPublicIPFunc = func(string) (n.PublicIPAddress, error) {
+			inFlight()
+			return n.PublicIPAddress{}, err
+		}
+		fakeClient.ApplyRouteTableFunc = func(string, string) error {
+			inFlight()
+			return err
+		}
+		fakeClient.GetSubnetFunc = func(string) (n.Subnet, error) {
+			inFlight()
+			return n.Subnet{}, err
+		}
+	}
+
+	operations := map[string]func() error{
+		"GetGateway": func() error {
+			_, err := client.GetGateway(context.TODO())
+			return err
+		},
+		"UpdateGateway": func() error {
+			return client.UpdateGateway(context.TODO(), &n.ApplicationGateway{})
+		},
+		"DeployGatewayWithSubnet": func() error {
+			return client.DeployGatewayWithSubnet(context.TODO(), "subnet", "Standard_v2")
+		},
+		"DeployGatewayWithVnet": func() error {
+			return client.DeployGatewayWithVnet(context.TODO(), "rg", "vnet", "subnet", "10.0.0.0/24", "Standard_v2")
+		},
+		"GetPublicIP": func() error {
+			_, err := client.GetPublicIP(context.TODO(), "ip")
+			return err
+		},
+		"ApplyRouteTable": func() error {
+			return client.ApplyRouteTable(context.TODO(), "subnet", "routeTable")
+		},
+		"GetSubnet": func() error {
+			_, err := client.GetSubnet(context.TODO(), "subnet")
+			return err
+		},
+	}
+
+	BeforeEach(func() {
+		watchdog = health.NewWatchdog(health.DefaultWatchdogConfig())
+		fakeClient = azure.NewFakeAzClient()
+		client = watchedAzClient{AzClient: fakeClient, watchdog: watchdog}
+	})
+
+	It("reports every ARM operation in flight and its failure", func() {
+		for name, operation := range operations {
+			failWith(errors.New("ARM is down"))
+			Expect(operation()).To(HaveOccurred())
+
+			check := watchdog.Report().Checks[1]
+			Expect(check.Name).To(Equal(health.CheckARMOperations))
+			Expect(check.Healthy).To(BeTrue())
+			Expect(check.Message).ToNot(ContainSubstring("in flight"))
+			Expect(check.Message).To(ContainSubstring(name+": ARM is down"), name)
+
+			failWith(nil)
+			Expect(operation()).To(Succeed())
+			Expect(watchdog.Report().Checks[1].Message).ToNot(ContainSubstring(name+":"), name)
+		}
+	})
+
+	It("watches the ARM operations of the CNI reconciler", func() {
+		k8sContext := k8scontext.NewContext(testclient.NewSimpleClientset(), fake.NewSimpleClientset(), multiClusterFake.NewSimpleClientset(), istioFake.NewSimpleClientset(), gateway_fake.NewSimpleClientset(), []string{}, 1000*time.Second, metricstore.NewFakeMetricStore(), environment.GetFakeEnv())
+		controller := NewAppGwIngressController(fakeClient, appgw.Identifier{AppGwName: "--AppGwName--"}, k8sContext, record.NewFakeRecorder(100), metricstore.NewFakeMetricStore(), nil, nil, false)
+		fakeClient.GetSubnetFunc = func(string) (n.Subnet, error) {
+			return n.Subnet{}, errors.New("ARM is down")
+		}
+
+		_, err := controller.AzClient().GetSubnet(context.TODO(), "subnet")
+		Expect(err).To(HaveOccurred())
+		Expect(controller.HealthDetails().Checks[1].Message).To(ContainSubstring("GetSubnet: ARM is down"))
+	})
+})
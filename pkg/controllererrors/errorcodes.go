@@ -36,17 +36,24 @@ const (
 	ErrorFetchingBackendAddressPool     ErrorCode = "ErrorFetchingBackendAddressPool"
 	ErrorFetchingRewrite                ErrorCode = "ErrorFetchingRewrite"
 	ErrorFetchingInstanceUpdateStatus   ErrorCode = "ErrorFetchingInstanceUpdateStatus"
+	ErrorFetchingIngressClassParameters ErrorCode = "ErrorFetchingIngressClassParameters"
+	ErrorFetchingLoadDistributionPolicy ErrorCode = "ErrorFetchingLoadDistributionPolicy"
+	ErrorFetchingRouteMatch             ErrorCode = "ErrorFetchingRouteMatch"
 	ErrorInformersNotInitialized        ErrorCode = "ErrorInformersNotInitialized"
 	ErrorFailedInitialCacheSync         ErrorCode = "ErrorFailedInitialCacheSync"
 	ErrorUpdatingIngressStatus          ErrorCode = "ErrorUpdatingIngressStatus"
+	ErrorUpdatingIngressAnnotation      ErrorCode = "ErrorUpdatingIngressAnnotation"
+	ErrorUpdatingGatewayAPIStatus       ErrorCode = "ErrorUpdatingGatewayAPIStatus"
+	ErrorUpdatingPodCondition           ErrorCode = "ErrorUpdatingPodCondition"
 	ErrorFetchingNodes                  ErrorCode = "ErrorFetchingNodes"
 	ErrorNoNodesFound                   ErrorCode = "ErrorNoNodesFound"
 	ErrorUnrecognizedNodeProviderPrefix ErrorCode = "ErrorUnrecognizedNodeProviderPrefix"
 	ErrorUnknownSecretType              ErrorCode = "ErrorUnknownSecretType"
 	ErrorMalformedSecret                ErrorCode = "ErrorMalformedSecret"
-	ErrorCreatingFile                   ErrorCode = "ErrorCreatingFile"
-	ErrorWritingToFile                  ErrorCode = "ErrorWritingToFile"
-	ErrorExportingWithOpenSSL           ErrorCode = "ErrorExportingWithOpenSSL"
+	ErrorDecodingCertificate            ErrorCode = "ErrorDecodingCertificate"
+	ErrorDecodingPrivateKey             ErrorCode = "ErrorDecodingPrivateKey"
+	ErrorCertificateKeyMismatch         ErrorCode = "ErrorCertificateKeyMismatch"
+	ErrorEncodingPfx                    ErrorCode = "ErrorEncodingPfx"
 
 	// brownfield package
 	ErrorListenerLookup ErrorCode = "ErrorListenerLookup"
@@ -57,10 +64,24 @@ const (
 	ErrorNotAllowedApplicationGatewayID                      ErrorCode = "ErrorNotAllowedApplicationGatewayID"
 	ErrorMissingSubnetInfo                                   ErrorCode = "ErrorMissingSubnetInfo"
 	ErrorInvalidReconcilePeriod                              ErrorCode = "ErrorInvalidReconcilePeriod"
+	ErrorMissingLeaderElectionNamespace                      ErrorCode = "ErrorMissingLeaderElectionNamespace"
+	ErrorInvalidWorkqueueConfig                              ErrorCode = "ErrorInvalidWorkqueueConfig"
+	ErrorInvalidConfigHistory                                ErrorCode = "ErrorInvalidConfigHistory"
+	ErrorInvalidAutoRollbackConfig                           ErrorCode = "ErrorInvalidAutoRollbackConfig"
+	ErrorInvalidWatchdogConfig                               ErrorCode = "ErrorInvalidWatchdogConfig"
+	ErrorInvalidARMTimeouts                                  ErrorCode = "ErrorInvalidARMTimeouts"
+	ErrorInvalidARMEndpoint                                  ErrorCode = "ErrorInvalidARMEndpoint"
+	ErrorInvalidGateways                                     ErrorCode = "ErrorInvalidGateways"
+	ErrorConflictingIngressClassGateway                      ErrorCode = "ErrorConflictingIngressClassGateway"
 
 	// controller package
-	ErrorFetchingAppGatewayConfig  ErrorCode = "ErrorFetchingAppGatewayConfig"
-	ErrorDeployingAppGatewayConfig ErrorCode = "ErrorDeployingAppGatewayConfig"
+	ErrorFetchingAppGatewayConfig     ErrorCode = "ErrorFetchingAppGatewayConfig"
+	ErrorDeployingAppGatewayConfig    ErrorCode = "ErrorDeployingAppGatewayConfig"
+	ErrorConfigHistoryDisabled        ErrorCode = "ErrorConfigHistoryDisabled"
+	ErrorConfigRevisionNotFound       ErrorCode = "ErrorConfigRevisionNotFound"
+	ErrorNotLeader                    ErrorCode = "ErrorNotLeader"
+	ErrorRollingBackAppGatewayConfig  ErrorCode = "ErrorRollingBackAppGatewayConfig"
+	ErrorAppGatewayProvisioningFailed ErrorCode = "ErrorAppGatewayProvisioningFailed"
 
 	// annotations package
 	ErrorMissingAnnotation ErrorCode = "ErrorMissingAnnotation"
@@ -73,6 +94,7 @@ const (
 	ErrorApplicationGatewayUnexpectedStatusCode ErrorCode = "ErrorApplicationGatewayUnexpectedStatusCode"
 	ErrorSubnetNotFound                         ErrorCode = "ErrorSubnetNotFound"
 	ErrorMissingResourceGroup                   ErrorCode = "ErrorMissingResourceGroup"
+	ErrorApplicationGatewayConcurrentUpdate     ErrorCode = "ErrorApplicationGatewayConcurrentUpdate"
 
 	// main package
 	ErrorNoSuchNamespace ErrorCode = "ErrorNoSuchNamespace"
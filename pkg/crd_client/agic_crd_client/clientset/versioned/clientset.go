@@ -22,8 +22,10 @@ import (
 	"fmt"
 
 	azureapplicationgatewaybackendpoolsv1beta1 "github.com/Azure/application-gateway-kubernetes-ingress/pkg/crd_client/agic_crd_client/clientset/versioned/typed/azureapplicationgatewaybackendpool/v1beta1"
+	azureapplicationgatewayclassparametersv1beta1 "github.com/Azure/application-gateway-kubernetes-ingress/pkg/crd_client/agic_crd_client/clientset/versioned/typed/azureapplicationgatewayclassparameters/v1beta1"
 	azureapplicationgatewayinstanceupdatestatusv1beta1 "github.com/Azure/application-gateway-kubernetes-ingress/pkg/crd_client/agic_crd_client/clientset/versioned/typed/azureapplicationgatewayinstanceupdatestatus/v1beta1"
 	azureapplicationgatewayrewritesv1beta1 "github.com/Azure/application-gateway-kubernetes-ingress/pkg/crd_client/agic_crd_client/clientset/versioned/typed/azureapplicationgatewayrewrite/v1beta1"
+	azureapplicationgatewayroutematchesv1beta1 "github.com/Azure/application-gateway-kubernetes-ingress/pkg/crd_client/agic_crd_client/clientset/versioned/typed/azureapplicationgatewayroutematch/v1beta1"
 	azureingressprohibitedtargetsv1 "github.com/Azure/application-gateway-kubernetes-ingress/pkg/crd_client/agic_crd_client/clientset/versioned/typed/azureingressprohibitedtarget/v1"
 	loaddistributionpoliciesv1beta1 "github.com/Azure/application-gateway-kubernetes-ingress/pkg/crd_client/agic_crd_client/clientset/versioned/typed/loaddistributionpolicy/v1beta1"
 	discovery "k8s.io/client-go/discovery"
@@ -34,8 +36,10 @@ import (
 type Interface interface {
 	Discovery() discovery.DiscoveryInterface
 	AzureapplicationgatewaybackendpoolsV1beta1() azureapplicationgatewaybackendpoolsv1beta1.AzureapplicationgatewaybackendpoolsV1beta1Interface
+	AzureapplicationgatewayclassparametersV1beta1() azureapplicationgatewayclassparametersv1beta1.AzureapplicationgatewayclassparametersV1beta1Interface
 	AzureapplicationgatewayinstanceupdatestatusV1beta1() azureapplicationgatewayinstanceupdatestatusv1beta1.AzureapplicationgatewayinstanceupdatestatusV1beta1Interface
 	AzureapplicationgatewayrewritesV1beta1() azureapplicationgatewayrewritesv1beta1.AzureapplicationgatewayrewritesV1beta1Interface
+	AzureapplicationgatewayroutematchesV1beta1() azureapplicationgatewayroutematchesv1beta1.AzureapplicationgatewayroutematchesV1beta1Interface
 	AzureingressprohibitedtargetsV1() azureingressprohibitedtargetsv1.AzureingressprohibitedtargetsV1Interface
 	LoaddistributionpoliciesV1beta1() loaddistributionpoliciesv1beta1.LoaddistributionpoliciesV1beta1Interface
 }
@@ -45,8 +49,10 @@ type Interface interface {
 type Clientset struct {
 	*discovery.DiscoveryClient
 	azureapplicationgatewaybackendpoolsV1beta1         *azureapplicationgatewaybackendpoolsv1beta1.AzureapplicationgatewaybackendpoolsV1beta1Client
+	azureapplicationgatewayclassparametersV1beta1      *azureapplicationgatewayclassparametersv1beta1.AzureapplicationgatewayclassparametersV1beta1Client
 	azureapplicationgatewayinstanceupdatestatusV1beta1 *azureapplicationgatewayinstanceupdatestatusv1beta1.AzureapplicationgatewayinstanceupdatestatusV1beta1Client
 	azureapplicationgatewayrewritesV1beta1             *azureapplicationgatewayrewritesv1beta1.AzureapplicationgatewayrewritesV1beta1Client
+	azureapplicationgatewayroutematchesV1beta1         *azureapplicationgatewayroutematchesv1beta1.AzureapplicationgatewayroutematchesV1beta1Client
 	azureingressprohibitedtargetsV1                    *azureingressprohibitedtargetsv1.AzureingressprohibitedtargetsV1Client
 	loaddistributionpoliciesV1beta1                    *loaddistributionpoliciesv1beta1.LoaddistributionpoliciesV1beta1Client
 }
@@ -56,6 +62,11 @@ func (c *Clientset) AzureapplicationgatewaybackendpoolsV1beta1() azureapplicatio
 	return c.azureapplicationgatewaybackendpoolsV1beta1
 }
 
+// AzureapplicationgatewayclassparametersV1beta1 retrieves the AzureapplicationgatewayclassparametersV1beta1Client
+func (c *Clientset) AzureapplicationgatewayclassparametersV1beta1() azureapplicationgatewayclassparametersv1beta1.AzureapplicationgatewayclassparametersV1beta1Interface {
+	return c.azureapplicationgatewayclassparametersV1beta1
+}
+
 // AzureapplicationgatewayinstanceupdatestatusV1beta1 retrieves the AzureapplicationgatewayinstanceupdatestatusV1beta1Client
 func (c *Clientset) AzureapplicationgatewayinstanceupdatestatusV1beta1() azureapplicationgatewayinstanceupdatestatusv1beta1.AzureapplicationgatewayinstanceupdatestatusV1beta1Interface {
 	return c.azureapplicationgatewayinstanceupdatestatusV1beta1
@@ -66,6 +77,11 @@ func (c *Clientset) AzureapplicationgatewayrewritesV1beta1() azureapplicationgat
 	return c.azureapplicationgatewayrewritesV1beta1
 }
 
+// AzureapplicationgatewayroutematchesV1beta1 retrieves the AzureapplicationgatewayroutematchesV1beta1Client
+func (c *Clientset) AzureapplicationgatewayroutematchesV1beta1() azureapplicationgatewayroutematchesv1beta1.AzureapplicationgatewayroutematchesV1beta1Interface {
+	return c.azureapplicationgatewayroutematchesV1beta1
+}
+
 // AzureingressprohibitedtargetsV1 retrieves the AzureingressprohibitedtargetsV1Client
 func (c *Clientset) AzureingressprohibitedtargetsV1() azureingressprohibitedtargetsv1.AzureingressprohibitedtargetsV1Interface {
 	return c.azureingressprohibitedtargetsV1
@@ -101,6 +117,10 @@ func NewForConfig(c *rest.Config) (*Clientset, error) {
 	if err != nil {
 		return nil, err
 	}
+	cs.azureapplicationgatewayclassparametersV1beta1, err = azureapplicationgatewayclassparametersv1beta1.NewForConfig(&configShallowCopy)
+	if err != nil {
+		return nil, err
+	}
 	cs.azureapplicationgatewayinstanceupdatestatusV1beta1, err = azureapplicationgatewayinstanceupdatestatusv1beta1.NewForConfig(&configShallowCopy)
 	if err != nil {
 		return nil, err
@@ -109,6 +129,10 @@ func NewForConfig(c *rest.Config) (*Clientset, error) {
 	if err != nil {
 		return nil, err
 	}
+	cs.azureapplicationgatewayroutematchesV1beta1, err = azureapplicationgatewayroutematchesv1beta1.NewForConfig(&configShallowCopy)
+	if err != nil {
+		return nil, err
+	}
 	cs.azureingressprohibitedtargetsV1, err = azureingressprohibitedtargetsv1.NewForConfig(&configShallowCopy)
 	if err != nil {
 		return nil, err
@@ -130,8 +154,10 @@ func NewForConfig(c *rest.Config) (*Clientset, error) {
 func NewForConfigOrDie(c *rest.Config) *Clientset {
 	var cs Clientset
 	cs.azureapplicationgatewaybackendpoolsV1beta1 = azureapplicationgatewaybackendpoolsv1beta1.NewForConfigOrDie(c)
+	cs.azureapplicationgatewayclassparametersV1beta1 = azureapplicationgatewayclassparametersv1beta1.NewForConfigOrDie(c)
 	cs.azureapplicationgatewayinstanceupdatestatusV1beta1 = azureapplicationgatewayinstanceupdatestatusv1beta1.NewForConfigOrDie(c)
 	cs.azureapplicationgatewayrewritesV1beta1 = azureapplicationgatewayrewritesv1beta1.NewForConfigOrDie(c)
+	cs.azureapplicationgatewayroutematchesV1beta1 = azureapplicationgatewayroutematchesv1beta1.NewForConfigOrDie(c)
 	cs.azureingressprohibitedtargetsV1 = azureingressprohibitedtargetsv1.NewForConfigOrDie(c)
 	cs.loaddistributionpoliciesV1beta1 = loaddistributionpoliciesv1beta1.NewForConfigOrDie(c)
 
@@ -143,8 +169,10 @@ func NewForConfigOrDie(c *rest.Config) *Clientset {
 func New(c rest.Interface) *Clientset {
 	var cs Clientset
 	cs.azureapplicationgatewaybackendpoolsV1beta1 = azureapplicationgatewaybackendpoolsv1beta1.New(c)
+	cs.azureapplicationgatewayclassparametersV1beta1 = azureapplicationgatewayclassparametersv1beta1.New(c)
 	cs.azureapplicationgatewayinstanceupdatestatusV1beta1 = azureapplicationgatewayinstanceupdatestatusv1beta1.New(c)
 	cs.azureapplicationgatewayrewritesV1beta1 = azureapplicationgatewayrewritesv1beta1.New(c)
+	cs.azureapplicationgatewayroutematchesV1beta1 = azureapplicationgatewayroutematchesv1beta1.New(c)
 	cs.azureingressprohibitedtargetsV1 = azureingressprohibitedtargetsv1.New(c)
 	cs.loaddistributionpoliciesV1beta1 = loaddistributionpoliciesv1beta1.New(c)
 
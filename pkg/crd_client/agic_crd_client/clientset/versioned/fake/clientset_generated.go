@@ -22,10 +22,14 @@ import (
 	clientset "github.com/Azure/application-gateway-kubernetes-ingress/pkg/crd_client/agic_crd_client/clientset/versioned"
 	azureapplicationgatewaybackendpoolsv1beta1 "github.com/Azure/application-gateway-kubernetes-ingress/pkg/crd_client/agic_crd_client/clientset/versioned/typed/azureapplicationgatewaybackendpool/v1beta1"
 	fakeazureapplicationgatewaybackendpoolsv1beta1 "github.com/Azure/application-gateway-kubernetes-ingress/pkg/crd_client/agic_crd_client/clientset/versioned/typed/azureapplicationgatewaybackendpool/v1beta1/fake"
+	azureapplicationgatewayclassparametersv1beta1 "github.com/Azure/application-gateway-kubernetes-ingress/pkg/crd_client/agic_crd_client/clientset/versioned/typed/azureapplicationgatewayclassparameters/v1beta1"
+	fakeazureapplicationgatewayclassparametersv1beta1 "github.com/Azure/application-gateway-kubernetes-ingress/pkg/crd_client/agic_crd_client/clientset/versioned/typed/azureapplicationgatewayclassparameters/v1beta1/fake"
 	azureapplicationgatewayinstanceupdatestatusv1beta1 "github.com/Azure/application-gateway-kubernetes-ingress/pkg/crd_client/agic_crd_client/clientset/versioned/typed/azureapplicationgatewayinstanceupdatestatus/v1beta1"
 	fakeazureapplicationgatewayinstanceupdatestatusv1beta1 "github.com/Azure/application-gateway-kubernetes-ingress/pkg/crd_client/agic_crd_client/clientset/versioned/typed/azureapplicationgatewayinstanceupdatestatus/v1beta1/fake"
 	azureapplicationgatewayrewritesv1beta1 "github.com/Azure/application-gateway-kubernetes-ingress/pkg/crd_client/agic_crd_client/clientset/versioned/typed/azureapplicationgatewayrewrite/v1beta1"
 	fakeazureapplicationgatewayrewritesv1beta1 "github.com/Azure/application-gateway-kubernetes-ingress/pkg/crd_client/agic_crd_client/clientset/versioned/typed/azureapplicationgatewayrewrite/v1beta1/fake"
+	azureapplicationgatewayroutematchesv1beta1 "github.com/Azure/application-gateway-kubernetes-ingress/pkg/crd_client/agic_crd_client/clientset/versioned/typed/azureapplicationgatewayroutematch/v1beta1"
+	fakeazureapplicationgatewayroutematchesv1beta1 "github.com/Azure/application-gateway-kubernetes-ingress/pkg/crd_client/agic_crd_client/clientset/versioned/typed/azureapplicationgatewayroutematch/v1beta1/fake"
 	azureingressprohibitedtargetsv1 "github.com/Azure/application-gateway-kubernetes-ingress/pkg/crd_client/agic_crd_client/clientset/versioned/typed/azureingressprohibitedtarget/v1"
 	fakeazureingressprohibitedtargetsv1 "github.com/Azure/application-gateway-kubernetes-ingress/pkg/crd_client/agic_crd_client/clientset/versioned/typed/azureingressprohibitedtarget/v1/fake"
 	loaddistributionpoliciesv1beta1 "github.com/Azure/application-gateway-kubernetes-ingress/pkg/crd_client/agic_crd_client/clientset/versioned/typed/loaddistributionpolicy/v1beta1"
@@ -89,6 +93,11 @@ func (c *Clientset) AzureapplicationgatewaybackendpoolsV1beta1() azureapplicatio
 	return &fakeazureapplicationgatewaybackendpoolsv1beta1.FakeAzureapplicationgatewaybackendpoolsV1beta1{Fake: &c.Fake}
 }
 
+// AzureapplicationgatewayclassparametersV1beta1 retrieves the AzureapplicationgatewayclassparametersV1beta1Client
+func (c *Clientset) AzureapplicationgatewayclassparametersV1beta1() azureapplicationgatewayclassparametersv1beta1.AzureapplicationgatewayclassparametersV1beta1Interface {
+	return &fakeazureapplicationgatewayclassparametersv1beta1.FakeAzureapplicationgatewayclassparametersV1beta1{Fake: &c.Fake}
+}
+
 // AzureapplicationgatewayinstanceupdatestatusV1beta1 retrieves the AzureapplicationgatewayinstanceupdatestatusV1beta1Client
 func (c *Clientset) AzureapplicationgatewayinstanceupdatestatusV1beta1() azureapplicationgatewayinstanceupdatestatusv1beta1.AzureapplicationgatewayinstanceupdatestatusV1beta1Interface {
 	return &fakeazureapplicationgatewayinstanceupdatestatusv1beta1.FakeAzureapplicationgatewayinstanceupdatestatusV1beta1{Fake: &c.Fake}
@@ -99,6 +108,11 @@ func (c *Clientset) AzureapplicationgatewayrewritesV1beta1() azureapplicationgat
 	return &fakeazureapplicationgatewayrewritesv1beta1.FakeAzureapplicationgatewayrewritesV1beta1{Fake: &c.Fake}
 }
 
+// AzureapplicationgatewayroutematchesV1beta1 retrieves the AzureapplicationgatewayroutematchesV1beta1Client
+func (c *Clientset) AzureapplicationgatewayroutematchesV1beta1() azureapplicationgatewayroutematchesv1beta1.AzureapplicationgatewayroutematchesV1beta1Interface {
+	return &fakeazureapplicationgatewayroutematchesv1beta1.FakeAzureapplicationgatewayroutematchesV1beta1{Fake: &c.Fake}
+}
+
 // AzureingressprohibitedtargetsV1 retrieves the AzureingressprohibitedtargetsV1Client
 func (c *Clientset) AzureingressprohibitedtargetsV1() azureingressprohibitedtargetsv1.AzureingressprohibitedtargetsV1Interface {
 	return &fakeazureingressprohibitedtargetsv1.FakeAzureingressprohibitedtargetsV1{Fake: &c.Fake}
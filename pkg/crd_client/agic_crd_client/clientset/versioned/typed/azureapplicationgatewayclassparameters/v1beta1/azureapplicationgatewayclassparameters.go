@@ -0,0 +1,168 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Code generated by client-gen. DO NOT EDIT.
+
+package v1beta1
+
+import (
+	"context"
+	"time"
+
+	v1beta1 "github.com/Azure/application-gateway-kubernetes-ingress/pkg/apis/azureapplicationgatewayclassparameters/v1beta1"
+	scheme "github.com/Azure/application-gateway-kubernetes-ingress/pkg/crd_client/agic_crd_client/clientset/versioned/scheme"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	types "k8s.io/apimachinery/pkg/types"
+	watch "k8s.io/apimachinery/pkg/watch"
+	rest "k8s.io/client-go/rest"
+)
+
+// AzureApplicationGatewayClassParametersGetter has a method to return a AzureApplicationGatewayClassParametersInterface.
+// A group's client should implement this interface.
+type AzureApplicationGatewayClassParametersGetter interface {
+	AzureApplicationGatewayClassParameters() AzureApplicationGatewayClassParametersInterface
+}
+
+// AzureApplicationGatewayClassParametersInterface has methods to work with AzureApplicationGatewayClassParameters resources.
+type AzureApplicationGatewayClassParametersInterface interface {
+	Create(ctx context.Context, azureApplicationGatewayClassParameters *v1beta1.AzureApplicationGatewayClassParameters, opts v1.CreateOptions) (*v1beta1.AzureApplicationGatewayClassParameters, error)
+	Update(ctx context.Context, azureApplicationGatewayClassParameters *v1beta1.AzureApplicationGatewayClassParameters, opts v1.UpdateOptions) (*v1beta1.AzureApplicationGatewayClassParameters, error)
+	Delete(ctx context.Context, name string, opts v1.DeleteOptions) error
+	DeleteCollection(ctx context.Context, opts v1.DeleteOptions, listOpts v1.ListOptions) error
+	Get(ctx context.Context, name string, opts v1.GetOptions) (*v1beta1.AzureApplicationGatewayClassParameters, error)
+	List(ctx context.Context, opts v1.ListOptions) (*v1beta1.AzureApplicationGatewayClassParametersList, error)
+	Watch(ctx context.Context, opts v1.ListOptions) (watch.Interface, error)
+	Patch(ctx context.Context, name string, pt types.PatchType, data []byte, opts v1.PatchOptions, subresources ...string) (result *v1beta1.AzureApplicationGatewayClassParameters, err error)
+	AzureApplicationGatewayClassParametersExpansion
+}
+
+// azureApplicationGatewayClassParameters implements AzureApplicationGatewayClassParametersInterface
+type azureApplicationGatewayClassParameters struct {
+	client rest.Interface
+}
+
+// newAzureApplicationGatewayClassParameters returns a AzureApplicationGatewayClassParameters
+func newAzureApplicationGatewayClassParameters(c *AzureapplicationgatewayclassparametersV1beta1Client) *azureApplicationGatewayClassParameters {
+	return &azureApplicationGatewayClassParameters{
+		client: c.RESTClient(),
+	}
+}
+
+// Get takes name of the azureApplicationGatewayClassParameters, and returns the corresponding azureApplicationGatewayClassParameters object, and an error if there is any.
+func (c *azureApplicationGatewayClassParameters) Get(ctx context.Context, name string, options v1.GetOptions) (result *v1beta1.AzureApplicationGatewayClassParameters, err error) {
+	result = &v1beta1.AzureApplicationGatewayClassParameters{}
+	err = c.client.Get().
+		Resource("azureapplicationgatewayclassparameters").
+		Name(name).
+		VersionedParams(&options, scheme.ParameterCodec).
+		Do(ctx).
+		Into(result)
+	return
+}
+
+// List takes label and field selectors, and returns the list of AzureApplicationGatewayClassParameters that match those selectors.
+func (c *azureApplicationGatewayClassParameters) List(ctx context.Context, opts v1.ListOptions) (result *v1beta1.AzureApplicationGatewayClassParametersList, err error) {
+	var timeout time.Duration
+	if opts.TimeoutSeconds != nil {
+		timeout = time.Duration(*opts.TimeoutSeconds) * time.Second
+	}
+	result = &v1beta1.AzureApplicationGatewayClassParametersList{}
+	err = c.client.Get().
+		Resource("azureapplicationgatewayclassparameters").
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Timeout(timeout).
+		Do(ctx).
+		Into(result)
+	return
+}
+
+// Watch returns a watch.Interface that watches the requested azureApplicationGatewayClassParameters.
+func (c *azureApplicationGatewayClassParameters) Watch(ctx context.Context, opts v1.ListOptions) (watch.Interface, error) {
+	var timeout time.Duration
+	if opts.TimeoutSeconds != nil {
+		timeout = time.Duration(*opts.TimeoutSeconds) * time.Second
+	}
+	opts.Watch = true
+	return c.client.Get().
+		Resource("azureapplicationgatewayclassparameters").
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Timeout(timeout).
+		Watch(ctx)
+}
+
+// Create takes the representation of a azureApplicationGatewayClassParameters and creates it.  Returns the server's representation of the azureApplicationGatewayClassParameters, and an error, if there is any.
+func (c *azureApplicationGatewayClassParameters) Create(ctx context.Context, azureApplicationGatewayClassParameters *v1beta1.AzureApplicationGatewayClassParameters, opts v1.CreateOptions) (result *v1beta1.AzureApplicationGatewayClassParameters, err error) {
+	result = &v1beta1.AzureApplicationGatewayClassParameters{}
+	err = c.client.Post().
+		Resource("azureapplicationgatewayclassparameters").
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Body(azureApplicationGatewayClassParameters).
+		Do(ctx).
+		Into(result)
+	return
+}
+
+// Update takes the representation of a azureApplicationGatewayClassParameters and updates it. Returns the server's representation of the azureApplicationGatewayClassParameters, and an error, if there is any.
+func (c *azureApplicationGatewayClassParameters) Update(ctx context.Context, azureApplicationGatewayClassParameters *v1beta1.AzureApplicationGatewayClassParameters, opts v1.UpdateOptions) (result *v1beta1.AzureApplicationGatewayClassParameters, err error) {
+	result = &v1beta1.AzureApplicationGatewayClassParameters{}
+	err = c.client.Put().
+		Resource("azureapplicationgatewayclassparameters").
+		Name(azureApplicationGatewayClassParameters.Name).
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Body(azureApplicationGatewayClassParameters).
+		Do(ctx).
+		Into(result)
+	return
+}
+
+// Delete takes name of the azureApplicationGatewayClassParameters and deletes it. Returns an error if one occurs.
+func (c *azureApplicationGatewayClassParameters) Delete(ctx context.Context, name string, opts v1.DeleteOptions) error {
+	return c.client.Delete().
+		Resource("azureapplicationgatewayclassparameters").
+		Name(name).
+		Body(&opts).
+		Do(ctx).
+		Error()
+}
+
+// DeleteCollection deletes a collection of objects.
+func (c *azureApplicationGatewayClassParameters) DeleteCollection(ctx context.Context, opts v1.DeleteOptions, listOpts v1.ListOptions) error {
+	var timeout time.Duration
+	if listOpts.TimeoutSeconds != nil {
+		timeout = time.Duration(*listOpts.TimeoutSeconds) * time.Second
+	}
+	return c.client.Delete().
+		Resource("azureapplicationgatewayclassparameters").
+		VersionedParams(&listOpts, scheme.ParameterCodec).
+		Timeout(timeout).
+		Body(&opts).
+		Do(ctx).
+		Error()
+}
+
+// Patch applies the patch and returns the patched azureApplicationGatewayClassParameters.
+func (c *azureApplicationGatewayClassParameters) Patch(ctx context.Context, name string, pt types.PatchType, data []byte, opts v1.PatchOptions, subresources ...string) (result *v1beta1.AzureApplicationGatewayClassParameters, err error) {
+	result = &v1beta1.AzureApplicationGatewayClassParameters{}
+	err = c.client.Patch(pt).
+		Resource("azureapplicationgatewayclassparameters").
+		Name(name).
+		SubResource(subresources...).
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Body(data).
+		Do(ctx).
+		Into(result)
+	return
+}
@@ -0,0 +1,122 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Code generated by client-gen. DO NOT EDIT.
+
+package fake
+
+import (
+	"context"
+
+	v1beta1 "github.com/Azure/application-gateway-kubernetes-ingress/pkg/apis/azureapplicationgatewayclassparameters/v1beta1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	labels "k8s.io/apimachinery/pkg/labels"
+	schema "k8s.io/apimachinery/pkg/runtime/schema"
+	types "k8s.io/apimachinery/pkg/types"
+	watch "k8s.io/apimachinery/pkg/watch"
+	testing "k8s.io/client-go/testing"
+)
+
+// FakeAzureApplicationGatewayClassParameters implements AzureApplicationGatewayClassParametersInterface
+type FakeAzureApplicationGatewayClassParameters struct {
+	Fake *FakeAzureapplicationgatewayclassparametersV1beta1
+}
+
+var azureapplicationgatewayclassparametersResource = schema.GroupVersionResource{Group: "azureapplicationgatewayclassparameters.appgw.ingress.azure.io", Version: "v1beta1", Resource: "azureapplicationgatewayclassparameters"}
+
+var azureapplicationgatewayclassparametersKind = schema.GroupVersionKind{Group: "azureapplicationgatewayclassparameters.appgw.ingress.azure.io", Version: "v1beta1", Kind: "AzureApplicationGatewayClassParameters"}
+
+// Get takes name of the azureApplicationGatewayClassParameters, and returns the corresponding azureApplicationGatewayClassParameters object, and an error if there is any.
+func (c *FakeAzureApplicationGatewayClassParameters) Get(ctx context.Context, name string, options v1.GetOptions) (result *v1beta1.AzureApplicationGatewayClassParameters, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewRootGetAction(azureapplicationgatewayclassparametersResource, name), &v1beta1.AzureApplicationGatewayClassParameters{})
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1beta1.AzureApplicationGatewayClassParameters), err
+}
+
+// List takes label and field selectors, and returns the list of AzureApplicationGatewayClassParameters that match those selectors.
+func (c *FakeAzureApplicationGatewayClassParameters) List(ctx context.Context, opts v1.ListOptions) (result *v1beta1.AzureApplicationGatewayClassParametersList, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewRootListAction(azureapplicationgatewayclassparametersResource, azureapplicationgatewayclassparametersKind, opts), &v1beta1.AzureApplicationGatewayClassParametersList{})
+	if obj == nil {
+		return nil, err
+	}
+
+	label, _, _ := testing.ExtractFromListOptions(opts)
+	if label == nil {
+		label = labels.Everything()
+	}
+	list := &v1beta1.AzureApplicationGatewayClassParametersList{ListMeta: obj.(*v1beta1.AzureApplicationGatewayClassParametersList).ListMeta}
+	for _, item := range obj.(*v1beta1.AzureApplicationGatewayClassParametersList).Items {
+		if label.Matches(labels.Set(item.Labels)) {
+			list.Items = append(list.Items, item)
+		}
+	}
+	return list, err
+}
+
+// Watch returns a watch.Interface that watches the requested azureApplicationGatewayClassParameters.
+func (c *FakeAzureApplicationGatewayClassParameters) Watch(ctx context.Context, opts v1.ListOptions) (watch.Interface, error) {
+	return c.Fake.
+		InvokesWatch(testing.NewRootWatchAction(azureapplicationgatewayclassparametersResource, opts))
+}
+
+// Create takes the representation of a azureApplicationGatewayClassParameters and creates it.  Returns the server's representation of the azureApplicationGatewayClassParameters, and an error, if there is any.
+func (c *FakeAzureApplicationGatewayClassParameters) Create(ctx context.Context, azureApplicationGatewayClassParameters *v1beta1.AzureApplicationGatewayClassParameters, opts v1.CreateOptions) (result *v1beta1.AzureApplicationGatewayClassParameters, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewRootCreateAction(azureapplicationgatewayclassparametersResource, azureApplicationGatewayClassParameters), &v1beta1.AzureApplicationGatewayClassParameters{})
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1beta1.AzureApplicationGatewayClassParameters), err
+}
+
+// Update takes the representation of a azureApplicationGatewayClassParameters and updates it. Returns the server's representation of the azureApplicationGatewayClassParameters, and an error, if there is any.
+func (c *FakeAzureApplicationGatewayClassParameters) Update(ctx context.Context, azureApplicationGatewayClassParameters *v1beta1.AzureApplicationGatewayClassParameters, opts v1.UpdateOptions) (result *v1beta1.AzureApplicationGatewayClassParameters, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewRootUpdateAction(azureapplicationgatewayclassparametersResource, azureApplicationGatewayClassParameters), &v1beta1.AzureApplicationGatewayClassParameters{})
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1beta1.AzureApplicationGatewayClassParameters), err
+}
+
+// Delete takes name of the azureApplicationGatewayClassParameters and deletes it. Returns an error if one occurs.
+func (c *FakeAzureApplicationGatewayClassParameters) Delete(ctx context.Context, name string, opts v1.DeleteOptions) error {
+	_, err := c.Fake.
+		Invokes(testing.NewRootDeleteAction(azureapplicationgatewayclassparametersResource, name), &v1beta1.AzureApplicationGatewayClassParameters{})
+	return err
+}
+
+// DeleteCollection deletes a collection of objects.
+func (c *FakeAzureApplicationGatewayClassParameters) DeleteCollection(ctx context.Context, opts v1.DeleteOptions, listOpts v1.ListOptions) error {
+	action := testing.NewRootDeleteCollectionAction(azureapplicationgatewayclassparametersResource, listOpts)
+
+	_, err := c.Fake.Invokes(action, &v1beta1.AzureApplicationGatewayClassParametersList{})
+	return err
+}
+
+// Patch applies the patch and returns the patched azureApplicationGatewayClassParameters.
+func (c *FakeAzureApplicationGatewayClassParameters) Patch(ctx context.Context, name string, pt types.PatchType, data []byte, opts v1.PatchOptions, subresources ...string) (result *v1beta1.AzureApplicationGatewayClassParameters, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewRootPatchSubresourceAction(azureapplicationgatewayclassparametersResource, name, pt, data, subresources...), &v1beta1.AzureApplicationGatewayClassParameters{})
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1beta1.AzureApplicationGatewayClassParameters), err
+}
@@ -0,0 +1,178 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Code generated by client-gen. DO NOT EDIT.
+
+package v1beta1
+
+import (
+	"context"
+	"time"
+
+	v1beta1 "github.com/Azure/application-gateway-kubernetes-ingress/pkg/apis/azureapplicationgatewayroutematch/v1beta1"
+	scheme "github.com/Azure/application-gateway-kubernetes-ingress/pkg/crd_client/agic_crd_client/clientset/versioned/scheme"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	types "k8s.io/apimachinery/pkg/types"
+	watch "k8s.io/apimachinery/pkg/watch"
+	rest "k8s.io/client-go/rest"
+)
+
+// AzureApplicationGatewayRouteMatchesGetter has a method to return a AzureApplicationGatewayRouteMatchInterface.
+// A group's client should implement this interface.
+type AzureApplicationGatewayRouteMatchesGetter interface {
+	AzureApplicationGatewayRouteMatches(namespace string) AzureApplicationGatewayRouteMatchInterface
+}
+
+// AzureApplicationGatewayRouteMatchInterface has methods to work with AzureApplicationGatewayRouteMatch resources.
+type AzureApplicationGatewayRouteMatchInterface interface {
+	Create(ctx context.Context, azureApplicationGatewayRouteMatch *v1beta1.AzureApplicationGatewayRouteMatch, opts v1.CreateOptions) (*v1beta1.AzureApplicationGatewayRouteMatch, error)
+	Update(ctx context.Context, azureApplicationGatewayRouteMatch *v1beta1.AzureApplicationGatewayRouteMatch, opts v1.UpdateOptions) (*v1beta1.AzureApplicationGatewayRouteMatch, error)
+	Delete(ctx context.Context, name string, opts v1.DeleteOptions) error
+	DeleteCollection(ctx context.Context, opts v1.DeleteOptions, listOpts v1.ListOptions) error
+	Get(ctx context.Context, name string, opts v1.GetOptions) (*v1beta1.AzureApplicationGatewayRouteMatch, error)
+	List(ctx context.Context, opts v1.ListOptions) (*v1beta1.AzureApplicationGatewayRouteMatchList, error)
+	Watch(ctx context.Context, opts v1.ListOptions) (watch.Interface, error)
+	Patch(ctx context.Context, name string, pt types.PatchType, data []byte, opts v1.PatchOptions, subresources ...string) (result *v1beta1.AzureApplicationGatewayRouteMatch, err error)
+	AzureApplicationGatewayRouteMatchExpansion
+}
+
+// azureApplicationGatewayRouteMatches implements AzureApplicationGatewayRouteMatchInterface
+type azureApplicationGatewayRouteMatches struct {
+	client rest.Interface
+	ns     string
+}
+
+// newAzureApplicationGatewayRouteMatches returns a AzureApplicationGatewayRouteMatches
+func newAzureApplicationGatewayRouteMatches(c *AzureapplicationgatewayroutematchesV1beta1Client, namespace string) *azureApplicationGatewayRouteMatches {
+	return &azureApplicationGatewayRouteMatches{
+		client: c.RESTClient(),
+		ns:     namespace,
+	}
+}
+
+// Get takes name of the azureApplicationGatewayRouteMatch, and returns the corresponding azureApplicationGatewayRouteMatch object, and an error if there is any.
+func (c *azureApplicationGatewayRouteMatches) Get(ctx context.Context, name string, options v1.GetOptions) (result *v1beta1.AzureApplicationGatewayRouteMatch, err error) {
+	result = &v1beta1.AzureApplicationGatewayRouteMatch{}
+	err = c.client.Get().
+		Namespace(c.ns).
+		Resource("azureapplicationgatewayroutematches").
+		Name(name).
+		VersionedParams(&options, scheme.ParameterCodec).
+		Do(ctx).
+		Into(result)
+	return
+}
+
+// List takes label and field selectors, and returns the list of AzureApplicationGatewayRouteMatches that match those selectors.
+func (c *azureApplicationGatewayRouteMatches) List(ctx context.Context, opts v1.ListOptions) (result *v1beta1.AzureApplicationGatewayRouteMatchList, err error) {
+	var timeout time.Duration
+	if opts.TimeoutSeconds != nil {
+		timeout = time.Duration(*opts.TimeoutSeconds) * time.Second
+	}
+	result = &v1beta1.AzureApplicationGatewayRouteMatchList{}
+	err = c.client.Get().
+		Namespace(c.ns).
+		Resource("azureapplicationgatewayroutematches").
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Timeout(timeout).
+		Do(ctx).
+		Into(result)
+	return
+}
+
+// Watch returns a watch.Interface that watches the requested azureApplicationGatewayRouteMatches.
+func (c *azureApplicationGatewayRouteMatches) Watch(ctx context.Context, opts v1.ListOptions) (watch.Interface, error) {
+	var timeout time.Duration
+	if opts.TimeoutSeconds != nil {
+		timeout = time.Duration(*opts.TimeoutSeconds) * time.Second
+	}
+	opts.Watch = true
+	return c.client.Get().
+		Namespace(c.ns).
+		Resource("azureapplicationgatewayroutematches").
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Timeout(timeout).
+		Watch(ctx)
+}
+
+// Create takes the representation of a azureApplicationGatewayRouteMatch and creates it.  Returns the server's representation of the azureApplicationGatewayRouteMatch, and an error, if there is any.
+func (c *azureApplicationGatewayRouteMatches) Create(ctx context.Context, azureApplicationGatewayRouteMatch *v1beta1.AzureApplicationGatewayRouteMatch, opts v1.CreateOptions) (result *v1beta1.AzureApplicationGatewayRouteMatch, err error) {
+	result = &v1beta1.AzureApplicationGatewayRouteMatch{}
+	err = c.client.Post().
+		Namespace(c.ns).
+		Resource("azureapplicationgatewayroutematches").
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Body(azureApplicationGatewayRouteMatch).
+		Do(ctx).
+		Into(result)
+	return
+}
+
+// Update takes the representation of a azureApplicationGatewayRouteMatch and updates it. Returns the server's representation of the azureApplicationGatewayRouteMatch, and an error, if there is any.
+func (c *azureApplicationGatewayRouteMatches) Update(ctx context.Context, azureApplicationGatewayRouteMatch *v1beta1.AzureApplicationGatewayRouteMatch, opts v1.UpdateOptions) (result *v1beta1.AzureApplicationGatewayRouteMatch, err error) {
+	result = &v1beta1.AzureApplicationGatewayRouteMatch{}
+	err = c.client.Put().
+		Namespace(c.ns).
+		Resource("azureapplicationgatewayroutematches").
+		Name(azureApplicationGatewayRouteMatch.Name).
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Body(azureApplicationGatewayRouteMatch).
+		Do(ctx).
+		Into(result)
+	return
+}
+
+// Delete takes name of the azureApplicationGatewayRouteMatch and deletes it. Returns an error if one occurs.
+func (c *azureApplicationGatewayRouteMatches) Delete(ctx context.Context, name string, opts v1.DeleteOptions) error {
+	return c.client.Delete().
+		Namespace(c.ns).
+		Resource("azureapplicationgatewayroutematches").
+		Name(name).
+		Body(&opts).
+		Do(ctx).
+		Error()
+}
+
+// DeleteCollection deletes a collection of objects.
+func (c *azureApplicationGatewayRouteMatches) DeleteCollection(ctx context.Context, opts v1.DeleteOptions, listOpts v1.ListOptions) error {
+	var timeout time.Duration
+	if listOpts.TimeoutSeconds != nil {
+		timeout = time.Duration(*listOpts.TimeoutSeconds) * time.Second
+	}
+	return c.client.Delete().
+		Namespace(c.ns).
+		Resource("azureapplicationgatewayroutematches").
+		VersionedParams(&listOpts, scheme.ParameterCodec).
+		Timeout(timeout).
+		Body(&opts).
+		Do(ctx).
+		Error()
+}
+
+// Patch applies the patch and returns the patched azureApplicationGatewayRouteMatch.
+func (c *azureApplicationGatewayRouteMatches) Patch(ctx context.Context, name string, pt types.PatchType, data []byte, opts v1.PatchOptions, subresources ...string) (result *v1beta1.AzureApplicationGatewayRouteMatch, err error) {
+	result = &v1beta1.AzureApplicationGatewayRouteMatch{}
+	err = c.client.Patch(pt).
+		Namespace(c.ns).
+		Resource("azureapplicationgatewayroutematches").
+		Name(name).
+		SubResource(subresources...).
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Body(data).
+		Do(ctx).
+		Into(result)
+	return
+}
@@ -0,0 +1,130 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Code generated by client-gen. DO NOT EDIT.
+
+package fake
+
+import (
+	"context"
+
+	v1beta1 "github.com/Azure/application-gateway-kubernetes-ingress/pkg/apis/azureapplicationgatewayroutematch/v1beta1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	labels "k8s.io/apimachinery/pkg/labels"
+	schema "k8s.io/apimachinery/pkg/runtime/schema"
+	types "k8s.io/apimachinery/pkg/types"
+	watch "k8s.io/apimachinery/pkg/watch"
+	testing "k8s.io/client-go/testing"
+)
+
+// FakeAzureApplicationGatewayRouteMatches implements AzureApplicationGatewayRouteMatchInterface
+type FakeAzureApplicationGatewayRouteMatches struct {
+	Fake *FakeAzureapplicationgatewayroutematchesV1beta1
+	ns   string
+}
+
+var azureapplicationgatewayroutematchesResource = schema.GroupVersionResource{Group: "azureapplicationgatewayroutematches.appgw.ingress.azure.io", Version: "v1beta1", Resource: "azureapplicationgatewayroutematches"}
+
+var azureapplicationgatewayroutematchesKind = schema.GroupVersionKind{Group: "azureapplicationgatewayroutematches.appgw.ingress.azure.io", Version: "v1beta1", Kind: "AzureApplicationGatewayRouteMatch"}
+
+// Get takes name of the azureApplicationGatewayRouteMatch, and returns the corresponding azureApplicationGatewayRouteMatch object, and an error if there is any.
+func (c *FakeAzureApplicationGatewayRouteMatches) Get(ctx context.Context, name string, options v1.GetOptions) (result *v1beta1.AzureApplicationGatewayRouteMatch, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewGetAction(azureapplicationgatewayroutematchesResource, c.ns, name), &v1beta1.AzureApplicationGatewayRouteMatch{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1beta1.AzureApplicationGatewayRouteMatch), err
+}
+
+// List takes label and field selectors, and returns the list of AzureApplicationGatewayRouteMatches that match those selectors.
+func (c *FakeAzureApplicationGatewayRouteMatches) List(ctx context.Context, opts v1.ListOptions) (result *v1beta1.AzureApplicationGatewayRouteMatchList, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewListAction(azureapplicationgatewayroutematchesResource, azureapplicationgatewayroutematchesKind, c.ns, opts), &v1beta1.AzureApplicationGatewayRouteMatchList{})
+
+	if obj == nil {
+		return nil, err
+	}
+
+	label, _, _ := testing.ExtractFromListOptions(opts)
+	if label == nil {
+		label = labels.Everything()
+	}
+	list := &v1beta1.AzureApplicationGatewayRouteMatchList{ListMeta: obj.(*v1beta1.AzureApplicationGatewayRouteMatchList).ListMeta}
+	for _, item := range obj.(*v1beta1.AzureApplicationGatewayRouteMatchList).Items {
+		if label.Matches(labels.Set(item.Labels)) {
+			list.Items = append(list.Items, item)
+		}
+	}
+	return list, err
+}
+
+// Watch returns a watch.Interface that watches the requested azureApplicationGatewayRouteMatches.
+func (c *FakeAzureApplicationGatewayRouteMatches) Watch(ctx context.Context, opts v1.ListOptions) (watch.Interface, error) {
+	return c.Fake.
+		InvokesWatch(testing.NewWatchAction(azureapplicationgatewayroutematchesResource, c.ns, opts))
+
+}
+
+// Create takes the representation of a azureApplicationGatewayRouteMatch and creates it.  Returns the server's representation of the azureApplicationGatewayRouteMatch, and an error, if there is any.
+func (c *FakeAzureApplicationGatewayRouteMatches) Create(ctx context.Context, azureApplicationGatewayRouteMatch *v1beta1.AzureApplicationGatewayRouteMatch, opts v1.CreateOptions) (result *v1beta1.AzureApplicationGatewayRouteMatch, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewCreateAction(azureapplicationgatewayroutematchesResource, c.ns, azureApplicationGatewayRouteMatch), &v1beta1.AzureApplicationGatewayRouteMatch{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1beta1.AzureApplicationGatewayRouteMatch), err
+}
+
+// Update takes the representation of a azureApplicationGatewayRouteMatch and updates it. Returns the server's representation of the azureApplicationGatewayRouteMatch, and an error, if there is any.
+func (c *FakeAzureApplicationGatewayRouteMatches) Update(ctx context.Context, azureApplicationGatewayRouteMatch *v1beta1.AzureApplicationGatewayRouteMatch, opts v1.UpdateOptions) (result *v1beta1.AzureApplicationGatewayRouteMatch, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewUpdateAction(azureapplicationgatewayroutematchesResource, c.ns, azureApplicationGatewayRouteMatch), &v1beta1.AzureApplicationGatewayRouteMatch{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1beta1.AzureApplicationGatewayRouteMatch), err
+}
+
+// Delete takes name of the azureApplicationGatewayRouteMatch and deletes it. Returns an error if one occurs.
+func (c *FakeAzureApplicationGatewayRouteMatches) Delete(ctx context.Context, name string, opts v1.DeleteOptions) error {
+	_, err := c.Fake.
+		Invokes(testing.NewDeleteAction(azureapplicationgatewayroutematchesResource, c.ns, name), &v1beta1.AzureApplicationGatewayRouteMatch{})
+
+	return err
+}
+
+// DeleteCollection deletes a collection of objects.
+func (c *FakeAzureApplicationGatewayRouteMatches) DeleteCollection(ctx context.Context, opts v1.DeleteOptions, listOpts v1.ListOptions) error {
+	action := testing.NewDeleteCollectionAction(azureapplicationgatewayroutematchesResource, c.ns, listOpts)
+
+	_, err := c.Fake.Invokes(action, &v1beta1.AzureApplicationGatewayRouteMatchList{})
+	return err
+}
+
+// Patch applies the patch and returns the patched azureApplicationGatewayRouteMatch.
+func (c *FakeAzureApplicationGatewayRouteMatches) Patch(ctx context.Context, name string, pt types.PatchType, data []byte, opts v1.PatchOptions, subresources ...string) (result *v1beta1.AzureApplicationGatewayRouteMatch, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewPatchSubresourceAction(azureapplicationgatewayroutematchesResource, c.ns, name, pt, data, subresources...), &v1beta1.AzureApplicationGatewayRouteMatch{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1beta1.AzureApplicationGatewayRouteMatch), err
+}
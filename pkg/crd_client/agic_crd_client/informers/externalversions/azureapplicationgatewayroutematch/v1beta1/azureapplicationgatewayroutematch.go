@@ -0,0 +1,90 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Code generated by informer-gen. DO NOT EDIT.
+
+package v1beta1
+
+import (
+	"context"
+	time "time"
+
+	azureapplicationgatewayroutematchv1beta1 "github.com/Azure/application-gateway-kubernetes-ingress/pkg/apis/azureapplicationgatewayroutematch/v1beta1"
+	versioned "github.com/Azure/application-gateway-kubernetes-ingress/pkg/crd_client/agic_crd_client/clientset/versioned"
+	internalinterfaces "github.com/Azure/application-gateway-kubernetes-ingress/pkg/crd_client/agic_crd_client/informers/externalversions/internalinterfaces"
+	v1beta1 "github.com/Azure/application-gateway-kubernetes-ingress/pkg/crd_client/agic_crd_client/listers/azureapplicationgatewayroutematch/v1beta1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	runtime "k8s.io/apimachinery/pkg/runtime"
+	watch "k8s.io/apimachinery/pkg/watch"
+	cache "k8s.io/client-go/tools/cache"
+)
+
+// AzureApplicationGatewayRouteMatchInformer provides access to a shared informer and lister for
+// AzureApplicationGatewayRouteMatches.
+type AzureApplicationGatewayRouteMatchInformer interface {
+	Informer() cache.SharedIndexInformer
+	Lister() v1beta1.AzureApplicationGatewayRouteMatchLister
+}
+
+type azureApplicationGatewayRouteMatchInformer struct {
+	factory          internalinterfaces.SharedInformerFactory
+	tweakListOptions internalinterfaces.TweakListOptionsFunc
+	namespace        string
+}
+
+// NewAzureApplicationGatewayRouteMatchInformer constructs a new informer for AzureApplicationGatewayRouteMatch type.
+// Always prefer using an informer factory to get a shared informer instead of getting an independent
+// one. This reduces memory footprint and number of connections to the server.
+func NewAzureApplicationGatewayRouteMatchInformer(client versioned.Interface, namespace string, resyncPeriod time.Duration, indexers cache.Indexers) cache.SharedIndexInformer {
+	return NewFilteredAzureApplicationGatewayRouteMatchInformer(client, namespace, resyncPeriod, indexers, nil)
+}
+
+// NewFilteredAzureApplicationGatewayRouteMatchInformer constructs a new informer for AzureApplicationGatewayRouteMatch type.
+// Always prefer using an informer factory to get a shared informer instead of getting an independent
+// one. This reduces memory footprint and number of connections to the server.
+func NewFilteredAzureApplicationGatewayRouteMatchInformer(client versioned.Interface, namespace string, resyncPeriod time.Duration, indexers cache.Indexers, tweakListOptions internalinterfaces.TweakListOptionsFunc) cache.SharedIndexInformer {
+	return cache.NewSharedIndexInformer(
+		&cache.ListWatch{
+			ListFunc: func(options v1.ListOptions) (runtime.Object, error) {
+				if tweakListOptions != nil {
+					tweakListOptions(&options)
+				}
+				return client.AzureapplicationgatewayroutematchesV1beta1().AzureApplicationGatewayRouteMatches(namespace).List(context.TODO(), options)
+			},
+			WatchFunc: func(options v1.ListOptions) (watch.Interface, error) {
+				if tweakListOptions != nil {
+					tweakListOptions(&options)
+				}
+				return client.AzureapplicationgatewayroutematchesV1beta1().AzureApplicationGatewayRouteMatches(namespace).Watch(context.TODO(), options)
+			},
+		},
+		&azureapplicationgatewayroutematchv1beta1.AzureApplicationGatewayRouteMatch{},
+		resyncPeriod,
+		indexers,
+	)
+}
+
+func (f *azureApplicationGatewayRouteMatchInformer) defaultInformer(client versioned.Interface, resyncPeriod time.Duration) cache.SharedIndexInformer {
+	return NewFilteredAzureApplicationGatewayRouteMatchInformer(client, f.namespace, resyncPeriod, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc}, f.tweakListOptions)
+}
+
+func (f *azureApplicationGatewayRouteMatchInformer) Informer() cache.SharedIndexInformer {
+	return f.factory.InformerFor(&azureapplicationgatewayroutematchv1beta1.AzureApplicationGatewayRouteMatch{}, f.defaultInformer)
+}
+
+func (f *azureApplicationGatewayRouteMatchInformer) Lister() v1beta1.AzureApplicationGatewayRouteMatchLister {
+	return v1beta1.NewAzureApplicationGatewayRouteMatchLister(f.Informer().GetIndexer())
+}
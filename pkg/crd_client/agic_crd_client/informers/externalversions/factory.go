@@ -25,8 +25,10 @@ import (
 
 	versioned "github.com/Azure/application-gateway-kubernetes-ingress/pkg/crd_client/agic_crd_client/clientset/versioned"
 	azureapplicationgatewaybackendpool "github.com/Azure/application-gateway-kubernetes-ingress/pkg/crd_client/agic_crd_client/informers/externalversions/azureapplicationgatewaybackendpool"
+	azureapplicationgatewayclassparameters "github.com/Azure/application-gateway-kubernetes-ingress/pkg/crd_client/agic_crd_client/informers/externalversions/azureapplicationgatewayclassparameters"
 	azureapplicationgatewayinstanceupdatestatus "github.com/Azure/application-gateway-kubernetes-ingress/pkg/crd_client/agic_crd_client/informers/externalversions/azureapplicationgatewayinstanceupdatestatus"
 	azureapplicationgatewayrewrite "github.com/Azure/application-gateway-kubernetes-ingress/pkg/crd_client/agic_crd_client/informers/externalversions/azureapplicationgatewayrewrite"
+	azureapplicationgatewayroutematch "github.com/Azure/application-gateway-kubernetes-ingress/pkg/crd_client/agic_crd_client/informers/externalversions/azureapplicationgatewayroutematch"
 	azureingressprohibitedtarget "github.com/Azure/application-gateway-kubernetes-ingress/pkg/crd_client/agic_crd_client/informers/externalversions/azureingressprohibitedtarget"
 	internalinterfaces "github.com/Azure/application-gateway-kubernetes-ingress/pkg/crd_client/agic_crd_client/informers/externalversions/internalinterfaces"
 	loaddistributionpolicy "github.com/Azure/application-gateway-kubernetes-ingress/pkg/crd_client/agic_crd_client/informers/externalversions/loaddistributionpolicy"
@@ -177,8 +179,10 @@ type SharedInformerFactory interface {
 	WaitForCacheSync(stopCh <-chan struct{}) map[reflect.Type]bool
 
 	Azureapplicationgatewaybackendpools() azureapplicationgatewaybackendpool.Interface
+	Azureapplicationgatewayclassparameters() azureapplicationgatewayclassparameters.Interface
 	Azureapplicationgatewayinstanceupdatestatus() azureapplicationgatewayinstanceupdatestatus.Interface
 	Azureapplicationgatewayrewrites() azureapplicationgatewayrewrite.Interface
+	Azureapplicationgatewayroutematches() azureapplicationgatewayroutematch.Interface
 	Azureingressprohibitedtargets() azureingressprohibitedtarget.Interface
 	Loaddistributionpolicies() loaddistributionpolicy.Interface
 }
@@ -187,6 +191,10 @@ func (f *sharedInformerFactory) Azureapplicationgatewaybackendpools() azureappli
 	return azureapplicationgatewaybackendpool.New(f, f.namespace, f.tweakListOptions)
 }
 
+func (f *sharedInformerFactory) Azureapplicationgatewayclassparameters() azureapplicationgatewayclassparameters.Interface {
+	return azureapplicationgatewayclassparameters.New(f, f.namespace, f.tweakListOptions)
+}
+
 func (f *sharedInformerFactory) Azureapplicationgatewayinstanceupdatestatus() azureapplicationgatewayinstanceupdatestatus.Interface {
 	return azureapplicationgatewayinstanceupdatestatus.New(f, f.namespace, f.tweakListOptions)
 }
@@ -195,6 +203,10 @@ func (f *sharedInformerFactory) Azureapplicationgatewayrewrites() azureapplicati
 	return azureapplicationgatewayrewrite.New(f, f.namespace, f.tweakListOptions)
 }
 
+func (f *sharedInformerFactory) Azureapplicationgatewayroutematches() azureapplicationgatewayroutematch.Interface {
+	return azureapplicationgatewayroutematch.New(f, f.namespace, f.tweakListOptions)
+}
+
 func (f *sharedInformerFactory) Azureingressprohibitedtargets() azureingressprohibitedtarget.Interface {
 	return azureingressprohibitedtarget.New(f, f.namespace, f.tweakListOptions)
 }
@@ -22,8 +22,10 @@ import (
 	"fmt"
 
 	v1beta1 "github.com/Azure/application-gateway-kubernetes-ingress/pkg/apis/azureapplicationgatewaybackendpool/v1beta1"
+	azureapplicationgatewayclassparametersv1beta1 "github.com/Azure/application-gateway-kubernetes-ingress/pkg/apis/azureapplicationgatewayclassparameters/v1beta1"
 	azureapplicationgatewayinstanceupdatestatusv1beta1 "github.com/Azure/application-gateway-kubernetes-ingress/pkg/apis/azureapplicationgatewayinstanceupdatestatus/v1beta1"
 	azureapplicationgatewayrewritev1beta1 "github.com/Azure/application-gateway-kubernetes-ingress/pkg/apis/azureapplicationgatewayrewrite/v1beta1"
+	azureapplicationgatewayroutematchv1beta1 "github.com/Azure/application-gateway-kubernetes-ingress/pkg/apis/azureapplicationgatewayroutematch/v1beta1"
 	v1 "github.com/Azure/application-gateway-kubernetes-ingress/pkg/apis/azureingressprohibitedtarget/v1"
 	loaddistributionpolicyv1beta1 "github.com/Azure/application-gateway-kubernetes-ingress/pkg/apis/loaddistributionpolicy/v1beta1"
 	schema "k8s.io/apimachinery/pkg/runtime/schema"
@@ -60,6 +62,10 @@ func (f *sharedInformerFactory) ForResource(resource schema.GroupVersionResource
 	case v1beta1.SchemeGroupVersion.WithResource("azureapplicationgatewaybackendpools"):
 		return &genericInformer{resource: resource.GroupResource(), informer: f.Azureapplicationgatewaybackendpools().V1beta1().AzureApplicationGatewayBackendPools().Informer()}, nil
 
+		// Group=azureapplicationgatewayclassparameters.appgw.ingress.azure.io, Version=v1beta1
+	case azureapplicationgatewayclassparametersv1beta1.SchemeGroupVersion.WithResource("azureapplicationgatewayclassparameters"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Azureapplicationgatewayclassparameters().V1beta1().AzureApplicationGatewayClassParameters().Informer()}, nil
+
 		// Group=azureapplicationgatewayinstanceupdatestatus.appgw.ingress.azure.io, Version=v1beta1
 	case azureapplicationgatewayinstanceupdatestatusv1beta1.SchemeGroupVersion.WithResource("azureapplicationgatewayinstanceupdatestatuses"):
 		return &genericInformer{resource: resource.GroupResource(), informer: f.Azureapplicationgatewayinstanceupdatestatus().V1beta1().AzureApplicationGatewayInstanceUpdateStatuses().Informer()}, nil
@@ -68,6 +74,10 @@ func (f *sharedInformerFactory) ForResource(resource schema.GroupVersionResource
 	case azureapplicationgatewayrewritev1beta1.SchemeGroupVersion.WithResource("azureapplicationgatewayrewrites"):
 		return &genericInformer{resource: resource.GroupResource(), informer: f.Azureapplicationgatewayrewrites().V1beta1().AzureApplicationGatewayRewrites().Informer()}, nil
 
+		// Group=azureapplicationgatewayroutematches.appgw.ingress.azure.io, Version=v1beta1
+	case azureapplicationgatewayroutematchv1beta1.SchemeGroupVersion.WithResource("azureapplicationgatewayroutematches"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Azureapplicationgatewayroutematches().V1beta1().AzureApplicationGatewayRouteMatches().Informer()}, nil
+
 		// Group=azureingressprohibitedtargets.appgw.ingress.k8s.io, Version=v1
 	case v1.SchemeGroupVersion.WithResource("azureingressprohibitedtargets"):
 		return &genericInformer{resource: resource.GroupResource(), informer: f.Azureingressprohibitedtargets().V1().AzureIngressProhibitedTargets().Informer()}, nil
@@ -0,0 +1,68 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Code generated by lister-gen. DO NOT EDIT.
+
+package v1beta1
+
+import (
+	v1beta1 "github.com/Azure/application-gateway-kubernetes-ingress/pkg/apis/azureapplicationgatewayclassparameters/v1beta1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/tools/cache"
+)
+
+// AzureApplicationGatewayClassParametersLister helps list AzureApplicationGatewayClassParameters.
+// All objects returned here must be treated as read-only.
+type AzureApplicationGatewayClassParametersLister interface {
+	// List lists all AzureApplicationGatewayClassParameters in the indexer.
+	// Objects returned here must be treated as read-only.
+	List(selector labels.Selector) (ret []*v1beta1.AzureApplicationGatewayClassParameters, err error)
+	// Get retrieves the AzureApplicationGatewayClassParameters from the index for a given name.
+	// Objects returned here must be treated as read-only.
+	Get(name string) (*v1beta1.AzureApplicationGatewayClassParameters, error)
+	AzureApplicationGatewayClassParametersListerExpansion
+}
+
+// azureApplicationGatewayClassParametersLister implements the AzureApplicationGatewayClassParametersLister interface.
+type azureApplicationGatewayClassParametersLister struct {
+	indexer cache.Indexer
+}
+
+// NewAzureApplicationGatewayClassParametersLister returns a new AzureApplicationGatewayClassParametersLister.
+func NewAzureApplicationGatewayClassParametersLister(indexer cache.Indexer) AzureApplicationGatewayClassParametersLister {
+	return &azureApplicationGatewayClassParametersLister{indexer: indexer}
+}
+
+// List lists all AzureApplicationGatewayClassParameters in the indexer.
+func (s *azureApplicationGatewayClassParametersLister) List(selector labels.Selector) (ret []*v1beta1.AzureApplicationGatewayClassParameters, err error) {
+	err = cache.ListAll(s.indexer, selector, func(m interface{}) {
+		ret = append(ret, m.(*v1beta1.AzureApplicationGatewayClassParameters))
+	})
+	return ret, err
+}
+
+// Get retrieves the AzureApplicationGatewayClassParameters from the index for a given name.
+func (s *azureApplicationGatewayClassParametersLister) Get(name string) (*v1beta1.AzureApplicationGatewayClassParameters, error) {
+	obj, exists, err := s.indexer.GetByKey(name)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, errors.NewNotFound(v1beta1.Resource("azureapplicationgatewayclassparameters"), name)
+	}
+	return obj.(*v1beta1.AzureApplicationGatewayClassParameters), nil
+}
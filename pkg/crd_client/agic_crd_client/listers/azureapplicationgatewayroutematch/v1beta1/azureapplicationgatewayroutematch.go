@@ -0,0 +1,99 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Code generated by lister-gen. DO NOT EDIT.
+
+package v1beta1
+
+import (
+	v1beta1 "github.com/Azure/application-gateway-kubernetes-ingress/pkg/apis/azureapplicationgatewayroutematch/v1beta1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/tools/cache"
+)
+
+// AzureApplicationGatewayRouteMatchLister helps list AzureApplicationGatewayRouteMatches.
+// All objects returned here must be treated as read-only.
+type AzureApplicationGatewayRouteMatchLister interface {
+	// List lists all AzureApplicationGatewayRouteMatches in the indexer.
+	// Objects returned here must be treated as read-only.
+	List(selector labels.Selector) (ret []*v1beta1.AzureApplicationGatewayRouteMatch, err error)
+	// AzureApplicationGatewayRouteMatches returns an object that can list and get AzureApplicationGatewayRouteMatches.
+	AzureApplicationGatewayRouteMatches(namespace string) AzureApplicationGatewayRouteMatchNamespaceLister
+	AzureApplicationGatewayRouteMatchListerExpansion
+}
+
+// azureApplicationGatewayRouteMatchLister implements the AzureApplicationGatewayRouteMatchLister interface.
+type azureApplicationGatewayRouteMatchLister struct {
+	indexer cache.Indexer
+}
+
+// NewAzureApplicationGatewayRouteMatchLister returns a new AzureApplicationGatewayRouteMatchLister.
+func NewAzureApplicationGatewayRouteMatchLister(indexer cache.Indexer) AzureApplicationGatewayRouteMatchLister {
+	return &azureApplicationGatewayRouteMatchLister{indexer: indexer}
+}
+
+// List lists all AzureApplicationGatewayRouteMatches in the indexer.
+func (s *azureApplicationGatewayRouteMatchLister) List(selector labels.Selector) (ret []*v1beta1.AzureApplicationGatewayRouteMatch, err error) {
+	err = cache.ListAll(s.indexer, selector, func(m interface{}) {
+		ret = append(ret, m.(*v1beta1.AzureApplicationGatewayRouteMatch))
+	})
+	return ret, err
+}
+
+// AzureApplicationGatewayRouteMatches returns an object that can list and get AzureApplicationGatewayRouteMatches.
+func (s *azureApplicationGatewayRouteMatchLister) AzureApplicationGatewayRouteMatches(namespace string) AzureApplicationGatewayRouteMatchNamespaceLister {
+	return azureApplicationGatewayRouteMatchNamespaceLister{indexer: s.indexer, namespace: namespace}
+}
+
+// AzureApplicationGatewayRouteMatchNamespaceLister helps list and get AzureApplicationGatewayRouteMatches.
+// All objects returned here must be treated as read-only.
+type AzureApplicationGatewayRouteMatchNamespaceLister interface {
+	// List lists all AzureApplicationGatewayRouteMatches in the indexer for a given namespace.
+	// Objects returned here must be treated as read-only.
+	List(selector labels.Selector) (ret []*v1beta1.AzureApplicationGatewayRouteMatch, err error)
+	// Get retrieves the AzureApplicationGatewayRouteMatch from the indexer for a given namespace and name.
+	// Objects returned here must be treated as read-only.
+	Get(name string) (*v1beta1.AzureApplicationGatewayRouteMatch, error)
+	AzureApplicationGatewayRouteMatchNamespaceListerExpansion
+}
+
+// azureApplicationGatewayRouteMatchNamespaceLister implements the AzureApplicationGatewayRouteMatchNamespaceLister
+// interface.
+type azureApplicationGatewayRouteMatchNamespaceLister struct {
+	indexer   cache.Indexer
+	namespace string
+}
+
+// List lists all AzureApplicationGatewayRouteMatches in the indexer for a given namespace.
+func (s azureApplicationGatewayRouteMatchNamespaceLister) List(selector labels.Selector) (ret []*v1beta1.AzureApplicationGatewayRouteMatch, err error) {
+	err = cache.ListAllByNamespace(s.indexer, s.namespace, selector, func(m interface{}) {
+		ret = append(ret, m.(*v1beta1.AzureApplicationGatewayRouteMatch))
+	})
+	return ret, err
+}
+
+// Get retrieves the AzureApplicationGatewayRouteMatch from the indexer for a given namespace and name.
+func (s azureApplicationGatewayRouteMatchNamespaceLister) Get(name string) (*v1beta1.AzureApplicationGatewayRouteMatch, error) {
+	obj, exists, err := s.indexer.GetByKey(s.namespace + "/" + name)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, errors.NewNotFound(v1beta1.Resource("azureapplicationgatewayroutematch"), name)
+	}
+	return obj.(*v1beta1.AzureApplicationGatewayRouteMatch), nil
+}
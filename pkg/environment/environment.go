@@ -6,9 +6,11 @@
 package environment
 
 import (
+	"net/url"
 	"os"
 	"regexp"
 	"strconv"
+	"time"
 
 	"k8s.io/klog/v2"
 
@@ -115,6 +117,98 @@ const (
 
 	// AddonModeVarName is an environment variable to inform if the controller is running as an addon.
 	AddonModeVarName = "ADDON_MODE"
+
+	// EnableLeaderElectionVarName is a feature flag enabling Lease based leader election between AGIC replicas.
+	EnableLeaderElectionVarName = "APPGW_ENABLE_LEADER_ELECTION"
+
+	// LeaderElectionLeaseNameVarName is an environment variable which specifies the name of the Lease used for leader election.
+	LeaderElectionLeaseNameVarName = "LEADER_ELECTION_LEASE_NAME"
+
+	// EnableDryRunVarName is a feature flag making AGIC compute a plan of the App Gateway changes instead of applying them.
+	EnableDryRunVarName = "APPGW_ENABLE_DRY_RUN"
+
+	// EventDebounceDurationVarName is an environment variable which specifies how long the worker waits for events to stop arriving before processing them.
+	EventDebounceDurationVarName = "EVENT_DEBOUNCE_DURATION"
+
+	// EventCoalescingWindowVarName is an environment variable which specifies for how long at most events are coalesced into a single update.
+	EventCoalescingWindowVarName = "EVENT_COALESCING_WINDOW"
+
+	// EventMaxRetriesVarName is an environment variable which specifies how many times a failed update is retried before its events are dropped.
+	EventMaxRetriesVarName = "EVENT_MAX_RETRIES"
+
+	// EventRetryBaseDelayVarName is an environment variable which specifies the backoff after the first failed update.
+	EventRetryBaseDelayVarName = "EVENT_RETRY_BASE_DELAY"
+
+	// EventRetryMaxDelayVarName is an environment variable which caps the exponential backoff between retries of a failed update.
+	EventRetryMaxDelayVarName = "EVENT_RETRY_MAX_DELAY"
+
+	// EnableConfigHistoryVarName is a feature flag making AGIC keep the last applied App Gateway configs, which can be rolled back to.
+	EnableConfigHistoryVarName = "APPGW_ENABLE_CONFIG_HISTORY"
+
+	// ConfigHistoryConfigMapNameVarName is an environment variable which specifies the name of the ConfigMap storing the config history.
+	ConfigHistoryConfigMapNameVarName = "CONFIG_HISTORY_CONFIGMAP_NAME"
+
+	// ConfigHistorySizeVarName is an environment variable which specifies how many applied configs are kept.
+	ConfigHistorySizeVarName = "CONFIG_HISTORY_SIZE"
+
+	// EnableConfigAdminAPIVarName is a feature flag making AGIC serve the config rollback and resume endpoints on a localhost-only port.
+	EnableConfigAdminAPIVarName = "APPGW_ENABLE_CONFIG_ADMIN_API"
+
+	// ConfigAdminPortVarName is an environment variable which specifies the localhost port of the config rollback and resume endpoints.
+	ConfigAdminPortVarName = "CONFIG_ADMIN_PORT"
+
+	// EnableAutoRollbackVarName is a feature flag making AGIC roll App Gateway back when a deployment fails and exclude the Ingress which caused it.
+	EnableAutoRollbackVarName = "APPGW_ENABLE_AUTO_ROLLBACK"
+
+	// AutoRollbackMaxTrialDeploymentsVarName is an environment variable which specifies how many trial deployments AGIC may make to find the Ingress
+	// which caused a failed deployment, when the error does not name it. Trial deployments are disabled by default.
+	AutoRollbackMaxTrialDeploymentsVarName = "APPGW_AUTO_ROLLBACK_MAX_TRIAL_DEPLOYMENTS"
+
+	// WatchdogHeartbeatTimeoutVarName is an environment variable which specifies how long the worker may go without a heartbeat before the liveness probe fails.
+	WatchdogHeartbeatTimeoutVarName = "WATCHDOG_HEARTBEAT_TIMEOUT"
+
+	// WatchdogEventTimeoutVarName is an environment variable which specifies how long the worker may process a batch of events before the liveness probe fails.
+	WatchdogEventTimeoutVarName = "WATCHDOG_EVENT_TIMEOUT"
+
+	// WatchdogARMOperationTimeoutVarName is an environment variable which specifies how long an ARM operation may be in flight before the liveness probe fails.
+	WatchdogARMOperationTimeoutVarName = "WATCHDOG_ARM_OPERATION_TIMEOUT"
+
+	// WatchdogInformerSyncTimeoutVarName is an environment variable which specifies how long the initial sync of the informers may take before the liveness probe fails.
+	WatchdogInformerSyncTimeoutVarName = "WATCHDOG_INFORMER_SYNC_TIMEOUT"
+
+	// ARMGetTimeoutVarName is an environment variable which specifies how long a single GET request to ARM may take.
+	ARMGetTimeoutVarName = "ARM_GET_TIMEOUT"
+
+	// ARMPutTimeoutVarName is an environment variable which specifies how long the request starting a deployment on ARM may take.
+	ARMPutTimeoutVarName = "ARM_PUT_TIMEOUT"
+
+	// ARMPollingTimeoutVarName is an environment variable which specifies how long AGIC waits for a deployment on ARM to complete.
+	ARMPollingTimeoutVarName = "ARM_POLLING_TIMEOUT"
+
+	// ShutdownGracePeriodVarName is an environment variable which specifies how long AGIC waits on shutdown for a deployment in progress to complete.
+	ShutdownGracePeriodVarName = "SHUTDOWN_GRACE_PERIOD"
+
+	// ARMEndpointVarName is an environment variable which points AGIC, without authentication, at a stand-in for ARM, e.g. the fake-arm subcommand.
+	ARMEndpointVarName = "ARM_ENDPOINT"
+
+	// GatewaysVarName is an environment variable which lists the Application Gateways AGIC manages, each bound to its own IngressClass,
+	// as comma separated <ingress class>=<application gateway resource ID> pairs. It replaces APPGW_RESOURCE_ID.
+	GatewaysVarName = "APPGW_GATEWAYS"
+
+	// EnableGatewayAPIVarName is a feature flag enabling the translation of Gateway API GatewayClasses, Gateways and HTTPRoutes.
+	EnableGatewayAPIVarName = "APPGW_ENABLE_GATEWAY_API"
+
+	// GatewayClassControllerVarName is an environment variable which specifies the controllerName of the GatewayClasses AGIC implements.
+	GatewayClassControllerVarName = "GATEWAY_CLASS_CONTROLLER"
+
+	// GatewayClassNameVarName is an environment variable which restricts AGIC to the Gateways of a single GatewayClass.
+	GatewayClassNameVarName = "GATEWAY_CLASS_NAME"
+
+	// EnableLoadDistributionPolicyVarName is a feature flag enabling observation of the LoadDistributionPolicy CRD
+	EnableLoadDistributionPolicyVarName = "APPGW_ENABLE_LOAD_DISTRIBUTION_POLICY"
+
+	// EnableRouteMatchVarName is a feature flag enabling observation of the AzureApplicationGatewayRouteMatch CRD
+	EnableRouteMatchVarName = "APPGW_ENABLE_ROUTE_MATCH"
 )
 
 const (
@@ -123,6 +217,21 @@ const (
 
 	//DefaultIngressClassResourceName defines the default app gateway ingress class object name
 	DefaultIngressClassResourceName = "azure-application-gateway"
+
+	//DefaultLeaderElectionLeaseName defines the default name of the Lease used for leader election
+	DefaultLeaderElectionLeaseName = "ingress-appgw-leader"
+
+	//DefaultConfigHistoryConfigMapName defines the default name of the ConfigMap storing the config history
+	DefaultConfigHistoryConfigMapName = "ingress-appgw-config-history"
+
+	//DefaultConfigHistorySize defines the default number of applied configs kept in the config history
+	DefaultConfigHistorySize = "5"
+
+	//DefaultConfigAdminPort defines the default localhost port of the config rollback and resume endpoints
+	DefaultConfigAdminPort = "8124"
+
+	//DefaultGatewayClassController defines the default controllerName of the GatewayClasses AGIC implements
+	DefaultGatewayClassController = "azure.com/application-gateway"
 )
 
 var (
@@ -166,6 +275,42 @@ type EnvVariables struct {
 	ReconcilePeriodSeconds      string
 	MultiClusterMode            bool
 	AddonMode                   bool
+	EnableLeaderElection        bool
+	LeaderElectionLeaseName     string
+	EnableDryRun                bool
+	EventDebounceDuration       string
+	EventCoalescingWindow       string
+	EventMaxRetries             string
+	EventRetryBaseDelay         string
+	EventRetryMaxDelay          string
+	EnableConfigHistory         bool
+	ConfigHistoryConfigMapName  string
+	ConfigHistorySize           string
+	EnableAutoRollback          bool
+
+	// AutoRollbackMaxTrialDeployments bounds the trial deployments finding the Ingress which caused a failed deployment.
+	AutoRollbackMaxTrialDeployments string
+
+	// EnableConfigAdminAPI serves the config rollback and resume endpoints on 127.0.0.1:ConfigAdminPort.
+	EnableConfigAdminAPI bool
+	ConfigAdminPort      string
+
+	WatchdogHeartbeatTimeout    string
+	WatchdogEventTimeout        string
+	WatchdogARMOperationTimeout string
+	WatchdogInformerSyncTimeout string
+	ARMGetTimeout               string
+	ARMPutTimeout               string
+	ARMPollingTimeout           string
+	ShutdownGracePeriod         string
+	ARMEndpoint                 string
+	Gateways                    string
+	EnableGatewayAPI            bool
+	GatewayClassControllerName  string
+	GatewayClassName            string
+
+	EnableLoadDistributionPolicy bool
+	EnableRouteMatch             bool
 }
 
 // Consolidate sets defaults and missing values using cpConfig
@@ -205,6 +350,18 @@ func (env *EnvVariables) Consolidate(cpConfig *azure.CloudProviderConfig) {
 	if env.IngressClassResourceName == "" {
 		env.IngressClassResourceName = DefaultIngressClassResourceName
 	}
+
+	if env.LeaderElectionLeaseName == "" {
+		env.LeaderElectionLeaseName = DefaultLeaderElectionLeaseName
+	}
+
+	if env.ConfigHistoryConfigMapName == "" {
+		env.ConfigHistoryConfigMapName = DefaultConfigHistoryConfigMapName
+	}
+
+	if env.GatewayClassControllerName == "" {
+		env.GatewayClassControllerName = DefaultGatewayClassController
+	}
 }
 
 // GetEnv returns values for defined environment variables for Ingress Controller.
@@ -246,6 +403,39 @@ func GetEnv() EnvVariables {
 		ReconcilePeriodSeconds:      os.Getenv(ReconcilePeriodSecondsVarName),
 		MultiClusterMode:            multiClusterMode,
 		AddonMode:                   GetEnvironmentVariable(AddonModeVarName, "false", boolValidator) == "true",
+		EnableLeaderElection:        GetEnvironmentVariable(EnableLeaderElectionVarName, "false", boolValidator) == "true",
+		LeaderElectionLeaseName:     os.Getenv(LeaderElectionLeaseNameVarName),
+		EnableDryRun:                GetEnvironmentVariable(EnableDryRunVarName, "false", boolValidator) == "true",
+		EventDebounceDuration:       os.Getenv(EventDebounceDurationVarName),
+		EventCoalescingWindow:       os.Getenv(EventCoalescingWindowVarName),
+		EventMaxRetries:             os.Getenv(EventMaxRetriesVarName),
+		EventRetryBaseDelay:         os.Getenv(EventRetryBaseDelayVarName),
+		EventRetryMaxDelay:          os.Getenv(EventRetryMaxDelayVarName),
+		EnableConfigHistory:         GetEnvironmentVariable(EnableConfigHistoryVarName, "false", boolValidator) == "true",
+		ConfigHistoryConfigMapName:  os.Getenv(ConfigHistoryConfigMapNameVarName),
+		ConfigHistorySize:           GetEnvironmentVariable(ConfigHistorySizeVarName, DefaultConfigHistorySize, nil),
+		EnableAutoRollback:          GetEnvironmentVariable(EnableAutoRollbackVarName, "false", boolValidator) == "true",
+
+		AutoRollbackMaxTrialDeployments: os.Getenv(AutoRollbackMaxTrialDeploymentsVarName),
+		EnableConfigAdminAPI:            GetEnvironmentVariable(EnableConfigAdminAPIVarName, "false", boolValidator) == "true",
+		ConfigAdminPort:                 GetEnvironmentVariable(ConfigAdminPortVarName, DefaultConfigAdminPort, portNumberValidator),
+
+		WatchdogHeartbeatTimeout:    os.Getenv(WatchdogHeartbeatTimeoutVarName),
+		WatchdogEventTimeout:        os.Getenv(WatchdogEventTimeoutVarName),
+		WatchdogARMOperationTimeout: os.Getenv(WatchdogARMOperationTimeoutVarName),
+		WatchdogInformerSyncTimeout: os.Getenv(WatchdogInformerSyncTimeoutVarName),
+		ARMGetTimeout:               os.Getenv(ARMGetTimeoutVarName),
+		ARMPutTimeout:               os.Getenv(ARMPutTimeoutVarName),
+		ARMPollingTimeout:           os.Getenv(ARMPollingTimeoutVarName),
+		ShutdownGracePeriod:         os.Getenv(ShutdownGracePeriodVarName),
+		ARMEndpoint:                 os.Getenv(ARMEndpointVarName),
+		Gateways:                    os.Getenv(GatewaysVarName),
+		EnableGatewayAPI:            GetEnvironmentVariable(EnableGatewayAPIVarName, "false", boolValidator) == "true",
+		GatewayClassControllerName:  os.Getenv(GatewayClassControllerVarName),
+		GatewayClassName:            os.Getenv(GatewayClassNameVarName),
+
+		EnableLoadDistributionPolicy: GetEnvironmentVariable(EnableLoadDistributionPolicyVarName, "false", boolValidator) == "true",
+		EnableRouteMatch:             GetEnvironmentVariable(EnableRouteMatchVarName, "false", boolValidator) == "true",
 	}
 
 	return env
@@ -283,7 +473,7 @@ func ValidateEnv(env EnvVariables) error {
 			)
 
 		}
-	} else {
+	} else if env.Gateways == "" {
 		// if deploy is false, we need one of appgw name or resource id
 		if len(env.AppGwName) == 0 && len(env.AppGwResourceID) == 0 {
 			return controllererrors.NewError(
@@ -299,6 +489,54 @@ func ValidateEnv(env EnvVariables) error {
 		klog.V(1).Infof("%s is not set. Watching all available namespaces.", WatchNamespaceVarName)
 	}
 
+	if env.EnableLeaderElection && env.AGICPodNamespace == "" {
+		return controllererrors.NewError(
+			controllererrors.ErrorMissingLeaderElectionNamespace,
+			"Leader election requires AGIC_POD_NAMESPACE to be set to the namespace in which the leader election Lease is created",
+		)
+	}
+
+	if env.EnableConfigHistory {
+		if env.AGICPodNamespace == "" {
+			return controllererrors.NewError(
+				controllererrors.ErrorInvalidConfigHistory,
+				"Config history requires AGIC_POD_NAMESPACE to be set to the namespace in which the config history ConfigMap is created",
+			)
+		}
+
+		if size, err := strconv.Atoi(env.ConfigHistorySize); err != nil || size < 1 || size > 20 {
+			return controllererrors.NewError(
+				controllererrors.ErrorInvalidConfigHistory,
+				"Please make sure that CONFIG_HISTORY_SIZE (helm var name: .configHistory.size) is an integer. Range: (1 - 20)",
+			)
+		}
+	}
+
+	if env.EnableConfigAdminAPI {
+		if !env.EnableConfigHistory {
+			return controllererrors.NewError(
+				controllererrors.ErrorInvalidConfigHistory,
+				"The config admin API (helm var name: .configHistory.adminAPI.enabled) requires config history to be enabled",
+			)
+		}
+
+		if env.ConfigAdminPort == env.HTTPServicePort {
+			return controllererrors.NewError(
+				controllererrors.ErrorInvalidConfigHistory,
+				"Please make sure that CONFIG_ADMIN_PORT (helm var name: .configHistory.adminAPI.port) differs from HTTP_SERVICE_PORT",
+			)
+		}
+	}
+
+	if env.AutoRollbackMaxTrialDeployments != "" {
+		if maxTrials, err := strconv.Atoi(env.AutoRollbackMaxTrialDeployments); err != nil || maxTrials < 0 || maxTrials > 20 {
+			return controllererrors.NewError(
+				controllererrors.ErrorInvalidAutoRollbackConfig,
+				"Please make sure that APPGW_AUTO_ROLLBACK_MAX_TRIAL_DEPLOYMENTS (helm var name: .appgw.autoRollbackMaxTrialDeployments) is an integer. Range: (0 - 20)",
+			)
+		}
+	}
+
 	if env.ReconcilePeriodSeconds != "" {
 		reconcilePeriodSeconds, err := strconv.Atoi(env.ReconcilePeriodSeconds)
 		if err != nil {
@@ -317,6 +555,120 @@ func ValidateEnv(env EnvVariables) error {
 		}
 	}
 
+	if err := validateWorkqueueEnv(env); err != nil {
+		return err
+	}
+
+	if err := validateWatchdogEnv(env); err != nil {
+		return err
+	}
+
+	if err := validateGatewaysEnv(env); err != nil {
+		return err
+	}
+
+	return validateARMEnv(env)
+}
+
+// validateWorkqueueEnv validates the environment variables tuning how the worker coalesces and retries events.
+func validateWorkqueueEnv(env EnvVariables) error {
+	durations := []struct {
+		varName  string
+		helmName string
+		value    string
+	}{
+		{EventDebounceDurationVarName, ".workqueue.debounceDuration", env.EventDebounceDuration},
+		{EventCoalescingWindowVarName, ".workqueue.coalescingWindow", env.EventCoalescingWindow},
+		{EventRetryBaseDelayVarName, ".workqueue.retryBaseDelay", env.EventRetryBaseDelay},
+		{EventRetryMaxDelayVarName, ".workqueue.retryMaxDelay", env.EventRetryMaxDelay},
+	}
+	for _, duration := range durations {
+		if duration.value == "" {
+			continue
+		}
+		if parsed, err := time.ParseDuration(duration.value); err != nil || parsed < 0 {
+			return controllererrors.NewErrorf(
+				controllererrors.ErrorInvalidWorkqueueConfig,
+				"Please make sure that %s (helm var name: %s) is a non-negative duration, e.g. 500ms or 10s",
+				duration.varName, duration.helmName,
+			)
+		}
+	}
+
+	if env.EventMaxRetries != "" {
+		if maxRetries, err := strconv.Atoi(env.EventMaxRetries); err != nil || maxRetries < 0 {
+			return controllererrors.NewError(
+				controllererrors.ErrorInvalidWorkqueueConfig,
+				"Please make sure that EVENT_MAX_RETRIES (helm var name: .workqueue.maxRetries) is a non-negative integer",
+			)
+		}
+	}
+
+	return nil
+}
+
+// validateWatchdogEnv validates the environment variables bounding how long AGIC may go without making progress.
+func validateWatchdogEnv(env EnvVariables) error {
+	timeouts := []struct {
+		varName  string
+		helmName string
+		value    string
+	}{
+		{WatchdogHeartbeatTimeoutVarName, ".watchdog.heartbeatTimeout", env.WatchdogHeartbeatTimeout},
+		{WatchdogEventTimeoutVarName, ".watchdog.eventTimeout", env.WatchdogEventTimeout},
+		{WatchdogARMOperationTimeoutVarName, ".watchdog.armOperationTimeout", env.WatchdogARMOperationTimeout},
+		{WatchdogInformerSyncTimeoutVarName, ".watchdog.informerSyncTimeout", env.WatchdogInformerSyncTimeout},
+	}
+	for _, timeout := range timeouts {
+		if timeout.value == "" {
+			continue
+		}
+		if parsed, err := time.ParseDuration(timeout.value); err != nil || parsed <= 0 {
+			return controllererrors.NewErrorf(
+				controllererrors.ErrorInvalidWatchdogConfig,
+				"Please make sure that %s (helm var name: %s) is a positive duration, e.g. 2m or 30m",
+				timeout.varName, timeout.helmName,
+			)
+		}
+	}
+
+	return nil
+}
+
+// validateARMEnv validates the environment variables bounding how long AGIC waits on ARM.
+func validateARMEnv(env EnvVariables) error {
+	timeouts := []struct {
+		varName  string
+		helmName string
+		value    string
+	}{
+		{ARMGetTimeoutVarName, ".arm.getTimeout", env.ARMGetTimeout},
+		{ARMPutTimeoutVarName, ".arm.putTimeout", env.ARMPutTimeout},
+		{ARMPollingTimeoutVarName, ".arm.pollingTimeout", env.ARMPollingTimeout},
+		{ShutdownGracePeriodVarName, ".arm.shutdownGracePeriod", env.ShutdownGracePeriod},
+	}
+	for _, timeout := range timeouts {
+		if timeout.value == "" {
+			continue
+		}
+		if parsed, err := time.ParseDuration(timeout.value); err != nil || parsed <= 0 {
+			return controllererrors.NewErrorf(
+				controllererrors.ErrorInvalidARMTimeouts,
+				"Please make sure that %s (helm var name: %s) is a positive duration, e.g. 20s or 60m",
+				timeout.varName, timeout.helmName,
+			)
+		}
+	}
+
+	if env.ARMEndpoint != "" {
+		if endpoint, err := url.Parse(env.ARMEndpoint); err != nil || (endpoint.Scheme != "http" && endpoint.Scheme != "https") || endpoint.Host == "" {
+			return controllererrors.NewError(
+				controllererrors.ErrorInvalidARMEndpoint,
+				"Please make sure that ARM_ENDPOINT (helm var name: .arm.endpoint) is an http or https URL, e.g. http://fake-arm:8080",
+			)
+		}
+	}
+
 	return nil
 }
 
@@ -74,6 +74,8 @@ var _ = Describe("Environment", func() {
 				_ = os.Setenv(EnableSaveConfigToFileVarName, "false")
 				_ = os.Setenv(EnablePanicOnPutErrorVarName, "true")
 				_ = os.Setenv(ReconcilePeriodSecondsVarName, "30")
+				_ = os.Setenv(EnableLoadDistributionPolicyVarName, "true")
+				_ = os.Setenv(EnableRouteMatchVarName, "SomethingIrrelevant1234")
 
 				expected := EnvVariables{
 					SubscriptionID:             "SubscriptionIDVarName",
@@ -90,6 +92,11 @@ var _ = Describe("Environment", func() {
 					EnablePanicOnPutError:      true,
 					HTTPServicePort:            "8123",
 					ReconcilePeriodSeconds:     "30",
+					ConfigHistorySize:          "5",
+					ConfigAdminPort:            "8124",
+
+					EnableLoadDistributionPolicy: true,
+					EnableRouteMatch:             false,
 				}
 
 				Expect(GetEnv()).To(Equal(expected))
@@ -209,5 +216,302 @@ var _ = Describe("Environment", func() {
 			})
 		})
 
+		Context("Test ValidateEnv for APPGW_ENABLE_LEADER_ELECTION", func() {
+			It("should error when leader election is enabled without AGIC_POD_NAMESPACE", func() {
+				env := EnvVariables{
+					AppGwResourceID:      "id",
+					EnableLeaderElection: true,
+				}
+				Expect(controllererrors.IsErrorCode(ValidateEnv(env),
+					controllererrors.ErrorMissingLeaderElectionNamespace)).To(BeTrue())
+
+				env.AGICPodNamespace = "agic"
+				Expect(ValidateEnv(env)).To(BeNil())
+			})
+		})
+
+		Context("Test ValidateEnv for the workqueue", func() {
+			It("should accept durations and a retry count", func() {
+				env := EnvVariables{
+					AppGwResourceID:       "id",
+					EventDebounceDuration: "500ms",
+					EventCoalescingWindow: "10s",
+					EventMaxRetries:       "0",
+					EventRetryBaseDelay:   "5s",
+					EventRetryMaxDelay:    "5m",
+				}
+				Expect(ValidateEnv(env)).To(BeNil())
+			})
+
+			It("should error when a duration or the retry count is invalid", func() {
+				env := EnvVariables{
+					AppGwResourceID:       "id",
+					EventDebounceDuration: "500",
+				}
+				Expect(controllererrors.IsErrorCode(ValidateEnv(env),
+					controllererrors.ErrorInvalidWorkqueueConfig)).To(BeTrue())
+
+				env.EventDebounceDuration = ""
+				env.EventMaxRetries = "-1"
+				Expect(controllererrors.IsErrorCode(ValidateEnv(env),
+					controllererrors.ErrorInvalidWorkqueueConfig)).To(BeTrue())
+			})
+		})
+
+		Context("Test ValidateEnv for the watchdog", func() {
+			It("should error when a timeout is not a positive duration", func() {
+				env := EnvVariables{
+					AppGwResourceID:             "id",
+					WatchdogHeartbeatTimeout:    "2m",
+					WatchdogARMOperationTimeout: "30m",
+				}
+				Expect(ValidateEnv(env)).To(BeNil())
+
+				env.WatchdogInformerSyncTimeout = "0s"
+				Expect(controllererrors.IsErrorCode(ValidateEnv(env),
+					controllererrors.ErrorInvalidWatchdogConfig)).To(BeTrue())
+
+				env.WatchdogInformerSyncTimeout = "10"
+				Expect(controllererrors.IsErrorCode(ValidateEnv(env),
+					controllererrors.ErrorInvalidWatchdogConfig)).To(BeTrue())
+			})
+		})
+
+		Context("Test ValidateEnv for the ARM timeouts", func() {
+			It("should error when a timeout is not a positive duration", func() {
+				env := EnvVariables{
+					AppGwResourceID:     "id",
+					ARMGetTimeout:       "1m",
+					ARMPollingTimeout:   "60m",
+					ShutdownGracePeriod: "20s",
+				}
+				Expect(ValidateEnv(env)).To(BeNil())
+
+				env.ARMPutTimeout = "-1m"
+				Expect(controllererrors.IsErrorCode(ValidateEnv(env),
+					controllererrors.ErrorInvalidARMTimeouts)).To(BeTrue())
+
+				env.ARMPutTimeout = "2m"
+				env.ShutdownGracePeriod = "forever"
+				Expect(controllererrors.IsErrorCode(ValidateEnv(env),
+					controllererrors.ErrorInvalidARMTimeouts)).To(BeTrue())
+			})
+
+			It("should error when the ARM endpoint is not an http or https URL", func() {
+				env := EnvVariables{
+					AppGwResourceID: "id",
+					ARMEndpoint:     "http://fake-arm:8080",
+				}
+				Expect(ValidateEnv(env)).To(BeNil())
+
+				env.ARMEndpoint = "fake-arm:8080"
+				Expect(controllererrors.IsErrorCode(ValidateEnv(env),
+					controllererrors.ErrorInvalidARMEndpoint)).To(BeTrue())
+			})
+		})
+
+		Context("Test ValidateEnv for APPGW_ENABLE_CONFIG_HISTORY", func() {
+			It("should error when config history is enabled without AGIC_POD_NAMESPACE", func() {
+				env := EnvVariables{
+					AppGwResourceID:     "id",
+					EnableConfigHistory: true,
+					ConfigHistorySize:   "5",
+				}
+				Expect(controllererrors.IsErrorCode(ValidateEnv(env),
+					controllererrors.ErrorInvalidConfigHistory)).To(BeTrue())
+
+				env.AGICPodNamespace = "agic"
+				Expect(ValidateEnv(env)).To(BeNil())
+			})
+
+			It("should error when CONFIG_HISTORY_SIZE is out of range", func() {
+				env := EnvVariables{
+					AppGwResourceID:     "id",
+					AGICPodNamespace:    "agic",
+					EnableConfigHistory: true,
+					ConfigHistorySize:   "0",
+				}
+				Expect(controllererrors.IsErrorCode(ValidateEnv(env),
+					controllererrors.ErrorInvalidConfigHistory)).To(BeTrue())
+
+				env.ConfigHistorySize = "21"
+				Expect(controllererrors.IsErrorCode(ValidateEnv(env),
+					controllererrors.ErrorInvalidConfigHistory)).To(BeTrue())
+			})
+
+			It("should error when the config admin API is enabled without config history or on the HTTP service port", func() {
+				env := EnvVariables{
+					AppGwResourceID:      "id",
+					AGICPodNamespace:     "agic",
+					HTTPServicePort:      "8123",
+					EnableConfigAdminAPI: true,
+					ConfigAdminPort:      "8124",
+				}
+				Expect(controllererrors.IsErrorCode(ValidateEnv(env),
+					controllererrors.ErrorInvalidConfigHistory)).To(BeTrue())
+
+				env.EnableConfigHistory = true
+				env.ConfigHistorySize = "5"
+				Expect(ValidateEnv(env)).To(BeNil())
+
+				env.ConfigAdminPort = "8123"
+				Expect(controllererrors.IsErrorCode(ValidateEnv(env),
+					controllererrors.ErrorInvalidConfigHistory)).To(BeTrue())
+			})
+		})
+
+		Context("Test ValidateEnv for APPGW_AUTO_ROLLBACK_MAX_TRIAL_DEPLOYMENTS", func() {
+			It("should error when the number of trial deployments is out of range", func() {
+				env := EnvVariables{
+					AppGwResourceID:                 "id",
+					AutoRollbackMaxTrialDeployments: "3",
+				}
+				Expect(ValidateEnv(env)).To(BeNil())
+
+				env.AutoRollbackMaxTrialDeployments = "-1"
+				Expect(controllererrors.IsErrorCode(ValidateEnv(env),
+					controllererrors.ErrorInvalidAutoRollbackConfig)).To(BeTrue())
+
+				env.AutoRollbackMaxTrialDeployments = "all"
+				Expect(controllererrors.IsErrorCode(ValidateEnv(env),
+					controllererrors.ErrorInvalidAutoRollbackConfig)).To(BeTrue())
+			})
+		})
+
+		Context("Test ValidateEnv for APPGW_GATEWAYS", func() {
+			publicID := "/subscriptions/sub/resourceGroups/rg/providers/Microsoft.Network/applicationGateways/public"
+			internalID := "/subscriptions/sub/resourceGroups/rg/providers/Microsoft.Network/applicationGateways/internal"
+
+			It("should accept gateways bound to distinct IngressClasses in place of APPGW_RESOURCE_ID", func() {
+				env := EnvVariables{
+					Gateways: "public=" + publicID + ", internal=" + internalID,
+				}
+				Expect(ValidateEnv(env)).To(BeNil())
+
+				gateways, err := env.GetGateways()
+				Expect(err).ToNot(HaveOccurred())
+				Expect(gateways).To(Equal([]Gateway{
+					{IngressClass: "public", ResourceID: publicID},
+					{IngressClass: "internal", ResourceID: internalID},
+				}))
+			})
+
+			It("should error when an entry is malformed or bound twice", func() {
+				for _, gateways := range []string{
+					publicID,
+					"public=",
+					"public=not-a-resource-id",
+					"public=" + publicID + ",public=" + internalID,
+					"public=" + publicID + ",internal=" + publicID,
+				} {
+					env := EnvVariables{Gateways: gateways}
+					Expect(controllererrors.IsErrorCode(ValidateEnv(env),
+						controllererrors.ErrorInvalidGateways)).To(BeTrue(), gateways)
+				}
+			})
+
+			It("should error when AGIC is asked to deploy the gateways", func() {
+				env := EnvVariables{
+					Gateways:               "public=" + publicID,
+					EnableDeployAppGateway: true,
+					AppGwName:              "public",
+					AppGwSubnetPrefix:      "10.0.0.0/24",
+				}
+				Expect(controllererrors.IsErrorCode(ValidateEnv(env),
+					controllererrors.ErrorInvalidGateways)).To(BeTrue())
+			})
+
+			It("should narrow the environment down to a gateway", func() {
+				env := EnvVariables{
+					AppGwName:                   "ignored",
+					IngressClassControllerName:  DefaultIngressClassController,
+					IngressClassResourceName:    DefaultIngressClassResourceName,
+					IngressClassResourceDefault: true,
+					LeaderElectionLeaseName:     DefaultLeaderElectionLeaseName,
+					ConfigHistoryConfigMapName:  DefaultConfigHistoryConfigMapName,
+				}
+
+				gatewayEnv := env.ForGateway(Gateway{IngressClass: "internal", ResourceID: internalID})
+				Expect(gatewayEnv.SubscriptionID).To(Equal("sub"))
+				Expect(gatewayEnv.ResourceGroupName).To(Equal("rg"))
+				Expect(gatewayEnv.AppGwName).To(Equal("internal"))
+				Expect(gatewayEnv.IngressClass).To(Equal("internal"))
+				Expect(gatewayEnv.IngressClassControllerName).To(Equal(DefaultIngressClassController))
+				Expect(gatewayEnv.IngressClassResourceEnabled).To(BeTrue())
+				Expect(gatewayEnv.IngressClassResourceName).To(Equal("internal"))
+				Expect(gatewayEnv.IngressClassResourceDefault).To(BeFalse())
+				Expect(gatewayEnv.GatewayClassName).To(Equal("internal"))
+				Expect(gatewayEnv.LeaderElectionLeaseName).To(Equal(DefaultLeaderElectionLeaseName + "-internal"))
+				Expect(gatewayEnv.ConfigHistoryConfigMapName).To(Equal(DefaultConfigHistoryConfigMapName + "-internal"))
+			})
+		})
+
+		Context("Test ResolveGateways with the parameters of the IngressClasses", func() {
+			publicID := "/subscriptions/sub/resourceGroups/rg/providers/Microsoft.Network/applicationGateways/public"
+			internalID := "/subscriptions/sub/resourceGroups/rg/providers/Microsoft.Network/applicationGateways/internal"
+			gatewayOfClass := func(ingressClass string) (string, error) {
+				if ingressClass == "internal" {
+					return internalID, nil
+				}
+				return "", nil
+			}
+
+			It("should fill in the gateways APPGW_GATEWAYS leaves to the IngressClasses", func() {
+				env, err := EnvVariables{Gateways: "public=" + publicID + ",internal"}.ResolveGateways(gatewayOfClass)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(ValidateEnv(env)).To(BeNil())
+
+				gateways, err := env.GetGateways()
+				Expect(err).ToNot(HaveOccurred())
+				Expect(gateways).To(Equal([]Gateway{
+					{IngressClass: "public", ResourceID: publicID},
+					{IngressClass: "internal", ResourceID: internalID},
+				}))
+			})
+
+			It("should keep an entry without a gateway for ValidateEnv to reject", func() {
+				env, err := EnvVariables{Gateways: "public,internal"}.ResolveGateways(gatewayOfClass)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(controllererrors.IsErrorCode(ValidateEnv(env),
+					controllererrors.ErrorInvalidGateways)).To(BeTrue())
+			})
+
+			It("should use the gateway of the IngressClass AGIC serves in place of APPGW_RESOURCE_ID", func() {
+				env, err := EnvVariables{
+					IngressClassResourceEnabled: true,
+					IngressClassResourceName:    "internal",
+				}.ResolveGateways(gatewayOfClass)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(ValidateEnv(env)).To(BeNil())
+				Expect(env.AppGwResourceID).To(Equal(internalID))
+				Expect(env.SubscriptionID).To(Equal("sub"))
+				Expect(env.ResourceGroupName).To(Equal("rg"))
+				Expect(env.AppGwName).To(Equal("internal"))
+			})
+
+			It("should error when the IngressClass and the environment set different gateways", func() {
+				_, err := EnvVariables{
+					Gateways: "internal=" + publicID,
+				}.ResolveGateways(gatewayOfClass)
+				Expect(controllererrors.IsErrorCode(err, controllererrors.ErrorConflictingIngressClassGateway)).To(BeTrue())
+
+				_, err = EnvVariables{
+					IngressClassResourceEnabled: true,
+					IngressClassResourceName:    "internal",
+					SubscriptionID:              "sub",
+					ResourceGroupName:           "rg",
+					AppGwName:                   "public",
+				}.ResolveGateways(gatewayOfClass)
+				Expect(controllererrors.IsErrorCode(err, controllererrors.ErrorConflictingIngressClassGateway)).To(BeTrue())
+
+				_, err = EnvVariables{
+					IngressClassResourceEnabled: true,
+					IngressClassResourceName:    "internal",
+					AppGwResourceID:             internalID,
+				}.ResolveGateways(gatewayOfClass)
+				Expect(err).ToNot(HaveOccurred())
+			})
+		})
+
 	})
 })
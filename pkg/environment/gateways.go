@@ -0,0 +1,177 @@
+// -------------------------------------------------------------------------------------------
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License. See License.txt in the project root for license information.
+// --------------------------------------------------------------------------------------------
+
+package environment
+
+import (
+	"strings"
+
+	"github.com/Azure/application-gateway-kubernetes-ingress/pkg/azure"
+	"github.com/Azure/application-gateway-kubernetes-ingress/pkg/controllererrors"
+)
+
+// Gateway is one of the Application Gateways listed in APPGW_GATEWAYS, along with the IngressClass bound to it.
+type Gateway struct {
+	IngressClass string
+	ResourceID   string
+}
+
+// GetGateways parses APPGW_GATEWAYS; It returns no gateways when AGIC manages the single Application Gateway of APPGW_RESOURCE_ID or APPGW_NAME.
+func (env EnvVariables) GetGateways() ([]Gateway, error) {
+	if env.Gateways == "" {
+		return nil, nil
+	}
+
+	var gateways []Gateway
+	ingressClasses := map[string]interface{}{}
+	resourceIDs := map[string]interface{}{}
+	for _, pair := range strings.Split(env.Gateways, ",") {
+		ingressClass, resourceID, found := strings.Cut(strings.TrimSpace(pair), "=")
+		ingressClass = strings.TrimSpace(ingressClass)
+		resourceID = strings.TrimSpace(resourceID)
+		if !found || ingressClass == "" {
+			return nil, controllererrors.NewErrorf(
+				controllererrors.ErrorInvalidGateways,
+				"Please make sure that each entry of APPGW_GATEWAYS (helm var name: .appgw.gateways) is <ingress class>=<application gateway resource ID>, "+
+					"or an <ingress class> whose AzureApplicationGatewayClassParameters set the applicationGatewayID; Got %q", pair,
+			)
+		}
+		if _, _, name := azure.ParseResourceID(resourceID); name == "" {
+			return nil, controllererrors.NewErrorf(
+				controllererrors.ErrorInvalidGateways,
+				"The Application Gateway bound to IngressClass %s in APPGW_GATEWAYS (helm var name: .appgw.gateways) is not a resource ID: %q", ingressClass, resourceID,
+			)
+		}
+
+		if _, exists := ingressClasses[ingressClass]; exists {
+			return nil, controllererrors.NewErrorf(
+				controllererrors.ErrorInvalidGateways,
+				"IngressClass %s is bound to more than one Application Gateway in APPGW_GATEWAYS (helm var name: .appgw.gateways)", ingressClass,
+			)
+		}
+		if _, exists := resourceIDs[strings.ToLower(resourceID)]; exists {
+			return nil, controllererrors.NewErrorf(
+				controllererrors.ErrorInvalidGateways,
+				"Application Gateway %s is bound to more than one IngressClass in APPGW_GATEWAYS (helm var name: .appgw.gateways)", resourceID,
+			)
+		}
+		ingressClasses[ingressClass] = nil
+		resourceIDs[strings.ToLower(resourceID)] = nil
+
+		gateways = append(gateways, Gateway{IngressClass: ingressClass, ResourceID: resourceID})
+	}
+
+	return gateways, nil
+}
+
+// ForGateway returns the environment variables of the controller managing the gateway.
+// The controller only processes the Ingresses of the IngressClass bound to the gateway, either through their
+// spec.ingressClassName or their kubernetes.io/ingress.class annotation, and keeps its own Lease and config history.
+// With the Gateway API enabled, it only translates the Gateways of the GatewayClass named after the IngressClass.
+func (env EnvVariables) ForGateway(gateway Gateway) EnvVariables {
+	subscriptionID, resourceGroupName, applicationGatewayName := azure.ParseResourceID(gateway.ResourceID)
+	env.AppGwResourceID = gateway.ResourceID
+	env.SubscriptionID = string(subscriptionID)
+	env.ResourceGroupName = string(resourceGroupName)
+	env.AppGwName = string(applicationGatewayName)
+
+	env.IngressClass = gateway.IngressClass
+	env.IngressClassResourceEnabled = true
+	env.IngressClassResourceName = gateway.IngressClass
+	env.IngressClassResourceDefault = false
+	env.GatewayClassName = gateway.IngressClass
+
+	env.LeaderElectionLeaseName = env.LeaderElectionLeaseName + "-" + gateway.IngressClass
+	env.ConfigHistoryConfigMapName = env.ConfigHistoryConfigMapName + "-" + gateway.IngressClass
+	return env
+}
+
+// ResolveGateways fills in the Application Gateways which the parameters of the IngressClasses set; gatewayOfClass returns the resource ID
+// the AzureApplicationGatewayClassParameters of an IngressClass set, if any.
+// An entry of APPGW_GATEWAYS may omit the resource ID of its IngressClass, and APPGW_RESOURCE_ID and APPGW_NAME may be omitted for the
+// IngressClass AGIC serves. A gateway set both ways must be the same.
+func (env EnvVariables) ResolveGateways(gatewayOfClass func(ingressClass string) (string, error)) (EnvVariables, error) {
+	if env.Gateways != "" {
+		var pairs []string
+		for _, pair := range strings.Split(env.Gateways, ",") {
+			ingressClass, resourceID, found := strings.Cut(strings.TrimSpace(pair), "=")
+			ingressClass = strings.TrimSpace(ingressClass)
+			if ingressClass == "" {
+				pairs = append(pairs, pair)
+				continue
+			}
+
+			classResourceID, err := gatewayOfClass(ingressClass)
+			if err != nil {
+				return env, err
+			}
+			if !found {
+				if classResourceID == "" {
+					// GetGateways rejects the entry.
+					pairs = append(pairs, pair)
+					continue
+				}
+				resourceID = classResourceID
+			} else if err := sameGateway(ingressClass, strings.TrimSpace(resourceID), classResourceID); err != nil {
+				return env, err
+			}
+			pairs = append(pairs, ingressClass+"="+strings.TrimSpace(resourceID))
+		}
+		env.Gateways = strings.Join(pairs, ",")
+		return env, nil
+	}
+
+	if !env.IngressClassResourceEnabled || env.EnableDeployAppGateway {
+		return env, nil
+	}
+
+	resourceID, err := gatewayOfClass(env.IngressClassResourceName)
+	if err != nil || resourceID == "" {
+		return env, err
+	}
+
+	if env.AppGwResourceID == "" && env.AppGwName == "" {
+		subscriptionID, resourceGroupName, applicationGatewayName := azure.ParseResourceID(resourceID)
+		env.AppGwResourceID = resourceID
+		env.SubscriptionID = string(subscriptionID)
+		env.ResourceGroupName = string(resourceGroupName)
+		env.AppGwName = string(applicationGatewayName)
+		return env, nil
+	}
+
+	configuredResourceID := env.AppGwResourceID
+	if configuredResourceID == "" {
+		configuredResourceID = azure.ApplicationGatewayID(azure.SubscriptionID(env.SubscriptionID), azure.ResourceGroup(env.ResourceGroupName), azure.ResourceName(env.AppGwName))
+	}
+	return env, sameGateway(env.IngressClassResourceName, configuredResourceID, resourceID)
+}
+
+// sameGateway makes sure that the Application Gateway configured for an IngressClass is the one its parameters set, if any.
+func sameGateway(ingressClass string, configuredResourceID string, classResourceID string) error {
+	if classResourceID == "" || strings.EqualFold(configuredResourceID, classResourceID) {
+		return nil
+	}
+	return controllererrors.NewErrorf(
+		controllererrors.ErrorConflictingIngressClassGateway,
+		"The parameters of IngressClass %s set Application Gateway %s, but AGIC is configured with Application Gateway %s for it", ingressClass, classResourceID, configuredResourceID,
+	)
+}
+
+// validateGatewaysEnv validates the list of Application Gateways AGIC manages.
+func validateGatewaysEnv(env EnvVariables) error {
+	if env.Gateways == "" {
+		return nil
+	}
+
+	if env.EnableDeployAppGateway {
+		return controllererrors.NewError(
+			controllererrors.ErrorInvalidGateways,
+			"AGIC can not deploy the Application Gateways of APPGW_GATEWAYS (helm var name: .appgw.gateways); Please create them beforehand",
+		)
+	}
+
+	_, err := env.GetGateways()
+	return err
+}
@@ -80,4 +80,40 @@ const (
 
 	// UnsupportedAppGatewaySKUTier is a reason for an event to be emitted.
 	UnsupportedAppGatewaySKUTier = "UnsupportedAppGatewaySKUTier"
+
+	// ReasonStartedLeading is a reason for an event to be emitted.
+	ReasonStartedLeading = "StartedLeading"
+
+	// ReasonStoppedLeading is a reason for an event to be emitted.
+	ReasonStoppedLeading = "StoppedLeading"
+
+	// ReasonAppliedAppGwConfig is a reason for an event to be emitted.
+	ReasonAppliedAppGwConfig = "AppliedAppGwConfig"
+
+	// ReasonProhibitedTarget is a reason for an event to be emitted.
+	ReasonProhibitedTarget = "ProhibitedTarget"
+
+	// ReasonAppliedIngress is a reason for an event to be emitted.
+	ReasonAppliedIngress = "AppliedIngress"
+
+	// ReasonRolledBackAppGwConfig is a reason for an event to be emitted.
+	ReasonRolledBackAppGwConfig = "RolledBackAppGwConfig"
+
+	// ReasonResumedReconciliation is a reason for an event to be emitted.
+	ReasonResumedReconciliation = "ResumedReconciliation"
+
+	// ReasonFailedProvisioning is a reason for an event to be emitted.
+	ReasonFailedProvisioning = "FailedProvisioning"
+
+	// ReasonInvalidLoadDistributionPolicy is a reason for an event to be emitted.
+	ReasonInvalidLoadDistributionPolicy = "InvalidLoadDistributionPolicy"
+
+	// ReasonInvalidCanary is a reason for an event to be emitted.
+	ReasonInvalidCanary = "InvalidCanary"
+
+	// ReasonInvalidRouteMatch is a reason for an event to be emitted.
+	ReasonInvalidRouteMatch = "InvalidRouteMatch"
+
+	// ReasonUnsupportedIstioConfig is a reason for an event to be emitted.
+	ReasonUnsupportedIstioConfig = "UnsupportedIstioConfig"
 )
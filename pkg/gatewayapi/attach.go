@@ -0,0 +1,305 @@
+// -------------------------------------------------------------------------------------------
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License. See License.txt in the project root for license information.
+// --------------------------------------------------------------------------------------------
+
+package gatewayapi
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+)
+
+const (
+	// GatewayKind is the kind of the parents HTTPRoutes attach to.
+	GatewayKind = "Gateway"
+
+	// HTTPRouteKind is the only kind of route AGIC attaches to the listeners of Gateways.
+	HTTPRouteKind = "HTTPRoute"
+
+	// ServiceKind is the only kind of backend AGIC forwards HTTPRoutes to.
+	ServiceKind = "Service"
+
+	// SecretKind is the only kind of certificate reference AGIC installs on HTTPS listeners.
+	SecretKind = "Secret"
+)
+
+// Listener is a listener of a Gateway which AGIC translates into App Gateway listeners.
+type Listener struct {
+	Gateway *gatewayv1.Gateway
+	gatewayv1.Listener
+
+	// SecretName is the Secret in the namespace of the Gateway holding the certificate of an HTTPS listener.
+	SecretName string
+}
+
+// Hostname returns the hostname of the listener; It is empty when the listener matches all hostnames.
+func (l Listener) Hostname() string {
+	if l.Listener.Hostname == nil {
+		return ""
+	}
+	return string(*l.Listener.Hostname)
+}
+
+// Attachment is an HTTPRoute attached to a listener of a Gateway through one of its parentRefs.
+type Attachment struct {
+	Route    *gatewayv1.HTTPRoute
+	Listener Listener
+
+	// Hostnames are the hostnames the route serves on the listener; A single empty hostname matches all hostnames.
+	Hostnames []string
+}
+
+// Attach validates the listeners of the gateways and attaches the routes to them.
+// Gateways and routes are processed oldest first, so that the older of two conflicting listeners or routes wins.
+// Every outcome is recorded on the tracker, from which the statuses of the resources are computed.
+func Attach(gateways []*gatewayv1.Gateway, routes []*gatewayv1.HTTPRoute, tracker *Tracker) ([]Listener, []Attachment) {
+	gateways = append([]*gatewayv1.Gateway{}, gateways...)
+	sort.SliceStable(gateways, func(i, j int) bool {
+		return olderThan(gateways[i].CreationTimestamp.Time, gateways[j].CreationTimestamp.Time, key(gateways[i].Namespace, gateways[i].Name), key(gateways[j].Namespace, gateways[j].Name))
+	})
+	routes = SortRoutes(routes)
+
+	var listeners []Listener
+	gatewaysByKey := make(map[string]*gatewayv1.Gateway)
+	listenersByGateway := make(map[string][]Listener)
+	for _, gateway := range gateways {
+		gatewaysByKey[key(gateway.Namespace, gateway.Name)] = gateway
+		for _, listener := range validListeners(gateway, tracker) {
+			if conflicting := findConflict(listeners, listener); conflicting != nil {
+				reason := gatewayv1.ListenerReasonHostnameConflict
+				if conflicting.Protocol != listener.Protocol {
+					reason = gatewayv1.ListenerReasonProtocolConflict
+				}
+				tracker.ConflictListener(gateway, listener.Name, reason,
+					fmt.Sprintf("Listener %s of Gateway %s/%s already uses port %d for hostname %q", conflicting.Name, conflicting.Gateway.Namespace, conflicting.Gateway.Name, listener.Port, listener.Hostname()))
+				continue
+			}
+			listeners = append(listeners, listener)
+			gatewayKey := key(gateway.Namespace, gateway.Name)
+			listenersByGateway[gatewayKey] = append(listenersByGateway[gatewayKey], listener)
+		}
+	}
+
+	var attachments []Attachment
+	for _, route := range routes {
+		for parentIdx, parentRef := range route.Spec.ParentRefs {
+			if !isGatewayRef(parentRef) {
+				continue
+			}
+			namespace := route.Namespace
+			if parentRef.Namespace != nil {
+				namespace = string(*parentRef.Namespace)
+			}
+			gateway, exists := gatewaysByKey[key(namespace, string(parentRef.Name))]
+			if !exists {
+				// The Gateway does not exist or is implemented by another controller.
+				continue
+			}
+
+			attached, reason, message := attachToGateway(route, parentRef, listenersByGateway[key(gateway.Namespace, gateway.Name)])
+			if len(attached) == 0 {
+				tracker.RejectRoute(route, parentIdx, reason, message)
+				continue
+			}
+			tracker.AcceptRoute(route, parentIdx)
+			for _, attachment := range attached {
+				tracker.attachRoute(attachment.Listener, route)
+			}
+			attachments = append(attachments, attached...)
+		}
+	}
+
+	return listeners, attachments
+}
+
+// SortRoutes returns the routes oldest first, then by namespace and name.
+func SortRoutes(routes []*gatewayv1.HTTPRoute) []*gatewayv1.HTTPRoute {
+	routes = append([]*gatewayv1.HTTPRoute{}, routes...)
+	sort.SliceStable(routes, func(i, j int) bool {
+		return olderThan(routes[i].CreationTimestamp.Time, routes[j].CreationTimestamp.Time, key(routes[i].Namespace, routes[i].Name), key(routes[j].Namespace, routes[j].Name))
+	})
+	return routes
+}
+
+// validListeners returns the listeners of the gateway App Gateway can serve; The others are rejected on the tracker.
+func validListeners(gateway *gatewayv1.Gateway, tracker *Tracker) []Listener {
+	var listeners []Listener
+	for _, spec := range gateway.Spec.Listeners {
+		listener := Listener{Gateway: gateway, Listener: spec}
+		switch spec.Protocol {
+		case gatewayv1.HTTPProtocolType:
+		case gatewayv1.HTTPSProtocolType:
+			secretName, reason, message := listenerSecret(gateway, spec)
+			if secretName == "" {
+				tracker.RejectListener(gateway, spec.Name, gatewayv1.ListenerConditionResolvedRefs, reason, message)
+				continue
+			}
+			listener.SecretName = secretName
+		default:
+			tracker.RejectListener(gateway, spec.Name, gatewayv1.ListenerConditionAccepted, gatewayv1.ListenerReasonUnsupportedProtocol,
+				fmt.Sprintf("Application Gateway does not support protocol %s; Use HTTP or HTTPS", spec.Protocol))
+			continue
+		}
+
+		if !allowsHTTPRoutes(spec) {
+			tracker.RejectListener(gateway, spec.Name, gatewayv1.ListenerConditionResolvedRefs, gatewayv1.ListenerReasonInvalidRouteKinds,
+				"AGIC only supports HTTPRoutes")
+			continue
+		}
+		listeners = append(listeners, listener)
+	}
+	return listeners
+}
+
+// listenerSecret returns the Secret holding the certificate of an HTTPS listener, or the reason why it has none AGIC can use.
+func listenerSecret(gateway *gatewayv1.Gateway, spec gatewayv1.Listener) (string, gatewayv1.ListenerConditionReason, string) {
+	if spec.TLS == nil || len(spec.TLS.CertificateRefs) == 0 {
+		return "", gatewayv1.ListenerReasonInvalidCertificateRef, "HTTPS listeners need a certificateRef"
+	}
+	if spec.TLS.Mode != nil && *spec.TLS.Mode != gatewayv1.TLSModeTerminate {
+		return "", gatewayv1.ListenerReasonInvalidCertificateRef, "Application Gateway only terminates TLS"
+	}
+
+	ref := spec.TLS.CertificateRefs[0]
+	if (ref.Group != nil && *ref.Group != "") || (ref.Kind != nil && *ref.Kind != SecretKind) {
+		return "", gatewayv1.ListenerReasonInvalidCertificateRef, "The certificateRef must be a core Secret"
+	}
+	if ref.Namespace != nil && string(*ref.Namespace) != gateway.Namespace {
+		return "", gatewayv1.ListenerReasonRefNotPermitted, "AGIC only supports Secrets in the namespace of the Gateway"
+	}
+	return string(ref.Name), "", ""
+}
+
+// allowsHTTPRoutes tells whether the listener accepts HTTPRoutes.
+func allowsHTTPRoutes(spec gatewayv1.Listener) bool {
+	if spec.AllowedRoutes == nil || len(spec.AllowedRoutes.Kinds) == 0 {
+		return true
+	}
+	for _, kind := range spec.AllowedRoutes.Kinds {
+		if (kind.Group == nil || *kind.Group == gatewayv1.GroupName) && kind.Kind == HTTPRouteKind {
+			return true
+		}
+	}
+	return false
+}
+
+// findConflict returns the listener which App Gateway could not tell apart from the given one, if any.
+// Listeners on the same port must all use the same protocol and different hostnames.
+func findConflict(listeners []Listener, listener Listener) *Listener {
+	for idx := range listeners {
+		if listeners[idx].Port != listener.Port {
+			continue
+		}
+		if listeners[idx].Protocol != listener.Protocol || listeners[idx].Hostname() == listener.Hostname() {
+			return &listeners[idx]
+		}
+	}
+	return nil
+}
+
+// attachToGateway attaches the route to the listeners of the gateway its parentRef selects.
+// When the route attaches to none of them, the reason and message explain why.
+func attachToGateway(route *gatewayv1.HTTPRoute, parentRef gatewayv1.ParentReference, listeners []Listener) ([]Attachment, gatewayv1.RouteConditionReason, string) {
+	var attachments []Attachment
+	reason := gatewayv1.RouteReasonNoMatchingParent
+	message := "The Gateway has no valid listener matching the sectionName and port of the parentRef"
+	for _, listener := range listeners {
+		if parentRef.SectionName != nil && *parentRef.SectionName != listener.Name {
+			continue
+		}
+		if parentRef.Port != nil && *parentRef.Port != listener.Port {
+			continue
+		}
+		if !allowsHTTPRoutes(listener.Listener) || !allowsNamespace(listener, route.Namespace) {
+			if reason == gatewayv1.RouteReasonNoMatchingParent {
+				reason = gatewayv1.RouteReasonNotAllowedByListeners
+				message = "The listeners of the Gateway do not allow HTTPRoutes from this namespace"
+			}
+			continue
+		}
+		hostnames := intersectHostnames(listener.Hostname(), route.Spec.Hostnames)
+		if len(hostnames) == 0 {
+			reason = gatewayv1.RouteReasonNoMatchingListenerHostname
+			message = "None of the hostnames of the HTTPRoute match the hostnames of the listeners"
+			continue
+		}
+		attachments = append(attachments, Attachment{Route: route, Listener: listener, Hostnames: hostnames})
+	}
+	return attachments, reason, message
+}
+
+// allowsNamespace tells whether the listener accepts routes from the namespace; Label selectors are not supported.
+func allowsNamespace(listener Listener, namespace string) bool {
+	from := gatewayv1.NamespacesFromSame
+	if listener.AllowedRoutes != nil && listener.AllowedRoutes.Namespaces != nil && listener.AllowedRoutes.Namespaces.From != nil {
+		from = *listener.AllowedRoutes.Namespaces.From
+	}
+	switch from {
+	case gatewayv1.NamespacesFromAll:
+		return true
+	case gatewayv1.NamespacesFromSame:
+		return namespace == listener.Gateway.Namespace
+	default:
+		return false
+	}
+}
+
+// intersectHostnames returns the hostnames a route serves on a listener; The more specific of two matching hostnames is used.
+func intersectHostnames(listenerHostname string, routeHostnames []gatewayv1.Hostname) []string {
+	if len(routeHostnames) == 0 {
+		return []string{listenerHostname}
+	}
+
+	var hostnames []string
+	seen := make(map[string]interface{})
+	for _, routeHostname := range routeHostnames {
+		hostname := string(routeHostname)
+		switch {
+		case listenerHostname == "" || HostnameMatches(listenerHostname, hostname):
+		case HostnameMatches(hostname, listenerHostname):
+			hostname = listenerHostname
+		default:
+			continue
+		}
+		if _, exists := seen[hostname]; !exists {
+			seen[hostname] = nil
+			hostnames = append(hostnames, hostname)
+		}
+	}
+	return hostnames
+}
+
+// HostnameMatches tells whether the hostname is matched by the pattern, which may be a wildcard hostname, e.g. *.example.com.
+// An empty pattern matches all hostnames.
+func HostnameMatches(pattern string, hostname string) bool {
+	if pattern == "" || pattern == hostname {
+		return true
+	}
+	if !strings.HasPrefix(pattern, "*.") {
+		return false
+	}
+	suffix := strings.TrimPrefix(pattern, "*")
+	return strings.HasSuffix(hostname, suffix) && len(hostname) > len(suffix) && !strings.HasPrefix(hostname, "*.")
+}
+
+func isGatewayRef(parentRef gatewayv1.ParentReference) bool {
+	return (parentRef.Group == nil || *parentRef.Group == gatewayv1.GroupName) &&
+		(parentRef.Kind == nil || *parentRef.Kind == GatewayKind)
+}
+
+// olderThan orders resources by creation time, then by key.
+func olderThan(first time.Time, second time.Time, firstKey string, secondKey string) bool {
+	if !first.Equal(second) {
+		return first.Before(second)
+	}
+	return firstKey < secondKey
+}
+
+func key(namespace string, name string) string {
+	return namespace + "/" + name
+}
@@ -0,0 +1,20 @@
+// -------------------------------------------------------------------------------------------
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License. See License.txt in the project root for license information.
+// --------------------------------------------------------------------------------------------
+
+// +build unittest
+
+package gatewayapi
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+func TestGatewayapi(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Gatewayapi Suite")
+}
@@ -0,0 +1,233 @@
+// -------------------------------------------------------------------------------------------
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License. See License.txt in the project root for license information.
+// --------------------------------------------------------------------------------------------
+
+package gatewayapi
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+)
+
+const controllerName = "azure.com/application-gateway"
+
+func newGateway(name string, listeners ...gatewayv1.Listener) *gatewayv1.Gateway {
+	return &gatewayv1.Gateway{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:       name,
+			Namespace:  "default",
+			Generation: 2,
+		},
+		Spec: gatewayv1.GatewaySpec{
+			GatewayClassName: "azure-application-gateway",
+			Listeners:        listeners,
+		},
+	}
+}
+
+func newListener(name string, protocol gatewayv1.ProtocolType, port gatewayv1.PortNumber, hostname string) gatewayv1.Listener {
+	listener := gatewayv1.Listener{
+		Name:     gatewayv1.SectionName(name),
+		Protocol: protocol,
+		Port:     port,
+	}
+	if hostname != "" {
+		h := gatewayv1.Hostname(hostname)
+		listener.Hostname = &h
+	}
+	return listener
+}
+
+func newRoute(name string, gateway string, hostnames ...gatewayv1.Hostname) *gatewayv1.HTTPRoute {
+	return &gatewayv1.HTTPRoute{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:       name,
+			Namespace:  "default",
+			Generation: 3,
+		},
+		Spec: gatewayv1.HTTPRouteSpec{
+			CommonRouteSpec: gatewayv1.CommonRouteSpec{
+				ParentRefs: []gatewayv1.ParentReference{{Name: gatewayv1.ObjectName(gateway)}},
+			},
+			Hostnames: hostnames,
+		},
+	}
+}
+
+func listenerCondition(status gatewayv1.GatewayStatus, listener string, conditionType gatewayv1.ListenerConditionType) *metav1.Condition {
+	for _, listenerStatus := range status.Listeners {
+		if string(listenerStatus.Name) == listener {
+			return meta.FindStatusCondition(listenerStatus.Conditions, string(conditionType))
+		}
+	}
+	return nil
+}
+
+var _ = Describe("Gateway API", func() {
+	Context("ensure HostnameMatches matches wildcards", func() {
+		It("matches exact and wildcard hostnames", func() {
+			Expect(HostnameMatches("", "foo.com")).To(BeTrue())
+			Expect(HostnameMatches("foo.com", "foo.com")).To(BeTrue())
+			Expect(HostnameMatches("*.foo.com", "a.foo.com")).To(BeTrue())
+			Expect(HostnameMatches("*.foo.com", "a.b.foo.com")).To(BeTrue())
+			Expect(HostnameMatches("*.foo.com", "foo.com")).To(BeFalse())
+			Expect(HostnameMatches("foo.com", "bar.com")).To(BeFalse())
+			Expect(HostnameMatches("a.foo.com", "*.foo.com")).To(BeFalse())
+		})
+	})
+
+	Context("ensure Attach attaches routes to listeners", func() {
+		It("intersects the hostnames of the route and listener", func() {
+			tracker := NewTracker(controllerName)
+			gateway := newGateway("gw", newListener("http", gatewayv1.HTTPProtocolType, 80, "*.foo.com"))
+			route := newRoute("route", "gw", "a.foo.com", "bar.com", "*.foo.com")
+
+			listeners, attachments := Attach([]*gatewayv1.Gateway{gateway}, []*gatewayv1.HTTPRoute{route}, tracker)
+			Expect(listeners).To(HaveLen(1))
+			Expect(attachments).To(HaveLen(1))
+			Expect(attachments[0].Hostnames).To(Equal([]string{"a.foo.com", "*.foo.com"}))
+
+			status := tracker.RouteStatus(route)
+			Expect(status.Parents).To(HaveLen(1))
+			Expect(string(status.Parents[0].ControllerName)).To(Equal(controllerName))
+			accepted := meta.FindStatusCondition(status.Parents[0].Conditions, string(gatewayv1.RouteConditionAccepted))
+			Expect(accepted.Status).To(Equal(metav1.ConditionTrue))
+			Expect(accepted.ObservedGeneration).To(Equal(int64(3)))
+
+			gatewayStatus := tracker.GatewayStatus(gateway, "1.2.3.4")
+			Expect(gatewayStatus.Listeners[0].AttachedRoutes).To(Equal(int32(1)))
+			Expect(gatewayStatus.Addresses[0].Value).To(Equal("1.2.3.4"))
+		})
+
+		It("uses the listener hostname for routes without hostnames", func() {
+			gateway := newGateway("gw", newListener("http", gatewayv1.HTTPProtocolType, 80, "foo.com"))
+			_, attachments := Attach([]*gatewayv1.Gateway{gateway}, []*gatewayv1.HTTPRoute{newRoute("route", "gw")}, NewTracker(controllerName))
+			Expect(attachments).To(HaveLen(1))
+			Expect(attachments[0].Hostnames).To(Equal([]string{"foo.com"}))
+		})
+
+		It("rejects routes whose hostnames match no listener", func() {
+			tracker := NewTracker(controllerName)
+			gateway := newGateway("gw", newListener("http", gatewayv1.HTTPProtocolType, 80, "foo.com"))
+			route := newRoute("route", "gw", "bar.com")
+
+			_, attachments := Attach([]*gatewayv1.Gateway{gateway}, []*gatewayv1.HTTPRoute{route}, tracker)
+			Expect(attachments).To(BeEmpty())
+
+			accepted := meta.FindStatusCondition(tracker.RouteStatus(route).Parents[0].Conditions, string(gatewayv1.RouteConditionAccepted))
+			Expect(accepted.Status).To(Equal(metav1.ConditionFalse))
+			Expect(accepted.Reason).To(Equal(string(gatewayv1.RouteReasonNoMatchingListenerHostname)))
+		})
+
+		It("rejects routes from other namespaces unless the listener allows them", func() {
+			tracker := NewTracker(controllerName)
+			gateway := newGateway("gw", newListener("http", gatewayv1.HTTPProtocolType, 80, ""))
+			route := newRoute("route", "gw")
+			route.Namespace = "other"
+			namespace := gatewayv1.Namespace("default")
+			route.Spec.ParentRefs[0].Namespace = &namespace
+
+			_, attachments := Attach([]*gatewayv1.Gateway{gateway}, []*gatewayv1.HTTPRoute{route}, tracker)
+			Expect(attachments).To(BeEmpty())
+			accepted := meta.FindStatusCondition(tracker.RouteStatus(route).Parents[0].Conditions, string(gatewayv1.RouteConditionAccepted))
+			Expect(accepted.Reason).To(Equal(string(gatewayv1.RouteReasonNotAllowedByListeners)))
+
+			from := gatewayv1.NamespacesFromAll
+			gateway.Spec.Listeners[0].AllowedRoutes = &gatewayv1.AllowedRoutes{Namespaces: &gatewayv1.RouteNamespaces{From: &from}}
+			_, attachments = Attach([]*gatewayv1.Gateway{gateway}, []*gatewayv1.HTTPRoute{route}, NewTracker(controllerName))
+			Expect(attachments).To(HaveLen(1))
+		})
+
+		It("ignores parentRefs of Gateways it does not implement", func() {
+			tracker := NewTracker(controllerName)
+			route := newRoute("route", "someone-elses-gateway")
+			route.Status.Parents = []gatewayv1.RouteParentStatus{{
+				ParentRef:      route.Spec.ParentRefs[0],
+				ControllerName: "example.com/other",
+			}}
+
+			_, attachments := Attach(nil, []*gatewayv1.HTTPRoute{route}, tracker)
+			Expect(attachments).To(BeEmpty())
+			Expect(tracker.RouteStatus(route)).To(Equal(route.Status))
+		})
+	})
+
+	Context("ensure Attach validates listeners", func() {
+		It("rejects the newer of two conflicting listeners", func() {
+			tracker := NewTracker(controllerName)
+			older := newGateway("older", newListener("http", gatewayv1.HTTPProtocolType, 80, "foo.com"))
+			older.CreationTimestamp = metav1.NewTime(time.Unix(100, 0))
+			newer := newGateway("newer",
+				newListener("same-host", gatewayv1.HTTPProtocolType, 80, "foo.com"),
+				newListener("other-host", gatewayv1.HTTPProtocolType, 80, "bar.com"),
+				newListener("tcp", gatewayv1.TCPProtocolType, 8080, ""))
+			newer.CreationTimestamp = metav1.NewTime(time.Unix(200, 0))
+
+			listeners, _ := Attach([]*gatewayv1.Gateway{newer, older}, nil, tracker)
+			Expect(listeners).To(HaveLen(2))
+			Expect(listeners[0].Gateway).To(Equal(older))
+			Expect(string(listeners[1].Name)).To(Equal("other-host"))
+
+			status := tracker.GatewayStatus(newer, "1.2.3.4")
+			conflicted := listenerCondition(status, "same-host", gatewayv1.ListenerConditionConflicted)
+			Expect(conflicted.Status).To(Equal(metav1.ConditionTrue))
+			Expect(conflicted.Reason).To(Equal(string(gatewayv1.ListenerReasonHostnameConflict)))
+			Expect(listenerCondition(status, "same-host", gatewayv1.ListenerConditionProgrammed).Status).To(Equal(metav1.ConditionFalse))
+
+			accepted := listenerCondition(status, "tcp", gatewayv1.ListenerConditionAccepted)
+			Expect(accepted.Status).To(Equal(metav1.ConditionFalse))
+			Expect(accepted.Reason).To(Equal(string(gatewayv1.ListenerReasonUnsupportedProtocol)))
+
+			Expect(listenerCondition(status, "other-host", gatewayv1.ListenerConditionProgrammed).Status).To(Equal(metav1.ConditionTrue))
+			Expect(meta.FindStatusCondition(status.Conditions, string(gatewayv1.GatewayConditionAccepted)).Status).To(Equal(metav1.ConditionTrue))
+		})
+
+		It("requires a certificate on HTTPS listeners", func() {
+			tracker := NewTracker(controllerName)
+			gateway := newGateway("gw", newListener("https", gatewayv1.HTTPSProtocolType, 443, ""))
+
+			listeners, _ := Attach([]*gatewayv1.Gateway{gateway}, nil, tracker)
+			Expect(listeners).To(BeEmpty())
+
+			status := tracker.GatewayStatus(gateway, "")
+			resolvedRefs := listenerCondition(status, "https", gatewayv1.ListenerConditionResolvedRefs)
+			Expect(resolvedRefs.Reason).To(Equal(string(gatewayv1.ListenerReasonInvalidCertificateRef)))
+
+			programmed := meta.FindStatusCondition(status.Conditions, string(gatewayv1.GatewayConditionProgrammed))
+			Expect(programmed.Reason).To(Equal(string(gatewayv1.GatewayReasonAddressNotAssigned)))
+
+			gateway.Spec.Listeners[0].TLS = &gatewayv1.ListenerTLSConfig{
+				CertificateRefs: []gatewayv1.SecretObjectReference{{Name: "tls"}},
+			}
+			listeners, _ = Attach([]*gatewayv1.Gateway{gateway}, nil, NewTracker(controllerName))
+			Expect(listeners).To(HaveLen(1))
+			Expect(listeners[0].SecretName).To(Equal("tls"))
+		})
+	})
+
+	Context("ensure RouteStatus reports dropped rules", func() {
+		It("sets and clears PartiallyInvalid", func() {
+			gateway := newGateway("gw", newListener("http", gatewayv1.HTTPProtocolType, 80, ""))
+			route := newRoute("route", "gw")
+
+			tracker := NewTracker(controllerName)
+			Attach([]*gatewayv1.Gateway{gateway}, []*gatewayv1.HTTPRoute{route}, tracker)
+			tracker.DropRouteRules(route, "Rule 0 was dropped")
+			route.Status = tracker.RouteStatus(route)
+			partiallyInvalid := meta.FindStatusCondition(route.Status.Parents[0].Conditions, string(gatewayv1.RouteConditionPartiallyInvalid))
+			Expect(partiallyInvalid.Status).To(Equal(metav1.ConditionTrue))
+			Expect(partiallyInvalid.Message).To(Equal("Rule 0 was dropped"))
+
+			tracker = NewTracker(controllerName)
+			Attach([]*gatewayv1.Gateway{gateway}, []*gatewayv1.HTTPRoute{route}, tracker)
+			status := tracker.RouteStatus(route)
+			Expect(meta.FindStatusCondition(status.Parents[0].Conditions, string(gatewayv1.RouteConditionPartiallyInvalid))).To(BeNil())
+		})
+	})
+})
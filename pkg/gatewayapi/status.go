@@ -0,0 +1,378 @@
+// -------------------------------------------------------------------------------------------
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License. See License.txt in the project root for license information.
+// --------------------------------------------------------------------------------------------
+
+package gatewayapi
+
+import (
+	"reflect"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+)
+
+// condition is a condition AGIC reports on a Gateway API resource, apart from the resource's generation and transition time.
+type condition struct {
+	Type    string
+	Status  metav1.ConditionStatus
+	Reason  string
+	Message string
+}
+
+// parentState is the outcome of attaching a route through one of its parentRefs.
+type parentState struct {
+	accepted bool
+	reason   string
+	message  string
+}
+
+// Tracker records how AGIC translated the Gateway API resources during a single event loop.
+// All methods are no-ops on a nil Tracker.
+type Tracker struct {
+	controllerName string
+
+	// listeners holds the conditions overriding the defaults of a listener, keyed by Gateway key and listener name.
+	listeners map[string]map[gatewayv1.SectionName][]condition
+
+	// attached holds the keys of the routes attached to a listener, keyed by Gateway key and listener name.
+	attached map[string]map[gatewayv1.SectionName]map[string]interface{}
+
+	// parents holds the outcome of every parentRef of a route AGIC processed, keyed by route key and parentRef index.
+	parents map[string]map[int]parentState
+
+	// routeConditions holds the conditions overriding the defaults of every parent of a route, keyed by route key.
+	routeConditions map[string][]condition
+}
+
+// NewTracker creates a Tracker reporting statuses on behalf of the given controller.
+func NewTracker(controllerName string) *Tracker {
+	return &Tracker{
+		controllerName:  controllerName,
+		listeners:       make(map[string]map[gatewayv1.SectionName][]condition),
+		attached:        make(map[string]map[gatewayv1.SectionName]map[string]interface{}),
+		parents:         make(map[string]map[int]parentState),
+		routeConditions: make(map[string][]condition),
+	}
+}
+
+// RejectListener records that the listener of the Gateway cannot be programmed; The condition type tells which condition turns False.
+func (t *Tracker) RejectListener(gateway *gatewayv1.Gateway, name gatewayv1.SectionName, conditionType gatewayv1.ListenerConditionType, reason gatewayv1.ListenerConditionReason, message string) {
+	t.setListenerCondition(gateway, name, condition{
+		Type:    string(conditionType),
+		Status:  metav1.ConditionFalse,
+		Reason:  string(reason),
+		Message: message,
+	})
+}
+
+// ConflictListener records that the listener of the Gateway conflicts with another listener on the same port.
+func (t *Tracker) ConflictListener(gateway *gatewayv1.Gateway, name gatewayv1.SectionName, reason gatewayv1.ListenerConditionReason, message string) {
+	t.setListenerCondition(gateway, name, condition{
+		Type:    string(gatewayv1.ListenerConditionConflicted),
+		Status:  metav1.ConditionTrue,
+		Reason:  string(reason),
+		Message: message,
+	})
+}
+
+// AcceptRoute records that the route attached to at least one listener through the parentRef with the given index.
+func (t *Tracker) AcceptRoute(route *gatewayv1.HTTPRoute, parentIdx int) {
+	t.setParent(route, parentIdx, parentState{accepted: true})
+}
+
+// RejectRoute records that the route attached to no listener through the parentRef with the given index.
+func (t *Tracker) RejectRoute(route *gatewayv1.HTTPRoute, parentIdx int, reason gatewayv1.RouteConditionReason, message string) {
+	t.setParent(route, parentIdx, parentState{reason: string(reason), message: message})
+}
+
+// RouteRefsNotResolved records that some backendRefs of the route could not be resolved.
+// The first reason recorded for a route is kept.
+func (t *Tracker) RouteRefsNotResolved(route *gatewayv1.HTTPRoute, reason gatewayv1.RouteConditionReason, message string) {
+	t.setRouteCondition(route, condition{
+		Type:    string(gatewayv1.RouteConditionResolvedRefs),
+		Status:  metav1.ConditionFalse,
+		Reason:  string(reason),
+		Message: message,
+	})
+}
+
+// DropRouteRules records that some rules of the route cannot be expressed on App Gateway and were dropped.
+// The first message recorded for a route is kept.
+func (t *Tracker) DropRouteRules(route *gatewayv1.HTTPRoute, message string) {
+	t.setRouteCondition(route, condition{
+		Type:    string(gatewayv1.RouteConditionPartiallyInvalid),
+		Status:  metav1.ConditionTrue,
+		Reason:  string(gatewayv1.RouteReasonUnsupportedValue),
+		Message: message,
+	})
+}
+
+// GatewayClassStatus computes the status of a GatewayClass AGIC implements.
+func (t *Tracker) GatewayClassStatus(gatewayClass *gatewayv1.GatewayClass) gatewayv1.GatewayClassStatus {
+	status := *gatewayClass.Status.DeepCopy()
+	meta.SetStatusCondition(&status.Conditions, metav1.Condition{
+		Type:               string(gatewayv1.GatewayClassConditionStatusAccepted),
+		Status:             metav1.ConditionTrue,
+		Reason:             string(gatewayv1.GatewayClassReasonAccepted),
+		Message:            "Handled by the Application Gateway Ingress Controller",
+		ObservedGeneration: gatewayClass.Generation,
+	})
+	return status
+}
+
+// GatewayStatus computes the status of the Gateway, given the frontend IP address of App Gateway, if any.
+func (t *Tracker) GatewayStatus(gateway *gatewayv1.Gateway, address string) gatewayv1.GatewayStatus {
+	status := *gateway.Status.DeepCopy()
+	gatewayKey := key(gateway.Namespace, gateway.Name)
+
+	status.Addresses = nil
+	if address != "" {
+		status.Addresses = []gatewayv1.GatewayStatusAddress{{
+			Type:  addressTypePtr(gatewayv1.IPAddressType),
+			Value: address,
+		}}
+	}
+
+	var listenerStatuses []gatewayv1.ListenerStatus
+	anyAccepted := false
+	for _, listener := range gateway.Spec.Listeners {
+		listenerStatus := gatewayv1.ListenerStatus{
+			Name:           listener.Name,
+			SupportedKinds: []gatewayv1.RouteGroupKind{},
+			Conditions:     findListenerConditions(gateway.Status.Listeners, listener.Name),
+		}
+		overrides := t.listenerConditions(gatewayKey, listener.Name)
+		if allowsHTTPRoutes(listener) {
+			listenerStatus.SupportedKinds = append(listenerStatus.SupportedKinds, gatewayv1.RouteGroupKind{
+				Group: groupPtr(gatewayv1.GroupName),
+				Kind:  HTTPRouteKind,
+			})
+		}
+		if t != nil {
+			listenerStatus.AttachedRoutes = int32(len(t.attached[gatewayKey][listener.Name]))
+		}
+
+		programmed := len(overrides) == 0
+		defaults := []condition{
+			{Type: string(gatewayv1.ListenerConditionAccepted), Status: metav1.ConditionTrue, Reason: string(gatewayv1.ListenerReasonAccepted)},
+			{Type: string(gatewayv1.ListenerConditionResolvedRefs), Status: metav1.ConditionTrue, Reason: string(gatewayv1.ListenerReasonResolvedRefs)},
+			{Type: string(gatewayv1.ListenerConditionConflicted), Status: metav1.ConditionFalse, Reason: string(gatewayv1.ListenerReasonNoConflicts)},
+			{Type: string(gatewayv1.ListenerConditionProgrammed), Status: metav1.ConditionTrue, Reason: string(gatewayv1.ListenerReasonProgrammed)},
+		}
+		if !programmed {
+			defaults[3] = condition{Type: string(gatewayv1.ListenerConditionProgrammed), Status: metav1.ConditionFalse, Reason: string(gatewayv1.ListenerReasonInvalid),
+				Message: overrides[0].Message}
+		}
+		for _, cond := range merge(defaults, overrides) {
+			if cond.Type == string(gatewayv1.ListenerConditionAccepted) && cond.Status == metav1.ConditionTrue {
+				anyAccepted = true
+			}
+			setCondition(&listenerStatus.Conditions, cond, gateway.Generation)
+		}
+		listenerStatuses = append(listenerStatuses, listenerStatus)
+	}
+	status.Listeners = listenerStatuses
+
+	accepted := condition{Type: string(gatewayv1.GatewayConditionAccepted), Status: metav1.ConditionTrue, Reason: string(gatewayv1.GatewayReasonAccepted)}
+	switch {
+	case len(gateway.Spec.Addresses) > 0:
+		accepted = condition{Type: accepted.Type, Status: metav1.ConditionFalse, Reason: string(gatewayv1.GatewayReasonUnsupportedAddress),
+			Message: "The addresses of the Gateway are those of the frontend IP configurations of Application Gateway"}
+	case !anyAccepted:
+		accepted = condition{Type: accepted.Type, Status: metav1.ConditionFalse, Reason: string(gatewayv1.GatewayReasonListenersNotValid),
+			Message: "None of the listeners of the Gateway can be programmed on Application Gateway"}
+	}
+	setCondition(&status.Conditions, accepted, gateway.Generation)
+
+	programmed := condition{Type: string(gatewayv1.GatewayConditionProgrammed), Status: metav1.ConditionTrue, Reason: string(gatewayv1.GatewayReasonProgrammed)}
+	if address == "" {
+		programmed = condition{Type: programmed.Type, Status: metav1.ConditionFalse, Reason: string(gatewayv1.GatewayReasonAddressNotAssigned),
+			Message: "Application Gateway has no frontend IP configuration of the configured type"}
+	}
+	setCondition(&status.Conditions, programmed, gateway.Generation)
+
+	return status
+}
+
+// RouteStatus computes the status of the route.
+// The statuses other controllers report for their parents are kept; Those of AGIC's parents are rebuilt from the parentRefs recorded.
+func (t *Tracker) RouteStatus(route *gatewayv1.HTTPRoute) gatewayv1.HTTPRouteStatus {
+	status := *route.Status.DeepCopy()
+	var controllerName string
+	var parents map[int]parentState
+	var overrides []condition
+	if t != nil {
+		routeKey := key(route.Namespace, route.Name)
+		controllerName = t.controllerName
+		parents = t.parents[routeKey]
+		overrides = t.routeConditions[routeKey]
+	}
+
+	var parentStatuses []gatewayv1.RouteParentStatus
+	for _, parentStatus := range status.Parents {
+		if string(parentStatus.ControllerName) != controllerName {
+			parentStatuses = append(parentStatuses, parentStatus)
+		}
+	}
+
+	for idx, parentRef := range route.Spec.ParentRefs {
+		state, exists := parents[idx]
+		if !exists {
+			continue
+		}
+		parentStatus := gatewayv1.RouteParentStatus{
+			ParentRef:      parentRef,
+			ControllerName: gatewayv1.GatewayController(controllerName),
+			Conditions:     findParentConditions(route.Status.Parents, parentRef, controllerName),
+		}
+
+		accepted := condition{Type: string(gatewayv1.RouteConditionAccepted), Status: metav1.ConditionTrue, Reason: string(gatewayv1.RouteReasonAccepted)}
+		if !state.accepted {
+			accepted = condition{Type: accepted.Type, Status: metav1.ConditionFalse, Reason: state.reason, Message: state.message}
+		}
+		defaults := []condition{
+			accepted,
+			{Type: string(gatewayv1.RouteConditionResolvedRefs), Status: metav1.ConditionTrue, Reason: string(gatewayv1.RouteReasonResolvedRefs)},
+		}
+		for _, cond := range merge(defaults, overrides) {
+			setCondition(&parentStatus.Conditions, cond, route.Generation)
+		}
+		if meta.FindStatusCondition(parentStatus.Conditions, string(gatewayv1.RouteConditionPartiallyInvalid)) != nil && findCondition(overrides, string(gatewayv1.RouteConditionPartiallyInvalid)) == nil {
+			meta.RemoveStatusCondition(&parentStatus.Conditions, string(gatewayv1.RouteConditionPartiallyInvalid))
+		}
+		parentStatuses = append(parentStatuses, parentStatus)
+	}
+
+	status.Parents = parentStatuses
+	if status.Parents == nil {
+		status.Parents = []gatewayv1.RouteParentStatus{}
+	}
+	return status
+}
+
+func (t *Tracker) setListenerCondition(gateway *gatewayv1.Gateway, name gatewayv1.SectionName, cond condition) {
+	if t == nil {
+		return
+	}
+	gatewayKey := key(gateway.Namespace, gateway.Name)
+	if _, exists := t.listeners[gatewayKey]; !exists {
+		t.listeners[gatewayKey] = make(map[gatewayv1.SectionName][]condition)
+	}
+	if findCondition(t.listeners[gatewayKey][name], cond.Type) != nil {
+		return
+	}
+	t.listeners[gatewayKey][name] = append(t.listeners[gatewayKey][name], cond)
+}
+
+func (t *Tracker) listenerConditions(gatewayKey string, name gatewayv1.SectionName) []condition {
+	if t == nil {
+		return nil
+	}
+	return t.listeners[gatewayKey][name]
+}
+
+func (t *Tracker) attachRoute(listener Listener, route *gatewayv1.HTTPRoute) {
+	if t == nil {
+		return
+	}
+	gatewayKey := key(listener.Gateway.Namespace, listener.Gateway.Name)
+	if _, exists := t.attached[gatewayKey]; !exists {
+		t.attached[gatewayKey] = make(map[gatewayv1.SectionName]map[string]interface{})
+	}
+	if _, exists := t.attached[gatewayKey][listener.Name]; !exists {
+		t.attached[gatewayKey][listener.Name] = make(map[string]interface{})
+	}
+	t.attached[gatewayKey][listener.Name][key(route.Namespace, route.Name)] = nil
+}
+
+func (t *Tracker) setParent(route *gatewayv1.HTTPRoute, parentIdx int, state parentState) {
+	if t == nil {
+		return
+	}
+	routeKey := key(route.Namespace, route.Name)
+	if _, exists := t.parents[routeKey]; !exists {
+		t.parents[routeKey] = make(map[int]parentState)
+	}
+	t.parents[routeKey][parentIdx] = state
+}
+
+func (t *Tracker) setRouteCondition(route *gatewayv1.HTTPRoute, cond condition) {
+	if t == nil {
+		return
+	}
+	routeKey := key(route.Namespace, route.Name)
+	if findCondition(t.routeConditions[routeKey], cond.Type) != nil {
+		return
+	}
+	t.routeConditions[routeKey] = append(t.routeConditions[routeKey], cond)
+}
+
+// merge returns the defaults with the overrides of the same type applied, followed by the other overrides.
+func merge(defaults []condition, overrides []condition) []condition {
+	merged := append([]condition{}, defaults...)
+	for _, override := range overrides {
+		replaced := false
+		for idx := range merged {
+			if merged[idx].Type == override.Type {
+				merged[idx] = override
+				replaced = true
+			}
+		}
+		if !replaced {
+			merged = append(merged, override)
+		}
+	}
+	return merged
+}
+
+func findCondition(conditions []condition, conditionType string) *condition {
+	for idx := range conditions {
+		if conditions[idx].Type == conditionType {
+			return &conditions[idx]
+		}
+	}
+	return nil
+}
+
+// setCondition sets the condition, keeping its transition time when its status did not change.
+func setCondition(conditions *[]metav1.Condition, cond condition, generation int64) {
+	message := cond.Message
+	if message == "" {
+		message = cond.Reason
+	}
+	meta.SetStatusCondition(conditions, metav1.Condition{
+		Type:               cond.Type,
+		Status:             cond.Status,
+		Reason:             cond.Reason,
+		Message:            message,
+		ObservedGeneration: generation,
+	})
+}
+
+func findListenerConditions(listeners []gatewayv1.ListenerStatus, name gatewayv1.SectionName) []metav1.Condition {
+	for _, listener := range listeners {
+		if listener.Name == name {
+			return append([]metav1.Condition{}, listener.Conditions...)
+		}
+	}
+	return []metav1.Condition{}
+}
+
+func findParentConditions(parents []gatewayv1.RouteParentStatus, parentRef gatewayv1.ParentReference, controllerName string) []metav1.Condition {
+	for _, parent := range parents {
+		if string(parent.ControllerName) == controllerName && reflect.DeepEqual(parent.ParentRef, parentRef) {
+			return append([]metav1.Condition{}, parent.Conditions...)
+		}
+	}
+	return []metav1.Condition{}
+}
+
+func addressTypePtr(addressType gatewayv1.AddressType) *gatewayv1.AddressType {
+	return &addressType
+}
+
+func groupPtr(group gatewayv1.Group) *gatewayv1.Group {
+	return &group
+}
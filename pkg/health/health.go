@@ -5,7 +5,12 @@
 
 package health
 
-import "net/http"
+import (
+	"encoding/json"
+	"net/http"
+
+	"k8s.io/klog/v2"
+)
 
 // Probe is a type alias for a function.
 type Probe func() bool
@@ -16,6 +21,11 @@ type Probes interface {
 	Readiness() bool
 }
 
+// DetailsProbe is the interface for the report explaining the liveness probe
+type DetailsProbe interface {
+	HealthDetails() Report
+}
+
 func makeHandler(probe Probe) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
 		w.WriteHeader(map[bool]int{
@@ -34,3 +44,20 @@ func ReadinessHandler(probe Probes) http.Handler {
 func LivenessHandler(probe Probes) http.Handler {
 	return makeHandler(probe.Liveness)
 }
+
+// DetailsHandler returns the http handler serving the checks behind the liveness probe as JSON
+func DetailsHandler(probe DetailsProbe) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		report := probe.HealthDetails()
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(map[bool]int{
+			true:  http.StatusOK,
+			false: http.StatusServiceUnavailable,
+		}[report.Alive])
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "    ")
+		if err := encoder.Encode(report); err != nil {
+			klog.Error("Could not write the health details: ", err)
+		}
+	})
+}
@@ -0,0 +1,333 @@
+// -------------------------------------------------------------------------------------------
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License. See License.txt in the project root for license information.
+// --------------------------------------------------------------------------------------------
+
+package health
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"k8s.io/klog/v2"
+
+	"github.com/Azure/application-gateway-kubernetes-ingress/pkg/azure"
+	"github.com/Azure/application-gateway-kubernetes-ingress/pkg/environment"
+)
+
+const (
+	// CheckWorker fails when the worker stopped sending heartbeats, or an event has been processed for too long.
+	CheckWorker = "worker"
+
+	// CheckARMOperations fails when an ARM operation has been in flight for too long, e.g. a hung deployment.
+	CheckARMOperations = "armOperations"
+
+	// CheckInformers fails when the informers did not complete their initial sync in time.
+	CheckInformers = "informers"
+)
+
+// WatchdogConfig bounds how long AGIC may go without making progress before its liveness probe fails.
+type WatchdogConfig struct {
+	// HeartbeatTimeout is how long the worker may go without a heartbeat.
+	HeartbeatTimeout time.Duration
+
+	// EventTimeout is how long the worker may process a single batch of events.
+	EventTimeout time.Duration
+
+	// ARMOperationTimeout is how long a single ARM operation may be in flight.
+	ARMOperationTimeout time.Duration
+
+	// InformerSyncTimeout is how long the initial sync of the informers may take.
+	InformerSyncTimeout time.Duration
+}
+
+// armOperationMargin is how long an ARM operation may outlive the Timeouts of AzClient, e.g. to retry a throttled request.
+const armOperationMargin = 5 * time.Minute
+
+// DefaultWatchdogConfig returns the WatchdogConfig used for the environment variables which are not set,
+// with the default Timeouts of AzClient.
+func DefaultWatchdogConfig() WatchdogConfig {
+	return defaultWatchdogConfig(azure.DefaultTimeouts())
+}
+
+// defaultWatchdogConfig derives the ARM operation and event timeouts from the Timeouts of AzClient, so that the watchdog
+// does not restart AGIC while AzClient still waits on ARM: An ARM operation may take as long as AzClient waits for it,
+// and a batch of events as long as a GET followed by a deployment.
+func defaultWatchdogConfig(timeouts azure.Timeouts) WatchdogConfig {
+	armOperationTimeout := minARMOperationTimeout(timeouts) + armOperationMargin
+	return WatchdogConfig{
+		HeartbeatTimeout:    2 * time.Minute,
+		EventTimeout:        timeouts.Get + armOperationTimeout + armOperationMargin,
+		ARMOperationTimeout: armOperationTimeout,
+		InformerSyncTimeout: 10 * time.Minute,
+	}
+}
+
+// minARMOperationTimeout is the longest AzClient waits on a single ARM operation: A deployment is a PUT followed by polling.
+func minARMOperationTimeout(timeouts azure.Timeouts) time.Duration {
+	if timeouts.Get > timeouts.Put+timeouts.Polling {
+		return timeouts.Get
+	}
+	return timeouts.Put + timeouts.Polling
+}
+
+// NewWatchdogConfig reads the WatchdogConfig from the environment variables; ValidateEnv has already rejected invalid values.
+// Timeouts shorter than the ARM timeouts of AzClient allow are raised, since the watchdog would restart AGIC during a deployment.
+func NewWatchdogConfig(env environment.EnvVariables) WatchdogConfig {
+	timeouts := azure.NewTimeouts(env.ARMGetTimeout, env.ARMPutTimeout, env.ARMPollingTimeout)
+	config := defaultWatchdogConfig(timeouts)
+	parseDuration(env.WatchdogHeartbeatTimeout, &config.HeartbeatTimeout)
+	parseDuration(env.WatchdogEventTimeout, &config.EventTimeout)
+	parseDuration(env.WatchdogARMOperationTimeout, &config.ARMOperationTimeout)
+	parseDuration(env.WatchdogInformerSyncTimeout, &config.InformerSyncTimeout)
+
+	if minimum := minARMOperationTimeout(timeouts); config.ARMOperationTimeout < minimum {
+		klog.Warningf("%s (%s) is shorter than the ARM timeouts of a deployment; Using %s",
+			environment.WatchdogARMOperationTimeoutVarName, config.ARMOperationTimeout, minimum)
+		config.ARMOperationTimeout = minimum
+	}
+	if minimum := timeouts.Get + config.ARMOperationTimeout; config.EventTimeout < minimum {
+		klog.Warningf("%s (%s) is shorter than a GET followed by an ARM operation; Using %s",
+			environment.WatchdogEventTimeoutVarName, config.EventTimeout, minimum)
+		config.EventTimeout = minimum
+	}
+	return config
+}
+
+// Check is the result of one of the checks of the Watchdog.
+type Check struct {
+	Name    string `json:"name"`
+	Healthy bool   `json:"healthy"`
+	Message string `json:"message,omitempty"`
+}
+
+// Report is the result of all checks of the Watchdog; AGIC is alive when all checks are healthy.
+type Report struct {
+	Alive     bool      `json:"alive"`
+	CheckedAt time.Time `json:"checkedAt"`
+	Checks    []Check   `json:"checks"`
+}
+
+// Watchdog tracks whether AGIC makes progress: heartbeats of the worker, the age of in-flight ARM operations
+// and the initial sync of the informers. All methods are no-ops on a nil Watchdog.
+type Watchdog struct {
+	mutex sync.Mutex
+
+	config  WatchdogConfig
+	now     func() time.Time
+	started time.Time
+
+	lastHeartbeat   time.Time
+	eventStarted    time.Time
+	operations      map[uint64]operation
+	nextOperationID uint64
+	// failures holds the error of the last attempt of each ARM operation which failed, by operation name.
+	failures        map[string]string
+	informersSynced func() bool
+}
+
+type operation struct {
+	name    string
+	started time.Time
+}
+
+// NewWatchdog creates a Watchdog; Its timeouts start counting right away.
+func NewWatchdog(config WatchdogConfig) *Watchdog {
+	return newWatchdog(config, time.Now)
+}
+
+func newWatchdog(config WatchdogConfig, now func() time.Time) *Watchdog {
+	return &Watchdog{
+		config:     config,
+		now:        now,
+		started:    now(),
+		operations: make(map[uint64]operation),
+		failures:   make(map[string]string),
+	}
+}
+
+// SetConfig replaces the timeouts of the Watchdog.
+func (w *Watchdog) SetConfig(config WatchdogConfig) {
+	if w == nil {
+		return
+	}
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	w.config = config
+}
+
+// Heartbeat records that the worker is running.
+func (w *Watchdog) Heartbeat() {
+	if w == nil {
+		return
+	}
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	w.lastHeartbeat = w.now()
+}
+
+// BeginEvent records that the worker started processing events; Call the returned function once it completes.
+// The worker does not send heartbeats while it processes events, CheckWorker bounds their processing by EventTimeout instead.
+func (w *Watchdog) BeginEvent() func() {
+	if w == nil {
+		return func() {}
+	}
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	w.eventStarted = w.now()
+	return func() {
+		w.mutex.Lock()
+		defer w.mutex.Unlock()
+		w.eventStarted = time.Time{}
+		w.lastHeartbeat = w.now()
+	}
+}
+
+// BeginOperation records that an ARM operation is in flight; Call the returned function once it completes.
+func (w *Watchdog) BeginOperation(name string) func() {
+	if w == nil {
+		return func() {}
+	}
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	id := w.nextOperationID
+	w.nextOperationID++
+	w.operations[id] = operation{
+		name:    name,
+		started: w.now(),
+	}
+	return func() {
+		w.mutex.Lock()
+		defer w.mutex.Unlock()
+		delete(w.operations, id)
+	}
+}
+
+// RecordOperationResult records the outcome of an ARM operation; The report shows the last error of each operation until it succeeds.
+// Failed operations do not fail the liveness probe, as AGIC retries them on the next event and a restart would not help.
+func (w *Watchdog) RecordOperationResult(name string, err error) {
+	if w == nil {
+		return
+	}
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	if err == nil {
+		delete(w.failures, name)
+		return
+	}
+	w.failures[name] = err.Error()
+}
+
+// WatchInformers sets the function telling whether the informers completed their initial sync.
+func (w *Watchdog) WatchInformers(synced func() bool) {
+	if w == nil {
+		return
+	}
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	w.informersSynced = synced
+}
+
+// Alive tells whether all checks are healthy.
+func (w *Watchdog) Alive() bool {
+	return w.Report().Alive
+}
+
+// Report runs all checks.
+func (w *Watchdog) Report() Report {
+	if w == nil {
+		return Report{Alive: true, Checks: []Check{}}
+	}
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	now := w.now()
+	report := Report{
+		Alive:     true,
+		CheckedAt: now,
+		Checks: []Check{
+			w.checkWorker(now),
+			w.checkARMOperations(now),
+			w.checkInformers(now),
+		},
+	}
+	for _, check := range report.Checks {
+		report.Alive = report.Alive && check.Healthy
+	}
+	return report
+}
+
+func (w *Watchdog) checkWorker(now time.Time) Check {
+	check := Check{Name: CheckWorker, Healthy: true}
+	if w.lastHeartbeat.IsZero() {
+		// The worker starts once the informers are synced, which CheckInformers bounds.
+		check.Message = "worker has not started yet"
+		return check
+	}
+	if !w.eventStarted.IsZero() {
+		age := now.Sub(w.eventStarted)
+		if age > w.config.EventTimeout {
+			check.Healthy = false
+			check.Message = fmt.Sprintf("worker has been processing events for %s; Timeout is %s", age.Round(time.Second), w.config.EventTimeout)
+		} else {
+			check.Message = fmt.Sprintf("worker has been processing events for %s", age.Round(time.Second))
+		}
+		return check
+	}
+	if age := now.Sub(w.lastHeartbeat); age > w.config.HeartbeatTimeout {
+		check.Healthy = false
+		check.Message = fmt.Sprintf("no heartbeat from the worker for %s; Timeout is %s", age.Round(time.Second), w.config.HeartbeatTimeout)
+	}
+	return check
+}
+
+func (w *Watchdog) checkARMOperations(now time.Time) Check {
+	check := Check{Name: CheckARMOperations, Healthy: true}
+	var stuck []string
+	for _, operation := range w.operations {
+		if age := now.Sub(operation.started); age > w.config.ARMOperationTimeout {
+			stuck = append(stuck, fmt.Sprintf("%s in flight for %s", operation.name, age.Round(time.Second)))
+		}
+	}
+	if len(stuck) > 0 {
+		sort.Strings(stuck)
+		check.Healthy = false
+		check.Message = fmt.Sprintf("ARM operations exceeded the timeout of %s: %v", w.config.ARMOperationTimeout, stuck)
+	} else if len(w.operations) > 0 {
+		check.Message = fmt.Sprintf("%d ARM operations in flight", len(w.operations))
+	}
+	if len(w.failures) > 0 {
+		var failures []string
+		for name, err := range w.failures {
+			failures = append(failures, fmt.Sprintf("%s: %s", name, err))
+		}
+		sort.Strings(failures)
+		if check.Message != "" {
+			check.Message += "; "
+		}
+		check.Message += fmt.Sprintf("ARM operations failed: %v", failures)
+	}
+	return check
+}
+
+func (w *Watchdog) checkInformers(now time.Time) Check {
+	check := Check{Name: CheckInformers, Healthy: true}
+	if w.informersSynced == nil || w.informersSynced() {
+		return check
+	}
+	if age := now.Sub(w.started); age > w.config.InformerSyncTimeout {
+		check.Healthy = false
+		check.Message = fmt.Sprintf("informers did not complete their initial sync within %s", w.config.InformerSyncTimeout)
+	} else {
+		check.Message = "waiting for the initial sync of the informers"
+	}
+	return check
+}
+
+func parseDuration(value string, duration *time.Duration) {
+	if parsed, err := time.ParseDuration(value); err == nil && parsed > 0 {
+		*duration = parsed
+	}
+}
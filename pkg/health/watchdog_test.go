@@ -0,0 +1,173 @@
+// -------------------------------------------------------------------------------------------
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License. See License.txt in the project root for license information.
+// --------------------------------------------------------------------------------------------
+
+package health
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/Azure/application-gateway-kubernetes-ingress/pkg/azure"
+	"github.com/Azure/application-gateway-kubernetes-ingress/pkg/environment"
+)
+
+type fakeDetailsProbe struct {
+	report Report
+}
+
+func (p fakeDetailsProbe) HealthDetails() Report {
+	return p.report
+}
+
+var _ = Describe("Watchdog", func() {
+	var now time.Time
+	var watchdog *Watchdog
+
+	unhealthy := func() []string {
+		var names []string
+		for _, check := range watchdog.Report().Checks {
+			if !check.Healthy {
+				names = append(names, check.Name)
+			}
+		}
+		return names
+	}
+
+	BeforeEach(func() {
+		now = time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+		watchdog = newWatchdog(DefaultWatchdogConfig(), func() time.Time { return now })
+	})
+
+	It("is alive before the worker starts", func() {
+		Expect(watchdog.Alive()).To(BeTrue())
+		Expect(watchdog.Report().Checks).To(HaveLen(3))
+	})
+
+	It("fails when the worker stops sending heartbeats", func() {
+		watchdog.Heartbeat()
+		now = now.Add(time.Minute)
+		Expect(watchdog.Alive()).To(BeTrue())
+
+		now = now.Add(2 * time.Minute)
+		Expect(watchdog.Alive()).To(BeFalse())
+		Expect(unhealthy()).To(Equal([]string{CheckWorker}))
+
+		watchdog.Heartbeat()
+		Expect(watchdog.Alive()).To(BeTrue())
+	})
+
+	It("bounds the processing of events by the event timeout instead of the heartbeats", func() {
+		watchdog.Heartbeat()
+		done := watchdog.BeginEvent()
+		now = now.Add(72 * time.Minute)
+		Expect(watchdog.Alive()).To(BeTrue())
+
+		now = now.Add(2 * time.Minute)
+		Expect(watchdog.Alive()).To(BeFalse())
+		Expect(unhealthy()).To(Equal([]string{CheckWorker}))
+		Expect(watchdog.Report().Checks[0].Message).To(ContainSubstring("processing events for 1h14m0s"))
+
+		// Completing the event counts as a heartbeat.
+		done()
+		Expect(watchdog.Alive()).To(BeTrue())
+	})
+
+	It("fails while an ARM operation is in flight for too long", func() {
+		done := watchdog.BeginOperation("UpdateGateway")
+		now = now.Add(66 * time.Minute)
+		watchdog.Heartbeat()
+		Expect(watchdog.Alive()).To(BeTrue())
+
+		now = now.Add(2 * time.Minute)
+		watchdog.Heartbeat()
+		Expect(watchdog.Alive()).To(BeFalse())
+		Expect(unhealthy()).To(Equal([]string{CheckARMOperations}))
+		Expect(watchdog.Report().Checks[1].Message).To(ContainSubstring("UpdateGateway in flight for 1h8m0s"))
+
+		done()
+		Expect(watchdog.Alive()).To(BeTrue())
+	})
+
+	It("reports failed ARM operations without failing until they succeed", func() {
+		watchdog.RecordOperationResult("ApplyRouteTable", errors.New("route table not found"))
+		watchdog.RecordOperationResult("GetSubnet", nil)
+		Expect(watchdog.Alive()).To(BeTrue())
+		Expect(watchdog.Report().Checks[1].Message).To(Equal("ARM operations failed: [ApplyRouteTable: route table not found]"))
+
+		watchdog.RecordOperationResult("ApplyRouteTable", nil)
+		Expect(watchdog.Report().Checks[1].Message).To(BeEmpty())
+	})
+
+	It("fails when the informers do not sync in time", func() {
+		synced := false
+		watchdog.WatchInformers(func() bool { return synced })
+		Expect(watchdog.Alive()).To(BeTrue())
+
+		now = now.Add(11 * time.Minute)
+		Expect(unhealthy()).To(Equal([]string{CheckInformers}))
+
+		synced = true
+		Expect(watchdog.Alive()).To(BeTrue())
+	})
+
+	It("reads the timeouts from the environment", func() {
+		config := NewWatchdogConfig(environment.EnvVariables{
+			WatchdogHeartbeatTimeout: "30s",
+			WatchdogEventTimeout:     "90m",
+		})
+		Expect(config.HeartbeatTimeout).To(Equal(30 * time.Second))
+		Expect(config.EventTimeout).To(Equal(90 * time.Minute))
+		Expect(config.ARMOperationTimeout).To(Equal(DefaultWatchdogConfig().ARMOperationTimeout))
+	})
+
+	It("outlasts the ARM timeouts of AzClient by default", func() {
+		for _, env := range []environment.EnvVariables{
+			{},
+			{ARMGetTimeout: "5m", ARMPutTimeout: "10m", ARMPollingTimeout: "120m"},
+			{ARMGetTimeout: "90m", ARMPutTimeout: "1m", ARMPollingTimeout: "5m"},
+		} {
+			timeouts := azure.NewTimeouts(env.ARMGetTimeout, env.ARMPutTimeout, env.ARMPollingTimeout)
+			config := NewWatchdogConfig(env)
+			Expect(config.ARMOperationTimeout).To(BeNumerically(">", timeouts.Get))
+			Expect(config.ARMOperationTimeout).To(BeNumerically(">", timeouts.Put+timeouts.Polling))
+			Expect(config.EventTimeout).To(BeNumerically(">", timeouts.Get+config.ARMOperationTimeout))
+		}
+
+		defaults := azure.DefaultTimeouts()
+		Expect(DefaultWatchdogConfig().ARMOperationTimeout).To(BeNumerically(">", defaults.Put+defaults.Polling))
+	})
+
+	It("raises timeouts which are shorter than the ARM timeouts of AzClient", func() {
+		config := NewWatchdogConfig(environment.EnvVariables{
+			ARMPutTimeout:               "2m",
+			ARMPollingTimeout:           "60m",
+			WatchdogARMOperationTimeout: "30m",
+			WatchdogEventTimeout:        "30m",
+		})
+		Expect(config.ARMOperationTimeout).To(Equal(62 * time.Minute))
+		Expect(config.EventTimeout).To(Equal(63 * time.Minute))
+	})
+
+	It("is a no-op when nil", func() {
+		var nilWatchdog *Watchdog
+		nilWatchdog.Heartbeat()
+		nilWatchdog.BeginOperation("GetGateway")()
+		nilWatchdog.BeginEvent()()
+		nilWatchdog.RecordOperationResult("GetGateway", errors.New("failed"))
+		Expect(nilWatchdog.Alive()).To(BeTrue())
+	})
+
+	It("serves the report with the status of the liveness probe", func() {
+		recorder := httptest.NewRecorder()
+		DetailsHandler(fakeDetailsProbe{report: Report{Alive: false}}).ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/health/details", nil))
+		Expect(recorder.Code).To(Equal(http.StatusServiceUnavailable))
+		Expect(recorder.Body.String()).To(ContainSubstring(`"alive": false`))
+	})
+})
@@ -0,0 +1,135 @@
+// -------------------------------------------------------------------------------------------
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License. See License.txt in the project root for license information.
+// --------------------------------------------------------------------------------------------
+
+package httpserver
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"k8s.io/klog/v2"
+
+	"github.com/Azure/application-gateway-kubernetes-ingress/pkg/confighistory"
+	"github.com/Azure/application-gateway-kubernetes-ingress/pkg/controller"
+	"github.com/Azure/application-gateway-kubernetes-ingress/pkg/controllererrors"
+)
+
+// revisionsResponse lists the config history along with the pause state.
+type revisionsResponse struct {
+	Paused    *confighistory.Pause     `json:"paused"`
+	Revisions []confighistory.Revision `json:"revisions"`
+}
+
+// RevisionsHandler serves the config history as JSON; With a "revision" query parameter it serves that revision including its config.
+func RevisionsHandler(controllers []*controller.AppGwIngressController) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.Method != http.MethodGet {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+
+		controller := gateways(controllers).find(w, req)
+		if controller == nil {
+			return
+		}
+
+		if req.URL.Query().Has("revision") {
+			number, err := strconv.Atoi(req.URL.Query().Get("revision"))
+			if err != nil {
+				http.Error(w, "The revision query parameter must be an integer", http.StatusBadRequest)
+				return
+			}
+			revision, err := controller.ConfigRevision(number)
+			if err != nil {
+				writeError(w, err)
+				return
+			}
+			writeJSON(w, revision)
+			return
+		}
+
+		revisions, err := controller.ConfigRevisions()
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		writeJSON(w, revisionsResponse{
+			Paused:    controller.Paused(),
+			Revisions: revisions,
+		})
+	})
+}
+
+// RollbackHandler re-applies the revision given by the "revision" query parameter and pauses reconciliation.
+// The optional "reason" query parameter is recorded with the pause.
+func RollbackHandler(controllers []*controller.AppGwIngressController) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.Method != http.MethodPost {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+
+		controller := gateways(controllers).find(w, req)
+		if controller == nil {
+			return
+		}
+
+		number, err := strconv.Atoi(req.URL.Query().Get("revision"))
+		if err != nil {
+			http.Error(w, "The revision query parameter must be an integer", http.StatusBadRequest)
+			return
+		}
+
+		revision, err := controller.Rollback(number, req.URL.Query().Get("reason"))
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		writeJSON(w, revision)
+	})
+}
+
+// ResumeHandler lifts the pause set by a rollback.
+func ResumeHandler(controllers []*controller.AppGwIngressController) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.Method != http.MethodPost {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+
+		controller := gateways(controllers).find(w, req)
+		if controller == nil {
+			return
+		}
+
+		if err := controller.Resume(); err != nil {
+			writeError(w, err)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	})
+}
+
+func writeError(w http.ResponseWriter, err error) {
+	status := http.StatusInternalServerError
+	switch {
+	case controllererrors.IsErrorCode(err, controllererrors.ErrorConfigHistoryDisabled),
+		controllererrors.IsErrorCode(err, controllererrors.ErrorConfigRevisionNotFound):
+		status = http.StatusNotFound
+	case controllererrors.IsErrorCode(err, controllererrors.ErrorNotLeader):
+		status = http.StatusConflict
+	}
+	http.Error(w, err.Error(), status)
+}
+
+func writeJSON(w http.ResponseWriter, value interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "    ")
+	if err := encoder.Encode(value); err != nil {
+		klog.Error("Could not write the response: ", err)
+	}
+}
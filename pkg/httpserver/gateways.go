@@ -0,0 +1,83 @@
+// -------------------------------------------------------------------------------------------
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License. See License.txt in the project root for license information.
+// --------------------------------------------------------------------------------------------
+
+package httpserver
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/Azure/application-gateway-kubernetes-ingress/pkg/controller"
+	"github.com/Azure/application-gateway-kubernetes-ingress/pkg/health"
+)
+
+// gatewayQueryParameter selects the App Gateway, by the IngressClass bound to it, when AGIC manages several.
+const gatewayQueryParameter = "gateway"
+
+// gateways are the controllers of the Application Gateways AGIC manages; AGIC is ready and alive when all of them are.
+type gateways []*controller.AppGwIngressController
+
+// Readiness fulfills the health.Probes interface.
+func (g gateways) Readiness() bool {
+	for _, controller := range g {
+		if !controller.Readiness() {
+			return false
+		}
+	}
+	return true
+}
+
+// Liveness fulfills the health.Probes interface.
+func (g gateways) Liveness() bool {
+	alive := true
+	for _, controller := range g {
+		// Every controller logs its failed checks.
+		alive = controller.Liveness() && alive
+	}
+	return alive
+}
+
+// HealthDetails fulfills the health.DetailsProbe interface; The checks are prefixed with the IngressClass of their App Gateway when AGIC manages several.
+func (g gateways) HealthDetails() health.Report {
+	if len(g) == 1 {
+		return g[0].HealthDetails()
+	}
+
+	merged := health.Report{Alive: true}
+	for _, controller := range g {
+		report := controller.HealthDetails()
+		merged.Alive = merged.Alive && report.Alive
+		if report.CheckedAt.After(merged.CheckedAt) {
+			merged.CheckedAt = report.CheckedAt
+		}
+		for _, check := range report.Checks {
+			check.Name = fmt.Sprintf("%s/%s", controller.IngressClass(), check.Name)
+			merged.Checks = append(merged.Checks, check)
+		}
+	}
+	return merged
+}
+
+// find returns the controller selected by the gateway query parameter; It writes an error and returns nil when there is none.
+// The parameter may be omitted when AGIC manages a single App Gateway.
+func (g gateways) find(w http.ResponseWriter, req *http.Request) *controller.AppGwIngressController {
+	ingressClass := req.URL.Query().Get(gatewayQueryParameter)
+	if ingressClass == "" && len(g) == 1 {
+		return g[0]
+	}
+
+	for _, controller := range g {
+		if controller.IngressClass() == ingressClass {
+			return controller
+		}
+	}
+
+	if ingressClass == "" {
+		http.Error(w, "AGIC manages several Application Gateways; Select one with the gateway query parameter set to the IngressClass bound to it", http.StatusBadRequest)
+	} else {
+		http.Error(w, fmt.Sprintf("AGIC manages no Application Gateway bound to IngressClass %s", ingressClass), http.StatusNotFound)
+	}
+	return nil
+}
@@ -25,7 +25,7 @@ type HTTPServer interface {
 }
 
 type httpServer struct {
-	server *http.Server
+	servers []*http.Server
 }
 
 // NewHealthMux makes a new *http.ServeMux
@@ -38,33 +38,57 @@ func NewHealthMux(handlers map[string]http.Handler) *http.ServeMux {
 	return router
 }
 
-// NewHTTPServer creates a new api server
-func NewHTTPServer(controller *controller.AppGwIngressController, metricStore metricstore.MetricStore, apiPort string) HTTPServer {
-	return &httpServer{
-		server: &http.Server{
+// NewHTTPServer creates a new api server for the controllers of the Application Gateways AGIC manages.
+// metricStore serves the metrics of all of them; The plan and config endpoints select one of them with the gateway query parameter.
+// The rollback and resume endpoints update Application Gateway, so they are only served when adminPort is set, and only on localhost:
+// Reaching them takes a port-forward to the AGIC pod, which RBAC controls.
+func NewHTTPServer(controllers []*controller.AppGwIngressController, metricStore metricstore.MetricStore, apiPort string, adminPort string) HTTPServer {
+	servers := []*http.Server{
+		{
 			Addr: fmt.Sprintf(":%s", apiPort),
 			Handler: NewHealthMux(map[string]http.Handler{
-				"/health/ready": health.ReadinessHandler(controller),
-				"/health/alive": health.LivenessHandler(controller),
-				"/metrics":      metricStore.Handler(),
+				"/health/ready":     health.ReadinessHandler(gateways(controllers)),
+				"/health/alive":     health.LivenessHandler(gateways(controllers)),
+				"/health/details":   health.DetailsHandler(gateways(controllers)),
+				"/metrics":          metricStore.Handler(),
+				"/plan":             PlanHandler(controllers),
+				"/config/revisions": RevisionsHandler(controllers),
 			}),
 		},
 	}
+	if adminPort != "" {
+		servers = append(servers, &http.Server{
+			Addr: fmt.Sprintf("127.0.0.1:%s", adminPort),
+			Handler: NewHealthMux(map[string]http.Handler{
+				"/config/revisions": RevisionsHandler(controllers),
+				"/config/rollback":  RollbackHandler(controllers),
+				"/config/resume":    ResumeHandler(controllers),
+			}),
+		})
+	}
+	return &httpServer{
+		servers: servers,
+	}
 }
 
 func (s *httpServer) Start() {
-	go func() {
-		klog.Infof("Starting API Server on %s", s.server.Addr)
-		if err := s.server.ListenAndServe(); err != nil {
-			klog.Fatal("Failed to start API server", err)
-		}
-	}()
+	for _, server := range s.servers {
+		server := server
+		go func() {
+			klog.Infof("Starting API Server on %s", server.Addr)
+			if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+				klog.Fatal("Failed to start API server", err)
+			}
+		}()
+	}
 }
 
 func (s *httpServer) Stop() {
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
-	if err := s.server.Shutdown(ctx); err != nil {
-		klog.Error("Unable to shutdown API server gracefully", err)
+	for _, server := range s.servers {
+		if err := server.Shutdown(ctx); err != nil {
+			klog.Error("Unable to shutdown API server gracefully", err)
+		}
 	}
 }
@@ -0,0 +1,36 @@
+// -------------------------------------------------------------------------------------------
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License. See License.txt in the project root for license information.
+// --------------------------------------------------------------------------------------------
+
+package httpserver
+
+import (
+	"net/http"
+
+	"github.com/Azure/application-gateway-kubernetes-ingress/pkg/controller"
+	"github.com/Azure/application-gateway-kubernetes-ingress/pkg/environment"
+)
+
+// PlanHandler serves the plan computed by the most recent dry run as JSON.
+func PlanHandler(controllers []*controller.AppGwIngressController) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.Method != http.MethodGet {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+
+		controller := gateways(controllers).find(w, req)
+		if controller == nil {
+			return
+		}
+
+		plan := controller.LastPlan()
+		if plan == nil {
+			http.Error(w, "No plan has been computed yet; Plans are computed when "+environment.EnableDryRunVarName+" is true", http.StatusNotFound)
+			return
+		}
+
+		writeJSON(w, plan)
+	})
+}
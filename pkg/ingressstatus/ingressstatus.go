@@ -0,0 +1,189 @@
+// -------------------------------------------------------------------------------------------
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License. See License.txt in the project root for license information.
+// --------------------------------------------------------------------------------------------
+
+package ingressstatus
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+
+	networking "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/Azure/application-gateway-kubernetes-ingress/pkg/utils"
+)
+
+// Rule is a single backend of an Ingress: either a path of a host rule or the default backend.
+type Rule struct {
+	Host    string `json:"host,omitempty"`
+	Path    string `json:"path,omitempty"`
+	Backend string `json:"backend"`
+
+	// Reason and Message explain why a rule was pruned, or why an applied rule cannot route traffic.
+	Reason  string `json:"reason,omitempty"`
+	Message string `json:"message,omitempty"`
+}
+
+// Status tells which rules of an Ingress AGIC applied to App Gateway and which it pruned.
+// It is stored as JSON in the annotations.IngressStatusKey annotation of the Ingress.
+type Status struct {
+	// ObservedGeneration is the generation of the Ingress the status was computed for.
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// LastAppliedTime is when a config including rules of this generation of the Ingress was applied to App Gateway.
+	LastAppliedTime *metav1.Time `json:"lastAppliedTime,omitempty"`
+
+	AppliedRules []Rule `json:"appliedRules"`
+	PrunedRules  []Rule `json:"prunedRules"`
+}
+
+// Tracker records the rules AGIC prunes during a single event loop.
+// All methods are no-ops on a nil Tracker.
+type Tracker struct {
+	pruned   map[string][]Rule
+	warnings map[string][]Rule
+}
+
+// NewTracker creates a Tracker without any pruned rules.
+func NewTracker() *Tracker {
+	return &Tracker{
+		pruned:   make(map[string][]Rule),
+		warnings: make(map[string][]Rule),
+	}
+}
+
+// NewRule creates the Rule for a path of an Ingress; host and path are empty for the default backend.
+func NewRule(host string, path string, backend *networking.IngressBackend) Rule {
+	return Rule{
+		Host:    host,
+		Path:    path,
+		Backend: backendName(backend),
+	}
+}
+
+// Rules lists all rules of the Ingress.
+func Rules(ingress *networking.Ingress) []Rule {
+	var rules []Rule
+	if ingress.Spec.DefaultBackend != nil {
+		rules = append(rules, NewRule("", "", ingress.Spec.DefaultBackend))
+	}
+	for _, rule := range ingress.Spec.Rules {
+		if rule.HTTP == nil {
+			continue
+		}
+		for idx := range rule.HTTP.Paths {
+			rules = append(rules, NewRule(rule.Host, rule.HTTP.Paths[idx].Path, &rule.HTTP.Paths[idx].Backend))
+		}
+	}
+	return rules
+}
+
+// PruneIngress records that all rules of the Ingress were pruned.
+func (t *Tracker) PruneIngress(ingress *networking.Ingress, reason string, message string) {
+	t.PruneRules(ingress, Rules(ingress), reason, message)
+}
+
+// PruneRules records that the given rules of the Ingress were pruned.
+// A rule pruned more than once keeps the first reason.
+func (t *Tracker) PruneRules(ingress *networking.Ingress, rules []Rule, reason string, message string) {
+	if t == nil {
+		return
+	}
+	ingressKey := utils.GetResourceKey(ingress.Namespace, ingress.Name)
+	for _, rule := range rules {
+		if find(t.pruned[ingressKey], rule) != nil {
+			continue
+		}
+		rule.Reason = reason
+		rule.Message = message
+		t.pruned[ingressKey] = append(t.pruned[ingressKey], rule)
+	}
+}
+
+// Warn records that the given rule of the Ingress is applied, but cannot route traffic.
+func (t *Tracker) Warn(ingress *networking.Ingress, rule Rule, reason string, message string) {
+	if t == nil {
+		return
+	}
+	ingressKey := utils.GetResourceKey(ingress.Namespace, ingress.Name)
+	if find(t.warnings[ingressKey], rule) != nil {
+		return
+	}
+	rule.Reason = reason
+	rule.Message = message
+	t.warnings[ingressKey] = append(t.warnings[ingressKey], rule)
+}
+
+// Status computes the status of the Ingress from its unpruned spec.
+// LastAppliedTime is left unset; It is up to the caller to decide whether a config was applied.
+func (t *Tracker) Status(ingress *networking.Ingress) Status {
+	status := Status{
+		ObservedGeneration: ingress.Generation,
+		AppliedRules:       []Rule{},
+		PrunedRules:        []Rule{},
+	}
+	if t == nil {
+		status.AppliedRules = append(status.AppliedRules, Rules(ingress)...)
+		return status
+	}
+	ingressKey := utils.GetResourceKey(ingress.Namespace, ingress.Name)
+	for _, rule := range Rules(ingress) {
+		if find(t.pruned[ingressKey], rule) != nil {
+			continue
+		}
+		if warning := find(t.warnings[ingressKey], rule); warning != nil {
+			rule = *warning
+		}
+		status.AppliedRules = append(status.AppliedRules, rule)
+	}
+	status.PrunedRules = append(status.PrunedRules, t.pruned[ingressKey]...)
+	return status
+}
+
+// IsUpToDate tells whether both statuses were computed for the same generation of the Ingress and list the same rules.
+func (s Status) IsUpToDate(other Status) bool {
+	return s.ObservedGeneration == other.ObservedGeneration &&
+		reflect.DeepEqual(s.AppliedRules, other.AppliedRules) &&
+		reflect.DeepEqual(s.PrunedRules, other.PrunedRules)
+}
+
+// Parse reads a Status from the value of the status annotation.
+func Parse(value string) (Status, error) {
+	var status Status
+	err := json.Unmarshal([]byte(value), &status)
+	return status, err
+}
+
+// String formats the Status as the value of the status annotation.
+func (s Status) String() string {
+	statusJSON, _ := json.Marshal(s)
+	return string(statusJSON)
+}
+
+// find looks up the given rule by host, path and backend.
+func find(rules []Rule, rule Rule) *Rule {
+	for idx := range rules {
+		if rules[idx].Host == rule.Host && rules[idx].Path == rule.Path && rules[idx].Backend == rule.Backend {
+			return &rules[idx]
+		}
+	}
+	return nil
+}
+
+func backendName(backend *networking.IngressBackend) string {
+	switch {
+	case backend == nil:
+		return ""
+	case backend.Service != nil && backend.Service.Port.Name != "":
+		return fmt.Sprintf("%s:%s", backend.Service.Name, backend.Service.Port.Name)
+	case backend.Service != nil:
+		return fmt.Sprintf("%s:%d", backend.Service.Name, backend.Service.Port.Number)
+	case backend.Resource != nil:
+		return fmt.Sprintf("%s/%s", backend.Resource.Kind, backend.Resource.Name)
+	default:
+		return ""
+	}
+}
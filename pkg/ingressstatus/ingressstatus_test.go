@@ -0,0 +1,69 @@
+// -------------------------------------------------------------------------------------------
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License. See License.txt in the project root for license information.
+// --------------------------------------------------------------------------------------------
+
+package ingressstatus
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/Azure/application-gateway-kubernetes-ingress/pkg/tests"
+)
+
+var _ = Describe("test ingress status", func() {
+	Context("ensure Tracker works as expected", func() {
+		ingress := tests.NewIngressFixture()
+		rules := Rules(ingress)
+
+		It("should list the rules of the ingress", func() {
+			Expect(rules).To(Equal([]Rule{
+				{Host: tests.Host, Path: tests.URLPath1, Backend: tests.ServiceName + ":80"},
+				{Host: tests.Host, Path: tests.URLPath2, Backend: tests.ServiceName + ":443"},
+			}))
+		})
+
+		It("should split applied and pruned rules", func() {
+			tracker := NewTracker()
+			tracker.PruneRules(ingress, rules[:1], "ProhibitedTarget", "pruned by brownfield")
+			tracker.PruneRules(ingress, rules[:1], "NoPrivateIP", "pruned again")
+			tracker.Warn(ingress, rules[1], "ServiceNotFound", "service is missing")
+
+			status := tracker.Status(ingress)
+			Expect(status.PrunedRules).To(Equal([]Rule{
+				{Host: tests.Host, Path: tests.URLPath1, Backend: tests.ServiceName + ":80", Reason: "ProhibitedTarget", Message: "pruned by brownfield"},
+			}))
+			Expect(status.AppliedRules).To(Equal([]Rule{
+				{Host: tests.Host, Path: tests.URLPath2, Backend: tests.ServiceName + ":443", Reason: "ServiceNotFound", Message: "service is missing"},
+			}))
+		})
+
+		It("should apply all rules when nothing is tracked", func() {
+			var tracker *Tracker
+			tracker.PruneIngress(ingress, "NoPrivateIP", "ignored")
+
+			status := tracker.Status(ingress)
+			Expect(status.AppliedRules).To(Equal(rules))
+			Expect(status.PrunedRules).To(BeEmpty())
+		})
+	})
+
+	Context("ensure Status works as expected", func() {
+		It("should round trip through the annotation value", func() {
+			tracker := NewTracker()
+			ingress := tests.NewIngressFixture()
+			ingress.Generation = 3
+			tracker.PruneIngress(ingress, "NoPrivateIP", "ignored")
+			status := tracker.Status(ingress)
+
+			parsed, err := Parse(status.String())
+			Expect(err).ToNot(HaveOccurred())
+			Expect(parsed).To(Equal(status))
+			Expect(parsed.IsUpToDate(status)).To(BeTrue())
+
+			ingress.Generation = 4
+			Expect(tracker.Status(ingress).IsUpToDate(status)).To(BeFalse())
+		})
+	})
+})
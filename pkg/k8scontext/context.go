@@ -7,6 +7,7 @@ package k8scontext
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"sort"
 	"strings"
@@ -18,16 +19,23 @@ import (
 	extensionsv1beta1 "k8s.io/api/extensions/v1beta1"
 	networking "k8s.io/api/networking/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/informers"
 	"k8s.io/client-go/kubernetes"
+	corev1 "k8s.io/client-go/kubernetes/typed/core/v1"
 	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/leaderelection/resourcelock"
 	"k8s.io/klog/v2"
+	gatewayapi_versioned "sigs.k8s.io/gateway-api/pkg/client/clientset/versioned"
+	gatewayapi_externalversions "sigs.k8s.io/gateway-api/pkg/client/informers/externalversions"
 
 	"github.com/Azure/application-gateway-kubernetes-ingress/pkg/annotations"
 	agpoolv1beta1 "github.com/Azure/application-gateway-kubernetes-ingress/pkg/apis/azureapplicationgatewaybackendpool/v1beta1"
 	aginstv1beta1 "github.com/Azure/application-gateway-kubernetes-ingress/pkg/apis/azureapplicationgatewayinstanceupdatestatus/v1beta1"
 	agrewritev1beta1 "github.com/Azure/application-gateway-kubernetes-ingress/pkg/apis/azureapplicationgatewayrewrite/v1beta1"
+	agroutematchv1beta1 "github.com/Azure/application-gateway-kubernetes-ingress/pkg/apis/azureapplicationgatewayroutematch/v1beta1"
 	prohibitedv1 "github.com/Azure/application-gateway-kubernetes-ingress/pkg/apis/azureingressprohibitedtarget/v1"
+	ldpv1beta1 "github.com/Azure/application-gateway-kubernetes-ingress/pkg/apis/loaddistributionpolicy/v1beta1"
 	multiClusterIngress "github.com/Azure/application-gateway-kubernetes-ingress/pkg/apis/multiclusteringress/v1alpha1"
 	multiClusterService "github.com/Azure/application-gateway-kubernetes-ingress/pkg/apis/multiclusterservice/v1alpha1"
 	"github.com/Azure/application-gateway-kubernetes-ingress/pkg/azure"
@@ -55,26 +63,38 @@ var namespacesToIgnore = map[string]interface{}{
 }
 
 // NewContext creates a context based on a Kubernetes client instance.
-func NewContext(kubeClient kubernetes.Interface, crdClient versioned.Interface, multiClusterCrdClient multicluster_versioned.Interface, istioCrdClient istio_versioned.Interface, namespaces []string, resyncPeriod time.Duration, metricStore metricstore.MetricStore, envVariables environment.EnvVariables) *Context {
+func NewContext(kubeClient kubernetes.Interface, crdClient versioned.Interface, multiClusterCrdClient multicluster_versioned.Interface, istioCrdClient istio_versioned.Interface, gatewayAPIClient gatewayapi_versioned.Interface, namespaces []string, resyncPeriod time.Duration, metricStore metricstore.MetricStore, envVariables environment.EnvVariables) *Context {
 	informerFactory := informers.NewSharedInformerFactory(kubeClient, resyncPeriod)
 	crdInformerFactory := externalversions.NewSharedInformerFactory(crdClient, resyncPeriod)
 	multiClusterCrdInformerFactory := multicluster_externalversions.NewSharedInformerFactory(multiClusterCrdClient, resyncPeriod)
 	istioCrdInformerFactory := istio_externalversions.NewSharedInformerFactoryWithOptions(istioCrdClient, resyncPeriod)
+	gatewayAPIInformerFactory := gatewayapi_externalversions.NewSharedInformerFactory(gatewayAPIClient, resyncPeriod)
 
 	informerCollection := InformerCollection{
-		Endpoints: informerFactory.Core().V1().Endpoints().Informer(),
-		Pods:      informerFactory.Core().V1().Pods().Informer(),
-		Secret:    informerFactory.Core().V1().Secrets().Informer(),
-		Service:   informerFactory.Core().V1().Services().Informer(),
+		EndpointSlices: informerFactory.Discovery().V1().EndpointSlices().Informer(),
+		Pods:           informerFactory.Core().V1().Pods().Informer(),
+		Secret:         informerFactory.Core().V1().Secrets().Informer(),
+		Service:        informerFactory.Core().V1().Services().Informer(),
 
 		AzureIngressProhibitedTarget:                crdInformerFactory.Azureingressprohibitedtargets().V1().AzureIngressProhibitedTargets().Informer(),
 		AzureApplicationGatewayBackendPool:          crdInformerFactory.Azureapplicationgatewaybackendpools().V1beta1().AzureApplicationGatewayBackendPools().Informer(),
 		AzureApplicationGatewayRewrite:              crdInformerFactory.Azureapplicationgatewayrewrites().V1beta1().AzureApplicationGatewayRewrites().Informer(),
 		AzureApplicationGatewayInstanceUpdateStatus: crdInformerFactory.Azureapplicationgatewayinstanceupdatestatus().V1beta1().AzureApplicationGatewayInstanceUpdateStatuses().Informer(),
+		AzureApplicationGatewayClassParameters:      crdInformerFactory.Azureapplicationgatewayclassparameters().V1beta1().AzureApplicationGatewayClassParameters().Informer(),
+		LoadDistributionPolicy:                      crdInformerFactory.Loaddistributionpolicies().V1beta1().LoadDistributionPolicies().Informer(),
+		AzureApplicationGatewayRouteMatch:           crdInformerFactory.Azureapplicationgatewayroutematches().V1beta1().AzureApplicationGatewayRouteMatches().Informer(),
 		MultiClusterService:                         multiClusterCrdInformerFactory.Multiclusterservices().V1alpha1().MultiClusterServices().Informer(),
 		MultiClusterIngress:                         multiClusterCrdInformerFactory.Multiclusteringresses().V1alpha1().MultiClusterIngresses().Informer(),
 		IstioGateway:                                istioCrdInformerFactory.Networking().V1alpha3().Gateways().Informer(),
 		IstioVirtualService:                         istioCrdInformerFactory.Networking().V1alpha3().VirtualServices().Informer(),
+		IstioDestinationRule:                        istioCrdInformerFactory.Networking().V1alpha3().DestinationRules().Informer(),
+		GatewayClass:                                gatewayAPIInformerFactory.Gateway().V1().GatewayClasses().Informer(),
+		Gateway:                                     gatewayAPIInformerFactory.Gateway().V1().Gateways().Informer(),
+		HTTPRoute:                                   gatewayAPIInformerFactory.Gateway().V1().HTTPRoutes().Informer(),
+	}
+
+	if err := informerCollection.EndpointSlices.AddIndexers(EndpointSliceIndexers); err != nil {
+		klog.Error("Error adding indexers to the EndpointSlices informer: ", err)
 	}
 
 	if IsNetworkingV1PackageSupported {
@@ -84,7 +104,7 @@ func NewContext(kubeClient kubernetes.Interface, crdClient versioned.Interface,
 	}
 
 	cacheCollection := CacheCollection{
-		Endpoints:                          informerCollection.Endpoints.GetStore(),
+		EndpointSlices:                     informerCollection.EndpointSlices.GetIndexer(),
 		Ingress:                            informerCollection.Ingress.GetStore(),
 		Pods:                               informerCollection.Pods.GetStore(),
 		Secret:                             informerCollection.Secret.GetStore(),
@@ -93,10 +113,17 @@ func NewContext(kubeClient kubernetes.Interface, crdClient versioned.Interface,
 		AzureApplicationGatewayBackendPool: informerCollection.AzureApplicationGatewayBackendPool.GetStore(),
 		AzureApplicationGatewayRewrite:     informerCollection.AzureApplicationGatewayRewrite.GetStore(),
 		AzureApplicationGatewayInstanceUpdateStatus: informerCollection.AzureApplicationGatewayInstanceUpdateStatus.GetStore(),
+		AzureApplicationGatewayClassParameters:      informerCollection.AzureApplicationGatewayClassParameters.GetStore(),
+		LoadDistributionPolicy:                      informerCollection.LoadDistributionPolicy.GetStore(),
+		AzureApplicationGatewayRouteMatch:           informerCollection.AzureApplicationGatewayRouteMatch.GetStore(),
 		MultiClusterService:                         informerCollection.MultiClusterService.GetStore(),
 		MultiClusterIngress:                         informerCollection.MultiClusterIngress.GetStore(),
 		IstioGateway:                                informerCollection.IstioGateway.GetStore(),
 		IstioVirtualService:                         informerCollection.IstioVirtualService.GetStore(),
+		IstioDestinationRule:                        informerCollection.IstioDestinationRule.GetStore(),
+		GatewayClass:                                informerCollection.GatewayClass.GetStore(),
+		Gateway:                                     informerCollection.Gateway.GetStore(),
+		HTTPRoute:                                   informerCollection.HTTPRoute.GetStore(),
 	}
 
 	context := &Context{
@@ -104,6 +131,7 @@ func NewContext(kubeClient kubernetes.Interface, crdClient versioned.Interface,
 		crdClient:             crdClient,
 		multiClusterCrdClient: multiClusterCrdClient,
 		istioCrdClient:        istioCrdClient,
+		gatewayAPIClient:      gatewayAPIClient,
 
 		informers:              &informerCollection,
 		ingressSecretsMap:      utils.NewThreadsafeMultimap(),
@@ -119,6 +147,15 @@ func NewContext(kubeClient kubernetes.Interface, crdClient versioned.Interface,
 		ingressClassResourceName:    envVariables.IngressClassResourceName,
 		ingressClassResourceEnabled: envVariables.IngressClassResourceEnabled,
 		ingressClassResourceDefault: envVariables.IngressClassResourceDefault,
+
+		gatewayClassControllerName: envVariables.GatewayClassControllerName,
+		gatewayClassName:           envVariables.GatewayClassName,
+		gatewayAPIEnabled:          envVariables.EnableGatewayAPI,
+	}
+
+	// When AGIC manages several Application Gateways, the annotation names the IngressClass bound to the gateway.
+	if envVariables.Gateways != "" {
+		context.ingressClassAnnotation = envVariables.IngressClass
 	}
 
 	for _, ns := range namespaces {
@@ -145,8 +182,20 @@ func NewContext(kubeClient kubernetes.Interface, crdClient versioned.Interface,
 		DeleteFunc: h.secretDelete,
 	}
 
+	gatewayAPIResourceHandler := cache.ResourceEventHandlerFuncs{
+		AddFunc:    h.gatewayAPIAdd,
+		UpdateFunc: h.gatewayAPIUpdate,
+		DeleteFunc: h.gatewayAPIDelete,
+	}
+
+	istioGatewayResourceHandler := cache.ResourceEventHandlerFuncs{
+		AddFunc:    h.istioGatewayAdd,
+		UpdateFunc: h.istioGatewayUpdate,
+		DeleteFunc: h.istioGatewayDelete,
+	}
+
 	// Register event handlers.
-	informerCollection.Endpoints.AddEventHandler(resourceHandler)
+	informerCollection.EndpointSlices.AddEventHandler(resourceHandler)
 	informerCollection.Ingress.AddEventHandler(ingressResourceHandler)
 	informerCollection.Pods.AddEventHandler(resourceHandler)
 	informerCollection.Secret.AddEventHandler(secretResourceHandler)
@@ -155,8 +204,17 @@ func NewContext(kubeClient kubernetes.Interface, crdClient versioned.Interface,
 	informerCollection.AzureApplicationGatewayRewrite.AddEventHandler(resourceHandler)
 	informerCollection.AzureApplicationGatewayBackendPool.AddEventHandler(resourceHandler)
 	informerCollection.AzureApplicationGatewayInstanceUpdateStatus.AddEventHandler(resourceHandler)
+	informerCollection.AzureApplicationGatewayClassParameters.AddEventHandler(resourceHandler)
+	informerCollection.LoadDistributionPolicy.AddEventHandler(resourceHandler)
+	informerCollection.AzureApplicationGatewayRouteMatch.AddEventHandler(resourceHandler)
 	informerCollection.MultiClusterService.AddEventHandler(resourceHandler)
 	informerCollection.MultiClusterIngress.AddEventHandler(resourceHandler)
+	informerCollection.IstioGateway.AddEventHandler(istioGatewayResourceHandler)
+	informerCollection.IstioVirtualService.AddEventHandler(resourceHandler)
+	informerCollection.IstioDestinationRule.AddEventHandler(resourceHandler)
+	informerCollection.GatewayClass.AddEventHandler(gatewayAPIResourceHandler)
+	informerCollection.Gateway.AddEventHandler(gatewayAPIResourceHandler)
+	informerCollection.HTTPRoute.AddEventHandler(gatewayAPIResourceHandler)
 
 	if IsNetworkingV1PackageSupported {
 		informerCollection.IngressClass = informerFactory.Networking().V1().IngressClasses().Informer()
@@ -184,16 +242,23 @@ func (c *Context) Run(stopChannel chan struct{}, omitCRDs bool, envVariables env
 		c.informers.AzureIngressProhibitedTarget: nil,
 		c.informers.IstioGateway:                 nil,
 		c.informers.IstioVirtualService:          nil,
+		c.informers.IstioDestinationRule:         nil,
 		c.informers.MultiClusterService:          nil,
 		c.informers.MultiClusterIngress:          nil,
-
-		c.informers.AzureApplicationGatewayRewrite: nil,
+		c.informers.GatewayClass:                 nil,
+		c.informers.Gateway:                      nil,
+		c.informers.HTTPRoute:                    nil,
+
+		c.informers.AzureApplicationGatewayRewrite:         nil,
+		c.informers.AzureApplicationGatewayClassParameters: nil,
+		c.informers.LoadDistributionPolicy:                 nil,
+		c.informers.AzureApplicationGatewayRouteMatch:      nil,
 		// c.informers.AzureApplicationGatewayBackendPool:          nil,
 		// c.informers.AzureApplicationGatewayInstanceUpdateStatus: nil,
 	}
 
 	sharedInformers := []cache.SharedInformer{
-		c.informers.Endpoints,
+		c.informers.EndpointSlices,
 		c.informers.Pods,
 		c.informers.Service,
 		c.informers.Secret,
@@ -208,6 +273,11 @@ func (c *Context) Run(stopChannel chan struct{}, omitCRDs bool, envVariables env
 
 	if IsNetworkingV1PackageSupported {
 		sharedInformers = append(sharedInformers, c.informers.IngressClass)
+
+		// The IngressClass AGIC serves may reference AzureApplicationGatewayClassParameters in its spec.parameters
+		if envVariables.IngressClassResourceEnabled {
+			sharedInformers = append(sharedInformers, c.informers.AzureApplicationGatewayClassParameters)
+		}
 	}
 
 	// For AGIC to watch for these CRDs the EnableBrownfieldDeploymentVarName env variable must be set to true
@@ -223,7 +293,21 @@ func (c *Context) Run(stopChannel chan struct{}, omitCRDs bool, envVariables env
 	}
 
 	if envVariables.EnableIstioIntegration {
-		sharedInformers = append(sharedInformers, c.informers.IstioGateway, c.informers.IstioVirtualService)
+		sharedInformers = append(sharedInformers, c.informers.IstioGateway, c.informers.IstioVirtualService, c.informers.IstioDestinationRule)
+	}
+
+	if envVariables.EnableGatewayAPI {
+		sharedInformers = append(sharedInformers, c.informers.GatewayClass, c.informers.Gateway, c.informers.HTTPRoute)
+	}
+
+	// For AGIC to watch for these CRDs the EnableLoadDistributionPolicyVarName and EnableRouteMatchVarName env variables must be set to true;
+	// Helm does not install the CRDs of a chart on upgrade, so the CRDs may be missing from clusters upgraded from an older chart
+	if envVariables.EnableLoadDistributionPolicy {
+		sharedInformers = append(sharedInformers, c.informers.LoadDistributionPolicy)
+	}
+
+	if envVariables.EnableRouteMatch {
+		sharedInformers = append(sharedInformers, c.informers.AzureApplicationGatewayRouteMatch)
 	}
 
 	for _, informer := range sharedInformers {
@@ -231,6 +315,7 @@ func (c *Context) Run(stopChannel chan struct{}, omitCRDs bool, envVariables env
 		// NOTE: Delyan could not figure out how to make informer.HasSynced == true for the CRDs in unit tests
 		// so until we do that - we omit WaitForCacheSync for CRDs in unit testing
 		if _, isCRD := crds[informer]; isCRD {
+			c.crdsSynced = append(c.crdsSynced, informer.HasSynced)
 			continue
 		}
 		hasSynced = append(hasSynced, informer.HasSynced)
@@ -254,9 +339,19 @@ func (c *Context) Run(stopChannel chan struct{}, omitCRDs bool, envVariables env
 	return nil
 }
 
+// WaitForCRDsSync waits for the initial sync of the CRD informers started by Run, which Run itself does not wait for.
+func (c *Context) WaitForCRDsSync(stopChannel chan struct{}) bool {
+	return cache.WaitForCacheSync(stopChannel, c.crdsSynced...)
+}
+
 // GetAGICPod returns the pod with specified name and namespace
 func (c *Context) GetAGICPod(envVariables environment.EnvVariables) *v1.Pod {
-	pod, err := c.kubeClient.CoreV1().Pods(envVariables.AGICPodNamespace).Get(context.TODO(), envVariables.AGICPodName, metav1.GetOptions{})
+	return GetAGICPod(c.kubeClient, envVariables)
+}
+
+// GetAGICPod fetches the pod AGIC runs in, before a Context exists.
+func GetAGICPod(kubeClient kubernetes.Interface, envVariables environment.EnvVariables) *v1.Pod {
+	pod, err := kubeClient.CoreV1().Pods(envVariables.AGICPodNamespace).Get(context.TODO(), envVariables.AGICPodName, metav1.GetOptions{})
 	if err != nil {
 		klog.Error("Error fetching AGIC Pod (This may happen if AGIC is running in a test environment). Error: ", err)
 		return nil
@@ -264,6 +359,25 @@ func (c *Context) GetAGICPod(envVariables environment.EnvVariables) *v1.Pod {
 	return pod
 }
 
+// NewLeaseLock returns a Lease based lock, which AGIC replicas use to elect the one replica allowed to mutate Application Gateway.
+func (c *Context) NewLeaseLock(namespace string, name string, identity string) resourcelock.Interface {
+	return &resourcelock.LeaseLock{
+		LeaseMeta: metav1.ObjectMeta{
+			Namespace: namespace,
+			Name:      name,
+		},
+		Client: c.kubeClient.CoordinationV1(),
+		LockConfig: resourcelock.ResourceLockConfig{
+			Identity: identity,
+		},
+	}
+}
+
+// ConfigMaps returns a client for the ConfigMaps in the given namespace, e.g. to persist state which AGIC replicas share.
+func (c *Context) ConfigMaps(namespace string) corev1.ConfigMapInterface {
+	return c.kubeClient.CoreV1().ConfigMaps(namespace)
+}
+
 // GetBackendPool returns backend pool with specified name
 func (c *Context) GetBackendPool(backendPoolName string) (*agpoolv1beta1.AzureApplicationGatewayBackendPool, error) {
 	agpool, exist, err := c.Caches.AzureApplicationGatewayBackendPool.GetByKey(backendPoolName)
@@ -319,6 +433,60 @@ func (c *Context) GetRewriteRuleSetCustomResource(namespace string, name string)
 	return agrewrite.(*agrewritev1beta1.AzureApplicationGatewayRewrite), nil
 }
 
+// GetLoadDistributionPolicy returns the load distribution policy with specified name and namespace
+func (c *Context) GetLoadDistributionPolicy(namespace string, name string) (*ldpv1beta1.LoadDistributionPolicy, error) {
+	policy, exist, err := c.Caches.LoadDistributionPolicy.GetByKey(namespace + "/" + name)
+	if err != nil {
+		e := controllererrors.NewErrorWithInnerErrorf(
+			controllererrors.ErrorFetchingLoadDistributionPolicy,
+			err,
+			"Error fetching load distribution policy %s/%s from store",
+			namespace, name)
+		klog.Error(e.Error())
+		c.MetricStore.IncErrorCount(e.Code)
+		return nil, e
+	}
+
+	if !exist {
+		e := controllererrors.NewErrorf(
+			controllererrors.ErrorFetchingLoadDistributionPolicy,
+			"Load distribution policy %s/%s not found",
+			namespace, name)
+		klog.Error(e.Error())
+		c.MetricStore.IncErrorCount(e.Code)
+		return nil, e
+	}
+
+	return policy.(*ldpv1beta1.LoadDistributionPolicy), nil
+}
+
+// GetRouteMatch returns the route match custom resource with specified name and namespace
+func (c *Context) GetRouteMatch(namespace string, name string) (*agroutematchv1beta1.AzureApplicationGatewayRouteMatch, error) {
+	routeMatch, exist, err := c.Caches.AzureApplicationGatewayRouteMatch.GetByKey(namespace + "/" + name)
+	if err != nil {
+		e := controllererrors.NewErrorWithInnerErrorf(
+			controllererrors.ErrorFetchingRouteMatch,
+			err,
+			"Error fetching route match custom resource %s/%s from store",
+			namespace, name)
+		klog.Error(e.Error())
+		c.MetricStore.IncErrorCount(e.Code)
+		return nil, e
+	}
+
+	if !exist {
+		e := controllererrors.NewErrorf(
+			controllererrors.ErrorFetchingRouteMatch,
+			"Route match custom resource %s/%s not found",
+			namespace, name)
+		klog.Error(e.Error())
+		c.MetricStore.IncErrorCount(e.Code)
+		return nil, e
+	}
+
+	return routeMatch.(*agroutematchv1beta1.AzureApplicationGatewayRouteMatch), nil
+}
+
 // GetInstanceUpdateStatus returns update status from when Application Gateway instances update backend pool addresses
 func (c *Context) GetInstanceUpdateStatus(instanceUpdateStatusName string) (*aginstv1beta1.AzureApplicationGatewayInstanceUpdateStatus, error) {
 	agpool, exist, err := c.Caches.AzureApplicationGatewayInstanceUpdateStatus.GetByKey(instanceUpdateStatusName)
@@ -388,79 +556,6 @@ func (c *Context) ListServices() []*v1.Service {
 	return serviceList
 }
 
-// GetEndpointsByService returns the endpoints associated with a specific service.
-func (c *Context) GetEndpointsByService(serviceKey string) (*v1.Endpoints, error) {
-	if IsInMultiClusterMode {
-		return c.generateEndpointsFromMultiClusterService(serviceKey)
-	}
-	endpointsInterface, exist, err := c.Caches.Endpoints.GetByKey(serviceKey)
-
-	if !exist {
-		e := controllererrors.NewErrorf(
-			controllererrors.ErrorFetchingEndpoints,
-			"Endpoint not found for %s",
-			serviceKey)
-		klog.Error(e.Error())
-		c.MetricStore.IncErrorCount(e.Code)
-		return nil, e
-	}
-
-	if err != nil {
-		e := controllererrors.NewErrorWithInnerErrorf(
-			controllererrors.ErrorFetchingEndpoints,
-			err,
-			"Error fetching endpoints from store for %s",
-			serviceKey)
-		klog.Error(e.Error())
-		c.MetricStore.IncErrorCount(e.Code)
-		return nil, e
-	}
-	return endpointsInterface.(*v1.Endpoints), nil
-
-}
-
-func (c *Context) generateEndpointsFromMultiClusterService(serviceKey string) (*v1.Endpoints, error) {
-	multiClusterServiceInterface, exist, err := c.Caches.MultiClusterService.GetByKey(serviceKey)
-
-	if !exist {
-		e := controllererrors.NewErrorf(
-			controllererrors.ErrorFetchingMultiClusterService,
-			"MultiCluster service not found for %s",
-			serviceKey)
-		klog.Error(e.Error())
-		c.MetricStore.IncErrorCount(e.Code)
-		return nil, e
-	}
-
-	if err != nil {
-		e := controllererrors.NewErrorWithInnerErrorf(
-			controllererrors.ErrorFetchingMultiClusterService,
-			err,
-			"Error fetching MultiCluster service from store for %s",
-			serviceKey)
-		klog.Error(e.Error())
-		c.MetricStore.IncErrorCount(e.Code)
-		return nil, e
-	}
-
-	multiClusterService := multiClusterServiceInterface.(*multiClusterService.MultiClusterService)
-	endpoints := &v1.Endpoints{}
-	subset := v1.EndpointSubset{}
-
-	for _, multiClusterEndpoint := range multiClusterService.Status.Endpoints {
-		address := v1.EndpointAddress{IP: multiClusterEndpoint.IP}
-		subset.Addresses = append(subset.Addresses, address)
-	}
-
-	for _, ports := range multiClusterService.Spec.Ports {
-		v1Port := v1.EndpointPort{Port: int32(ports.Port), Protocol: v1.Protocol(ports.Protocol)}
-		subset.Ports = append(subset.Ports, v1Port)
-	}
-
-	endpoints.Subsets = []v1.EndpointSubset{subset}
-	return endpoints, nil
-}
-
 // ListPodsByServiceSelector returns pods that are associated with a specific service.
 func (c *Context) ListPodsByServiceSelector(service *v1.Service) []*v1.Pod {
 	selectorSet := mapset.NewSet()
@@ -487,10 +582,42 @@ func (c *Context) ListPodsByServiceSelector(service *v1.Service) []*v1.Pod {
 	return podList
 }
 
+// GetPod returns the pod identified by the key; It is nil when the pod is not in the cache.
+func (c *Context) GetPod(podKey string) *v1.Pod {
+	podInterface, exist, err := c.Caches.Pods.GetByKey(podKey)
+	if err != nil {
+		klog.V(3).Infof("unable to get pod from store, error occurred %s", err)
+		return nil
+	}
+	if !exist {
+		klog.V(9).Infof("Pod %s does not exist", podKey)
+		return nil
+	}
+	return podInterface.(*v1.Pod)
+}
+
+// ListPodsWithReadinessGate returns the pods of the watched namespaces whose spec lists the readiness gate of the given condition type.
+func (c *Context) ListPodsWithReadinessGate(conditionType v1.PodConditionType) []*v1.Pod {
+	var podList []*v1.Pod
+	for _, podInterface := range c.Caches.Pods.List() {
+		pod := podInterface.(*v1.Pod)
+		if _, exists := c.namespaces[pod.Namespace]; len(c.namespaces) > 0 && !exists {
+			continue
+		}
+		for _, gate := range pod.Spec.ReadinessGates {
+			if gate.ConditionType == conditionType {
+				podList = append(podList, pod)
+				break
+			}
+		}
+	}
+	return podList
+}
+
 // IsPodReferencedByAnyIngress provides whether a POD is useful i.e. a POD is used by an ingress
 func (c *Context) IsPodReferencedByAnyIngress(pod *v1.Pod) bool {
 	// first find all the services
-	services := c.listServicesByPodSelector(pod)
+	services := c.ListServicesByPodSelector(pod)
 	for _, service := range services {
 		if c.isServiceReferencedByAnyIngress(service) {
 			return true
@@ -500,12 +627,6 @@ func (c *Context) IsPodReferencedByAnyIngress(pod *v1.Pod) bool {
 	return false
 }
 
-// IsEndpointReferencedByAnyIngress provides whether an Endpoint is useful i.e. a Endpoint is used by an ingress
-func (c *Context) IsEndpointReferencedByAnyIngress(endpoints *v1.Endpoints) bool {
-	service := c.GetService(fmt.Sprintf("%v/%v", endpoints.Namespace, endpoints.Name))
-	return service != nil && c.isServiceReferencedByAnyIngress(service)
-}
-
 // ListHTTPIngresses returns a list of all the ingresses for HTTP from cache.
 func (c *Context) ListHTTPIngresses() []*networking.Ingress {
 	var ingressList []*networking.Ingress
@@ -537,10 +658,14 @@ func (c *Context) ListHTTPIngresses() []*networking.Ingress {
 
 func (c *Context) filterAndSort(ingList []*networking.Ingress) []*networking.Ingress {
 	var ingressList []*networking.Ingress
+	parameters := c.GetIngressClassParameters()
 	for _, ingress := range ingList {
 		if !c.IsIngressClass(ingress) {
 			continue
 		}
+		if parameters != nil {
+			ingress = withClassDefaults(ingress, parameters)
+		}
 		if len(ingress.Spec.Rules) > 0 && !hasHTTPRule(ingress) {
 			continue
 		}
@@ -656,24 +781,6 @@ func (c *Context) GetVirtualServicesForGateway(gateway v1alpha3.Gateway) []*v1al
 	return virtualServices
 }
 
-// GetEndpointsForVirtualService returns a list of Endpoints associated with a Virtual Service
-func (c *Context) GetEndpointsForVirtualService(virtualService v1alpha3.VirtualService) v1.EndpointsList {
-	endpointList := make([]v1.Endpoints, 0)
-	namespace := virtualService.Namespace
-	for _, httpRouteRule := range virtualService.Spec.HTTP {
-		for _, route := range httpRouteRule.Route {
-			serviceKey := fmt.Sprintf("%v/%v", namespace, route.Destination.Host)
-			endpoint, err := c.GetEndpointsByService(serviceKey)
-			if err == nil {
-				endpointList = append(endpointList, *endpoint)
-			}
-		}
-	}
-	return v1.EndpointsList{
-		Items: endpointList,
-	}
-}
-
 // GetGateways returns all Istio Gateways that are annotated.
 func (c *Context) GetGateways() []*v1alpha3.Gateway {
 	annotatedGateways := make([]*v1alpha3.Gateway, 0)
@@ -854,6 +961,63 @@ func (c *Context) updateMultiClusterIngressStatus(ingressToUpdate networking.Ing
 	return nil
 }
 
+// UpdateIngressAnnotation sets a single annotation on the ingress; The rest of the ingress is left untouched.
+func (c *Context) UpdateIngressAnnotation(ingressToUpdate networking.Ingress, key string, value string) error {
+	patch, err := json.Marshal(map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"annotations": map[string]string{
+				key: value,
+			},
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	if IsNetworkingV1PackageSupported && !IsInMultiClusterMode {
+		_, err = c.kubeClient.NetworkingV1().Ingresses(ingressToUpdate.Namespace).Patch(context.TODO(), ingressToUpdate.Name, types.MergePatchType, patch, metav1.PatchOptions{})
+	} else if IsInMultiClusterMode {
+		_, err = c.multiClusterCrdClient.MulticlusteringressesV1alpha1().MultiClusterIngresses(ingressToUpdate.Namespace).Patch(context.TODO(), ingressToUpdate.Name, types.MergePatchType, patch, metav1.PatchOptions{})
+	} else {
+		_, err = c.kubeClient.ExtensionsV1beta1().Ingresses(ingressToUpdate.Namespace).Patch(context.TODO(), ingressToUpdate.Name, types.MergePatchType, patch, metav1.PatchOptions{})
+	}
+
+	if err != nil {
+		e := controllererrors.NewErrorWithInnerErrorf(
+			controllererrors.ErrorUpdatingIngressAnnotation,
+			err,
+			"Unable to set annotation %s on ingress %s/%s", key, ingressToUpdate.Namespace, ingressToUpdate.Name,
+		)
+		c.MetricStore.IncErrorCount(e.Code)
+		return e
+	}
+	return nil
+}
+
+// UpdatePodCondition sets a single condition in the status of the pod; The other conditions are left untouched.
+func (c *Context) UpdatePodCondition(pod *v1.Pod, condition v1.PodCondition) error {
+	patch, err := json.Marshal(map[string]interface{}{
+		"status": map[string]interface{}{
+			"conditions": []v1.PodCondition{condition},
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = c.kubeClient.CoreV1().Pods(pod.Namespace).Patch(context.TODO(), pod.Name, types.StrategicMergePatchType, patch, metav1.PatchOptions{}, "status")
+	if err != nil {
+		e := controllererrors.NewErrorWithInnerErrorf(
+			controllererrors.ErrorUpdatingPodCondition,
+			err,
+			"Unable to set condition %s on pod %s/%s", condition.Type, pod.Namespace, pod.Name,
+		)
+		c.MetricStore.IncErrorCount(e.Code)
+		return e
+	}
+	return nil
+}
+
 func hasHTTPRule(ingress *networking.Ingress) bool {
 	for _, rule := range ingress.Spec.Rules {
 		if rule.HTTP != nil {
@@ -872,7 +1036,8 @@ func hasTCPPort(service *v1.Service) bool {
 	return false
 }
 
-func (c *Context) listServicesByPodSelector(pod *v1.Pod) []*v1.Service {
+// ListServicesByPodSelector returns the Services whose selector selects the pod.
+func (c *Context) ListServicesByPodSelector(pod *v1.Pod) []*v1.Service {
 	labelSet := mapset.NewSet()
 	for k, v := range pod.Labels {
 		labelSet.Add(k + ":" + v)
@@ -906,8 +1071,47 @@ func (c *Context) isServiceReferencedByAnyIngress(service *v1.Service) bool {
 				}
 			}
 		}
+		if c.isServiceTargetedByLoadDistributionPolicy(ingress, service) || c.isServiceTargetedByRouteMatch(ingress, service) {
+			return true
+		}
 	}
 
+	return c.isServiceReferencedByAnyHTTPRoute(service)
+}
+
+// isServiceTargetedByLoadDistributionPolicy tells whether the load distribution policy the Ingress references sends traffic to the service.
+func (c *Context) isServiceTargetedByLoadDistributionPolicy(ingress *networking.Ingress, service *v1.Service) bool {
+	policyName, err := annotations.LoadDistributionPolicy(ingress)
+	if err != nil || policyName == "" || ingress.Namespace != service.Namespace || c.Caches.LoadDistributionPolicy == nil {
+		return false
+	}
+	policy, exists, err := c.Caches.LoadDistributionPolicy.GetByKey(ingress.Namespace + "/" + policyName)
+	if err != nil || !exists {
+		return false
+	}
+	for _, target := range policy.(*ldpv1beta1.LoadDistributionPolicy).Spec.Targets {
+		if target.Backend.Service != nil && target.Backend.Service.Name == service.Name {
+			return true
+		}
+	}
+	return false
+}
+
+// isServiceTargetedByRouteMatch tells whether the route match custom resource the Ingress references routes requests to the service.
+func (c *Context) isServiceTargetedByRouteMatch(ingress *networking.Ingress, service *v1.Service) bool {
+	routeMatchName, err := annotations.RouteMatchCustomResource(ingress)
+	if err != nil || routeMatchName == "" || ingress.Namespace != service.Namespace || c.Caches.AzureApplicationGatewayRouteMatch == nil {
+		return false
+	}
+	routeMatch, exists, err := c.Caches.AzureApplicationGatewayRouteMatch.GetByKey(ingress.Namespace + "/" + routeMatchName)
+	if err != nil || !exists {
+		return false
+	}
+	for _, rule := range routeMatch.(*agroutematchv1beta1.AzureApplicationGatewayRouteMatch).Spec.Rules {
+		if rule.Backend.Service != nil && rule.Backend.Service.Name == service.Name {
+			return true
+		}
+	}
 	return false
 }
 
@@ -953,7 +1157,7 @@ func (c *Context) IsIngressClass(ing *networking.Ingress) bool {
 
 	// match by annotation (for Backward compatibility)
 	if className, err := annotations.IngressClass(ing); err == nil && className != "" {
-		return className == c.ingressClassControllerName
+		return className == c.annotatedIngressClass()
 	}
 
 	// match by ingress class resource
@@ -972,6 +1176,14 @@ func (c *Context) IsIngressClass(ing *networking.Ingress) bool {
 	return false
 }
 
+// annotatedIngressClass is the kubernetes.io/ingress.class annotation of the resources AGIC processes.
+func (c *Context) annotatedIngressClass() string {
+	if c.ingressClassAnnotation != "" {
+		return c.ingressClassAnnotation
+	}
+	return c.ingressClassControllerName
+}
+
 // IsIstioGatewayIngress checks if this gateway should be handled by AGIC or not
 func (c *Context) IsIstioGatewayIngress(gateway *v1alpha3.Gateway) bool {
 	className, err := annotations.IstioGatewayIngressClass(gateway)
@@ -979,5 +1191,5 @@ func (c *Context) IsIstioGatewayIngress(gateway *v1alpha3.Gateway) bool {
 		return false
 	}
 
-	return className == c.ingressClassControllerName
+	return className == c.annotatedIngressClass()
 }
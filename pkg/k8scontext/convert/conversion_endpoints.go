@@ -0,0 +1,83 @@
+package convert
+
+import (
+	"fmt"
+	"net"
+
+	"github.com/Azure/go-autorest/autorest/to"
+	v1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+)
+
+// ToEndpointSlices converts k8s.io/api/core/v1/Endpoints into the EndpointSlices the EndpointSlice mirroring controller would create:
+// One slice per subset and address type, labeled with the name of the service. Not ready addresses are not ready endpoints.
+func ToEndpointSlices(endpoints *v1.Endpoints) []*discoveryv1.EndpointSlice {
+	if endpoints == nil {
+		return nil
+	}
+
+	var slices []*discoveryv1.EndpointSlice
+	for _, subset := range endpoints.Subsets {
+		var ports []discoveryv1.EndpointPort
+		for idx := range subset.Ports {
+			port := subset.Ports[idx]
+			endpointPort := discoveryv1.EndpointPort{
+				Port:        to.Int32Ptr(port.Port),
+				Protocol:    &port.Protocol,
+				AppProtocol: port.AppProtocol,
+			}
+			if port.Name != "" {
+				endpointPort.Name = to.StringPtr(port.Name)
+			}
+			ports = append(ports, endpointPort)
+		}
+
+		byAddressType := make(map[discoveryv1.AddressType][]discoveryv1.Endpoint)
+		for _, addresses := range []struct {
+			addresses []v1.EndpointAddress
+			ready     bool
+		}{
+			{subset.Addresses, true},
+			{subset.NotReadyAddresses, false},
+		} {
+			for _, address := range addresses.addresses {
+				addressType := discoveryv1.AddressTypeIPv4
+				if ip := net.ParseIP(address.IP); ip != nil && ip.To4() == nil {
+					addressType = discoveryv1.AddressTypeIPv6
+				}
+				endpoint := discoveryv1.Endpoint{
+					Addresses: []string{address.IP},
+					Conditions: discoveryv1.EndpointConditions{
+						Ready:       to.BoolPtr(addresses.ready),
+						Serving:     to.BoolPtr(addresses.ready),
+						Terminating: to.BoolPtr(false),
+					},
+					NodeName:  address.NodeName,
+					TargetRef: address.TargetRef,
+				}
+				if address.Hostname != "" {
+					endpoint.Hostname = to.StringPtr(address.Hostname)
+				}
+				byAddressType[addressType] = append(byAddressType[addressType], endpoint)
+			}
+		}
+
+		for _, addressType := range []discoveryv1.AddressType{discoveryv1.AddressTypeIPv4, discoveryv1.AddressTypeIPv6} {
+			if _, exists := byAddressType[addressType]; !exists {
+				continue
+			}
+			slice := &discoveryv1.EndpointSlice{
+				AddressType: addressType,
+				Endpoints:   byAddressType[addressType],
+				Ports:       ports,
+			}
+			slice.Namespace = endpoints.Namespace
+			slice.Name = fmt.Sprintf("%s-%d", endpoints.Name, len(slices))
+			slice.Labels = map[string]string{discoveryv1.LabelServiceName: endpoints.Name}
+			slice.APIVersion = discoveryv1.SchemeGroupVersion.String()
+			slice.Kind = "EndpointSlice"
+			slices = append(slices, slice)
+		}
+	}
+	return slices
+}
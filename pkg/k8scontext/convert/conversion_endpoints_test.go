@@ -0,0 +1,44 @@
+package convert
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	v1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+
+	"github.com/Azure/application-gateway-kubernetes-ingress/pkg/tests"
+)
+
+var _ = Describe("Test endpoints conversion", func() {
+	Context("Test conversion of Endpoints into EndpointSlices", func() {
+		It("creates a slice per subset and address type", func() {
+			endpoints := tests.NewEndpointsFixture()
+			endpoints.Subsets[0].NotReadyAddresses = []v1.EndpointAddress{{IP: "10.9.8.6"}, {IP: "fd00::1"}}
+
+			slices := ToEndpointSlices(endpoints)
+			Expect(slices).To(HaveLen(2))
+
+			ipv4 := slices[0]
+			Expect(ipv4.Name).To(Equal(tests.ServiceName + "-0"))
+			Expect(ipv4.Namespace).To(Equal(tests.Namespace))
+			Expect(ipv4.Labels).To(HaveKeyWithValue(discoveryv1.LabelServiceName, tests.ServiceName))
+			Expect(ipv4.AddressType).To(Equal(discoveryv1.AddressTypeIPv4))
+			Expect(ipv4.Ports).To(HaveLen(len(endpoints.Subsets[0].Ports)))
+			Expect(ipv4.Endpoints).To(HaveLen(2))
+			Expect(ipv4.Endpoints[0].Addresses).To(Equal([]string{"10.9.8.7"}))
+			Expect(*ipv4.Endpoints[0].Conditions.Ready).To(BeTrue())
+			Expect(ipv4.Endpoints[1].Addresses).To(Equal([]string{"10.9.8.6"}))
+			Expect(*ipv4.Endpoints[1].Conditions.Ready).To(BeFalse())
+			Expect(*ipv4.Endpoints[1].Conditions.Terminating).To(BeFalse())
+
+			ipv6 := slices[1]
+			Expect(ipv6.Name).To(Equal(tests.ServiceName + "-1"))
+			Expect(ipv6.AddressType).To(Equal(discoveryv1.AddressTypeIPv6))
+			Expect(ipv6.Endpoints).To(HaveLen(1))
+		})
+
+		It("returns no slices for nil endpoints", func() {
+			Expect(ToEndpointSlices(nil)).To(BeEmpty())
+		})
+	})
+})
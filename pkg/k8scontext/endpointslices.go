@@ -0,0 +1,151 @@
+// -------------------------------------------------------------------------------------------
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License. See License.txt in the project root for license information.
+// --------------------------------------------------------------------------------------------
+
+package k8scontext
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/knative/pkg/apis/istio/v1alpha3"
+	v1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/klog/v2"
+
+	multiClusterService "github.com/Azure/application-gateway-kubernetes-ingress/pkg/apis/multiclusterservice/v1alpha1"
+	"github.com/Azure/application-gateway-kubernetes-ingress/pkg/controllererrors"
+	"github.com/Azure/application-gateway-kubernetes-ingress/pkg/k8scontext/convert"
+)
+
+// EndpointSliceServiceIndex indexes EndpointSlices by the key of the service they belong to.
+const EndpointSliceServiceIndex = "service"
+
+// EndpointSliceIndexers are the indexers of the EndpointSlices cache.
+var EndpointSliceIndexers = cache.Indexers{
+	EndpointSliceServiceIndex: func(obj interface{}) ([]string, error) {
+		if serviceKey := endpointSliceServiceKey(obj.(*discoveryv1.EndpointSlice)); serviceKey != "" {
+			return []string{serviceKey}, nil
+		}
+		return nil, nil
+	},
+}
+
+// endpointSliceServiceKey returns the key of the service the slice belongs to; It is empty for slices not managed for a service.
+func endpointSliceServiceKey(slice *discoveryv1.EndpointSlice) string {
+	serviceName := slice.Labels[discoveryv1.LabelServiceName]
+	if serviceName == "" {
+		return ""
+	}
+	return fmt.Sprintf("%v/%v", slice.Namespace, serviceName)
+}
+
+// GetEndpointSlicesByService returns the EndpointSlices of a specific service, sorted by name.
+// A service has several slices when it has many endpoints, is dual-stack or its endpoints expose different ports.
+func (c *Context) GetEndpointSlicesByService(serviceKey string) ([]*discoveryv1.EndpointSlice, error) {
+	if IsInMultiClusterMode {
+		return c.generateEndpointSlicesFromMultiClusterService(serviceKey)
+	}
+
+	objects, err := c.Caches.EndpointSlices.ByIndex(EndpointSliceServiceIndex, serviceKey)
+	if err != nil {
+		e := controllererrors.NewErrorWithInnerErrorf(
+			controllererrors.ErrorFetchingEndpoints,
+			err,
+			"Error fetching endpoint slices from store for %s",
+			serviceKey)
+		klog.Error(e.Error())
+		c.MetricStore.IncErrorCount(e.Code)
+		return nil, e
+	}
+
+	if len(objects) == 0 {
+		e := controllererrors.NewErrorf(
+			controllererrors.ErrorFetchingEndpoints,
+			"Endpoint slices not found for %s",
+			serviceKey)
+		klog.Error(e.Error())
+		c.MetricStore.IncErrorCount(e.Code)
+		return nil, e
+	}
+
+	slices := make([]*discoveryv1.EndpointSlice, 0, len(objects))
+	for _, obj := range objects {
+		slices = append(slices, obj.(*discoveryv1.EndpointSlice))
+	}
+	sort.Slice(slices, func(i, j int) bool {
+		return slices[i].Name < slices[j].Name
+	})
+	return slices, nil
+}
+
+func (c *Context) generateEndpointSlicesFromMultiClusterService(serviceKey string) ([]*discoveryv1.EndpointSlice, error) {
+	multiClusterServiceInterface, exist, err := c.Caches.MultiClusterService.GetByKey(serviceKey)
+
+	if !exist {
+		e := controllererrors.NewErrorf(
+			controllererrors.ErrorFetchingMultiClusterService,
+			"MultiCluster service not found for %s",
+			serviceKey)
+		klog.Error(e.Error())
+		c.MetricStore.IncErrorCount(e.Code)
+		return nil, e
+	}
+
+	if err != nil {
+		e := controllererrors.NewErrorWithInnerErrorf(
+			controllererrors.ErrorFetchingMultiClusterService,
+			err,
+			"Error fetching MultiCluster service from store for %s",
+			serviceKey)
+		klog.Error(e.Error())
+		c.MetricStore.IncErrorCount(e.Code)
+		return nil, e
+	}
+
+	multiClusterService := multiClusterServiceInterface.(*multiClusterService.MultiClusterService)
+	endpoints := &v1.Endpoints{}
+	endpoints.Namespace = multiClusterService.Namespace
+	endpoints.Name = multiClusterService.Name
+	subset := v1.EndpointSubset{}
+
+	for _, multiClusterEndpoint := range multiClusterService.Status.Endpoints {
+		address := v1.EndpointAddress{IP: multiClusterEndpoint.IP}
+		subset.Addresses = append(subset.Addresses, address)
+	}
+
+	for _, ports := range multiClusterService.Spec.Ports {
+		v1Port := v1.EndpointPort{Port: int32(ports.Port), Protocol: v1.Protocol(ports.Protocol)}
+		subset.Ports = append(subset.Ports, v1Port)
+	}
+
+	endpoints.Subsets = []v1.EndpointSubset{subset}
+	return convert.ToEndpointSlices(endpoints), nil
+}
+
+// IsEndpointSliceReferencedByAnyIngress provides whether an EndpointSlice is useful i.e. the service it belongs to is used by an ingress
+func (c *Context) IsEndpointSliceReferencedByAnyIngress(slice *discoveryv1.EndpointSlice) bool {
+	serviceKey := endpointSliceServiceKey(slice)
+	if serviceKey == "" {
+		return false
+	}
+	service := c.GetService(serviceKey)
+	return service != nil && c.isServiceReferencedByAnyIngress(service)
+}
+
+// GetEndpointSlicesForVirtualService returns the EndpointSlices of the services a Virtual Service routes to
+func (c *Context) GetEndpointSlicesForVirtualService(virtualService v1alpha3.VirtualService) []*discoveryv1.EndpointSlice {
+	endpointSlices := make([]*discoveryv1.EndpointSlice, 0)
+	namespace := virtualService.Namespace
+	for _, httpRouteRule := range virtualService.Spec.HTTP {
+		for _, route := range httpRouteRule.Route {
+			serviceKey := fmt.Sprintf("%v/%v", namespace, route.Destination.Host)
+			if slices, err := c.GetEndpointSlicesByService(serviceKey); err == nil {
+				endpointSlices = append(endpointSlices, slices...)
+			}
+		}
+	}
+	return endpointSlices
+}
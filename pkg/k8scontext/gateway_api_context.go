@@ -0,0 +1,160 @@
+// -------------------------------------------------------------------------------------------
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License. See License.txt in the project root for license information.
+// --------------------------------------------------------------------------------------------
+
+package k8scontext
+
+import (
+	"context"
+	"sort"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+
+	"github.com/Azure/application-gateway-kubernetes-ingress/pkg/controllererrors"
+	"github.com/Azure/application-gateway-kubernetes-ingress/pkg/gatewayapi"
+)
+
+// ListGatewayClasses returns the GatewayClasses AGIC implements, i.e. those naming AGIC's controller, sorted by name.
+// When AGIC manages several Application Gateways, only the GatewayClass named after the IngressClass bound to this gateway is listed.
+func (c *Context) ListGatewayClasses() []*gatewayv1.GatewayClass {
+	var gatewayClasses []*gatewayv1.GatewayClass
+	if c.Caches.GatewayClass == nil {
+		return gatewayClasses
+	}
+	for _, obj := range c.Caches.GatewayClass.List() {
+		gatewayClass := obj.(*gatewayv1.GatewayClass)
+		if string(gatewayClass.Spec.ControllerName) != c.gatewayClassControllerName {
+			continue
+		}
+		if c.gatewayClassName != "" && gatewayClass.Name != c.gatewayClassName {
+			continue
+		}
+		gatewayClasses = append(gatewayClasses, gatewayClass)
+	}
+	sort.SliceStable(gatewayClasses, func(i, j int) bool {
+		return gatewayClasses[i].Name < gatewayClasses[j].Name
+	})
+	return gatewayClasses
+}
+
+// ListGatewayAPIGateways returns the Gateways of the GatewayClasses AGIC implements in the watched namespaces.
+func (c *Context) ListGatewayAPIGateways() []*gatewayv1.Gateway {
+	var gateways []*gatewayv1.Gateway
+	if c.Caches.Gateway == nil {
+		return gateways
+	}
+	gatewayClasses := make(map[string]interface{})
+	for _, gatewayClass := range c.ListGatewayClasses() {
+		gatewayClasses[gatewayClass.Name] = nil
+	}
+	for _, obj := range c.Caches.Gateway.List() {
+		gateway := obj.(*gatewayv1.Gateway)
+		if _, exists := gatewayClasses[string(gateway.Spec.GatewayClassName)]; !exists {
+			continue
+		}
+		if _, exists := c.namespaces[gateway.Namespace]; len(c.namespaces) > 0 && !exists {
+			continue
+		}
+		gateways = append(gateways, gateway)
+	}
+	return gateways
+}
+
+// ListHTTPRoutes returns the HTTPRoutes in the watched namespaces.
+func (c *Context) ListHTTPRoutes() []*gatewayv1.HTTPRoute {
+	var routes []*gatewayv1.HTTPRoute
+	if c.Caches.HTTPRoute == nil {
+		return routes
+	}
+	for _, obj := range c.Caches.HTTPRoute.List() {
+		route := obj.(*gatewayv1.HTTPRoute)
+		if _, exists := c.namespaces[route.Namespace]; len(c.namespaces) > 0 && !exists {
+			continue
+		}
+		routes = append(routes, route)
+	}
+	return routes
+}
+
+// isServiceReferencedByAnyHTTPRoute tells whether a backendRef of an HTTPRoute, in the namespace of the route or another one, points at the service.
+func (c *Context) isServiceReferencedByAnyHTTPRoute(service *v1.Service) bool {
+	if !c.gatewayAPIEnabled {
+		return false
+	}
+	for _, route := range c.ListHTTPRoutes() {
+		for _, rule := range route.Spec.Rules {
+			for _, ref := range rule.BackendRefs {
+				if (ref.Group != nil && *ref.Group != "") || (ref.Kind != nil && *ref.Kind != gatewayapi.ServiceKind) {
+					continue
+				}
+				namespace := route.Namespace
+				if ref.Namespace != nil {
+					namespace = string(*ref.Namespace)
+				}
+				if namespace == service.Namespace && string(ref.Name) == service.Name {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// GetGatewayClassControllerName returns the controllerName of the GatewayClasses AGIC implements.
+func (c *Context) GetGatewayClassControllerName() string {
+	return c.gatewayClassControllerName
+}
+
+// UpdateGatewayClassStatus replaces the status of the GatewayClass.
+func (c *Context) UpdateGatewayClassStatus(gatewayClass *gatewayv1.GatewayClass) error {
+	client := c.gatewayAPIClient.GatewayV1().GatewayClasses()
+	existing, err := client.Get(context.TODO(), gatewayClass.Name, metav1.GetOptions{})
+	if err != nil {
+		return c.gatewayAPIStatusError(err, "Unable to get GatewayClass %s", gatewayClass.Name)
+	}
+
+	existing.Status = gatewayClass.Status
+	if _, err := client.UpdateStatus(context.TODO(), existing, metav1.UpdateOptions{}); err != nil {
+		return c.gatewayAPIStatusError(err, "Unable to update GatewayClass %s status", gatewayClass.Name)
+	}
+	return nil
+}
+
+// UpdateGatewayStatus replaces the status of the Gateway.
+func (c *Context) UpdateGatewayStatus(gateway *gatewayv1.Gateway) error {
+	client := c.gatewayAPIClient.GatewayV1().Gateways(gateway.Namespace)
+	existing, err := client.Get(context.TODO(), gateway.Name, metav1.GetOptions{})
+	if err != nil {
+		return c.gatewayAPIStatusError(err, "Unable to get Gateway %s/%s", gateway.Namespace, gateway.Name)
+	}
+
+	existing.Status = gateway.Status
+	if _, err := client.UpdateStatus(context.TODO(), existing, metav1.UpdateOptions{}); err != nil {
+		return c.gatewayAPIStatusError(err, "Unable to update Gateway %s/%s status", gateway.Namespace, gateway.Name)
+	}
+	return nil
+}
+
+// UpdateHTTPRouteStatus replaces the status of the HTTPRoute.
+func (c *Context) UpdateHTTPRouteStatus(route *gatewayv1.HTTPRoute) error {
+	client := c.gatewayAPIClient.GatewayV1().HTTPRoutes(route.Namespace)
+	existing, err := client.Get(context.TODO(), route.Name, metav1.GetOptions{})
+	if err != nil {
+		return c.gatewayAPIStatusError(err, "Unable to get HTTPRoute %s/%s", route.Namespace, route.Name)
+	}
+
+	existing.Status = route.Status
+	if _, err := client.UpdateStatus(context.TODO(), existing, metav1.UpdateOptions{}); err != nil {
+		return c.gatewayAPIStatusError(err, "Unable to update HTTPRoute %s/%s status", route.Namespace, route.Name)
+	}
+	return nil
+}
+
+func (c *Context) gatewayAPIStatusError(err error, msg string, args ...interface{}) error {
+	e := controllererrors.NewErrorWithInnerErrorf(controllererrors.ErrorUpdatingGatewayAPIStatus, err, msg, args...)
+	c.MetricStore.IncErrorCount(e.Code)
+	return e
+}
@@ -0,0 +1,99 @@
+// -------------------------------------------------------------------------------------------
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License. See License.txt in the project root for license information.
+// --------------------------------------------------------------------------------------------
+
+package k8scontext
+
+import (
+	"maps"
+	"reflect"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/klog/v2"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+
+	"github.com/Azure/application-gateway-kubernetes-ingress/pkg/utils"
+)
+
+// gatewaySecretsKey keys the certificates of a Gateway in the ingressSecretsMap, apart from those of an Ingress with the same name.
+type gatewaySecretsKey string
+
+// Gateway API resource handlers
+func (h handlers) gatewayAPIAdd(obj interface{}) {
+	if gateway, ok := obj.(*gatewayv1.Gateway); ok {
+		h.trackGatewaySecrets(gateway)
+	}
+	h.addFunc(obj)
+}
+
+func (h handlers) gatewayAPIUpdate(oldObj, newObj interface{}) {
+	// AGIC reports the status of the Gateway API resources; Updating it must not trigger another event loop.
+	if onlyGatewayAPIStatusChanged(oldObj, newObj) {
+		return
+	}
+	if gateway, ok := newObj.(*gatewayv1.Gateway); ok {
+		h.trackGatewaySecrets(gateway)
+	}
+	h.updateFunc(oldObj, newObj)
+}
+
+func (h handlers) gatewayAPIDelete(obj interface{}) {
+	if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+		obj = tombstone.Obj
+	}
+	if gateway, ok := obj.(*gatewayv1.Gateway); ok {
+		h.context.ingressSecretsMap.Erase(gatewaySecretsKey(utils.GetResourceKey(gateway.Namespace, gateway.Name)))
+	}
+	h.deleteFunc(obj)
+}
+
+// trackGatewaySecrets converts the certificates the HTTPS listeners of the Gateway reference, so that they can be installed on App Gateway.
+func (h handlers) trackGatewaySecrets(gateway *gatewayv1.Gateway) {
+	if _, exists := h.context.namespaces[gateway.Namespace]; len(h.context.namespaces) > 0 && !exists {
+		return
+	}
+
+	gatewayKey := gatewaySecretsKey(utils.GetResourceKey(gateway.Namespace, gateway.Name))
+	h.context.ingressSecretsMap.Clear(gatewayKey)
+	for _, listener := range gateway.Spec.Listeners {
+		if listener.TLS == nil {
+			continue
+		}
+		for _, ref := range listener.TLS.CertificateRefs {
+			// Only Secrets in the namespace of the Gateway are supported.
+			if ref.Namespace != nil && string(*ref.Namespace) != gateway.Namespace {
+				continue
+			}
+			secKey := utils.GetResourceKey(gateway.Namespace, string(ref.Name))
+			if secret, exists, err := h.context.Caches.Secret.GetByKey(secKey); exists && err == nil {
+				if !h.context.ingressSecretsMap.ContainsValue(secKey) {
+					if err := h.context.CertificateSecretStore.ConvertSecret(secKey, secret.(*v1.Secret)); err != nil {
+						klog.Error(err.Error())
+					}
+				}
+			}
+			h.context.ingressSecretsMap.Insert(gatewayKey, secKey)
+		}
+	}
+}
+
+func onlyGatewayAPIStatusChanged(oldObj, newObj interface{}) bool {
+	oldMeta, oldOk := oldObj.(metav1.Object)
+	newMeta, newOk := newObj.(metav1.Object)
+	if !oldOk || !newOk {
+		return false
+	}
+
+	return maps.Equal(oldMeta.GetAnnotations(), newMeta.GetAnnotations()) &&
+		maps.Equal(oldMeta.GetLabels(), newMeta.GetLabels()) &&
+		reflect.DeepEqual(oldMeta.GetDeletionTimestamp(), newMeta.GetDeletionTimestamp()) &&
+		reflect.DeepEqual(getSpec(oldObj), getSpec(newObj))
+}
+
+// getSpec returns the spec of a Gateway API object.
+func getSpec(obj interface{}) interface{} {
+	return reflect.ValueOf(obj).Elem().FieldByName("Spec").Interface()
+}
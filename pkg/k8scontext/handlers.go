@@ -16,7 +16,7 @@ func (h handlers) addFunc(obj interface{}) {
 	if _, exists := namespacesToIgnore[ns]; exists {
 		return
 	}
-	if _, exists := h.context.namespaces[ns]; len(h.context.namespaces) > 0 && !exists {
+	if _, exists := h.context.namespaces[ns]; ns != "" && len(h.context.namespaces) > 0 && !exists {
 		return
 	}
 
@@ -32,7 +32,7 @@ func (h handlers) updateFunc(oldObj, newObj interface{}) {
 	if _, exists := namespacesToIgnore[ns]; exists {
 		return
 	}
-	if _, exists := h.context.namespaces[ns]; len(h.context.namespaces) > 0 && !exists {
+	if _, exists := h.context.namespaces[ns]; ns != "" && len(h.context.namespaces) > 0 && !exists {
 		return
 	}
 
@@ -51,7 +51,7 @@ func (h handlers) deleteFunc(obj interface{}) {
 	if _, exists := namespacesToIgnore[ns]; exists {
 		return
 	}
-	if _, exists := h.context.namespaces[ns]; len(h.context.namespaces) > 0 && !exists {
+	if _, exists := h.context.namespaces[ns]; ns != "" && len(h.context.namespaces) > 0 && !exists {
 		return
 	}
 
@@ -62,6 +62,7 @@ func (h handlers) deleteFunc(obj interface{}) {
 	h.context.MetricStore.IncK8sAPIEventCounter()
 }
 
+// getNamespace returns the namespace of the object; Cluster-scoped objects, e.g. IngressClasses, have none and are not filtered by the watched namespaces.
 func getNamespace(obj interface{}) string {
 	return reflect.ValueOf(obj).Elem().FieldByName("ObjectMeta").FieldByName("Namespace").String()
 }
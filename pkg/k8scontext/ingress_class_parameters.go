@@ -0,0 +1,156 @@
+// -------------------------------------------------------------------------------------------
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License. See License.txt in the project root for license information.
+// --------------------------------------------------------------------------------------------
+
+package k8scontext
+
+import (
+	"context"
+
+	networking "k8s.io/api/networking/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/klog/v2"
+
+	"github.com/Azure/application-gateway-kubernetes-ingress/pkg/annotations"
+	classparametersv1beta1 "github.com/Azure/application-gateway-kubernetes-ingress/pkg/apis/azureapplicationgatewayclassparameters/v1beta1"
+	"github.com/Azure/application-gateway-kubernetes-ingress/pkg/controllererrors"
+	"github.com/Azure/application-gateway-kubernetes-ingress/pkg/crd_client/agic_crd_client/clientset/versioned"
+)
+
+// ingressClassParametersKind is the kind an IngressClass references in its spec.parameters to configure AGIC.
+const ingressClassParametersKind = "AzureApplicationGatewayClassParameters"
+
+// GetIngressClassParameters returns the AzureApplicationGatewayClassParameters referenced by the IngressClass AGIC serves;
+// It returns nil when AGIC does not use the IngressClass resource or the IngressClass references no parameters.
+func (c *Context) GetIngressClassParameters() *classparametersv1beta1.AzureApplicationGatewayClassParameters {
+	if !c.ingressClassResourceEnabled || c.Caches.AzureApplicationGatewayClassParameters == nil {
+		return nil
+	}
+
+	ingressClass := c.getIngressClassResourceFromCache(c.ingressClassResourceName)
+	if ingressClass == nil {
+		return nil
+	}
+
+	parametersName := ingressClassParametersName(ingressClass)
+	if parametersName == "" {
+		return nil
+	}
+
+	parameters, exists, err := c.Caches.AzureApplicationGatewayClassParameters.GetByKey(parametersName)
+	if err != nil {
+		e := controllererrors.NewErrorWithInnerErrorf(
+			controllererrors.ErrorFetchingIngressClassParameters,
+			err,
+			"Error fetching the parameters %s of IngressClass %s from store", parametersName, ingressClass.Name,
+		)
+		klog.Error(e.Error())
+		c.MetricStore.IncErrorCount(e.Code)
+		return nil
+	}
+	if !exists {
+		klog.Warningf("IngressClass %s references %s %s, which does not exist", ingressClass.Name, ingressClassParametersKind, parametersName)
+		return nil
+	}
+
+	return parameters.(*classparametersv1beta1.AzureApplicationGatewayClassParameters)
+}
+
+// GetIngressClassGateway fetches the resource ID of the Application Gateway the parameters of the IngressClass set, before a Context exists.
+// It returns an empty resource ID when the IngressClass does not exist or sets no Application Gateway.
+func GetIngressClassGateway(kubeClient kubernetes.Interface, crdClient versioned.Interface, ingressClassName string) (string, error) {
+	ingressClass, err := kubeClient.NetworkingV1().IngressClasses().Get(context.TODO(), ingressClassName, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return "", nil
+	}
+	if err != nil {
+		return "", controllererrors.NewErrorWithInnerErrorf(
+			controllererrors.ErrorFetchingIngressClassParameters,
+			err,
+			"Unable to get IngressClass %s", ingressClassName,
+		)
+	}
+
+	parametersName := ingressClassParametersName(ingressClass)
+	if parametersName == "" {
+		return "", nil
+	}
+
+	parameters, err := crdClient.AzureapplicationgatewayclassparametersV1beta1().AzureApplicationGatewayClassParameters().Get(context.TODO(), parametersName, metav1.GetOptions{})
+	if err != nil {
+		return "", controllererrors.NewErrorWithInnerErrorf(
+			controllererrors.ErrorFetchingIngressClassParameters,
+			err,
+			"Unable to get the parameters %s of IngressClass %s", parametersName, ingressClassName,
+		)
+	}
+
+	return parameters.Spec.ApplicationGatewayID, nil
+}
+
+// ingressClassParametersName returns the name of the AzureApplicationGatewayClassParameters the IngressClass references, if any.
+func ingressClassParametersName(ingressClass *networking.IngressClass) string {
+	parameters := ingressClass.Spec.Parameters
+	if parameters == nil || parameters.APIGroup == nil || *parameters.APIGroup != classparametersv1beta1.SchemeGroupVersion.Group || parameters.Kind != ingressClassParametersKind {
+		return ""
+	}
+
+	if parameters.Scope != nil && *parameters.Scope != networking.IngressClassParametersReferenceScopeCluster {
+		klog.Warningf("IngressClass %s references %s %s in the %s scope; %s is cluster-scoped", ingressClass.Name, ingressClassParametersKind, parameters.Name, *parameters.Scope, ingressClassParametersKind)
+		return ""
+	}
+
+	return parameters.Name
+}
+
+// withClassDefaults returns the Ingress with the annotations the parameters of its IngressClass default and it does not set.
+// The Ingress in the cache is left untouched; A copy is returned when there are annotations to default.
+func withClassDefaults(ingress *networking.Ingress, parameters *classparametersv1beta1.AzureApplicationGatewayClassParameters) *networking.Ingress {
+	defaulted := ingress
+	for key, value := range annotationDefaults(parameters.Spec) {
+		if _, exists := ingress.Annotations[key]; exists {
+			continue
+		}
+
+		if defaulted == ingress {
+			defaulted = ingress.DeepCopy()
+			if defaulted.Annotations == nil {
+				defaulted.Annotations = map[string]string{}
+			}
+		}
+		defaulted.Annotations[key] = value
+	}
+	return defaulted
+}
+
+// annotationDefaults returns the annotations the parameters default; The dedicated fields take precedence over annotationDefaults.
+func annotationDefaults(spec classparametersv1beta1.AzureApplicationGatewayClassParametersSpec) map[string]string {
+	defaults := make(map[string]string, len(spec.AnnotationDefaults)+3)
+	for key, value := range spec.AnnotationDefaults {
+		// The IngressClass of an Ingress and the status AGIC reports on it are not defaults.
+		if key == annotations.IngressClassKey || key == annotations.IngressStatusKey {
+			continue
+		}
+		defaults[key] = value
+	}
+
+	switch spec.DefaultFrontend {
+	case classparametersv1beta1.PrivateFrontend:
+		defaults[annotations.UsePrivateIPKey] = "true"
+	case classparametersv1beta1.PublicFrontend:
+		defaults[annotations.UsePrivateIPKey] = "false"
+	}
+
+	if spec.DefaultWAFPolicy != "" {
+		defaults[annotations.FirewallPolicy] = spec.DefaultWAFPolicy
+	}
+
+	if spec.DefaultSSLProfile != "" {
+		defaults[annotations.AppGwSslProfile] = spec.DefaultSSLProfile
+	}
+
+	return defaults
+}
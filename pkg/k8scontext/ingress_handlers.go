@@ -1,6 +1,7 @@
 package k8scontext
 
 import (
+	"maps"
 	"reflect"
 
 	v1 "k8s.io/api/core/v1"
@@ -8,6 +9,7 @@ import (
 	"k8s.io/client-go/tools/cache"
 	"k8s.io/klog/v2"
 
+	"github.com/Azure/application-gateway-kubernetes-ingress/pkg/annotations"
 	"github.com/Azure/application-gateway-kubernetes-ingress/pkg/events"
 	"github.com/Azure/application-gateway-kubernetes-ingress/pkg/k8scontext/convert"
 	"github.com/Azure/application-gateway-kubernetes-ingress/pkg/utils"
@@ -103,6 +105,10 @@ func (h handlers) ingressUpdate(oldObj, newObj interface{}) {
 	if !h.context.IsIngressClass(ing) && !h.context.IsIngressClass(oldIng) {
 		return
 	}
+	// AGIC reports the status of the Ingress in an annotation; Updating it must not trigger another event loop.
+	if onlyStatusAnnotationChanged(oldIng, ing) {
+		return
+	}
 	if ing.Spec.TLS != nil && len(ing.Spec.TLS) > 0 {
 		ingKey := utils.GetResourceKey(ing.Namespace, ing.Name)
 		h.context.ingressSecretsMap.Clear(ingKey)
@@ -131,3 +137,19 @@ func (h handlers) ingressUpdate(oldObj, newObj interface{}) {
 	}
 	h.context.MetricStore.IncK8sAPIEventCounter()
 }
+
+func onlyStatusAnnotationChanged(oldIng *networking.Ingress, newIng *networking.Ingress) bool {
+	if oldIng == nil || newIng == nil || oldIng.Annotations[annotations.IngressStatusKey] == newIng.Annotations[annotations.IngressStatusKey] {
+		return false
+	}
+
+	oldAnnotations := maps.Clone(oldIng.Annotations)
+	newAnnotations := maps.Clone(newIng.Annotations)
+	delete(oldAnnotations, annotations.IngressStatusKey)
+	delete(newAnnotations, annotations.IngressStatusKey)
+
+	return maps.Equal(oldAnnotations, newAnnotations) &&
+		maps.Equal(oldIng.Labels, newIng.Labels) &&
+		reflect.DeepEqual(oldIng.Spec, newIng.Spec) &&
+		reflect.DeepEqual(oldIng.Status, newIng.Status)
+}
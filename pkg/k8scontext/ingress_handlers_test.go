@@ -15,6 +15,7 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes"
 	testclient "k8s.io/client-go/kubernetes/fake"
+	gateway_fake "sigs.k8s.io/gateway-api/pkg/client/clientset/versioned/fake"
 
 	"github.com/Azure/application-gateway-kubernetes-ingress/pkg/annotations"
 	"github.com/Azure/application-gateway-kubernetes-ingress/pkg/crd_client/agic_crd_client/clientset/versioned/fake"
@@ -62,7 +63,7 @@ var _ = ginkgo.Describe("K8scontext Ingress Cache Handlers", func() {
 		Expect(err).To(BeNil())
 
 		IsNetworkingV1PackageSupported = true
-		ctx = NewContext(k8sClient, fake.NewSimpleClientset(), multiClusterFake.NewSimpleClientset(), istioFake.NewSimpleClientset(), []string{"ns"}, 1000*time.Second, metricstore.NewFakeMetricStore(), environment.GetFakeEnv())
+		ctx = NewContext(k8sClient, fake.NewSimpleClientset(), multiClusterFake.NewSimpleClientset(), istioFake.NewSimpleClientset(), gateway_fake.NewSimpleClientset(), []string{"ns"}, 1000*time.Second, metricstore.NewFakeMetricStore(), environment.GetFakeEnv())
 		h = handlers{
 			context: ctx,
 		}
@@ -93,6 +94,19 @@ var _ = ginkgo.Describe("K8scontext Ingress Cache Handlers", func() {
 			Expect(len(h.context.Work)).To(Equal(0))
 		})
 
+		ginkgo.It("should not add events when only the status annotation of the ingress changed", func() {
+			ing := fixtures.GetIngress()
+			ing.Namespace = "ns"
+			updated := ing.DeepCopy()
+			updated.Annotations[annotations.IngressStatusKey] = `{"appliedRules":[],"prunedRules":[]}`
+			h.ingressUpdate(ing, updated)
+			Expect(len(h.context.Work)).To(Equal(0))
+
+			updated.Annotations[annotations.HealthProbePathKey] = "/healthz"
+			h.ingressUpdate(ing, updated)
+			Expect(len(h.context.Work)).To(Equal(1))
+		})
+
 		ginkgo.It("should update the ingressSecretsMap even when secret is malformed", func() {
 			namespace := "ns"
 			data := map[string][]byte{
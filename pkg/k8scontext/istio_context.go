@@ -32,3 +32,16 @@ func (c *Context) ListIstioVirtualServices() []*v1alpha3.VirtualService {
 	}
 	return virtualServices
 }
+
+// ListIstioDestinationRules returns a list of discovered Istio Destination Rules
+func (c *Context) ListIstioDestinationRules() []*v1alpha3.DestinationRule {
+	var destinationRules []*v1alpha3.DestinationRule
+	for _, destinationRule := range c.Caches.IstioDestinationRule.List() {
+		rule := destinationRule.(*v1alpha3.DestinationRule)
+		if _, exists := c.namespaces[rule.Namespace]; len(c.namespaces) > 0 && !exists {
+			continue
+		}
+		destinationRules = append(destinationRules, rule)
+	}
+	return destinationRules
+}
@@ -0,0 +1,67 @@
+// -------------------------------------------------------------------------------------------
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License. See License.txt in the project root for license information.
+// --------------------------------------------------------------------------------------------
+
+package k8scontext
+
+import (
+	"github.com/knative/pkg/apis/istio/v1alpha3"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/klog/v2"
+
+	"github.com/Azure/application-gateway-kubernetes-ingress/pkg/utils"
+)
+
+// istioGatewaySecretsKey keys the certificates of an Istio Gateway in the ingressSecretsMap, apart from those of other resources with the same name.
+type istioGatewaySecretsKey string
+
+// Istio Gateway handlers
+func (h handlers) istioGatewayAdd(obj interface{}) {
+	if gateway, ok := obj.(*v1alpha3.Gateway); ok {
+		h.trackIstioGatewaySecrets(gateway)
+	}
+	h.addFunc(obj)
+}
+
+func (h handlers) istioGatewayUpdate(oldObj, newObj interface{}) {
+	if gateway, ok := newObj.(*v1alpha3.Gateway); ok {
+		h.trackIstioGatewaySecrets(gateway)
+	}
+	h.updateFunc(oldObj, newObj)
+}
+
+func (h handlers) istioGatewayDelete(obj interface{}) {
+	if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+		obj = tombstone.Obj
+	}
+	if gateway, ok := obj.(*v1alpha3.Gateway); ok {
+		h.context.ingressSecretsMap.Erase(istioGatewaySecretsKey(utils.GetResourceKey(gateway.Namespace, gateway.Name)))
+	}
+	h.deleteFunc(obj)
+}
+
+// trackIstioGatewaySecrets converts the certificates the HTTPS servers of the Gateway reference through credentialName, so that they can be installed on App Gateway.
+func (h handlers) trackIstioGatewaySecrets(gateway *v1alpha3.Gateway) {
+	if _, exists := h.context.namespaces[gateway.Namespace]; len(h.context.namespaces) > 0 && !exists {
+		return
+	}
+
+	gatewayKey := istioGatewaySecretsKey(utils.GetResourceKey(gateway.Namespace, gateway.Name))
+	h.context.ingressSecretsMap.Clear(gatewayKey)
+	for _, server := range gateway.Spec.Servers {
+		if server.TLS == nil || server.TLS.CredentialName == "" {
+			continue
+		}
+		secKey := utils.GetResourceKey(gateway.Namespace, server.TLS.CredentialName)
+		if secret, exists, err := h.context.Caches.Secret.GetByKey(secKey); exists && err == nil {
+			if !h.context.ingressSecretsMap.ContainsValue(secKey) {
+				if err := h.context.CertificateSecretStore.ConvertSecret(secKey, secret.(*v1.Secret)); err != nil {
+					klog.Error(err.Error())
+				}
+			}
+		}
+		h.context.ingressSecretsMap.Insert(gatewayKey, secKey)
+	}
+}
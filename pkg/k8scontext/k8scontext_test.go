@@ -19,8 +19,10 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes"
 	testclient "k8s.io/client-go/kubernetes/fake"
+	gateway_fake "sigs.k8s.io/gateway-api/pkg/client/clientset/versioned/fake"
 
 	"github.com/Azure/application-gateway-kubernetes-ingress/pkg/annotations"
+	classparametersv1beta1 "github.com/Azure/application-gateway-kubernetes-ingress/pkg/apis/azureapplicationgatewayclassparameters/v1beta1"
 	agiccrd "github.com/Azure/application-gateway-kubernetes-ingress/pkg/crd_client/agic_crd_client/clientset/versioned"
 	agiccrdFake "github.com/Azure/application-gateway-kubernetes-ingress/pkg/crd_client/agic_crd_client/clientset/versioned/fake"
 	mcscrd "github.com/Azure/application-gateway-kubernetes-ingress/pkg/crd_client/azure_multicluster_crd_client/clientset/versioned"
@@ -28,6 +30,7 @@ import (
 	istiocrd "github.com/Azure/application-gateway-kubernetes-ingress/pkg/crd_client/istio_crd_client/clientset/versioned"
 	istiofake "github.com/Azure/application-gateway-kubernetes-ingress/pkg/crd_client/istio_crd_client/clientset/versioned/fake"
 	"github.com/Azure/application-gateway-kubernetes-ingress/pkg/environment"
+	"github.com/Azure/application-gateway-kubernetes-ingress/pkg/k8scontext/convert"
 	"github.com/Azure/application-gateway-kubernetes-ingress/pkg/metricstore"
 	"github.com/Azure/application-gateway-kubernetes-ingress/pkg/tests"
 	"github.com/Azure/go-autorest/autorest/to"
@@ -99,7 +102,7 @@ var _ = ginkgo.Describe("K8scontext", func() {
 
 		// Create a `k8scontext` to start listening to ingress resources.
 		IsNetworkingV1PackageSupported = true
-		ctxt = NewContext(k8sClient, crdClient, multiClusterCrdClient, istioCrdClient, []string{ingressNS}, 1000*time.Second, metricstore.NewFakeMetricStore(), environment.GetFakeEnv())
+		ctxt = NewContext(k8sClient, crdClient, multiClusterCrdClient, istioCrdClient, gateway_fake.NewSimpleClientset(), []string{ingressNS}, 1000*time.Second, metricstore.NewFakeMetricStore(), environment.GetFakeEnv())
 
 		Expect(ctxt).ShouldNot(BeNil(), "Unable to create `k8scontext`")
 	})
@@ -356,9 +359,10 @@ var _ = ginkgo.Describe("K8scontext", func() {
 			endpoints := tests.NewEndpointsFixture()
 			endpoints.Namespace = ingressNS
 
-			// create a POD with labels
-			_, err := k8sClient.CoreV1().Endpoints(ingressNS).Create(context.TODO(), endpoints, metav1.CreateOptions{})
-			Expect(err).ToNot(HaveOccurred(), "Unable to create endpoints resource due to: %v", err)
+			// create the endpoint slice mirrored from the endpoints
+			endpointSlice := convert.ToEndpointSlices(endpoints)[0]
+			_, err := k8sClient.DiscoveryV1().EndpointSlices(ingressNS).Create(context.TODO(), endpointSlice, metav1.CreateOptions{})
+			Expect(err).ToNot(HaveOccurred(), "Unable to create endpoint slice resource due to: %v", err)
 
 			// create a service with label
 			servicePort := tests.NewServicePortsFixture()
@@ -368,13 +372,13 @@ var _ = ginkgo.Describe("K8scontext", func() {
 			Expect(err).ToNot(HaveOccurred(), "Unable to create service resource due to: %v", err)
 
 			// wait for sync
-			waitContextSync(ctxt, ingress, service, endpoints)
+			waitContextSync(ctxt, ingress, service, endpointSlice)
 
 			// check that ctxt synced the service
 			Expect(len(ctxt.ListServices())).To(Equal(1), "Context was not able to sync in time")
 
 			// run IsPodReferencedByAnyIngress: true
-			Expect(ctxt.IsEndpointReferencedByAnyIngress(endpoints)).To(BeTrue(), "Expected is endpoints is selected by the service and ingress.")
+			Expect(ctxt.IsEndpointSliceReferencedByAnyIngress(endpointSlice)).To(BeTrue(), "Expected is endpoints is selected by the service and ingress.")
 		})
 
 		ginkgo.It("should be able to skip unrelated endpoints", func() {
@@ -386,9 +390,10 @@ var _ = ginkgo.Describe("K8scontext", func() {
 			endpoints.Name = "random"
 			endpoints.Namespace = ingressNS
 
-			// create a POD with labels
-			_, err := k8sClient.CoreV1().Endpoints(ingressNS).Create(context.TODO(), endpoints, metav1.CreateOptions{})
-			Expect(err).ToNot(HaveOccurred(), "Unable to create endpoints resource due to: %v", err)
+			// create the endpoint slice mirrored from the endpoints
+			endpointSlice := convert.ToEndpointSlices(endpoints)[0]
+			_, err := k8sClient.DiscoveryV1().EndpointSlices(ingressNS).Create(context.TODO(), endpointSlice, metav1.CreateOptions{})
+			Expect(err).ToNot(HaveOccurred(), "Unable to create endpoint slice resource due to: %v", err)
 
 			// create a service with label
 			servicePort := tests.NewServicePortsFixture()
@@ -398,13 +403,13 @@ var _ = ginkgo.Describe("K8scontext", func() {
 			Expect(err).ToNot(HaveOccurred(), "Unable to create service resource due to: %v", err)
 
 			// wait for sync
-			waitContextSync(ctxt, ingress, service, endpoints)
+			waitContextSync(ctxt, ingress, service, endpointSlice)
 
 			// check that ctxt synced the service
 			Expect(len(ctxt.ListServices())).To(Equal(1), "Context was not able to sync in time")
 
 			// run IsPodReferencedByAnyIngress: true
-			Expect(ctxt.IsEndpointReferencedByAnyIngress(endpoints)).To(BeFalse(), "Expected is endpoints is not selected by the service and ingress.")
+			Expect(ctxt.IsEndpointSliceReferencedByAnyIngress(endpointSlice)).To(BeFalse(), "Expected is endpoints is not selected by the service and ingress.")
 		})
 	})
 
@@ -502,6 +507,21 @@ var _ = ginkgo.Describe("K8scontext", func() {
 			Expect(ctxt.ingressClassControllerName).To(Equal(environment.DefaultIngressClassController))
 			Expect(actual).To(Equal(false))
 		})
+
+		ginkgo.It("matches the annotation with the IngressClass of the gateway when AGIC manages several", func() {
+			env := environment.GetFakeEnv().ForGateway(environment.Gateway{
+				IngressClass: "internal",
+				ResourceID:   "/subscriptions/sub/resourceGroups/rg/providers/Microsoft.Network/applicationGateways/internal",
+			})
+			env.Gateways = "internal=" + env.AppGwResourceID
+			gatewayCtxt := NewContext(k8sClient, crdClient, multiClusterCrdClient, istioCrdClient, gateway_fake.NewSimpleClientset(), []string{ingressNS}, 1000*time.Second, metricstore.NewFakeMetricStore(), env)
+
+			Expect(gatewayCtxt.IsIngressClass(ingress)).To(BeFalse())
+
+			ingress.Annotations[annotations.IngressClassKey] = "internal"
+			Expect(gatewayCtxt.IsIngressClass(ingress)).To(BeTrue())
+			Expect(ctxt.IsIngressClass(ingress)).To(BeFalse())
+		})
 	})
 
 	ginkgo.Context("Check Ingress Class Resource is used correctly for filtering ingress", func() {
@@ -638,6 +658,49 @@ var _ = ginkgo.Describe("K8scontext", func() {
 			Expect(testIngresses[0]).To(Equal(ingress), "Expected to retrieve the same ingress that we inserted, but it seems we found the following ingress: %v", testIngresses[0])
 			Expect(testIngresses[1]).To(Equal(&ingressWithoutIngressClass), "Expected to retrieve the same ingress that we inserted, but it seems we found the following ingress: %v", testIngresses[1])
 		})
+
+		ginkgo.It("Should default the annotations of the ingresses to the parameters of the ingress class", func() {
+			// start the informers. This will sync the cache with the latest ingress.
+			runErr := ctxt.Run(stopChannel, true, environment.GetFakeEnv())
+			Expect(runErr).ToNot(HaveOccurred())
+
+			ingressClass := tests.GetIngressClass()
+			ingressClass.Spec.Parameters = &networking.IngressClassParametersReference{
+				APIGroup: to.StringPtr(classparametersv1beta1.SchemeGroupVersion.Group),
+				Kind:     ingressClassParametersKind,
+				Name:     "internal",
+			}
+			Expect(ctxt.Caches.IngressClass.Update(ingressClass)).To(Succeed())
+			Expect(ctxt.Caches.AzureApplicationGatewayClassParameters.Add(&classparametersv1beta1.AzureApplicationGatewayClassParameters{
+				ObjectMeta: metav1.ObjectMeta{Name: "internal"},
+				Spec: classparametersv1beta1.AzureApplicationGatewayClassParametersSpec{
+					DefaultFrontend:   classparametersv1beta1.PrivateFrontend,
+					DefaultSSLProfile: "internal-ssl-profile",
+					AnnotationDefaults: map[string]string{
+						annotations.SslRedirectKey:   "true",
+						annotations.UsePrivateIPKey:  "false",
+						annotations.IngressStatusKey: "ignored",
+					},
+				},
+			})).To(Succeed())
+
+			cachedIngress := ingress.DeepCopy()
+			cachedIngress.Annotations = map[string]string{
+				annotations.SslRedirectKey: "false",
+			}
+			Expect(ctxt.Caches.Ingress.Update(cachedIngress)).To(Succeed())
+
+			testIngresses := ctxt.ListHTTPIngresses()
+			Expect(len(testIngresses)).To(Equal(1), "Expected to have a single ingress in the k8scontext but found: %d ingresses", len(testIngresses))
+			Expect(testIngresses[0].Annotations).To(Equal(map[string]string{
+				annotations.SslRedirectKey:  "false",
+				annotations.UsePrivateIPKey: "true",
+				annotations.AppGwSslProfile: "internal-ssl-profile",
+			}))
+
+			// the ingress in the cache is left untouched
+			Expect(cachedIngress.Annotations).To(HaveLen(1))
+		})
 	})
 
 	ginkgo.Context("Checking when server doesn't support v1/ingress", func() {
@@ -645,7 +708,7 @@ var _ = ginkgo.Describe("K8scontext", func() {
 		ginkgo.BeforeEach(func() {
 			// Create a `k8scontext` to start listening to ingress resources.
 			IsNetworkingV1PackageSupported = false
-			ctxt = NewContext(k8sClient, crdClient, multiClusterCrdClient, istioCrdClient, []string{ingressNS}, 1000*time.Second, metricstore.NewFakeMetricStore(), environment.GetFakeEnv())
+			ctxt = NewContext(k8sClient, crdClient, multiClusterCrdClient, istioCrdClient, gateway_fake.NewSimpleClientset(), []string{ingressNS}, 1000*time.Second, metricstore.NewFakeMetricStore(), environment.GetFakeEnv())
 
 			Expect(ctxt).ShouldNot(BeNil(), "Unable to create `k8scontext`")
 		})
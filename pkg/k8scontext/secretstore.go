@@ -6,10 +6,7 @@
 package k8scontext
 
 import (
-	"bytes"
 	"context"
-	"os"
-	"os/exec"
 	"sync"
 
 	v1 "k8s.io/api/core/v1"
@@ -18,21 +15,28 @@ import (
 	"k8s.io/client-go/tools/cache"
 
 	"github.com/Azure/application-gateway-kubernetes-ingress/pkg/controllererrors"
+	"github.com/Azure/application-gateway-kubernetes-ingress/pkg/pfx"
 	"github.com/Azure/application-gateway-kubernetes-ingress/pkg/utils"
 )
 
 // SecretsKeeper is the interface definition for secret store
 type SecretsKeeper interface {
 	GetPfxCertificate(secretKey string) []byte
+	GetPfxPassword(secretKey string) string
 	ConvertSecret(secretKey string, secret *v1.Secret) error
 	delete(secretKey string)
 }
 
 // SecretsStore maintains a cache of the deployment secrets.
+// Secrets are parsed concurrently; Each has its own random PFX password.
 type SecretsStore struct {
-	conversionSync sync.Mutex
-	Client         kubernetes.Interface
-	Cache          cache.ThreadSafeStore
+	Client    kubernetes.Interface
+	Cache     cache.ThreadSafeStore
+	passwords sync.Map
+
+	// lock is held from reading the password of a secret until its PFX is cached, and while deleting both,
+	// so that a deletion does not leave behind the PFX of a conversion still in progress without its password.
+	lock sync.Mutex
 }
 
 // NewSecretStore creates a new SecretsKeeper object
@@ -57,6 +61,14 @@ func (s *SecretsStore) GetPfxCertificate(secretKey string) []byte {
 	return nil
 }
 
+// GetPfxPassword returns the password the certificate of the given secret key is encrypted with.
+func (s *SecretsStore) GetPfxPassword(secretKey string) string {
+	if password, exists := s.passwords.Load(secretKey); exists {
+		return password.(string)
+	}
+	return ""
+}
+
 func (s *SecretsStore) GetFromCluster(secretKey string) ([]byte, error) {
 	secretNamespace, secretName, err := utils.ParseNamespacedName(secretKey)
 	if err != nil {
@@ -78,17 +90,15 @@ func (s *SecretsStore) GetFromCluster(secretKey string) ([]byte, error) {
 }
 
 func (s *SecretsStore) delete(secretKey string) {
-	s.conversionSync.Lock()
-	defer s.conversionSync.Unlock()
-
+	s.lock.Lock()
+	defer s.lock.Unlock()
 	s.Cache.Delete(secretKey)
+	s.passwords.Delete(secretKey)
 }
 
 // ConvertSecret converts a secret to a PKCS12.
+// The PFX carries the certificate of the private key followed by its chain, and is encrypted with the password of the secret.
 func (s *SecretsStore) ConvertSecret(secretKey string, secret *v1.Secret) error {
-	s.conversionSync.Lock()
-	defer s.conversionSync.Unlock()
-
 	// check if this is a secret with the correct type
 	if secret.Type != v1.SecretTypeTLS {
 		return controllererrors.NewErrorf(
@@ -104,59 +114,54 @@ func (s *SecretsStore) ConvertSecret(secretKey string, secret *v1.Secret) error
 		)
 	}
 
-	tempfileCert, err := os.CreateTemp("", "appgw-ingress-cert")
+	certificates, err := pfx.ParseCertificates(secret.Data[v1.TLSCertKey])
 	if err != nil {
 		return controllererrors.NewErrorWithInnerErrorf(
-			controllererrors.ErrorCreatingFile,
+			controllererrors.ErrorDecodingCertificate,
 			err,
-			"unable to create temporary file for certificate conversion",
+			"unable to decode secret [%v].tls.crt", secretKey,
 		)
 	}
-	defer os.Remove(tempfileCert.Name())
 
-	tempfileKey, err := os.CreateTemp("", "appgw-ingress-key")
+	privateKey, err := pfx.ParsePrivateKey(secret.Data[v1.TLSPrivateKeyKey])
 	if err != nil {
 		return controllererrors.NewErrorWithInnerErrorf(
-			controllererrors.ErrorCreatingFile,
+			controllererrors.ErrorDecodingPrivateKey,
 			err,
-			"unable to create temporary file for certificate conversion",
+			"unable to decode secret [%v].tls.key", secretKey,
 		)
 	}
-	defer os.Remove(tempfileKey.Name())
 
-	if err := writeFileDecode(secret.Data["tls.crt"], tempfileCert); err != nil {
+	chain, err := pfx.OrderChain(privateKey, certificates)
+	if err != nil {
 		return controllererrors.NewErrorWithInnerErrorf(
-			controllererrors.ErrorWritingToFile,
+			controllererrors.ErrorCertificateKeyMismatch,
 			err,
-			"unable to write secret [%v].tls.crt to temporary file", secretKey,
+			"secret [%v].tls.key does not match the certificates of tls.crt", secretKey,
 		)
 	}
 
-	if err := writeFileDecode(secret.Data["tls.key"], tempfileKey); err != nil {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	password, err := s.getOrCreatePassword(secretKey)
+	if err != nil {
 		return controllererrors.NewErrorWithInnerErrorf(
-			controllererrors.ErrorWritingToFile,
+			controllererrors.ErrorEncodingPfx,
 			err,
-			"unable to write secret [%v].tls.key to temporary file", secretKey,
+			"unable to generate a password for secret [%v]", secretKey,
 		)
 	}
 
-	// both cert and key are in temp file now, call openssl
-	var cout, cerr bytes.Buffer
-	cmd := exec.Command("openssl", "pkcs12", "-export", "-in", tempfileCert.Name(), "-inkey", tempfileKey.Name(), "-password", "pass:msazure")
-	cmd.Stderr = &cerr
-	cmd.Stdout = &cout
-
-	// if openssl exited with an error or the output is empty, report error
-	if err := cmd.Run(); err != nil || len(cout.Bytes()) == 0 {
+	pfxCert, err := pfx.Encode(privateKey, chain, password)
+	if err != nil {
 		return controllererrors.NewErrorWithInnerErrorf(
-			controllererrors.ErrorExportingWithOpenSSL,
+			controllererrors.ErrorEncodingPfx,
 			err,
-			"unable to export using openssl, error=[%v], stderr=[%v]", err, cerr.String(),
+			"unable to encode secret [%v] as PFX", secretKey,
 		)
 	}
 
-	pfxCert := cout.Bytes()
-
 	// TODO i'm not sure if comparison against existing certificate can help
 	// us optimize by eliminating some events
 	_, exists := s.Cache.Get(secretKey)
@@ -169,12 +174,17 @@ func (s *SecretsStore) ConvertSecret(secretKey string, secret *v1.Secret) error
 	return nil
 }
 
-func writeFileDecode(data []byte, fileHandle *os.File) error {
-	if _, err := fileHandle.Write(data); err != nil {
-		return err
+// getOrCreatePassword returns the password of the PFX of the secret; The caller must hold lock.
+// A secret keeps its password across conversions, so that a PFX and the password read along with it always match.
+func (s *SecretsStore) getOrCreatePassword(secretKey string) (string, error) {
+	if password, exists := s.passwords.Load(secretKey); exists {
+		return password.(string), nil
 	}
-	if err := fileHandle.Close(); err != nil {
-		return err
+
+	password, err := pfx.NewPassword()
+	if err != nil {
+		return "", err
 	}
-	return nil
+	s.passwords.Store(secretKey, password)
+	return password, nil
 }
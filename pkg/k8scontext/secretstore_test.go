@@ -30,7 +30,11 @@ var _ = ginkgo.Describe("Testing K8sContext.SecretStore", func() {
 		ginkgo.Entry("invalid data", &v1.Secret{Type: v1.SecretTypeTLS, Data: map[string][]byte{
 			v1.TLSCertKey:       []byte("X"),
 			v1.TLSPrivateKeyKey: []byte("X"),
-		}}, controllererrors.ErrorExportingWithOpenSSL),
+		}}, controllererrors.ErrorDecodingCertificate),
+		ginkgo.Entry("certificate without its key", &v1.Secret{Type: v1.SecretTypeTLS, Data: map[string][]byte{
+			v1.TLSCertKey:       tests.NewSecretTestFixture().Data[v1.TLSCertKey],
+			v1.TLSPrivateKeyKey: []byte("X"),
+		}}, controllererrors.ErrorDecodingPrivateKey),
 	)
 
 	ginkgo.When("certificate gets stored", func() {
@@ -40,6 +44,17 @@ var _ = ginkgo.Describe("Testing K8sContext.SecretStore", func() {
 			actual := secretsStore.GetPfxCertificate("someKey")
 			Expect(len(actual)).To(BeNumerically(">", 0))
 		})
+
+		ginkgo.It("should keep its password across conversions", func() {
+			password := secretsStore.GetPfxPassword("someKey")
+			Expect(password).ToNot(BeEmpty())
+			Expect(password).ToNot(Equal("msazure"))
+
+			err := secretsStore.ConvertSecret("someKey", tests.NewSecretTestFixture())
+			Expect(err).ToNot(HaveOccurred())
+			Expect(secretsStore.GetPfxPassword("someKey")).To(Equal(password))
+			Expect(secretsStore.GetPfxPassword("otherKey")).To(BeEmpty())
+		})
 	})
 
 	ginkgo.When("certificate is no cached", func() {
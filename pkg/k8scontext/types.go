@@ -3,6 +3,7 @@ package k8scontext
 import (
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/tools/cache"
+	gatewayapi_versioned "sigs.k8s.io/gateway-api/pkg/client/clientset/versioned"
 
 	"github.com/Azure/application-gateway-kubernetes-ingress/pkg/crd_client/agic_crd_client/clientset/versioned"
 	multicluster_versioned "github.com/Azure/application-gateway-kubernetes-ingress/pkg/crd_client/azure_multicluster_crd_client/clientset/versioned"
@@ -14,7 +15,7 @@ import (
 
 // InformerCollection : all the informers for k8s resources we care about.
 type InformerCollection struct {
-	Endpoints                                   cache.SharedIndexInformer
+	EndpointSlices                              cache.SharedIndexInformer
 	Ingress                                     cache.SharedIndexInformer
 	IngressClass                                cache.SharedIndexInformer
 	Pods                                        cache.SharedIndexInformer
@@ -26,15 +27,22 @@ type InformerCollection struct {
 	AzureApplicationGatewayBackendPool          cache.SharedInformer
 	AzureApplicationGatewayRewrite              cache.SharedInformer
 	AzureApplicationGatewayInstanceUpdateStatus cache.SharedInformer
+	AzureApplicationGatewayClassParameters      cache.SharedInformer
+	LoadDistributionPolicy                      cache.SharedInformer
+	AzureApplicationGatewayRouteMatch           cache.SharedInformer
 	MultiClusterService                         cache.SharedInformer
 	MultiClusterIngress                         cache.SharedInformer
 	IstioGateway                                cache.SharedIndexInformer
 	IstioVirtualService                         cache.SharedIndexInformer
+	IstioDestinationRule                        cache.SharedIndexInformer
+	GatewayClass                                cache.SharedIndexInformer
+	Gateway                                     cache.SharedIndexInformer
+	HTTPRoute                                   cache.SharedIndexInformer
 }
 
 // CacheCollection : all the listers from the informers.
 type CacheCollection struct {
-	Endpoints                                   cache.Store
+	EndpointSlices                              cache.Indexer
 	Ingress                                     cache.Store
 	IngressClass                                cache.Store
 	Pods                                        cache.Store
@@ -46,10 +54,17 @@ type CacheCollection struct {
 	AzureApplicationGatewayBackendPool          cache.Store
 	AzureApplicationGatewayRewrite              cache.Store
 	AzureApplicationGatewayInstanceUpdateStatus cache.Store
+	AzureApplicationGatewayClassParameters      cache.Store
+	LoadDistributionPolicy                      cache.Store
+	AzureApplicationGatewayRouteMatch           cache.Store
 	MultiClusterService                         cache.Store
 	MultiClusterIngress                         cache.Store
 	IstioGateway                                cache.Store
 	IstioVirtualService                         cache.Store
+	IstioDestinationRule                        cache.Store
+	GatewayClass                                cache.Store
+	Gateway                                     cache.Store
+	HTTPRoute                                   cache.Store
 }
 
 // Context : cache and listener for k8s resources.
@@ -59,6 +74,7 @@ type Context struct {
 	crdClient             versioned.Interface
 	istioCrdClient        istio_versioned.Interface
 	multiClusterCrdClient multicluster_versioned.Interface
+	gatewayAPIClient      gatewayapi_versioned.Interface
 
 	informers              *InformerCollection
 	Caches                 *CacheCollection
@@ -70,13 +86,25 @@ type Context struct {
 
 	CacheSynced chan interface{}
 
+	// crdsSynced are the informers of the CRDs which Run starts without waiting for their initial sync.
+	crdsSynced []cache.InformerSynced
+
 	MetricStore metricstore.MetricStore
 	namespaces  map[string]interface{}
 
-	ingressClassControllerName  string
-	ingressClassResourceName    string
+	ingressClassControllerName string
+	ingressClassResourceName   string
+	// ingressClassAnnotation, when set, replaces the controller name as the kubernetes.io/ingress.class annotation of the Ingresses AGIC processes.
+	ingressClassAnnotation      string
 	ingressClassResourceEnabled bool
 	ingressClassResourceDefault bool
+
+	// gatewayClassControllerName and gatewayClassName select the GatewayClasses, and hence the Gateways, AGIC translates.
+	gatewayClassControllerName string
+	gatewayClassName           string
+
+	// gatewayAPIEnabled tells whether AGIC translates HTTPRoutes, whose backendRefs then also reference Services.
+	gatewayAPIEnabled bool
 }
 
 // IPAddress is type for IP address string
@@ -9,6 +9,8 @@ import (
 	"net/http"
 	"time"
 
+	"k8s.io/client-go/util/workqueue"
+
 	"github.com/Azure/application-gateway-kubernetes-ingress/pkg/controllererrors"
 )
 
@@ -46,3 +48,22 @@ func (ms *fakeMetricStore) IncArmAPICallCounter() {}
 func (ms *fakeMetricStore) IncK8sAPIEventCounter() {}
 
 func (ms *fakeMetricStore) IncErrorCount(controllererrors.ErrorCode) {}
+
+func (ms *fakeMetricStore) SetIsLeader(bool) {}
+
+func (ms *fakeMetricStore) AddConfigChanges(string, string, int) {}
+
+// WorkqueueMetricsProvider returns nil, making the workqueue fall back to its no-op metrics.
+func (ms *fakeMetricStore) WorkqueueMetricsProvider() workqueue.MetricsProvider {
+	return nil
+}
+
+func (ms *fakeMetricStore) IncDroppedEventCounter() {}
+
+func (ms *fakeMetricStore) IncArmAPIConcurrencyConflictCounter() {}
+
+func (ms *fakeMetricStore) IncArmAPIRetryCounter(string) {}
+
+func (ms *fakeMetricStore) IncArmAPIThrottledCounter() {}
+
+func (ms *fakeMetricStore) SetArmAPIRemainingQuota(string, int) {}
@@ -12,6 +12,7 @@ import (
 
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"k8s.io/client-go/util/workqueue"
 
 	"github.com/Azure/application-gateway-kubernetes-ingress/pkg/controllererrors"
 	"github.com/Azure/application-gateway-kubernetes-ingress/pkg/environment"
@@ -24,6 +25,18 @@ const (
 
 	// ErrorCode is a sub-label for keeping track of error for a specific error code
 	ErrorCode = "error_code"
+
+	// ResourceKind is a sub-label for keeping track of changes to a specific kind of App Gateway sub-resource
+	ResourceKind = "resource_kind"
+
+	// ChangeAction is a sub-label for keeping track of whether App Gateway sub-resources were added, changed or removed
+	ChangeAction = "action"
+
+	// RetryReason is a sub-label for keeping track of why ARM requests were retried
+	RetryReason = "reason"
+
+	// QuotaKind is a sub-label for keeping track of a specific kind of remaining ARM quota
+	QuotaKind = "quota_kind"
 )
 
 // MetricStore is store maintaining all metrics
@@ -37,6 +50,14 @@ type MetricStore interface {
 	IncArmAPICallCounter()
 	IncK8sAPIEventCounter()
 	IncErrorCount(controllererrors.ErrorCode)
+	SetIsLeader(bool)
+	AddConfigChanges(resourceKind string, action string, count int)
+	WorkqueueMetricsProvider() workqueue.MetricsProvider
+	IncDroppedEventCounter()
+	IncArmAPIConcurrencyConflictCounter()
+	IncArmAPIRetryCounter(reason string)
+	IncArmAPIThrottledCounter()
+	SetArmAPIRemainingQuota(kind string, remaining int)
 }
 
 // AGICMetricStore is store
@@ -47,13 +68,43 @@ type AGICMetricStore struct {
 	armAPICallCounter              prometheus.Counter
 	armAPIUpdateCallFailureCounter prometheus.Counter
 	armAPIUpdateCallSuccessCounter prometheus.Counter
+	armAPIConflictCounter          prometheus.Counter
+	armAPIRetryCounterVec          *prometheus.CounterVec
+	armAPIThrottledCounter         prometheus.Counter
+	armAPIRemainingQuotaVec        *prometheus.GaugeVec
 	errorCounterVec                *prometheus.CounterVec
+	isLeader                       prometheus.Gauge
+	configChangeCounterVec         *prometheus.CounterVec
+
+	workqueueDepth                   prometheus.Gauge
+	workqueueAddsCounter             prometheus.Counter
+	workqueueLatency                 prometheus.Histogram
+	workqueueWorkDuration            prometheus.Histogram
+	workqueueUnfinishedWork          prometheus.Gauge
+	workqueueLongestRunningProcessor prometheus.Gauge
+	workqueueRetriesCounter          prometheus.Counter
+	workqueueDroppedCounter          prometheus.Counter
 
 	registry *prometheus.Registry
 }
 
 // NewMetricStore returns a new metric store
 func NewMetricStore(envVariable environment.EnvVariables) MetricStore {
+	return newMetricStore(envVariable, prometheus.NewRegistry())
+}
+
+// NewMetricStores returns a metric store for each of the Application Gateways AGIC manages, labeled with the environment variables of its controller.
+// The stores share their registry, so that the Handler of any of them serves the metrics of all of them.
+func NewMetricStores(envVariables []environment.EnvVariables) []MetricStore {
+	registry := prometheus.NewRegistry()
+	var metricStores []MetricStore
+	for _, envVariable := range envVariables {
+		metricStores = append(metricStores, newMetricStore(envVariable, registry))
+	}
+	return metricStores
+}
+
+func newMetricStore(envVariable environment.EnvVariables, registry *prometheus.Registry) *AGICMetricStore {
 	constLabels := prometheus.Labels{
 		"controller_class":                envVariable.IngressClassControllerName,
 		"controller_namespace":            envVariable.AGICPodNamespace,
@@ -95,6 +146,36 @@ func NewMetricStore(envVariable environment.EnvVariables) MetricStore {
 			Name:        "arm_api_update_call_success_counter",
 			Help:        "This counter represents the number of update API calls that successfully updated Application Gateway",
 		}),
+		armAPIConflictCounter: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace:   PrometheusNamespace,
+			ConstLabels: constLabels,
+			Name:        "arm_api_concurrency_conflict_counter",
+			Help:        "This counter represents the number of update API calls rejected because Application Gateway was modified since AGIC read it",
+		}),
+		armAPIRetryCounterVec: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace:   PrometheusNamespace,
+				ConstLabels: constLabels,
+				Name:        "arm_api_retry_counter",
+				Help:        "This counter represents the number of API calls to ARM retried after being throttled, conflicting with an operation in progress or failing transiently",
+			},
+			[]string{RetryReason},
+		),
+		armAPIThrottledCounter: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace:   PrometheusNamespace,
+			ConstLabels: constLabels,
+			Name:        "arm_api_throttled_counter",
+			Help:        "This counter represents the number of API calls to ARM throttled with 429 Too Many Requests",
+		}),
+		armAPIRemainingQuotaVec: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Namespace:   PrometheusNamespace,
+				ConstLabels: constLabels,
+				Name:        "arm_api_remaining_quota",
+				Help:        "This gauge represents the number of ARM requests the subscription has left before being throttled, as last reported by ARM",
+			},
+			[]string{QuotaKind},
+		),
 		errorCounterVec: prometheus.NewCounterVec(
 			prometheus.CounterOpts{
 				Namespace:   PrometheusNamespace,
@@ -104,7 +185,72 @@ func NewMetricStore(envVariable environment.EnvVariables) MetricStore {
 			},
 			[]string{ErrorCode},
 		),
-		registry: prometheus.NewRegistry(),
+		isLeader: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace:   PrometheusNamespace,
+			ConstLabels: constLabels,
+			Name:        "is_leader",
+			Help:        "This gauge is 1 when this AGIC instance is the leader managing Application Gateway and 0 when it is on standby",
+		}),
+		configChangeCounterVec: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace:   PrometheusNamespace,
+				ConstLabels: constLabels,
+				Name:        "config_change_counter",
+				Help:        "This counter represents the number of App Gateway sub-resources added, changed or removed by applied configurations",
+			},
+			[]string{ResourceKind, ChangeAction},
+		),
+		workqueueDepth: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace:   PrometheusNamespace,
+			ConstLabels: constLabels,
+			Name:        "workqueue_depth",
+			Help:        "This gauge represents the number of batches of events waiting to be processed",
+		}),
+		workqueueAddsCounter: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace:   PrometheusNamespace,
+			ConstLabels: constLabels,
+			Name:        "workqueue_adds_counter",
+			Help:        "This counter represents the number of times a batch of events was queued for processing",
+		}),
+		workqueueLatency: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Namespace:   PrometheusNamespace,
+			ConstLabels: constLabels,
+			Name:        "workqueue_queue_duration_seconds",
+			Help:        "How long a batch of events waited in the workqueue before being processed",
+			Buckets:     workqueueBuckets,
+		}),
+		workqueueWorkDuration: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Namespace:   PrometheusNamespace,
+			ConstLabels: constLabels,
+			Name:        "workqueue_work_duration_seconds",
+			Help:        "How long processing a batch of events took",
+			Buckets:     workqueueBuckets,
+		}),
+		workqueueUnfinishedWork: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace:   PrometheusNamespace,
+			ConstLabels: constLabels,
+			Name:        "workqueue_unfinished_work_seconds",
+			Help:        "This gauge represents the seconds spent on work in progress, which the work duration histogram has not observed yet",
+		}),
+		workqueueLongestRunningProcessor: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace:   PrometheusNamespace,
+			ConstLabels: constLabels,
+			Name:        "workqueue_longest_running_processor_seconds",
+			Help:        "This gauge represents for how long the batch of events in progress has been processed",
+		}),
+		workqueueRetriesCounter: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace:   PrometheusNamespace,
+			ConstLabels: constLabels,
+			Name:        "workqueue_retries_counter",
+			Help:        "This counter represents the number of times a batch of events was retried after failing to update Application Gateway",
+		}),
+		workqueueDroppedCounter: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace:   PrometheusNamespace,
+			ConstLabels: constLabels,
+			Name:        "workqueue_dropped_counter",
+			Help:        "This counter represents the number of batches of events dropped after exhausting their retries",
+		}),
+		registry: registry,
 	}
 }
 
@@ -114,8 +260,22 @@ func (ms *AGICMetricStore) Start() {
 	ms.registry.MustRegister(ms.k8sAPIEventCounter)
 	ms.registry.MustRegister(ms.armAPIUpdateCallSuccessCounter)
 	ms.registry.MustRegister(ms.armAPIUpdateCallFailureCounter)
+	ms.registry.MustRegister(ms.armAPIConflictCounter)
 	ms.registry.MustRegister(ms.armAPICallCounter)
+	ms.registry.MustRegister(ms.armAPIRetryCounterVec)
+	ms.registry.MustRegister(ms.armAPIThrottledCounter)
+	ms.registry.MustRegister(ms.armAPIRemainingQuotaVec)
 	ms.registry.MustRegister(ms.errorCounterVec)
+	ms.registry.MustRegister(ms.isLeader)
+	ms.registry.MustRegister(ms.configChangeCounterVec)
+	ms.registry.MustRegister(ms.workqueueDepth)
+	ms.registry.MustRegister(ms.workqueueAddsCounter)
+	ms.registry.MustRegister(ms.workqueueLatency)
+	ms.registry.MustRegister(ms.workqueueWorkDuration)
+	ms.registry.MustRegister(ms.workqueueUnfinishedWork)
+	ms.registry.MustRegister(ms.workqueueLongestRunningProcessor)
+	ms.registry.MustRegister(ms.workqueueRetriesCounter)
+	ms.registry.MustRegister(ms.workqueueDroppedCounter)
 }
 
 // Stop store
@@ -124,8 +284,22 @@ func (ms *AGICMetricStore) Stop() {
 	ms.registry.Unregister(ms.k8sAPIEventCounter)
 	ms.registry.Unregister(ms.armAPIUpdateCallSuccessCounter)
 	ms.registry.Unregister(ms.armAPIUpdateCallFailureCounter)
+	ms.registry.Unregister(ms.armAPIConflictCounter)
 	ms.registry.Unregister(ms.armAPICallCounter)
+	ms.registry.Unregister(ms.armAPIRetryCounterVec)
+	ms.registry.Unregister(ms.armAPIThrottledCounter)
+	ms.registry.Unregister(ms.armAPIRemainingQuotaVec)
 	ms.registry.Unregister(ms.errorCounterVec)
+	ms.registry.Unregister(ms.isLeader)
+	ms.registry.Unregister(ms.configChangeCounterVec)
+	ms.registry.Unregister(ms.workqueueDepth)
+	ms.registry.Unregister(ms.workqueueAddsCounter)
+	ms.registry.Unregister(ms.workqueueLatency)
+	ms.registry.Unregister(ms.workqueueWorkDuration)
+	ms.registry.Unregister(ms.workqueueUnfinishedWork)
+	ms.registry.Unregister(ms.workqueueLongestRunningProcessor)
+	ms.registry.Unregister(ms.workqueueRetriesCounter)
+	ms.registry.Unregister(ms.workqueueDroppedCounter)
 }
 
 // SetUpdateLatencySec updates latency
@@ -150,6 +324,28 @@ func (ms *AGICMetricStore) IncArmAPIUpdateCallSuccessCounter() {
 	ms.armAPICallCounter.Inc()
 }
 
+// IncArmAPIConcurrencyConflictCounter increases the counter after ARM rejected an update as Application Gateway was modified since AGIC read it
+func (ms *AGICMetricStore) IncArmAPIConcurrencyConflictCounter() {
+	ms.armAPIConflictCounter.Inc()
+	ms.armAPICallCounter.Inc()
+}
+
+// IncArmAPIRetryCounter increases the counter after an API call to ARM was retried for the reason
+func (ms *AGICMetricStore) IncArmAPIRetryCounter(reason string) {
+	ms.armAPIRetryCounterVec.With(prometheus.Labels{RetryReason: reason}).Inc()
+	ms.armAPICallCounter.Inc()
+}
+
+// IncArmAPIThrottledCounter increases the counter after ARM throttled an API call
+func (ms *AGICMetricStore) IncArmAPIThrottledCounter() {
+	ms.armAPIThrottledCounter.Inc()
+}
+
+// SetArmAPIRemainingQuota records the number of ARM requests of a kind the subscription has left, as reported by ARM
+func (ms *AGICMetricStore) SetArmAPIRemainingQuota(kind string, remaining int) {
+	ms.armAPIRemainingQuotaVec.With(prometheus.Labels{QuotaKind: kind}).Set(float64(remaining))
+}
+
 // IncArmAPICallCounter increases the counter for success on ARM
 func (ms *AGICMetricStore) IncArmAPICallCounter() {
 	ms.armAPICallCounter.Inc()
@@ -160,6 +356,30 @@ func (ms *AGICMetricStore) IncErrorCount(errorCode controllererrors.ErrorCode) {
 	ms.errorCounterVec.With(prometheus.Labels{ErrorCode: string(errorCode)}).Inc()
 }
 
+// SetIsLeader records whether this AGIC instance currently holds the leader election Lease
+func (ms *AGICMetricStore) SetIsLeader(isLeader bool) {
+	if isLeader {
+		ms.isLeader.Set(1)
+		return
+	}
+	ms.isLeader.Set(0)
+}
+
+// AddConfigChanges increases the counter of App Gateway sub-resources of a kind which an applied configuration added, changed or removed
+func (ms *AGICMetricStore) AddConfigChanges(resourceKind string, action string, count int) {
+	ms.configChangeCounterVec.With(prometheus.Labels{ResourceKind: resourceKind, ChangeAction: action}).Add(float64(count))
+}
+
+// WorkqueueMetricsProvider returns the provider feeding the metrics of the workqueue of the worker
+func (ms *AGICMetricStore) WorkqueueMetricsProvider() workqueue.MetricsProvider {
+	return workqueueMetricsProvider{ms: ms}
+}
+
+// IncDroppedEventCounter increases the counter after a batch of events was dropped for exhausting its retries
+func (ms *AGICMetricStore) IncDroppedEventCounter() {
+	ms.workqueueDroppedCounter.Inc()
+}
+
 // Handler return the registry
 func (ms *AGICMetricStore) Handler() http.Handler {
 	return promhttp.InstrumentMetricHandler(
@@ -0,0 +1,48 @@
+// -------------------------------------------------------------------------------------------
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License. See License.txt in the project root for license information.
+// --------------------------------------------------------------------------------------------
+
+package metricstore
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"k8s.io/client-go/util/workqueue"
+)
+
+// Buckets from 10ms to ~5.5min, covering both the debounce of events and slow ARM deployments.
+var workqueueBuckets = prometheus.ExponentialBuckets(0.01, 2, 16)
+
+// workqueueMetricsProvider hands the metrics of the AGIC metric store to the workqueue.
+// AGIC runs a single workqueue, so the name of the queue is not used as a label.
+type workqueueMetricsProvider struct {
+	ms *AGICMetricStore
+}
+
+func (p workqueueMetricsProvider) NewDepthMetric(string) workqueue.GaugeMetric {
+	return p.ms.workqueueDepth
+}
+
+func (p workqueueMetricsProvider) NewAddsMetric(string) workqueue.CounterMetric {
+	return p.ms.workqueueAddsCounter
+}
+
+func (p workqueueMetricsProvider) NewLatencyMetric(string) workqueue.HistogramMetric {
+	return p.ms.workqueueLatency
+}
+
+func (p workqueueMetricsProvider) NewWorkDurationMetric(string) workqueue.HistogramMetric {
+	return p.ms.workqueueWorkDuration
+}
+
+func (p workqueueMetricsProvider) NewUnfinishedWorkSecondsMetric(string) workqueue.SettableGaugeMetric {
+	return p.ms.workqueueUnfinishedWork
+}
+
+func (p workqueueMetricsProvider) NewLongestRunningProcessorSecondsMetric(string) workqueue.SettableGaugeMetric {
+	return p.ms.workqueueLongestRunningProcessor
+}
+
+func (p workqueueMetricsProvider) NewRetriesMetric(string) workqueue.CounterMetric {
+	return p.ms.workqueueRetriesCounter
+}
@@ -0,0 +1,124 @@
+// -------------------------------------------------------------------------------------------
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License. See License.txt in the project root for license information.
+// --------------------------------------------------------------------------------------------
+
+package pfx
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+)
+
+// ParseCertificates returns the certificates of the PEM encoded data, e.g. the tls.crt of a Kubernetes TLS secret.
+func ParseCertificates(pemData []byte) ([]*x509.Certificate, error) {
+	var certificates []*x509.Certificate
+	for block, rest := pem.Decode(pemData); block != nil; block, rest = pem.Decode(rest) {
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+		certificate, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		certificates = append(certificates, certificate)
+	}
+
+	if len(certificates) == 0 {
+		return nil, errors.New("no PEM encoded certificate found")
+	}
+	return certificates, nil
+}
+
+// ParsePrivateKey returns the RSA or EC private key of the PEM encoded data, e.g. the tls.key of a Kubernetes TLS secret.
+// The key may be in PKCS#8, PKCS#1 (RSA) or SEC 1 (EC) form.
+func ParsePrivateKey(pemData []byte) (crypto.Signer, error) {
+	for block, rest := pem.Decode(pemData); block != nil; block, rest = pem.Decode(rest) {
+		var key interface{}
+		var err error
+		switch block.Type {
+		case "PRIVATE KEY":
+			key, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+		case "RSA PRIVATE KEY":
+			key, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+		case "EC PRIVATE KEY":
+			key, err = x509.ParseECPrivateKey(block.Bytes)
+		default:
+			// e.g. the EC PARAMETERS openssl writes before an EC key
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		switch key := key.(type) {
+		case *rsa.PrivateKey:
+			return key, nil
+		case *ecdsa.PrivateKey:
+			return key, nil
+		default:
+			return nil, fmt.Errorf("unsupported private key type %T; Application Gateway supports RSA and EC keys", key)
+		}
+	}
+
+	return nil, errors.New("no PEM encoded private key found")
+}
+
+// OrderChain returns the certificate of the private key followed by the chain of its issuers, as a PFX carries them.
+// Certificates which are not part of the chain are kept at the end, in their original order.
+func OrderChain(key crypto.Signer, certificates []*x509.Certificate) ([]*x509.Certificate, error) {
+	remaining := append([]*x509.Certificate(nil), certificates...)
+
+	leafIdx := -1
+	for idx, certificate := range remaining {
+		if publicKey, ok := certificate.PublicKey.(interface{ Equal(crypto.PublicKey) bool }); ok && publicKey.Equal(key.Public()) {
+			leafIdx = idx
+			break
+		}
+	}
+	if leafIdx < 0 {
+		return nil, errors.New("the private key does not match any of the certificates")
+	}
+
+	chain := []*x509.Certificate{remaining[leafIdx]}
+	remaining = append(remaining[:leafIdx], remaining[leafIdx+1:]...)
+	for {
+		issuerIdx := findIssuer(chain[len(chain)-1], remaining)
+		if issuerIdx < 0 {
+			break
+		}
+		chain = append(chain, remaining[issuerIdx])
+		remaining = append(remaining[:issuerIdx], remaining[issuerIdx+1:]...)
+	}
+
+	return append(chain, remaining...), nil
+}
+
+// findIssuer returns the index of the certificate which issued the given one, or -1.
+// A certificate whose signature verifies is preferred over one which only has the expected subject.
+func findIssuer(certificate *x509.Certificate, candidates []*x509.Certificate) int {
+	if bytes.Equal(certificate.RawIssuer, certificate.RawSubject) {
+		// self-signed: the chain is complete
+		return -1
+	}
+
+	found := -1
+	for idx, candidate := range candidates {
+		if !bytes.Equal(certificate.RawIssuer, candidate.RawSubject) {
+			continue
+		}
+		if certificate.CheckSignatureFrom(candidate) == nil {
+			return idx
+		}
+		if found < 0 {
+			found = idx
+		}
+	}
+	return found
+}
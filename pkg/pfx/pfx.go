@@ -0,0 +1,38 @@
+// -------------------------------------------------------------------------------------------
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License. See License.txt in the project root for license information.
+// --------------------------------------------------------------------------------------------
+
+// Package pfx encodes private keys and their certificate chains as PKCS#12 (PFX), the format Application Gateway imports SSL certificates in.
+package pfx
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/base64"
+	"errors"
+
+	"software.sslmate.com/src/go-pkcs12"
+)
+
+const passwordLength = 24
+
+// Encode returns the PFX of the private key and its certificates, the first of which must be the certificate of the key.
+// The PFX is encrypted with 3DES and integrity protected with HMAC-SHA1, both keyed by the password, which every version of
+// Application Gateway imports.
+func Encode(key crypto.Signer, certificates []*x509.Certificate, password string) ([]byte, error) {
+	if len(certificates) == 0 {
+		return nil, errors.New("a PFX needs the certificate of the private key")
+	}
+	return pkcs12.LegacyDES.Encode(key, certificates[0], certificates[1:], password)
+}
+
+// NewPassword returns a random password to encode a PFX with.
+func NewPassword() (string, error) {
+	password := make([]byte, passwordLength)
+	if _, err := rand.Read(password); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(password), nil
+}
@@ -0,0 +1,21 @@
+// -------------------------------------------------------------------------------------------
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License. See License.txt in the project root for license information.
+// --------------------------------------------------------------------------------------------
+
+//go:build unittest
+// +build unittest
+
+package pfx
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+func TestPfx(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Pfx Suite")
+}
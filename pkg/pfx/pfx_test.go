@@ -0,0 +1,142 @@
+// -------------------------------------------------------------------------------------------
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License. See License.txt in the project root for license information.
+// --------------------------------------------------------------------------------------------
+
+package pfx
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"golang.org/x/crypto/pkcs12"
+)
+
+var _ = Describe("Test PFX encoding", func() {
+	newCertificate := func(name string, key crypto.Signer, issuer *x509.Certificate, issuerKey crypto.Signer) *x509.Certificate {
+		template := &x509.Certificate{
+			SerialNumber:          big.NewInt(time.Now().UnixNano()),
+			Subject:               pkix.Name{CommonName: name},
+			NotBefore:             time.Now().Add(-time.Hour),
+			NotAfter:              time.Now().Add(time.Hour),
+			BasicConstraintsValid: true,
+			IsCA:                  issuer == nil || name != "leaf",
+			KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature,
+		}
+		if issuer == nil {
+			issuer, issuerKey = template, key
+		}
+		der, err := x509.CreateCertificate(rand.Reader, template, issuer, key.Public(), issuerKey)
+		Expect(err).ToNot(HaveOccurred())
+		certificate, err := x509.ParseCertificate(der)
+		Expect(err).ToNot(HaveOccurred())
+		return certificate
+	}
+
+	rootKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	intermediateKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	ecKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	rsaKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+
+	root := newCertificate("root", rootKey, nil, nil)
+	intermediate := newCertificate("intermediate", intermediateKey, root, rootKey)
+	ecLeaf := newCertificate("leaf", ecKey, intermediate, intermediateKey)
+	rsaLeaf := newCertificate("leaf", rsaKey, intermediate, intermediateKey)
+
+	pemEncode := func(blockType string, data []byte) []byte {
+		return pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: data})
+	}
+
+	Context("parsing the tls.key of a secret", func() {
+		It("accepts PKCS#8, PKCS#1 and SEC 1 keys", func() {
+			pkcs8Key, err := x509.MarshalPKCS8PrivateKey(rsaKey)
+			Expect(err).ToNot(HaveOccurred())
+			key, err := ParsePrivateKey(pemEncode("PRIVATE KEY", pkcs8Key))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(key.Public()).To(Equal(rsaKey.Public()))
+
+			key, err = ParsePrivateKey(pemEncode("RSA PRIVATE KEY", x509.MarshalPKCS1PrivateKey(rsaKey)))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(key.Public()).To(Equal(rsaKey.Public()))
+
+			sec1Key, err := x509.MarshalECPrivateKey(ecKey)
+			Expect(err).ToNot(HaveOccurred())
+			ecParameters := pemEncode("EC PARAMETERS", []byte{0x06, 0x08, 0x2a, 0x86, 0x48, 0xce, 0x3d, 0x03, 0x01, 0x07})
+			key, err = ParsePrivateKey(append(ecParameters, pemEncode("EC PRIVATE KEY", sec1Key)...))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(key.Public()).To(Equal(ecKey.Public()))
+		})
+
+		It("rejects data without a key", func() {
+			_, err := ParsePrivateKey([]byte("X"))
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	Context("ordering the certificates of tls.crt", func() {
+		It("puts the certificate of the key first, followed by its issuers", func() {
+			certificates, err := ParseCertificates(append(append(
+				pemEncode("CERTIFICATE", root.Raw),
+				pemEncode("CERTIFICATE", ecLeaf.Raw)...),
+				pemEncode("CERTIFICATE", intermediate.Raw)...))
+			Expect(err).ToNot(HaveOccurred())
+
+			chain, err := OrderChain(ecKey, certificates)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(chain).To(Equal([]*x509.Certificate{ecLeaf, intermediate, root}))
+		})
+
+		It("fails when the key does not match any certificate", func() {
+			_, err := OrderChain(rsaKey, []*x509.Certificate{ecLeaf, intermediate})
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	Context("encoding", func() {
+		for _, tc := range []struct {
+			name     string
+			key      crypto.Signer
+			leaf     *x509.Certificate
+			pemBlock string
+		}{
+			{"RSA", rsaKey, rsaLeaf, "RSA PRIVATE KEY"},
+			{"EC", ecKey, ecLeaf, "EC PRIVATE KEY"},
+		} {
+			tc := tc
+			It("round-trips the "+tc.name+" key and its chain", func() {
+				password, err := NewPassword()
+				Expect(err).ToNot(HaveOccurred())
+
+				data, err := Encode(tc.key, []*x509.Certificate{tc.leaf, intermediate, root}, password)
+				Expect(err).ToNot(HaveOccurred())
+
+				_, err = pkcs12.ToPEM(data, "msazure")
+				Expect(err).To(HaveOccurred())
+
+				blocks, err := pkcs12.ToPEM(data, password)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(blocks).To(HaveLen(4))
+				Expect(blocks[0].Bytes).To(Equal(tc.leaf.Raw))
+				Expect(blocks[1].Bytes).To(Equal(intermediate.Raw))
+				Expect(blocks[2].Bytes).To(Equal(root.Raw))
+				Expect(blocks[0].Headers["localKeyId"]).To(Equal(blocks[3].Headers["localKeyId"]))
+
+				// ToPEM returns the key in PKCS#1 or SEC 1 form, not PKCS#8
+				blocks[3].Type = tc.pemBlock
+				key, err := ParsePrivateKey(pem.EncodeToMemory(blocks[3]))
+				Expect(err).ToNot(HaveOccurred())
+				Expect(key.Public()).To(Equal(tc.key.Public()))
+			})
+		}
+	})
+})
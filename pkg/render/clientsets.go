@@ -0,0 +1,72 @@
+// -------------------------------------------------------------------------------------------
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License. See License.txt in the project root for license information.
+// --------------------------------------------------------------------------------------------
+
+package render
+
+import (
+	"strings"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/watch"
+	k8stesting "k8s.io/client-go/testing"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+	gatewayapifake "sigs.k8s.io/gateway-api/pkg/client/clientset/versioned/fake"
+)
+
+// serveFromKindGroup makes a generated fake clientset of CRDs serve the list and watch requests of the informers.
+// The fakes request each resource under the group of its +groupName tag, e.g. azureapplicationgatewayrewrites.appgw.ingress.azure.io,
+// while NewSimpleClientset tracks the objects under the API group of their kind, appgw.ingress.azure.io, which is also the
+// only group the scheme can create the lists for.
+func serveFromKindGroup(fake *k8stesting.Fake, tracker k8stesting.ObjectTracker, scheme *runtime.Scheme) {
+	kindGroup := func(resource schema.GroupVersionResource) (schema.GroupVersionResource, bool) {
+		idx := strings.Index(resource.Group, ".")
+		if idx < 0 || scheme.IsGroupRegistered(resource.Group) || !scheme.IsGroupRegistered(resource.Group[idx+1:]) {
+			return resource, false
+		}
+		resource.Group = resource.Group[idx+1:]
+		return resource, true
+	}
+
+	fake.PrependReactor("list", "*", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		list, ok := action.(k8stesting.ListActionImpl)
+		if !ok {
+			return false, nil, nil
+		}
+		resource, ok := kindGroup(list.Resource)
+		if !ok {
+			return false, nil, nil
+		}
+		list.Resource = resource
+		list.Kind.Group = resource.Group
+		return k8stesting.ObjectReaction(tracker)(list)
+	})
+	fake.PrependWatchReactor("*", func(action k8stesting.Action) (bool, watch.Interface, error) {
+		resource, ok := kindGroup(action.GetResource())
+		if !ok {
+			return false, nil, nil
+		}
+		watcher, err := tracker.Watch(resource, action.GetNamespace())
+		return true, watcher, err
+	})
+}
+
+// newGatewayAPIClientset returns a fake clientset of the Gateway API objects. NewSimpleClientset would track the Gateways
+// under the resource UnsafeGuessKindToResource guesses for their kind, gatewaies, so the informers would never list them.
+func newGatewayAPIClientset(objects []runtime.Object) (*gatewayapifake.Clientset, error) {
+	client := gatewayapifake.NewSimpleClientset()
+	for _, object := range objects {
+		if gateway, ok := object.(*gatewayv1.Gateway); ok {
+			if err := client.Tracker().Create(gatewayv1.SchemeGroupVersion.WithResource("gateways"), gateway, gateway.Namespace); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		if err := client.Tracker().Add(object); err != nil {
+			return nil, err
+		}
+	}
+	return client, nil
+}
@@ -0,0 +1,196 @@
+// -------------------------------------------------------------------------------------------
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License. See License.txt in the project root for license information.
+// --------------------------------------------------------------------------------------------
+
+package render
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/serializer"
+	"k8s.io/apimachinery/pkg/runtime/serializer/json"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/apimachinery/pkg/util/yaml"
+	kubescheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/klog/v2"
+	gatewayapischeme "sigs.k8s.io/gateway-api/pkg/client/clientset/versioned/scheme"
+
+	agicscheme "github.com/Azure/application-gateway-kubernetes-ingress/pkg/crd_client/agic_crd_client/clientset/versioned/scheme"
+	multiclusterscheme "github.com/Azure/application-gateway-kubernetes-ingress/pkg/crd_client/azure_multicluster_crd_client/clientset/versioned/scheme"
+	istioscheme "github.com/Azure/application-gateway-kubernetes-ingress/pkg/crd_client/istio_crd_client/clientset/versioned/scheme"
+	"github.com/Azure/application-gateway-kubernetes-ingress/pkg/k8scontext/convert"
+)
+
+// Objects are the Kubernetes resources of the manifests, sorted by the clientset which serves them to AGIC.
+type Objects struct {
+	Kubernetes   []runtime.Object
+	AGIC         []runtime.Object
+	MultiCluster []runtime.Object
+	Istio        []runtime.Object
+	GatewayAPI   []runtime.Object
+}
+
+// clusterScopedKinds are the kinds AGIC reads which do not live in a namespace.
+var clusterScopedKinds = map[string]interface{}{
+	"GatewayClass": nil,
+	"IngressClass": nil,
+	"Namespace":    nil,
+	"Node":         nil,
+}
+
+var manifestExtensions = map[string]interface{}{
+	".yaml": nil,
+	".yml":  nil,
+	".json": nil,
+}
+
+// LoadManifests reads the resources of the YAML and JSON manifests at path, a file or a directory which is walked recursively.
+// Namespaced resources without a namespace are placed in namespace, as kubectl would. Kinds which none of the clientsets
+// of AGIC serve, e.g. other CRDs, are skipped.
+func LoadManifests(path string, namespace string) (*Objects, error) {
+	objects := &Objects{}
+	err := filepath.Walk(path, func(file string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			return nil
+		}
+		if _, ok := manifestExtensions[strings.ToLower(filepath.Ext(file))]; !ok && file != path {
+			return nil
+		}
+
+		f, err := os.Open(file)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		if err := objects.load(f, namespace); err != nil {
+			return fmt.Errorf("reading manifest %s: %w", file, err)
+		}
+		return nil
+	})
+	return objects, err
+}
+
+// load adds the resources of the documents of a multi-document YAML or JSON manifest.
+func (o *Objects) load(manifest io.Reader, namespace string) error {
+	reader := yaml.NewYAMLReader(bufio.NewReader(manifest))
+	for {
+		document, err := reader.Read()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		jsonDocument, err := yaml.ToJSON(document)
+		if err != nil {
+			return err
+		}
+		// Empty documents, e.g. a trailing "---" or a document of comments only.
+		if trimmed := bytes.TrimSpace(jsonDocument); len(trimmed) == 0 || bytes.Equal(trimmed, []byte("null")) {
+			continue
+		}
+		if err := o.add(jsonDocument, namespace); err != nil {
+			return err
+		}
+	}
+}
+
+// setDefaults sets the defaults the API server would set on the fields AGIC reads, as the manifests usually leave them out.
+func setDefaults(object runtime.Object) {
+	switch obj := object.(type) {
+	case *v1.Service:
+		for idx := range obj.Spec.Ports {
+			port := &obj.Spec.Ports[idx]
+			if port.Protocol == "" {
+				port.Protocol = v1.ProtocolTCP
+			}
+			if port.TargetPort == (intstr.IntOrString{}) {
+				port.TargetPort = intstr.FromInt32(port.Port)
+			}
+		}
+	case *v1.Endpoints:
+		for subsetIdx := range obj.Subsets {
+			for idx := range obj.Subsets[subsetIdx].Ports {
+				if port := &obj.Subsets[subsetIdx].Ports[idx]; port.Protocol == "" {
+					port.Protocol = v1.ProtocolTCP
+				}
+			}
+		}
+	case *v1.Pod:
+		for containerIdx := range obj.Spec.Containers {
+			for idx := range obj.Spec.Containers[containerIdx].Ports {
+				if port := &obj.Spec.Containers[containerIdx].Ports[idx]; port.Protocol == "" {
+					port.Protocol = v1.ProtocolTCP
+				}
+			}
+		}
+	}
+}
+
+// add decodes a single resource with the scheme of the first clientset which serves its kind; Lists are added item by item.
+func (o *Objects) add(document []byte, namespace string) error {
+	for _, target := range []struct {
+		codecs  serializer.CodecFactory
+		objects *[]runtime.Object
+	}{
+		{kubescheme.Codecs, &o.Kubernetes},
+		{agicscheme.Codecs, &o.AGIC},
+		{multiclusterscheme.Codecs, &o.MultiCluster},
+		{istioscheme.Codecs, &o.Istio},
+		{gatewayapischeme.Codecs, &o.GatewayAPI},
+	} {
+		object, gvk, err := target.codecs.UniversalDeserializer().Decode(document, nil, nil)
+		if runtime.IsNotRegisteredError(err) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+
+		if list, ok := object.(*v1.List); ok {
+			for _, item := range list.Items {
+				if err := o.add(item.Raw, namespace); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+
+		if accessor, err := meta.Accessor(object); err == nil && accessor.GetNamespace() == "" {
+			if _, ok := clusterScopedKinds[gvk.Kind]; !ok {
+				accessor.SetNamespace(namespace)
+			}
+		}
+		setDefaults(object)
+		if endpoints, ok := object.(*v1.Endpoints); ok {
+			// AGIC reads EndpointSlices, which the cluster mirrors from Endpoints created by hand.
+			for _, endpointSlice := range convert.ToEndpointSlices(endpoints) {
+				*target.objects = append(*target.objects, endpointSlice)
+			}
+			return nil
+		}
+		*target.objects = append(*target.objects, object)
+		return nil
+	}
+
+	gvk, err := json.DefaultMetaFactory.Interpret(document)
+	if err != nil {
+		return err
+	}
+	klog.V(3).Infof("Skipping %s: AGIC does not read it", gvk)
+	return nil
+}
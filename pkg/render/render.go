@@ -0,0 +1,236 @@
+// -------------------------------------------------------------------------------------------
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License. See License.txt in the project root for license information.
+// --------------------------------------------------------------------------------------------
+
+package render
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+
+	n "github.com/Azure/azure-sdk-for-go/services/network/mgmt/2021-03-01/network"
+	"github.com/Azure/go-autorest/autorest/to"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientfeatures "k8s.io/client-go/features"
+	testclient "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/klog/v2"
+
+	"github.com/Azure/application-gateway-kubernetes-ingress/pkg/appgw"
+	"github.com/Azure/application-gateway-kubernetes-ingress/pkg/azure"
+	"github.com/Azure/application-gateway-kubernetes-ingress/pkg/configdiff"
+	"github.com/Azure/application-gateway-kubernetes-ingress/pkg/controller"
+	"github.com/Azure/application-gateway-kubernetes-ingress/pkg/controllererrors"
+	agicfake "github.com/Azure/application-gateway-kubernetes-ingress/pkg/crd_client/agic_crd_client/clientset/versioned/fake"
+	agicscheme "github.com/Azure/application-gateway-kubernetes-ingress/pkg/crd_client/agic_crd_client/clientset/versioned/scheme"
+	multiclusterfake "github.com/Azure/application-gateway-kubernetes-ingress/pkg/crd_client/azure_multicluster_crd_client/clientset/versioned/fake"
+	multiclusterscheme "github.com/Azure/application-gateway-kubernetes-ingress/pkg/crd_client/azure_multicluster_crd_client/clientset/versioned/scheme"
+	istiofake "github.com/Azure/application-gateway-kubernetes-ingress/pkg/crd_client/istio_crd_client/clientset/versioned/fake"
+	"github.com/Azure/application-gateway-kubernetes-ingress/pkg/environment"
+	"github.com/Azure/application-gateway-kubernetes-ingress/pkg/k8scontext"
+	"github.com/Azure/application-gateway-kubernetes-ingress/pkg/metricstore"
+)
+
+// redacted replaces the certificate material in the rendered config.
+const redacted = "<redacted>"
+
+var actionSymbols = map[configdiff.Action]string{
+	configdiff.Added:   "+",
+	configdiff.Changed: "~",
+	configdiff.Removed: "-",
+}
+
+var disableWatchListOnce sync.Once
+
+// watchListDisabled turns off the watch-list streams of the informers, which the generated fake clientsets of the CRDs do not
+// support: The informers would wait for the bookmark of the initial events forever.
+type watchListDisabled struct {
+	clientfeatures.Gates
+}
+
+func (g watchListDisabled) Enabled(key clientfeatures.Feature) bool {
+	if key == clientfeatures.WatchListClient {
+		return false
+	}
+	return g.Gates.Enabled(key)
+}
+
+// Result is the App Gateway config generated from the manifests and how it differs from the existing config.
+type Result struct {
+	AppGw *n.ApplicationGateway
+	Diff  *configdiff.Diff
+}
+
+// Render generates the App Gateway config of the objects on top of the existing config, the way the event loop of AGIC does
+// before deploying it. It talks neither to ARM nor to a cluster. The existing config is mutated in place.
+func Render(existing *n.ApplicationGateway, objects *Objects, env environment.EnvVariables, namespaces []string) (*Result, error) {
+	if existing.ApplicationGatewayPropertiesFormat == nil {
+		return nil, fmt.Errorf("the existing App Gateway config has no properties")
+	}
+	initializeCollections(existing.ApplicationGatewayPropertiesFormat)
+
+	disableWatchListOnce.Do(func() {
+		clientfeatures.ReplaceFeatureGates(watchListDisabled{clientfeatures.FeatureGates()})
+	})
+	agicClient := agicfake.NewSimpleClientset(objects.AGIC...)
+	serveFromKindGroup(&agicClient.Fake, agicClient.Tracker(), agicscheme.Scheme)
+	multiClusterClient := multiclusterfake.NewSimpleClientset(objects.MultiCluster...)
+	serveFromKindGroup(&multiClusterClient.Fake, multiClusterClient.Tracker(), multiclusterscheme.Scheme)
+	gatewayAPIClient, err := newGatewayAPIClientset(objects.GatewayAPI)
+	if err != nil {
+		return nil, err
+	}
+
+	k8scontext.IsNetworkingV1PackageSupported = true
+	k8scontext.IsInMultiClusterMode = env.MultiClusterMode
+	k8sContext := k8scontext.NewContext(
+		testclient.NewSimpleClientset(objects.Kubernetes...),
+		agicClient,
+		multiClusterClient,
+		istiofake.NewSimpleClientset(objects.Istio...),
+		gatewayAPIClient,
+		namespaces, 0, metricstore.NewFakeMetricStore(), env)
+
+	stopChannel := make(chan struct{})
+	defer close(stopChannel)
+	if err := k8sContext.Run(stopChannel, false, env); err != nil {
+		return nil, err
+	}
+	if !k8sContext.WaitForCRDsSync(stopChannel) {
+		return nil, controllererrors.NewError(controllererrors.ErrorFailedInitialCacheSync, "failed initial sync of the custom resources")
+	}
+
+	// The event loop runs without ARM; Only the config generation is used.
+	c := controller.NewAppGwIngressController(azure.NewFakeAzClient(), identifierOf(existing, env), k8sContext, eventLogger{}, metricstore.NewFakeMetricStore(), nil, nil, env.HostedOnUnderlay)
+
+	existingSnapshot, err := configdiff.NewSnapshot(existing)
+	if err != nil {
+		return nil, err
+	}
+	generated, err := c.GenerateAppGw(existing, c.NewConfigBuilderContext(existing, env))
+	if err != nil {
+		return nil, err
+	}
+	generatedSnapshot, err := configdiff.NewSnapshot(generated)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Result{
+		AppGw: generated,
+		Diff:  configdiff.Compare(existingSnapshot, generatedSnapshot),
+	}, nil
+}
+
+// JSON returns the indented JSON of the generated config, without the certificate material of the SSL certificates.
+func (r *Result) JSON() ([]byte, error) {
+	if r.AppGw.SslCertificates != nil {
+		for idx := range *r.AppGw.SslCertificates {
+			cert := &(*r.AppGw.SslCertificates)[idx]
+			if cert.ApplicationGatewaySslCertificatePropertiesFormat == nil {
+				continue
+			}
+			if cert.Data != nil {
+				cert.Data = to.StringPtr(redacted)
+			}
+			if cert.Password != nil {
+				cert.Password = to.StringPtr(redacted)
+			}
+		}
+	}
+
+	jsonBlob, err := r.AppGw.MarshalJSON()
+	if err != nil {
+		return nil, err
+	}
+	var into map[string]interface{}
+	if err := json.Unmarshal(jsonBlob, &into); err != nil {
+		return nil, err
+	}
+	// Keep the placeholder of the redacted material readable, rather than escaping its angle brackets.
+	var indented bytes.Buffer
+	encoder := json.NewEncoder(&indented)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "    ")
+	if err := encoder.Encode(into); err != nil {
+		return nil, err
+	}
+	return bytes.TrimRight(indented.Bytes(), "\n"), nil
+}
+
+// identifierOf identifies App Gateway by the ID of its existing config, as the IDs of the generated sub-resources derive from it.
+func identifierOf(existing *n.ApplicationGateway, env environment.EnvVariables) appgw.Identifier {
+	if existing.ID == nil {
+		return appgw.Identifier{
+			SubscriptionID: env.SubscriptionID,
+			ResourceGroup:  env.ResourceGroupName,
+			AppGwName:      env.AppGwName,
+		}
+	}
+	subscriptionID, resourceGroup, appGwName := azure.ParseResourceID(*existing.ID)
+	return appgw.Identifier{
+		SubscriptionID: string(subscriptionID),
+		ResourceGroup:  string(resourceGroup),
+		AppGwName:      string(appGwName),
+	}
+}
+
+// initializeCollections sets the sub-resource collections missing from the existing config to empty ones. ARM always
+// returns them, but an exported or handwritten config may leave them out.
+func initializeCollections(properties *n.ApplicationGatewayPropertiesFormat) {
+	value := reflect.ValueOf(properties).Elem()
+	for idx := 0; idx < value.NumField(); idx++ {
+		field := value.Field(idx)
+		if field.Kind() == reflect.Ptr && field.Type().Elem().Kind() == reflect.Slice && field.IsNil() {
+			field.Set(reflect.New(field.Type().Elem()))
+			field.Elem().Set(reflect.MakeSlice(field.Type().Elem(), 0, 0))
+		}
+	}
+}
+
+// eventLogger logs the events the config generation records on the Kubernetes resources, e.g. an Ingress referencing a
+// Service which does not exist.
+type eventLogger struct{}
+
+func (eventLogger) Event(object runtime.Object, eventtype, reason, message string) {
+	klog.Warningf("%s %s: %s", reason, describe(object), message)
+}
+
+func (l eventLogger) Eventf(object runtime.Object, eventtype, reason, messageFmt string, args ...interface{}) {
+	l.Event(object, eventtype, reason, fmt.Sprintf(messageFmt, args...))
+}
+
+func (l eventLogger) AnnotatedEventf(object runtime.Object, annotations map[string]string, eventtype, reason, messageFmt string, args ...interface{}) {
+	l.Event(object, eventtype, reason, fmt.Sprintf(messageFmt, args...))
+}
+
+// describe names an object as Kind namespace/name.
+func describe(object runtime.Object) string {
+	kind := reflect.Indirect(reflect.ValueOf(object)).Type().Name()
+	accessor, err := meta.Accessor(object)
+	if err != nil {
+		return kind
+	}
+	if accessor.GetNamespace() == "" {
+		return fmt.Sprintf("%s %s", kind, accessor.GetName())
+	}
+	return fmt.Sprintf("%s %s/%s", kind, accessor.GetNamespace(), accessor.GetName())
+}
+
+// DiffText formats the diff as a summary line followed by a line per changed sub-resource, prefixed by +, ~ or -.
+func (r *Result) DiffText() string {
+	var text strings.Builder
+	fmt.Fprintln(&text, r.Diff.String())
+	for _, change := range r.Diff.Changes {
+		fmt.Fprintf(&text, "%s %s %s\n", actionSymbols[change.Action], change.Kind, change.Name)
+		for _, field := range change.Fields {
+			fmt.Fprintf(&text, "    %s\n", field)
+		}
+	}
+	return text.String()
+}
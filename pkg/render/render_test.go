@@ -0,0 +1,137 @@
+// -------------------------------------------------------------------------------------------
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License. See License.txt in the project root for license information.
+// --------------------------------------------------------------------------------------------
+
+package render
+
+import (
+	"encoding/json"
+	"os"
+
+	n "github.com/Azure/azure-sdk-for-go/services/network/mgmt/2021-03-01/network"
+	"github.com/Azure/go-autorest/autorest/to"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	v1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	networking "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+
+	agrewrite "github.com/Azure/application-gateway-kubernetes-ingress/pkg/apis/azureapplicationgatewayrewrite/v1beta1"
+	"github.com/Azure/application-gateway-kubernetes-ingress/pkg/configdiff"
+	"github.com/Azure/application-gateway-kubernetes-ingress/pkg/environment"
+)
+
+var _ = Describe("rendering the App Gateway config of manifests", func() {
+	readAppGw := func() *n.ApplicationGateway {
+		contents, err := os.ReadFile("testdata/appgw.json")
+		Expect(err).ToNot(HaveOccurred())
+		var appGw n.ApplicationGateway
+		Expect(json.Unmarshal(contents, &appGw)).To(Succeed())
+		return &appGw
+	}
+
+	Context("ensure LoadManifests reads the resources AGIC reads", func() {
+		It("should sort the resources by clientset and place them in the namespace", func() {
+			objects, err := LoadManifests("testdata/manifests", "test-ns")
+			Expect(err).ToNot(HaveOccurred())
+
+			// Ingress, Service, the EndpointSlice mirrored from the Endpoints and Deployment; The Certificate of the List is skipped.
+			Expect(objects.Kubernetes).To(HaveLen(4))
+			Expect(objects.AGIC).To(HaveLen(1))
+			Expect(objects.MultiCluster).To(BeEmpty())
+			Expect(objects.Istio).To(BeEmpty())
+
+			ingress := objects.Kubernetes[0].(*networking.Ingress)
+			Expect(ingress.Namespace).To(Equal("test-ns"))
+			rewrite := objects.AGIC[0].(*agrewrite.AzureApplicationGatewayRewrite)
+			Expect(rewrite.Namespace).To(Equal("test-ns"))
+			Expect(rewrite.Spec.RewriteRules[0].Actions.ResponseHeaderConfigurations[0].HeaderName).To(Equal("X-Rendered"))
+		})
+
+		It("should set the defaults of the API server", func() {
+			objects, err := LoadManifests("testdata/manifests/web.yaml", "test-ns")
+			Expect(err).ToNot(HaveOccurred())
+
+			service := objects.Kubernetes[1].(*v1.Service)
+			Expect(service.Spec.Ports[0].Protocol).To(Equal(v1.ProtocolTCP))
+			Expect(service.Spec.Ports[0].TargetPort).To(Equal(intstr.FromInt32(8080)))
+			endpointSlice := objects.Kubernetes[2].(*discoveryv1.EndpointSlice)
+			Expect(*endpointSlice.Ports[0].Protocol).To(Equal(v1.ProtocolTCP))
+			Expect(endpointSlice.Labels[discoveryv1.LabelServiceName]).To(Equal("web"))
+			Expect(endpointSlice.Namespace).To(Equal("test-ns"))
+		})
+
+		It("should fail on a manifest which is not YAML", func() {
+			manifest, err := os.CreateTemp("", "manifest-*.yaml")
+			Expect(err).ToNot(HaveOccurred())
+			defer os.Remove(manifest.Name())
+			_, err = manifest.WriteString("kind: [Service")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(manifest.Close()).To(Succeed())
+
+			_, err = LoadManifests(manifest.Name(), "default")
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	Context("ensure Render generates the config the controller would", func() {
+		var result *Result
+
+		BeforeEach(func() {
+			objects, err := LoadManifests("testdata/manifests", "default")
+			Expect(err).ToNot(HaveOccurred())
+
+			env := environment.GetFakeEnv()
+			result, err = Render(readAppGw(), objects, env, nil)
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		It("should route the Ingress to the endpoints of its Service", func() {
+			pools := *result.AppGw.BackendAddressPools
+			Expect(pools).To(HaveLen(1))
+			Expect(*pools[0].Name).To(Equal("pool-default-web-80-bp-8080"))
+			Expect(*(*pools[0].BackendAddresses)[0].IPAddress).To(Equal("10.0.0.4"))
+			Expect(*result.AppGw.ID).To(Equal("/subscriptions/sub/resourceGroups/rg/providers/Microsoft.Network/applicationGateways/appgw"))
+			Expect(*pools[0].ID).To(HavePrefix(*result.AppGw.ID + "/backendAddressPools/"))
+
+			listeners := *result.AppGw.HTTPListeners
+			Expect(listeners).To(HaveLen(1))
+			Expect(*listeners[0].HostNames).To(ConsistOf("www.contoso.com"))
+		})
+
+		It("should apply the rewrite rules of the custom resource", func() {
+			Expect(*result.AppGw.RewriteRuleSets).To(HaveLen(1))
+			rules := *(*result.AppGw.RewriteRuleSets)[0].RewriteRules
+			Expect(*rules[0].Name).To(Equal("add-header"))
+		})
+
+		It("should diff against the existing config", func() {
+			Expect(result.Diff.Summary[configdiff.BackendAddressPools]).To(Equal(configdiff.Counts{Added: 1, Removed: 1}))
+			Expect(result.DiffText()).To(ContainSubstring("- backendAddressPools legacy-pool\n"))
+			Expect(result.DiffText()).To(ContainSubstring("+ backendAddressPools pool-default-web-80-bp-8080\n"))
+		})
+	})
+
+	Context("ensure the JSON of the result does not contain secrets", func() {
+		It("should redact the certificates", func() {
+			appGw := readAppGw()
+			appGw.SslCertificates = &[]n.ApplicationGatewaySslCertificate{
+				{
+					Name: to.StringPtr("cert"),
+					ApplicationGatewaySslCertificatePropertiesFormat: &n.ApplicationGatewaySslCertificatePropertiesFormat{
+						Data:     to.StringPtr("c2VjcmV0"),
+						Password: to.StringPtr("secret"),
+					},
+				},
+			}
+
+			jsonBlob, err := (&Result{AppGw: appGw}).JSON()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(string(jsonBlob)).ToNot(ContainSubstring("secret"))
+			Expect(string(jsonBlob)).ToNot(ContainSubstring("c2VjcmV0"))
+			Expect(string(jsonBlob)).To(ContainSubstring(redacted))
+		})
+	})
+})
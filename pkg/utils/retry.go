@@ -1,6 +1,7 @@
 package utils
 
 import (
+	"context"
 	"time"
 
 	"k8s.io/klog/v2"
@@ -17,6 +18,12 @@ type RetriableFunction func() (Retriable, error)
 // if retriableFunction returns boolean as true, then Retry will retry if fn returned an error
 // if totalRetryCount is -1, then retry happen forever until one of the two above conditions are satisfied.
 func Retry(totalRetryCount int, retryPause time.Duration, retriableFunction RetriableFunction) (err error) {
+	return RetryWithContext(context.Background(), totalRetryCount, retryPause, retriableFunction)
+}
+
+// RetryWithContext is Retry, which additionally stops retrying once ctx is done.
+// When ctx is done while waiting to retry, the last error of retriableFunction is returned.
+func RetryWithContext(ctx context.Context, totalRetryCount int, retryPause time.Duration, retriableFunction RetriableFunction) (err error) {
 	retryCounter := 0
 	retry := Retriable(true)
 	for {
@@ -31,7 +38,14 @@ func Retry(totalRetryCount int, retryPause time.Duration, retriableFunction Retr
 		}
 
 		klog.Infof("Retrying in %s", retryPause)
-		time.Sleep(retryPause)
+		timer := time.NewTimer(retryPause)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			klog.Infof("Stopped retrying: %s", ctx.Err())
+			return
+		}
 	}
 	return
 }
@@ -1,6 +1,7 @@
 package utils
 
 import (
+	"context"
 	"errors"
 	"time"
 
@@ -104,5 +105,21 @@ var _ = Describe("Retry", func() {
 				Expect(retryError).To(BeNil())
 			})
 		})
+
+		Context("Test retry with context", func() {
+			It("should stop retrying once the context is done", func() {
+				ctx, cancel := context.WithCancel(context.Background())
+				counter := 0
+				err := errors.New("fake")
+				retryError := RetryWithContext(ctx, -1, time.Hour,
+					func() (Retriable, error) {
+						counter++
+						cancel()
+						return Retriable(true), err
+					})
+				Expect(counter).To(Equal(1))
+				Expect(retryError).To(Equal(err))
+			})
+		})
 	})
 })
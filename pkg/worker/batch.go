@@ -0,0 +1,119 @@
+// -------------------------------------------------------------------------------------------
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License. See License.txt in the project root for license information.
+// --------------------------------------------------------------------------------------------
+
+package worker
+
+import (
+	"sync"
+	"time"
+
+	"k8s.io/client-go/util/workqueue"
+
+	"github.com/Azure/application-gateway-kubernetes-ingress/pkg/events"
+)
+
+// batch coalesces the events received since the EventProcessor last ran.
+// Every run of the EventProcessor rebuilds the whole App Gateway config, so a batch is processed as a single event.
+type batch struct {
+	sync.Mutex
+
+	event        *events.Event
+	size         int
+	firstEventAt time.Time
+	lastEventAt  time.Time
+}
+
+// add coalesces the event into the batch.
+// A PeriodicReconcile is never replaced, as it is the only event which makes the EventProcessor skip its config cache.
+func (b *batch) add(event events.Event, now time.Time) {
+	b.Lock()
+	defer b.Unlock()
+
+	if b.event == nil {
+		b.firstEventAt = now
+	}
+	if b.event == nil || b.event.Type != events.PeriodicReconcile {
+		b.event = &event
+	}
+	b.size++
+	b.lastEventAt = now
+}
+
+// take empties the batch once no event arrived for the debounce duration, or once the batch is older than the coalescing window.
+// Until then it returns how long to wait; notBefore delays the batch further while a retry is backing off.
+func (b *batch) take(now time.Time, config Config, notBefore time.Time) (*events.Event, int, time.Duration) {
+	b.Lock()
+	defer b.Unlock()
+
+	if b.event == nil {
+		return nil, 0, 0
+	}
+
+	readyAt := b.lastEventAt.Add(config.DebounceDuration)
+	if windowEnd := b.firstEventAt.Add(config.CoalescingWindow); windowEnd.Before(readyAt) {
+		readyAt = windowEnd
+	}
+	if notBefore.After(readyAt) {
+		readyAt = notBefore
+	}
+	if wait := readyAt.Sub(now); wait > 0 {
+		return nil, 0, wait
+	}
+
+	event, size := b.event, b.size
+	b.event, b.size = nil, 0
+	return event, size, 0
+}
+
+// retry puts the events of a failed batch back, coalescing them with the events which arrived in the meantime.
+// The retried batch does not wait for the debounce duration; Only the backoff delays it.
+func (b *batch) retry(event events.Event, size int) {
+	b.Lock()
+	defer b.Unlock()
+
+	if b.event == nil || event.Type == events.PeriodicReconcile {
+		b.event = &event
+	}
+	b.size += size
+	b.firstEventAt = time.Time{}
+}
+
+// retryBackoff remembers when the rate limiter allows the next retry, so that events arriving
+// while a failed batch is backing off do not cut the backoff short.
+type retryBackoff struct {
+	workqueue.TypedRateLimiter[string]
+
+	mutex   sync.Mutex
+	retryAt time.Time
+}
+
+func newRetryBackoff(config Config) *retryBackoff {
+	return &retryBackoff{
+		TypedRateLimiter: workqueue.NewTypedItemExponentialFailureRateLimiter[string](config.RetryBaseDelay, config.RetryMaxDelay),
+	}
+}
+
+// When returns the backoff before the next retry and remembers when it ends.
+func (r *retryBackoff) When(key string) time.Duration {
+	delay := r.TypedRateLimiter.When(key)
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.retryAt = time.Now().Add(delay)
+	return delay
+}
+
+// Forget resets the backoff once a batch succeeded or was dropped.
+func (r *retryBackoff) Forget(key string) {
+	r.TypedRateLimiter.Forget(key)
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.retryAt = time.Time{}
+}
+
+func (r *retryBackoff) notBefore() time.Time {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return r.retryAt
+}
@@ -0,0 +1,60 @@
+// -------------------------------------------------------------------------------------------
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License. See License.txt in the project root for license information.
+// --------------------------------------------------------------------------------------------
+
+package worker
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/Azure/application-gateway-kubernetes-ingress/pkg/environment"
+)
+
+// Config tunes how the Worker coalesces events and retries failed updates.
+type Config struct {
+	// DebounceDuration is how long the worker waits for events to stop arriving before processing them.
+	DebounceDuration time.Duration
+
+	// CoalescingWindow caps how long events keep being coalesced, so that a steady stream of events
+	// (e.g. endpoint churn during a rolling deploy) still results in periodic updates.
+	CoalescingWindow time.Duration
+
+	// MaxRetries is how many times a failed batch is retried before its events are dropped.
+	MaxRetries int
+
+	// RetryBaseDelay and RetryMaxDelay bound the exponential backoff between retries.
+	RetryBaseDelay time.Duration
+	RetryMaxDelay  time.Duration
+}
+
+// DefaultConfig returns the Config used for the environment variables which are not set.
+func DefaultConfig() Config {
+	return Config{
+		DebounceDuration: 1 * time.Second,
+		CoalescingWindow: 10 * time.Second,
+		MaxRetries:       10,
+		RetryBaseDelay:   5 * time.Second,
+		RetryMaxDelay:    5 * time.Minute,
+	}
+}
+
+// NewConfig reads the Config from the environment variables; ValidateEnv has already rejected invalid values.
+func NewConfig(env environment.EnvVariables) Config {
+	config := DefaultConfig()
+	parseDuration(env.EventDebounceDuration, &config.DebounceDuration)
+	parseDuration(env.EventCoalescingWindow, &config.CoalescingWindow)
+	parseDuration(env.EventRetryBaseDelay, &config.RetryBaseDelay)
+	parseDuration(env.EventRetryMaxDelay, &config.RetryMaxDelay)
+	if maxRetries, err := strconv.Atoi(env.EventMaxRetries); err == nil && maxRetries >= 0 {
+		config.MaxRetries = maxRetries
+	}
+	return config
+}
+
+func parseDuration(value string, duration *time.Duration) {
+	if parsed, err := time.ParseDuration(value); err == nil && parsed >= 0 {
+		*duration = parsed
+	}
+}
@@ -6,7 +6,13 @@
 package worker
 
 import (
+	"time"
+
+	"k8s.io/client-go/util/workqueue"
+
 	"github.com/Azure/application-gateway-kubernetes-ingress/pkg/events"
+	"github.com/Azure/application-gateway-kubernetes-ingress/pkg/health"
+	"github.com/Azure/application-gateway-kubernetes-ingress/pkg/metricstore"
 )
 
 // EventProcessor provides a mechanism to act on events in the internal queue.
@@ -15,8 +21,22 @@ type EventProcessor interface {
 	ShouldProcess(events.Event) (bool, *string)
 }
 
-// Worker listens to the eventChannel and runs the EventProcessor.MutateAppGateway and MutateAKS
-// for each event.
+// Worker listens to the eventChannel, coalesces the events into batches on a rate limited workqueue
+// and runs the EventProcessor once per batch.
 type Worker struct {
 	EventProcessor
+
+	// Config tunes the coalescing and the retries of events; The zero value falls back to DefaultConfig.
+	Config Config
+
+	// MetricStore receives the workqueue metrics; Optional.
+	MetricStore metricstore.MetricStore
+
+	// Watchdog receives a heartbeat while the worker is idle and tracks the batches in process; Optional.
+	Watchdog *health.Watchdog
+
+	queue             workqueue.TypedRateLimitingInterface[string]
+	backoff           *retryBackoff
+	batch             *batch
+	heartbeatInterval time.Duration
 }
@@ -9,73 +9,129 @@ import (
 	"reflect"
 	"time"
 
+	"k8s.io/client-go/util/workqueue"
 	"k8s.io/klog/v2"
 
 	"github.com/Azure/application-gateway-kubernetes-ingress/pkg/events"
 )
 
-const sleepOnErrorSeconds = 5
-const minTimeBetweenUpdates = 1 * time.Second
+// queueName names the workqueue in its metrics.
+const queueName = "appgw"
 
-func drainChan(ch chan events.Event, defaultEvent events.Event) events.Event {
-	lastEvent := defaultEvent
-	klog.V(9).Infof("Draining %d events from work channel", len(ch))
-	for {
-		select {
-		case event := <-ch:
-			// if there are more event in the queue
-			// we will skip the reconcile event as we should focus on k8s related events
-			if event.Type != events.PeriodicReconcile {
-				lastEvent = event
-			}
-		default:
-			return lastEvent
-		}
+// heartbeatInterval is how often an idle worker tells the watchdog that it is running.
+const heartbeatInterval = 10 * time.Second
+
+// heartbeatKey is queued next to batchKey, so that heartbeats come from the goroutine processing the batches
+// and stop when it gets stuck.
+const heartbeatKey = "heartbeat"
+
+// batchKey is the only key in the workqueue: all events are coalesced into a single batch,
+// since every run of the EventProcessor reconciles the whole App Gateway.
+const batchKey = "appgw"
+
+// Run coalesces the events of the work channel into batches and processes them until stopChannel is closed.
+func (w *Worker) Run(work chan events.Event, stopChannel <-chan struct{}) {
+	if w.Config == (Config{}) {
+		w.Config = DefaultConfig()
 	}
-}
+	w.backoff = newRetryBackoff(w.Config)
+	w.batch = &batch{}
+	queueConfig := workqueue.TypedRateLimitingQueueConfig[string]{
+		Name: queueName,
+	}
+	if w.MetricStore != nil {
+		queueConfig.MetricsProvider = w.MetricStore.WorkqueueMetricsProvider()
+	}
+	w.queue = workqueue.NewTypedRateLimitingQueueWithConfig[string](w.backoff, queueConfig)
+	if w.heartbeatInterval == 0 {
+		w.heartbeatInterval = heartbeatInterval
+	}
+	w.queue.Add(heartbeatKey)
 
-// Run starts the worker which listens for events in eventChannel; stops when stopChannel is closed.
-func (w *Worker) Run(work chan events.Event, stopChannel chan struct{}) {
-	lastUpdate := time.Now().Add(-1 * time.Second)
 	klog.V(1).Infoln("Worker started")
+	go func() {
+		for w.processNextBatch() {
+		}
+	}()
+
 	for {
 		select {
 		case event := <-work:
-			if shouldProcess, reason := w.ShouldProcess(event); !shouldProcess {
-				if reason != nil {
-					// This log statement could potentially generate a large amount of log lines and most could be
-					// innocuous - for instance: "endpoint default/aad-pod-identity-mic is not used by any Ingress"
-					klog.V(3).Infof("Skipping event. Reason: %s", *reason)
-				}
-				continue
-			}
-
-			if event.Value != nil {
-				// get name, namespace and kind from event.Value
-				name := reflect.ValueOf(event.Value).Elem().FieldByName("Name").String()
-				namespace := reflect.ValueOf(event.Value).Elem().FieldByName("Namespace").String()
-				objectType := reflect.TypeOf(event.Value).Elem()
-
-				klog.V(3).Infof("Processing k8s event of type:%s object:%s/%s/%s", event.Type, objectType, namespace, name)
-			}
-
-			since := time.Since(lastUpdate)
-			if since < minTimeBetweenUpdates {
-				sleep := minTimeBetweenUpdates - since
-				klog.V(9).Infof("[worker] It has been %+v since last update; Sleeping for %+v before next update", since, sleep)
-				time.Sleep(sleep)
-			}
-
-			_ = drainChan(work, event)
-
-			if err := w.ProcessEvent(event); err != nil {
-				klog.Error("Error processing event.", err)
-				time.Sleep(sleepOnErrorSeconds * time.Second)
-			}
-
-			lastUpdate = time.Now()
+			w.enqueue(event)
 		case <-stopChannel:
-			break
+			w.queue.ShutDown()
+			klog.V(1).Infoln("Worker stopped")
+			return
+		}
+	}
+}
+
+// enqueue adds the event to the pending batch and schedules the batch for after the debounce duration.
+func (w *Worker) enqueue(event events.Event) {
+	if shouldProcess, reason := w.ShouldProcess(event); !shouldProcess {
+		if reason != nil {
+			// This log statement could potentially generate a large amount of log lines and most could be
+			// innocuous - for instance: "endpoint default/aad-pod-identity-mic is not used by any Ingress"
+			klog.V(3).Infof("Skipping event. Reason: %s", *reason)
 		}
+		return
 	}
+
+	if event.Value != nil {
+		// get name, namespace and kind from event.Value
+		name := reflect.ValueOf(event.Value).Elem().FieldByName("Name").String()
+		namespace := reflect.ValueOf(event.Value).Elem().FieldByName("Namespace").String()
+		objectType := reflect.TypeOf(event.Value).Elem()
+
+		klog.V(3).Infof("Queueing k8s event of type:%s object:%s/%s/%s", event.Type, objectType, namespace, name)
+	}
+
+	w.batch.add(event, time.Now())
+	w.queue.AddAfter(batchKey, w.Config.DebounceDuration)
+}
+
+// processNextBatch waits for the batch to become ready and processes it; It returns false once the queue is shut down.
+func (w *Worker) processNextBatch() bool {
+	key, shutdown := w.queue.Get()
+	if shutdown {
+		return false
+	}
+	defer w.queue.Done(key)
+
+	if key == heartbeatKey {
+		w.Watchdog.Heartbeat()
+		w.queue.AddAfter(key, w.heartbeatInterval)
+		return true
+	}
+
+	event, size, wait := w.batch.take(time.Now(), w.Config, w.backoff.notBefore())
+	if wait > 0 {
+		// More events arrived since the batch was scheduled, or a retry is backing off.
+		klog.V(9).Infof("[worker] Batch is not ready; Waiting for %+v", wait)
+		w.queue.AddAfter(key, wait)
+		return true
+	}
+	if event == nil {
+		return true
+	}
+
+	klog.V(3).Infof("Processing a batch of %d events", size)
+	eventDone := w.Watchdog.BeginEvent()
+	err := w.ProcessEvent(*event)
+	eventDone()
+	if err != nil {
+		if retries := w.queue.NumRequeues(key); retries < w.Config.MaxRetries {
+			klog.Errorf("Error processing event; Retrying (%d/%d): %s", retries+1, w.Config.MaxRetries, err)
+			w.batch.retry(*event, size)
+			w.queue.AddRateLimited(key)
+			return true
+		}
+		klog.Errorf("Error processing event; Dropping a batch of %d events after %d retries: %s", size, w.Config.MaxRetries, err)
+		if w.MetricStore != nil {
+			w.MetricStore.IncDroppedEventCounter()
+		}
+	}
+
+	w.queue.Forget(key)
+	return true
 }
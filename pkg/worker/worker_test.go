@@ -6,12 +6,15 @@
 package worker
 
 import (
+	"errors"
 	"time"
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 
+	"github.com/Azure/application-gateway-kubernetes-ingress/pkg/environment"
 	"github.com/Azure/application-gateway-kubernetes-ingress/pkg/events"
+	"github.com/Azure/application-gateway-kubernetes-ingress/pkg/health"
 	"github.com/Azure/application-gateway-kubernetes-ingress/pkg/tests"
 )
 
@@ -19,6 +22,14 @@ var _ = Describe("Worker Test", func() {
 	var stopChannel chan struct{}
 	var work chan events.Event
 
+	config := Config{
+		DebounceDuration: 50 * time.Millisecond,
+		CoalescingWindow: 1 * time.Second,
+		MaxRetries:       2,
+		RetryBaseDelay:   10 * time.Millisecond,
+		RetryMaxDelay:    20 * time.Millisecond,
+	}
+
 	BeforeEach(func() {
 		stopChannel = make(chan struct{})
 		work = make(chan events.Event)
@@ -38,6 +49,7 @@ var _ = Describe("Worker Test", func() {
 			eventProcessor := NewFakeProcessor(processEvent)
 			worker := Worker{
 				EventProcessor: eventProcessor,
+				Config:         config,
 			}
 			go worker.Run(work, stopChannel)
 
@@ -60,39 +72,147 @@ var _ = Describe("Worker Test", func() {
 		})
 	})
 
-	Context("Verify that drainChan works", func() {
-		It("Should drain the channel and return the last element", func() {
-			buffSize := 10
-			counter := int64(0)
-
-			// Create and fill the channel
-			work := make(chan events.Event, buffSize)
-		Fill:
-			for {
-				select {
-				case work <- events.Event{}:
-					counter++
-				default:
-					break Fill
-				}
+	Context("Check that worker coalesces and retries events", func() {
+		It("Should process a burst of events once", func() {
+			processed := make(chan events.Event, 10)
+			worker := Worker{
+				EventProcessor: NewFakeProcessor(func(event events.Event) error {
+					processed <- event
+					return nil
+				}),
+				Config: config,
+			}
+			go worker.Run(work, stopChannel)
+
+			work <- events.Event{Type: events.Create}
+			work <- events.Event{Type: events.PeriodicReconcile}
+			work <- events.Event{Type: events.Update}
+
+			Eventually(processed).Should(Receive(Equal(events.Event{Type: events.PeriodicReconcile})))
+			Consistently(processed, 200*time.Millisecond).ShouldNot(Receive())
+		})
+
+		It("Should retry a failed batch until it runs out of retries", func() {
+			processed := make(chan events.Event, 10)
+			worker := Worker{
+				EventProcessor: NewFakeProcessor(func(event events.Event) error {
+					processed <- event
+					return errors.New("ARM is throttling")
+				}),
+				Config: config,
+			}
+			go worker.Run(work, stopChannel)
+
+			work <- events.Event{Type: events.Create}
+
+			// The first attempt and 2 retries.
+			for attempt := 0; attempt < 3; attempt++ {
+				Eventually(processed).Should(Receive(Equal(events.Event{Type: events.Create})))
+			}
+			Consistently(processed, 200*time.Millisecond).ShouldNot(Receive())
+		})
+	})
+
+	Context("Check that worker sends heartbeats only while it makes progress", func() {
+		newWatchdog := func() *health.Watchdog {
+			return health.NewWatchdog(health.WatchdogConfig{
+				HeartbeatTimeout:    100 * time.Millisecond,
+				EventTimeout:        300 * time.Millisecond,
+				ARMOperationTimeout: time.Hour,
+				InformerSyncTimeout: time.Hour,
+			})
+		}
+
+		It("Should keep the watchdog alive while idle", func() {
+			watchdog := newWatchdog()
+			worker := Worker{
+				EventProcessor:    NewFakeProcessor(func(event events.Event) error { return nil }),
+				Config:            config,
+				Watchdog:          watchdog,
+				heartbeatInterval: 20 * time.Millisecond,
+			}
+			go worker.Run(work, stopChannel)
+
+			Consistently(watchdog.Alive, 300*time.Millisecond).Should(BeTrue())
+		})
+
+		It("Should fail the watchdog when processing an event gets stuck", func() {
+			watchdog := newWatchdog()
+			started := make(chan struct{}, 10)
+			release := make(chan struct{})
+			defer close(release)
+			worker := Worker{
+				EventProcessor: NewFakeProcessor(func(event events.Event) error {
+					started <- struct{}{}
+					<-release
+					return nil
+				}),
+				Config:            config,
+				Watchdog:          watchdog,
+				heartbeatInterval: 20 * time.Millisecond,
+			}
+			go worker.Run(work, stopChannel)
+
+			work <- events.Event{Type: events.Create}
+			Eventually(started).Should(Receive())
+
+			// Incoming events do not count as progress.
+			for idx := 0; idx < 5; idx++ {
+				work <- events.Event{Type: events.Update}
 			}
-			Expect(counter).To(Equal(int64(len(work))))
-			def := events.Event{}
-			lastEvent := drainChan(work, def)
-			Expect(len(work)).To(Equal(0))
-			Expect(lastEvent).To(Equal(events.Event{}))
+			Expect(watchdog.Alive()).To(BeTrue())
+			Eventually(watchdog.Alive).Should(BeFalse())
 		})
 	})
 
-	Context("Verify that drainChan works", func() {
-		It("Should drain the channel and return the default element", func() {
-			buffSize := 10
+	Context("Verify that batch works", func() {
+		It("Should wait for the debounce duration and the coalescing window", func() {
+			b := &batch{}
+			start := time.Now()
+			b.add(events.Event{Type: events.Create}, start)
+
+			_, _, wait := b.take(start, config, time.Time{})
+			Expect(wait).To(Equal(config.DebounceDuration))
 
-			// Keep the channel empty
-			work := make(chan events.Event, buffSize)
-			def := events.Event{}
-			lastEvent := drainChan(work, def)
-			Expect(lastEvent).To(Equal(def))
+			// Events keep arriving, but the coalescing window caps the wait.
+			b.add(events.Event{Type: events.Update}, start.Add(990*time.Millisecond))
+			_, _, wait = b.take(start.Add(990*time.Millisecond), config, time.Time{})
+			Expect(wait).To(Equal(10 * time.Millisecond))
+
+			event, size, wait := b.take(start.Add(config.CoalescingWindow), config, time.Time{})
+			Expect(wait).To(BeZero())
+			Expect(size).To(Equal(2))
+			Expect(*event).To(Equal(events.Event{Type: events.Update}))
+
+			event, _, _ = b.take(start.Add(config.CoalescingWindow), config, time.Time{})
+			Expect(event).To(BeNil())
+		})
+
+		It("Should wait for the backoff before retrying", func() {
+			b := &batch{}
+			now := time.Now()
+			b.retry(events.Event{Type: events.Create}, 1)
+			b.add(events.Event{Type: events.Update}, now)
+
+			_, _, wait := b.take(now, config, now.Add(time.Second))
+			Expect(wait).To(Equal(time.Second))
+
+			event, size, _ := b.take(now.Add(time.Second), config, now.Add(time.Second))
+			Expect(size).To(Equal(2))
+			Expect(*event).To(Equal(events.Event{Type: events.Update}))
+		})
+	})
+
+	Context("Verify that NewConfig works", func() {
+		It("Should fall back to defaults for unset variables", func() {
+			env := environment.EnvVariables{
+				EventDebounceDuration: "250ms",
+				EventMaxRetries:       "0",
+			}
+			expected := DefaultConfig()
+			expected.DebounceDuration = 250 * time.Millisecond
+			expected.MaxRetries = 0
+			Expect(NewConfig(env)).To(Equal(expected))
 		})
 	})
 })
@@ -4,6 +4,8 @@ set -auexo pipefail
 
 # This script requres a checkout of https://github.com/kubernetes/code-generator release-1.21 in ../
 # For more information read https://blog.openshift.com/kubernetes-deep-dive-code-generation-customresources/
+# The +groupName tags of pkg/apis name the CRD rather than its API group, e.g. azureapplicationgatewayrewrites.appgw.ingress.azure.io:
+# client-gen generates one typed client per group and version, so CRDs sharing appgw.ingress.azure.io/v1beta1 would be merged into one.
 # To generate CRDs, run this in the base directory of AGIC repo. Generated files will be in ~/go/src/ dir. Copy them over to ./pkg folder.
 
 # Commands to copy:
@@ -13,14 +15,25 @@ set -auexo pipefail
 # echo -e "Cleanup previously generated code..."
 # rm -rf pkg/client $(find ./pkg -name 'zz_*.go')
 
+AGIC_GROUPS="azureapplicationgatewayinstanceupdatestatus:v1beta1 azureapplicationgatewaybackendpool:v1beta1 azureingressprohibitedtarget:v1 loaddistributionpolicy:v1beta1 azureapplicationgatewayrewrite:v1beta1 azureapplicationgatewayclassparameters:v1beta1 azureapplicationgatewayroutematch:v1beta1"
+
 echo -e "Generate Application Gateway CRDs..."
 ../code-generator/generate-groups.sh \
-    all \
+    deepcopy \
     github.com/Azure/application-gateway-kubernetes-ingress/pkg/crd_client/agic_crd_client \
     github.com/Azure/application-gateway-kubernetes-ingress/pkg/apis \
-    "azureapplicationgatewayinstanceupdatestatus:v1beta1 azureapplicationgatewaybackendpool:v1beta1 azureingressprohibitedtarget:v1 loaddistributionpolicy:v1beta1 azureapplicationgatewayrewrite:v1beta1" \
+    "${AGIC_GROUPS}" \
     --go-header-file ../code-generator/hack/boilerplate.go.txt
 
+# deepcopy-gen does not take --plural-exceptions, which keeps AzureApplicationGatewayClassParameters from becoming ...Parameterses.
+../code-generator/generate-groups.sh \
+    client,lister,informer \
+    github.com/Azure/application-gateway-kubernetes-ingress/pkg/crd_client/agic_crd_client \
+    github.com/Azure/application-gateway-kubernetes-ingress/pkg/apis \
+    "${AGIC_GROUPS}" \
+    --go-header-file ../code-generator/hack/boilerplate.go.txt \
+    --plural-exceptions Endpoints:Endpoints,AzureApplicationGatewayClassParameters:AzureApplicationGatewayClassParameters
+
 echo -e "Generate Azure Multi-Cluster CRDs..."
 ../code-generator/generate-groups.sh \
     all \